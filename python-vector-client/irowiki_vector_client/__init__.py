@@ -114,6 +114,9 @@ class QdrantClient(BaseVectorClient):
                     "chunk_type": result.payload.get("chunk_type"),
                     "namespace": result.payload.get("namespace"),
                     "page_id": result.payload.get("page_id"),
+                    "revision_id": result.payload.get("revision_id"),
+                    "section_anchor": result.payload.get("section_anchor"),
+                    "url": result.payload.get("url"),
                 }
             )
 
@@ -188,6 +191,10 @@ class ChromaDBClient(BaseVectorClient):
                     "chunk_type": metadata.get("chunk_type"),
                     "namespace": metadata.get("namespace"),
                     "page_id": metadata.get("page_id"),
+                    "revision_id": metadata.get("revision_id"),
+                    "section_anchor": metadata.get("section_anchor"),
+                    "url": metadata.get("url"),
+                    "tokens": metadata.get("tokens"),
                 }
             )
 
@@ -7,7 +7,10 @@
 -- - page_id uses INTEGER PRIMARY KEY (auto-increment in SQLite, serial in PostgreSQL)
 -- - namespace follows MediaWiki standard (0=Main, 2=User, 4=Project, 6=File, etc.)
 -- - title is stored without namespace prefix (e.g., "Prontera" not "Main:Prontera")
--- - is_redirect tracks redirect pages for link resolution
+-- - is_redirect tracks redirect pages for link resolution; redirect_target
+--   is where they point (see 014_redirect_targets.sql)
+-- - wiki_id scopes titles to one source wiki when an archive holds several
+--   (see 010_wikis.sql); '' is the archive's primary wiki
 -- - created_at/updated_at track database timestamps (not wiki timestamps)
 
 CREATE TABLE IF NOT EXISTS pages (
@@ -31,6 +34,15 @@ CREATE TABLE IF NOT EXISTS pages (
     -- BOOLEAN stored as 0/1 in SQLite, native boolean in PostgreSQL
     is_redirect BOOLEAN NOT NULL DEFAULT 0,
     
+    -- Title the redirect points to, parsed from its latest revision
+    -- NULL for pages that aren't redirects
+    redirect_target TEXT,
+    
+    -- Source wiki of this page (wikis.wiki_id)
+    -- '' is the primary wiki described by archive_meta; sister wikis merged
+    -- into the archive use their own ID, so the same title can exist in each
+    wiki_id TEXT NOT NULL DEFAULT '',
+    
     -- Timestamp when page record was created in our database
     -- TIMESTAMP type compatible with both SQLite and PostgreSQL
     -- Tracks when we first discovered this page
@@ -40,9 +52,9 @@ CREATE TABLE IF NOT EXISTS pages (
     -- Used for incremental scraping and change tracking
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     
-    -- Ensure titles are unique within each namespace
+    -- Ensure titles are unique within each namespace of each wiki
     -- Prevents duplicate page entries
-    UNIQUE(namespace, title),
+    UNIQUE(wiki_id, namespace, title),
     
     -- Namespace must be non-negative
     -- Negative namespaces are for special MediaWiki pages
@@ -0,0 +1,42 @@
+-- schema/sqlite/007_archive_meta.sql
+-- Archive metadata: Describe where an archive came from and how it may be reused
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - Simple key/value table so new keys never need a migration
+-- - Written by the scraper at the start of each full scrape
+-- - Read by the SDK (GetArchiveInfo) for attribution and interwiki links
+--
+-- Well-known keys:
+-- - wiki_name:          Site name from siteinfo (e.g., "iRO Wiki")
+-- - base_url:           Base URL of the source wiki (e.g., "https://irowiki.org")
+-- - api_url:            Full URL of the MediaWiki API endpoint
+-- - article_path:       Article URL pattern (e.g., "/wiki/$1")
+-- - language:           Content language code (e.g., "en")
+-- - generator:          MediaWiki version string (e.g., "MediaWiki 1.44.0")
+-- - license_name:       License text from siteinfo rightsinfo
+-- - license_url:        License URL from siteinfo rightsinfo
+-- - scraper_version:    Version of the tool that produced the archive
+-- - scraped_at:         When the scrape started (ISO 8601, UTC)
+-- - siteinfo:           Raw siteinfo "general" block as JSON
+
+-- ============================================================================
+-- Table: archive_meta
+-- Key/value metadata describing the archive
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS archive_meta (
+    -- Metadata key (see well-known keys above)
+    key TEXT PRIMARY KEY,
+
+    -- Metadata value, always stored as text
+    -- Structured values (e.g., siteinfo) are stored as JSON
+    value TEXT NOT NULL,
+
+    -- When this key was last written (UTC)
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (2, 'Add archive_meta key/value table');
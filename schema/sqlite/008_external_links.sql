@@ -0,0 +1,50 @@
+-- schema/sqlite/008_external_links.sql
+-- External links table: Outbound http(s) links and their archived copies
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - Populated from the latest revision of each page (not full history)
+-- - Liveness is checked out-of-band; NULL checked_at means never checked
+-- - Dead links are enriched with the nearest Internet Archive snapshot
+-- - Renderers link to archive_url instead of url when is_dead is set
+
+CREATE TABLE IF NOT EXISTS external_links (
+    -- Page whose latest revision contains the link
+    source_page_id INTEGER NOT NULL,
+
+    -- Full external URL as written in the wikitext
+    url TEXT NOT NULL,
+
+    -- Result of the last liveness check
+    -- NULL until checked, 0 alive, 1 dead
+    is_dead BOOLEAN,
+
+    -- When liveness was last checked (UTC)
+    checked_at TIMESTAMP,
+
+    -- Nearest archived copy of a dead link (e.g., web.archive.org URL)
+    -- NULL if alive, unchecked, or no snapshot exists
+    archive_url TEXT,
+
+    -- Capture time of the archived copy
+    archive_timestamp TIMESTAMP,
+
+    -- One record per URL per page
+    UNIQUE(source_page_id, url)
+);
+
+-- Index for rendering a page's external links
+CREATE INDEX IF NOT EXISTS idx_extlinks_source
+ON external_links(source_page_id);
+
+-- Index for checking each URL once regardless of how many pages use it
+CREATE INDEX IF NOT EXISTS idx_extlinks_url
+ON external_links(url);
+
+-- Index for finding links that still need checking
+CREATE INDEX IF NOT EXISTS idx_extlinks_unchecked
+ON external_links(checked_at) WHERE checked_at IS NULL;
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (3, 'Add external_links with archived snapshot URLs');
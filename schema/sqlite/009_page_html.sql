@@ -0,0 +1,35 @@
+-- schema/sqlite/009_page_html.sql
+-- Page HTML table: Wiki-rendered HTML of each page's latest revision
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - Optional: only populated when the scraper runs with --capture-html
+-- - HTML comes from the wiki's own parser (action=parse), so complex
+--   templates render exactly as they did on the live site
+-- - One row per page; recapturing replaces the row
+-- - revision_id records which revision was rendered; it lags behind the
+--   latest revision until the page is recaptured
+
+CREATE TABLE IF NOT EXISTS page_html (
+    -- Page the HTML belongs to
+    page_id INTEGER PRIMARY KEY,
+
+    -- Revision that was rendered
+    revision_id INTEGER NOT NULL,
+
+    -- Rendered HTML body as returned by action=parse (no <html>/<body> wrapper)
+    html TEXT NOT NULL,
+
+    -- When the HTML was fetched (UTC)
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+);
+
+-- Index for finding pages whose captured HTML is out of date
+CREATE INDEX IF NOT EXISTS idx_page_html_revision
+ON page_html(revision_id);
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (4, 'Add page_html with wiki-rendered HTML');
@@ -0,0 +1,49 @@
+-- schema/sqlite/010_wikis.sql
+-- Wikis table: Sister wikis merged into one archive
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - Optional: archives of a single wiki have no rows here; archive_meta
+--   describes their source
+-- - Each sister wiki (e.g., iRO Wiki Classic) is scraped into its own archive
+--   and merged with "python -m scraper merge-wiki"
+-- - MediaWiki page and revision IDs are only unique within one wiki, so a
+--   merged wiki's IDs are shifted by id_offset to stay unique archive-wide:
+--   archive ID = source ID + id_offset
+-- - pages.wiki_id scopes titles; files are shared by filename, as sister
+--   wikis normally use a common file repository
+
+CREATE TABLE IF NOT EXISTS wikis (
+    -- Short identifier used in pages.wiki_id (e.g., "classic")
+    -- '' is the primary wiki and is never stored here
+    wiki_id TEXT PRIMARY KEY,
+
+    -- Site name from the wiki's siteinfo (e.g., "iRO Wiki Classic")
+    name TEXT NOT NULL,
+
+    -- Base URL of the wiki (e.g., "https://irowiki.org")
+    base_url TEXT NOT NULL,
+
+    -- Full URL of the MediaWiki API endpoint
+    api_url TEXT,
+
+    -- Article URL pattern (e.g., "/classic/$1")
+    article_path TEXT,
+
+    -- Added to the wiki's page and revision IDs when merged
+    id_offset INTEGER NOT NULL UNIQUE,
+
+    -- When the wiki was last merged into the archive (UTC)
+    merged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK(wiki_id <> ''),
+    CHECK(id_offset > 0)
+);
+
+-- Index for listing a wiki's pages
+CREATE INDEX IF NOT EXISTS idx_pages_wiki
+ON pages(wiki_id);
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (5, 'Add wikis for sister wikis merged into one archive');
@@ -0,0 +1,36 @@
+-- schema/sqlite/011_interwiki_links.sql
+-- Interwiki links table: Cross-wiki mapping between archived wikis
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - Optional: only populated in archives holding sister wikis (see 010_wikis.sql)
+-- - Rebuilt by "python -m scraper merge-wiki" from the latest revision of
+--   every page: an interwiki or interlanguage link such as [[classic:Poring]]
+--   whose prefix is the ID of a wiki in the archive maps the page to the
+--   linked article
+-- - Links to wikis that aren't archived are ignored; links to the primary
+--   wiki can't be told apart from them, so the mapping is read both ways
+-- - target_title is stored as written (normalized) and resolved against
+--   pages at query time, as the target may be merged later
+
+CREATE TABLE IF NOT EXISTS interwiki_links (
+    -- Page containing the link
+    source_page_id INTEGER NOT NULL,
+
+    -- Wiki the link points to (wikis.wiki_id)
+    target_wiki TEXT NOT NULL,
+
+    -- Linked title on the target wiki (underscores replaced with spaces)
+    target_title TEXT NOT NULL,
+
+    PRIMARY KEY (source_page_id, target_wiki),
+    FOREIGN KEY (source_page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+);
+
+-- Index for the reverse mapping (which pages link to a title)
+CREATE INDEX IF NOT EXISTS idx_interwiki_target
+ON interwiki_links(target_wiki, target_title);
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (6, 'Add interwiki_links mapping pages across sister wikis');
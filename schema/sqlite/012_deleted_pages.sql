@@ -0,0 +1,25 @@
+-- schema/sqlite/012_deleted_pages.sql
+-- Deleted pages table: Tombstones for pages deleted from the live wiki
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - Populated by incremental scrapes when recent changes report a deletion
+-- - Deleted pages keep their rows in pages and revisions, so removed content
+--   stays available for research; readers hide pages listed here by default
+--   to match the live wiki
+-- - A page restored on the wiki has its row removed on the next scrape
+-- - Archives built before this table existed have no tombstones
+
+CREATE TABLE IF NOT EXISTS deleted_pages (
+    -- Page that was deleted
+    page_id INTEGER PRIMARY KEY,
+
+    -- When the deletion was detected (UTC)
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+);
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (7, 'Add deleted_pages tombstones');
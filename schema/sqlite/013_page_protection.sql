@@ -0,0 +1,34 @@
+-- schema/sqlite/013_page_protection.sql
+-- Page protection table: Protection levels of pages on the live wiki
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - Optional: only populated when the scraper runs with --capture-protection
+-- - One row per protected action of a page (prop=info&inprop=protection);
+--   unprotected pages have no rows
+-- - Recapturing replaces a page's rows, so lifted protections disappear
+-- - Cascading protections are recorded like direct ones
+
+CREATE TABLE IF NOT EXISTS page_protection (
+    -- Protected page
+    page_id INTEGER NOT NULL,
+
+    -- Protected action: 'edit', 'move', 'upload' or 'create'
+    action TEXT NOT NULL,
+
+    -- Group allowed to perform the action (e.g., 'autoconfirmed', 'sysop')
+    level TEXT NOT NULL,
+
+    -- When the protection expires: ISO 8601 timestamp, or 'infinity'
+    expiry TEXT NOT NULL DEFAULT 'infinity',
+
+    -- When the protection was fetched (UTC)
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (page_id, action),
+    FOREIGN KEY (page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+);
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (8, 'Add page_protection levels');
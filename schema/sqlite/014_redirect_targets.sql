@@ -0,0 +1,19 @@
+-- schema/sqlite/014_redirect_targets.sql
+-- Redirect targets: Where redirect pages point
+-- Version: 1.0
+-- Compatible: SQLite 3.35+, PostgreSQL 13+
+--
+-- Design Notes:
+-- - pages.redirect_target is declared in 001_pages.sql; archives created
+--   before it exist get the column (filled in from their latest revisions)
+--   when the schema is initialized
+-- - Parsed from the latest revision's "#REDIRECT [[Target]]" after each
+--   scrape and merge, normalized like page titles (spaces, first letter
+--   upper-cased) and without any #section
+-- - Indexed so the redirects into a page can be listed without a scan
+
+CREATE INDEX IF NOT EXISTS idx_pages_redirect_target
+ON pages(redirect_target) WHERE redirect_target IS NOT NULL;
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (9, 'Add pages.redirect_target');
@@ -0,0 +1,53 @@
+-- schema/sqlite/015_comment_fts.sql
+-- Edit summary search: Full-text index of revision comments
+-- Version: 1.0
+-- Compatible: SQLite 3.35+
+--
+-- Design Notes:
+-- - One row per revision with a non-empty comment; the FTS rowid is the
+--   revision_id, so matches join straight back to revisions
+-- - Separate from pages_fts, which only indexes the latest revision of
+--   each page, while every revision's summary is searchable here
+-- - Triggers keep it in sync; the insert trigger replaces an existing row
+--   because the scraper writes revisions with INSERT OR REPLACE
+-- - Archives created before this table are indexed when the schema is
+--   next initialized
+
+CREATE VIRTUAL TABLE IF NOT EXISTS revision_comments_fts USING fts5(
+    comment,
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS revision_comments_fts_insert
+AFTER INSERT ON revisions
+BEGIN
+    DELETE FROM revision_comments_fts WHERE rowid = NEW.revision_id;
+    INSERT INTO revision_comments_fts (rowid, comment)
+    SELECT NEW.revision_id, NEW.comment
+    WHERE NEW.comment IS NOT NULL AND NEW.comment != '';
+END;
+
+CREATE TRIGGER IF NOT EXISTS revision_comments_fts_update
+AFTER UPDATE OF comment ON revisions
+BEGIN
+    DELETE FROM revision_comments_fts WHERE rowid = OLD.revision_id;
+    INSERT INTO revision_comments_fts (rowid, comment)
+    SELECT NEW.revision_id, NEW.comment
+    WHERE NEW.comment IS NOT NULL AND NEW.comment != '';
+END;
+
+CREATE TRIGGER IF NOT EXISTS revision_comments_fts_delete
+AFTER DELETE ON revisions
+BEGIN
+    DELETE FROM revision_comments_fts WHERE rowid = OLD.revision_id;
+END;
+
+-- Initial population, skipped once the index has rows
+INSERT INTO revision_comments_fts (rowid, comment)
+SELECT revision_id, comment
+FROM revisions
+WHERE comment IS NOT NULL AND comment != ''
+  AND NOT EXISTS (SELECT 1 FROM revision_comments_fts);
+
+INSERT OR IGNORE INTO schema_version (version, description)
+VALUES (10, 'Add revision_comments_fts edit summary index');
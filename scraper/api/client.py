@@ -353,6 +353,26 @@ class MediaWikiAPIClient:
         """
         return self._request("query", params)
 
+    def parse(self, params: Dict[str, Any]) -> Dict[str, Any]:
+        """
+        Execute parse action with custom parameters.
+
+        Renders wikitext with the wiki's own parser, so templates, modules,
+        and extensions produce exactly the HTML the live site shows.
+
+        Args:
+            params: Parse parameters (action and format will be added automatically)
+
+        Returns:
+            Parse results
+
+        Example:
+            >>> client = MediaWikiAPIClient("https://irowiki.org")
+            >>> data = client.parse({'oldid': 12345, 'prop': 'text'})
+            >>> html = data['parse']['text']['*']
+        """
+        return self._request("parse", params)
+
     def _detect_api_version(self) -> None:
         """Detect MediaWiki version and validate compatibility.
 
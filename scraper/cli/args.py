@@ -61,11 +61,20 @@ Examples:
   # Dry run to estimate time and page count
   python -m scraper full --dry-run
 
+  # Quick estimate from the wiki's statistics (a single request)
+  python -m scraper full --estimate --format json
+
   # Resume from checkpoint automatically
   python -m scraper full --resume
 
   # Force new scrape (ignore existing data)
   python -m scraper full --force
+
+  # Also store the wiki's own rendering of each page
+  python -m scraper full --capture-html
+
+  # Also store protection levels, for badging protected pages
+  python -m scraper full --capture-protection
 """
 
     full_parser = subparsers.add_parser(
@@ -104,6 +113,12 @@ Examples:
         help="Discover pages but don't scrape revisions or store data",
     )
 
+    full_parser.add_argument(
+        "--estimate",
+        action="store_true",
+        help="Estimate requests, duration and size from the wiki's statistics and exit",
+    )
+
     full_parser.add_argument(
         "--format",
         choices=["text", "json"],
@@ -111,6 +126,18 @@ Examples:
         help="Output format for statistics (default: text)",
     )
 
+    full_parser.add_argument(
+        "--capture-html",
+        action="store_true",
+        help="Also store the wiki-rendered HTML of each page's latest revision",
+    )
+
+    full_parser.add_argument(
+        "--capture-protection",
+        action="store_true",
+        help="Also store the protection levels of each page",
+    )
+
     # Resume flags (mutually exclusive)
     resume_group = full_parser.add_mutually_exclusive_group()
     resume_group.add_argument(
@@ -184,6 +211,60 @@ Examples:
         help="Output format for statistics (default: text)",
     )
 
+    incr_parser.add_argument(
+        "--capture-html",
+        action="store_true",
+        help="Also refresh the wiki-rendered HTML of changed pages",
+    )
+
+    incr_parser.add_argument(
+        "--capture-protection",
+        action="store_true",
+        help="Also refresh the protection levels of every page",
+    )
+
+    # Merge a sister wiki command
+    merge_epilog = """
+Examples:
+  # Scrape the classic wiki into its own archive, then merge it
+  python -m scraper --config classic.yaml --database data/classic.db full
+  python -m scraper merge-wiki data/classic.db --wiki-id classic
+
+  # Re-merge after an incremental update of the classic archive
+  python -m scraper merge-wiki data/classic.db --wiki-id classic
+"""
+
+    merge_parser = subparsers.add_parser(
+        "merge-wiki",
+        help="Merge a sister wiki's archive into this archive",
+        description=(
+            "Copy the archive of a sister wiki (e.g., iRO Wiki Classic) into the "
+            "database so both wikis can be queried together"
+        ),
+        epilog=merge_epilog,
+        formatter_class=argparse.RawDescriptionHelpFormatter,
+    )
+
+    merge_parser.add_argument(
+        "source",
+        type=Path,
+        help="Archive of the sister wiki, produced by a full scrape",
+    )
+
+    merge_parser.add_argument(
+        "--wiki-id",
+        required=True,
+        metavar="ID",
+        help='Short identifier for the merged wiki (e.g., "classic")',
+    )
+
+    merge_parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format for statistics (default: text)",
+    )
+
     # Note: set_defaults(func=...) is called in __main__.py to avoid circular imports
 
     return parser
@@ -2,6 +2,7 @@
 
 import json
 import logging
+import os
 import sys
 from argparse import Namespace
 from datetime import UTC, datetime
@@ -9,15 +10,21 @@ from typing import Dict
 
 from scraper.api.client import MediaWikiAPIClient
 from scraper.api.rate_limiter import RateLimiter
-from scraper.config import Config, ConfigError
+from scraper.config import CONFIG_ENV_VAR, Config, ConfigError
 from scraper.incremental.models import IncrementalStats
 from scraper.incremental.page_scraper import (
     FirstRunRequiresFullScrapeError,
     IncrementalPageScraper,
 )
 from scraper.orchestration.checkpoint import CheckpointManager
+from scraper.orchestration.estimator import ScrapeEstimator, ScrapePlan
 from scraper.orchestration.full_scraper import FullScraper, ScrapeResult
+from scraper.scrapers.html_scraper import PageHTMLScraper
+from scraper.scrapers.protection_scraper import PageProtectionScraper
+from scraper.storage.archive_meta import ArchiveMetaRepository
 from scraper.storage.database import Database
+from scraper.storage.lock import ArchiveLockedError
+from scraper.storage.wikis import merge_wiki
 
 logger = logging.getLogger(__name__)
 
@@ -71,10 +78,11 @@ def _load_config(args: Namespace) -> Config:
         SystemExit: If configuration loading or validation fails
     """
     # Load from file or use defaults
-    if args.config:
-        logger.info(f"Loading configuration from {args.config}")
+    config_path = args.config or os.environ.get(CONFIG_ENV_VAR)
+    if config_path:
+        logger.info(f"Loading configuration from {config_path}")
         try:
-            config = Config.from_yaml(args.config)
+            config = Config.from_yaml(config_path)
         except ConfigError as e:
             logger.error(f"Failed to load config: {e}")
             sys.exit(1)
@@ -82,6 +90,13 @@ def _load_config(args: Namespace) -> Config:
         logger.info("Using default configuration")
         config = Config()
 
+    # Environment variables override the file, CLI arguments override both
+    try:
+        config.apply_env()
+    except ConfigError as e:
+        logger.error(f"Failed to load config: {e}")
+        sys.exit(1)
+
     # Override config with CLI arguments
     if hasattr(args, "rate_limit"):
         config.scraper.rate_limit = args.rate_limit
@@ -101,14 +116,18 @@ def _load_config(args: Namespace) -> Config:
     return config
 
 
-def _create_database(config: Config) -> Database:
-    """Create and initialize database.
+def _create_database(config: Config, tool: str = "scraper") -> Database:
+    """Create and initialize database, holding its writer lock.
 
     Args:
         config: Configuration instance
+        tool: Name of the command, shown to other writers finding it locked
 
     Returns:
         Initialized Database instance
+
+    Raises:
+        ArchiveLockedError: If another writer holds the archive
     """
     db_path = config.storage.database_file
 
@@ -117,7 +136,12 @@ def _create_database(config: Config) -> Database:
 
     logger.info(f"Opening database: {db_path}")
     database = Database(str(db_path))
-    database.initialize_schema()
+    database.acquire_lock(tool)
+    try:
+        database.initialize_schema()
+    except Exception:
+        database.close()
+        raise
 
     return database
 
@@ -163,6 +187,39 @@ def _format_duration(seconds: float) -> str:
     return f"{seconds:.1f}s"
 
 
+def _format_size(num_bytes: int) -> str:
+    """Format byte count in human-readable units.
+
+    Args:
+        num_bytes: Size in bytes
+
+    Returns:
+        Formatted string (e.g., "1.5 GB")
+    """
+    size = float(num_bytes)
+    for unit in ("B", "KB", "MB", "GB"):
+        if size < 1024:
+            return f"{size:.1f} {unit}" if unit != "B" else f"{int(size)} B"
+        size /= 1024
+    return f"{size:.1f} TB"
+
+
+def _print_scrape_plan(plan: ScrapePlan) -> None:
+    """Print the estimates of a scrape plan.
+
+    Args:
+        plan: Plan to print
+    """
+    print(f"\nEstimated revisions: {_format_number(plan.revisions)}")
+    print(f"Estimated API calls: {_format_number(plan.total_requests)}")
+    for stage, count in plan.requests.items():
+        print(f"  {stage:12s}: {_format_number(count)}")
+    print(
+        f"Estimated duration: {_format_duration(plan.duration_seconds)} at {plan.rate_limit} req/sec"
+    )
+    print(f"Estimated database size: {_format_size(plan.output_bytes)}")
+
+
 def _prompt_resume(checkpoint_manager: CheckpointManager) -> bool:
     """Prompt user to resume from checkpoint.
 
@@ -408,6 +465,7 @@ def full_scrape_command(args: Namespace) -> int:
     Returns:
         Exit code (0 for success, non-zero for failure)
     """
+    database = None
     try:
         # Setup
         _setup_logging(args.log_level)
@@ -433,9 +491,43 @@ def full_scrape_command(args: Namespace) -> int:
         # Check for JSON output format
         output_json = hasattr(args, "format") and args.format == "json"
 
+        # Estimate check - a single siteinfo request, nothing discovered
+        if getattr(args, "estimate", False):
+            rate_limiter = RateLimiter(requests_per_second=config.scraper.rate_limit)
+            api_client = MediaWikiAPIClient(
+                base_url=config.wiki.base_url,
+                user_agent=config.scraper.user_agent,
+                timeout=config.scraper.timeout,
+                max_retries=config.scraper.max_retries,
+                rate_limiter=rate_limiter,
+            )
+            estimator = ScrapeEstimator(
+                api_client,
+                rate_limit=config.scraper.rate_limit,
+                capture_html=getattr(args, "capture_html", False),
+                capture_protection=getattr(args, "capture_protection", False),
+            )
+            plan = estimator.estimate(args.namespace)
+            if plan.pages == 0:
+                logger.error("Wiki did not report page statistics; use --dry-run")
+                return 1
+
+            if output_json:
+                print(json.dumps(plan.to_dict(), indent=2))
+                return 0
+
+            print(
+                f"Wiki reports {_format_number(plan.pages)} pages across all namespaces"
+            )
+            _print_scrape_plan(plan)
+            print("\nNOTE: Page counts cover every namespace; use --dry-run for")
+            print("      exact counts of the namespaces being scraped.")
+            return 0
+
         # Dry run check - handle before creating database
         if args.dry_run:
-            print("DRY RUN MODE: Will discover pages but not scrape revisions")
+            if not output_json:
+                print("DRY RUN MODE: Will discover pages but not scrape revisions")
 
             # Create only components needed for discovery (no database)
             rate_limiter = RateLimiter(requests_per_second=config.scraper.rate_limit)
@@ -456,13 +548,27 @@ def full_scrape_command(args: Namespace) -> int:
             discovery = PageDiscovery(api_client)
             pages = discovery.discover_all_pages(namespaces)
 
+            from collections import Counter
+
+            ns_counts = Counter(p.namespace for p in pages)
+
+            # Estimate requests, duration and size from the discovered pages
+            estimator = ScrapeEstimator(
+                api_client,
+                rate_limit=config.scraper.rate_limit,
+                capture_html=getattr(args, "capture_html", False),
+                capture_protection=getattr(args, "capture_protection", False),
+            )
+            plan = estimator.estimate(namespaces, page_counts=dict(ns_counts))
+
+            if output_json:
+                print(json.dumps(plan.to_dict(), indent=2))
+                return 0
+
             print("\nDRY RUN COMPLETE")
             print(f"Would scrape {_format_number(len(pages))} pages")
 
             # Show breakdown by namespace
-            from collections import Counter
-
-            ns_counts = Counter(p.namespace for p in pages)
             print("\nBreakdown by namespace:")
             for ns in sorted(ns_counts.keys()):
                 ns_name = NAMESPACE_NAMES.get(ns, str(ns))
@@ -470,17 +576,10 @@ def full_scrape_command(args: Namespace) -> int:
                     f"  {ns:2d} ({ns_name:12s}): {_format_number(ns_counts[ns])} pages"
                 )
 
-            # Estimate API calls and duration
-            estimated_calls = len(pages)  # Discovery + revision calls per page
-            estimated_duration = estimated_calls / config.scraper.rate_limit
+            _print_scrape_plan(plan)
 
-            print(f"\nEstimated API calls: {_format_number(estimated_calls)}")
-            print(
-                f"Estimated duration: {_format_duration(estimated_duration)} at {config.scraper.rate_limit} req/sec"
-            )
-
-            print("\nNOTE: Actual duration will be longer due to revision scraping")
-            print("      and may vary based on page complexity.")
+            print("\nNOTE: Revision counts and sizes are projected from the wiki's")
+            print("      statistics; actual figures vary with page history.")
 
             return 0
 
@@ -497,7 +596,7 @@ def full_scrape_command(args: Namespace) -> int:
                 return 0
 
         # Create components for actual scrape
-        database = _create_database(config)
+        database = _create_database(config, "scraper full")
 
         # Initialize checkpoint manager
         checkpoint_manager = CheckpointManager(config.storage.checkpoint_file)
@@ -531,6 +630,11 @@ def full_scrape_command(args: Namespace) -> int:
             rate_limiter=rate_limiter,
         )
 
+        # Record where this archive came from (attribution, interwiki links)
+        ArchiveMetaRepository(database).record_source(
+            api_client, config.wiki.base_url, config.wiki.api_path
+        )
+
         scraper = FullScraper(config, api_client, database, checkpoint_manager)
 
         # Determine namespaces
@@ -553,6 +657,29 @@ def full_scrape_command(args: Namespace) -> int:
             namespaces=namespaces, progress_callback=progress_callback, resume=resume
         )
 
+        # Optional stage: the wiki's own rendering of each latest revision
+        if getattr(args, "capture_html", False):
+            html_result = PageHTMLScraper(api_client, database).capture(
+                namespaces=namespaces, progress_callback=progress_callback
+            )
+            if not output_json:
+                print(
+                    f"Captured rendered HTML for {_format_number(html_result.captured)} pages"
+                    f" ({len(html_result.failed)} failed)"
+                )
+
+        # Optional stage: protection levels for rendering hints
+        if getattr(args, "capture_protection", False):
+            protection_result = PageProtectionScraper(api_client, database).capture(
+                namespaces=namespaces, progress_callback=progress_callback
+            )
+            if not output_json:
+                print(
+                    f"Captured protection for {_format_number(protection_result.pages)} pages"
+                    f" ({_format_number(protection_result.protected)} protected,"
+                    f" {len(protection_result.failed)} failed)"
+                )
+
         # Output results based on format
         if output_json:
             _output_full_scrape_json(result, database)
@@ -566,12 +693,18 @@ def full_scrape_command(args: Namespace) -> int:
             logger.warning(f"Scrape completed with {len(result.errors)} errors")
             return 1 if len(result.failed_pages) > result.pages_count * 0.1 else 0
 
+    except ArchiveLockedError as e:
+        logger.error(str(e))
+        return 1
     except KeyboardInterrupt:
         logger.info("Scrape interrupted by user")
         return 130
     except Exception as e:
         logger.error(f"Full scrape failed: {e}", exc_info=True)
         return 1
+    finally:
+        if database is not None:
+            database.close()
 
 
 def incremental_scrape_command(args: Namespace) -> int:
@@ -583,6 +716,7 @@ def incremental_scrape_command(args: Namespace) -> int:
     Returns:
         Exit code (0 for success, non-zero for failure)
     """
+    database = None
     try:
         # Setup
         _setup_logging(args.log_level)
@@ -598,7 +732,7 @@ def incremental_scrape_command(args: Namespace) -> int:
             return 1
 
         # Create components
-        database = _create_database(config)
+        database = _create_database(config, "scraper incremental")
         rate_limiter = RateLimiter(requests_per_second=config.scraper.rate_limit)
         api_client = MediaWikiAPIClient(
             base_url=config.wiki.base_url,
@@ -623,6 +757,30 @@ def incremental_scrape_command(args: Namespace) -> int:
         # Run incremental scrape
         stats = scraper.scrape_incremental()
 
+        # Optional stage: refresh the rendered HTML of pages that changed
+        if getattr(args, "capture_html", False):
+            html_result = PageHTMLScraper(api_client, database).capture(
+                namespaces=args.namespace
+            )
+            if not output_json:
+                print(
+                    f"Captured rendered HTML for {_format_number(html_result.captured)} pages"
+                    f" ({len(html_result.failed)} failed)"
+                )
+
+        # Optional stage: protection isn't tracked by recent changes, so
+        # every page is refreshed
+        if getattr(args, "capture_protection", False):
+            protection_result = PageProtectionScraper(api_client, database).capture(
+                namespaces=args.namespace
+            )
+            if not output_json:
+                print(
+                    f"Captured protection for {_format_number(protection_result.pages)} pages"
+                    f" ({_format_number(protection_result.protected)} protected,"
+                    f" {len(protection_result.failed)} failed)"
+                )
+
         # Output results based on format
         if output_json:
             _output_incremental_scrape_json(stats)
@@ -636,9 +794,69 @@ def incremental_scrape_command(args: Namespace) -> int:
         print(f"\nERROR: {e}")
         print("Run 'scraper full' first to create baseline.")
         return 1
+    except ArchiveLockedError as e:
+        logger.error(str(e))
+        return 1
     except KeyboardInterrupt:
         logger.info("Scrape interrupted by user")
         return 130
     except Exception as e:
         logger.error(f"Incremental scrape failed: {e}", exc_info=True)
         return 1
+    finally:
+        if database is not None:
+            database.close()
+
+
+def merge_wiki_command(args: Namespace) -> int:
+    """Execute merge-wiki command.
+
+    Args:
+        args: Parsed command-line arguments
+
+    Returns:
+        Exit code (0 for success, non-zero for failure)
+    """
+    try:
+        # Setup
+        _setup_logging(args.log_level)
+        config = _load_config(args)
+
+        if not args.source.exists():
+            logger.error(f"Source archive not found: {args.source}")
+            return 1
+
+        database = _create_database(config, "scraper merge-wiki")
+        try:
+            result = merge_wiki(database, str(args.source), args.wiki_id)
+        finally:
+            database.close()
+
+        if getattr(args, "format", "text") == "json":
+            output = {
+                "wiki_id": result.wiki.wiki_id,
+                "name": result.wiki.name,
+                "base_url": result.wiki.base_url,
+                "id_offset": result.wiki.id_offset,
+                "pages": result.pages,
+                "revisions": result.revisions,
+                "files": result.files,
+                "interwiki_links": result.interwiki_links,
+            }
+            print(json.dumps(output, indent=2))
+        else:
+            print(f"Merged {result.wiki.name} as '{result.wiki.wiki_id}'")
+            print(f"  Pages:     {_format_number(result.pages)}")
+            print(f"  Revisions: {_format_number(result.revisions)}")
+            print(f"  Files:     {_format_number(result.files)} new")
+            print(f"  Interwiki: {_format_number(result.interwiki_links)} links")
+            print(f"  ID offset: {_format_number(result.wiki.id_offset)}")
+
+        return 0
+
+    except (ValueError, ArchiveLockedError) as e:
+        logger.error(str(e))
+        return 1
+    except Exception as e:
+        logger.error(f"Merge failed: {e}", exc_info=True)
+        return 1
@@ -18,17 +18,40 @@ Example:
     >>> # Use defaults
     >>> config = Config()
     >>> config.validate()
+
+The file format and IROWIKI_* environment variables are shared with the Go
+tools (sdk/config), so one config.yaml configures the scraper, server and CLI.
 """
 
 import logging
+import os
 from dataclasses import dataclass, field
 from pathlib import Path
-from typing import Union
+from typing import Callable, Dict, Mapping, Optional, Tuple, Union
 
 import yaml
 
 logger = logging.getLogger(__name__)
 
+# Environment variable naming the config file
+CONFIG_ENV_VAR = "IROWIKI_CONFIG"
+
+# Environment variables overriding settings: (section, field, parser)
+ENV_VARS: Dict[str, Tuple[str, str, Callable[[str], object]]] = {
+    "IROWIKI_WIKI_BASE_URL": ("wiki", "base_url", str),
+    "IROWIKI_WIKI_API_PATH": ("wiki", "api_path", str),
+    "IROWIKI_SCRAPER_RATE_LIMIT": ("scraper", "rate_limit", float),
+    "IROWIKI_SCRAPER_TIMEOUT": ("scraper", "timeout", int),
+    "IROWIKI_SCRAPER_MAX_RETRIES": ("scraper", "max_retries", int),
+    "IROWIKI_SCRAPER_USER_AGENT": ("scraper", "user_agent", str),
+    "IROWIKI_STORAGE_DATA_DIR": ("storage", "data_dir", Path),
+    "IROWIKI_STORAGE_CHECKPOINT_FILE": ("storage", "checkpoint_file", Path),
+    "IROWIKI_DB": ("storage", "database_file", Path),
+    "IROWIKI_STORAGE_DATABASE_FILE": ("storage", "database_file", Path),
+    "IROWIKI_LOG_LEVEL": ("logging", "level", str.upper),
+    "IROWIKI_LOG_FILE": ("logging", "log_file", Path),
+}
+
 
 class ConfigError(Exception):
     """Raised when configuration is invalid or cannot be loaded."""
@@ -220,6 +243,11 @@ class Config:
         storage_data = data.get("storage", {})
         logging_data = data.get("logging", {})
 
+        # The Go tools' top-level db is the archive when it's a local path
+        db = data.get("db")
+        if isinstance(db, str) and "://" not in db:
+            storage_data.setdefault("database_file", str(Path(db).expanduser()))
+
         # Convert string paths to Path objects in storage section
         if "data_dir" in storage_data and isinstance(storage_data["data_dir"], str):
             storage_data["data_dir"] = Path(storage_data["data_dir"])
@@ -252,6 +280,31 @@ class Config:
         config.validate()
         return config
 
+    def apply_env(self, environ: Optional[Mapping[str, str]] = None) -> None:
+        """
+        Override settings with IROWIKI_* environment variables.
+
+        Empty variables are ignored. IROWIKI_STORAGE_DATABASE_FILE wins over
+        IROWIKI_DB, which is ignored when it holds a database URL.
+
+        Args:
+            environ: Environment to read (default: os.environ)
+
+        Raises:
+            ConfigError: If a variable can't be parsed
+        """
+        if environ is None:
+            environ = os.environ
+
+        for name, (section, key, parse) in ENV_VARS.items():
+            value = environ.get(name)
+            if not value or (name == "IROWIKI_DB" and "://" in value):
+                continue
+            try:
+                setattr(getattr(self, section), key, parse(value))
+            except ValueError as e:
+                raise ConfigError(f"Invalid {name}={value!r}: {e}") from e
+
     def validate(self) -> None:
         """
         Validate configuration values.
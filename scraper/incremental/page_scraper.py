@@ -137,7 +137,11 @@ class IncrementalPageScraper:
                 f"moved={len(change_set.moved_pages)}"
             )
 
-            # Process all change types
+            # Process all change types; pages with new activity were
+            # restored if they had been deleted
+            self._clear_deleted_pages(
+                change_set.new_page_ids | change_set.modified_page_ids
+            )
             stats.pages_new = self._process_new_pages(change_set.new_page_ids)
             modified_stats = self._process_modified_pages(change_set.modified_page_ids)
             stats.pages_modified = modified_stats[0]
@@ -146,6 +150,9 @@ class IncrementalPageScraper:
                 change_set.deleted_page_ids
             )
             stats.pages_moved = self._process_moved_pages(change_set.moved_pages)
+            self.page_repo.update_redirect_targets(
+                list(change_set.new_page_ids | change_set.modified_page_ids)
+            )
 
             # Process files
             file_changes = self.file_scraper.detect_file_changes()
@@ -280,25 +287,33 @@ class IncrementalPageScraper:
         """
         Mark pages as deleted.
 
-        Note: Does not delete from database, just marks as deleted.
-        Historical data is preserved.
+        Note: Does not delete from database, just records a tombstone in
+        deleted_pages. Historical data is preserved. Pages that were never
+        archived have nothing to mark.
 
         Args:
             page_ids: Set of deleted page IDs
 
         Returns:
-            Number of pages marked as deleted
+            Number of deleted pages processed
         """
         if not page_ids:
             return 0
 
         logger.info(f"Processing {len(page_ids)} deleted pages")
 
+        conn = self.db.get_connection()
         processed = 0
         for page_id in page_ids:
             try:
-                # TODO: Add is_deleted column to pages table in future
-                # For now, just log the deletion
+                conn.execute(
+                    """
+                    INSERT OR IGNORE INTO deleted_pages (page_id)
+                    SELECT page_id FROM pages WHERE page_id = ?
+                    """,
+                    (page_id,),
+                )
+                conn.commit()
                 logger.info(f"Page {page_id} was deleted")
                 processed += 1
             except Exception as e:
@@ -306,6 +321,23 @@ class IncrementalPageScraper:
 
         return processed
 
+    def _clear_deleted_pages(self, page_ids: Set[int]) -> None:
+        """
+        Remove tombstones of pages that were restored on the wiki.
+
+        Args:
+            page_ids: Set of page IDs with new activity
+        """
+        if not page_ids:
+            return
+
+        conn = self.db.get_connection()
+        conn.executemany(
+            "DELETE FROM deleted_pages WHERE page_id = ?",
+            [(page_id,) for page_id in page_ids],
+        )
+        conn.commit()
+
     def _process_moved_pages(self, moved_pages: list[MovedPage]) -> int:
         """
         Update titles for moved/renamed pages.
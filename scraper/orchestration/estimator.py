@@ -0,0 +1,254 @@
+"""Cost estimates for scrapes and exports.
+
+This module predicts what a full scrape or an XML export will cost before it
+is started: the API requests a scrape makes, how long they take at the
+configured rate limit, and how much data either one writes. Scrape estimates
+come from the wiki's siteinfo statistics (one API request), or from the page
+counts of a dry-run discovery; export estimates come from archive counts.
+
+Example:
+    >>> estimator = ScrapeEstimator(api_client, rate_limit=2.0)
+    >>> plan = estimator.estimate()
+    >>> print(f"{plan.total_requests} requests, {plan.duration_seconds / 3600:.1f}h")
+"""
+
+import logging
+import math
+from dataclasses import dataclass, field
+from typing import Any, Dict, List, Optional
+
+from scraper.scrapers.page_scraper import PageDiscovery
+from scraper.storage.database import Database
+
+logger = logging.getLogger(__name__)
+
+# Items per request of the list and revision queries (the API maximum)
+API_BATCH_SIZE = 500
+
+# Pages per protection request (see protection_scraper.BATCH_SIZE)
+PROTECTION_BATCH_SIZE = 50
+
+# Rough average sizes on iRO Wiki, used when the archive can't tell
+AVERAGE_REVISION_BYTES = 4096
+AVERAGE_HTML_BYTES = 24576
+
+# Database bytes per revision and page beyond their content (metadata,
+# indexes, full-text index)
+REVISION_OVERHEAD_BYTES = 300
+PAGE_OVERHEAD_BYTES = 200
+
+# XML bytes per exported revision and page beyond their content
+XML_REVISION_OVERHEAD_BYTES = 450
+XML_PAGE_OVERHEAD_BYTES = 120
+
+
+@dataclass
+class ScrapePlan:
+    """Predicted cost of a full scrape.
+
+    Attributes:
+        pages: Pages to scrape
+        revisions: Revisions expected across those pages
+        requests: API requests by stage (discover, revisions, html, protection)
+        rate_limit: Requests per second the scrape is limited to
+        output_bytes: Expected size of the database
+        source: Where the page count came from ("siteinfo" or "discovery")
+        namespaces: Namespaces covered
+    """
+
+    pages: int
+    revisions: int
+    requests: Dict[str, int]
+    rate_limit: float
+    output_bytes: int
+    source: str
+    namespaces: List[int] = field(default_factory=list)
+
+    @property
+    def total_requests(self) -> int:
+        """Get the total number of API requests."""
+        return sum(self.requests.values())
+
+    @property
+    def duration_seconds(self) -> float:
+        """Get the time the requests take at the rate limit."""
+        if self.rate_limit <= 0:
+            return 0.0
+        return self.total_requests / self.rate_limit
+
+    def to_dict(self) -> Dict[str, Any]:
+        """Convert to dictionary for JSON output."""
+        return {
+            "pages": self.pages,
+            "revisions": self.revisions,
+            "requests": dict(self.requests),
+            "total_requests": self.total_requests,
+            "rate_limit": self.rate_limit,
+            "duration_seconds": round(self.duration_seconds, 1),
+            "output_bytes": self.output_bytes,
+            "source": self.source,
+            "namespaces": list(self.namespaces),
+        }
+
+
+@dataclass
+class ExportPlan:
+    """Predicted cost of an XML export.
+
+    Attributes:
+        pages: Pages to export
+        revisions: Revisions to export
+        content_bytes: Size of the revisions' wikitext
+        output_bytes: Expected size of the XML file
+    """
+
+    pages: int
+    revisions: int
+    content_bytes: int
+    output_bytes: int
+
+    def to_dict(self) -> Dict[str, Any]:
+        """Convert to dictionary for JSON output."""
+        return {
+            "pages": self.pages,
+            "revisions": self.revisions,
+            "content_bytes": self.content_bytes,
+            "output_bytes": self.output_bytes,
+        }
+
+
+class ScrapeEstimator:
+    """
+    Estimates the cost of a full scrape.
+
+    The wiki's statistics count pages in every namespace, so estimates made
+    without page counts are upper bounds for scrapes of some namespaces.
+    """
+
+    def __init__(
+        self,
+        api_client,
+        rate_limit: float,
+        capture_html: bool = False,
+        capture_protection: bool = False,
+    ):
+        """
+        Initialize estimator.
+
+        Args:
+            api_client: MediaWiki API client
+            rate_limit: Requests per second the scrape will be limited to
+            capture_html: Whether the scrape captures rendered HTML
+            capture_protection: Whether the scrape captures protection levels
+        """
+        self.api = api_client
+        self.rate_limit = rate_limit
+        self.capture_html = capture_html
+        self.capture_protection = capture_protection
+
+    def fetch_statistics(self) -> Optional[Dict[str, int]]:
+        """
+        Fetch the wiki's siteinfo statistics.
+
+        Returns:
+            Statistics (pages, articles, edits, images, ...), or None if the
+            wiki doesn't report them
+        """
+        try:
+            data = self.api.query({"meta": "siteinfo", "siprop": "statistics"})
+        except Exception as e:
+            logger.warning(f"Failed to fetch siteinfo statistics: {e}")
+            return None
+
+        stats = data.get("query", {}).get("statistics") if isinstance(data, dict) else None
+        if not isinstance(stats, dict):
+            return None
+        return {k: v for k, v in stats.items() if isinstance(v, int)}
+
+    def estimate(
+        self,
+        namespaces: Optional[List[int]] = None,
+        page_counts: Optional[Dict[int, int]] = None,
+    ) -> ScrapePlan:
+        """
+        Estimate a full scrape.
+
+        Args:
+            namespaces: Namespaces to scrape (default: PageDiscovery's defaults)
+            page_counts: Pages per namespace from a discovery, if one was run
+
+        Returns:
+            The predicted plan
+        """
+        if namespaces is None:
+            namespaces = list(page_counts) if page_counts else PageDiscovery.DEFAULT_NAMESPACES
+        stats = self.fetch_statistics() or {}
+
+        if page_counts is not None:
+            pages = sum(page_counts.get(ns, 0) for ns in namespaces)
+            source = "discovery"
+            discover = sum(
+                max(1, math.ceil(page_counts.get(ns, 0) / API_BATCH_SIZE))
+                for ns in namespaces
+            )
+        else:
+            pages = stats.get("pages", 0)
+            source = "siteinfo"
+            discover = len(namespaces) + math.ceil(pages / API_BATCH_SIZE)
+
+        # Scale the wiki's edits per page to the pages scraped
+        edits_per_page = 1.0
+        if stats.get("pages") and stats.get("edits"):
+            edits_per_page = max(1.0, stats["edits"] / stats["pages"])
+        revisions = round(pages * edits_per_page)
+
+        requests = {
+            "discover": discover,
+            # One request per page, plus continuations for long histories
+            "revisions": pages + max(0, math.ceil((revisions - pages) / API_BATCH_SIZE)),
+        }
+        output_bytes = (
+            revisions * (AVERAGE_REVISION_BYTES + REVISION_OVERHEAD_BYTES)
+            + pages * PAGE_OVERHEAD_BYTES
+        )
+        if self.capture_html:
+            requests["html"] = pages
+            output_bytes += pages * AVERAGE_HTML_BYTES
+        if self.capture_protection:
+            requests["protection"] = math.ceil(pages / PROTECTION_BATCH_SIZE)
+
+        return ScrapePlan(
+            pages=pages,
+            revisions=revisions,
+            requests=requests,
+            rate_limit=self.rate_limit,
+            output_bytes=output_bytes,
+            source=source,
+            namespaces=list(namespaces),
+        )
+
+
+def estimate_export(database: Database) -> ExportPlan:
+    """
+    Estimate an XML export of an archive from its counts.
+
+    Args:
+        database: Archive to export
+
+    Returns:
+        The predicted plan
+    """
+    conn = database.get_connection()
+    pages = conn.execute("SELECT COUNT(*) FROM pages").fetchone()[0]
+    revisions, content_bytes = conn.execute(
+        "SELECT COUNT(*), COALESCE(SUM(size), 0) FROM revisions"
+    ).fetchone()
+
+    return ExportPlan(
+        pages=pages,
+        revisions=revisions,
+        content_bytes=content_bytes,
+        output_bytes=content_bytes
+        + revisions * XML_REVISION_OVERHEAD_BYTES
+        + pages * XML_PAGE_OVERHEAD_BYTES,
+    )
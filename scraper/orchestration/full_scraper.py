@@ -183,6 +183,11 @@ class FullScraper:
                 f"Scraped {result.revisions_count} revisions for {result.pages_count} pages"
             )
 
+            # Phase 3: Record where redirects point, now that their
+            # wikitext is stored
+            redirects = self.page_repo.update_redirect_targets()
+            logger.info(f"Recorded targets of {redirects} redirects")
+
             # Clear checkpoint on successful completion
             if self.checkpoint and result.success:
                 logger.info("Scrape completed successfully, clearing checkpoint")
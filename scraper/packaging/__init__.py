@@ -8,6 +8,7 @@ from scraper.packaging.checksums import (
 from scraper.packaging.compression import compress_directory, split_archive
 from scraper.packaging.manifest import ManifestGenerator
 from scraper.packaging.release import ReleaseBuilder
+from scraper.packaging.torrent import create_torrent, pin_to_ipfs
 from scraper.packaging.verify import verify_release
 
 __all__ = [
@@ -19,4 +20,6 @@ __all__ = [
     "verify_checksums",
     "ManifestGenerator",
     "verify_release",
+    "create_torrent",
+    "pin_to_ipfs",
 ]
@@ -5,13 +5,15 @@ the complete release packaging workflow.
 """
 
 from pathlib import Path
-from typing import Optional
+from typing import List, Optional
 
 from scraper.export.xml_exporter import XMLExporter
+from scraper.orchestration.estimator import estimate_export
 from scraper.packaging.checksums import generate_checksums, write_checksums_file
 from scraper.packaging.compression import compress_directory, split_archive
 from scraper.packaging.manifest import ManifestGenerator
 from scraper.packaging.release import ReleaseBuilder
+from scraper.packaging.torrent import create_torrent
 from scraper.packaging.verify import verify_release
 from scraper.storage.database import Database
 
@@ -28,6 +30,9 @@ class PackagingConfig:
         compress: bool = True,
         split_large: bool = True,
         chunk_size_mb: int = 1900,
+        torrent: bool = False,
+        trackers: Optional[List[str]] = None,
+        web_seeds: Optional[List[str]] = None,
     ):
         """
         Initialize packaging configuration.
@@ -40,6 +45,9 @@ class PackagingConfig:
             compress: Whether to compress the release
             split_large: Whether to split large archives
             chunk_size_mb: Chunk size for splitting (MB)
+            torrent: Whether to create a .torrent for the archive
+            trackers: Tracker announce URLs for the torrent
+            web_seeds: HTTP URLs serving the archive, used as web seeds
         """
         self.database_path = database_path
         self.files_dir = files_dir
@@ -48,6 +56,9 @@ class PackagingConfig:
         self.compress = compress
         self.split_large = split_large
         self.chunk_size_mb = chunk_size_mb
+        self.torrent = torrent
+        self.trackers = trackers or []
+        self.web_seeds = web_seeds or []
 
 
 def package_release(config: PackagingConfig) -> dict:
@@ -66,6 +77,9 @@ def package_release(config: PackagingConfig) -> dict:
     9. Split if size > chunk_size (if enabled)
     10. Verify release
 
+    With config.torrent, a .torrent for the archive (or the release directory
+    when not compressed) is created next to it afterwards.
+
     Args:
         config: PackagingConfig with all settings
 
@@ -204,6 +218,25 @@ def package_release(config: PackagingConfig) -> dict:
             print(f"       • {error.message}")
     print()
 
+    # Optional: torrent for peer-to-peer distribution
+    if config.torrent:
+        print("Creating torrent...")
+        torrent = create_torrent(
+            archive_path or release_dir,
+            trackers=config.trackers,
+            web_seeds=config.web_seeds,
+            comment=f"iRO Wiki Archive {config.version}",
+            show_progress=True,
+        )
+        results["torrent"] = {
+            "torrent_path": str(torrent["torrent_path"]),
+            "info_hash": torrent["info_hash"],
+            "magnet_uri": torrent["magnet_uri"],
+        }
+        print(f"  ✓ Torrent: {torrent['torrent_path']}")
+        print(f"     - Info hash: {torrent['info_hash']}")
+        print()
+
     print("=" * 70)
     if verification.is_valid:
         print("✓ RELEASE PACKAGING COMPLETE")
@@ -214,6 +247,8 @@ def package_release(config: PackagingConfig) -> dict:
     print(f"Release directory: {release_dir}")
     if archive_path:
         print(f"Archive: {archive_path}")
+    if "torrent" in results:
+        print(f"Magnet: {results['torrent']['magnet_uri']}")
     print()
 
     return results
@@ -263,6 +298,28 @@ def main():
         default=1900,
         help="Chunk size in MB for splitting (default: 1900)",
     )
+    parser.add_argument(
+        "--torrent",
+        action="store_true",
+        help="Create a .torrent for the archive",
+    )
+    parser.add_argument(
+        "--tracker",
+        action="append",
+        default=[],
+        help="Tracker announce URL for --torrent (repeatable)",
+    )
+    parser.add_argument(
+        "--web-seed",
+        action="append",
+        default=[],
+        help="HTTP URL serving the archive, used as a web seed (repeatable)",
+    )
+    parser.add_argument(
+        "--estimate",
+        action="store_true",
+        help="Print the expected size of the XML export as JSON and exit",
+    )
 
     args = parser.parse_args()
 
@@ -271,6 +328,14 @@ def main():
         print(f"Error: Database file not found: {args.database}", file=sys.stderr)
         sys.exit(1)
 
+    if args.estimate:
+        import json
+
+        plan = estimate_export(Database(str(args.database), read_only=True)).to_dict()
+        plan["database_bytes"] = args.database.stat().st_size
+        print(json.dumps(plan, indent=2))
+        sys.exit(0)
+
     if args.files and not args.files.exists():
         print(f"Error: Files directory not found: {args.files}", file=sys.stderr)
         sys.exit(1)
@@ -284,6 +349,9 @@ def main():
         compress=not args.no_compress,
         split_large=not args.no_split,
         chunk_size_mb=args.chunk_size,
+        torrent=args.torrent,
+        trackers=args.tracker,
+        web_seeds=args.web_seed,
     )
 
     # Package release
@@ -0,0 +1,430 @@
+"""BitTorrent and IPFS publishing for release archives.
+
+Archives run to several GB, so the community shares them over BitTorrent
+and IPFS rather than one download server. This module creates a .torrent
+for an exported archive file (or a release directory) and can add it to an
+IPFS node through the Kubo HTTP API.
+"""
+
+import hashlib
+import math
+import os
+import uuid
+from pathlib import Path
+from typing import Dict, Iterator, List, Optional, Tuple
+from urllib.parse import quote
+
+# Piece length bounds; sizes between them are picked to give about
+# TARGET_PIECES pieces, which keeps .torrent files small for multi-GB archives
+MIN_PIECE_LENGTH = 256 * 1024
+MAX_PIECE_LENGTH = 16 * 1024 * 1024
+TARGET_PIECES = 1500
+
+DEFAULT_IPFS_API = "http://127.0.0.1:5001"
+
+
+def bencode(value) -> bytes:
+    """
+    Encode a value in the BitTorrent bencoding.
+
+    Args:
+        value: int, str, bytes, list or dict (with str or bytes keys)
+
+    Returns:
+        Bencoded bytes, with dict keys sorted as the specification requires
+
+    Raises:
+        TypeError: If value contains an unsupported type
+    """
+    if isinstance(value, bool):
+        raise TypeError("Cannot bencode bool")
+    if isinstance(value, int):
+        return b"i%de" % value
+    if isinstance(value, str):
+        value = value.encode("utf-8")
+    if isinstance(value, bytes):
+        return b"%d:%s" % (len(value), value)
+    if isinstance(value, list):
+        return b"l" + b"".join(bencode(item) for item in value) + b"e"
+    if isinstance(value, dict):
+        items = sorted(
+            (k.encode("utf-8") if isinstance(k, str) else k, v)
+            for k, v in value.items()
+        )
+        return b"d" + b"".join(bencode(k) + bencode(v) for k, v in items) + b"e"
+    raise TypeError(f"Cannot bencode {type(value).__name__}")
+
+
+def choose_piece_length(total_size: int) -> int:
+    """
+    Choose a power-of-two piece length for content of total_size bytes.
+
+    Args:
+        total_size: Total size of the torrent content in bytes
+
+    Returns:
+        Piece length in bytes, between MIN_PIECE_LENGTH and MAX_PIECE_LENGTH
+    """
+    if total_size <= 0:
+        return MIN_PIECE_LENGTH
+    target = total_size / TARGET_PIECES
+    length = 2 ** math.ceil(math.log2(max(target, 1)))
+    return max(MIN_PIECE_LENGTH, min(MAX_PIECE_LENGTH, length))
+
+
+def compute_piece_hashes(
+    files: List[Path],
+    piece_length: int,
+    show_progress: bool = False,
+) -> bytes:
+    """
+    Compute the SHA-1 piece hashes of files read as one stream.
+
+    Args:
+        files: Files in torrent order; pieces span file boundaries
+        piece_length: Piece length in bytes
+        show_progress: Whether to show progress bar
+
+    Returns:
+        Concatenated 20-byte SHA-1 digests, one per piece
+
+    Raises:
+        ValueError: If piece_length is not positive
+        OSError: If a file read fails
+    """
+    if piece_length <= 0:
+        raise ValueError(f"Piece length must be positive, got {piece_length}")
+
+    progress = None
+    if show_progress:
+        from tqdm import tqdm
+
+        total = sum(f.stat().st_size for f in files)
+        progress = tqdm(total=total, desc="Hashing pieces", unit="B", unit_scale=True)
+
+    pieces = []
+    piece = hashlib.sha1()
+    filled = 0
+    for file_path in files:
+        with open(file_path, "rb") as f:
+            while True:
+                chunk = f.read(piece_length - filled)
+                if not chunk:
+                    break
+                piece.update(chunk)
+                filled += len(chunk)
+                if progress:
+                    progress.update(len(chunk))
+                if filled == piece_length:
+                    pieces.append(piece.digest())
+                    piece = hashlib.sha1()
+                    filled = 0
+    if filled:
+        pieces.append(piece.digest())
+
+    if progress:
+        progress.close()
+
+    return b"".join(pieces)
+
+
+def create_torrent(
+    path: Path,
+    output_path: Optional[Path] = None,
+    trackers: Optional[List[str]] = None,
+    web_seeds: Optional[List[str]] = None,
+    piece_length: Optional[int] = None,
+    comment: Optional[str] = None,
+    private: bool = False,
+    show_progress: bool = False,
+) -> Dict[str, any]:
+    """
+    Create a .torrent file for an archive file or a release directory.
+
+    Args:
+        path: Archive file (single-file torrent) or directory (multi-file torrent)
+        output_path: Where to write the .torrent (default: <path>.torrent)
+        trackers: Announce URLs; the first is the primary tracker
+        web_seeds: HTTP URLs serving the same content (BEP 19), such as a
+            GitHub release asset or an "irowiki serve --download" mirror
+        piece_length: Piece length in bytes (default: chosen from the size)
+        comment: Free-form comment stored in the torrent
+        private: Whether to mark the torrent private (tracker-only peers)
+        show_progress: Whether to show progress bar
+
+    Returns:
+        Dictionary with torrent details:
+            - torrent_path: Path to the created .torrent file
+            - info_hash: BitTorrent v1 info hash (hex string)
+            - magnet_uri: Magnet link including trackers and web seeds
+            - piece_length: Piece length in bytes
+            - piece_count: Number of pieces
+            - total_size: Size of the content in bytes
+            - file_count: Number of files in the torrent
+
+    Raises:
+        FileNotFoundError: If path doesn't exist
+        ValueError: If path is an empty directory
+        OSError: If file read or write fails
+    """
+    if not path.exists():
+        raise FileNotFoundError(f"Path not found: {path}")
+
+    files = _torrent_files(path)
+    if not files:
+        raise ValueError(f"Directory is empty: {path}")
+
+    total_size = sum(f.stat().st_size for f, _ in files)
+    if piece_length is None:
+        piece_length = choose_piece_length(total_size)
+
+    pieces = compute_piece_hashes(
+        [f for f, _ in files], piece_length, show_progress=show_progress
+    )
+
+    info = {
+        "name": path.name,
+        "piece length": piece_length,
+        "pieces": pieces,
+    }
+    if path.is_dir():
+        info["files"] = [
+            {"length": f.stat().st_size, "path": list(parts)} for f, parts in files
+        ]
+    else:
+        info["length"] = total_size
+    if private:
+        info["private"] = 1
+
+    metainfo = {
+        "info": info,
+        "created by": "iRO Wiki Scraper",
+        "creation date": int(path.stat().st_mtime),
+    }
+    if trackers:
+        metainfo["announce"] = trackers[0]
+        metainfo["announce-list"] = [[t] for t in trackers]
+    if web_seeds:
+        metainfo["url-list"] = list(web_seeds)
+    if comment:
+        metainfo["comment"] = comment
+
+    if output_path is None:
+        output_path = path.parent / f"{path.name}.torrent"
+    output_path.write_bytes(bencode(metainfo))
+
+    info_hash = hashlib.sha1(bencode(info)).hexdigest()
+    return {
+        "torrent_path": output_path,
+        "info_hash": info_hash,
+        "magnet_uri": _magnet_uri(info_hash, path.name, trackers, web_seeds),
+        "piece_length": piece_length,
+        "piece_count": len(pieces) // 20,
+        "total_size": total_size,
+        "file_count": len(files),
+    }
+
+
+def pin_to_ipfs(
+    path: Path,
+    api_url: str = DEFAULT_IPFS_API,
+    timeout: int = 3600,
+) -> Dict[str, any]:
+    """
+    Add and pin an archive file on an IPFS node through the Kubo HTTP API.
+
+    The file is streamed, so multi-GB archives are not read into memory.
+
+    Args:
+        path: Archive file to add
+        api_url: Base URL of the node's RPC API
+        timeout: Request timeout in seconds
+
+    Returns:
+        Dictionary with:
+            - cid: Content identifier (CIDv1) of the file
+            - size: Size reported by the node
+            - gateway_url: Public gateway URL for the file
+
+    Raises:
+        FileNotFoundError: If path doesn't exist
+        ValueError: If path is not a file
+        requests.RequestException: If the node can't be reached or fails
+    """
+    import requests
+
+    if not path.exists():
+        raise FileNotFoundError(f"File not found: {path}")
+    if not path.is_file():
+        raise ValueError(f"Path must be a file: {path}")
+
+    boundary = uuid.uuid4().hex
+    response = requests.post(
+        f"{api_url.rstrip('/')}/api/v0/add",
+        params={"pin": "true", "cid-version": "1", "quieter": "true"},
+        data=_multipart_stream(path, boundary),
+        headers={"Content-Type": f"multipart/form-data; boundary={boundary}"},
+        timeout=timeout,
+    )
+    response.raise_for_status()
+
+    result = response.json()
+    cid = result["Hash"]
+    return {
+        "cid": cid,
+        "size": int(result.get("Size", path.stat().st_size)),
+        "gateway_url": f"https://ipfs.io/ipfs/{cid}?filename={quote(path.name)}",
+    }
+
+
+def _torrent_files(path: Path) -> List[Tuple[Path, Tuple[str, ...]]]:
+    """
+    List the files of a torrent with their path components.
+
+    Args:
+        path: Archive file or release directory
+
+    Returns:
+        List of (file path, path components relative to path), sorted so the
+        piece layout is reproducible
+    """
+    if path.is_file():
+        return [(path, (path.name,))]
+
+    files = []
+    for root, _, names in os.walk(path):
+        for name in names:
+            file_path = Path(root) / name
+            files.append((file_path, file_path.relative_to(path).parts))
+    files.sort(key=lambda item: item[1])
+    return files
+
+
+def _magnet_uri(
+    info_hash: str,
+    name: str,
+    trackers: Optional[List[str]],
+    web_seeds: Optional[List[str]],
+) -> str:
+    """
+    Build a magnet link for a torrent.
+
+    Args:
+        info_hash: Info hash (hex string)
+        name: Display name
+        trackers: Announce URLs
+        web_seeds: Web seed URLs
+
+    Returns:
+        Magnet URI
+    """
+    parts = [f"xt=urn:btih:{info_hash}", f"dn={quote(name)}"]
+    parts.extend(f"tr={quote(t, safe='')}" for t in trackers or [])
+    parts.extend(f"ws={quote(w, safe='')}" for w in web_seeds or [])
+    return "magnet:?" + "&".join(parts)
+
+
+def _multipart_stream(path: Path, boundary: str) -> Iterator[bytes]:
+    """
+    Stream a file as a multipart/form-data body.
+
+    Args:
+        path: File to send
+        boundary: Multipart boundary
+
+    Yields:
+        Chunks of the request body
+    """
+    yield (
+        f"--{boundary}\r\n"
+        f'Content-Disposition: form-data; name="file"; filename="{quote(path.name)}"\r\n'
+        "Content-Type: application/octet-stream\r\n\r\n"
+    ).encode("utf-8")
+    with open(path, "rb") as f:
+        while True:
+            chunk = f.read(1024 * 1024)
+            if not chunk:
+                break
+            yield chunk
+    yield f"\r\n--{boundary}--\r\n".encode("utf-8")
+
+
+def main():
+    """Command-line interface for publishing a release archive."""
+    import argparse
+    import sys
+
+    parser = argparse.ArgumentParser(
+        description="Create a .torrent for a release archive and optionally pin it to IPFS"
+    )
+    parser.add_argument(
+        "path",
+        type=Path,
+        help="Archive file or release directory",
+    )
+    parser.add_argument(
+        "--output",
+        type=Path,
+        help="Output .torrent path (default: <path>.torrent)",
+    )
+    parser.add_argument(
+        "--tracker",
+        action="append",
+        default=[],
+        help="Tracker announce URL (repeatable)",
+    )
+    parser.add_argument(
+        "--web-seed",
+        action="append",
+        default=[],
+        help="HTTP URL serving the same file, e.g. the GitHub release asset (repeatable)",
+    )
+    parser.add_argument(
+        "--comment",
+        help="Comment stored in the torrent",
+    )
+    parser.add_argument(
+        "--ipfs",
+        action="store_true",
+        help="Also add and pin the archive on an IPFS node",
+    )
+    parser.add_argument(
+        "--ipfs-api",
+        default=DEFAULT_IPFS_API,
+        help=f"IPFS node RPC API URL (default: {DEFAULT_IPFS_API})",
+    )
+
+    args = parser.parse_args()
+
+    if not args.path.exists():
+        print(f"Error: Path not found: {args.path}", file=sys.stderr)
+        sys.exit(1)
+
+    try:
+        torrent = create_torrent(
+            args.path,
+            output_path=args.output,
+            trackers=args.tracker,
+            web_seeds=args.web_seed,
+            comment=args.comment,
+            show_progress=True,
+        )
+        print(f"Torrent: {torrent['torrent_path']}")
+        print(f"  Info hash: {torrent['info_hash']}")
+        print(
+            f"  Pieces: {torrent['piece_count']} x "
+            f"{torrent['piece_length'] // 1024} KiB"
+        )
+        print(f"  Magnet: {torrent['magnet_uri']}")
+
+        if args.ipfs:
+            pinned = pin_to_ipfs(args.path, api_url=args.ipfs_api)
+            print(f"IPFS: {pinned['cid']}")
+            print(f"  Gateway: {pinned['gateway_url']}")
+    except Exception as e:
+        print(f"Error: {e}", file=sys.stderr)
+        sys.exit(1)
+
+
+if __name__ == "__main__":
+    main()
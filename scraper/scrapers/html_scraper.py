@@ -0,0 +1,168 @@
+"""Rendered HTML capture functionality.
+
+This module provides the PageHTMLScraper class, an optional stage that
+fetches the wiki's own rendering (action=parse) of each page's latest
+revision into the page_html table. Consumers that don't trust a
+from-scratch wikitext renderer for complex templates can serve this HTML
+instead.
+"""
+
+import logging
+from dataclasses import dataclass, field
+from typing import Any, Callable, List, Optional, Tuple
+
+from scraper.api.client import MediaWikiAPIClient
+from scraper.storage.database import Database
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class HTMLCaptureResult:
+    """Statistics from an HTML capture run."""
+
+    captured: int = 0
+    failed: List[int] = field(default_factory=list)
+
+
+class PageHTMLScraper:
+    """Captures wiki-rendered HTML for the latest revision of each page.
+
+    Pages whose stored HTML already matches their latest revision are
+    skipped, so running the stage after every scrape only fetches pages
+    that changed.
+
+    Example:
+        >>> with Database("wiki.db") as db:
+        ...     db.initialize_schema()
+        ...     result = PageHTMLScraper(api_client, db).capture()
+        ...     print(result.captured)
+    """
+
+    def __init__(self, api_client: MediaWikiAPIClient, db: Database):
+        """Initialize HTML scraper.
+
+        Args:
+            api_client: MediaWiki API client instance
+            db: Database instance with initialized schema
+        """
+        self.api = api_client
+        self.db = db
+        self.conn = db.get_connection()
+
+    def pending(self, namespaces: Optional[List[int]] = None) -> List[Tuple[int, int]]:
+        """List pages whose HTML is missing or older than their latest revision.
+
+        Args:
+            namespaces: Only consider pages in these namespaces (default: all)
+
+        Returns:
+            List of (page_id, revision_id) tuples, ordered by page_id
+        """
+        query = """
+            SELECT p.page_id, latest.revision_id
+            FROM pages p
+            JOIN revisions latest ON latest.revision_id = (
+                SELECT r.revision_id FROM revisions r
+                WHERE r.page_id = p.page_id
+                ORDER BY r.timestamp DESC, r.revision_id DESC
+                LIMIT 1
+            )
+            LEFT JOIN page_html h ON h.page_id = p.page_id
+            WHERE (h.revision_id IS NULL OR h.revision_id != latest.revision_id)
+        """
+        params: List[Any] = []
+        if namespaces:
+            query += f" AND p.namespace IN ({', '.join('?' * len(namespaces))})"
+            params.extend(namespaces)
+        query += " ORDER BY p.page_id"
+
+        cursor = self.conn.execute(query, params)
+        return [(row[0], row[1]) for row in cursor.fetchall()]
+
+    def fetch_html(self, revision_id: int) -> str:
+        """Fetch the rendered HTML of a revision.
+
+        Args:
+            revision_id: Revision to render
+
+        Returns:
+            HTML body as produced by the wiki's parser
+
+        Raises:
+            APIError: If API request fails
+            ValueError: If the response contains no HTML
+        """
+        response = self.api.parse(
+            {
+                "oldid": revision_id,
+                "prop": "text",
+                "disableeditsection": 1,
+                "disablelimitreport": 1,
+            }
+        )
+
+        text = response.get("parse", {}).get("text", {})
+        html = text.get("*") if isinstance(text, dict) else text
+        if not isinstance(html, str):
+            raise ValueError(f"No HTML in parse response for revision {revision_id}")
+        return html
+
+    def store_html(self, page_id: int, revision_id: int, html: str) -> None:
+        """Insert or replace the HTML of a page.
+
+        Args:
+            page_id: Page the HTML belongs to
+            revision_id: Revision that was rendered
+            html: Rendered HTML
+        """
+        self.conn.execute(
+            """
+            INSERT INTO page_html (page_id, revision_id, html, fetched_at)
+            VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+            ON CONFLICT(page_id) DO UPDATE SET
+                revision_id = excluded.revision_id,
+                html = excluded.html,
+                fetched_at = excluded.fetched_at
+        """,
+            (page_id, revision_id, html),
+        )
+        self.conn.commit()
+
+    def capture(
+        self,
+        namespaces: Optional[List[int]] = None,
+        progress_callback: Optional[Callable[[str, int, int], None]] = None,
+    ) -> HTMLCaptureResult:
+        """Capture HTML for every page that needs it.
+
+        A page that fails to render is logged and skipped; it stays pending
+        and is retried by the next run.
+
+        Args:
+            namespaces: Only capture pages in these namespaces (default: all)
+            progress_callback: Optional callback function(stage, current, total) for progress
+
+        Returns:
+            HTMLCaptureResult with counts of captured and failed pages
+        """
+        result = HTMLCaptureResult()
+        pages = self.pending(namespaces)
+        logger.info(f"Capturing rendered HTML for {len(pages)} pages")
+
+        for i, (page_id, revision_id) in enumerate(pages, 1):
+            try:
+                self.store_html(page_id, revision_id, self.fetch_html(revision_id))
+                result.captured += 1
+            except Exception as e:
+                logger.warning(f"Failed to capture HTML for page {page_id}: {e}")
+                result.failed.append(page_id)
+
+            if progress_callback:
+                progress_callback("html", i, len(pages))
+
+        logger.info(
+            f"HTML capture complete: {result.captured} captured, "
+            f"{len(result.failed)} failed"
+        )
+        return result
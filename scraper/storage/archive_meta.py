@@ -0,0 +1,158 @@
+"""Archive metadata storage.
+
+This module provides the ArchiveMetaRepository class for reading and writing
+the archive_meta key/value table, which records where an archive came from
+(source wiki, siteinfo, license, scraper version) so consumers can generate
+correct attribution and interwiki links.
+"""
+
+import json
+import logging
+from datetime import UTC, datetime
+from importlib import metadata
+from typing import Any, Dict, Optional
+
+from scraper.storage.database import Database
+
+logger = logging.getLogger(__name__)
+
+# Distribution name used to look up the scraper version
+PACKAGE_NAME = "iro-wiki-scraper"
+
+
+def scraper_version() -> str:
+    """
+    Return the installed scraper version, or "unknown" if not installed.
+
+    Returns:
+        Version string (e.g., "0.1.0")
+    """
+    try:
+        return metadata.version(PACKAGE_NAME)
+    except metadata.PackageNotFoundError:
+        return "unknown"
+
+
+class ArchiveMetaRepository:
+    """
+    Repository for archive-level key/value metadata.
+
+    Example:
+        >>> with Database("wiki.db") as db:
+        ...     db.initialize_schema()
+        ...     repo = ArchiveMetaRepository(db)
+        ...     repo.set("base_url", "https://irowiki.org")
+        ...     print(repo.get("base_url"))
+        https://irowiki.org
+    """
+
+    def __init__(self, db: Database) -> None:
+        """
+        Initialize repository.
+
+        Args:
+            db: Database instance with initialized schema
+        """
+        self.db = db
+        self.conn = db.get_connection()
+
+    def set(self, key: str, value: str) -> None:
+        """
+        Insert or replace a single metadata value.
+
+        Args:
+            key: Metadata key
+            value: Metadata value
+        """
+        self.set_many({key: value})
+
+    def set_many(self, values: Dict[str, str]) -> None:
+        """
+        Insert or replace several metadata values in one transaction.
+
+        Args:
+            values: Mapping of key to value
+        """
+        self.conn.executemany(
+            """
+            INSERT INTO archive_meta (key, value, updated_at)
+            VALUES (?, ?, CURRENT_TIMESTAMP)
+            ON CONFLICT(key) DO UPDATE SET
+                value = excluded.value,
+                updated_at = excluded.updated_at
+        """,
+            list(values.items()),
+        )
+        self.conn.commit()
+
+    def get(self, key: str) -> Optional[str]:
+        """
+        Get a single metadata value.
+
+        Args:
+            key: Metadata key
+
+        Returns:
+            Value, or None if the key is not set
+        """
+        cursor = self.conn.execute(
+            "SELECT value FROM archive_meta WHERE key = ?", (key,)
+        )
+        row = cursor.fetchone()
+        return row[0] if row else None
+
+    def get_all(self) -> Dict[str, str]:
+        """
+        Get all metadata values.
+
+        Returns:
+            Mapping of key to value
+        """
+        cursor = self.conn.execute("SELECT key, value FROM archive_meta ORDER BY key")
+        return {row[0]: row[1] for row in cursor.fetchall()}
+
+    def record_source(self, api_client: Any, base_url: str, api_path: str) -> None:
+        """
+        Record the source wiki, its siteinfo, and the scraper version.
+
+        Siteinfo is fetched from the API; if that fails, the URL and
+        version keys are still written so the archive is never anonymous.
+
+        Args:
+            api_client: MediaWikiAPIClient for the source wiki
+            base_url: Base URL of the wiki (e.g., "https://irowiki.org")
+            api_path: Path to the API endpoint (e.g., "/w/api.php")
+        """
+        values = {
+            "base_url": base_url.rstrip("/"),
+            "api_url": base_url.rstrip("/") + api_path,
+            "scraper_version": scraper_version(),
+            "scraped_at": datetime.now(UTC).isoformat(),
+        }
+
+        try:
+            response = api_client.query(
+                {"meta": "siteinfo", "siprop": "general|rightsinfo"}
+            )
+            query = response.get("query", {})
+            general = query.get("general", {})
+            rights = query.get("rightsinfo", {})
+
+            for key, source in (
+                ("wiki_name", general.get("sitename")),
+                ("article_path", general.get("articlepath")),
+                ("language", general.get("lang")),
+                ("generator", general.get("generator")),
+                ("license_name", rights.get("text")),
+                ("license_url", rights.get("url")),
+            ):
+                if isinstance(source, str) and source:
+                    values[key] = source
+
+            if isinstance(general, dict) and general:
+                values["siteinfo"] = json.dumps(general, sort_keys=True)
+        except Exception as e:
+            logger.warning(f"Could not fetch siteinfo for archive metadata: {e}")
+
+        self.set_many(values)
+        logger.info(f"Recorded archive metadata ({len(values)} keys)")
@@ -11,6 +11,9 @@ import sqlite3
 from pathlib import Path
 from typing import Optional
 
+from scraper.storage.lock import ArchiveLock
+from scraper.storage.redirects import update_redirect_targets
+
 logger = logging.getLogger(__name__)
 
 
@@ -48,6 +51,7 @@ class Database:
         self.db_path = Path(db_path)
         self.read_only = read_only
         self._connection: Optional[sqlite3.Connection] = None
+        self._lock: Optional[ArchiveLock] = None
         self._schema_dir = Path(__file__).parent.parent.parent / "schema" / "sqlite"
 
         # Validate path
@@ -85,6 +89,11 @@ class Database:
 
         conn = self.get_connection()
 
+        # Bring tables created by older schema versions up to date first, so
+        # the schema files can index their new columns
+        self._migrate_pages_wiki_id(conn)
+        self._migrate_pages_redirect_target(conn)
+
         # Enable foreign key enforcement
         conn.execute("PRAGMA foreign_keys = ON")
 
@@ -117,6 +126,82 @@ class Database:
         version = cursor.fetchone()[0]
         logger.info(f"Database schema version: {version}")
 
+    def _migrate_pages_wiki_id(self, conn: sqlite3.Connection) -> None:
+        """
+        Add pages.wiki_id to archives created before sister wiki support.
+
+        The (namespace, title) unique constraint becomes (wiki_id, namespace,
+        title), which SQLite can only change by rebuilding the table. Indexes
+        and triggers on pages are recreated by the schema files afterwards.
+
+        Args:
+            conn: Connection to migrate
+        """
+        columns = [row[1] for row in conn.execute("PRAGMA table_info(pages)")]
+        if not columns or "wiki_id" in columns:
+            return
+
+        logger.info("Migrating pages table: adding wiki_id")
+
+        # Dropping pages must not cascade to revisions, and renaming must not
+        # validate triggers that refer to the table while it is missing
+        conn.execute("PRAGMA foreign_keys = OFF")
+        conn.execute("PRAGMA legacy_alter_table = ON")
+        try:
+            conn.executescript(
+                """
+                BEGIN;
+                CREATE TABLE pages_migrated (
+                    page_id INTEGER PRIMARY KEY,
+                    namespace INTEGER NOT NULL DEFAULT 0,
+                    title TEXT NOT NULL,
+                    is_redirect BOOLEAN NOT NULL DEFAULT 0,
+                    wiki_id TEXT NOT NULL DEFAULT '',
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    UNIQUE(wiki_id, namespace, title),
+                    CHECK(namespace >= 0)
+                );
+                INSERT INTO pages_migrated
+                    (page_id, namespace, title, is_redirect, created_at, updated_at)
+                SELECT page_id, namespace, title, is_redirect, created_at, updated_at
+                FROM pages;
+                DROP TABLE pages;
+                ALTER TABLE pages_migrated RENAME TO pages;
+                COMMIT;
+            """
+            )
+        except sqlite3.Error:
+            conn.rollback()
+            raise
+        finally:
+            conn.execute("PRAGMA legacy_alter_table = OFF")
+
+    def _migrate_pages_redirect_target(self, conn: sqlite3.Connection) -> None:
+        """
+        Add pages.redirect_target to archives created before it, filled in
+        from the latest revision of each redirect.
+
+        Args:
+            conn: Connection to migrate
+        """
+        columns = [row[1] for row in conn.execute("PRAGMA table_info(pages)")]
+        if not columns or "redirect_target" in columns:
+            return
+
+        logger.info("Migrating pages table: adding redirect_target")
+        conn.execute("ALTER TABLE pages ADD COLUMN redirect_target TEXT")
+
+        tables = conn.execute(
+            "SELECT name FROM sqlite_master WHERE type='table' AND name='revisions'"
+        )
+        if tables.fetchone():
+            redirects = conn.execute(
+                "SELECT page_id FROM pages WHERE is_redirect = 1"
+            ).fetchall()
+            update_redirect_targets(conn, [row[0] for row in redirects])
+        conn.commit()
+
     def get_connection(self) -> sqlite3.Connection:
         """
         Get SQLite connection (creates if doesn't exist).
@@ -162,12 +247,29 @@ class Database:
 
         return self._connection
 
+    def acquire_lock(self, tool: str) -> None:
+        """
+        Take the archive's writer lock until the database is closed, so other
+        scrapes and SDK maintenance tools can't modify it at the same time.
+
+        Args:
+            tool: Name of this writer, shown to writers finding it locked
+
+        Raises:
+            ArchiveLockedError: If another writer holds the lock
+        """
+        if self._lock is None:
+            self._lock = ArchiveLock(self.db_path, tool).acquire()
+
     def close(self) -> None:
-        """Close database connection."""
+        """Close database connection and release the writer lock, if held."""
         if self._connection:
             self._connection.close()
             self._connection = None
             logger.debug("Database connection closed")
+        if self._lock is not None:
+            self._lock.release()
+            self._lock = None
 
     def __enter__(self):
         """Context manager entry."""
@@ -0,0 +1,168 @@
+"""Archive writer locking.
+
+This module provides the ArchiveLock class, an advisory lock that keeps two
+writers (scrapes, merges, the SDK's prune and bulk load) from modifying the
+same SQLite archive at once. The lock is a "<archive>.lock" file created
+exclusively and holding JSON describing its holder; the Go SDK uses the same
+file, so the scraper and SDK tools coordinate with each other.
+"""
+
+import json
+import logging
+import os
+import socket
+from datetime import UTC, datetime
+from pathlib import Path
+from typing import Any, Dict, Optional
+
+logger = logging.getLogger(__name__)
+
+
+class ArchiveLockedError(Exception):
+    """Raised when another writer holds an archive's lock."""
+
+    def __init__(self, path: Path, holder: Dict[str, Any]) -> None:
+        """
+        Initialize error.
+
+        Args:
+            path: Path of the lock file
+            holder: Holder info read from the lock file (pid, host, tool, acquired)
+        """
+        self.path = path
+        self.holder = holder
+        super().__init__(
+            f"archive is locked by {holder.get('tool') or 'unknown'} "
+            f"(pid {holder.get('pid')} on {holder.get('host') or 'unknown host'}) "
+            f"since {holder.get('acquired') or 'unknown'}; "
+            f"remove {path} if that process is gone"
+        )
+
+
+def lock_path(db_path: Path) -> Path:
+    """
+    Return the lock file path of an archive.
+
+    Args:
+        db_path: Path to the SQLite archive
+
+    Returns:
+        Path of the archive's lock file
+    """
+    return db_path.with_name(db_path.name + ".lock")
+
+
+def _process_alive(pid: int) -> bool:
+    """
+    Check whether a process exists on this host.
+
+    Args:
+        pid: Process ID
+
+    Returns:
+        True if the process exists (or can't be checked)
+    """
+    try:
+        os.kill(pid, 0)
+    except ProcessLookupError:
+        return False
+    except (PermissionError, OSError):
+        return True
+    return True
+
+
+class ArchiveLock:
+    """
+    Advisory lock held by a writer for the duration of its changes.
+
+    A lock left behind by a crashed process on the same host is taken over;
+    locks of live processes, or of other hosts sharing the file, are not.
+
+    Example:
+        >>> with ArchiveLock(Path("irowiki.db"), "scraper full"):
+        ...     run_scrape()
+    """
+
+    def __init__(self, db_path: Path, tool: str) -> None:
+        """
+        Initialize lock.
+
+        Args:
+            db_path: Path to the SQLite archive
+            tool: Name of the writer, shown to other writers finding it locked
+        """
+        self.path = lock_path(Path(db_path))
+        self.tool = tool
+        self._held = False
+
+    def acquire(self) -> "ArchiveLock":
+        """
+        Take the lock.
+
+        Returns:
+            The lock, for chaining
+
+        Raises:
+            ArchiveLockedError: If another writer holds the lock
+        """
+        holder = {
+            "pid": os.getpid(),
+            "host": socket.gethostname(),
+            "tool": self.tool,
+            "acquired": datetime.now(UTC).strftime("%Y-%m-%dT%H:%M:%SZ"),
+        }
+
+        for _ in range(2):
+            try:
+                fd = os.open(self.path, os.O_CREAT | os.O_EXCL | os.O_WRONLY, 0o644)
+            except FileExistsError:
+                existing = self._read_holder()
+                if existing is not None and self._is_stale(existing):
+                    logger.warning(
+                        f"Removing stale lock of {existing.get('tool')} "
+                        f"(pid {existing.get('pid')})"
+                    )
+                    self.path.unlink(missing_ok=True)
+                    continue
+                raise ArchiveLockedError(self.path, existing or {})
+
+            with os.fdopen(fd, "w") as f:
+                json.dump(holder, f)
+            self._held = True
+            logger.debug(f"Acquired archive lock: {self.path}")
+            return self
+
+        raise ArchiveLockedError(self.path, self._read_holder() or {})
+
+    def release(self) -> None:
+        """Release the lock, if held."""
+        if self._held:
+            self.path.unlink(missing_ok=True)
+            self._held = False
+            logger.debug(f"Released archive lock: {self.path}")
+
+    def _read_holder(self) -> Optional[Dict[str, Any]]:
+        """Read the holder info of the lock file, or None if it's unreadable."""
+        try:
+            with open(self.path) as f:
+                holder = json.load(f)
+        except (OSError, ValueError):
+            return None
+        return holder if isinstance(holder, dict) else None
+
+    @staticmethod
+    def _is_stale(holder: Dict[str, Any]) -> bool:
+        """Check whether a holder was a process on this host that has exited."""
+        pid = holder.get("pid")
+        if holder.get("host") != socket.gethostname() or not isinstance(pid, int):
+            return False
+        return not _process_alive(pid)
+
+    def __enter__(self) -> "ArchiveLock":
+        """Context manager entry (takes the lock)."""
+        return self.acquire()
+
+    def __exit__(self, exc_type, exc_val, exc_tb):
+        """Context manager exit (releases the lock)."""
+        self.release()
+        return False
@@ -7,6 +7,7 @@ from typing import List, Optional
 
 from scraper.storage.database import Database
 from scraper.storage.models import Page
+from scraper.storage.redirects import update_redirect_targets
 
 logger = logging.getLogger(__name__)
 
@@ -237,6 +238,20 @@ class PageRepository:
         self.conn.commit()
         logger.debug(f"Deleted page: {page_id}")
 
+    def update_redirect_targets(self, page_ids: Optional[List[int]] = None) -> int:
+        """
+        Record where redirect pages point, from their latest revisions.
+
+        Args:
+            page_ids: Pages to update (None = every page)
+
+        Returns:
+            Number of those pages that are redirects with a target
+        """
+        targets = update_redirect_targets(self.conn, page_ids)
+        self.conn.commit()
+        return targets
+
     def count_pages(self, namespace: Optional[int] = None) -> int:
         """
         Count pages.
@@ -0,0 +1,106 @@
+"""Redirect target storage.
+
+MediaWiki only reports whether a page is a redirect; where it points is in
+the wikitext of its latest revision ("#REDIRECT [[Target#Section]]"). This
+module parses that target and records it in pages.redirect_target, so
+archive consumers can list the redirects into a page and find double or
+broken redirects without parsing wikitext.
+"""
+
+import logging
+import re
+import sqlite3
+from typing import Iterable, Optional
+
+logger = logging.getLogger(__name__)
+
+# Matches the link of a redirect page; MediaWiki accepts an optional colon
+# after the magic word and ignores anything following the link
+REDIRECT_PATTERN = re.compile(r"^\s*#redirect\s*:?\s*\[\[([^|\]\n]+)", re.IGNORECASE)
+
+# SQL function registered on connections that update targets
+SQL_FUNCTION = "irowiki_redirect_target"
+
+
+def extract_redirect_target(wikitext: Optional[str]) -> Optional[str]:
+    """
+    Parse the target of a redirect page.
+
+    The target is normalized the way MediaWiki stores titles: underscores
+    become spaces, a leading colon and any #section are dropped, and the
+    first letter is upper-cased.
+
+    Args:
+        wikitext: Content of the page's latest revision
+
+    Returns:
+        Target title, or None if the wikitext isn't a redirect
+
+    Example:
+        >>> extract_redirect_target("#REDIRECT [[poring_card#Drops]]")
+        'Poring card'
+    """
+    if not wikitext:
+        return None
+
+    match = REDIRECT_PATTERN.match(wikitext)
+    if not match:
+        return None
+
+    title = match.group(1).split("#", 1)[0]
+    title = " ".join(title.replace("_", " ").split()).lstrip(":").strip()
+    if not title:
+        return None
+    return title[0].upper() + title[1:]
+
+
+def update_redirect_targets(
+    conn: sqlite3.Connection, page_ids: Optional[Iterable[int]] = None
+) -> int:
+    """
+    Recompute pages.redirect_target from the latest revision of each page.
+
+    Pages that aren't redirects (or no longer are) get NULL. The caller
+    commits, so merges can update targets within their transaction.
+
+    Args:
+        conn: Connection to an archive with pages.redirect_target
+        page_ids: Pages to update (None = every page)
+
+    Returns:
+        Number of pages that are redirects with a target after the update
+    """
+    conn.create_function(SQL_FUNCTION, 1, extract_redirect_target, deterministic=True)
+
+    query = f"""
+        UPDATE pages
+        SET redirect_target = CASE WHEN is_redirect THEN {SQL_FUNCTION}((
+            SELECT content FROM revisions r
+            WHERE r.page_id = pages.page_id
+            ORDER BY r.timestamp DESC, r.revision_id DESC
+            LIMIT 1
+        )) END
+    """
+
+    if page_ids is None:
+        conn.execute(query)
+        cursor = conn.execute(
+            "SELECT COUNT(*) FROM pages WHERE redirect_target IS NOT NULL"
+        )
+        return cursor.fetchone()[0]
+
+    ids = list(page_ids)
+    targets = 0
+    # Stay under SQLite's bound parameter limit
+    for start in range(0, len(ids), 500):
+        batch = ids[start : start + 500]
+        marks = ",".join("?" * len(batch))
+        conn.execute(f"{query} WHERE page_id IN ({marks})", batch)
+        cursor = conn.execute(
+            f"SELECT COUNT(*) FROM pages WHERE redirect_target IS NOT NULL AND page_id IN ({marks})",
+            batch,
+        )
+        targets += cursor.fetchone()[0]
+
+    logger.debug(f"Updated redirect targets of {len(ids)} pages")
+    return targets
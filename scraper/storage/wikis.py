@@ -0,0 +1,415 @@
+"""Sister wiki storage.
+
+This module provides the WikiRepository class for the wikis table and the
+merge_wiki function, which copies the archive of a sister wiki (for example
+iRO Wiki Classic) into another archive so both can be browsed and searched
+from one file.
+
+MediaWiki page and revision IDs are only unique within one wiki, so the
+merged wiki's IDs are shifted by its id_offset. Pages keep their titles and
+are told apart by pages.wiki_id. Interwiki links between the merged wikis are
+recorded in interwiki_links so tools can jump to the same article elsewhere.
+"""
+
+import logging
+import re
+import sqlite3
+from dataclasses import dataclass
+from typing import List, Optional
+
+from scraper.storage.database import Database
+from scraper.storage.redirects import update_redirect_targets
+
+logger = logging.getLogger(__name__)
+
+# Distance between the ID ranges of merged wikis. Source IDs must be smaller.
+ID_OFFSET_STEP = 1_000_000_000
+
+# Wiki IDs are short lowercase keys such as "classic"
+WIKI_ID_PATTERN = re.compile(r"^[a-z0-9][a-z0-9_-]*$")
+
+# Interwiki and interlanguage links: [[classic:Poring]], [[:classic:Poring#Drops|...]]
+INTERWIKI_LINK_PATTERN = re.compile(
+    r"\[\[:?\s*([A-Za-z0-9_-]+)\s*:([^\[\]\n|#]+)(?:#[^\[\]\n|]*)?(?:\|[^\[\]\n]*)?\]\]"
+)
+
+
+@dataclass(frozen=True)
+class Wiki:
+    """
+    A sister wiki merged into an archive.
+
+    Attributes:
+        wiki_id: Short identifier stored in pages.wiki_id (e.g., "classic")
+        name: Site name (e.g., "iRO Wiki Classic")
+        base_url: Base URL of the wiki
+        api_url: Full URL of the MediaWiki API endpoint, if known
+        article_path: Article URL pattern (e.g., "/classic/$1"), if known
+        id_offset: Added to the wiki's page and revision IDs
+    """
+
+    wiki_id: str
+    name: str
+    base_url: str
+    api_url: Optional[str]
+    article_path: Optional[str]
+    id_offset: int
+
+
+@dataclass
+class MergeResult:
+    """
+    Outcome of merging a sister wiki archive.
+
+    Attributes:
+        wiki: The merged wiki
+        pages: Pages copied
+        revisions: Revisions copied
+        files: Files copied (files already in the archive are kept)
+        interwiki_links: Cross-wiki links mapped across the whole archive
+    """
+
+    wiki: Wiki
+    pages: int = 0
+    revisions: int = 0
+    files: int = 0
+    interwiki_links: int = 0
+
+
+class WikiRepository:
+    """
+    Repository for the sister wikis of an archive.
+
+    Example:
+        >>> with Database("irowiki.db") as db:
+        ...     db.initialize_schema()
+        ...     for wiki in WikiRepository(db).list():
+        ...         print(wiki.wiki_id, wiki.base_url)
+        classic https://irowiki.org
+    """
+
+    def __init__(self, db: Database) -> None:
+        """
+        Initialize repository.
+
+        Args:
+            db: Database instance with initialized schema
+        """
+        self.db = db
+        self.conn = db.get_connection()
+
+    def list(self) -> List[Wiki]:
+        """
+        List the merged wikis.
+
+        Returns:
+            Wikis ordered by ID offset
+        """
+        cursor = self.conn.execute(
+            """
+            SELECT wiki_id, name, base_url, api_url, article_path, id_offset
+            FROM wikis
+            ORDER BY id_offset
+        """
+        )
+        return [Wiki(*row) for row in cursor.fetchall()]
+
+    def get(self, wiki_id: str) -> Optional[Wiki]:
+        """
+        Get a merged wiki.
+
+        Args:
+            wiki_id: Wiki identifier
+
+        Returns:
+            The wiki, or None if it hasn't been merged
+        """
+        cursor = self.conn.execute(
+            """
+            SELECT wiki_id, name, base_url, api_url, article_path, id_offset
+            FROM wikis
+            WHERE wiki_id = ?
+        """,
+            (wiki_id,),
+        )
+        row = cursor.fetchone()
+        return Wiki(*row) if row else None
+
+    def next_offset(self) -> int:
+        """
+        Return the ID offset for a newly merged wiki.
+
+        Returns:
+            A multiple of ID_OFFSET_STEP above every offset in use
+        """
+        cursor = self.conn.execute("SELECT MAX(id_offset) FROM wikis")
+        current = cursor.fetchone()[0] or 0
+        return current + ID_OFFSET_STEP
+
+    def save(self, wiki: Wiki) -> None:
+        """
+        Insert or update a wiki without committing.
+
+        Args:
+            wiki: Wiki to store
+        """
+        self.conn.execute(
+            """
+            INSERT INTO wikis (wiki_id, name, base_url, api_url, article_path, id_offset, merged_at)
+            VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+            ON CONFLICT(wiki_id) DO UPDATE SET
+                name = excluded.name,
+                base_url = excluded.base_url,
+                api_url = excluded.api_url,
+                article_path = excluded.article_path,
+                merged_at = excluded.merged_at
+        """,
+            (
+                wiki.wiki_id,
+                wiki.name,
+                wiki.base_url,
+                wiki.api_url,
+                wiki.article_path,
+                wiki.id_offset,
+            ),
+        )
+
+
+def _table_exists(conn: sqlite3.Connection, schema: str, table: str) -> bool:
+    """Report whether a table exists in an attached database."""
+    cursor = conn.execute(
+        f"SELECT 1 FROM {schema}.sqlite_master WHERE type = 'table' AND name = ?",
+        (table,),
+    )
+    return cursor.fetchone() is not None
+
+
+def _normalize_title(title: str) -> str:
+    """Normalize a linked title the way MediaWiki stores it."""
+    title = " ".join(title.replace("_", " ").split())
+    return title[:1].upper() + title[1:]
+
+
+def map_interwiki_links(db: Database) -> int:
+    """
+    Rebuild interwiki_links from the latest revision of every page, without
+    committing.
+
+    A link maps its page to another wiki when its prefix is the ID of a wiki
+    merged into the archive. Only the first link to each wiki counts, as in
+    MediaWiki's language links.
+
+    Args:
+        db: Archive with initialized schema
+
+    Returns:
+        Number of links recorded
+    """
+    conn = db.get_connection()
+    wiki_ids = {wiki.wiki_id for wiki in WikiRepository(db).list()}
+
+    links = {}
+    if wiki_ids:
+        cursor = conn.execute(
+            """
+            SELECT p.page_id, p.wiki_id,
+                   (SELECT r.content FROM revisions r
+                    WHERE r.page_id = p.page_id
+                    ORDER BY r.timestamp DESC
+                    LIMIT 1)
+            FROM pages p
+        """
+        )
+        for page_id, page_wiki, content in cursor:
+            for match in INTERWIKI_LINK_PATTERN.finditer(content or ""):
+                target_wiki = match.group(1).lower()
+                title = _normalize_title(match.group(2))
+                if target_wiki in wiki_ids and target_wiki != page_wiki and title:
+                    links.setdefault((page_id, target_wiki), title)
+
+    conn.execute("DELETE FROM interwiki_links")
+    conn.executemany(
+        "INSERT INTO interwiki_links (source_page_id, target_wiki, target_title) VALUES (?, ?, ?)",
+        [(page_id, wiki, title) for (page_id, wiki), title in links.items()],
+    )
+    return len(links)
+
+
+def merge_wiki(db: Database, source_path: str, wiki_id: str) -> MergeResult:
+    """
+    Copy a sister wiki's archive into db as wiki_id.
+
+    The source is an ordinary single-wiki archive, such as one produced by a
+    full scrape of the sister wiki. Merging the same wiki again replaces its
+    pages, so a refreshed source archive can be re-merged after each scrape.
+
+    Args:
+        db: Target archive with initialized schema
+        source_path: Path to the sister wiki's archive
+        wiki_id: Identifier for the merged wiki (e.g., "classic")
+
+    Returns:
+        MergeResult with the number of rows copied
+
+    Raises:
+        ValueError: If wiki_id is invalid, the source has no source URL
+            metadata, or IDs don't fit below ID_OFFSET_STEP
+    """
+    if not WIKI_ID_PATTERN.match(wiki_id):
+        raise ValueError(
+            f"Invalid wiki ID {wiki_id!r}: use lowercase letters, digits, '-' and '_'"
+        )
+
+    conn = db.get_connection()
+    repo = WikiRepository(db)
+
+    conn.execute("ATTACH DATABASE ? AS source", (source_path,))
+    try:
+        meta = {}
+        if _table_exists(conn, "source", "archive_meta"):
+            cursor = conn.execute("SELECT key, value FROM source.archive_meta")
+            meta = {row[0]: row[1] for row in cursor.fetchall()}
+        if not meta.get("base_url"):
+            raise ValueError(
+                f"{source_path} has no base_url in archive_meta; "
+                "merge archives written by a full scrape"
+            )
+        if _table_exists(conn, "source", "wikis"):
+            if conn.execute("SELECT 1 FROM source.wikis LIMIT 1").fetchone():
+                raise ValueError(f"{source_path} already holds several wikis")
+
+        # Primary IDs must stay below the first offset, and source IDs below the step
+        for label, query in (
+            (
+                "source",
+                """SELECT MAX((SELECT COALESCE(MAX(page_id), 0) FROM source.pages),
+                              (SELECT COALESCE(MAX(revision_id), 0) FROM source.revisions))""",
+            ),
+            (
+                "target",
+                """SELECT MAX((SELECT COALESCE(MAX(page_id), 0) FROM pages WHERE wiki_id = ''),
+                              (SELECT COALESCE(MAX(r.revision_id), 0) FROM revisions r
+                               JOIN pages p ON p.page_id = r.page_id
+                               WHERE p.wiki_id = ''))""",
+            ),
+        ):
+            if conn.execute(query).fetchone()[0] >= ID_OFFSET_STEP:
+                raise ValueError(
+                    f"The {label} archive has IDs of {ID_OFFSET_STEP} or more"
+                )
+
+        existing = repo.get(wiki_id)
+        wiki = Wiki(
+            wiki_id=wiki_id,
+            name=meta.get("wiki_name") or wiki_id,
+            base_url=meta["base_url"],
+            api_url=meta.get("api_url"),
+            article_path=meta.get("article_path"),
+            id_offset=existing.id_offset if existing else repo.next_offset(),
+        )
+        offset = wiki.id_offset
+        result = MergeResult(wiki=wiki)
+
+        with conn:
+            # Revisions may be copied before their parents
+            conn.execute("PRAGMA defer_foreign_keys = ON")
+
+            # Replace an earlier merge; revisions and page_html cascade
+            old_pages = "SELECT page_id FROM pages WHERE wiki_id = ?"
+            conn.execute(
+                f"DELETE FROM links WHERE source_page_id IN ({old_pages})",
+                (wiki_id,),
+            )
+            if _table_exists(conn, "main", "external_links"):
+                conn.execute(
+                    f"DELETE FROM external_links WHERE source_page_id IN ({old_pages})",
+                    (wiki_id,),
+                )
+            conn.execute("DELETE FROM pages WHERE wiki_id = ?", (wiki_id,))
+
+            result.pages = conn.execute(
+                """
+                INSERT INTO pages (page_id, namespace, title, is_redirect, wiki_id, created_at, updated_at)
+                SELECT page_id + ?, namespace, title, is_redirect, ?, created_at, updated_at
+                FROM source.pages
+            """,
+                (offset, wiki_id),
+            ).rowcount
+
+            result.revisions = conn.execute(
+                """
+                INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id,
+                                       comment, content, size, sha1, minor, tags)
+                SELECT revision_id + ?, page_id + ?, parent_id + ?, timestamp, user, user_id,
+                       comment, content, size, sha1, minor, tags
+                FROM source.revisions
+                ORDER BY revision_id
+            """,
+                (offset, offset, offset),
+            ).rowcount
+
+            # Recomputed rather than copied, so archives scraped before
+            # redirect targets were stored get them too
+            update_redirect_targets(
+                conn,
+                [
+                    row[0]
+                    for row in conn.execute(
+                        "SELECT page_id FROM pages WHERE wiki_id = ? AND is_redirect = 1",
+                        (wiki_id,),
+                    )
+                ],
+            )
+
+            conn.execute(
+                """
+                INSERT OR IGNORE INTO links (source_page_id, target_title, link_type)
+                SELECT source_page_id + ?, target_title, link_type
+                FROM source.links
+            """,
+                (offset,),
+            )
+
+            # Sister wikis normally share a file repository; keep existing files
+            result.files = conn.execute(
+                """
+                INSERT OR IGNORE INTO files (filename, url, descriptionurl, sha1, size,
+                                             width, height, mime_type, timestamp, uploader)
+                SELECT filename, url, descriptionurl, sha1, size,
+                       width, height, mime_type, timestamp, uploader
+                FROM source.files
+            """
+            ).rowcount
+
+            if _table_exists(conn, "source", "page_html"):
+                conn.execute(
+                    """
+                    INSERT INTO page_html (page_id, revision_id, html, fetched_at)
+                    SELECT page_id + ?, revision_id + ?, html, fetched_at
+                    FROM source.page_html
+                """,
+                    (offset, offset),
+                )
+            if _table_exists(conn, "source", "external_links"):
+                conn.execute(
+                    """
+                    INSERT OR IGNORE INTO external_links (source_page_id, url, is_dead, checked_at,
+                                                          archive_url, archive_timestamp)
+                    SELECT source_page_id + ?, url, is_dead, checked_at,
+                           archive_url, archive_timestamp
+                    FROM source.external_links
+                """,
+                    (offset,),
+                )
+
+            repo.save(wiki)
+            result.interwiki_links = map_interwiki_links(db)
+    finally:
+        conn.execute("DETACH DATABASE source")
+
+    logger.info(
+        f"Merged {wiki_id}: {result.pages} pages, {result.revisions} revisions, "
+        f"{result.files} files, {result.interwiki_links} interwiki links "
+        f"(ID offset {offset})"
+    )
+    return result
@@ -20,8 +20,10 @@ import sys
 import time
 from dataclasses import dataclass, field
 from datetime import datetime
+from enum import Enum
 from pathlib import Path
 from typing import Any, Dict, Iterator, List, Optional
+from urllib.parse import quote
 
 import numpy as np
 from tqdm import tqdm
@@ -41,6 +43,42 @@ logging.basicConfig(
 )
 logger = logging.getLogger(__name__)
 
+# MediaWiki's default article path, used when the archive doesn't record one
+DEFAULT_ARTICLE_PATH = "/wiki/$1"
+
+
+def section_anchor(heading: str) -> str:
+    """Return the anchor MediaWiki gives a section heading"""
+    return "_".join(heading.split())
+
+
+def page_url(
+    base_url: str, title: str, article_path: str = DEFAULT_ARTICLE_PATH
+) -> str:
+    """Return the URL of a page on the wiki at base_url"""
+    # Keep namespace separators and subpage slashes readable, as MediaWiki does
+    escaped = quote(title.replace(" ", "_"), safe=":/")
+    return base_url.rstrip("/") + article_path.replace("$1", escaped, 1)
+
+
+def count_tokens(model: Any, texts: List[str]) -> List[int]:
+    """Count the tokens the model's tokenizer splits each text into, untruncated"""
+    encoded = model.tokenizer(texts, verbose=False)
+    return [len(ids) for ids in encoded["input_ids"]]
+
+
+class ChunkType(str, Enum):
+    """Kinds of chunk; must match the Go SDK's vector.ChunkType constants,
+    which search filters use"""
+
+    PAGE = "page"  # A whole page
+    SECTION = "section"  # A section under a heading
+    PARAGRAPH = "paragraph"  # A paragraph
+    WINDOW = "window"  # A fixed window of words
+    TABLE = "table"  # Rows of a wikitable
+    SUMMARY = "summary"  # A page's introduction, before its first heading
+    INFOBOX = "infobox"  # The parameters of an infobox template
+
 
 @dataclass
 class Chunk:
@@ -54,11 +92,37 @@ class Chunk:
     metadata: Dict[str, Any] = field(default_factory=dict)
 
     # Chunk-specific fields
-    chunk_type: str = "page"  # page, section, paragraph
+    chunk_type: ChunkType = ChunkType.PAGE
     section_title: Optional[str] = None
     section_level: Optional[int] = None
+    section_anchor: Optional[str] = None
     chunk_index: int = 0
 
+    # URL of the page on the wiki or a mirror, if known
+    page_url: Optional[str] = None
+
+    # Length in the embedding model's tokens, set when the chunk is embedded
+    tokens: Optional[int] = None
+
+    def __post_init__(self):
+        # Unknown types would silently never match search filters
+        try:
+            self.chunk_type = ChunkType(self.chunk_type)
+        except ValueError:
+            valid = ", ".join(t.value for t in ChunkType)
+            raise ValueError(
+                f"unknown chunk type {self.chunk_type!r} (expected one of: {valid})"
+            ) from None
+
+    @property
+    def url(self) -> Optional[str]:
+        """Source URL of this chunk, deep-linking to its section"""
+        if not self.page_url:
+            return None
+        if self.section_anchor:
+            return f"{self.page_url}#{quote(self.section_anchor, safe=':/')}"
+        return self.page_url
+
     def to_dict(self) -> Dict[str, Any]:
         """Convert chunk to dictionary for storage"""
         return {
@@ -67,24 +131,31 @@ class Chunk:
             "page_title": self.page_title,
             "namespace": self.namespace,
             "content": self.content,
-            "chunk_type": self.chunk_type,
+            "chunk_type": self.chunk_type.value,
             "section_title": self.section_title,
             "section_level": self.section_level,
+            "section_anchor": self.section_anchor,
             "chunk_index": self.chunk_index,
+            "url": self.url,
+            "tokens": self.tokens,
             "metadata": self.metadata,
         }
 
     def get_id(self) -> str:
         """Generate unique ID for this chunk"""
-        if self.chunk_type == "page":
+        if self.chunk_type == ChunkType.PAGE:
             return f"page_{self.page_id}"
-        elif self.chunk_type == "section":
+        elif self.chunk_type in (ChunkType.SECTION, ChunkType.SUMMARY):
             section_slug = (
                 self.section_title.lower().replace(" ", "_")
                 if self.section_title
                 else "intro"
             )
             return f"page_{self.page_id}_section_{section_slug}_{self.chunk_index}"
+        elif self.chunk_type == ChunkType.WINDOW:
+            return f"page_{self.page_id}_window_{self.chunk_index}"
+        elif self.chunk_type in (ChunkType.TABLE, ChunkType.INFOBOX):
+            return f"page_{self.page_id}_{self.chunk_type.value}_{self.chunk_index}"
         else:  # paragraph
             return f"page_{self.page_id}_para_{self.chunk_index}"
 
@@ -95,6 +166,28 @@ class WikiChunker:
     MIN_CHUNK_SIZE = 50  # Minimum words per chunk
     MAX_CHUNK_SIZE = 1000  # Maximum words per chunk (for paragraph mode)
 
+    def __init__(
+        self,
+        window_size: int = 256,
+        window_overlap: int = 32,
+        table_rows: int = 10,
+    ):
+        """
+        Args:
+            window_size: Words per chunk in window mode
+            window_overlap: Words repeated from the previous window
+            table_rows: Table rows per chunk in table mode
+        """
+        if window_size <= 0:
+            raise ValueError("window_size must be positive")
+        if not 0 <= window_overlap < window_size:
+            raise ValueError("window_overlap must be at least 0 and less than window_size")
+        if table_rows <= 0:
+            raise ValueError("table_rows must be positive")
+        self.window_size = window_size
+        self.window_overlap = window_overlap
+        self.table_rows = table_rows
+
     @staticmethod
     def clean_wikitext(text: str) -> str:
         """Clean MediaWiki markup from text"""
@@ -147,10 +240,29 @@ class WikiChunker:
             page_title=page_data["page_title"],
             namespace=page_data["namespace"],
             content=content,
-            chunk_type="page",
+            chunk_type=ChunkType.PAGE,
             metadata=page_data["metadata"],
+            page_url=page_data.get("url"),
         )
 
+    @staticmethod
+    def split_sections(content: str) -> List[tuple]:
+        """
+        Split wikitext by MediaWiki headings
+
+        Returns:
+            (heading, level, text) tuples; the first is the intro, with no
+            heading
+        """
+        # Split by headings (==, ===, etc.)
+        # Pattern: one or more = signs, text, same number of = signs
+        parts = re.split(r"\n(={2,})([^=]+)\1\n", content)
+
+        sections = [(None, 1, parts[0])]
+        for i in range(1, len(parts) - 2, 3):
+            sections.append((parts[i + 1].strip(), len(parts[i]), parts[i + 2]))
+        return sections
+
     def chunk_section_level(self, page_data: Dict[str, Any]) -> Iterator[Chunk]:
         """Chunk at section level (split by MediaWiki headings)"""
         content = page_data["content"]
@@ -169,11 +281,12 @@ class WikiChunker:
                 page_title=page_title,
                 namespace=page_data["namespace"],
                 content=intro,
-                chunk_type="section",
+                chunk_type=ChunkType.SECTION,
                 section_title="Introduction",
                 section_level=1,
                 chunk_index=0,
                 metadata=page_data["metadata"],
+                page_url=page_data.get("url"),
             )
 
         # Process remaining sections
@@ -199,11 +312,13 @@ class WikiChunker:
                 page_title=page_title,
                 namespace=page_data["namespace"],
                 content=full_content,
-                chunk_type="section",
+                chunk_type=ChunkType.SECTION,
                 section_title=heading_text,
                 section_level=heading_level,
+                section_anchor=section_anchor(heading_text),
                 chunk_index=chunk_index,
                 metadata=page_data["metadata"],
+                page_url=page_data.get("url"),
             )
             chunk_index += 1
 
@@ -249,10 +364,11 @@ class WikiChunker:
                                 page_title=page_title,
                                 namespace=page_data["namespace"],
                                 content=chunk_text,
-                                chunk_type="paragraph",
+                                chunk_type=ChunkType.PARAGRAPH,
                                 section_title=current_section,
                                 chunk_index=chunk_index,
                                 metadata=page_data["metadata"],
+                                page_url=page_data.get("url"),
                             )
                             chunk_index += 1
                         current_chunk = [sentence]
@@ -271,10 +387,11 @@ class WikiChunker:
                             page_title=page_title,
                             namespace=page_data["namespace"],
                             content=chunk_text,
-                            chunk_type="paragraph",
+                            chunk_type=ChunkType.PARAGRAPH,
                             section_title=current_section,
                             chunk_index=chunk_index,
                             metadata=page_data["metadata"],
+                            page_url=page_data.get("url"),
                         )
                         chunk_index += 1
             else:
@@ -284,27 +401,334 @@ class WikiChunker:
                     page_title=page_title,
                     namespace=page_data["namespace"],
                     content=para,
-                    chunk_type="paragraph",
+                    chunk_type=ChunkType.PARAGRAPH,
                     section_title=current_section,
                     chunk_index=chunk_index,
                     metadata=page_data["metadata"],
+                    page_url=page_data.get("url"),
+                )
+                chunk_index += 1
+
+    def chunk_window_level(self, page_data: Dict[str, Any]) -> Iterator[Chunk]:
+        """Chunk into fixed windows of words, overlapping so that text cut
+        at a window boundary appears whole in the next window"""
+        words = self.clean_wikitext(page_data["content"]).split()
+        if len(words) < self.MIN_CHUNK_SIZE:
+            return
+
+        step = self.window_size - self.window_overlap
+        chunk_index = 0
+        for start in range(0, len(words), step):
+            window = words[start : start + self.window_size]
+            yield Chunk(
+                page_id=page_data["page_id"],
+                revision_id=page_data["revision_id"],
+                page_title=page_data["page_title"],
+                namespace=page_data["namespace"],
+                content=" ".join(window),
+                chunk_type=ChunkType.WINDOW,
+                chunk_index=chunk_index,
+                metadata=page_data["metadata"],
+                page_url=page_data.get("url"),
+            )
+            chunk_index += 1
+            # The last window reaches the end of the page
+            if start + self.window_size >= len(words):
+                break
+
+    def chunk_table_aware(self, page_data: Dict[str, Any]) -> Iterator[Chunk]:
+        """
+        Chunk by section, splitting wikitables into groups of rows
+
+        Drop and stat tables lose their meaning when split mid-row or
+        stripped of their headers, so each table chunk holds whole rows,
+        each cell labeled with its column header, under the page, section
+        and table caption. Infoboxes become chunks of their parameters, and
+        the introduction a summary chunk.
+        """
+        page_title = page_data["page_title"]
+        chunk_index = 0
+
+        for heading, level, text in self.split_sections(page_data["content"]):
+            text, infoboxes = self.extract_infoboxes(text)
+            prose, tables = self.extract_tables(text)
+            anchor = section_anchor(heading) if heading else None
+
+            for name, params in infoboxes:
+                lines = [f"{key}: {value}" for key, value in params if value]
+                if not lines:
+                    continue
+                yield Chunk(
+                    page_id=page_data["page_id"],
+                    revision_id=page_data["revision_id"],
+                    page_title=page_title,
+                    namespace=page_data["namespace"],
+                    content=f"{page_title} > {name}\n\n" + "\n".join(lines),
+                    chunk_type=ChunkType.INFOBOX,
+                    section_title=heading or "Introduction",
+                    section_level=level,
+                    section_anchor=anchor,
+                    chunk_index=chunk_index,
+                    metadata=page_data["metadata"],
+                    page_url=page_data.get("url"),
+                )
+                chunk_index += 1
+
+            cleaned = self.clean_wikitext(prose)
+            if self.word_count(cleaned) >= self.MIN_CHUNK_SIZE:
+                yield Chunk(
+                    page_id=page_data["page_id"],
+                    revision_id=page_data["revision_id"],
+                    page_title=page_title,
+                    namespace=page_data["namespace"],
+                    content=f"{heading}\n\n{cleaned}" if heading else cleaned,
+                    chunk_type=ChunkType.SECTION if heading else ChunkType.SUMMARY,
+                    section_title=heading or "Introduction",
+                    section_level=level,
+                    section_anchor=anchor,
+                    chunk_index=chunk_index,
+                    metadata=page_data["metadata"],
+                    page_url=page_data.get("url"),
                 )
                 chunk_index += 1
 
+            for caption, headers, rows in tables:
+                context = " > ".join(t for t in (page_title, heading, caption) if t)
+                for start in range(0, len(rows), self.table_rows):
+                    lines = [
+                        self.format_row(headers, row)
+                        for row in rows[start : start + self.table_rows]
+                    ]
+                    yield Chunk(
+                        page_id=page_data["page_id"],
+                        revision_id=page_data["revision_id"],
+                        page_title=page_title,
+                        namespace=page_data["namespace"],
+                        content=context + "\n\n" + "\n".join(lines),
+                        chunk_type=ChunkType.TABLE,
+                        section_title=heading or "Introduction",
+                        section_level=level,
+                        section_anchor=anchor,
+                        chunk_index=chunk_index,
+                        metadata=page_data["metadata"],
+                        page_url=page_data.get("url"),
+                    )
+                    chunk_index += 1
+
+    @classmethod
+    def extract_infoboxes(cls, text: str) -> tuple:
+        """
+        Remove the infobox templates from text
+
+        Returns:
+            The text without its infoboxes, and (name, [(key, value)]) for
+            each; positional parameters are keyed by position, from 1
+        """
+        rest = []
+        infoboxes = []
+        pos = 0
+        while True:
+            start = text.find("{{", pos)
+            if start < 0:
+                break
+            end = cls.find_closing(text, start)
+            if end < 0:
+                break
+            parts = cls.split_top_level(text[start + 2 : end - 2])
+            name = parts[0].strip()
+            if "infobox" not in name.lower():
+                rest.append(text[pos:end])
+                pos = end
+                continue
+
+            params = []
+            for i, part in enumerate(parts[1:], 1):
+                key, sep, value = part.partition("=")
+                if not sep or any(c in key for c in "[{"):
+                    key, value = str(i), part
+                params.append((key.strip(), cls.clean_cell(value)))
+            infoboxes.append((name, params))
+            rest.append(text[pos:start])
+            pos = end
+        rest.append(text[pos:])
+        return "".join(rest), infoboxes
+
+    @staticmethod
+    def find_closing(text: str, start: int) -> int:
+        """Return the end of the template opening at start, or -1"""
+        depth = 0
+        i = start
+        while i < len(text) - 1:
+            pair = text[i : i + 2]
+            if pair == "{{":
+                depth += 1
+                i += 2
+            elif pair == "}}":
+                depth -= 1
+                i += 2
+                if depth == 0:
+                    return i
+            else:
+                i += 1
+        return -1
+
+    @staticmethod
+    def split_top_level(body: str) -> List[str]:
+        """Split a template body on the | outside links and templates"""
+        parts = []
+        depth = 0
+        last = 0
+        for i, ch in enumerate(body):
+            if ch in "[{":
+                depth += 1
+            elif ch in "]}":
+                depth -= 1
+            elif ch == "|" and depth == 0:
+                parts.append(body[last:i])
+                last = i + 1
+        parts.append(body[last:])
+        return parts
+
+    @classmethod
+    def extract_tables(cls, text: str) -> tuple:
+        """
+        Remove the wikitables from text
+
+        Returns:
+            The text without its tables, and (caption, headers, rows) for
+            each table with rows. Nested tables stay in their cell.
+        """
+        prose = []
+        tables = []
+        table_lines = []
+        depth = 0
+
+        for line in text.split("\n"):
+            stripped = line.strip()
+            if stripped.startswith("{|"):
+                depth += 1
+            if depth > 0:
+                table_lines.append(stripped)
+            else:
+                prose.append(line)
+            if stripped.startswith("|}") and depth > 0:
+                depth -= 1
+                if depth == 0:
+                    table = cls.parse_table(table_lines)
+                    if table[2]:
+                        tables.append(table)
+                    table_lines = []
+
+        # An unclosed table is kept as text
+        prose.extend(table_lines)
+        return "\n".join(prose), tables
+
+    @classmethod
+    def parse_table(cls, lines: List[str]) -> tuple:
+        """Parse the lines of a top-level wikitable into (caption,
+        headers, rows), with cells as plain text"""
+        caption = None
+        headers: List[str] = []
+        rows: List[List[str]] = []
+        row: List[str] = []
+        row_is_header = True
+        depth = 0
+
+        def end_row():
+            nonlocal row, row_is_header
+            if row:
+                if row_is_header and not headers and not rows:
+                    headers.extend(row)
+                else:
+                    rows.append(row)
+            row = []
+            row_is_header = True
+
+        for line in lines[1:-1]:
+            # Lines of nested tables belong to the current cell
+            if depth > 0 or line.startswith("{|"):
+                depth += line.startswith("{|") - line.startswith("|}")
+                continue
+            if line.startswith("|+"):
+                caption = cls.clean_cell(line[2:])
+            elif line.startswith("|-"):
+                end_row()
+            elif line.startswith("!"):
+                row.extend(cls.clean_cell(c) for c in re.split(r"!!|\|\|", line[1:]))
+            elif line.startswith("|"):
+                row_is_header = False
+                row.extend(cls.clean_cell(c) for c in line[1:].split("||"))
+            elif row and line:
+                # Continuation of the previous cell
+                row[-1] = f"{row[-1]} {cls.clean_cell(line)}".strip()
+        end_row()
+        return caption, headers, rows
+
+    @classmethod
+    def clean_cell(cls, cell: str) -> str:
+        """Drop a cell's attributes (style="..." | text) and markup"""
+        # Attributes end at the first | outside links and templates
+        depth = 0
+        for i, ch in enumerate(cell):
+            if ch in "[{":
+                depth += 1
+            elif ch in "]}":
+                depth -= 1
+            elif ch == "|" and depth == 0:
+                cell = cell[i + 1 :]
+                break
+        return cls.clean_wikitext(cell).replace("\n", " ")
+
+    @staticmethod
+    def format_row(headers: List[str], row: List[str]) -> str:
+        """Format a row, labeling cells with their headers when they line up"""
+        if headers and len(headers) == len(row):
+            return "; ".join(f"{h}: {v}" for h, v in zip(headers, row) if v)
+        return " | ".join(row)
+
 
 class DatabaseReader:
     """Reads pages from SQLite database"""
 
-    def __init__(self, db_path: str, namespaces: List[int] = None):
+    def __init__(
+        self,
+        db_path: str,
+        namespaces: List[int] = None,
+        base_url: Optional[str] = None,
+    ):
         self.db_path = db_path
         self.namespaces = namespaces or [0]  # Default to main namespace
+        self.base_url = base_url
+        self.article_path = DEFAULT_ARTICLE_PATH
         self.conn = None
 
     def __enter__(self):
         self.conn = sqlite3.connect(self.db_path)
         self.conn.row_factory = sqlite3.Row
+        self._load_archive_meta()
         return self
 
+    def _load_archive_meta(self):
+        """Read the source wiki's URL from archive_meta, if recorded"""
+        cursor = self.conn.cursor()
+        exists = cursor.execute(
+            "SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'archive_meta'"
+        ).fetchone()
+        if not exists:
+            return
+
+        meta = {
+            row["key"]: row["value"]
+            for row in cursor.execute(
+                "SELECT key, value FROM archive_meta "
+                "WHERE key IN ('base_url', 'article_path')"
+            )
+        }
+        # An explicit base URL, such as a mirror's, keeps the default path
+        if not self.base_url:
+            self.base_url = meta.get("base_url") or None
+            self.article_path = meta.get("article_path") or DEFAULT_ARTICLE_PATH
+
     def __exit__(self, exc_type, exc_val, exc_tb):
         if self.conn:
             self.conn.close()
@@ -359,6 +783,11 @@ class DatabaseReader:
                 "namespace": row["namespace"],
                 "revision_id": row["revision_id"],
                 "content": row["content"],
+                "url": (
+                    page_url(self.base_url, row["page_title"], self.article_path)
+                    if self.base_url
+                    else None
+                ),
                 "metadata": {
                     "timestamp": row["timestamp"],
                     "contributor": row["contributor_name"],
@@ -510,13 +939,19 @@ class ChromaDBWriter(VectorDBWriter):
                 "revision_id": chunk.revision_id,
                 "page_title": chunk.page_title,
                 "namespace": chunk.namespace,
-                "chunk_type": chunk.chunk_type,
+                "chunk_type": chunk.chunk_type.value,
                 "chunk_index": chunk.chunk_index,
             }
             if chunk.section_title:
                 metadata["section_title"] = chunk.section_title
             if chunk.section_level:
                 metadata["section_level"] = chunk.section_level
+            if chunk.section_anchor:
+                metadata["section_anchor"] = chunk.section_anchor
+            if chunk.url:
+                metadata["url"] = chunk.url
+            if chunk.tokens is not None:
+                metadata["tokens"] = chunk.tokens
 
             metadatas.append(metadata)
             documents.append(chunk.content)
@@ -549,6 +984,12 @@ Examples:
   # Paragraph-level chunking
   python scripts/vectorize-wiki.py --chunk-level paragraph
 
+  # Keep drop and stat tables intact, 10 rows per chunk
+  python scripts/vectorize-wiki.py --chunk-level table --table-rows 10
+
+  # Fixed windows of 256 words overlapping by 32
+  python scripts/vectorize-wiki.py --chunk-level window --window-size 256 --window-overlap 32
+
   # Output to ChromaDB
   python scripts/vectorize-wiki.py --vector-db chromadb --output chroma_storage
 
@@ -584,11 +1025,32 @@ Examples:
 
     parser.add_argument(
         "--chunk-level",
-        choices=["page", "section", "paragraph"],
+        choices=["page", "section", "paragraph", "window", "table"],
         default="section",
         help="Chunking strategy (default: section)",
     )
 
+    parser.add_argument(
+        "--window-size",
+        type=int,
+        default=256,
+        help="Words per chunk for --chunk-level window (default: 256)",
+    )
+
+    parser.add_argument(
+        "--window-overlap",
+        type=int,
+        default=32,
+        help="Words shared by consecutive windows (default: 32)",
+    )
+
+    parser.add_argument(
+        "--table-rows",
+        type=int,
+        default=10,
+        help="Table rows per chunk for --chunk-level table (default: 10)",
+    )
+
     parser.add_argument(
         "--namespaces",
         type=int,
@@ -597,6 +1059,11 @@ Examples:
         help="Wiki namespaces to include (default: 0 for main namespace)",
     )
 
+    parser.add_argument(
+        "--base-url",
+        help="Wiki or mirror URL for source links (default: the archive's base_url)",
+    )
+
     parser.add_argument(
         "--batch-size",
         type=int,
@@ -632,13 +1099,25 @@ Examples:
         sys.exit(1)
 
     # Initialize chunker
-    chunker = WikiChunker()
+    try:
+        chunker = WikiChunker(
+            window_size=args.window_size,
+            window_overlap=args.window_overlap,
+            table_rows=args.table_rows,
+        )
+    except ValueError as e:
+        logger.error(f"Invalid chunking options: {e}")
+        sys.exit(1)
 
     # Choose chunking method
     if args.chunk_level == "page":
         chunk_method = chunker.chunk_page_level
     elif args.chunk_level == "section":
         chunk_method = chunker.chunk_section_level
+    elif args.chunk_level == "window":
+        chunk_method = chunker.chunk_window_level
+    elif args.chunk_level == "table":
+        chunk_method = chunker.chunk_table_aware
     else:
         chunk_method = chunker.chunk_paragraph_level
 
@@ -661,7 +1140,10 @@ Examples:
 
     logger.info("Starting vectorization...")
 
-    with DatabaseReader(args.db, args.namespaces) as db:
+    with DatabaseReader(args.db, args.namespaces, args.base_url) as db:
+        base_url = db.base_url
+        if not base_url:
+            logger.warning("No base URL in the archive; chunks won't have source URLs")
         page_count = db.count_pages()
         logger.info(f"Processing {page_count} pages from namespaces {args.namespaces}")
 
@@ -687,6 +1169,10 @@ Examples:
                             show_progress_bar=False,
                             convert_to_numpy=True,
                         )
+                        for chunk, tokens in zip(
+                            batch_chunks, count_tokens(model, batch_contents)
+                        ):
+                            chunk.tokens = tokens
 
                         # Write to vector DB
                         writer.add_chunks(batch_chunks, embeddings)
@@ -705,6 +1191,8 @@ Examples:
                 show_progress_bar=False,
                 convert_to_numpy=True,
             )
+            for chunk, tokens in zip(batch_chunks, count_tokens(model, batch_contents)):
+                chunk.tokens = tokens
             writer.add_chunks(batch_chunks, embeddings)
 
     # Finalize
@@ -718,9 +1206,15 @@ Examples:
         "model": args.model,
         "embedding_dim": embedding_dim,
         "chunk_level": args.chunk_level,
+        "chunk_options": {
+            "window_size": args.window_size,
+            "window_overlap": args.window_overlap,
+            "table_rows": args.table_rows,
+        },
         "namespaces": args.namespaces,
         "vector_db": args.vector_db,
         "collection_name": args.collection_name,
+        "base_url": base_url,
         "total_pages": total_pages,
         "total_chunks": total_chunks,
         "elapsed_seconds": int(elapsed),
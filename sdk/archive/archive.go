@@ -0,0 +1,426 @@
+// Package archive wires an archive's client, its vector index and its
+// mirrored media files together behind one object, for applications that
+// would otherwise integrate the three themselves.
+//
+// The vector client lives in its own module (sdk/vector), so it's plugged
+// in as a function:
+//
+//	vc, err := vector.NewVectorClient(vector.Config{URL: "http://localhost:6333", Embedder: embedder})
+//	// ...
+//	a, err := archive.Open("irowiki.db", archive.Options{
+//	    FilesDir: "files",
+//	    Vector: func(ctx context.Context, query string, limit int) ([]archive.VectorHit, error) {
+//	        results, err := vc.Search(ctx, query, limit)
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        hits := make([]archive.VectorHit, len(results))
+//	        for i, r := range results {
+//	            hits[i] = archive.VectorHit{Title: r.PageTitle, Content: r.Content, Score: float64(r.Score), URL: r.URL}
+//	        }
+//	        return hits, nil
+//	    },
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer a.Close()
+//
+//	hits, err := a.HybridSearch(ctx, "GX build", 10)
+//	page, err := a.GetPage(ctx, "Poring")
+package archive
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/filestore"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/render"
+)
+
+// rrfK is the rank constant of reciprocal rank fusion, as in eval.Hybrid.
+const rrfK = 60
+
+// fusionDepth is the minimum number of results HybridSearch fetches from
+// each search, so pages ranked just outside the limit by one can still be
+// lifted by the other.
+const fusionDepth = 50
+
+// suggestQueryLength is the number of bytes of a page's wikitext
+// SuggestCategories searches the vector index with.
+const suggestQueryLength = 2000
+
+// VectorHit is a result of semantic search.
+type VectorHit struct {
+	// Title is the title of the page the chunk belongs to.
+	Title string
+
+	// Content is the matching chunk.
+	Content string
+
+	// Score is the similarity to the query.
+	Score float64
+
+	// URL deep-links to the chunk's section, if known.
+	URL string
+}
+
+// VectorSearchFunc runs a semantic search, returning up to limit chunks
+// most similar to query first.
+type VectorSearchFunc func(ctx context.Context, query string, limit int) ([]VectorHit, error)
+
+// Options configures Open and New.
+type Options struct {
+	// Connection configures the archive's client when opened by Open.
+	Connection irowiki.ConnectionOptions
+
+	// Vector runs semantic search, typically with a vector.VectorClient.
+	// Optional; without it HybridSearch uses full-text search alone.
+	Vector VectorSearchFunc
+
+	// VectorWeight is the weight of semantic results in HybridSearch,
+	// from 0 to 1; full-text results get the rest. Default: 0.5.
+	VectorWeight float64
+
+	// FilesDir is the directory of media files mirrored by the scraper.
+	// Optional; without it pages' images have no local paths.
+	FilesDir string
+
+	// Render renders pages without captured HTML.
+	Render render.Options
+}
+
+// Validate checks if the Options are valid.
+func (o *Options) Validate() error {
+	if o.VectorWeight < 0 || o.VectorWeight > 1 {
+		return fmt.Errorf("vector_weight must be between 0 and 1")
+	}
+	return nil
+}
+
+// Archive is an archive with its optional vector index and media files.
+type Archive struct {
+	// Client queries the archive.
+	Client irowiki.Client
+
+	// Files is the mirrored media, or nil without Options.FilesDir.
+	Files *filestore.Store
+
+	vector       VectorSearchFunc
+	vectorWeight float64
+	render       render.Options
+}
+
+// Open opens the archive at location (see irowiki.Open) with the vector
+// index and media files given by opts.
+func Open(location string, opts Options) (*Archive, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", irowiki.ErrInvalidInput, err)
+	}
+	client, err := irowiki.OpenWithOptions(location, opts.Connection)
+	if err != nil {
+		return nil, err
+	}
+	a, err := New(client, opts)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// New wraps an open client. Closing the Archive closes the client.
+func New(client irowiki.Client, opts Options) (*Archive, error) {
+	if client == nil {
+		return nil, fmt.Errorf("%w: client is required", irowiki.ErrInvalidInput)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", irowiki.ErrInvalidInput, err)
+	}
+
+	a := &Archive{
+		Client:       client,
+		vector:       opts.Vector,
+		vectorWeight: opts.VectorWeight,
+		render:       opts.Render,
+	}
+	if a.vectorWeight == 0 {
+		a.vectorWeight = 0.5
+	}
+	if opts.FilesDir != "" {
+		files, err := filestore.Open(opts.FilesDir)
+		if err != nil {
+			return nil, err
+		}
+		a.Files = files
+	}
+	return a, nil
+}
+
+// HasVector reports whether the archive has semantic search.
+func (a *Archive) HasVector() bool {
+	return a.vector != nil
+}
+
+// Close closes the archive's client.
+func (a *Archive) Close() error {
+	return a.Client.Close()
+}
+
+// SearchHit is a page found by HybridSearch.
+type SearchHit struct {
+	Title string
+
+	// Snippet is the full-text snippet, or the best semantic chunk for
+	// pages only semantic search found.
+	Snippet string
+
+	// Score is the page's fused score; higher is better.
+	Score float64
+
+	// Lexical and Semantic report which searches found the page.
+	Lexical  bool
+	Semantic bool
+
+	// URL deep-links to the page's best semantic chunk, if known.
+	URL string
+}
+
+// HybridSearch searches the archive's full text and, if it has one, its
+// vector index, fusing their rankings with weighted reciprocal rank fusion.
+// A page with several matching chunks counts once, at its best rank.
+func (a *Archive) HybridSearch(ctx context.Context, query string, limit int) ([]SearchHit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive, got %d", irowiki.ErrInvalidInput, limit)
+	}
+	depth := max(limit, fusionDepth)
+
+	lexicalWeight := 1.0
+	if a.vector != nil {
+		lexicalWeight = 1 - a.vectorWeight
+	}
+
+	hits := make(map[string]*SearchHit)
+	var order []string
+	hit := func(title string) *SearchHit {
+		key := normalizeTitle(title)
+		h, ok := hits[key]
+		if !ok {
+			h = &SearchHit{Title: title}
+			hits[key] = h
+			order = append(order, key)
+		}
+		return h
+	}
+
+	results, err := a.Client.SearchFullText(ctx, query, irowiki.SearchOptions{Namespace: -1, Limit: depth})
+	if err != nil {
+		return nil, fmt.Errorf("full-text search failed: %w", err)
+	}
+	for rank, r := range results {
+		h := hit(r.Title)
+		if h.Lexical {
+			continue
+		}
+		h.Lexical = true
+		h.Snippet = r.Snippet
+		h.Score += lexicalWeight / float64(rrfK+rank+1)
+	}
+
+	if a.vector != nil {
+		chunks, err := a.vector(ctx, query, depth)
+		if err != nil {
+			return nil, fmt.Errorf("vector search failed: %w", err)
+		}
+		rank := 0
+		for _, c := range chunks {
+			h := hit(c.Title)
+			if h.Semantic {
+				continue
+			}
+			h.Semantic = true
+			h.URL = c.URL
+			if h.Snippet == "" {
+				h.Snippet = c.Content
+			}
+			h.Score += a.vectorWeight / float64(rrfK+rank+1)
+			rank++
+		}
+	}
+
+	slices.SortStableFunc(order, func(x, y string) int {
+		return cmp.Compare(hits[y].Score, hits[x].Score)
+	})
+	out := make([]SearchHit, 0, min(limit, len(order)))
+	for _, key := range order[:min(limit, len(order))] {
+		out = append(out, *hits[key])
+	}
+	return out, nil
+}
+
+// SuggestCategories proposes up to n categories (0 for default 10) for a
+// page. With a vector index, they come from the categorized pages
+// semantically closest to the page's wikitext, weighted by rank as in
+// HybridSearch; without, from Client.SuggestCategories's shared templates.
+// Categories the page is already in aren't proposed.
+func (a *Archive) SuggestCategories(ctx context.Context, title string, n int) ([]irowiki.CategorySuggestion, error) {
+	if a.vector == nil {
+		return a.Client.SuggestCategories(ctx, title, n)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("%w: negative suggestion count", irowiki.ErrInvalidInput)
+	}
+	if n == 0 {
+		n = 10
+	}
+
+	page, err := a.Client.GetPage(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+	query := page.Content
+	if len(query) > suggestQueryLength {
+		query = strings.ToValidUTF8(query[:suggestQueryLength], "")
+	}
+	chunks, err := a.vector(ctx, page.Title+"\n"+query, fusionDepth)
+	if err != nil {
+		return nil, fmt.Errorf("vector search failed: %w", err)
+	}
+
+	// Each page counts once, at its best rank
+	self := normalizeTitle(page.Title)
+	seen := map[string]bool{self: true}
+	var titles []string
+	for _, c := range chunks {
+		if key := normalizeTitle(c.Title); !seen[key] {
+			seen[key] = true
+			titles = append(titles, c.Title)
+		}
+	}
+	if len(titles) == 0 {
+		return []irowiki.CategorySuggestion{}, nil
+	}
+	resolved, err := a.Client.GetPagesByTitle(ctx, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	var neighbours []irowiki.CategoryNeighbour
+	for rank, r := range resolved {
+		if !r.Found() {
+			continue
+		}
+		categories := irowiki.PageCategories(r.Page.Content)
+		if len(categories) == 0 {
+			continue
+		}
+		neighbours = append(neighbours, irowiki.CategoryNeighbour{
+			Title:      r.Page.Title,
+			Weight:     1 / float64(rrfK+rank+1),
+			Categories: categories,
+		})
+	}
+	return irowiki.RankCategories(neighbours, irowiki.PageCategories(page.Content), n), nil
+}
+
+// Page is a page with its HTML and images.
+type Page struct {
+	*irowiki.Page
+
+	// HTML is the source wiki's rendering of the latest revision if it was
+	// captured, or else the SDK's rendering of its wikitext.
+	HTML string
+
+	// Rendered reports whether HTML was rendered by the SDK.
+	Rendered bool
+
+	// Images are the files the page embeds, in order of appearance.
+	Images []Image
+}
+
+// Image is a file embedded in a page.
+type Image struct {
+	// Name is the file name as the page embeds it.
+	Name string
+
+	// File is the file's metadata, or nil if the archive has none.
+	File *irowiki.File
+
+	// Path is the mirrored copy of the file, or "" if it isn't mirrored.
+	Path string
+}
+
+// GetPage returns a page with its HTML and the files it embeds.
+func (a *Archive) GetPage(ctx context.Context, title string) (*Page, error) {
+	p, err := a.Client.GetPage(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{Page: p}
+
+	captured, err := a.Client.GetPageHTML(ctx, p.Title)
+	switch {
+	case err == nil && captured.Current():
+		page.HTML = captured.HTML
+	case err == nil || errors.Is(err, irowiki.ErrNotFound):
+		page.HTML = render.HTML(p.Content, a.render)
+		page.Rendered = true
+	default:
+		return nil, err
+	}
+
+	for _, name := range irowiki.EmbeddedFiles(p.Content) {
+		image := Image{Name: name}
+		if image.File, err = a.getFile(ctx, name); err != nil {
+			return nil, err
+		}
+		if a.Files != nil {
+			lookup := name
+			if image.File != nil {
+				lookup = image.File.Filename
+			}
+			image.Path, _ = a.Files.Find(lookup)
+		}
+		page.Images = append(page.Images, image)
+	}
+	return page, nil
+}
+
+// getFile looks up a file's metadata under its name as embedded and as
+// MediaWiki stores it, with underscores. It returns nil if there's none.
+func (a *Archive) getFile(ctx context.Context, name string) (*irowiki.File, error) {
+	for _, filename := range []string{name, underscored(name)} {
+		file, err := a.Client.GetFile(ctx, filename)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, irowiki.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// normalizeTitle applies MediaWiki title rules, so the two searches agree
+// on a page's title: underscores become spaces and the first letter is
+// upper-cased.
+func normalizeTitle(title string) string {
+	title = strings.TrimSpace(strings.ReplaceAll(title, "_", " "))
+	if title == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(title)
+	return string(unicode.ToUpper(r)) + title[size:]
+}
+
+// underscored returns a title with spaces as underscores.
+func underscored(title string) string {
+	return strings.ReplaceAll(title, " ", "_")
+}
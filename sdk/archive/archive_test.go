@@ -0,0 +1,203 @@
+package archive_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/archive"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestArchive_HybridSearch tests fusing full-text and semantic rankings
+func TestArchive_HybridSearch(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	var asked int
+	a, err := archive.Open(tdb.Path, archive.Options{
+		Vector: func(ctx context.Context, query string, limit int) ([]archive.VectorHit, error) {
+			asked = limit
+			return []archive.VectorHit{
+				{Title: "Poring", Content: "A pink slime.", Score: 0.9, URL: "https://irowiki.org/wiki/Poring"},
+				{Title: "Poring", Content: "Drops jellopy.", Score: 0.8},
+				{Title: "Prontera", Content: "The capital city.", Score: 0.7},
+			}, nil
+		},
+		VectorWeight: 0.6,
+	})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer a.Close()
+
+	hits, err := a.HybridSearch(context.Background(), "capital", 5)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if asked < 50 {
+		t.Errorf("expected at least 50 semantic results to be fused, asked for %d", asked)
+	}
+
+	// Prontera is found by both searches, Poring (twice) by semantic only
+	if len(hits) != 2 {
+		t.Fatalf("expected 2 pages, got %+v", hits)
+	}
+	if hits[0].Title != "Prontera" || !hits[0].Lexical || !hits[0].Semantic {
+		t.Errorf("expected Prontera first from both searches, got %+v", hits[0])
+	}
+	if hits[1].Title != "Poring" || hits[1].Lexical || hits[1].Snippet != "A pink slime." || hits[1].URL == "" {
+		t.Errorf("expected Poring from its best chunk, got %+v", hits[1])
+	}
+	if !a.HasVector() {
+		t.Error("expected the archive to have semantic search")
+	}
+
+	if _, err := a.HybridSearch(context.Background(), "capital", 0); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for limit 0, got %v", err)
+	}
+}
+
+// TestArchive_HybridSearch_LexicalOnly tests searching without a vector index
+func TestArchive_HybridSearch_LexicalOnly(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	a, err := archive.Open(tdb.Path, archive.Options{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer a.Close()
+
+	hits, err := a.HybridSearch(context.Background(), "capital", 5)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Title != "Prontera" || hits[0].Semantic {
+		t.Errorf("expected Prontera from full-text search, got %+v", hits)
+	}
+}
+
+// TestArchive_GetPage tests rendering a page with its embedded images
+func TestArchive_GetPage(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`UPDATE revisions SET content = 'Poring is a [[Monster]]. [[File:Example.png|thumb]] [[Image:Missing.png]]' WHERE revision_id = 104`)
+	if err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+
+	files := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(files, "File", "E"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(files, "File", "E", "Example.png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := archive.Open(tdb.Path, archive.Options{FilesDir: files})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer a.Close()
+
+	page, err := a.GetPage(context.Background(), "Poring")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+
+	// Without captured HTML, the wikitext is rendered
+	if !page.Rendered || !strings.Contains(page.HTML, `href="/wiki/Monster"`) {
+		t.Errorf("expected rendered HTML, got %q", page.HTML)
+	}
+
+	if len(page.Images) != 2 {
+		t.Fatalf("expected 2 images, got %+v", page.Images)
+	}
+	example := page.Images[0]
+	if example.Name != "Example.png" || example.File == nil || example.File.Size != 12345 {
+		t.Errorf("expected Example.png with metadata, got %+v", example)
+	}
+	if example.Path != filepath.Join(files, "File", "E", "Example.png") {
+		t.Errorf("expected the mirrored copy of Example.png, got %q", example.Path)
+	}
+	if missing := page.Images[1]; missing.File != nil || missing.Path != "" {
+		t.Errorf("expected Missing.png without metadata or copy, got %+v", missing)
+	}
+
+	if _, err := a.GetPage(context.Background(), "Nonexistent"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestArchive_SuggestCategories tests proposing the categories of the pages
+// semantically closest to a page
+func TestArchive_SuggestCategories(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	for _, stmt := range []string{
+		`UPDATE revisions SET content = 'A pink slime. [[Category:Monsters]]' WHERE revision_id = 104`,
+		`UPDATE revisions SET content = 'The capital city. [[Category:Cities]] [[Category:Monsters]]' WHERE revision_id = 103`,
+	} {
+		if _, err := tdb.DB.Exec(stmt); err != nil {
+			t.Fatalf("failed to update fixture: %v", err)
+		}
+	}
+
+	var query string
+	a, err := archive.Open(tdb.Path, archive.Options{
+		Vector: func(ctx context.Context, q string, limit int) ([]archive.VectorHit, error) {
+			query = q
+			return []archive.VectorHit{
+				{Title: "Poring", Score: 1},
+				{Title: "Prontera", Score: 0.8},
+				{Title: "Prontera", Score: 0.7},
+				{Title: "Main_Page", Score: 0.6},
+				{Title: "Nonexistent", Score: 0.5},
+			}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer a.Close()
+
+	// Poring itself and the uncategorized Main_Page don't count, and
+	// Monsters is Poring's own
+	suggestions, err := a.SuggestCategories(context.Background(), "Poring", 0)
+	if err != nil {
+		t.Fatalf("SuggestCategories failed: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Category != "Cities" || suggestions[0].Score != 1 || suggestions[0].Pages[0] != "Prontera" {
+		t.Errorf("expected Cities from Prontera, got %+v", suggestions)
+	}
+	if !strings.Contains(query, "A pink slime.") {
+		t.Errorf("expected the page's wikitext as the query, got %q", query)
+	}
+
+	if _, err := a.SuggestCategories(context.Background(), "Nonexistent", 0); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestOpen_InvalidOptions tests option validation
+func TestOpen_InvalidOptions(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	if _, err := archive.Open(tdb.Path, archive.Options{VectorWeight: 2}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for vector weight 2, got %v", err)
+	}
+	if _, err := archive.Open(tdb.Path, archive.Options{FilesDir: filepath.Join(t.TempDir(), "missing")}); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing files directory, got %v", err)
+	}
+	if _, err := archive.New(nil, archive.Options{}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for a nil client, got %v", err)
+	}
+}
@@ -0,0 +1,395 @@
+package arrowipc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/arrowipc"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// fbTable reads a FlatBuffers table.
+type fbTable struct {
+	t   *testing.T
+	buf []byte
+	pos int
+}
+
+func (f fbTable) u16(pos int) int { return int(binary.LittleEndian.Uint16(f.buf[pos:])) }
+func (f fbTable) u32(pos int) int { return int(binary.LittleEndian.Uint32(f.buf[pos:])) }
+
+// field returns the position of a field, or 0 if it's absent.
+func (f fbTable) field(slot int) int {
+	vtable := f.pos - int(int32(binary.LittleEndian.Uint32(f.buf[f.pos:])))
+	if o := 4 + 2*slot; o < f.u16(vtable) && f.u16(vtable+o) != 0 {
+		return f.pos + f.u16(vtable+o)
+	}
+	return 0
+}
+
+func (f fbTable) table(slot int) fbTable {
+	p := f.field(slot)
+	return fbTable{f.t, f.buf, p + f.u32(p)}
+}
+
+func (f fbTable) vector(slot int) (start, n int) {
+	p := f.field(slot)
+	if p == 0 {
+		return 0, 0
+	}
+	p += f.u32(p)
+	return p + 4, f.u32(p)
+}
+
+func (f fbTable) str(slot int) string {
+	start, n := f.vector(slot)
+	return string(f.buf[start : start+n])
+}
+
+func (f fbTable) int64At(pos int) int64 {
+	if pos%8 != 0 {
+		f.t.Fatalf("misaligned long at %d", pos)
+	}
+	return int64(binary.LittleEndian.Uint64(f.buf[pos:]))
+}
+
+func (f fbTable) int64(slot int) int64 {
+	if p := f.field(slot); p != 0 {
+		return f.int64At(p)
+	}
+	return 0
+}
+
+func (f fbTable) int16(slot int) int {
+	if p := f.field(slot); p != 0 {
+		return f.u16(p)
+	}
+	return 0
+}
+
+func (f fbTable) uint8(slot int) int {
+	if p := f.field(slot); p != 0 {
+		return int(f.buf[p])
+	}
+	return 0
+}
+
+// decoded is a stream read back by decode.
+type decoded struct {
+	fields  []arrowipc.Field
+	batches int
+	rows    [][]any
+}
+
+// decode reads an Arrow IPC stream as arrowipc writes it.
+func decode(t *testing.T, stream []byte) decoded {
+	t.Helper()
+
+	var d decoded
+	for {
+		if len(stream) < 8 || binary.LittleEndian.Uint32(stream) != 0xFFFFFFFF {
+			t.Fatalf("expected a continuation marker, got %x", stream[:min(len(stream), 8)])
+		}
+		metaLen := int(binary.LittleEndian.Uint32(stream[4:]))
+		if metaLen == 0 {
+			if len(stream) != 8 {
+				t.Fatalf("expected the stream to end, got %d more bytes", len(stream)-8)
+			}
+			return d
+		}
+		if metaLen%8 != 0 {
+			t.Fatalf("metadata length %d isn't padded to 8 bytes", metaLen)
+		}
+		meta := stream[8 : 8+metaLen]
+		msg := fbTable{t, meta, int(binary.LittleEndian.Uint32(meta))}
+		if v := msg.int16(0); v != 4 {
+			t.Fatalf("expected metadata version V5, got %d", v)
+		}
+		bodyLen := int(msg.int64(3))
+		body := stream[8+metaLen : 8+metaLen+bodyLen]
+		stream = stream[8+metaLen+bodyLen:]
+
+		header := msg.table(2)
+		switch msg.uint8(1) {
+		case 1: // Schema
+			start, n := header.vector(1)
+			for i := range n {
+				p := start + 4*i
+				field := fbTable{t, meta, p + header.u32(p)}
+				if _, children := field.vector(5); field.field(5) == 0 || children != 0 {
+					t.Errorf("expected an empty children vector")
+				}
+				typ := field.table(3)
+				var ft arrowipc.Type
+				switch field.uint8(2) {
+				case 2:
+					if typ.u32(typ.field(0)) != 64 || typ.uint8(1) != 1 {
+						t.Errorf("expected a signed 64-bit int")
+					}
+					ft = arrowipc.Int64
+				case 3:
+					ft = arrowipc.Float64
+				case 5:
+					ft = arrowipc.String
+				case 6:
+					ft = arrowipc.Bool
+				case 10:
+					if typ.int16(0) != 2 || typ.str(1) != "UTC" {
+						t.Errorf("expected microseconds in UTC")
+					}
+					ft = arrowipc.Timestamp
+				}
+				d.fields = append(d.fields, arrowipc.Field{Name: field.str(0), Type: ft, Nullable: field.uint8(1) == 1})
+			}
+		case 3: // RecordBatch
+			d.batches++
+			length := int(header.int64(0))
+			nodesStart, _ := header.vector(1)
+			bufStart, _ := header.vector(2)
+			buffer := func(i int) []byte {
+				off := header.int64At(bufStart + 16*i)
+				if off%8 != 0 {
+					t.Fatalf("misaligned buffer at %d", off)
+				}
+				return body[off : off+header.int64At(bufStart+16*i+8)]
+			}
+			bit := func(b []byte, i int) bool { return b[i/8]&(1<<(i%8)) != 0 }
+
+			rows := make([][]any, length)
+			for i := range rows {
+				rows[i] = make([]any, len(d.fields))
+			}
+			b := 0
+			for c, f := range d.fields {
+				if n := header.int64At(nodesStart + 16*c); int(n) != length {
+					t.Fatalf("column %d has %d rows, want %d", c, n, length)
+				}
+				valid := buffer(b)
+				values := buffer(b + 1)
+				b += 2
+				var data []byte
+				if f.Type == arrowipc.String {
+					data = buffer(b)
+					b++
+				}
+				for i := range length {
+					if len(valid) > 0 && !bit(valid, i) {
+						continue
+					}
+					switch f.Type {
+					case arrowipc.Int64:
+						rows[i][c] = int64(binary.LittleEndian.Uint64(values[8*i:]))
+					case arrowipc.Float64:
+						rows[i][c] = math.Float64frombits(binary.LittleEndian.Uint64(values[8*i:]))
+					case arrowipc.Bool:
+						rows[i][c] = bit(values, i)
+					case arrowipc.String:
+						rows[i][c] = string(data[binary.LittleEndian.Uint32(values[4*i:]):binary.LittleEndian.Uint32(values[4*i+4:])])
+					case arrowipc.Timestamp:
+						rows[i][c] = time.UnixMicro(int64(binary.LittleEndian.Uint64(values[8*i:]))).UTC()
+					}
+				}
+			}
+			d.rows = append(d.rows, rows...)
+		default:
+			t.Fatalf("unexpected message type %d", msg.uint8(1))
+		}
+	}
+}
+
+// TestWriter tests writing columns of each type, with nulls
+func TestWriter(t *testing.T) {
+	fields := []arrowipc.Field{
+		{Name: "id", Type: arrowipc.Int64},
+		{Name: "score", Type: arrowipc.Float64, Nullable: true},
+		{Name: "minor", Type: arrowipc.Bool},
+		{Name: "user", Type: arrowipc.String, Nullable: true},
+		{Name: "timestamp", Type: arrowipc.Timestamp, Nullable: true},
+	}
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	user := "Admin"
+
+	var buf bytes.Buffer
+	w := arrowipc.NewWriter(&buf, fields)
+	rows := [][]any{
+		{int64(1), 0.5, true, &user, ts},
+		{2, nil, false, nil, time.Time{}},
+		{uint16(3), 2, true, "Pörìng", ts.Add(time.Hour)},
+	}
+	for _, row := range rows {
+		if err := w.Write(row...); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	d := decode(t, buf.Bytes())
+	if !reflect.DeepEqual(d.fields, fields) {
+		t.Errorf("expected fields %+v, got %+v", fields, d.fields)
+	}
+	want := [][]any{
+		{int64(1), 0.5, true, "Admin", ts},
+		{int64(2), nil, false, nil, nil},
+		{int64(3), 2.0, true, "Pörìng", ts.Add(time.Hour)},
+	}
+	if !reflect.DeepEqual(d.rows, want) {
+		t.Errorf("expected rows %v, got %v", want, d.rows)
+	}
+
+	// Test: Writes after Close fail
+	if err := w.Write(1, 1.0, true, "x", ts); err == nil {
+		t.Error("expected an error writing to a closed Writer")
+	}
+}
+
+// TestWriter_Batches tests splitting long streams into record batches
+func TestWriter_Batches(t *testing.T) {
+	var buf bytes.Buffer
+	w := arrowipc.NewWriter(&buf, []arrowipc.Field{{Name: "n", Type: arrowipc.Int64}})
+	for i := range arrowipc.BatchSize + 2 {
+		if err := w.Write(i); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	d := decode(t, buf.Bytes())
+	if d.batches != 2 || len(d.rows) != arrowipc.BatchSize+2 {
+		t.Fatalf("expected %d rows in 2 batches, got %d in %d", arrowipc.BatchSize+2, len(d.rows), d.batches)
+	}
+	if last := d.rows[len(d.rows)-1][0]; last != int64(arrowipc.BatchSize+1) {
+		t.Errorf("expected last value %d, got %v", arrowipc.BatchSize+1, last)
+	}
+
+	// Test: Empty streams still carry their schema
+	buf.Reset()
+	if err := arrowipc.NewWriter(&buf, []arrowipc.Field{{Name: "n", Type: arrowipc.Int64}}).Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if d := decode(t, buf.Bytes()); len(d.fields) != 1 || d.batches != 0 {
+		t.Errorf("expected a schema without batches, got %+v", d)
+	}
+}
+
+// TestWriter_Errors tests rejecting values that don't fit their column
+func TestWriter_Errors(t *testing.T) {
+	fields := []arrowipc.Field{{Name: "id", Type: arrowipc.Int64}, {Name: "user", Type: arrowipc.String}}
+
+	tests := []struct {
+		name string
+		row  []any
+	}{
+		{"null in non-nullable column", []any{1, nil}},
+		{"wrong type", []any{"1", "Admin"}},
+		{"wrong length", []any{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := arrowipc.NewWriter(&bytes.Buffer{}, fields)
+			if err := w.Write(tt.row...); err == nil {
+				t.Errorf("expected an error writing %v", tt.row)
+			}
+		})
+	}
+}
+
+// TestEncodeRevisions tests streaming a page's history from the fixture
+func TestEncodeRevisions(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	revisions := irowiki.PageHistoryRevisions(ctx, client, "Prontera", irowiki.HistoryOptions{ExcludeContent: true})
+	if err := arrowipc.EncodeRevisions(&buf, revisions); err != nil {
+		t.Fatalf("EncodeRevisions failed: %v", err)
+	}
+
+	d := decode(t, buf.Bytes())
+	var names []string
+	for _, f := range d.fields {
+		names = append(names, f.Name)
+	}
+	wantNames := []string{"id", "page_id", "parent_id", "timestamp", "user", "user_id", "comment", "size", "sha1", "minor"}
+	if !slices.Equal(names, wantNames) {
+		t.Errorf("expected columns %v, got %v", wantNames, names)
+	}
+	if len(d.rows) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(d.rows))
+	}
+	if got := fmt.Sprint(d.rows[0][:3], d.rows[1][2], d.rows[0][9]); got != "[103 2 102] <nil> true" {
+		t.Errorf("unexpected revisions: %v", d.rows)
+	}
+
+	// Test: Errors leave the stream unterminated
+	buf.Reset()
+	err = arrowipc.EncodeRevisions(&buf, irowiki.PageHistoryRevisions(ctx, client, "Nonexistent", irowiki.HistoryOptions{}))
+	if !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %d bytes", buf.Len())
+	}
+}
+
+// TestEncodeRecords tests streaming stats series of SDK types
+func TestEncodeRecords(t *testing.T) {
+	stats := []irowiki.EditorStat{
+		{Username: "Admin", EditCount: 4, FirstEdit: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Username: "UpdateBot", EditCount: 1, Bot: true},
+	}
+
+	var buf bytes.Buffer
+	err := arrowipc.EncodeRecords(&buf, func(yield func(irowiki.EditorStat, error) bool) {
+		for _, s := range stats {
+			if !yield(s, nil) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("EncodeRecords failed: %v", err)
+	}
+
+	d := decode(t, buf.Bytes())
+	want := []arrowipc.Field{
+		{Name: "username", Type: arrowipc.String},
+		{Name: "edit_count", Type: arrowipc.Int64},
+		{Name: "first_edit", Type: arrowipc.Timestamp, Nullable: true},
+		{Name: "last_edit", Type: arrowipc.Timestamp, Nullable: true},
+		{Name: "minor_edits", Type: arrowipc.Int64},
+		{Name: "pages_edited", Type: arrowipc.Int64},
+		{Name: "bot", Type: arrowipc.Bool},
+	}
+	if !reflect.DeepEqual(d.fields, want) {
+		t.Errorf("expected fields %+v, got %+v", want, d.fields)
+	}
+	if len(d.rows) != 2 || d.rows[1][0] != "UpdateBot" || d.rows[1][2] != nil || d.rows[1][6] != true {
+		t.Errorf("unexpected rows: %v", d.rows)
+	}
+
+	// Test: Slices and maps are left out
+	fields := arrowipc.FieldsOf(reflect.TypeFor[irowiki.PageCohort]())
+	if len(fields) != 2 || fields[0].Name != "month" || fields[1].Name != "pages" {
+		t.Errorf("expected month and pages columns, got %+v", fields)
+	}
+}
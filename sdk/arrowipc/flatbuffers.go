@@ -0,0 +1,162 @@
+package arrowipc
+
+import "encoding/binary"
+
+// builder builds a FlatBuffer back to front, as the FlatBuffers libraries
+// do: objects are prepended, and an object's offset is its distance from
+// the end of the buffer, so objects can only refer to ones built before
+// them. It covers the subset of FlatBuffers that Arrow's metadata needs.
+type builder struct {
+	buf      []byte
+	minAlign int
+
+	// fields holds the offsets of the fields of the table being built,
+	// indexed by vtable slot, and 0 for absent fields
+	fields     []int
+	tableStart int
+}
+
+// offset returns the current offset, that of the last object prepended.
+func (b *builder) offset() int {
+	return len(b.buf)
+}
+
+// prepend adds p to the front of the buffer.
+func (b *builder) prepend(p ...byte) {
+	b.buf = append(p, b.buf...)
+}
+
+// prep pads the buffer so that a value of size bytes, written after
+// additional bytes, is aligned to size.
+func (b *builder) prep(size, additional int) {
+	b.minAlign = max(b.minAlign, size)
+	pad := -(len(b.buf) + additional) & (size - 1)
+	b.prepend(make([]byte, pad)...)
+}
+
+func (b *builder) prependUint8(v uint8) {
+	b.prepend(v)
+}
+
+func (b *builder) prependUint16(v uint16) {
+	b.prep(2, 0)
+	b.prepend(binary.LittleEndian.AppendUint16(nil, v)...)
+}
+
+func (b *builder) prependUint32(v uint32) {
+	b.prep(4, 0)
+	b.prepend(binary.LittleEndian.AppendUint32(nil, v)...)
+}
+
+func (b *builder) prependInt64(v int64) {
+	b.prep(8, 0)
+	b.prepend(binary.LittleEndian.AppendUint64(nil, uint64(v))...)
+}
+
+// prependOffset writes a reference to the object at off.
+func (b *builder) prependOffset(off int) {
+	b.prep(4, 0)
+	b.prependUint32(uint32(len(b.buf) + 4 - off))
+}
+
+// createString writes a null-terminated string and returns its offset.
+func (b *builder) createString(s string) int {
+	b.prep(4, len(s)+1)
+	b.prepend(append([]byte(s), 0)...)
+	b.prependUint32(uint32(len(s)))
+	return b.offset()
+}
+
+// createOffsets writes a vector of references and returns its offset.
+func (b *builder) createOffsets(offs []int) int {
+	b.prep(4, 4*len(offs))
+	for i := len(offs) - 1; i >= 0; i-- {
+		b.prependOffset(offs[i])
+	}
+	b.prependUint32(uint32(len(offs)))
+	return b.offset()
+}
+
+// createInt64Pairs writes a vector of structs of two longs, such as Arrow's
+// FieldNode and Buffer, and returns its offset.
+func (b *builder) createInt64Pairs(pairs [][2]int64) int {
+	b.prep(4, 16*len(pairs))
+	b.prep(8, 16*len(pairs))
+	for i := len(pairs) - 1; i >= 0; i-- {
+		b.prependInt64(pairs[i][1])
+		b.prependInt64(pairs[i][0])
+	}
+	b.prependUint32(uint32(len(pairs)))
+	return b.offset()
+}
+
+// startTable begins a table with n vtable slots.
+func (b *builder) startTable(n int) {
+	b.fields = make([]int, n)
+	b.tableStart = b.offset()
+}
+
+// Field setters write a field of the table being built into slot.
+
+func (b *builder) addUint8(slot int, v uint8) {
+	b.prependUint8(v)
+	b.fields[slot] = b.offset()
+}
+
+func (b *builder) addBool(slot int, v bool) {
+	var u uint8
+	if v {
+		u = 1
+	}
+	b.addUint8(slot, u)
+}
+
+func (b *builder) addInt16(slot int, v int16) {
+	b.prependUint16(uint16(v))
+	b.fields[slot] = b.offset()
+}
+
+func (b *builder) addInt32(slot int, v int32) {
+	b.prependUint32(uint32(v))
+	b.fields[slot] = b.offset()
+}
+
+func (b *builder) addInt64(slot int, v int64) {
+	b.prependInt64(v)
+	b.fields[slot] = b.offset()
+}
+
+func (b *builder) addOffset(slot int, off int) {
+	b.prependOffset(off)
+	b.fields[slot] = b.offset()
+}
+
+// endTable finishes the table being built, writing its vtable, and returns
+// its offset.
+func (b *builder) endTable() int {
+	b.prependUint32(0) // the vtable offset, patched below
+	table := b.offset()
+
+	for i := len(b.fields) - 1; i >= 0; i-- {
+		var pos uint16
+		if b.fields[i] != 0 {
+			pos = uint16(table - b.fields[i])
+		}
+		b.prependUint16(pos)
+	}
+	b.prependUint16(uint16(table - b.tableStart))
+	b.prependUint16(uint16(4 + 2*len(b.fields)))
+	vtable := b.offset()
+
+	// The vtable precedes the table, at a positive distance
+	binary.LittleEndian.PutUint32(b.buf[len(b.buf)-table:], uint32(vtable-table))
+	b.fields = nil
+	return table
+}
+
+// finish writes the reference to the root table and returns the buffer.
+func (b *builder) finish(root int) []byte {
+	b.prep(max(b.minAlign, 4), 4)
+	b.prependOffset(root)
+	return b.buf
+}
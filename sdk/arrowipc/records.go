@@ -0,0 +1,152 @@
+package arrowipc
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// FieldsOf returns the columns EncodeRecords writes for a struct type: one
+// per exported field of a scalar type, named as encoding/json names it.
+// Pointer fields and times are nullable. Slices, maps and nested structs
+// are left out.
+func FieldsOf(t reflect.Type) []Field {
+	fields, _ := layout(t)
+	return fields
+}
+
+// layout returns the columns of a struct type and the indexes of the
+// fields they're read from.
+func layout(t reflect.Type) ([]Field, []int) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var fields []Field
+	var index []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		ft := f.Type
+		nullable := false
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+			nullable = true
+		}
+		var typ Type
+		switch ft.Kind() {
+		case reflect.Bool:
+			typ = Bool
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			typ = Int64
+		case reflect.Float32, reflect.Float64:
+			typ = Float64
+		case reflect.String:
+			typ = String
+		case reflect.Struct:
+			if ft != timeType {
+				continue
+			}
+			typ = Timestamp
+			nullable = true
+		default:
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, Field{Name: name, Type: typ, Nullable: nullable})
+		index = append(index, i)
+	}
+	return fields, index
+}
+
+// EncodeRecords writes the values of seq to w as an Arrow stream with the
+// columns FieldsOf returns for T, for query results such as []EditorStat or
+// []PageCohort. It stops at the first error seq yields, without ending the
+// stream, so a truncated stream isn't mistaken for a complete one.
+func EncodeRecords[T any](w io.Writer, seq iter.Seq2[T, error]) error {
+	t := reflect.TypeFor[T]()
+	fields, index := layout(t)
+	if len(fields) == 0 {
+		return fmt.Errorf("arrowipc: %s has no columns", t)
+	}
+
+	aw := NewWriter(w, fields)
+	row := make([]any, len(fields))
+	for v, err := range seq {
+		if err != nil {
+			return err
+		}
+		rv := reflect.Indirect(reflect.ValueOf(&v).Elem())
+		if !rv.IsValid() {
+			return fmt.Errorf("arrowipc: nil %s", t)
+		}
+		for i, fi := range index {
+			row[i] = rv.Field(fi).Interface()
+		}
+		if err := aw.Write(row...); err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+// revisionRecord is the row of a revision written by EncodeRevisions.
+type revisionRecord struct {
+	ID        int64     `json:"id"`
+	PageID    int64     `json:"page_id"`
+	ParentID  *int64    `json:"parent_id"`
+	Timestamp time.Time `json:"timestamp"`
+	User      string    `json:"user"`
+	UserID    *int      `json:"user_id"`
+	Comment   string    `json:"comment"`
+	Size      int       `json:"size"`
+	SHA1      string    `json:"sha1"`
+	Minor     bool      `json:"minor"`
+}
+
+// RevisionFields are the columns EncodeRevisions writes.
+var RevisionFields = FieldsOf(reflect.TypeFor[revisionRecord]())
+
+// EncodeRevisions writes the metadata of the revisions of seq to w as an
+// Arrow stream with RevisionFields, named as irowiki.EncodeRevisionsJSON
+// names them. Content and tags are left out.
+func EncodeRevisions(w io.Writer, revisions iter.Seq2[irowiki.Revision, error]) error {
+	return EncodeRecords(w, func(yield func(revisionRecord, error) bool) {
+		for rev, err := range revisions {
+			r := revisionRecord{
+				ID:        rev.ID,
+				PageID:    rev.PageID,
+				ParentID:  rev.ParentID,
+				Timestamp: rev.Timestamp,
+				User:      rev.User,
+				UserID:    rev.UserID,
+				Comment:   rev.Comment,
+				Size:      rev.Size,
+				SHA1:      rev.SHA1,
+				Minor:     rev.Minor,
+			}
+			if !yield(r, err) || err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,514 @@
+// Package bundle packs a complete archive snapshot into one .irowiki file,
+// so distributing a usable copy of the wiki is a single download: the
+// SQLite database, the mirrored media files, the vector index and a
+// manifest describing them, in a zstd-compressed tar.
+//
+//	f, err := os.Create("irowiki-2026.01.irowiki")
+//	// ...
+//	manifest, err := bundle.Pack(ctx, f, bundle.PackOptions{
+//	    Database:  "irowiki.db",
+//	    FilesDir:  "files",
+//	    VectorDir: "vector_db",
+//	    Version:   "2026.01",
+//	})
+//
+// and on the receiving side:
+//
+//	manifest, err := bundle.Unpack(ctx, f, "irowiki-2026.01")
+//	client, err := irowiki.OpenSQLite(filepath.Join("irowiki-2026.01", manifest.Database.Path))
+//
+// The manifest is the bundle's first entry, so ReadManifest can describe a
+// bundle without decompressing the rest. A checksums.sha256 file, in the
+// format of sha256sum, follows the other files; Unpack checks every file
+// against it. Bundles packed with a SigningKey end with an Ed25519
+// signature of the checksums, so a snapshot from a community mirror can be
+// traced to the key of whoever packed it (see Open and Verify).
+//
+// Bundles carry the format version they were written with and the oldest
+// reader version able to unpack them. Readers unpack bundles of newer
+// formats as long as they're at least that version, and refuse others with
+// ErrUnsupportedVersion before extracting anything.
+package bundle
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// Extension is the file extension of bundles.
+const Extension = ".irowiki"
+
+// MediaType is the media type of bundles, for HTTP responses.
+const MediaType = "application/vnd.irowiki.bundle+zstd"
+
+// FormatVersion is the bundle format version this package writes and the
+// newest it fully understands.
+const FormatVersion = 1
+
+// Names of the entries of a bundle.
+const (
+	ManifestName  = "manifest.json"
+	ChecksumsName = "checksums.sha256"
+	SignatureName = "checksums.sha256.sig"
+	DatabaseName  = "irowiki.db"
+	FilesDir      = "files"
+	VectorDir     = "vector"
+)
+
+var (
+	// ErrUnsupportedVersion is returned for bundles that need a newer
+	// reader than this package.
+	ErrUnsupportedVersion = errors.New("unsupported bundle version")
+
+	// ErrInvalidBundle is returned for files that aren't well-formed
+	// bundles, and for bundles whose files don't match their checksums or
+	// whose signature doesn't verify.
+	ErrInvalidBundle = errors.New("invalid bundle")
+
+	// ErrUntrustedBundle is returned by Open and Verify for bundles that
+	// aren't signed with a trusted key when one is required.
+	ErrUntrustedBundle = errors.New("untrusted bundle")
+)
+
+// Manifest describes a bundle.
+type Manifest struct {
+	// FormatVersion is the bundle format version it was written with.
+	FormatVersion int `json:"format_version"`
+
+	// MinReaderVersion is the oldest format version able to unpack it.
+	MinReaderVersion int `json:"min_reader_version"`
+
+	// Version labels the snapshot, such as "2026.01".
+	Version string `json:"version,omitempty"`
+
+	// CreatedAt is when the bundle was packed.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Wiki is the source wiki's name and BaseURL its address, from the
+	// archive's metadata.
+	Wiki    string `json:"wiki,omitempty"`
+	BaseURL string `json:"base_url,omitempty"`
+
+	// ScrapedAt is when the archive's scrape started (zero if unknown).
+	ScrapedAt time.Time `json:"scraped_at,omitzero"`
+
+	// Pages and Revisions count the archive's content.
+	Pages     int64 `json:"pages"`
+	Revisions int64 `json:"revisions"`
+
+	// Database is the SQLite archive.
+	Database Component `json:"database"`
+
+	// Files are the mirrored media files, if packed.
+	Files *Component `json:"files,omitempty"`
+
+	// Vector is the vector index, if packed.
+	Vector *VectorComponent `json:"vector,omitempty"`
+}
+
+// Component describes a part of a bundle.
+type Component struct {
+	// Path is the component's file or directory within the bundle.
+	Path string `json:"path"`
+
+	// Entries is the number of files.
+	Entries int `json:"entries"`
+
+	// Bytes is their total size.
+	Bytes int64 `json:"bytes"`
+}
+
+// VectorComponent describes a bundle's vector index, with the details of
+// its metadata.json.
+type VectorComponent struct {
+	Component
+
+	Model        string `json:"model,omitempty"`
+	EmbeddingDim int    `json:"embedding_dim,omitempty"`
+	Chunks       int    `json:"chunks,omitempty"`
+}
+
+// PackOptions configures Pack.
+type PackOptions struct {
+	// Database is the SQLite archive to pack. It shouldn't be written to
+	// while it's packed. Required.
+	Database string
+
+	// FilesDir is the directory of mirrored media files. Optional.
+	FilesDir string
+
+	// VectorDir is the vector index directory, with its metadata.json.
+	// Optional.
+	VectorDir string
+
+	// Version labels the snapshot, such as "2026.01". Optional.
+	Version string
+
+	// SigningKey signs the bundle's checksums. Optional.
+	SigningKey ed25519.PrivateKey
+}
+
+// packEntry is a file to pack.
+type packEntry struct {
+	src, name string
+	size      int64
+	mode      fs.FileMode
+	modTime   time.Time
+}
+
+// Pack writes a bundle of the archive and its optional files and vector
+// index to w and returns its manifest.
+func Pack(ctx context.Context, w io.Writer, opts PackOptions) (*Manifest, error) {
+	if opts.Database == "" {
+		return nil, fmt.Errorf("%w: a database is required", irowiki.ErrInvalidInput)
+	}
+	info, err := os.Stat(opts.Database)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", irowiki.ErrInvalidInput, err)
+	}
+
+	manifest, err := describeArchive(ctx, opts.Database)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Version = opts.Version
+	manifest.Database = Component{Path: DatabaseName, Entries: 1, Bytes: info.Size()}
+	entries := []packEntry{{src: opts.Database, name: DatabaseName, size: info.Size(), mode: info.Mode(), modTime: info.ModTime()}}
+
+	if opts.FilesDir != "" {
+		files, component, err := listDir(opts.FilesDir, FilesDir)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, files...)
+		manifest.Files = component
+	}
+	if opts.VectorDir != "" {
+		files, component, err := listDir(opts.VectorDir, VectorDir)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, files...)
+		manifest.Vector = &VectorComponent{Component: *component}
+		if data, err := os.ReadFile(filepath.Join(opts.VectorDir, "metadata.json")); err == nil {
+			var meta struct {
+				Model        string `json:"model"`
+				EmbeddingDim int    `json:"embedding_dim"`
+				TotalChunks  int    `json:"total_chunks"`
+			}
+			if err := json.Unmarshal(data, &meta); err != nil {
+				return nil, fmt.Errorf("%w: invalid vector metadata: %v", irowiki.ErrInvalidInput, err)
+			}
+			manifest.Vector.Model = meta.Model
+			manifest.Vector.EmbeddingDim = meta.EmbeddingDim
+			manifest.Vector.Chunks = meta.TotalChunks
+		}
+	}
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	// Closing again after a successful Close does nothing
+	defer zw.Close()
+	tw := tar.NewWriter(zw)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data = append(data, '\n')
+	if err := writeEntry(tw, ManifestName, data, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	// The manifest is listed too, so a signature covers it
+	var checksums strings.Builder
+	sum := sha256.Sum256(data)
+	fmt.Fprintf(&checksums, "%s  %s\n", hex.EncodeToString(sum[:]), ManifestName)
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sum, err := packFile(tw, e)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&checksums, "%s  %s\n", sum, e.name)
+	}
+	if err := writeEntry(tw, ChecksumsName, []byte(checksums.String()), manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	if opts.SigningKey != nil {
+		sig, err := json.Marshal(signature{
+			Key:       opts.SigningKey.Public().(ed25519.PublicKey),
+			Signature: ed25519.Sign(opts.SigningKey, []byte(checksums.String())),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeEntry(tw, SignatureName, append(sig, '\n'), manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// describeArchive starts the manifest of a bundle of the archive at path.
+func describeArchive(ctx context.Context, path string) (*Manifest, error) {
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	stats, err := client.GetStatistics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		FormatVersion:    FormatVersion,
+		MinReaderVersion: 1,
+		CreatedAt:        time.Now().UTC().Truncate(time.Second),
+		Pages:            stats.TotalPages,
+		Revisions:        stats.TotalRevisions,
+	}
+
+	// Archives from older scrapers have no metadata
+	archive, err := client.GetArchiveInfo(ctx)
+	if errors.Is(err, irowiki.ErrNotFound) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest.Wiki = archive.WikiName
+	manifest.BaseURL = archive.BaseURL
+	manifest.ScrapedAt = archive.ScrapedAt
+	return manifest, nil
+}
+
+// listDir lists the regular files under dir, to be packed under prefix,
+// in lexical order.
+func listDir(dir, prefix string) ([]packEntry, *Component, error) {
+	var entries []packEntry
+	component := &Component{Path: prefix + "/"}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, packEntry{src: p, name: path.Join(prefix, filepath.ToSlash(rel)), size: info.Size(), mode: info.Mode(), modTime: info.ModTime()})
+		component.Entries++
+		component.Bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", irowiki.ErrInvalidInput, err)
+	}
+	return entries, component, nil
+}
+
+// writeEntry writes a generated file.
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// packFile copies a file into the bundle and returns its SHA-256.
+func packFile(tw *tar.Writer, e packEntry) (string, error) {
+	f, err := os.Open(e.src)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hdr := &tar.Header{Name: e.name, Mode: int64(e.mode.Perm()), Size: e.size, ModTime: e.modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tw, h), io.LimitReader(f, e.size)); err != nil {
+		return "", fmt.Errorf("failed to pack %s: %v", e.src, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadManifest reads the manifest of the bundle r, its first entry,
+// without reading the rest. It doesn't check the version.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	defer zr.Close()
+	manifest, _, err := readManifest(tar.NewReader(zr))
+	return manifest, err
+}
+
+// readManifest reads the manifest entry, returning it decoded and as it
+// was written, with any fields of newer formats.
+func readManifest(tr *tar.Reader) (*Manifest, []byte, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if hdr.Name != ManifestName {
+		return nil, nil, fmt.Errorf("%w: first entry is %q, not %s", ErrInvalidBundle, hdr.Name, ManifestName)
+	}
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid manifest: %v", ErrInvalidBundle, err)
+	}
+	return &manifest, data, nil
+}
+
+// Unpack extracts the bundle r into dir, which must not exist or be empty,
+// and returns its manifest. Bundles needing a newer reader fail with
+// ErrUnsupportedVersion; unknown entries of newer formats are extracted
+// as they are. If a file doesn't match its checksum, the signature doesn't
+// verify, or the bundle is otherwise malformed, Unpack fails with
+// ErrInvalidBundle and removes what it extracted. Use Verify to check who
+// signed it.
+func Unpack(ctx context.Context, r io.Reader, dir string) (*Manifest, error) {
+	manifest, _, err := unpack(ctx, r, dir, VerifyOptions{})
+	return manifest, err
+}
+
+// unpack extracts and verifies the bundle r into dir.
+func unpack(ctx context.Context, r io.Reader, dir string, opts VerifyOptions) (manifest *Manifest, integrity *irowiki.ArchiveIntegrity, err error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	manifest, data, err := readManifest(tr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest.MinReaderVersion > FormatVersion {
+		return nil, nil, fmt.Errorf("%w: bundle format %d needs a reader of version %d or later, this is %d",
+			ErrUnsupportedVersion, manifest.FormatVersion, manifest.MinReaderVersion, FormatVersion)
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s is not empty", irowiki.ErrInvalidInput, dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			removeContents(dir)
+		}
+	}()
+
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), data, 0o644); err != nil {
+		return nil, nil, err
+	}
+	sum := sha256.Sum256(data)
+	sums := map[string]string{ManifestName: hex.EncodeToString(sum[:])}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(filepath.FromSlash(hdr.Name)) {
+			return nil, nil, fmt.Errorf("%w: unexpected entry %q", ErrInvalidBundle, hdr.Name)
+		}
+
+		sum, err := unpackFile(tr, filepath.Join(dir, filepath.FromSlash(hdr.Name)), hdr)
+		if err != nil {
+			return nil, nil, err
+		}
+		sums[hdr.Name] = sum
+	}
+
+	integrity, err = verifyDir(dir, manifest, sums, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return manifest, integrity, nil
+}
+
+// unpackFile extracts the current entry to dest and returns its SHA-256.
+func unpackFile(r io.Reader, dest string, hdr *tar.Header) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fs.FileMode(hdr.Mode).Perm()|0o600)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to unpack %s: %v", hdr.Name, err)
+	}
+	os.Chtimes(dest, hdr.ModTime, hdr.ModTime)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// removeContents removes the files Unpack extracted into dir.
+func removeContents(dir string) {
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		os.RemoveAll(filepath.Join(dir, e.Name()))
+	}
+}
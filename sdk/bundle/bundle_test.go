@@ -0,0 +1,194 @@
+package bundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/bundle"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// writeFile creates a file and its directories.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPackUnpack tests that an unpacked bundle is a usable archive
+func TestPackUnpack(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "files", "a", "Example.png"), "png data")
+	writeFile(t, filepath.Join(src, "vector", "metadata.json"), `{"model": "all-MiniLM-L6-v2", "embedding_dim": 384, "total_chunks": 12}`)
+	writeFile(t, filepath.Join(src, "vector", "collection", "segment.bin"), "vectors")
+
+	ctx := context.Background()
+	var buf bytes.Buffer
+	manifest, err := bundle.Pack(ctx, &buf, bundle.PackOptions{
+		Database:  tdb.Path,
+		FilesDir:  filepath.Join(src, "files"),
+		VectorDir: filepath.Join(src, "vector"),
+		Version:   "2026.01",
+	})
+	if err != nil {
+		t.Fatalf("Pack failed: %v", err)
+	}
+	if manifest.FormatVersion != bundle.FormatVersion || manifest.Pages != 5 || manifest.Revisions != 7 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if manifest.Files == nil || manifest.Files.Entries != 1 || manifest.Files.Bytes != 8 {
+		t.Errorf("unexpected files component %+v", manifest.Files)
+	}
+	if manifest.Vector == nil || manifest.Vector.Entries != 2 || manifest.Vector.Model != "all-MiniLM-L6-v2" || manifest.Vector.Chunks != 12 {
+		t.Errorf("unexpected vector component %+v", manifest.Vector)
+	}
+
+	// Test: ReadManifest reads only the first entry
+	read, err := bundle.ReadManifest(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadManifest failed: %v", err)
+	}
+	if read.Version != "2026.01" || read.Database.Path != bundle.DatabaseName {
+		t.Errorf("unexpected manifest %+v", read)
+	}
+
+	dest := filepath.Join(t.TempDir(), "snapshot")
+	if _, err := bundle.Unpack(ctx, bytes.NewReader(buf.Bytes()), dest); err != nil {
+		t.Fatalf("Unpack failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "files", "a", "Example.png"))
+	if err != nil || string(data) != "png data" {
+		t.Errorf("expected the media file to be unpacked, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "vector", "collection", "segment.bin")); err != nil {
+		t.Errorf("expected the vector index to be unpacked: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(filepath.Join(dest, manifest.Database.Path))
+	if err != nil {
+		t.Fatalf("failed to open unpacked archive: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.GetPage(ctx, "Prontera"); err != nil {
+		t.Errorf("GetPage failed: %v", err)
+	}
+
+	// Test: A non-empty destination is refused
+	if _, err := bundle.Unpack(ctx, bytes.NewReader(buf.Bytes()), dest); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// writeBundle writes a bundle of the given entries, in order.
+func writeBundle(t *testing.T, entries [][2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(zw)
+	for _, e := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: e[0], Mode: 0o644, Size: int64(len(e[1])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e[1]))
+	}
+	tw.Close()
+	zw.Close()
+	return buf.Bytes()
+}
+
+// checksumLine returns the checksums line of a file.
+func checksumLine(name, content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:]) + "  " + name + "\n"
+}
+
+// TestUnpack_Versions tests version negotiation and checksum verification
+func TestUnpack_Versions(t *testing.T) {
+	const manifest = `{"format_version": 1, "min_reader_version": 1, "database": {"path": "irowiki.db"}}`
+	const newer = `{"format_version": 3, "min_reader_version": 1, "database": {"path": "irowiki.db"}}`
+
+	tests := []struct {
+		name    string
+		entries [][2]string
+		wantErr error
+	}{
+		{
+			name:    "newer format readable by this version",
+			entries: [][2]string{{"manifest.json", newer}, {"irowiki.db", "db"}, {"thumbnails/a.png", ""}, {"checksums.sha256", checksumLine("manifest.json", newer) + checksumLine("irowiki.db", "db") + checksumLine("thumbnails/a.png", "")}},
+		},
+		{
+			name:    "file without checksum",
+			entries: [][2]string{{"manifest.json", manifest}, {"irowiki.db", "db"}, {"checksums.sha256", checksumLine("manifest.json", manifest)}},
+			wantErr: bundle.ErrInvalidBundle,
+		},
+		{
+			name:    "manifest without checksum",
+			entries: [][2]string{{"manifest.json", manifest}, {"irowiki.db", "db"}, {"checksums.sha256", checksumLine("irowiki.db", "db")}},
+			wantErr: bundle.ErrInvalidBundle,
+		},
+		{
+			name:    "newer reader required",
+			entries: [][2]string{{"manifest.json", `{"format_version": 3, "min_reader_version": 2}`}},
+			wantErr: bundle.ErrUnsupportedVersion,
+		},
+		{
+			name:    "manifest not first",
+			entries: [][2]string{{"irowiki.db", "db"}, {"manifest.json", manifest}},
+			wantErr: bundle.ErrInvalidBundle,
+		},
+		{
+			name:    "checksum mismatch",
+			entries: [][2]string{{"manifest.json", manifest}, {"irowiki.db", "dB"}, {"checksums.sha256", checksumLine("manifest.json", manifest) + checksumLine("irowiki.db", "db")}},
+			wantErr: bundle.ErrInvalidBundle,
+		},
+		{
+			name:    "no checksums",
+			entries: [][2]string{{"manifest.json", manifest}, {"irowiki.db", "db"}},
+			wantErr: bundle.ErrInvalidBundle,
+		},
+		{
+			name:    "path outside the destination",
+			entries: [][2]string{{"manifest.json", manifest}, {"../escape", "x"}},
+			wantErr: bundle.ErrInvalidBundle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := t.TempDir()
+			_, err := bundle.Unpack(context.Background(), bytes.NewReader(writeBundle(t, tt.entries)), dest)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Unpack failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if entries, _ := os.ReadDir(dest); len(entries) > 0 {
+				t.Errorf("expected a failed unpack to leave nothing behind, got %d entries", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+func init() {
+	irowiki.Register("bundle", func(dsn string, opts irowiki.ConnectionOptions) (irowiki.Client, error) {
+		return Open(context.Background(), dsn, OpenOptions{Connection: opts})
+	})
+}
+
+// OpenOptions configures Open.
+type OpenOptions struct {
+	VerifyOptions
+
+	// Dir is where the bundle is unpacked.
+	// Default: a directory named after the bundle in "irowiki/bundles"
+	// under the user's cache directory (os.UserCacheDir).
+	Dir string
+
+	// Connection configures the archive's client.
+	Connection irowiki.ConnectionOptions
+}
+
+// Open opens the archive of the bundle at path. The bundle is unpacked
+// once, into opts.Dir, and verified on every open: its files against its
+// checksums and, if it's signed, the signature against opts.TrustedKeys.
+// The result is reported in the Integrity of the client's GetArchiveInfo.
+// Bundles failing verification aren't opened.
+//
+// Importing the package also registers the "bundle" driver, so
+// irowiki.Open("bundle://snapshot.irowiki") opens bundles without
+// trusted keys.
+//
+// Example:
+//
+//	client, err := bundle.Open(ctx, "irowiki-2026.01.irowiki", bundle.OpenOptions{
+//	    VerifyOptions: bundle.VerifyOptions{TrustedKeys: []ed25519.PublicKey{publisherKey}},
+//	})
+//	// ...
+//	info, err := client.GetArchiveInfo(ctx)
+//	if !info.Integrity.Trusted {
+//	    log.Printf("bundle signed by unknown key %s", info.Integrity.KeyID)
+//	}
+func Open(ctx context.Context, path string, opts OpenOptions) (irowiki.Client, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", irowiki.ErrNotFound, err)
+	}
+	dir := opts.Dir
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("%w: no cache directory: %v", irowiki.ErrConnectionFailed, err)
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		// A changed bundle is unpacked afresh
+		sum := sha256.Sum256([]byte(abs + "\x00" + strconv.FormatInt(info.Size(), 10) + "\x00" + info.ModTime().String()))
+		dir = filepath.Join(cache, "irowiki", "bundles", hex.EncodeToString(sum[:8]))
+	}
+
+	var manifest *Manifest
+	var integrity *irowiki.ArchiveIntegrity
+	if _, err := os.Stat(filepath.Join(dir, ManifestName)); err == nil {
+		manifest, err = readManifestFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		if integrity, err = verifyDir(dir, manifest, nil, opts.VerifyOptions); err != nil {
+			return nil, err
+		}
+	} else {
+		manifest, integrity, err = unpackBundle(ctx, path, dir, opts.VerifyOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	integrity.Bundle = path
+
+	client, err := irowiki.OpenSQLiteWithOptions(filepath.Join(dir, filepath.FromSlash(manifest.Database.Path)), opts.Connection)
+	if err != nil {
+		return nil, err
+	}
+	return irowiki.WithInterceptor(client, func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+		result, err := next(ctx)
+		if call.Method != "GetArchiveInfo" {
+			return result, err
+		}
+		// Archives without metadata still report their integrity
+		if errors.Is(err, irowiki.ErrNotFound) {
+			return &irowiki.ArchiveInfo{Meta: map[string]string{}, Integrity: integrity}, nil
+		}
+		if archive, ok := result.(*irowiki.ArchiveInfo); ok {
+			archive.Integrity = integrity
+		}
+		return result, err
+	}), nil
+}
+
+// unpackBundle unpacks and verifies the bundle at path into dir. It's
+// unpacked beside dir first, so dir only ever holds a complete bundle.
+func unpackBundle(ctx context.Context, path, dir string, opts VerifyOptions) (*Manifest, *irowiki.ArchiveIntegrity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", irowiki.ErrConnectionFailed, err)
+	}
+	defer f.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return nil, nil, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".unpack-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest, integrity, err := unpack(ctx, f, tmp, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, nil, err
+	}
+	return manifest, integrity, nil
+}
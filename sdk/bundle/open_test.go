@@ -0,0 +1,106 @@
+package bundle_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/bundle"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// packFile packs the test archive into a bundle file.
+func packFile(t *testing.T, key ed25519.PrivateKey) string {
+	t.Helper()
+	tdb := testutil.SetupTestDBFile(t)
+	t.Cleanup(func() { tdb.Close() })
+
+	var buf bytes.Buffer
+	if _, err := bundle.Pack(context.Background(), &buf, bundle.PackOptions{Database: tdb.Path, SigningKey: key}); err != nil {
+		t.Fatalf("Pack failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot"+bundle.Extension)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestOpen tests that opening a bundle reports its verification
+func TestOpen(t *testing.T) {
+	publisher, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := packFile(t, key)
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "unpacked")
+
+	client, err := bundle.Open(ctx, path, bundle.OpenOptions{
+		VerifyOptions: bundle.VerifyOptions{TrustedKeys: []ed25519.PublicKey{other, publisher}},
+		Dir:           dir,
+	})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	info, err := client.GetArchiveInfo(ctx)
+	client.Close()
+	if err != nil {
+		t.Fatalf("GetArchiveInfo failed: %v", err)
+	}
+	want := irowiki.ArchiveIntegrity{Bundle: path, FormatVersion: bundle.FormatVersion, ChecksumsVerified: true, Signed: true, KeyID: bundle.KeyID(publisher), Trusted: true}
+	if got := info.Integrity; got == nil || got.VerifiedAt.IsZero() {
+		t.Fatalf("expected integrity, got %+v", got)
+	} else if got.VerifiedAt = (want.VerifiedAt); *got != want {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+
+	// Test: Reopening verifies the unpacked files again
+	if _, err := bundle.Verify(dir, bundle.VerifyOptions{TrustedKeys: []ed25519.PublicKey{other}, RequireTrusted: true}); !errors.Is(err, bundle.ErrUntrustedBundle) {
+		t.Errorf("expected ErrUntrustedBundle for an unknown key, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, bundle.DatabaseName), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.Open(ctx, path, bundle.OpenOptions{Dir: dir}); !errors.Is(err, bundle.ErrInvalidBundle) {
+		t.Errorf("expected ErrInvalidBundle for a changed database, got %v", err)
+	}
+}
+
+// TestOpen_Driver tests opening unsigned bundles through irowiki.Open
+func TestOpen_Driver(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	path := packFile(t, nil)
+	ctx := context.Background()
+
+	client, err := irowiki.Open("bundle://" + path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.GetPage(ctx, "Prontera"); err != nil {
+		t.Errorf("GetPage failed: %v", err)
+	}
+	info, err := client.GetArchiveInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetArchiveInfo failed: %v", err)
+	}
+	if info.Integrity == nil || !info.Integrity.ChecksumsVerified || info.Integrity.Signed || info.Integrity.Trusted {
+		t.Errorf("expected verified unsigned bundle, got %+v", info.Integrity)
+	}
+
+	// Test: Requiring a trusted signature refuses it
+	if _, err := bundle.Open(ctx, path, bundle.OpenOptions{VerifyOptions: bundle.VerifyOptions{RequireTrusted: true}}); !errors.Is(err, bundle.ErrUntrustedBundle) {
+		t.Errorf("expected ErrUntrustedBundle, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// signature is the content of a bundle's signature entry: an Ed25519
+// signature of its checksums file and the public key to check it with.
+type signature struct {
+	Key       ed25519.PublicKey `json:"key"`
+	Signature []byte            `json:"signature"`
+}
+
+// KeyID identifies a public key: the first 16 hex digits of its SHA-256,
+// as reported in ArchiveIntegrity.KeyID.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// VerifyOptions configures how bundles are verified.
+type VerifyOptions struct {
+	// TrustedKeys are the public keys of the publishers whose bundles are
+	// trusted.
+	TrustedKeys []ed25519.PublicKey
+
+	// RequireTrusted fails bundles not signed with one of TrustedKeys with
+	// ErrUntrustedBundle.
+	RequireTrusted bool
+}
+
+// Verify checks the files of the bundle unpacked into dir against its
+// checksums and signature, such as before reopening a snapshot unpacked
+// earlier, and returns the result. Changed or missing files fail with
+// ErrInvalidBundle.
+func Verify(dir string, opts VerifyOptions) (*irowiki.ArchiveIntegrity, error) {
+	manifest, err := readManifestFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	return verifyDir(dir, manifest, nil, opts)
+}
+
+// readManifestFile reads the manifest of the bundle unpacked into dir.
+func readManifestFile(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", ErrInvalidBundle, err)
+	}
+	return &manifest, nil
+}
+
+// verifyDir checks the unpacked bundle in dir. sums holds the SHA-256 of
+// every file as it was unpacked, which must all be listed in the checksums;
+// when nil, the listed files are hashed from dir instead.
+func verifyDir(dir string, manifest *Manifest, sums map[string]string, opts VerifyOptions) (*irowiki.ArchiveIntegrity, error) {
+	checksums, err := os.ReadFile(filepath.Join(dir, ChecksumsName))
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidBundle, ChecksumsName)
+	}
+
+	integrity := &irowiki.ArchiveIntegrity{FormatVersion: manifest.FormatVersion}
+	var sig signature
+	data, err := os.ReadFile(filepath.Join(dir, SignatureName))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &sig); err != nil || len(sig.Key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: malformed %s", ErrInvalidBundle, SignatureName)
+		}
+		if !ed25519.Verify(sig.Key, checksums, sig.Signature) {
+			return nil, fmt.Errorf("%w: signature does not match the checksums", ErrInvalidBundle)
+		}
+		integrity.Signed = true
+		integrity.KeyID = KeyID(sig.Key)
+		integrity.Trusted = slices.ContainsFunc(opts.TrustedKeys, func(key ed25519.PublicKey) bool {
+			return key.Equal(sig.Key)
+		})
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+
+	listed := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		sum, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok || !filepath.IsLocal(filepath.FromSlash(name)) {
+			return nil, fmt.Errorf("%w: malformed %s line %q", ErrInvalidBundle, ChecksumsName, scanner.Text())
+		}
+		var got string
+		if sums != nil {
+			got, ok = sums[name]
+		} else {
+			got, ok = hashFile(filepath.Join(dir, filepath.FromSlash(name)))
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s", ErrInvalidBundle, name)
+		}
+		if got != sum {
+			return nil, fmt.Errorf("%w: %s does not match its checksum", ErrInvalidBundle, name)
+		}
+		listed[name] = true
+	}
+
+	// Every file must be listed, the manifest included
+	for name := range sums {
+		if !listed[name] && name != ChecksumsName && name != SignatureName {
+			return nil, fmt.Errorf("%w: %s has no checksum", ErrInvalidBundle, name)
+		}
+	}
+	if !listed[ManifestName] {
+		return nil, fmt.Errorf("%w: %s has no checksum", ErrInvalidBundle, ManifestName)
+	}
+	if !listed[manifest.Database.Path] {
+		return nil, fmt.Errorf("%w: missing database %s", ErrInvalidBundle, manifest.Database.Path)
+	}
+
+	if opts.RequireTrusted && !integrity.Trusted {
+		if integrity.Signed {
+			return nil, fmt.Errorf("%w: signed with untrusted key %s", ErrUntrustedBundle, integrity.KeyID)
+		}
+		return nil, fmt.Errorf("%w: not signed", ErrUntrustedBundle)
+	}
+
+	integrity.ChecksumsVerified = true
+	integrity.VerifiedAt = time.Now().UTC()
+	return integrity, nil
+}
+
+// hashFile returns the SHA-256 of a file, reporting false if it can't be
+// read.
+func hashFile(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", false
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
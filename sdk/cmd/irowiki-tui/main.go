@@ -0,0 +1,80 @@
+// Command irowiki-tui browses an iRO Wiki archive in the terminal.
+//
+// Usage:
+//
+//	irowiki-tui --db <archive> [query]
+//
+// Search results, pages, history and diffs are navigated with the keyboard:
+// enter opens the selected item, h shows a page's history, d shows the diff
+// for the selected revision, n/p page through lists, esc goes back, / starts
+// a new search and q quits.
+//
+// The default --db is read from the irowiki config file
+// (~/.config/irowiki/config.yaml) or $IROWIKI_DB.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/config"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+// run parses flags, opens the archive and runs the browser until it quits.
+func run(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("irowiki-tui", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki-tui: %v\n", err)
+		return 1
+	}
+	dbPath := fs.String("db", cfg.DB, "path to the SQLite archive (required unless set in the config file)")
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: irowiki-tui --db <archive> [query]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Browses search results, pages, history and diffs in the terminal.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *dbPath == "" {
+		fmt.Fprintln(stderr, "irowiki-tui: --db is required")
+		fs.Usage()
+		return 2
+	}
+
+	client, err := irowiki.OpenSQLite(*dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki-tui: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	m := newModel(context.Background(), client, strings.Join(fs.Args(), " "))
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintf(stderr, "irowiki-tui: %v\n", err)
+		return 1
+	}
+	return 0
+}
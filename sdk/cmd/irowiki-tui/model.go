@@ -0,0 +1,613 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/render"
+)
+
+// pageSize is the number of search results or revisions shown per page.
+const pageSize = 20
+
+// screen identifies what the browser is showing.
+type screen int
+
+const (
+	screenSearch screen = iota
+	screenPage
+	screenHistory
+	screenDiff
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	faintStyle    = lipgloss.NewStyle().Faint(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	markStyle     = lipgloss.NewStyle().Reverse(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	addStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	delStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	hunkStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+)
+
+// searchMsg carries one page of search results.
+type searchMsg struct {
+	query      string
+	offset     int
+	results    []irowiki.SearchResult
+	suggestion string
+	err        error
+}
+
+// pageMsg carries a rendered page or old revision.
+type pageMsg struct {
+	title string
+	text  string
+	err   error
+}
+
+// historyMsg carries one page of a page's revision history.
+type historyMsg struct {
+	title     string
+	offset    int
+	revisions []irowiki.Revision
+	err       error
+}
+
+// diffMsg carries a rendered diff.
+type diffMsg struct {
+	title string
+	text  string
+	err   error
+}
+
+// frame is a screen saved on the back stack.
+type frame struct {
+	screen  screen
+	title   string
+	text    string
+	yOffset int
+}
+
+// model is the bubbletea model for the archive browser.
+type model struct {
+	ctx    context.Context
+	client irowiki.Client
+
+	width  int
+	height int
+	screen screen
+	stack  []frame
+	status string
+
+	input       textinput.Model
+	query       string
+	results     []irowiki.SearchResult
+	suggestion  string
+	offset      int
+	cursor      int
+	resultsMore bool
+
+	// title is the page being viewed; text is the page or diff shown in
+	// the viewport, kept unwrapped so it can be rewrapped on resize.
+	title    string
+	text     string
+	viewport viewport.Model
+
+	revisions     []irowiki.Revision
+	historyOffset int
+	historyCursor int
+	historyMore   bool
+	initialQuery  string
+}
+
+// newModel creates a browser over client, optionally running query on start.
+func newModel(ctx context.Context, client irowiki.Client, query string) model {
+	input := textinput.New()
+	input.Placeholder = "Search the archive"
+	input.Prompt = "/ "
+	input.SetValue(query)
+	input.Focus()
+
+	return model{
+		ctx:          ctx,
+		client:       client,
+		width:        80,
+		height:       24,
+		input:        input,
+		viewport:     viewport.New(80, 21),
+		initialQuery: query,
+	}
+}
+
+// Init starts the cursor blinking and runs the initial query, if any.
+func (m model) Init() tea.Cmd {
+	if m.initialQuery != "" {
+		return tea.Batch(textinput.Blink, m.search(m.initialQuery, 0))
+	}
+	return textinput.Blink
+}
+
+// Update handles key presses, resizes and loader results.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(msg.Height-3, 1)
+		m.input.Width = max(msg.Width-len(m.input.Prompt)-1, 1)
+		m.setText(m.text, false)
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case searchMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = ""
+		m.query, m.offset, m.suggestion = msg.query, msg.offset, msg.suggestion
+		m.results, m.resultsMore = trimPage(msg.results)
+		m.cursor = 0
+		m.screen = screenSearch
+		m.stack = nil
+		m.input.Blur()
+		return m, nil
+
+	case pageMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = ""
+		m.push(screenPage)
+		m.title = msg.title
+		m.setText(msg.text, true)
+		return m, nil
+
+	case historyMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = ""
+		if m.screen != screenHistory {
+			m.push(screenHistory)
+		}
+		m.title = msg.title
+		m.historyOffset = msg.offset
+		m.revisions, m.historyMore = trimPage(msg.revisions)
+		m.historyCursor = 0
+		return m, nil
+
+	case diffMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = ""
+		m.push(screenDiff)
+		m.title = msg.title
+		m.setText(msg.text, true)
+		return m, nil
+	}
+
+	if m.input.Focused() {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// handleKey handles a key press on the current screen.
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	if m.input.Focused() {
+		switch key {
+		case "enter":
+			query := strings.TrimSpace(m.input.Value())
+			if query == "" {
+				return m, nil
+			}
+			return m, m.search(query, 0)
+		case "esc":
+			if m.query != "" {
+				m.input.Blur()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "q":
+		return m, tea.Quit
+	case "/":
+		m.screen = screenSearch
+		m.stack = nil
+		m.status = ""
+		return m, m.input.Focus()
+	case "esc", "backspace":
+		m.back()
+		return m, nil
+	}
+
+	switch m.screen {
+	case screenSearch:
+		return m.handleSearchKey(key)
+	case screenHistory:
+		return m.handleHistoryKey(key)
+	case screenPage:
+		if key == "h" {
+			return m, m.loadHistory(m.title, 0)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// handleSearchKey handles keys on the search results screen.
+func (m model) handleSearchKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.results)-1, 0))
+	case "enter":
+		if len(m.results) > 0 {
+			return m, m.loadPage(m.results[m.cursor].Title)
+		}
+	case "n":
+		if m.resultsMore {
+			return m, m.search(m.query, m.offset+pageSize)
+		}
+	case "p":
+		if m.offset > 0 {
+			return m, m.search(m.query, max(m.offset-pageSize, 0))
+		}
+	case "tab":
+		if m.suggestion != "" {
+			m.input.SetValue(m.suggestion)
+			return m, m.search(m.suggestion, 0)
+		}
+	}
+	return m, nil
+}
+
+// handleHistoryKey handles keys on the history screen.
+func (m model) handleHistoryKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		m.historyCursor = max(m.historyCursor-1, 0)
+	case "down", "j":
+		m.historyCursor = min(m.historyCursor+1, max(len(m.revisions)-1, 0))
+	case "enter":
+		if len(m.revisions) > 0 {
+			return m, m.loadRevision(m.revisions[m.historyCursor].ID)
+		}
+	case "d":
+		if len(m.revisions) > 0 && m.revisions[m.historyCursor].ParentID != nil {
+			return m, m.loadDiff(m.title, m.revisions[m.historyCursor].ID)
+		}
+	case "n":
+		if m.historyMore {
+			return m, m.loadHistory(m.title, m.historyOffset+pageSize)
+		}
+	case "p":
+		if m.historyOffset > 0 {
+			return m, m.loadHistory(m.title, max(m.historyOffset-pageSize, 0))
+		}
+	}
+	return m, nil
+}
+
+// push saves the current screen on the back stack and switches to s.
+func (m *model) push(s screen) {
+	m.stack = append(m.stack, frame{screen: m.screen, title: m.title, text: m.text, yOffset: m.viewport.YOffset})
+	m.screen = s
+}
+
+// back returns to the previous screen.
+func (m *model) back() {
+	if len(m.stack) == 0 {
+		return
+	}
+	prev := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+
+	m.screen = prev.screen
+	m.title = prev.title
+	m.status = ""
+	m.setText(prev.text, true)
+	m.viewport.SetYOffset(prev.yOffset)
+}
+
+// setText sets the viewport content, wrapped to the window width.
+func (m *model) setText(text string, top bool) {
+	m.text = text
+	m.viewport.SetContent(lipgloss.NewStyle().Width(m.width).Render(text))
+	if top {
+		m.viewport.GotoTop()
+	}
+}
+
+// View renders the current screen.
+func (m model) View() string {
+	var b strings.Builder
+
+	switch m.screen {
+	case screenSearch:
+		b.WriteString(titleStyle.Render("iRO Wiki archive") + "\n")
+		b.WriteString(m.input.View() + "\n")
+		b.WriteString(m.searchView())
+	case screenPage:
+		b.WriteString(titleStyle.Render(m.title) + "\n")
+		b.WriteString(m.viewport.View() + "\n")
+	case screenHistory:
+		b.WriteString(titleStyle.Render("History of "+m.title) + "\n")
+		b.WriteString(m.historyView())
+	case screenDiff:
+		b.WriteString(titleStyle.Render("Changes to "+m.title) + "\n")
+		b.WriteString(m.viewport.View() + "\n")
+	}
+
+	if m.status != "" {
+		b.WriteString(errorStyle.Render(m.status))
+	} else {
+		b.WriteString(faintStyle.Render(m.help()))
+	}
+	return b.String()
+}
+
+// searchView renders the search results list.
+func (m model) searchView() string {
+	var b strings.Builder
+	if m.query == "" {
+		return "\n"
+	}
+
+	if len(m.results) == 0 {
+		b.WriteString(fmt.Sprintf("No results for %q.", m.query))
+		if m.suggestion != "" {
+			b.WriteString(fmt.Sprintf(" Did you mean %q? (tab)", m.suggestion))
+		}
+		return b.String() + "\n"
+	}
+
+	fit := lipgloss.NewStyle().MaxWidth(m.width)
+	start, end := window(m.cursor, len(m.results), max((m.height-4)/2, 1))
+	for i := start; i < end; i++ {
+		r := m.results[i]
+		line := fmt.Sprintf("%d. %s", m.offset+i+1, r.Title)
+		if i == m.cursor {
+			line = selectedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(fit.Render(line) + "\n")
+		b.WriteString(fit.Render("     "+highlight(r.Snippet)) + "\n")
+	}
+	return b.String()
+}
+
+// historyView renders the revision list.
+func (m model) historyView() string {
+	var b strings.Builder
+	fit := lipgloss.NewStyle().MaxWidth(m.width)
+
+	start, end := window(m.historyCursor, len(m.revisions), max(m.height-3, 1))
+	for i := start; i < end; i++ {
+		rev := m.revisions[i]
+		user := rev.User
+		if user == "" {
+			user = "unknown"
+		}
+		minor := " "
+		if rev.Minor {
+			minor = "m"
+		}
+		line := fmt.Sprintf("%-8d %s  %-16s %s %s", rev.ID, rev.Timestamp.Format("2006-01-02 15:04"), user, minor, rev.Comment)
+		if i == m.historyCursor {
+			line = selectedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(fit.Render(line) + "\n")
+	}
+	return b.String()
+}
+
+// help returns the key help for the current screen.
+func (m model) help() string {
+	switch {
+	case m.input.Focused():
+		return "enter search • esc results • ctrl+c quit"
+	case m.screen == screenSearch:
+		return "↑/↓ move • enter open • n/p next/prev • / search • q quit"
+	case m.screen == screenPage:
+		return "↑/↓ scroll • h history • esc back • / search • q quit"
+	case m.screen == screenHistory:
+		return "↑/↓ move • enter view • d diff • n/p older/newer • esc back • q quit"
+	default:
+		return "↑/↓ scroll • esc back • / search • q quit"
+	}
+}
+
+// search runs a full-text search, falling back to title search when the
+// query isn't valid full-text syntax, as the web UI does.
+func (m model) search(query string, offset int) tea.Cmd {
+	return func() tea.Msg {
+		opts := irowiki.SearchOptions{Query: query, Limit: pageSize + 1, Offset: offset}
+
+		results, err := m.client.SearchFullText(m.ctx, query, opts)
+		if err != nil {
+			results, err = m.client.Search(m.ctx, opts)
+			if err != nil {
+				return searchMsg{err: err}
+			}
+		}
+
+		msg := searchMsg{query: query, offset: offset, results: results}
+		if len(results) == 0 && offset == 0 {
+			paged, err := m.client.SearchPaged(m.ctx, irowiki.SearchOptions{Query: query, Limit: 1})
+			if err == nil {
+				msg.suggestion = paged.Suggestion
+			}
+		}
+		return msg
+	}
+}
+
+// loadPage loads and renders the latest revision of a page.
+func (m model) loadPage(title string) tea.Cmd {
+	return func() tea.Msg {
+		page, err := irowiki.FindPage(m.ctx, m.client, title)
+		if err != nil {
+			return pageMsg{err: err}
+		}
+
+		text, err := m.renderPage(page.Title, page.Content)
+		if err != nil {
+			return pageMsg{err: err}
+		}
+
+		meta := fmt.Sprintf("Revision %d · %s · %s", page.LatestRevisionID, page.Timestamp.Format("2006-01-02 15:04"), page.User)
+		return pageMsg{title: page.Title, text: faintStyle.Render(meta) + "\n\n" + text}
+	}
+}
+
+// loadRevision loads and renders an old revision.
+func (m model) loadRevision(id int64) tea.Cmd {
+	return func() tea.Msg {
+		rev, err := m.client.GetRevision(m.ctx, id)
+		if err != nil {
+			return pageMsg{err: err}
+		}
+		page, err := m.client.GetPageByID(m.ctx, rev.PageID)
+		if err != nil {
+			return pageMsg{err: err}
+		}
+
+		text, err := m.renderPage(page.Title, rev.Content)
+		if err != nil {
+			return pageMsg{err: err}
+		}
+
+		meta := fmt.Sprintf("Revision %d · %s · %s", rev.ID, rev.Timestamp.Format("2006-01-02 15:04"), rev.User)
+		if rev.ID != page.LatestRevisionID {
+			meta = "Old revision · " + meta
+		}
+		return pageMsg{title: page.Title, text: faintStyle.Render(meta) + "\n\n" + text}
+	}
+}
+
+// loadHistory loads one page of a page's history, newest first.
+func (m model) loadHistory(title string, offset int) tea.Cmd {
+	return func() tea.Msg {
+		revisions, err := m.client.GetPageHistory(m.ctx, title, irowiki.HistoryOptions{
+			Offset:         offset,
+			Limit:          pageSize + 1,
+			ExcludeContent: true,
+		})
+		if err != nil {
+			return historyMsg{err: err}
+		}
+		return historyMsg{title: title, offset: offset, revisions: revisions}
+	}
+}
+
+// loadDiff loads the change made by a revision.
+func (m model) loadDiff(title string, id int64) tea.Cmd {
+	return func() tea.Msg {
+		diff, err := m.client.GetConsecutiveDiff(m.ctx, id)
+		if err != nil {
+			return diffMsg{err: err}
+		}
+
+		var b strings.Builder
+		b.WriteString(faintStyle.Render(fmt.Sprintf("Revision %d → %d", diff.FromRevision, diff.ToRevision)) + "\n\n")
+		for _, line := range strings.Split(strings.TrimRight(diff.Unified, "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				line = faintStyle.Render(line)
+			case strings.HasPrefix(line, "@@"):
+				line = hunkStyle.Render(line)
+			case strings.HasPrefix(line, "+"):
+				line = addStyle.Render(line)
+			case strings.HasPrefix(line, "-"):
+				line = delStyle.Render(line)
+			}
+			b.WriteString(line + "\n")
+		}
+		return diffMsg{title: title, text: b.String()}
+	}
+}
+
+// renderPage renders wikitext as text, pointing dead external links at
+// archived copies.
+func (m model) renderPage(title, content string) (string, error) {
+	archiveURL, err := irowiki.ArchiveURLs(m.ctx, m.client, title)
+	if err != nil {
+		return "", err
+	}
+	return render.Text(content, render.Options{ArchiveURL: archiveURL}), nil
+}
+
+// highlight styles the <mark> spans of a search snippet.
+func highlight(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, "<mark>")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], "</mark>")
+		if end < 0 {
+			break
+		}
+		b.WriteString(snippet[:start])
+		b.WriteString(markStyle.Render(snippet[start+len("<mark>") : start+end]))
+		snippet = snippet[start+end+len("</mark>"):]
+	}
+	b.WriteString(snippet)
+	return b.String()
+}
+
+// trimPage splits a page+1 result slice into the page and whether more follow.
+func trimPage[T any](items []T) ([]T, bool) {
+	if len(items) > pageSize {
+		return items[:pageSize], true
+	}
+	return items, false
+}
+
+// window returns the range of n list items to show so that cursor stays
+// visible in rows lines.
+func window(cursor, n, rows int) (int, int) {
+	start := 0
+	if cursor >= rows {
+		start = cursor - rows + 1
+	}
+	return start, min(start+rows, n)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// newTestModel creates a browser over the fixture archive.
+func newTestModel(t *testing.T) model {
+	t.Helper()
+
+	tdb := testutil.SetupTestDBFile(t)
+	t.Cleanup(func() { tdb.Close() })
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	m := newModel(context.Background(), client, "")
+	return send(m, tea.WindowSizeMsg{Width: 100, Height: 30})
+}
+
+// send delivers msg to the model and runs the resulting loader, if any,
+// delivering its result too.
+func send(m model, msg tea.Msg) model {
+	next, cmd := m.Update(msg)
+	m = next.(model)
+	if cmd == nil {
+		return m
+	}
+
+	switch result := cmd().(type) {
+	case searchMsg, pageMsg, historyMsg, diffMsg:
+		next, _ = m.Update(result)
+		m = next.(model)
+	}
+	return m
+}
+
+// key returns the key press for s.
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// TestModel_Browse tests searching, opening a page, and walking its history and diffs
+func TestModel_Browse(t *testing.T) {
+	m := newTestModel(t)
+
+	// Test: Search lists matching pages
+	m.input.SetValue("capital")
+	m = send(m, key("enter"))
+	if m.screen != screenSearch || len(m.results) == 0 {
+		t.Fatalf("expected search results, got screen %d with %d results (%s)", m.screen, len(m.results), m.status)
+	}
+	if !strings.Contains(m.View(), "Prontera") {
+		t.Errorf("expected Prontera in results:\n%s", m.View())
+	}
+
+	// Test: Enter opens the selected page
+	m = send(m, key("enter"))
+	if m.screen != screenPage || m.title != "Prontera" {
+		t.Fatalf("expected Prontera page, got screen %d title %q (%s)", m.screen, m.title, m.status)
+	}
+	if !strings.Contains(m.View(), "Prontera is the capital city") {
+		t.Errorf("expected page content:\n%s", m.View())
+	}
+
+	// Test: h lists the page history, newest first
+	m = send(m, key("h"))
+	if m.screen != screenHistory || len(m.revisions) != 2 {
+		t.Fatalf("expected 2 revisions in history, got screen %d with %d (%s)", m.screen, len(m.revisions), m.status)
+	}
+	if !strings.Contains(m.View(), "Minor typo fix") {
+		t.Errorf("expected edit summary in history:\n%s", m.View())
+	}
+
+	// Test: d shows the selected revision's diff
+	m = send(m, key("d"))
+	if m.screen != screenDiff {
+		t.Fatalf("expected diff screen, got %d (%s)", m.screen, m.status)
+	}
+	if !strings.Contains(m.View(), "-Prontera is the capital city.") {
+		t.Errorf("expected removed line in diff:\n%s", m.View())
+	}
+
+	// Test: esc walks back through history to the page and the results
+	m = send(m, key("esc"))
+	if m.screen != screenHistory {
+		t.Errorf("expected history after back, got %d", m.screen)
+	}
+	m = send(m, key("esc"))
+	if m.screen != screenPage || !strings.Contains(m.View(), "Prontera is the capital city") {
+		t.Errorf("expected page after back, got %d:\n%s", m.screen, m.View())
+	}
+	m = send(m, key("esc"))
+	if m.screen != screenSearch {
+		t.Errorf("expected search after back, got %d", m.screen)
+	}
+}
+
+// TestModel_OldRevisionAndSuggestion tests viewing an old revision and following a spelling suggestion
+func TestModel_OldRevisionAndSuggestion(t *testing.T) {
+	m := newTestModel(t)
+
+	// Test: Misspelled query offers a suggestion that tab follows
+	m.input.SetValue("porring")
+	m = send(m, key("enter"))
+	if m.suggestion != "poring" {
+		t.Fatalf("expected suggestion poring, got %q", m.suggestion)
+	}
+	if !strings.Contains(m.View(), "Did you mean") {
+		t.Errorf("expected suggestion in view:\n%s", m.View())
+	}
+	m = send(m, key("tab"))
+	if m.query != "poring" || len(m.results) == 0 {
+		t.Fatalf("expected results for poring, got %q with %d results", m.query, len(m.results))
+	}
+
+	// Test: Enter on a history entry opens that revision
+	m = send(m, key("/"))
+	m.input.SetValue("Prontera")
+	m = send(m, key("enter"))
+	m = send(m, key("enter"))
+	m = send(m, key("h"))
+	m = send(m, key("j"))
+	m = send(m, key("enter"))
+	if m.screen != screenPage {
+		t.Fatalf("expected page screen, got %d (%s)", m.screen, m.status)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Old revision") || !strings.Contains(view, "Revision 102") {
+		t.Errorf("expected old revision 102:\n%s", view)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/jsonschema"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/render"
+)
+
+// browseFlags are the flags shared by page, search and history.
+type browseFlags struct {
+	fs     *flag.FlagSet
+	dbPath *string
+	format *formatFlag
+	schema *bool
+	fields *string
+
+	// output is a value of the command's json and yaml output type, and
+	// records the name of its field holding what --fields selects from.
+	output  any
+	records string
+}
+
+// newBrowseFlags creates the flag set for a read command taking one argument
+// whose json and yaml output has the type of output. --fields selects fields
+// of each record, the items of output's records field, or of output itself
+// when records is "".
+func newBrowseFlags(name, arg, summary string, output any, record, records string, stderr io.Writer, formats ...string) *browseFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	b := &browseFlags{
+		fs:      fs,
+		dbPath:  fs.String("db", defaults.DB, "path to the SQLite archive (required unless set in the config file)"),
+		format:  addFormatFlag(fs, formats...),
+		schema:  addSchemaFlag(fs),
+		fields:  addFieldsFlag(fs, record),
+		output:  output,
+		records: records,
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: irowiki %s --db <archive> [flags] <%s>\n", name, arg)
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, summary)
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+	return b
+}
+
+// parse parses args and opens the archive. It returns the joined positional
+// arguments, or a non-negative exit code if the command should stop, as it
+// does after printing the output schema for --schema.
+func (b *browseFlags) parse(args []string, stdout, stderr io.Writer) (irowiki.Client, string, int) {
+	if err := b.fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil, "", 0
+		}
+		return nil, "", 2
+	}
+	if *b.schema {
+		return nil, "", writeSchema(stdout, stderr, jsonschema.For(b.output))
+	}
+	if code := checkFields(stderr, b.output, b.records, *b.fields, b.format.value); code >= 0 {
+		return nil, "", code
+	}
+
+	arg := strings.TrimSpace(strings.Join(b.fs.Args(), " "))
+	if *b.dbPath == "" || arg == "" {
+		fmt.Fprintf(stderr, "irowiki: --db and an argument are required\n")
+		b.fs.Usage()
+		return nil, "", 2
+	}
+
+	client, err := irowiki.OpenSQLite(*b.dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return nil, "", 1
+	}
+	return client, arg, -1
+}
+
+// runPage implements "irowiki page".
+func runPage(args []string, stdout, stderr io.Writer) int {
+	b := newBrowseFlags("page", "title", "Prints the latest revision of a page, or an older one with --revision.",
+		PageOutput{}, "page", "", stderr, formatText, formatWikitext, formatMarkdown, formatJSON, formatYAML)
+	revisionID := b.fs.Int64("revision", 0, "revision ID to print instead of the latest")
+
+	client, title, code := b.parse(args, stdout, stderr)
+	if code >= 0 {
+		return code
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	page, err := irowiki.FindPage(ctx, client, title)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: page %s: %v\n", title, err)
+		return 1
+	}
+
+	out := newPageOutput(page)
+	if *revisionID != 0 && *revisionID != page.LatestRevisionID {
+		rev, err := client.GetRevision(ctx, *revisionID)
+		if err == nil && rev.PageID != page.ID {
+			err = fmt.Errorf("%w: revision belongs to another page", irowiki.ErrInvalidInput)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "irowiki: revision %d: %v\n", *revisionID, err)
+			return 1
+		}
+		out.RevisionID, out.Latest = rev.ID, false
+		out.Timestamp, out.User, out.Comment, out.Content = rev.Timestamp.UTC(), rev.User, rev.Comment, rev.Content
+	}
+
+	var pageURL func(string) string
+	if info, err := client.GetArchiveInfo(ctx); err == nil && info.BaseURL != "" {
+		pageURL = info.PageURL
+		out.SourceURL = info.PageURL(page.Title)
+	}
+
+	archiveURL, err := irowiki.ArchiveURLs(ctx, client, page.Title)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	opts := render.Options{PageURL: pageURL, ArchiveURL: archiveURL}
+
+	switch b.format.value {
+	case formatWikitext:
+		_, err = io.WriteString(stdout, out.Content)
+	case formatText:
+		_, err = fmt.Fprintf(stdout, "%s\n%s\n\n%s", out.Title, revisionLine(out), render.Text(out.Content, opts))
+	case formatMarkdown:
+		_, err = fmt.Fprintf(stdout, "# %s\n\n*%s*\n\n%s", out.Title, revisionLine(out), render.Markdown(out.Content, opts))
+	default:
+		err = b.encode(stdout, out)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runSearch implements "irowiki search".
+func runSearch(args []string, stdout, stderr io.Writer) int {
+	b := newBrowseFlags("search", "query", "Searches page content, falling back to titles for queries that aren't\nvalid full-text syntax.",
+		SearchOutput{}, "result", "results", stderr, formatText, formatJSON, formatYAML)
+	limit := b.fs.Int("limit", 20, "maximum number of results")
+	offset := b.fs.Int("offset", 0, "number of results to skip")
+
+	client, query, code := b.parse(args, stdout, stderr)
+	if code >= 0 {
+		return code
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	opts := irowiki.SearchOptions{Query: query, Limit: *limit, Offset: *offset}
+	results, err := client.SearchFullText(ctx, query, opts)
+	if err != nil {
+		results, err = client.Search(ctx, opts)
+		if err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+	}
+
+	out := newSearchOutput(query, *offset, results)
+	if len(results) == 0 && *offset == 0 {
+		if paged, err := client.SearchPaged(ctx, irowiki.SearchOptions{Query: query, Limit: 1}); err == nil {
+			out.Suggestion = paged.Suggestion
+		}
+	}
+
+	if b.format.value != formatText {
+		if err := b.encode(stdout, out); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(out.Results) == 0 {
+		fmt.Fprintf(stdout, "No results for %q.", query)
+		if out.Suggestion != "" {
+			fmt.Fprintf(stdout, " Did you mean %q?", out.Suggestion)
+		}
+		fmt.Fprintln(stdout)
+		return 0
+	}
+	for i, r := range out.Results {
+		fmt.Fprintf(stdout, "%d. %s\n", *offset+i+1, r.Title)
+		if snippet := plainSnippet(r.Snippet); snippet != "" {
+			fmt.Fprintf(stdout, "   %s\n", snippet)
+		}
+	}
+	return 0
+}
+
+// runHistory implements "irowiki history".
+func runHistory(args []string, stdout, stderr io.Writer) int {
+	b := newBrowseFlags("history", "title", "Lists a page's revisions, newest first.",
+		HistoryOutput{}, "revision", "revisions", stderr, formatText, formatJSON, formatYAML)
+	limit := b.fs.Int("limit", 50, "maximum number of revisions")
+	offset := b.fs.Int("offset", 0, "number of revisions to skip")
+
+	client, title, code := b.parse(args, stdout, stderr)
+	if code >= 0 {
+		return code
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	page, err := irowiki.FindPage(ctx, client, title)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: page %s: %v\n", title, err)
+		return 1
+	}
+
+	// The output never includes content, so don't read it
+	revisions, err := client.GetPageHistory(ctx, page.Title, irowiki.HistoryOptions{Offset: *offset, Limit: *limit, ExcludeContent: true})
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	out := newHistoryOutput(page.Title, *offset, revisions)
+
+	if b.format.value != formatText {
+		if err := b.encode(stdout, out); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, rev := range out.Revisions {
+		minor := ""
+		if rev.Minor {
+			minor = "m"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", rev.ID, rev.Timestamp.Format("2006-01-02 15:04"), rev.User, minor, rev.Comment)
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// encode writes out in the json or yaml format, keeping the fields selected by --fields.
+func (b *browseFlags) encode(w io.Writer, out any) error {
+	return encodeFields(w, b.format.value, out, b.records, *b.fields)
+}
+
+// revisionLine describes the revision being printed.
+func revisionLine(out PageOutput) string {
+	line := fmt.Sprintf("Revision %d, %s by %s", out.RevisionID, out.Timestamp.Format("2006-01-02 15:04"), out.User)
+	if !out.Latest {
+		line = "Old revision: " + line
+	}
+	return line
+}
+
+// plainSnippet removes <mark> tags and collapses whitespace in a snippet.
+func plainSnippet(snippet string) string {
+	snippet = strings.NewReplacer("<mark>", "", "</mark>", "").Replace(snippet)
+	return strings.Join(strings.Fields(snippet), " ")
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/config"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// completeCommand is the hidden command the completion scripts call. It
+// takes the words after "irowiki", the last being the one being completed,
+// and prints one candidate per line. No output means "complete file names".
+const completeCommand = "__complete"
+
+// completionScripts holds the script for each supported shell.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for irowiki
+_irowiki() {
+    local cur=${COMP_WORDS[COMP_CWORD]}
+    local IFS=$'\n'
+    COMPREPLY=($(irowiki __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    if [ ${#COMPREPLY[@]} -eq 0 ]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+    fi
+}
+complete -o filenames -F _irowiki irowiki
+`,
+	"zsh": `#compdef irowiki
+# zsh completion for irowiki
+_irowiki() {
+    local -a candidates
+    candidates=("${(@f)$(irowiki __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ -n ${candidates[1]} ]]; then
+        compadd -- $candidates
+    else
+        _files
+    fi
+}
+if [ "$funcstack[1]" = "_irowiki" ]; then
+    _irowiki "$@"
+else
+    compdef _irowiki irowiki
+fi
+`,
+	"fish": `# fish completion for irowiki
+function __irowiki_complete
+    set -l words (commandline -opc) (commandline -ct)
+    irowiki __complete $words[2..-1] 2>/dev/null
+end
+complete -c irowiki -a '(__irowiki_complete)'
+`,
+}
+
+// runCompletion implements "irowiki completion".
+func runCompletion(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 || completionScripts[args[0]] == "" {
+		fmt.Fprintln(stderr, "Usage: irowiki completion <bash|zsh|fish>")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Prints a completion script. For example:")
+		fmt.Fprintln(stderr, "  bash: source <(irowiki completion bash)")
+		fmt.Fprintln(stderr, "  zsh:  irowiki completion zsh > \"${fpath[1]}/_irowiki\"")
+		fmt.Fprintln(stderr, "  fish: irowiki completion fish > ~/.config/fish/completions/irowiki.fish")
+		if len(args) == 1 && (args[0] == "-h" || args[0] == "--help") {
+			return 0
+		}
+		return 2
+	}
+
+	fmt.Fprint(stdout, completionScripts[args[0]])
+	return 0
+}
+
+// runConfig implements "irowiki config".
+func runConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		fmt.Fprintln(stderr, "Usage: irowiki config")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Prints the config file location and the settings in effect, with")
+		fmt.Fprintln(stderr, "secrets redacted. Keys used by irowiki:")
+		fmt.Fprintln(stderr, "  db      default archive for --db ($IROWIKI_DB)")
+		fmt.Fprintln(stderr, "  format  default --format, for commands that support it ($IROWIKI_FORMAT)")
+		fmt.Fprintln(stderr, "  server  defaults for irowiki serve: addr, title, admin_token, download,")
+		fmt.Fprintln(stderr, "          slow_query, fts_tokenizer, vector_reindex_cmd")
+		fmt.Fprintln(stderr, "The wiki, scraper, storage, logging and sync sections configure the")
+		fmt.Fprintln(stderr, "scraper and sync jobs.")
+		fmt.Fprintf(stderr, "Set %s to use another file.\n", config.EnvVar)
+		if args[0] == "-h" || args[0] == "--help" {
+			return 0
+		}
+		return 2
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "# %s\n", path)
+	if err := yaml.NewEncoder(stdout).Encode(defaults.Redacted()); err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+var (
+	// helpFlagPattern matches a flag line printed by flag.PrintDefaults; the
+	// second group is the value placeholder, empty for boolean flags.
+	helpFlagPattern = regexp.MustCompile(`(?m)^  -(\S+)(?: (\S+))?`)
+
+	// helpFormatPattern matches the --format usage written by addFormatFlag.
+	helpFormatPattern = regexp.MustCompile(`output format: ([a-z, ]+)`)
+)
+
+// runComplete prints completion candidates for words.
+func runComplete(words []string, stdout io.Writer) int {
+	for _, candidate := range complete(words) {
+		fmt.Fprintln(stdout, candidate)
+	}
+	return 0
+}
+
+// complete returns the candidates for the last of words that start with it.
+func complete(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	var candidates []string
+	if len(words) == 1 {
+		for _, cmd := range commands {
+			candidates = append(candidates, cmd.name)
+		}
+		return withPrefix(candidates, current)
+	}
+
+	name := words[0]
+	args := words[1 : len(words)-1]
+	switch {
+	case name == "completion" && len(args) == 0:
+		candidates = []string{"bash", "fish", "zsh"}
+		return withPrefix(candidates, current)
+	case name == "export" && len(args) == 0 && !strings.HasPrefix(current, "-"):
+		return withPrefix([]string{"csv"}, current)
+	case name == "schema" && len(args) == 0:
+		return withPrefix(schemaNames(), current)
+	case name == "stats" && len(args) == 0:
+		return withPrefix([]string{"diff", "digest"}, current)
+	case name == "stats" && (args[0] == "diff" || args[0] == "digest"):
+		name = "stats " + args[0]
+		args = args[1:]
+	case name == "export" && len(args) > 0 && args[0] == "csv":
+		args = args[1:]
+		name = "export csv"
+	}
+
+	flags, formats := commandFlags(name)
+	if len(args) > 0 && strings.HasPrefix(args[len(args)-1], "-") {
+		switch prev := strings.TrimLeft(args[len(args)-1], "-"); {
+		case prev == "format":
+			return withPrefix(formats, current)
+		case prev == "table":
+			return withPrefix(irowiki.ExportTables(), current)
+		case flags[prev]:
+			// Other flag values (paths, addresses, numbers) fall back to files
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		for f := range flags {
+			candidates = append(candidates, "--"+f)
+		}
+	}
+	return withPrefix(candidates, current)
+}
+
+// commandFlags reads a command's flags from its -h output, reporting which
+// take a value, and the formats its --format accepts.
+func commandFlags(name string) (map[string]bool, []string) {
+	args := append(strings.Fields(name)[1:], "-h")
+	var help bytes.Buffer
+	for _, cmd := range commands {
+		if cmd.name == strings.Fields(name)[0] {
+			cmd.run(args, io.Discard, &help)
+		}
+	}
+
+	flags := make(map[string]bool)
+	for _, m := range helpFlagPattern.FindAllStringSubmatch(help.String(), -1) {
+		flags[m[1]] = m[2] != ""
+	}
+
+	var formats []string
+	if m := helpFormatPattern.FindStringSubmatch(help.String()); m != nil {
+		formats = strings.Split(m[1], ", ")
+	}
+	return flags, formats
+}
+
+// withPrefix returns the sorted candidates that start with prefix.
+func withPrefix(candidates []string, prefix string) []string {
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	slices.Sort(matches)
+	return matches
+}
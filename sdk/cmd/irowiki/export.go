@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// runExport implements "irowiki export", which writes CSV unless --format
+// says otherwise, and its "irowiki export csv" shorthand.
+func runExport(args []string, stdout, stderr io.Writer) int {
+	switch {
+	case len(args) > 0 && args[0] == "csv":
+		return runExportTable("export csv", args[1:], stdout, stderr, formatCSV)
+	case len(args) > 0 && strings.HasPrefix(args[0], "-"):
+		return runExportTable("export", args, stdout, stderr, formatCSV, formatJSON, formatYAML)
+	}
+
+	fmt.Fprintln(stderr, "Usage: irowiki export [csv] --db <archive> --table <table> [flags]")
+	return 2
+}
+
+// runExportTable exports a table in one of formats; with a single format
+// there is no --format flag.
+func runExportTable(name string, args []string, stdout, stderr io.Writer, formats ...string) int {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	format := &formatFlag{value: formats[0]}
+	schema := new(bool)
+	if len(formats) > 1 {
+		format = addFormatFlag(fs, formats...)
+		schema = addSchemaFlag(fs)
+	}
+
+	dbPath := fs.String("db", defaults.DB, "path to the SQLite archive (required unless set in the config file)")
+	table := fs.String("table", "", "table to export: "+strings.Join(irowiki.ExportTables(), ", ")+" (required)")
+	columns := fs.String("columns", "", "comma-separated columns to export (default: all stored columns)")
+	output := fs.String("output", "", "output file (default: stdout)")
+	limit := fs.Int("limit", 0, "maximum number of rows (0 = all)")
+	tokenModel := fs.String("token-model", "", `model the "tokens" column estimates for, e.g. all-MiniLM-L6-v2 (default: GPT-style)`)
+	progress := fs.Bool("progress", false, "show a progress bar on stderr")
+	var where stringList
+	fs.Var(&where, "where", `filter such as "year=2020" or "user!=Admin"; repeat to combine (operators: = != < <= > >= ~)`)
+
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: irowiki %s --db <archive> --table <table> [flags]\n", name)
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Exports a table as RFC 4180 CSV with a header row, or with --format as a")
+		fmt.Fprintln(stderr, "JSON or YAML array of objects with string values keyed by column.")
+		fmt.Fprintln(stderr, `Revisions and files also offer "year" and "month" columns; revisions offer "title".`)
+		fmt.Fprintln(stderr, `Pages and revisions offer "tokens", the estimated token count of their content.`)
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Example: all edits in 2020 with their page titles")
+		fmt.Fprintln(stderr, "  irowiki export csv --db irowiki.db --table revisions \\")
+		fmt.Fprintln(stderr, "    --columns timestamp,title,user,comment --where year=2020 --output edits-2020.csv")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if (*dbPath == "" && !*schema) || *table == "" {
+		fmt.Fprintln(stderr, "irowiki: --db and --table are required")
+		fs.Usage()
+		return 2
+	}
+
+	opts := irowiki.ExportOptions{Table: *table, Limit: *limit, TokenModel: *tokenModel}
+	if *columns != "" {
+		for _, col := range strings.Split(*columns, ",") {
+			if col = strings.TrimSpace(col); col != "" {
+				opts.Columns = append(opts.Columns, col)
+			}
+		}
+	}
+	for _, expr := range where {
+		filter, err := irowiki.ParseExportFilter(expr)
+		if err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 2
+		}
+		opts.Filters = append(opts.Filters, filter)
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 2
+	}
+	if *schema {
+		return writeSchema(stdout, stderr, exportSchema(opts.Table, opts.Columns))
+	}
+
+	client, err := irowiki.OpenSQLite(*dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	out := stdout
+	var file *os.File
+	if *output != "" {
+		if file, err = os.Create(*output); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	bar := &progressBar{w: stderr}
+	if *progress {
+		opts.Progress = bar
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if format.value == formatCSV {
+		// Rows are written as they are read; csv.Writer only buffers to batch writes
+		w := csv.NewWriter(out)
+		w.UseCRLF = true
+
+		err := client.ExportRows(ctx, opts, w)
+		bar.Finish()
+		if err != nil {
+			fmt.Fprintf(stderr, "irowiki: export failed: %v\n", err)
+			return 1
+		}
+
+		w.Flush()
+		if err := w.Error(); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+	} else {
+		w := newRecordWriter(out, format.value)
+		err := client.ExportRows(ctx, opts, w)
+		bar.Finish()
+		if err != nil {
+			fmt.Fprintf(stderr, "irowiki: export failed: %v\n", err)
+			return 1
+		}
+		if err := w.Close(); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+	}
+	// Close errors can mean the file was not fully written
+	if file != nil {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
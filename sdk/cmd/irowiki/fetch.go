@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/jsonschema"
+)
+
+// runFetchArchive implements "irowiki fetch-archive".
+func runFetchArchive(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("fetch-archive", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	output := fs.String("output", "irowiki.db", "file to write the archive to")
+	format := addFormatFlag(fs, formatText, formatJSON, formatYAML)
+	schema := addSchemaFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: irowiki fetch-archive [flags] <url>")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Downloads an archive from a mirror running \"irowiki serve --download\".")
+		fmt.Fprintln(stderr, "Interrupted downloads are kept as <output>.part and resumed by running")
+		fmt.Fprintln(stderr, "the command again, unless the archive changed in the meantime. The file")
+		fmt.Fprintln(stderr, "is checked against the mirror's SHA-256 before replacing --output.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Example: irowiki fetch-archive --output monsters.db 'https://mirror.example/archive?namespace=0'")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *schema {
+		return writeSchema(stdout, stderr, jsonschema.For(FetchArchiveOutput{}))
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	out, err := fetchArchive(ctx, fs.Arg(0), *output)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+
+	if format.value != formatText {
+		if err := encode(stdout, format.value, out); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stdout, "Fetched %s: %d bytes", out.Path, out.Bytes)
+	if out.Resumed > 0 {
+		fmt.Fprintf(stdout, " (resumed at %d)", out.Resumed)
+	}
+	fmt.Fprintf(stdout, "\nsha256 %s", out.SHA256)
+	if !out.Verified {
+		fmt.Fprint(stdout, " (not verified: the server sent no checksum)")
+	}
+	fmt.Fprintln(stdout)
+	return 0
+}
+
+// fetchArchive downloads url to path, resuming a previous partial download.
+func fetchArchive(ctx context.Context, url, path string) (*FetchArchiveOutput, error) {
+	part := path + ".part"
+	etagFile := part + ".etag"
+
+	// A partial download is only resumed if the server still has the same
+	// file, which If-Range checks against the ETag it was started with
+	var offset int64
+	etag, err := os.ReadFile(etagFile)
+	if err == nil {
+		if info, err := os.Stat(part); err == nil {
+			offset = info.Size()
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", string(etag))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)):
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The part is at least as long as the file: start over next time
+		os.Remove(part)
+		os.Remove(etagFile)
+		return nil, fmt.Errorf("%s: partial download doesn't match the archive; run again to restart", url)
+	default:
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		if err := os.WriteFile(etagFile, []byte(etag), 0o644); err != nil {
+			return nil, err
+		}
+	} else {
+		os.Remove(etagFile)
+	}
+
+	f, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("download interrupted (run again to resume): %w", err)
+	}
+
+	sum, size, err := fileSHA256(part)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &FetchArchiveOutput{Path: path, URL: url, Bytes: size, Resumed: offset, SHA256: hex.EncodeToString(sum)}
+	if want, ok := reprDigestSHA256(resp.Header.Get("Repr-Digest")); ok {
+		if !bytes.Equal(sum, want) {
+			os.Remove(part)
+			os.Remove(etagFile)
+			return nil, fmt.Errorf("%s: checksum mismatch (got sha256 %x, want %x)", url, sum, want)
+		}
+		out.Verified = true
+	}
+
+	if err := os.Rename(part, path); err != nil {
+		return nil, err
+	}
+	os.Remove(etagFile)
+	return out, nil
+}
+
+// fileSHA256 returns the SHA-256 and size of a file.
+func fileSHA256(path string) ([]byte, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return h.Sum(nil), n, nil
+}
+
+// reprDigestSHA256 extracts the sha-256 value of a Repr-Digest header
+// (RFC 9530), such as "sha-256=:<base64>:".
+func reprDigestSHA256(header string) ([]byte, bool) {
+	for _, member := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok || name != "sha-256" {
+			continue
+		}
+		sum, err := base64.StdEncoding.DecodeString(strings.Trim(value, ":"))
+		if err != nil || len(sum) != sha256.Size {
+			return nil, false
+		}
+		return sum, true
+	}
+	return nil, false
+}
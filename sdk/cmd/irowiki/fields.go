@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// addFieldsFlag registers --fields on fs. record names what the fields
+// select from, such as "revision" for history.
+func addFieldsFlag(fs *flag.FlagSet, record string) *string {
+	return fs.String("fields", "", "comma-separated fields of each "+record+" to include in json and yaml output (default all)")
+}
+
+// checkFields validates --fields against output before any work is done. It
+// returns a non-negative exit code if the command should stop.
+func checkFields(stderr io.Writer, output any, records, fields, format string) int {
+	if fields == "" {
+		return -1
+	}
+	if format != formatJSON && format != formatYAML {
+		fmt.Fprintln(stderr, "irowiki: --fields requires --format json or yaml")
+		return 2
+	}
+	if _, err := selectFields(output, records, fields); err != nil {
+		fmt.Fprintf(stderr, "irowiki: --fields: %v\n", err)
+		return 2
+	}
+	return -1
+}
+
+// encodeFields writes out like encode, keeping the fields selected by --fields.
+func encodeFields(w io.Writer, format string, out any, records, fields string) error {
+	v, err := selectFields(out, records, fields)
+	if err != nil {
+		return err
+	}
+	return encode(w, format, v)
+}
+
+// selectFields returns v with only the named fields of its records. records
+// is the JSON name of v's slice field holding them, or "" when v is itself
+// the record. v is returned unchanged when fields is empty.
+func selectFields(v any, records, fields string) (any, error) {
+	if strings.TrimSpace(fields) == "" {
+		return v, nil
+	}
+
+	var names []string
+	for _, name := range strings.Split(fields, ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	recordType := rv.Type()
+	if records != "" {
+		for _, f := range jsonFields(rv.Type()) {
+			if f.name == records {
+				recordType = rv.Type().Field(f.index).Type.Elem()
+			}
+		}
+	}
+	known := jsonFields(recordType)
+	for _, name := range names {
+		if !slices.ContainsFunc(known, func(f jsonField) bool { return f.name == name }) {
+			want := make([]string, len(known))
+			for i, f := range known {
+				want[i] = f.name
+			}
+			return nil, fmt.Errorf("unknown field %q (want %s)", name, strings.Join(want, ", "))
+		}
+	}
+
+	if records == "" {
+		return projectRecord(rv, names), nil
+	}
+
+	out := fieldObject{}
+	for _, f := range jsonFields(rv.Type()) {
+		value := rv.Field(f.index)
+		if f.name == records {
+			items := make([]fieldObject, 0, value.Len())
+			for i := 0; i < value.Len(); i++ {
+				items = append(items, projectRecord(value.Index(i), names))
+			}
+			out.add(f.name, items)
+			continue
+		}
+		if !(f.omitEmpty && isEmpty(value)) {
+			out.add(f.name, value.Interface())
+		}
+	}
+	return out, nil
+}
+
+// projectRecord keeps the named fields of a record struct, in struct order.
+func projectRecord(rv reflect.Value, names []string) fieldObject {
+	out := fieldObject{}
+	for _, f := range jsonFields(rv.Type()) {
+		if slices.Contains(names, f.name) {
+			out.add(f.name, rv.Field(f.index).Interface())
+		}
+	}
+	return out
+}
+
+// jsonField is an exported field of an output struct.
+type jsonField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// jsonFields lists the fields of an output struct as encoding/json names them.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, jsonField{name: name, index: i, omitEmpty: slices.Contains(strings.Split(opts, ","), "omitempty")})
+	}
+	return fields
+}
+
+// isEmpty reports whether encoding/json's omitempty would drop v.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// fieldObject is an object whose keys encode in insertion order, so
+// selected fields keep the order of the full output.
+type fieldObject struct {
+	keys   []string
+	values []any
+}
+
+func (o *fieldObject) add(key string, value any) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o fieldObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (o fieldObject) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i, key := range o.keys {
+		var value yaml.Node
+		if err := value.Encode(o.values[i]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &value)
+	}
+	return node, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --format. Not every command supports every
+// format; each command lists the ones it accepts.
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatYAML     = "yaml"
+	formatCSV      = "csv"
+	formatWikitext = "wikitext"
+	formatMarkdown = "markdown"
+	formatHTML     = "html"
+)
+
+// formatFlag is a --format flag restricted to a set of formats.
+type formatFlag struct {
+	value   string
+	allowed []string
+}
+
+// addFormatFlag registers --format on fs. It defaults to the config file's
+// format when the command supports it, and to the first allowed format otherwise.
+func addFormatFlag(fs *flag.FlagSet, allowed ...string) *formatFlag {
+	f := &formatFlag{value: allowed[0], allowed: allowed}
+	if slices.Contains(allowed, defaults.Format) {
+		f.value = defaults.Format
+	}
+	fs.Var(f, "format", "output format: "+strings.Join(allowed, ", "))
+	return f
+}
+
+func (f *formatFlag) String() string { return f.value }
+
+func (f *formatFlag) Set(v string) error {
+	v = strings.ToLower(strings.TrimSpace(v))
+	for _, allowed := range f.allowed {
+		if v == allowed {
+			f.value = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported format %q (want %s)", v, strings.Join(f.allowed, ", "))
+}
+
+// encode writes v as indented JSON or as YAML.
+func encode(w io.Writer, format string, v any) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("format %q cannot encode values", format)
+}
+
+// recordWriter streams export rows as a JSON or YAML array of objects keyed
+// by column name, in column order. It implements irowiki.RowWriter; the first
+// row written is the header.
+type recordWriter struct {
+	w       io.Writer
+	format  string
+	columns []string
+	rows    int
+	err     error
+
+	// array streams the JSON array to w
+	array *irowiki.JSONArrayWriter
+}
+
+// newRecordWriter returns a recordWriter writing format to w.
+func newRecordWriter(w io.Writer, format string) *recordWriter {
+	return &recordWriter{w: w, format: format, array: irowiki.NewJSONArrayWriter(w)}
+}
+
+// Write writes one row, or records the header.
+func (r *recordWriter) Write(record []string) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.columns == nil {
+		r.columns = append([]string{}, record...)
+		return nil
+	}
+
+	if r.format == formatJSON {
+		r.err = r.writeJSON(record)
+	} else {
+		r.err = r.writeYAML(record)
+	}
+	r.rows++
+	return r.err
+}
+
+// writeJSON writes one row as a JSON object inside the array.
+func (r *recordWriter) writeJSON(record []string) error {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, col := range r.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		key, _ := json.Marshal(col)
+		value, _ := json.Marshal(record[i])
+		b.Write(key)
+		b.WriteString(": ")
+		b.Write(value)
+	}
+	b.WriteString("}")
+	return r.array.WriteRaw([]byte(b.String()))
+}
+
+// writeYAML writes one row as a YAML sequence item.
+func (r *recordWriter) writeYAML(record []string) error {
+	row := &yaml.Node{Kind: yaml.MappingNode}
+	for i, col := range r.columns {
+		row.Content = append(row.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: col},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: record[i]},
+		)
+	}
+	out, err := yaml.Marshal(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{row}})
+	if err != nil {
+		return err
+	}
+	_, err = r.w.Write(out)
+	return err
+}
+
+// Close terminates the array; an export with no rows writes an empty one.
+func (r *recordWriter) Close() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.format == formatJSON {
+		return r.array.Close()
+	}
+	if r.rows == 0 {
+		_, err := io.WriteString(r.w, "[]\n")
+		return err
+	}
+	return nil
+}
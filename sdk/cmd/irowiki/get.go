@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/jsonschema"
+)
+
+// runGet implements "irowiki get".
+func runGet(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	dbPath := fs.String("db", defaults.DB, "path to the SQLite archive (required unless set in the config file)")
+	titlesFile := fs.String("titles-file", "", `file with one title per line ("-" for stdin)`)
+	format := addFormatFlag(fs, formatText, formatJSON, formatYAML)
+	schema := addSchemaFlag(fs)
+	fields := addFieldsFlag(fs, "page")
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: irowiki get --db <archive> [flags] [title...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Resolves many titles in one batch. Titles come from the arguments,")
+		fmt.Fprintln(stderr, "--titles-file, or stdin when neither is given; blank lines and lines")
+		fmt.Fprintln(stderr, "starting with # are skipped. Titles are matched ignoring the case of the")
+		fmt.Fprintln(stderr, "first letter and spaces versus underscores.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "The text format prints the stored title of each page found; json and")
+		fmt.Fprintln(stderr, "yaml include the pages' content, unless --fields leaves it out. Missing")
+		fmt.Fprintln(stderr, "titles are reported on stderr and make the command exit with status 3.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Example: irowiki get --format json < monsters.txt > monsters.json")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *schema {
+		return writeSchema(stdout, stderr, jsonschema.For(GetOutput{}))
+	}
+	if code := checkFields(stderr, GetOutput{}, "pages", *fields, format.value); code >= 0 {
+		return code
+	}
+	if *dbPath == "" {
+		fmt.Fprintln(stderr, "irowiki: --db is required")
+		fs.Usage()
+		return 2
+	}
+
+	titles, err := readTitles(fs.Args(), *titlesFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	if len(titles) == 0 {
+		fmt.Fprintln(stderr, "irowiki: no titles given")
+		return 2
+	}
+
+	client, err := irowiki.OpenSQLite(*dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	results, err := client.GetPagesByTitle(context.Background(), titles)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+
+	out := GetOutput{Pages: []PageOutput{}, Missing: []string{}}
+	for _, r := range results {
+		if !r.Found() {
+			out.Missing = append(out.Missing, r.Requested)
+			continue
+		}
+		page := newPageOutput(r.Page)
+		page.Requested = r.Requested
+		out.Pages = append(out.Pages, page)
+	}
+
+	if format.value == formatText {
+		for _, page := range out.Pages {
+			fmt.Fprintln(stdout, page.Title)
+		}
+	} else if err := encodeFields(stdout, format.value, out, "pages", *fields); err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+
+	if len(out.Missing) > 0 {
+		for _, title := range out.Missing {
+			fmt.Fprintf(stderr, "irowiki: missing: %s\n", title)
+		}
+		fmt.Fprintf(stderr, "irowiki: %d of %d titles not found\n", len(out.Missing), len(titles))
+		return 3
+	}
+	return 0
+}
+
+// readTitles collects titles from args, or from the titles file, or from
+// stdin when neither is given.
+func readTitles(args []string, titlesFile string) ([]string, error) {
+	titles := append([]string{}, args...)
+
+	var r io.Reader
+	switch {
+	case titlesFile == "-" || (titlesFile == "" && len(args) == 0):
+		r = stdin
+	case titlesFile != "":
+		f, err := os.Open(titlesFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	default:
+		return titles, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		titles = append(titles, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read titles: %w", err)
+	}
+	return titles, nil
+}
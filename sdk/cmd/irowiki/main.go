@@ -0,0 +1,121 @@
+// Command irowiki works with iRO Wiki archives from the command line.
+//
+// Usage:
+//
+//	irowiki <command> [subcommand] [flags]
+//
+// Commands:
+//
+//	page          Print a page as text, wikitext or Markdown
+//	get           Resolve many titles at once from arguments, a file or stdin
+//	search        Search page content
+//	history       List a page's revisions
+//	stats diff    Report growth between two archive snapshots
+//	stats digest  Print a month's digest as Markdown or HTML
+//	export        Export a table as CSV, JSON or YAML ("export csv" for CSV)
+//	serve         Serve a read-only website for browsing the archive
+//	fetch-archive Download an archive from a serve --download mirror
+//	schema        Print the JSON Schema of an SDK type or CLI output
+//	config        Show the config file location and values
+//	completion    Print a bash, zsh or fish completion script
+//
+// Every command accepts --format. The JSON and YAML outputs follow the
+// documented PageOutput, GetOutput, SearchOutput, HistoryOutput,
+// StatsDiffOutput, ServeOutput and FetchArchiveOutput types, whose field
+// names are stable; --schema prints a command's output type as a JSON Schema.
+//
+// Defaults for --db, --format and the serve flags can be set in
+// ~/.config/irowiki/config.yaml or IROWIKI_* environment variables (see
+// "irowiki config").
+//
+// Run "irowiki <command> -h" for command flags.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/config"
+)
+
+// command is a top-level CLI command.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands lists the available commands in help order.
+var commands = []command{
+	{"page", "Print a page (text, wikitext, markdown, json, yaml)", runPage},
+	{"get", "Resolve many titles and report missing ones", runGet},
+	{"search", "Search page content", runSearch},
+	{"history", "List a page's revisions", runHistory},
+	{"stats", "Compare archive snapshots (diff) or summarize a month (digest)", runStats},
+	{"export", "Export a table (csv, json, yaml)", runExport},
+	{"serve", "Browse the archive in a web browser", runServe},
+	{"fetch-archive", "Download an archive from a mirror, resuming if interrupted", runFetchArchive},
+	{"schema", "Print the JSON Schema of a type (Page, SearchOutput, ...)", runSchema},
+	{"config", "Show the config file location and values", runConfig},
+	{"completion", "Print a shell completion script (bash, zsh, fish)", runCompletion},
+}
+
+// stdin is read by commands that accept piped input.
+var stdin io.Reader = os.Stdin
+
+// defaults holds the config file and environment settings, which become
+// flag defaults; run loads them before dispatching.
+var defaults = config.Default()
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches to a command and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	if args[0] == completeCommand {
+		// Completion must never print errors into the user's shell
+		if cfg, err := config.Load(); err == nil {
+			defaults = cfg
+		}
+		return runComplete(args[1:], stdout)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	defaults = cfg
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "irowiki: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+// usage prints the command list.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: irowiki <command> [subcommand] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-13s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "irowiki <command> -h" for command flags.`)
+}
@@ -0,0 +1,536 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/config"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/server"
+)
+
+// TestMain keeps a developer's own config file out of the tests.
+func TestMain(m *testing.M) {
+	os.Setenv(config.EnvVar, os.DevNull)
+	os.Exit(m.Run())
+}
+
+// TestRun_ExportCSV tests the export csv command end to end
+func TestRun_ExportCSV(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Test: CSV goes to stdout with CRLF line endings and quoted fields
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"export", "csv", "--db", tdb.Path, "--table", "revisions",
+		"--columns", "revision_id,comment", "--where", "page_id=2"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	want := "revision_id,comment\r\n102,Created Prontera page\r\n103,Minor typo fix\r\n"
+	if stdout.String() != want {
+		t.Errorf("expected %q, got %q", want, stdout.String())
+	}
+
+	// Test: --output writes to a file, with --progress drawing a bar on stderr
+	out := filepath.Join(t.TempDir(), "pages.csv")
+	stderr.Reset()
+	code = run([]string{"export", "csv", "--db", tdb.Path, "--table", "pages", "--columns", "title", "--output", out, "--progress"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.HasSuffix(stderr.String(), "5/5 (100%)\n") {
+		t.Errorf("expected a finished progress bar, got %q", stderr.String())
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "title\r\nMain_Page\r\n") {
+		t.Errorf("unexpected file contents: %q", data)
+	}
+
+	// Test: Usage errors exit with code 2
+	for _, args := range [][]string{
+		{"export", "csv", "--table", "pages"},
+		{"export", "csv", "--db", tdb.Path, "--table", "nope"},
+		{"export", "csv", "--db", tdb.Path, "--table", "pages", "--where", "bogus"},
+		{"frobnicate"},
+	} {
+		stderr.Reset()
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("expected exit code 2 for %v, got %d", args, code)
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("expected an error message for %v", args)
+		}
+	}
+}
+
+// TestRun_Formats tests --format on the read commands and export
+func TestRun_Formats(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"page text", []string{"page", "--db", tdb.Path, "Poring"}, []string{"Poring\nRevision 104", "Poring is a pink slime monster."}},
+		{"page wikitext", []string{"page", "--db", tdb.Path, "--format", "wikitext", "Poring"}, []string{"Poring is a pink slime monster."}},
+		{"page markdown", []string{"page", "--db", tdb.Path, "--format", "markdown", "Main Page"}, []string{"# Main_Page\n"}},
+		{"page json", []string{"page", "--db", tdb.Path, "--format", "json", "--revision", "102", "Prontera"},
+			[]string{`"title": "Prontera"`, `"revision_id": 102`, `"latest": false`, `"timestamp": "2020-01-03T00:00:00Z"`}},
+		{"page yaml", []string{"page", "--db", tdb.Path, "--format", "yaml", "Poring"}, []string{"title: Poring\n", "revision_id: 104\n"}},
+		{"search text", []string{"search", "--db", tdb.Path, "slime"}, []string{"1. Poring\n   Poring is a pink slime monster."}},
+		{"search json", []string{"search", "--db", tdb.Path, "--format", "json", "slime"}, []string{`"query": "slime"`, `"title": "Poring"`}},
+		{"search suggestion", []string{"search", "--db", tdb.Path, "--format", "yaml", "porring"}, []string{"results: []\n", "suggestion: poring\n"}},
+		{"history text", []string{"history", "--db", tdb.Path, "Prontera"}, []string{"103  2020-01-04 00:00  Editor  m  Minor typo fix"}},
+		{"history yaml", []string{"history", "--db", tdb.Path, "--format", "yaml", "Prontera"}, []string{"revisions:\n  - id: 103\n    parent_id: 102\n"}},
+		{"export json", []string{"export", "--db", tdb.Path, "--format", "json", "--table", "revisions", "--columns", "revision_id,comment", "--where", "page_id=2"},
+			[]string{"[\n  {\"revision_id\": \"102\", \"comment\": \"Created Prontera page\"},\n  {\"revision_id\": \"103\", \"comment\": \"Minor typo fix\"}\n]\n"}},
+		{"export yaml", []string{"export", "--db", tdb.Path, "--format", "yaml", "--table", "revisions", "--columns", "revision_id,comment", "--where", "page_id=2"},
+			[]string{"- revision_id: \"102\"\n  comment: Created Prontera page\n- revision_id: \"103\"\n"}},
+		{"export empty", []string{"export", "--db", tdb.Path, "--format", "json", "--table", "pages", "--where", "page_id=999"}, []string{"[]\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != 0 {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, stdout.String())
+				}
+			}
+		})
+	}
+
+	// Test: Unsupported formats are usage errors
+	var stdout, stderr bytes.Buffer
+	for _, args := range [][]string{
+		{"search", "--db", tdb.Path, "--format", "wikitext", "slime"},
+		{"export", "csv", "--db", tdb.Path, "--table", "pages", "--format", "json"},
+	} {
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("expected exit code 2 for %v, got %d", args, code)
+		}
+	}
+}
+
+// TestRun_Config tests that config file values become flag defaults
+func TestRun_Config(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("db: "+tdb.Path+"\nformat: json\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv(config.EnvVar, path)
+
+	// Test: --db and --format come from the config file
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"page", "Poring"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"title": "Poring"`) {
+		t.Errorf("expected JSON output, got %s", stdout.String())
+	}
+
+	// Test: Flags override the config and unsupported formats fall back
+	stdout.Reset()
+	run([]string{"page", "--format", "wikitext", "Poring"}, &stdout, &stderr)
+	if stdout.String() != "Poring is a pink slime monster." {
+		t.Errorf("expected wikitext, got %q", stdout.String())
+	}
+	stdout.Reset()
+	run([]string{"export", "csv", "--table", "pages", "--columns", "title", "--limit", "1"}, &stdout, &stderr)
+	if stdout.String() != "title\r\nMain_Page\r\n" {
+		t.Errorf("expected CSV, got %q", stdout.String())
+	}
+
+	// Test: config shows the file and its values
+	stdout.Reset()
+	run([]string{"config"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "# "+path) || !strings.Contains(stdout.String(), "format: json") {
+		t.Errorf("unexpected config output: %s", stdout.String())
+	}
+
+	// Test: An invalid config file is reported
+	if err := os.WriteFile(path, []byte("dbpath: x\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	stderr.Reset()
+	if code := run([]string{"page", "Poring"}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "dbpath") {
+		t.Errorf("expected config error, got %d: %s", code, stderr.String())
+	}
+}
+
+// TestComplete tests completion candidates
+func TestComplete(t *testing.T) {
+	t.Setenv(config.EnvVar, os.DevNull)
+
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{""}, []string{"completion", "config", "export", "fetch-archive", "get", "history", "page", "schema", "search", "serve", "stats"}},
+		{[]string{"se"}, []string{"search", "serve"}},
+		{[]string{"export", ""}, []string{"csv"}},
+		{[]string{"search", "--f"}, []string{"--fields", "--format"}},
+		{[]string{"page", "--format", ""}, []string{"json", "markdown", "text", "wikitext", "yaml"}},
+		{[]string{"export", "csv", "--table", "p"}, []string{"pages"}},
+		{[]string{"serve", "--db", ""}, nil},
+		{[]string{"completion", "z"}, []string{"zsh"}},
+		{[]string{"stats", "d"}, []string{"diff", "digest"}},
+		{[]string{"stats", "digest", "--format", ""}, []string{"html", "json", "markdown", "yaml"}},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		run(append([]string{"__complete"}, tt.words...), &stdout, &stderr)
+		got := strings.Fields(stdout.String())
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%v: expected %v, got %v", tt.words, tt.want, got)
+		}
+	}
+
+	// Test: Each shell has a script that calls back into irowiki
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"completion", shell}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "irowiki __complete") {
+			t.Errorf("%s: unexpected script (exit %d)", shell, code)
+		}
+	}
+}
+
+// TestRun_Get tests bulk title resolution from arguments, files and stdin
+func TestRun_Get(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Test: Titles from stdin resolve in order and missing ones fail the command
+	stdin = strings.NewReader("# monsters\nporing\n\nmain Page\nBaphomet\n")
+	t.Cleanup(func() { stdin = os.Stdin })
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"get", "--db", tdb.Path}, &stdout, &stderr)
+	if code != 3 {
+		t.Fatalf("expected exit code 3, got %d: %s", code, stderr.String())
+	}
+	if stdout.String() != "Poring\nMain_Page\n" {
+		t.Errorf("expected resolved titles, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "missing: Baphomet") || !strings.Contains(stderr.String(), "1 of 3 titles not found") {
+		t.Errorf("expected missing report, got %s", stderr.String())
+	}
+
+	// Test: A titles file plus arguments with JSON output
+	list := filepath.Join(t.TempDir(), "titles.txt")
+	if err := os.WriteFile(list, []byte("Prontera\n"), 0o600); err != nil {
+		t.Fatalf("failed to write titles: %v", err)
+	}
+	stdout.Reset()
+	code = run([]string{"get", "--db", tdb.Path, "--format", "json", "--titles-file", list, "Poring"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	for _, want := range []string{`"requested": "Poring"`, `"content": "Prontera is the capital city"`, `"missing": []`} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected output to contain %q, got %s", want, stdout.String())
+		}
+	}
+	if strings.Index(stdout.String(), `"title": "Poring"`) > strings.Index(stdout.String(), `"title": "Prontera"`) {
+		t.Errorf("expected argument titles before file titles")
+	}
+}
+
+// TestRun_Fields tests selecting output fields with --fields
+func TestRun_Fields(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Test: Only the selected fields of each record are printed, in output order
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"get", "--db", tdb.Path, "--format", "json", "--fields", "title,id", "Poring"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var out struct {
+		Pages   []map[string]any `json:"pages"`
+		Missing []string         `json:"missing"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.Pages) != 1 || len(out.Pages[0]) != 2 || out.Pages[0]["title"] != "Poring" || out.Missing == nil {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	if strings.Index(stdout.String(), `"id"`) > strings.Index(stdout.String(), `"title"`) {
+		t.Errorf("expected fields in output order, got %s", stdout.String())
+	}
+
+	// Test: Single-record outputs and YAML are projected too
+	stdout.Reset()
+	code = run([]string{"page", "--db", tdb.Path, "--format", "yaml", "--fields", "revision_id", "Poring"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if stdout.String() != "revision_id: 104\n" {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+
+	// Test: Unknown fields and text output are rejected before opening the archive
+	for _, args := range [][]string{
+		{"history", "--db", tdb.Path, "--format", "json", "--fields", "content", "Poring"},
+		{"search", "--db", tdb.Path, "--fields", "title", "slime"},
+	} {
+		stderr.Reset()
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: expected exit code 2, got %d", args, code)
+		}
+	}
+	if !strings.Contains(stderr.String(), "--fields requires") {
+		t.Errorf("expected format error, got %s", stderr.String())
+	}
+}
+
+// TestRun_StatsDiff tests the growth report between two archives
+func TestRun_StatsDiff(t *testing.T) {
+	old := testutil.SetupTestDBFile(t)
+	defer old.Close()
+	grown := testutil.SetupTestDBFile(t)
+	defer grown.Close()
+
+	_, err := grown.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (6, 0, 'Lunatic', 0)`)
+	if err != nil {
+		t.Fatalf("failed to insert page: %v", err)
+	}
+	_, err = grown.DB.Exec(`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+		VALUES (200, 6, NULL, '2020-02-01 00:00:00', 'Newbie', 9, 'New page', 'Lunatic is a rabbit.', 20, 'x', 0)`)
+	if err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+
+	// Test: Text report shows growth
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"stats", "diff", old.Path, grown.Path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	report := strings.Join(strings.Fields(stdout.String()), " ")
+	for _, want := range []string{"Pages 5 → 6 +1", "Editors 3 → 4 +1", "2020-02 +1", "New top editors: Newbie"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	// Test: JSON report follows the schema
+	stdout.Reset()
+	if code := run([]string{"stats", "diff", "--format", "json", old.Path, grown.Path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"revisions": {`) || !strings.Contains(stdout.String(), `"added": 1`) {
+		t.Errorf("unexpected JSON: %s", stdout.String())
+	}
+
+	// Test: Two archives are required
+	if code := run([]string{"stats", "diff", old.Path}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
+
+// TestRun_StatsDigest tests the monthly digest of an archive
+func TestRun_StatsDigest(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Test: Markdown digest of the fixture's month
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"stats", "digest", "--db", tdb.Path, "--month", "2020-01"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	for _, want := range []string{"# January 2020 digest", "7 edits by 3 editors", "## Top editors", "| Admin | 4 | 4 |"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected digest to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	// Test: HTML digest
+	stdout.Reset()
+	if code := run([]string{"stats", "digest", "--db", tdb.Path, "--month", "2020-01", "--format", "html", "--limit", "1"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "<h2>New pages</h2>") || strings.Count(stdout.String(), "<li>") != 3 {
+		t.Errorf("unexpected HTML: %s", stdout.String())
+	}
+
+	// Test: Invalid month
+	if code := run([]string{"stats", "digest", "--db", tdb.Path, "--month", "May"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
+
+// TestRun_Schema tests the schema command and --schema flags
+func TestRun_Schema(t *testing.T) {
+	// Test: Without a type, the available types are listed
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"schema"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	for _, name := range []string{"Page", "Revision", "SearchResult", "Statistics", "PageOutput"} {
+		if !strings.Contains(stdout.String(), name+"\n") {
+			t.Errorf("expected %s in %q", name, stdout.String())
+		}
+	}
+
+	// Test: Type names are matched ignoring case
+	var schema struct {
+		Schema     string                    `json:"$schema"`
+		Title      string                    `json:"title"`
+		Type       any                       `json:"type"`
+		Properties map[string]map[string]any `json:"properties"`
+		Required   []string                  `json:"required"`
+		Items      struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"items"`
+	}
+	stdout.Reset()
+	if code := run([]string{"schema", "searchresult"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &schema); err != nil {
+		t.Fatalf("invalid schema JSON: %v", err)
+	}
+	if schema.Title != "SearchResult" || schema.Properties["PageID"]["type"] != "integer" {
+		t.Errorf("unexpected schema: %s", stdout.String())
+	}
+
+	// Test: --schema prints a command's output schema without an archive
+	stdout.Reset()
+	if code := run([]string{"history", "--schema"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	schema.Title = ""
+	if err := json.Unmarshal(stdout.Bytes(), &schema); err != nil {
+		t.Fatalf("invalid schema JSON: %v", err)
+	}
+	if schema.Title != "HistoryOutput" || schema.Properties["revisions"] == nil {
+		t.Errorf("unexpected schema: %s", stdout.String())
+	}
+
+	// Test: Export schemas list the selected columns as strings
+	stdout.Reset()
+	code := run([]string{"export", "--schema", "--table", "revisions", "--columns", "revision_id,title"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &schema); err != nil {
+		t.Fatalf("invalid schema JSON: %v", err)
+	}
+	if schema.Type != "array" || len(schema.Items.Properties) != 2 || schema.Items.Properties["title"]["type"] != "string" {
+		t.Errorf("unexpected schema: %s", stdout.String())
+	}
+
+	// Test: Unknown types are a usage error
+	if code := run([]string{"schema", "Nope"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
+
+// TestRun_FetchArchive tests downloading, resuming and verifying an archive from a mirror
+func TestRun_FetchArchive(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	srv, err := server.New(client, server.Options{ArchivePath: tdb.Path})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	defer srv.Close()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	want, err := os.ReadFile(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	output := filepath.Join(t.TempDir(), "mirror.db")
+
+	fetch := func() (int, FetchArchiveOutput, string) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"fetch-archive", "--format", "json", "--output", output, ts.URL + "/archive"}, &stdout, &stderr)
+		var out FetchArchiveOutput
+		if code == 0 {
+			if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+		}
+		return code, out, stderr.String()
+	}
+
+	// Test: A fresh download is verified against the mirror's checksum
+	code, out, stderr := fetch()
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	if got, _ := os.ReadFile(output); !bytes.Equal(got, want) {
+		t.Error("downloaded archive differs from the original")
+	}
+	if !out.Verified || out.Resumed != 0 || out.Bytes != int64(len(want)) {
+		t.Errorf("unexpected output: %+v", out)
+	}
+
+	// Test: A partial download of the same file is resumed
+	resp, err := http.Head(ts.URL + "/archive")
+	if err != nil {
+		t.Fatalf("HEAD failed: %v", err)
+	}
+	resp.Body.Close()
+	os.WriteFile(output+".part", want[:1000], 0o644)
+	os.WriteFile(output+".part.etag", []byte(resp.Header.Get("ETag")), 0o644)
+
+	code, out, stderr = fetch()
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	if got, _ := os.ReadFile(output); !bytes.Equal(got, want) || out.Resumed != 1000 {
+		t.Errorf("expected a resumed download, got %+v", out)
+	}
+	if _, err := os.Stat(output + ".part"); !os.IsNotExist(err) {
+		t.Error("expected the partial download to be removed")
+	}
+
+	// Test: A partial download of an older version starts over
+	os.WriteFile(output+".part", []byte("stale"), 0o644)
+	os.WriteFile(output+".part.etag", []byte(`"old"`), 0o644)
+	if code, out, stderr = fetch(); code != 0 || out.Resumed != 0 {
+		t.Errorf("expected a restarted download, got %d %+v: %s", code, out, stderr)
+	}
+
+	// Test: A corrupt partial download fails verification
+	corrupt := bytes.Repeat([]byte{0}, 1000)
+	os.WriteFile(output+".part", corrupt, 0o644)
+	os.WriteFile(output+".part.etag", []byte(resp.Header.Get("ETag")), 0o644)
+	if code, _, stderr = fetch(); code != 1 || !strings.Contains(stderr, "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %d: %s", code, stderr)
+	}
+}
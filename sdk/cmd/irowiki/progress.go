@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// progressBarWidth is the number of cells in a progress bar.
+const progressBarWidth = 30
+
+// progressBar renders irowiki.Progress reports as a bar redrawn in place,
+// for terminals.
+type progressBar struct {
+	w     io.Writer
+	drawn bool
+}
+
+// OnProgress redraws the bar.
+func (p *progressBar) OnProgress(done, total int64, stage string) {
+	p.drawn = true
+	if total < 0 {
+		fmt.Fprintf(p.w, "\r%s: %d", stage, done)
+		return
+	}
+
+	filled := progressBarWidth
+	percent := 100.0
+	if total > 0 {
+		filled = int(done * progressBarWidth / total)
+		percent = float64(done) * 100 / float64(total)
+	}
+	fmt.Fprintf(p.w, "\r%s [%s%s] %d/%d (%.0f%%)",
+		stage, strings.Repeat("#", filled), strings.Repeat(" ", progressBarWidth-filled), done, total, percent)
+}
+
+// Finish ends the bar's line, if one was drawn.
+func (p *progressBar) Finish() {
+	if p.drawn {
+		fmt.Fprintln(p.w)
+	}
+}
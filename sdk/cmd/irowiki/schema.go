@@ -0,0 +1,182 @@
+package main
+
+import (
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// The types below are the --format json and --format yaml schemas. Field
+// names are part of the CLI's interface: add fields freely, but don't rename
+// or remove them. Timestamps are RFC 3339 in UTC.
+
+// PageOutput is the schema of "irowiki page" and of each page of "irowiki get".
+type PageOutput struct {
+	Requested  string    `json:"requested,omitempty" yaml:"requested,omitempty"`
+	ID         int64     `json:"id" yaml:"id"`
+	Namespace  int       `json:"namespace" yaml:"namespace"`
+	Title      string    `json:"title" yaml:"title"`
+	IsRedirect bool      `json:"is_redirect" yaml:"is_redirect"`
+	Deleted    bool      `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+	RevisionID int64     `json:"revision_id" yaml:"revision_id"`
+	Latest     bool      `json:"latest" yaml:"latest"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	User       string    `json:"user" yaml:"user"`
+	Comment    string    `json:"comment" yaml:"comment"`
+	Content    string    `json:"content" yaml:"content"`
+	SourceURL  string    `json:"source_url,omitempty" yaml:"source_url,omitempty"`
+
+	IsStub           bool               `json:"is_stub,omitempty" yaml:"is_stub,omitempty"`
+	IsDisambiguation bool               `json:"is_disambiguation,omitempty" yaml:"is_disambiguation,omitempty"`
+	Protection       []ProtectionOutput `json:"protection,omitempty" yaml:"protection,omitempty"`
+}
+
+// ProtectionOutput is a protected action of a page. Expiry is omitted for
+// protections that don't expire.
+type ProtectionOutput struct {
+	Action string    `json:"action" yaml:"action"`
+	Level  string    `json:"level" yaml:"level"`
+	Expiry time.Time `json:"expiry,omitzero" yaml:"expiry,omitempty"`
+}
+
+// GetOutput is the schema of "irowiki get". Pages follow the order titles
+// were given in; Missing lists the titles that matched no page.
+type GetOutput struct {
+	Pages   []PageOutput `json:"pages" yaml:"pages"`
+	Missing []string     `json:"missing" yaml:"missing"`
+}
+
+// SearchOutput is the schema of "irowiki search".
+type SearchOutput struct {
+	Query      string               `json:"query" yaml:"query"`
+	Offset     int                  `json:"offset" yaml:"offset"`
+	Results    []SearchResultOutput `json:"results" yaml:"results"`
+	Suggestion string               `json:"suggestion,omitempty" yaml:"suggestion,omitempty"`
+}
+
+// SearchResultOutput is one search hit. Snippet marks matches with <mark> tags.
+type SearchResultOutput struct {
+	PageID    int64     `json:"page_id" yaml:"page_id"`
+	Namespace int       `json:"namespace" yaml:"namespace"`
+	Title     string    `json:"title" yaml:"title"`
+	Snippet   string    `json:"snippet,omitempty" yaml:"snippet,omitempty"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	Relevance float64   `json:"relevance" yaml:"relevance"`
+}
+
+// HistoryOutput is the schema of "irowiki history".
+type HistoryOutput struct {
+	Title     string           `json:"title" yaml:"title"`
+	Offset    int              `json:"offset" yaml:"offset"`
+	Revisions []RevisionOutput `json:"revisions" yaml:"revisions"`
+}
+
+// RevisionOutput is one revision in a page history, newest first.
+type RevisionOutput struct {
+	ID        int64     `json:"id" yaml:"id"`
+	ParentID  *int64    `json:"parent_id" yaml:"parent_id"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	User      string    `json:"user" yaml:"user"`
+	Comment   string    `json:"comment" yaml:"comment"`
+	Size      int       `json:"size" yaml:"size"`
+	Minor     bool      `json:"minor" yaml:"minor"`
+}
+
+// ServeOutput is the schema of the startup line of "irowiki serve".
+type ServeOutput struct {
+	URL string `json:"url" yaml:"url"`
+	DB  string `json:"db" yaml:"db"`
+}
+
+// FetchArchiveOutput is the schema of "irowiki fetch-archive" output.
+type FetchArchiveOutput struct {
+	Path     string `json:"path" yaml:"path"`
+	URL      string `json:"url" yaml:"url"`
+	Bytes    int64  `json:"bytes" yaml:"bytes"`
+	Resumed  int64  `json:"resumed" yaml:"resumed"`
+	SHA256   string `json:"sha256" yaml:"sha256"`
+	Verified bool   `json:"verified" yaml:"verified"`
+}
+
+// newPageOutput converts the latest version of a page to its output schema.
+func newPageOutput(page *irowiki.Page) PageOutput {
+	var protection []ProtectionOutput
+	for _, p := range page.Protection {
+		protection = append(protection, ProtectionOutput{Action: p.Action, Level: p.Level, Expiry: p.Expiry.UTC()})
+	}
+
+	return PageOutput{
+		ID:         page.ID,
+		Namespace:  page.Namespace,
+		Title:      page.Title,
+		IsRedirect: page.IsRedirect,
+		Deleted:    page.Deleted,
+		RevisionID: page.LatestRevisionID,
+		Latest:     true,
+		Timestamp:  page.Timestamp.UTC(),
+		User:       page.User,
+		Comment:    page.Comment,
+		Content:    page.Content,
+
+		IsStub:           page.IsStub,
+		IsDisambiguation: page.IsDisambiguation,
+		Protection:       protection,
+	}
+}
+
+// newSearchOutput converts search results to their output schema.
+func newSearchOutput(query string, offset int, results []irowiki.SearchResult) SearchOutput {
+	out := SearchOutput{Query: query, Offset: offset, Results: []SearchResultOutput{}}
+	for _, r := range results {
+		out.Results = append(out.Results, SearchResultOutput{
+			PageID:    r.PageID,
+			Namespace: r.Namespace,
+			Title:     r.Title,
+			Snippet:   r.Snippet,
+			Timestamp: r.Timestamp.UTC(),
+			Relevance: r.Relevance,
+		})
+	}
+	return out
+}
+
+// newHistoryOutput converts revisions to their output schema.
+func newHistoryOutput(title string, offset int, revisions []irowiki.Revision) HistoryOutput {
+	out := HistoryOutput{Title: title, Offset: offset, Revisions: []RevisionOutput{}}
+	for _, rev := range revisions {
+		out.Revisions = append(out.Revisions, RevisionOutput{
+			ID:        rev.ID,
+			ParentID:  rev.ParentID,
+			Timestamp: rev.Timestamp.UTC(),
+			User:      rev.User,
+			Comment:   rev.Comment,
+			Size:      rev.Size,
+			Minor:     rev.Minor,
+		})
+	}
+	return out
+}
+
+// StatsDiffOutput is the schema of "irowiki stats diff". Namespace and
+// month maps only list entries that changed.
+type StatsDiffOutput struct {
+	Old              string           `json:"old" yaml:"old"`
+	New              string           `json:"new" yaml:"new"`
+	OldLastEdit      time.Time        `json:"old_last_edit" yaml:"old_last_edit"`
+	NewLastEdit      time.Time        `json:"new_last_edit" yaml:"new_last_edit"`
+	Pages            CountChange      `json:"pages" yaml:"pages"`
+	Revisions        CountChange      `json:"revisions" yaml:"revisions"`
+	Files            CountChange      `json:"files" yaml:"files"`
+	Editors          CountChange      `json:"editors" yaml:"editors"`
+	ContentSize      CountChange      `json:"content_size" yaml:"content_size"`
+	PagesByNamespace map[int]int64    `json:"namespace_changes" yaml:"namespace_changes"`
+	EditsByMonth     map[string]int64 `json:"edits_by_month" yaml:"edits_by_month"`
+	NewTopEditors    []string         `json:"new_top_editors" yaml:"new_top_editors"`
+}
+
+// CountChange is a total in the old and new archives and the difference.
+type CountChange struct {
+	Old   int64 `json:"old" yaml:"old"`
+	New   int64 `json:"new" yaml:"new"`
+	Added int64 `json:"added" yaml:"added"`
+}
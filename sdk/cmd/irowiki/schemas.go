@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/jsonschema"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/report"
+)
+
+// schemaTypes are the types "irowiki schema" prints, keyed by Go type name:
+// the SDK's JSON-encoded types and the CLI's --format json outputs.
+var schemaTypes = map[string]any{
+	"Page":               irowiki.Page{},
+	"Revision":           irowiki.Revision{},
+	"File":               irowiki.File{},
+	"SearchResult":       irowiki.SearchResult{},
+	"Statistics":         irowiki.Statistics{},
+	"StatisticsEnhanced": irowiki.StatisticsEnhanced{},
+	"StatisticsDelta":    irowiki.StatisticsDelta{},
+	"PageHTML":           irowiki.PageHTML{},
+	"ModuleDependency":   irowiki.ModuleDependency{},
+	"PageOutput":         PageOutput{},
+	"GetOutput":          GetOutput{},
+	"SearchOutput":       SearchOutput{},
+	"HistoryOutput":      HistoryOutput{},
+	"StatsDiffOutput":    StatsDiffOutput{},
+	"ServeOutput":        ServeOutput{},
+	"FetchArchiveOutput": FetchArchiveOutput{},
+	"Digest":             report.Digest{},
+}
+
+// schemaNames returns the names of schemaTypes, sorted.
+func schemaNames() []string {
+	names := make([]string, 0, len(schemaTypes))
+	for name := range schemaTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addSchemaFlag registers --schema on fs.
+func addSchemaFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("schema", false, "print the JSON Schema of the json and yaml output and exit")
+}
+
+// writeSchema prints a schema as indented JSON.
+func writeSchema(stdout, stderr io.Writer, schema *jsonschema.Schema) int {
+	if err := encode(stdout, formatJSON, schema); err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// exportSchema returns the schema of "irowiki export --format json" rows:
+// an array of objects whose values are all strings.
+func exportSchema(table string, columns []string) *jsonschema.Schema {
+	if len(columns) == 0 {
+		columns = irowiki.ExportColumns(table)
+	}
+	row := &jsonschema.Schema{Type: "object", Properties: make(map[string]*jsonschema.Schema), Required: columns}
+	for _, col := range columns {
+		row.Properties[col] = &jsonschema.Schema{Type: "string"}
+	}
+	return &jsonschema.Schema{
+		Schema: jsonschema.Draft,
+		Title:  "Export " + table,
+		Type:   "array",
+		Items:  row,
+	}
+}
+
+// runSchema implements "irowiki schema".
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: irowiki schema [type]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Prints the JSON Schema (draft 2020-12) of an SDK type or CLI output, for")
+		fmt.Fprintln(stderr, "validating or generating code against the JSON the SDK and CLI write.")
+		fmt.Fprintln(stderr, "Without a type, lists the available types.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Example: irowiki schema SearchOutput > search-output.schema.json")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	switch fs.NArg() {
+	case 0:
+		for _, name := range schemaNames() {
+			fmt.Fprintln(stdout, name)
+		}
+		return 0
+	case 1:
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	for name, v := range schemaTypes {
+		if strings.EqualFold(name, fs.Arg(0)) {
+			return writeSchema(stdout, stderr, jsonschema.For(v))
+		}
+	}
+	fmt.Fprintf(stderr, "irowiki: unknown type %q (want %s)\n", fs.Arg(0), strings.Join(schemaNames(), ", "))
+	return 2
+}
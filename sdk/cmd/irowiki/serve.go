@@ -0,0 +1,149 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/jsonschema"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/server"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/wayback"
+)
+
+// runServe implements "irowiki serve".
+func runServe(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	dbPath := fs.String("db", defaults.DB, "path to the SQLite archive (required unless set in the config file)")
+	addr := fs.String("addr", defaults.Server.Addr, "address to listen on")
+	title := fs.String("title", defaults.Server.Title, "site title (default: the archive's wiki name)")
+	download := fs.Bool("download", defaults.Server.Download, "serve the archive file at /archive for mirrors (see fetch-archive)")
+	slowQuery := fs.Duration("slow-query", defaults.Server.SlowQuery, "log queries taking at least this long, with their request IDs (0 disables)")
+	adminToken := fs.String("admin-token", "", "bearer token enabling the /jobs maintenance endpoints (default: server.admin_token or $IROWIKI_ADMIN_TOKEN)")
+	tokenizer := fs.String("fts-tokenizer", defaults.Server.FTSTokenizer, "tokenizer of the reindex job: porter, unicode61 or trigram")
+	vectorCmd := fs.String("vector-reindex-cmd", defaults.Server.VectorReindexCmd, `command run by the vector-reindex job, e.g. "python3 scripts/vectorize_wiki.py --db irowiki.db"`)
+	format := addFormatFlag(fs, formatText, formatJSON, formatYAML)
+	schema := addSchemaFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: irowiki serve --db <archive> [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Serves a read-only website for browsing the archive: search,")
+		fmt.Fprintln(stderr, "pages, history and diffs. With --format json or yaml the startup line")
+		fmt.Fprintln(stderr, `is {"url": ..., "db": ...} for scripts waiting on the server.`)
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "With --admin-token, admins can run maintenance jobs (reindex, link-check,")
+		fmt.Fprintln(stderr, "refresh-stats, vector-reindex) through the /jobs endpoints, e.g.")
+		fmt.Fprintln(stderr, `  curl -H "Authorization: Bearer $TOKEN" -d '{"name": "reindex"}' http://localhost:8080/jobs`)
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *schema {
+		return writeSchema(stdout, stderr, jsonschema.For(ServeOutput{}))
+	}
+	if *dbPath == "" {
+		fmt.Fprintln(stderr, "irowiki: --db is required")
+		fs.Usage()
+		return 2
+	}
+	// The token isn't the flag's default, so -h doesn't print it
+	*adminToken = cmp.Or(*adminToken, defaults.Server.AdminToken)
+
+	client, err := irowiki.OpenSQLite(*dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	logger := log.New(stderr, "", log.LstdFlags)
+	if *slowQuery > 0 {
+		client = irowiki.WithInterceptor(client, irowiki.LoggingInterceptor(irowiki.LogOptions{
+			Logger:        logger,
+			SlowThreshold: *slowQuery,
+		}))
+	}
+	opts := server.Options{Title: *title, Logger: logger, AdminToken: *adminToken}
+	if *download {
+		opts.ArchivePath = *dbPath
+	}
+	if *adminToken != "" {
+		ftsOpts := irowiki.FTSOptions{Tokenizer: *tokenizer}
+		if err := ftsOpts.Validate(); err != nil {
+			fmt.Fprintf(stderr, "irowiki: --fts-tokenizer: %v\n", err)
+			return 2
+		}
+		store, err := irowiki.OpenSQLiteStoreWithOptions(*dbPath, irowiki.ConnectionOptions{WriterName: "irowiki serve"})
+		if err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+		defer store.Close()
+
+		opts.Jobs = map[string]server.JobFunc{
+			"reindex":    server.ReindexJob(store, ftsOpts),
+			"link-check": server.LinkCheckJob(store, wayback.NewClient(wayback.Options{}), irowiki.ExternalLinkOptions{}),
+		}
+		if args := strings.Fields(*vectorCmd); len(args) > 0 {
+			opts.Jobs["vector-reindex"] = server.CommandJob(args[0], args[1:]...)
+		}
+	}
+	handler, err := server.New(client, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	defer handler.Close()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          logger,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// End change streams on interrupt so Shutdown needn't wait for them
+	srv.BaseContext = func(net.Listener) context.Context { return ctx }
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	url := "http://" + *addr + "/"
+	if format.value == formatText {
+		fmt.Fprintf(stdout, "Serving %s at %s\n", *dbPath, url)
+	} else if err := encode(stdout, format.value, ServeOutput{URL: url, DB: *dbPath}); err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	return 0
+}
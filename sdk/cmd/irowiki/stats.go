@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/jsonschema"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/report"
+)
+
+// runStats dispatches stats subcommands.
+func runStats(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "diff":
+			return runStatsDiff(args[1:], stdout, stderr)
+		case "digest":
+			return runStatsDigest(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintln(stderr, "Usage: irowiki stats diff [flags] <old-archive> <new-archive>")
+	fmt.Fprintln(stderr, "       irowiki stats digest --db <archive> [flags]")
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		return 0
+	}
+	return 2
+}
+
+// runStatsDiff implements "irowiki stats diff".
+func runStatsDiff(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stats diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := addFormatFlag(fs, formatText, formatJSON, formatYAML)
+	schema := addSchemaFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: irowiki stats diff [flags] <old-archive> <new-archive>")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Reports how an archive grew between two snapshots: pages, revisions,")
+		fmt.Fprintln(stderr, "files and editors added, edits per month and new top editors.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *schema {
+		return writeSchema(stdout, stderr, jsonschema.For(StatsDiffOutput{}))
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(stderr, "irowiki: two archives are required")
+		fs.Usage()
+		return 2
+	}
+
+	ctx := context.Background()
+	var snapshots [2]*irowiki.StatisticsEnhanced
+	for i, path := range fs.Args() {
+		stats, err := archiveStatistics(ctx, path)
+		if err != nil {
+			fmt.Fprintf(stderr, "irowiki: %s: %v\n", path, err)
+			return 1
+		}
+		snapshots[i] = stats
+	}
+
+	before, after := snapshots[0], snapshots[1]
+	delta := irowiki.CompareStatistics(before, after)
+	out := StatsDiffOutput{
+		Old:              fs.Arg(0),
+		New:              fs.Arg(1),
+		OldLastEdit:      delta.OldLastEdit.UTC(),
+		NewLastEdit:      delta.NewLastEdit.UTC(),
+		Pages:            CountChange{before.TotalPages, after.TotalPages, delta.PagesAdded},
+		Revisions:        CountChange{before.TotalRevisions, after.TotalRevisions, delta.RevisionsAdded},
+		Files:            CountChange{before.TotalFiles, after.TotalFiles, delta.FilesAdded},
+		Editors:          CountChange{before.TotalEditors, after.TotalEditors, delta.EditorsAdded},
+		ContentSize:      CountChange{before.TotalContentSize, after.TotalContentSize, delta.ContentSizeChange},
+		PagesByNamespace: delta.PagesByNamespace,
+		EditsByMonth:     delta.EditsByMonth,
+		NewTopEditors:    delta.NewTopEditors,
+	}
+
+	if format.value != formatText {
+		if err := encode(stdout, format.value, out); err != nil {
+			fmt.Fprintf(stderr, "irowiki: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	writeStatsDiff(stdout, out)
+	return 0
+}
+
+// archiveStatistics opens an archive and reads its statistics.
+func archiveStatistics(ctx context.Context, path string) (*irowiki.StatisticsEnhanced, error) {
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return client.GetStatisticsEnhanced(ctx)
+}
+
+// writeStatsDiff prints a growth report.
+func writeStatsDiff(w io.Writer, out StatsDiffOutput) {
+	fmt.Fprintf(w, "Archive growth: %s → %s\n", out.Old, out.New)
+	if !out.OldLastEdit.IsZero() && !out.NewLastEdit.IsZero() {
+		fmt.Fprintf(w, "Last edit: %s → %s\n", out.OldLastEdit.Format("2006-01-02"), out.NewLastEdit.Format("2006-01-02"))
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, row := range []struct {
+		name  string
+		count CountChange
+	}{
+		{"Pages", out.Pages},
+		{"Revisions", out.Revisions},
+		{"Files", out.Files},
+		{"Editors", out.Editors},
+		{"Content bytes", out.ContentSize},
+	} {
+		fmt.Fprintf(tw, "%s\t%d\t→\t%d\t%+d\t\n", row.name, row.count.Old, row.count.New, row.count.Added)
+	}
+	tw.Flush()
+
+	if len(out.PagesByNamespace) > 0 {
+		fmt.Fprintln(w, "\nPages by namespace:")
+		namespaces := make([]int, 0, len(out.PagesByNamespace))
+		for ns := range out.PagesByNamespace {
+			namespaces = append(namespaces, ns)
+		}
+		slices.Sort(namespaces)
+		for _, ns := range namespaces {
+			fmt.Fprintf(w, "  %-8d %+d\n", ns, out.PagesByNamespace[ns])
+		}
+	}
+
+	if len(out.EditsByMonth) > 0 {
+		fmt.Fprintln(w, "\nEdits by month:")
+		months := make([]string, 0, len(out.EditsByMonth))
+		for month := range out.EditsByMonth {
+			months = append(months, month)
+		}
+		slices.Sort(months)
+		for _, month := range months {
+			fmt.Fprintf(w, "  %-8s %+d\n", month, out.EditsByMonth[month])
+		}
+	}
+
+	if len(out.NewTopEditors) > 0 {
+		fmt.Fprintf(w, "\nNew top editors: %s\n", strings.Join(out.NewTopEditors, ", "))
+	}
+}
+
+// runStatsDigest implements "irowiki stats digest".
+func runStatsDigest(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stats digest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dbPath := fs.String("db", defaults.DB, "path to the SQLite archive (required unless set in the config file)")
+	month := fs.String("month", "", "month to report, as YYYY-MM (default: last month)")
+	limit := fs.Int("limit", 10, "number of entries in each section")
+	format := addFormatFlag(fs, formatMarkdown, formatHTML, formatJSON, formatYAML)
+	schema := addSchemaFlag(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: irowiki stats digest --db <archive> [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Prints a month's \"state of the archive\" digest for community posts: new")
+		fmt.Fprintln(stderr, "pages, biggest edits, top editors and trending pages.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *schema {
+		return writeSchema(stdout, stderr, jsonschema.For(report.Digest{}))
+	}
+	if *dbPath == "" || fs.NArg() != 0 {
+		fmt.Fprintln(stderr, "irowiki: --db is required")
+		fs.Usage()
+		return 2
+	}
+
+	start := time.Now().UTC().AddDate(0, -1, 0)
+	if *month != "" {
+		var err error
+		if start, err = time.Parse("2006-01", *month); err != nil {
+			fmt.Fprintf(stderr, "irowiki: invalid --month %q (want YYYY-MM)\n", *month)
+			return 2
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(*dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	digest, err := report.MonthlyDigest(context.Background(), client, start, report.Options{Limit: *limit})
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+
+	switch format.value {
+	case formatMarkdown:
+		err = report.WriteMarkdown(stdout, digest)
+	case formatHTML:
+		err = report.WriteHTML(stdout, digest)
+	default:
+		err = encode(stdout, format.value, digest)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "irowiki: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,435 @@
+// Package config loads the configuration shared by the iRO Wiki tools: the
+// irowiki CLI and its server, irowiki-tui, and the scraper and sync jobs.
+//
+// Settings are layered. Defaults come first, then the YAML file, then
+// IROWIKI_* environment variables; binaries use the result as their flag
+// defaults, so flags always win. The file is read from $IROWIKI_CONFIG if
+// set, otherwise from $XDG_CONFIG_HOME/irowiki/config.yaml or
+// ~/.config/irowiki/config.yaml:
+//
+//	db: ~/archives/irowiki.db
+//	format: json
+//	server:
+//	  addr: localhost:8080
+//	  admin_token: s3cret
+//	wiki:
+//	  base_url: https://irowiki.org
+//	scraper:
+//	  rate_limit: 2
+//	sync:
+//	  interval: 1h
+//
+// The wiki, scraper, storage and logging sections are those of the Python
+// scraper's config.yaml, so one file configures every tool. Secrets, such as
+// the admin token and database passwords, are hidden by Redacted.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// EnvVar names the environment variable that overrides the config file path.
+const EnvVar = "IROWIKI_CONFIG"
+
+// redacted replaces secrets in Redacted configs.
+const redacted = "REDACTED"
+
+// Formats are the values accepted for Config.Format.
+var Formats = []string{"text", "json", "yaml", "csv", "wikitext", "markdown", "html"}
+
+// logLevels are the values accepted for LoggingConfig.Level.
+var logLevels = []string{"DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}
+
+// Config holds the settings of every tool. Fields tagged env can be
+// overridden by that environment variable; fields tagged secret are hidden
+// by Redacted.
+type Config struct {
+	// DB is the archive location, as accepted by irowiki.Open; the irowiki
+	// CLI expects a SQLite path. A leading "~/" is expanded; when empty, it
+	// defaults to storage.database_file.
+	DB string `yaml:"db,omitempty" env:"IROWIKI_DB" secret:"dsn"`
+
+	// Format is the default --format of the CLI. Commands that don't
+	// support it use their own default.
+	Format string `yaml:"format,omitempty" env:"IROWIKI_FORMAT"`
+
+	Server  ServerConfig  `yaml:"server"`
+	Wiki    WikiConfig    `yaml:"wiki"`
+	Scraper ScraperConfig `yaml:"scraper"`
+	Storage StorageConfig `yaml:"storage"`
+	Logging LoggingConfig `yaml:"logging"`
+	Sync    SyncConfig    `yaml:"sync"`
+}
+
+// ServerConfig configures "irowiki serve". In the file, a plain string is
+// taken as the address ("server: localhost:9000").
+type ServerConfig struct {
+	// Addr is the address to listen on. An http:// URL is accepted and
+	// reduced to its host and port.
+	Addr string `yaml:"addr" env:"IROWIKI_SERVER_ADDR"`
+
+	// Title is the site title (default: the archive's wiki name).
+	Title string `yaml:"title,omitempty" env:"IROWIKI_SERVER_TITLE"`
+
+	// AdminToken enables the /jobs maintenance endpoints.
+	AdminToken string `yaml:"admin_token,omitempty" env:"IROWIKI_ADMIN_TOKEN" secret:"true"`
+
+	// Download serves the archive file at /archive for mirrors.
+	Download bool `yaml:"download,omitempty" env:"IROWIKI_SERVER_DOWNLOAD"`
+
+	// SlowQuery logs queries taking at least this long (0 disables).
+	SlowQuery time.Duration `yaml:"slow_query,omitempty" env:"IROWIKI_SERVER_SLOW_QUERY"`
+
+	// FTSTokenizer is the tokenizer of the reindex job.
+	FTSTokenizer string `yaml:"fts_tokenizer" env:"IROWIKI_SERVER_FTS_TOKENIZER"`
+
+	// VectorReindexCmd is the command run by the vector-reindex job.
+	VectorReindexCmd string `yaml:"vector_reindex_cmd,omitempty" env:"IROWIKI_SERVER_VECTOR_REINDEX_CMD"`
+}
+
+// WikiConfig locates the live wiki.
+type WikiConfig struct {
+	BaseURL string `yaml:"base_url" env:"IROWIKI_WIKI_BASE_URL"`
+	APIPath string `yaml:"api_path" env:"IROWIKI_WIKI_API_PATH"`
+}
+
+// APIURL returns the URL of the wiki's api.php.
+func (w WikiConfig) APIURL() string {
+	return strings.TrimSuffix(w.BaseURL, "/") + "/" + strings.TrimPrefix(w.APIPath, "/")
+}
+
+// ScraperConfig controls requests to the live wiki.
+type ScraperConfig struct {
+	// RateLimit is the maximum number of requests per second.
+	RateLimit float64 `yaml:"rate_limit" env:"IROWIKI_SCRAPER_RATE_LIMIT"`
+
+	// Timeout is the request timeout in seconds.
+	Timeout int `yaml:"timeout" env:"IROWIKI_SCRAPER_TIMEOUT"`
+
+	MaxRetries int    `yaml:"max_retries" env:"IROWIKI_SCRAPER_MAX_RETRIES"`
+	UserAgent  string `yaml:"user_agent" env:"IROWIKI_SCRAPER_USER_AGENT"`
+}
+
+// StorageConfig holds the scraper's file locations.
+type StorageConfig struct {
+	DataDir        string `yaml:"data_dir,omitempty" env:"IROWIKI_STORAGE_DATA_DIR"`
+	CheckpointFile string `yaml:"checkpoint_file,omitempty" env:"IROWIKI_STORAGE_CHECKPOINT_FILE"`
+	DatabaseFile   string `yaml:"database_file,omitempty" env:"IROWIKI_STORAGE_DATABASE_FILE"`
+}
+
+// LoggingConfig controls the scraper's logging.
+type LoggingConfig struct {
+	Level     string `yaml:"level" env:"IROWIKI_LOG_LEVEL"`
+	LogFile   string `yaml:"log_file,omitempty" env:"IROWIKI_LOG_FILE"`
+	LogFormat string `yaml:"log_format,omitempty"`
+}
+
+// SyncConfig controls incremental syncs from the live wiki.
+type SyncConfig struct {
+	// Interval is the time between syncs of a sync daemon (0 syncs once).
+	Interval time.Duration `yaml:"interval,omitempty" env:"IROWIKI_SYNC_INTERVAL"`
+}
+
+// Default returns the settings used when neither the file nor the
+// environment sets them. They match the Python scraper's defaults.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:         "localhost:8080",
+			FTSTokenizer: irowiki.TokenizerPorter,
+		},
+		Wiki: WikiConfig{
+			BaseURL: "https://irowiki.org",
+			APIPath: "/w/api.php",
+		},
+		Scraper: ScraperConfig{
+			RateLimit:  1.0,
+			Timeout:    30,
+			MaxRetries: 3,
+			UserAgent:  "iROWikiArchiver/1.0 (github.com/irowiki/scraper; archiver@irowiki.org)",
+		},
+		Logging: LoggingConfig{Level: "INFO"},
+	}
+}
+
+// Path returns the config file location, which may not exist.
+func Path() (string, error) {
+	if path := os.Getenv(EnvVar); path != "" {
+		return path, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "irowiki", "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate config file: %w", err)
+	}
+	return filepath.Join(home, ".config", "irowiki", "config.yaml"), nil
+}
+
+// Load reads the config file and applies the environment.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ApplyEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFile reads the config file at path over the defaults, without the
+// environment or validation. A missing file yields Default(); unknown keys
+// are an error so typos don't go unnoticed.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	data, err = expandServerAddr(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	cfg.normalize()
+	return cfg, nil
+}
+
+// expandServerAddr rewrites "server: <addr>" as "server: {addr: <addr>}",
+// the form the file had before the server section.
+func expandServerAddr(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != "server" || value.Kind != yaml.ScalarNode {
+			continue
+		}
+		root.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "addr"},
+			value,
+		}}
+		return yaml.Marshal(&doc)
+	}
+	return data, nil
+}
+
+// ApplyEnv overrides settings with the environment variables named by
+// their env tags, looked up with getenv (usually os.Getenv). Empty
+// variables are ignored.
+func (c *Config) ApplyEnv(getenv func(string) string) error {
+	var errs []error
+	walk(reflect.ValueOf(c).Elem(), func(field reflect.StructField, v reflect.Value) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return
+		}
+		value := getenv(name)
+		if value == "" {
+			return
+		}
+		if err := setValue(v, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+	c.normalize()
+	return errors.Join(errs...)
+}
+
+// setValue parses s into v.
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// normalize cleans up values the way users tend to write them.
+func (c *Config) normalize() {
+	c.DB = expandHome(c.DB)
+	c.Storage.DatabaseFile = expandHome(c.Storage.DatabaseFile)
+	if c.DB == "" {
+		c.DB = c.Storage.DatabaseFile
+	}
+	c.Server.Addr = strings.TrimSuffix(strings.TrimPrefix(c.Server.Addr, "http://"), "/")
+	c.Format = strings.ToLower(strings.TrimSpace(c.Format))
+	c.Logging.Level = strings.ToUpper(strings.TrimSpace(c.Logging.Level))
+}
+
+// Validate reports every invalid setting.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Format != "" && !slices.Contains(Formats, c.Format) {
+		invalid("format", "unsupported format %q (want %s)", c.Format, strings.Join(Formats, ", "))
+	}
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		invalid("server.addr", "%v", err)
+	}
+	if c.Server.SlowQuery < 0 {
+		invalid("server.slow_query", "must not be negative, got %s", c.Server.SlowQuery)
+	}
+	fts := irowiki.FTSOptions{Tokenizer: c.Server.FTSTokenizer}
+	if err := fts.Validate(); err != nil {
+		invalid("server.fts_tokenizer", "%v", err)
+	}
+	if u, err := url.Parse(c.Wiki.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("wiki.base_url", "must be an http or https URL, got %q", c.Wiki.BaseURL)
+	}
+	if c.Scraper.RateLimit <= 0 {
+		invalid("scraper.rate_limit", "must be positive, got %g", c.Scraper.RateLimit)
+	}
+	if c.Scraper.Timeout <= 0 {
+		invalid("scraper.timeout", "must be positive, got %d", c.Scraper.Timeout)
+	}
+	if c.Scraper.MaxRetries < 0 {
+		invalid("scraper.max_retries", "must not be negative, got %d", c.Scraper.MaxRetries)
+	}
+	if c.Scraper.UserAgent == "" {
+		invalid("scraper.user_agent", "must not be empty")
+	}
+	if !slices.Contains(logLevels, c.Logging.Level) {
+		invalid("logging.level", "unsupported level %q (want %s)", c.Logging.Level, strings.Join(logLevels, ", "))
+	}
+	if c.Sync.Interval < 0 {
+		invalid("sync.interval", "must not be negative, got %s", c.Sync.Interval)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// dsnPassword matches the password of a key=value PostgreSQL DSN.
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// Redacted returns a copy of c with its secrets hidden, for printing and
+// logging.
+func (c *Config) Redacted() *Config {
+	out := *c
+	walk(reflect.ValueOf(&out).Elem(), func(field reflect.StructField, v reflect.Value) {
+		if v.String() == "" {
+			return
+		}
+		switch field.Tag.Get("secret") {
+		case "true":
+			v.SetString(redacted)
+		case "dsn":
+			v.SetString(redactDSN(v.String()))
+		}
+	})
+	return &out
+}
+
+// redactDSN hides the password of a database URL or DSN. Paths are left as
+// they are.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			return u.String()
+		}
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// walk calls fn for every setting below v, a struct, descending into the
+// sections.
+func walk(v reflect.Value, fn func(field reflect.StructField, v reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			walk(v.Field(i), fn)
+			continue
+		}
+		fn(field, v.Field(i))
+	}
+}
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
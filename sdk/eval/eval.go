@@ -0,0 +1,263 @@
+// Package eval measures retrieval quality, so changes to chunking, fusion
+// weights or search options can be compared with numbers.
+//
+// A Dataset lists queries with the pages relevant to them, graded by how
+// relevant they are. Evaluate runs a Searcher over every query and reports
+// recall@k, mean reciprocal rank and nDCG@k; Compare does so for several
+// search configurations at once:
+//
+//	dataset, err := eval.LoadDataset("queries.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	lexical := eval.Lexical(client, irowiki.SearchOptions{Namespace: -1})
+//	semantic := eval.SearcherFunc(func(ctx context.Context, query string, k int) ([]string, error) {
+//	    hits, err := vectorClient.Search(ctx, query, k)
+//	    // ... collect hit.PageTitle
+//	})
+//	reports, err := eval.Compare(ctx, dataset, 10, []eval.Config{
+//	    {Name: "lexical", Searcher: lexical},
+//	    {Name: "vector", Searcher: semantic},
+//	    {Name: "hybrid", Searcher: eval.Hybrid(
+//	        eval.Weighted{Searcher: lexical, Weight: 0.4},
+//	        eval.Weighted{Searcher: semantic, Weight: 0.6},
+//	    )},
+//	})
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// Searcher is a search configuration under evaluation. Search returns up to
+// k page titles for query, most relevant first.
+type Searcher interface {
+	Search(ctx context.Context, query string, k int) ([]string, error)
+}
+
+// SearcherFunc adapts a function to a Searcher.
+type SearcherFunc func(ctx context.Context, query string, k int) ([]string, error)
+
+// Search calls f.
+func (f SearcherFunc) Search(ctx context.Context, query string, k int) ([]string, error) {
+	return f(ctx, query, k)
+}
+
+// Query is a labeled query of a Dataset.
+type Query struct {
+	// Query is the search text.
+	Query string `json:"query"`
+
+	// Relevant grades the pages relevant to the query by title: 1 for
+	// relevant, higher for more relevant. Unlisted pages aren't relevant.
+	Relevant map[string]int `json:"relevant"`
+}
+
+// Dataset is a set of labeled queries.
+type Dataset []Query
+
+// LoadDataset loads a dataset from a JSON file:
+//
+//	[
+//	    {"query": "GX build", "relevant": {"Guillotine Cross": 2, "Cross Impact": 1}}
+//	]
+func LoadDataset(path string) (Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	var dataset Dataset
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		return nil, fmt.Errorf("failed to parse dataset: %w", err)
+	}
+	if err := dataset.Validate(); err != nil {
+		return nil, err
+	}
+	return dataset, nil
+}
+
+// Validate checks that every query has text and at least one relevant page
+// with a positive grade.
+func (d Dataset) Validate() error {
+	if len(d) == 0 {
+		return fmt.Errorf("%w: dataset has no queries", irowiki.ErrInvalidInput)
+	}
+	for i, q := range d {
+		if strings.TrimSpace(q.Query) == "" {
+			return fmt.Errorf("%w: query %d is empty", irowiki.ErrInvalidInput, i)
+		}
+		if len(q.Relevant) == 0 {
+			return fmt.Errorf("%w: query %q has no relevant pages", irowiki.ErrInvalidInput, q.Query)
+		}
+		for title, grade := range q.Relevant {
+			if grade <= 0 {
+				return fmt.Errorf("%w: query %q grades %q %d, grades must be positive", irowiki.ErrInvalidInput, q.Query, title, grade)
+			}
+		}
+	}
+	return nil
+}
+
+// Report is the result of evaluating a search configuration. Metrics are
+// averaged over the queries.
+type Report struct {
+	// Name is the configuration's name, from Config.
+	Name string `json:"name,omitempty"`
+
+	// K is the cutoff rank.
+	K int `json:"k"`
+
+	// Recall is the mean fraction of each query's relevant pages found in
+	// the top K.
+	Recall float64 `json:"recall"`
+
+	// MRR is the mean reciprocal rank of each query's first relevant page
+	// in the top K, 0 for queries without one.
+	MRR float64 `json:"mrr"`
+
+	// NDCG is the mean normalized discounted cumulative gain at K, using
+	// the relevance grades.
+	NDCG float64 `json:"ndcg"`
+
+	// Queries has the metrics of each query, in dataset order.
+	Queries []QueryResult `json:"queries"`
+}
+
+// QueryResult is the evaluation of one query.
+type QueryResult struct {
+	Query  string   `json:"query"`
+	Titles []string `json:"titles"`
+	Recall float64  `json:"recall"`
+	MRR    float64  `json:"mrr"`
+	NDCG   float64  `json:"ndcg"`
+}
+
+// Config names a search configuration for Compare.
+type Config struct {
+	Name     string
+	Searcher Searcher
+}
+
+// Evaluate runs searcher over the dataset and scores its top k results.
+// Titles are compared the way MediaWiki compares them, so "Main_Page"
+// matches "Main Page"; repeated titles, such as several chunks of one page
+// from a vector search, count once.
+func Evaluate(ctx context.Context, dataset Dataset, k int, searcher Searcher) (*Report, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("%w: k must be positive, got %d", irowiki.ErrInvalidInput, k)
+	}
+	if err := dataset.Validate(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{K: k, Queries: make([]QueryResult, len(dataset))}
+	for i, q := range dataset {
+		titles, err := searcher.Search(ctx, q.Query, k)
+		if err != nil {
+			return nil, fmt.Errorf("search for %q failed: %w", q.Query, err)
+		}
+		result := score(q, topTitles(titles, k), k)
+		report.Queries[i] = result
+		report.Recall += result.Recall
+		report.MRR += result.MRR
+		report.NDCG += result.NDCG
+	}
+	n := float64(len(dataset))
+	report.Recall /= n
+	report.MRR /= n
+	report.NDCG /= n
+	return report, nil
+}
+
+// Compare evaluates each configuration over the dataset, returning their
+// reports in the order given.
+func Compare(ctx context.Context, dataset Dataset, k int, configs []Config) ([]*Report, error) {
+	reports := make([]*Report, len(configs))
+	for i, c := range configs {
+		report, err := Evaluate(ctx, dataset, k, c.Searcher)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		report.Name = c.Name
+		reports[i] = report
+	}
+	return reports, nil
+}
+
+// topTitles normalizes titles and returns the first k distinct ones.
+func topTitles(titles []string, k int) []string {
+	top := make([]string, 0, k)
+	for _, title := range titles {
+		title = normalizeTitle(title)
+		if title != "" && !slices.Contains(top, title) {
+			top = append(top, title)
+			if len(top) == k {
+				break
+			}
+		}
+	}
+	return top
+}
+
+// score computes the metrics of one query's ranked titles.
+func score(q Query, titles []string, k int) QueryResult {
+	relevant := make(map[string]int, len(q.Relevant))
+	for title, grade := range q.Relevant {
+		relevant[normalizeTitle(title)] = grade
+	}
+
+	result := QueryResult{Query: q.Query, Titles: titles}
+	found := 0
+	var dcg float64
+	for i, title := range titles {
+		grade, ok := relevant[title]
+		if !ok {
+			continue
+		}
+		found++
+		if result.MRR == 0 {
+			result.MRR = 1 / float64(i+1)
+		}
+		dcg += gain(grade, i)
+	}
+	result.Recall = float64(found) / float64(len(relevant))
+
+	// The ideal ranking lists the relevant pages by grade
+	grades := make([]int, 0, len(relevant))
+	for _, grade := range relevant {
+		grades = append(grades, grade)
+	}
+	slices.SortFunc(grades, func(a, b int) int { return b - a })
+	var idcg float64
+	for i, grade := range grades[:min(k, len(grades))] {
+		idcg += gain(grade, i)
+	}
+	result.NDCG = dcg / idcg
+	return result
+}
+
+// gain is the discounted gain of a page of the grade at rank i (from 0).
+func gain(grade, i int) float64 {
+	return (math.Pow(2, float64(grade)) - 1) / math.Log2(float64(i+2))
+}
+
+// normalizeTitle applies MediaWiki title rules: underscores become spaces
+// and the first letter is upper-cased.
+func normalizeTitle(title string) string {
+	title = strings.TrimSpace(strings.ReplaceAll(title, "_", " "))
+	if title == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(title)
+	return string(unicode.ToUpper(r)) + title[size:]
+}
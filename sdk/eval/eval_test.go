@@ -0,0 +1,104 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/eval"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// fixed returns the same ranking for every query
+func fixed(titles ...string) eval.Searcher {
+	return eval.SearcherFunc(func(ctx context.Context, query string, k int) ([]string, error) {
+		return titles, nil
+	})
+}
+
+// TestEvaluate tests the metrics against hand-computed values
+func TestEvaluate(t *testing.T) {
+	dataset := eval.Dataset{
+		{Query: "capital", Relevant: map[string]int{"Prontera": 2, "Prontera Castle": 1}},
+	}
+	ctx := context.Background()
+
+	// Repeated chunks of a page count once, and titles are normalized
+	report, err := eval.Evaluate(ctx, dataset, 3, fixed("Poring", "Poring", "prontera_Castle", "Prontera"))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	ideal := 3/math.Log2(2) + 1/math.Log2(3)
+	ndcg := (1/math.Log2(3) + 3/math.Log2(4)) / ideal
+	if report.Recall != 1 || report.MRR != 0.5 || math.Abs(report.NDCG-ndcg) > 1e-9 {
+		t.Errorf("unexpected metrics: recall %v, mrr %v, ndcg %v (want %v)", report.Recall, report.MRR, report.NDCG, ndcg)
+	}
+	if got := report.Queries[0].Titles; len(got) != 3 || got[1] != "Prontera Castle" {
+		t.Errorf("unexpected titles: %v", got)
+	}
+
+	// Test: Results past k don't count
+	report, _ = eval.Evaluate(ctx, dataset, 1, fixed("Poring", "Prontera"))
+	if report.Recall != 0 || report.MRR != 0 || report.NDCG != 0 {
+		t.Errorf("expected zero metrics, got %+v", report)
+	}
+
+	// Test: Invalid datasets are rejected
+	if _, err := eval.Evaluate(ctx, eval.Dataset{{Query: "capital"}}, 3, fixed()); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// TestCompare tests lexical, vector-like and hybrid configurations over
+// the fixture archive
+func TestCompare(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	path := filepath.Join(t.TempDir(), "queries.json")
+	data := `[
+		{"query": "capital", "relevant": {"Prontera": 1}},
+		{"query": "slime", "relevant": {"Poring": 1}}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dataset, err := eval.LoadDataset(path)
+	if err != nil {
+		t.Fatalf("LoadDataset failed: %v", err)
+	}
+
+	lexical := eval.Lexical(client, irowiki.SearchOptions{Namespace: -1})
+	semantic := fixed("Main_Page", "Poring")
+	reports, err := eval.Compare(context.Background(), dataset, 2, []eval.Config{
+		{Name: "lexical", Searcher: lexical},
+		{Name: "vector", Searcher: semantic},
+		{Name: "hybrid", Searcher: eval.Hybrid(
+			eval.Weighted{Searcher: lexical, Weight: 1},
+			eval.Weighted{Searcher: semantic, Weight: 1},
+		)},
+	})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	want := map[string]float64{"lexical": 1, "vector": 0.5, "hybrid": 1}
+	for _, report := range reports {
+		if report.Recall != want[report.Name] {
+			t.Errorf("%s: expected recall %v, got %v (%+v)", report.Name, want[report.Name], report.Recall, report.Queries)
+		}
+	}
+	if reports[0].MRR != 1 || reports[1].MRR != 0.25 {
+		t.Errorf("unexpected MRR: lexical %v, vector %v", reports[0].MRR, reports[1].MRR)
+	}
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// rrfK is the rank constant of reciprocal rank fusion, which damps the
+// advantage of the very top ranks.
+const rrfK = 60
+
+// fusionDepth is the minimum number of results Hybrid fetches from each
+// searcher, so pages ranked just outside k by one can still be lifted by
+// another.
+const fusionDepth = 50
+
+// Lexical evaluates the archive's full-text search with opts. opts.Limit
+// is set to k.
+func Lexical(client irowiki.Client, opts irowiki.SearchOptions) Searcher {
+	return SearcherFunc(func(ctx context.Context, query string, k int) ([]string, error) {
+		opts := opts
+		opts.Limit = k
+		results, err := client.SearchFullText(ctx, query, opts)
+		if err != nil {
+			return nil, err
+		}
+		titles := make([]string, len(results))
+		for i, r := range results {
+			titles[i] = r.Title
+		}
+		return titles, nil
+	})
+}
+
+// Weighted is a searcher and its weight in Hybrid.
+type Weighted struct {
+	Searcher Searcher
+	Weight   float64
+}
+
+// Hybrid fuses the rankings of several searchers with weighted reciprocal
+// rank fusion: a page scores the sum of Weight / (60 + rank) over the
+// searchers that return it. Ties keep the order of the first searcher to
+// return the pages.
+func Hybrid(searchers ...Weighted) Searcher {
+	return SearcherFunc(func(ctx context.Context, query string, k int) ([]string, error) {
+		scores := make(map[string]float64)
+		var order []string
+		for _, s := range searchers {
+			titles, err := s.Searcher.Search(ctx, query, max(k, fusionDepth))
+			if err != nil {
+				return nil, err
+			}
+			for rank, title := range topTitles(titles, len(titles)) {
+				if _, ok := scores[title]; !ok {
+					order = append(order, title)
+				}
+				scores[title] += s.Weight / float64(rrfK+rank+1)
+			}
+		}
+
+		slices.SortStableFunc(order, func(a, b string) int {
+			return cmp.Compare(scores[b], scores[a])
+		})
+		return order[:min(k, len(order))], nil
+	})
+}
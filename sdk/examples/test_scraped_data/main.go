@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/config"
 	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
 )
 
@@ -14,8 +15,15 @@ func main() {
 	fmt.Println("=================================================================")
 	fmt.Println()
 
-	// Open the test database
-	client, err := irowiki.OpenSQLite("/home/mikekao/personal/iRO-Wiki-Scraper/test_data/test_scrape.db")
+	// Open the archive named by the config file or $IROWIKI_DB
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.DB == "" {
+		log.Fatal("No archive configured; set db in the config file (see \"irowiki config\") or IROWIKI_DB")
+	}
+	client, err := irowiki.Open(cfg.DB)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
@@ -2,18 +2,24 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/config"
 	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
 )
 
 func main() {
-	// This example requires an actual database file
-	// For demonstration, using a test database path
-	client, err := irowiki.OpenSQLite("../../testdata/test.db")
+	// This example requires an actual archive, named by the config file
+	// or $IROWIKI_DB
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	client, err := irowiki.Open(cmp.Or(cfg.DB, "../../testdata/test.db"))
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
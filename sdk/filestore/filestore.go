@@ -0,0 +1,84 @@
+// Package filestore reads the media files mirrored by the scraper. The
+// scraper lays them out by the first letter of their name:
+//
+//	files/File/A/Apple.png
+//	files/File/P/Poring_card.png
+//	files/File/1/123.png
+//
+// Store finds a file from the name recorded in the archive or the one used
+// in wikitext, which may have spaces where the archive has underscores.
+package filestore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// Store is a directory of mirrored media files.
+type Store struct {
+	dir string
+}
+
+// Open opens the mirrored files under dir, the scraper's files directory.
+func Open(dir string) (*Store, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", irowiki.ErrNotFound, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", irowiki.ErrInvalidInput, dir)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the store's directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Path returns where the scraper stores the file with filename, whether
+// or not it has been downloaded.
+func (s *Store) Path(filename string) string {
+	filename = strings.TrimSpace(filename)
+	r, _ := utf8.DecodeRuneInString(filename)
+	return filepath.Join(s.dir, "File", string(unicode.ToUpper(r)), filepath.Base(filename))
+}
+
+// Find returns the path of a downloaded file, trying filename as given,
+// with spaces as underscores, and with underscores as spaces.
+func (s *Store) Find(filename string) (string, bool) {
+	if strings.TrimSpace(filename) == "" {
+		return "", false
+	}
+	for _, name := range []string{
+		filename,
+		strings.ReplaceAll(filename, " ", "_"),
+		strings.ReplaceAll(filename, "_", " "),
+	} {
+		path := s.Path(name)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Open opens a downloaded file for reading. It returns irowiki.ErrNotFound
+// for files that haven't been downloaded.
+func (s *Store) Open(filename string) (*os.File, error) {
+	path, ok := s.Find(filename)
+	if !ok {
+		return nil, fmt.Errorf("%w: file %s is not mirrored", irowiki.ErrNotFound, filename)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,59 @@
+package filestore_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/filestore"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestStore tests finding files laid out by the scraper
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "File", "P"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "File", "P", "Poring_card.png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := filestore.Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if got, want := store.Path("apple.png"), filepath.Join(dir, "File", "A", "apple.png"); got != want {
+		t.Errorf("expected path %s, got %s", want, got)
+	}
+
+	// Test: Wikitext names with spaces find files stored with underscores
+	path, ok := store.Find("Poring card.png")
+	if !ok || filepath.Base(path) != "Poring_card.png" {
+		t.Errorf("expected Poring_card.png to be found, got %q, %v", path, ok)
+	}
+
+	f, err := store.Open("Poring_card.png")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "png" {
+		t.Errorf("unexpected contents %q", data)
+	}
+
+	if _, err := store.Open("Missing.png"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing file, got %v", err)
+	}
+	if _, ok := store.Find(""); ok {
+		t.Error("expected an empty name not to be found")
+	}
+
+	if _, err := filestore.Open(filepath.Join(dir, "missing")); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing directory, got %v", err)
+	}
+}
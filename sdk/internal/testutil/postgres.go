@@ -0,0 +1,168 @@
+package testutil
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgresDSNEnv names the environment variable holding the DSN of a
+// PostgreSQL database for tests. Tests needing one are skipped without it.
+const PostgresDSNEnv = "IROWIKI_TEST_POSTGRES_DSN"
+
+// PostgresTestDB is a schema of a PostgreSQL database holding the test data.
+type PostgresTestDB struct {
+	// DB runs statements with the schema first on the search path.
+	DB *sql.DB
+
+	// DSN is the database's DSN and Schema the name of the schema, for
+	// opening clients on it with ConnectionOptions.Schema.
+	DSN    string
+	Schema string
+}
+
+// postgresSchema creates the tables of the SQLite test database with their
+// PostgreSQL types.
+var postgresSchema = []string{
+	`CREATE TABLE pages (
+		page_id BIGINT PRIMARY KEY,
+		namespace INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL,
+		is_redirect BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(namespace, title),
+		CHECK(namespace >= 0)
+	)`,
+	`CREATE TABLE revisions (
+		revision_id BIGINT PRIMARY KEY,
+		page_id BIGINT NOT NULL REFERENCES pages(page_id) ON DELETE CASCADE,
+		parent_id BIGINT,
+		timestamp TIMESTAMPTZ NOT NULL,
+		"user" TEXT,
+		user_id INTEGER,
+		comment TEXT,
+		content TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		sha1 TEXT NOT NULL,
+		minor BOOLEAN DEFAULT FALSE,
+		tags TEXT,
+		CHECK(size >= 0)
+	)`,
+	`CREATE INDEX idx_rev_page_time ON revisions(page_id, timestamp DESC)`,
+	`CREATE TABLE files (
+		filename TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		descriptionurl TEXT NOT NULL,
+		sha1 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		width INTEGER,
+		height INTEGER,
+		mime_type TEXT NOT NULL,
+		timestamp TIMESTAMPTZ NOT NULL,
+		uploader TEXT
+	)`,
+}
+
+// SetupPostgresTestDB creates a schema in the database PostgresDSNEnv names
+// and copies the pages, revisions and files of src into it. The schema is
+// dropped when the test ends. The test is skipped if PostgresDSNEnv is unset.
+func SetupPostgresTestDB(t *testing.T, src *TestDB) *PostgresTestDB {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", PostgresDSNEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open PostgreSQL database: %v", err)
+	}
+	// One connection, so the search path set on it applies to every statement
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("irowiki_test_%d", time.Now().UnixNano())
+	quoted := pq.QuoteIdentifier(schema)
+	if _, err := db.Exec("CREATE SCHEMA " + quoted); err != nil {
+		db.Close()
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + quoted + " CASCADE")
+		db.Close()
+	})
+
+	if _, err := db.Exec("SET search_path TO " + quoted); err != nil {
+		t.Fatalf("failed to set search path: %v", err)
+	}
+	for _, statement := range postgresSchema {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("failed to execute schema: %v", err)
+		}
+	}
+	for _, table := range []string{"pages", "revisions", "files"} {
+		if err := copyTable(src.DB, db, table); err != nil {
+			t.Fatalf("failed to copy %s: %v", table, err)
+		}
+	}
+
+	return &PostgresTestDB{DB: db, DSN: dsn, Schema: schema}
+}
+
+// copyTable copies the rows of a table between databases with the same
+// columns. SQLite's 0 and 1 are valid PostgreSQL booleans.
+func copyTable(src, dest *sql.DB, table string) error {
+	rows, err := src.Query("SELECT * FROM " + table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(columns))
+	marks := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = pq.QuoteIdentifier(c)
+		marks[i] = fmt.Sprintf("$%d", i+1)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(marks, ", "))
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		if _, err := dest.Exec(insert, values...); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// PostgresPlaceholders rewrites the ? placeholders of a query to $1, $2, ...
+func PostgresPlaceholders(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
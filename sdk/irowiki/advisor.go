@@ -0,0 +1,302 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of Recommendation.
+const (
+	// RecommendIndex is a missing index. ApplyRecommended creates it.
+	RecommendIndex = "index"
+
+	// RecommendStatistics is missing query planner statistics.
+	// ApplyRecommended gathers them.
+	RecommendStatistics = "statistics"
+
+	// RecommendSearchIndex is a missing full-text index. It's built by
+	// RebuildSearchIndex, which ApplyRecommended doesn't run as it rewrites
+	// every page.
+	RecommendSearchIndex = "search_index"
+)
+
+// indexSpec is an index the SDK's queries assume.
+type indexSpec struct {
+	name    string
+	table   string
+	columns []string
+
+	// where makes the index partial
+	where string
+
+	// column is a column the index needs beyond those of the first
+	// schema version, which older archives may lack
+	column string
+
+	reason string
+}
+
+// advisedIndexes are the indexes the SDK's queries assume, from
+// sqliteSchema, plus revisions(user) for editor queries by name.
+var advisedIndexes = []indexSpec{
+	{name: "idx_pages_title", table: "pages", columns: []string{"title"}, reason: "page lookups by title"},
+	{name: "idx_pages_namespace", table: "pages", columns: []string{"namespace"}, reason: "listing pages by namespace"},
+	{name: "idx_pages_redirect_target", table: "pages", columns: []string{"redirect_target"}, where: "redirect_target IS NOT NULL", column: "redirect_target", reason: "finding the redirects into a page"},
+	{name: "idx_rev_page_time", table: "revisions", columns: []string{"page_id", "timestamp"}, reason: "latest revisions and page history"},
+	{name: "idx_rev_timestamp", table: "revisions", columns: []string{"timestamp"}, reason: "changes by period and recent changes"},
+	{name: "idx_rev_parent", table: "revisions", columns: []string{"parent_id"}, where: "parent_id IS NOT NULL", reason: "consecutive diffs"},
+	{name: "idx_rev_sha1", table: "revisions", columns: []string{"sha1"}, reason: "revert detection"},
+	{name: "idx_rev_user", table: "revisions", columns: []string{"user_id"}, where: "user_id IS NOT NULL", reason: "editor statistics by user ID"},
+	{name: "idx_rev_user_name", table: "revisions", columns: []string{"user", "timestamp"}, reason: "editor activity by username"},
+	{name: "idx_files_timestamp", table: "files", columns: []string{"timestamp"}, reason: "listing files by upload time"},
+	{name: "idx_links_source", table: "links", columns: []string{"source_page_id"}, reason: "a page's outgoing links"},
+	{name: "idx_links_target", table: "links", columns: []string{"target_title"}, reason: "backlinks and category members"},
+}
+
+// TableAnalysis describes a table of the archive.
+type TableAnalysis struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+
+	// Indexes are the names of the table's indexes.
+	Indexes []string `json:"indexes"`
+}
+
+// Recommendation is a change that would speed up the SDK's queries.
+type Recommendation struct {
+	// Kind is RecommendIndex, RecommendStatistics or RecommendSearchIndex.
+	Kind string `json:"kind"`
+
+	// Table is the table concerned, if any.
+	Table string `json:"table,omitempty"`
+
+	// Reason says which queries benefit.
+	Reason string `json:"reason"`
+
+	// SQL is the statement ApplyRecommended runs, or "" for
+	// recommendations it leaves to the caller.
+	SQL string `json:"sql,omitempty"`
+}
+
+// ArchiveAnalysis is the result of Store.Analyze.
+type ArchiveAnalysis struct {
+	// Tables are the archive's core tables, with their sizes.
+	Tables []TableAnalysis `json:"tables"`
+
+	// FullTextSearch and CommentSearch report whether the page and edit
+	// summary full-text indexes exist.
+	FullTextSearch bool `json:"full_text_search"`
+	CommentSearch  bool `json:"comment_search"`
+
+	// Statistics reports whether the query planner has statistics.
+	Statistics bool `json:"statistics"`
+
+	// Recommendations are in order of importance: indexes, then
+	// statistics, then full-text indexes.
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// ApplyResult is the result of Store.ApplyRecommended.
+type ApplyResult struct {
+	// Applied are the recommendations carried out.
+	Applied []Recommendation `json:"applied"`
+
+	// Remaining are the recommendations left to the caller.
+	Remaining []Recommendation `json:"remaining"`
+}
+
+// analyzedTables are the tables Analyze reports on.
+var analyzedTables = []string{"pages", "revisions", "files", "links"}
+
+// Analyze inspects the archive's tables and indexes.
+func (s *sqliteStore) Analyze(ctx context.Context) (*ArchiveAnalysis, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return analyzeSQLite(ctx, s.db)
+}
+
+// ApplyRecommended creates the missing indexes and gathers statistics.
+func (s *sqliteStore) ApplyRecommended(ctx context.Context) (*ApplyResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	release, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	analysis, err := analyzeSQLite(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{Applied: []Recommendation{}, Remaining: []Recommendation{}}
+	for _, r := range analysis.Recommendations {
+		if r.SQL == "" {
+			result.Remaining = append(result.Remaining, r)
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, r.SQL); err != nil {
+			return result, fmt.Errorf("%w: %s: %v", ErrDatabaseError, r.SQL, err)
+		}
+		result.Applied = append(result.Applied, r)
+	}
+
+	// Statistics gathered before the new indexes don't cover them;
+	// without any, the recommendation to gather them ran last
+	if len(result.Applied) > 0 && analysis.Statistics {
+		if _, err := s.db.ExecContext(ctx, "ANALYZE"); err != nil {
+			return result, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+	}
+	return result, nil
+}
+
+// analyzeSQLite inspects a SQLite archive.
+func analyzeSQLite(ctx context.Context, db *sql.DB) (*ArchiveAnalysis, error) {
+	analysis := &ArchiveAnalysis{Tables: []TableAnalysis{}, Recommendations: []Recommendation{}}
+
+	// Leading columns of each table's indexes
+	covered := make(map[string][][]string)
+	for _, table := range analyzedTables {
+		exists, err := sqliteTableExists(ctx, db, "main", table)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		info := TableAnalysis{Name: table, Indexes: []string{}}
+		covered[table] = [][]string{}
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&info.Rows); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		indexes, err := sqliteIndexColumns(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+		for name, columns := range indexes {
+			info.Indexes = append(info.Indexes, name)
+			covered[table] = append(covered[table], columns)
+		}
+		slices.Sort(info.Indexes)
+		analysis.Tables = append(analysis.Tables, info)
+	}
+
+	for _, spec := range advisedIndexes {
+		if _, ok := covered[spec.table]; !ok {
+			continue
+		}
+		if spec.column != "" {
+			exists, err := sqliteColumnExists(ctx, db, spec.table, spec.column)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				continue
+			}
+		}
+		if slices.ContainsFunc(covered[spec.table], func(columns []string) bool {
+			return len(columns) >= len(spec.columns) && slices.Equal(columns[:len(spec.columns)], spec.columns)
+		}) {
+			continue
+		}
+		analysis.Recommendations = append(analysis.Recommendations, Recommendation{
+			Kind:   RecommendIndex,
+			Table:  spec.table,
+			Reason: spec.reason,
+			SQL:    spec.createSQL(),
+		})
+	}
+
+	var err error
+	if analysis.Statistics, err = sqliteTableExists(ctx, db, "main", "sqlite_stat1"); err != nil {
+		return nil, err
+	}
+	if !analysis.Statistics {
+		analysis.Recommendations = append(analysis.Recommendations, Recommendation{
+			Kind:   RecommendStatistics,
+			Reason: "the query planner has no statistics to choose between indexes",
+			SQL:    "ANALYZE",
+		})
+	}
+
+	if analysis.FullTextSearch, err = sqliteTableExists(ctx, db, "main", "pages_fts"); err != nil {
+		return nil, err
+	}
+	if !analysis.FullTextSearch {
+		analysis.Recommendations = append(analysis.Recommendations, Recommendation{
+			Kind:   RecommendSearchIndex,
+			Table:  "pages_fts",
+			Reason: "SearchFullText needs a full-text index; build it with RebuildSearchIndex",
+		})
+	}
+	if analysis.CommentSearch, err = sqliteTableExists(ctx, db, "main", "revision_comments_fts"); err != nil {
+		return nil, err
+	}
+	return analysis, nil
+}
+
+// sqliteIndexColumns returns the columns of each index of a table, in
+// index order.
+func sqliteIndexColumns(ctx context.Context, db *sql.DB, table string) (map[string][]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT il.name, ii.name
+		FROM pragma_index_list(?) il, pragma_index_info(il.name) ii
+		ORDER BY il.name, ii.seqno`, table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[string][]string)
+	for rows.Next() {
+		var index string
+		var column sql.NullString
+		if err := rows.Scan(&index, &column); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		// Expression index columns have no name
+		indexes[index] = append(indexes[index], column.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return indexes, nil
+}
+
+// createSQL returns the statement creating the index.
+func (spec indexSpec) createSQL() string {
+	quoted := make([]string, len(spec.columns))
+	for i, column := range spec.columns {
+		quoted[i] = `"` + column + `"`
+	}
+	stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s(%s)", spec.name, spec.table, strings.Join(quoted, ", "))
+	if spec.where != "" {
+		stmt += " WHERE " + spec.where
+	}
+	return stmt
+}
+
+// Analyze inspects the archive's tables and indexes.
+func (s *postgresStore) Analyze(ctx context.Context) (*ArchiveAnalysis, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: Analyze is not implemented for PostgreSQL", ErrNotSupported)
+}
+
+// ApplyRecommended creates the missing indexes and gathers statistics.
+func (s *postgresStore) ApplyRecommended(ctx context.Context) (*ApplyResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: ApplyRecommended is not implemented for PostgreSQL", ErrNotSupported)
+}
@@ -0,0 +1,96 @@
+package irowiki_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteStore_Analyze tests recommending and creating missing indexes
+func TestSQLiteStore_Analyze(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// An archive from an older scraper: no history index or full-text index
+	_, err := tdb.DB.Exec(`
+		DROP INDEX IF EXISTS idx_rev_page_time;
+		DROP TABLE IF EXISTS pages_fts;
+		CREATE INDEX idx_rev_sha1_time ON revisions(sha1, timestamp)`)
+	if err != nil {
+		t.Fatalf("failed to alter fixture: %v", err)
+	}
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	analysis, err := store.Analyze(ctx)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	rows := map[string]int64{}
+	for _, table := range analysis.Tables {
+		rows[table.Name] = table.Rows
+	}
+	if rows["pages"] != 5 || rows["revisions"] != 7 {
+		t.Errorf("expected 5 pages and 7 revisions, got %v", rows)
+	}
+	if analysis.FullTextSearch || analysis.Statistics {
+		t.Errorf("expected no full-text index or statistics, got %+v", analysis)
+	}
+
+	kinds := map[string]int{}
+	sql := map[string]bool{}
+	for _, r := range analysis.Recommendations {
+		kinds[r.Kind]++
+		sql[r.SQL] = true
+	}
+	if !sql[`CREATE INDEX IF NOT EXISTS idx_rev_page_time ON revisions("page_id", "timestamp")`] {
+		t.Errorf("expected the history index to be recommended, got %+v", analysis.Recommendations)
+	}
+	if !sql[`CREATE INDEX IF NOT EXISTS idx_rev_user_name ON revisions("user", "timestamp")`] {
+		t.Errorf("expected the username index to be recommended, got %+v", analysis.Recommendations)
+	}
+	// Test: An index whose leading columns match covers the query
+	if sql[`CREATE INDEX IF NOT EXISTS idx_rev_sha1 ON revisions("sha1")`] {
+		t.Error("expected idx_rev_sha1_time to cover sha1 lookups")
+	}
+	if kinds[irowiki.RecommendStatistics] != 1 || kinds[irowiki.RecommendSearchIndex] != 1 {
+		t.Errorf("expected statistics and search index recommendations, got %v", kinds)
+	}
+
+	result, err := store.ApplyRecommended(ctx)
+	if err != nil {
+		t.Fatalf("ApplyRecommended failed: %v", err)
+	}
+	if len(result.Applied) != kinds[irowiki.RecommendIndex]+1 {
+		t.Errorf("expected the indexes and statistics to be applied, got %+v", result.Applied)
+	}
+	if len(result.Remaining) != 1 || result.Remaining[0].Kind != irowiki.RecommendSearchIndex {
+		t.Errorf("expected the search index to remain, got %+v", result.Remaining)
+	}
+
+	// Test: Only the search index is left to do
+	analysis, err = store.Analyze(ctx)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(analysis.Recommendations) != 1 || !analysis.Statistics {
+		t.Errorf("expected only the search index to be recommended, got %+v", analysis.Recommendations)
+	}
+
+	// Test: Nothing is left to apply
+	result, err = store.ApplyRecommended(ctx)
+	if err != nil {
+		t.Fatalf("ApplyRecommended failed: %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Errorf("expected nothing to apply, got %+v", result.Applied)
+	}
+}
@@ -0,0 +1,77 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultArticlePath is MediaWiki's default short URL pattern.
+const defaultArticlePath = "/wiki/$1"
+
+// readArchiveMeta reads the archive_meta key/value rows.
+func readArchiveMeta(ctx context.Context, db querier) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT key, value FROM archive_meta")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		meta[key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	return meta, nil
+}
+
+// newArchiveInfo maps archive_meta rows onto ArchiveInfo.
+func newArchiveInfo(meta map[string]string) *ArchiveInfo {
+	info := &ArchiveInfo{
+		WikiName:       meta["wiki_name"],
+		BaseURL:        meta["base_url"],
+		APIURL:         meta["api_url"],
+		ArticlePath:    meta["article_path"],
+		Language:       meta["language"],
+		Generator:      meta["generator"],
+		LicenseName:    meta["license_name"],
+		LicenseURL:     meta["license_url"],
+		ScraperVersion: meta["scraper_version"],
+		Meta:           meta,
+	}
+
+	if ts, err := time.Parse(time.RFC3339Nano, meta["scraped_at"]); err == nil {
+		info.ScrapedAt = ts
+	}
+
+	return info
+}
+
+// PageURL returns the URL of a page on the source wiki, or "" if the
+// archive does not record a base URL.
+func (a *ArchiveInfo) PageURL(title string) string {
+	if a.BaseURL == "" {
+		return ""
+	}
+
+	path := a.ArticlePath
+	if path == "" {
+		path = defaultArticlePath
+	}
+
+	escaped := url.PathEscape(strings.ReplaceAll(title, " ", "_"))
+	// Keep namespace separators and subpage slashes readable, as MediaWiki does
+	escaped = strings.NewReplacer("%3A", ":", "%2F", "/").Replace(escaped)
+
+	return strings.TrimRight(a.BaseURL, "/") + strings.Replace(path, "$1", escaped, 1)
+}
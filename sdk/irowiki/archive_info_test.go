@@ -0,0 +1,84 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetArchiveInfo tests reading archive metadata
+func TestSQLiteClient_GetArchiveInfo(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Archives without archive_meta report ErrNotFound
+	if _, err := client.GetArchiveInfo(ctx); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	_, err = tdb.DB.Exec(`CREATE TABLE archive_meta (key TEXT PRIMARY KEY, value TEXT NOT NULL, updated_at TIMESTAMP)`)
+	if err != nil {
+		t.Fatalf("failed to create archive_meta: %v", err)
+	}
+	_, err = tdb.DB.Exec(`INSERT INTO archive_meta (key, value) VALUES
+		('wiki_name', 'iRO Wiki'),
+		('base_url', 'https://irowiki.org'),
+		('license_name', 'CC BY-SA 4.0'),
+		('scraped_at', '2024-01-15T10:30:00+00:00'),
+		('custom', 'value')`)
+	if err != nil {
+		t.Fatalf("failed to insert archive_meta: %v", err)
+	}
+
+	info, err := client.GetArchiveInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetArchiveInfo failed: %v", err)
+	}
+
+	if info.WikiName != "iRO Wiki" {
+		t.Errorf("expected wiki name 'iRO Wiki', got '%s'", info.WikiName)
+	}
+	if info.LicenseName != "CC BY-SA 4.0" {
+		t.Errorf("expected license 'CC BY-SA 4.0', got '%s'", info.LicenseName)
+	}
+	if info.ScrapedAt.Year() != 2024 {
+		t.Errorf("expected scraped_at in 2024, got %v", info.ScrapedAt)
+	}
+	if info.Meta["custom"] != "value" {
+		t.Error("expected unmapped keys in Meta")
+	}
+}
+
+// TestArchiveInfo_PageURL tests source wiki URL generation
+func TestArchiveInfo_PageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     irowiki.ArchiveInfo
+		title    string
+		expected string
+	}{
+		{"default path", irowiki.ArchiveInfo{BaseURL: "https://irowiki.org/"}, "Main Page", "https://irowiki.org/wiki/Main_Page"},
+		{"custom path", irowiki.ArchiveInfo{BaseURL: "https://irowiki.org", ArticlePath: "/w/index.php?title=$1"}, "Poring", "https://irowiki.org/w/index.php?title=Poring"},
+		{"namespace", irowiki.ArchiveInfo{BaseURL: "https://irowiki.org"}, "File:Example.png", "https://irowiki.org/wiki/File:Example.png"},
+		{"no base url", irowiki.ArchiveInfo{}, "Poring", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.PageURL(tt.title); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package irowiki_test
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -148,3 +149,30 @@ func BenchmarkGetConsecutiveDiff(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkConcurrentReads measures mixed reads from goroutines sharing a
+// client; -cpu sets the number of goroutines
+func BenchmarkConcurrentReads(b *testing.B) {
+	tdb := testutil.SetupTestDBFile(&testing.T{})
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		b.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	var workers atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		worker := int(workers.Add(1))
+		ctx := context.WithValue(context.Background(), stressWorker{}, worker)
+		for i := worker; pb.Next(); i++ {
+			read := stressReads[i%len(stressReads)]
+			if err := read.call(ctx, client); err != nil {
+				b.Errorf("%s failed: %v", read.name, err)
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package irowiki
+
+import (
+	"slices"
+	"strings"
+)
+
+// systemUsers are the accounts MediaWiki makes edits as itself, such as
+// when importing pages or running maintenance scripts.
+var systemUsers = []string{"MediaWiki default", "Maintenance script", "MediaWiki message delivery"}
+
+// IsBot reports whether username looks like a bot account: it ends in "Bot"
+// or "BOT" ("AutoBot"), is "bot" or ends in " bot", "_bot" or "-bot" in any
+// case, or is one of MediaWiki's system users. Bots not named this way can
+// be listed in ConnectionOptions.BotUsers.
+func IsBot(username string) bool {
+	if strings.HasSuffix(username, "Bot") || strings.HasSuffix(username, "BOT") || slices.Contains(systemUsers, username) {
+		return true
+	}
+	lower := strings.ToLower(username)
+	if lower == "bot" {
+		return true
+	}
+	for _, sep := range []string{" ", "_", "-"} {
+		if strings.HasSuffix(lower, sep+"bot") {
+			return true
+		}
+	}
+	return false
+}
+
+// sqliteBotCondition returns a condition true when column names a bot by the
+// IsBot heuristics or is one of users. Anonymous edits are never bots.
+func sqliteBotCondition(column string, users []string) string {
+	return "(" + column + " IS NOT NULL AND (" +
+		column + " GLOB '*Bot' OR " + column + " GLOB '*BOT' OR " +
+		"LOWER(" + column + ") = 'bot' OR LOWER(" + column + ") GLOB '*[ _-]bot' OR " +
+		column + " IN (" + quoteUsers(users) + ")))"
+}
+
+// postgresBotCondition is sqliteBotCondition for PostgreSQL.
+func postgresBotCondition(column string, users []string) string {
+	return "(" + column + " IS NOT NULL AND (" +
+		column + " ~ '(Bot|BOT)$' OR " + column + " ~* '(^|[ _-])bot$' OR " +
+		column + " IN (" + quoteUsers(users) + ")))"
+}
+
+// quoteUsers returns the system users and users as a list of SQL string
+// literals. Underscores in users are read as spaces, as MediaWiki does.
+func quoteUsers(users []string) string {
+	quoted := make([]string, 0, len(systemUsers)+len(users))
+	for _, user := range append(slices.Clone(systemUsers), users...) {
+		user = strings.TrimSpace(strings.ReplaceAll(user, "_", " "))
+		if user != "" {
+			quoted = append(quoted, "'"+strings.ReplaceAll(user, "'", "''")+"'")
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,120 @@
+package irowiki_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// botNames are usernames and whether IsBot flags them
+var botNames = map[string]bool{
+	"UpdateBot":          true,
+	"MIRRORBOT":          true,
+	"Helper bot":         true,
+	"Helper_bot":         true,
+	"Helper-Bot":         true,
+	"bot":                true,
+	"Maintenance script": true,
+	"MediaWiki default":  true,
+	"Abbot":              false,
+	"Robotics":           false,
+	"Botanist":           false,
+	"Admin":              false,
+}
+
+// TestIsBot tests the bot username heuristics
+func TestIsBot(t *testing.T) {
+	for name, want := range botNames {
+		if got := irowiki.IsBot(name); got != want {
+			t.Errorf("IsBot(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestSQLiteClient_ExcludeBots tests flagging and excluding bot edits
+func TestSQLiteClient_ExcludeBots(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// One edit of Poring by each name, and one by a configured bot
+	id := int64(200)
+	users := []string{"Importer"}
+	for name := range botNames {
+		users = append(users, name)
+	}
+	for _, user := range users {
+		if _, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+			VALUES (?, 3, '2020-01-08 12:00:00', ?, NULL, 'edit', 'Poring', 6, 'x', 0)`, id, user); err != nil {
+			t.Fatalf("failed to insert revision: %v", err)
+		}
+		id++
+	}
+
+	client, err := irowiki.OpenSQLiteWithOptions(tdb.Path, irowiki.ConnectionOptions{BotUsers: []string{"Importer"}})
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	isBot := func(user string) bool { return user == "Importer" || irowiki.IsBot(user) }
+	bots := 0
+	for _, user := range users {
+		if isBot(user) {
+			bots++
+		}
+	}
+
+	// Test: Recent changes flag bot edits like IsBot, and can leave them out
+	changes, err := client.GetRecentChanges(ctx, irowiki.ChangesOptions{})
+	if err != nil {
+		t.Fatalf("GetRecentChanges failed: %v", err)
+	}
+	for _, change := range changes {
+		if change.Bot != isBot(change.User) {
+			t.Errorf("expected Bot = %v for %q", isBot(change.User), change.User)
+		}
+	}
+	humans, err := client.GetRecentChanges(ctx, irowiki.ChangesOptions{ExcludeBots: true})
+	if err != nil {
+		t.Fatalf("GetRecentChanges failed: %v", err)
+	}
+	if len(humans) != len(changes)-bots {
+		t.Errorf("expected %d changes without bots, got %d", len(changes)-bots, len(humans))
+	}
+	for _, change := range humans {
+		if change.Bot {
+			t.Errorf("expected no bot changes, got %q", change.User)
+		}
+	}
+
+	// Test: The heatmap leaves out bot edits
+	heatmap, err := client.GetActivityHeatmap(ctx, irowiki.ActivityScope{Title: "Poring", ExcludeBots: true})
+	if err != nil {
+		t.Fatalf("GetActivityHeatmap failed: %v", err)
+	}
+	if want := 1 + len(users) - bots; heatmap.Total != want {
+		t.Errorf("expected %d human edits of Poring, got %d", want, heatmap.Total)
+	}
+
+	// Test: Leaderboards flag and leave out bots
+	editors, err := client.GetTopEditorsForScope(ctx, irowiki.EditorScope{}, 100)
+	if err != nil {
+		t.Fatalf("GetTopEditorsForScope failed: %v", err)
+	}
+	for _, editor := range editors {
+		if editor.Bot != isBot(editor.Username) {
+			t.Errorf("expected Bot = %v for %q", isBot(editor.Username), editor.Username)
+		}
+	}
+	editors, err = client.GetTopEditorsForScope(ctx, irowiki.EditorScope{ExcludeBots: true}, 100)
+	if err != nil {
+		t.Fatalf("GetTopEditorsForScope failed: %v", err)
+	}
+	// The fixture's three editors and the new human names but Admin
+	if want := 3 + len(users) - bots - 1; len(editors) != want {
+		t.Errorf("expected %d human editors, got %d", want, len(editors))
+	}
+}
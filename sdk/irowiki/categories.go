@@ -0,0 +1,247 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxCategoryDepth is the deepest CategoryOptions.Depth accepted.
+const maxCategoryDepth = 10
+
+// CategoryOptions configures GetPagesInCategory.
+type CategoryOptions struct {
+	// Depth is how many levels of subcategories to descend into: 0 lists
+	// the category's own members, 1 also the members of its subcategories,
+	// and so on, up to 10. Each category is visited once, so cycles end.
+	Depth int
+
+	// Namespaces filters the members listed to these namespaces (default:
+	// all). Subcategories are descended into either way.
+	Namespaces []int
+
+	// Limit caps the number of members returned (0 = all).
+	Limit int
+}
+
+// Validate checks if the CategoryOptions are valid.
+func (o *CategoryOptions) Validate() error {
+	if o.Depth < 0 || o.Depth > maxCategoryDepth {
+		return fmt.Errorf("depth must be between 0 and %d", maxCategoryDepth)
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must be non-negative")
+	}
+	return nil
+}
+
+// CategoryMember is a page in a category or in one of its subcategories.
+type CategoryMember struct {
+	PageID    int64 `json:"page_id"`
+	Namespace int   `json:"namespace"`
+
+	// Title is the page's full title, with its namespace prefix.
+	Title string `json:"title"`
+
+	// Category is the category the page is directly in, without the
+	// "Category:" prefix: the one asked about or one of its subcategories.
+	Category string `json:"category"`
+
+	// Depth is the number of subcategory levels between the category asked
+	// about and the page: 0 for its own members.
+	Depth int `json:"depth"`
+}
+
+// getPageCategories lists the categories recorded for a page. The caller
+// checks that the links table exists.
+func getPageCategories(ctx context.Context, db querier, page *Page, placeholder func(n int) string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT target_title FROM links
+		WHERE source_page_id = `+placeholder(1)+` AND link_type = 'category'`, page.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var target string
+		if err := rows.Scan(&target); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		if name := categoryTitle(target); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// getPagesInCategory lists the members of a category, descending into its
+// subcategories level by level. The caller checks that the links table
+// exists and validates opts.
+func getPagesInCategory(ctx context.Context, db querier, scope wikiScope, category string, opts CategoryOptions, placeholder func(n int) string) ([]CategoryMember, error) {
+	members := []CategoryMember{}
+	seen := make(map[int64]bool)
+	visited := map[string]bool{category: true}
+	frontier := []string{category}
+	for depth := 0; len(frontier) > 0; depth++ {
+		// Categories are stored without their prefix, though older
+		// archives may have it
+		var args []any
+		var marks []string
+		for _, name := range frontier {
+			for _, target := range []string{name, strings.ReplaceAll(FullTitle(NamespaceCategory, name), "_", " ")} {
+				args = append(args, target)
+				marks = append(marks, placeholder(len(args)))
+			}
+		}
+
+		rows, err := db.QueryContext(ctx, `
+			SELECT DISTINCT p.page_id, p.namespace, p.title, l.target_title
+			FROM links l
+			JOIN pages p ON p.page_id = l.source_page_id
+			WHERE l.link_type = 'category'
+			  AND REPLACE(l.target_title, '_', ' ') IN (`+strings.Join(marks, ", ")+`)`+scope.filter("p")+`
+			ORDER BY p.namespace, p.title, l.target_title`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		var next []string
+		for rows.Next() {
+			var title, target string
+			m := CategoryMember{Depth: depth}
+			if err := rows.Scan(&m.PageID, &m.Namespace, &title, &target); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+			}
+			// Pages in several of the categories are listed under the first
+			if seen[m.PageID] {
+				continue
+			}
+			seen[m.PageID] = true
+			if m.Namespace == NamespaceCategory && depth < opts.Depth {
+				if sub := categoryTitle(title); !visited[sub] {
+					visited[sub] = true
+					next = append(next, sub)
+				}
+			}
+			if len(opts.Namespaces) > 0 && !slices.Contains(opts.Namespaces, m.Namespace) {
+				continue
+			}
+
+			m.Title, m.Category = FullTitle(m.Namespace, title), categoryTitle(target)
+			members = append(members, m)
+			if opts.Limit > 0 && len(members) == opts.Limit {
+				rows.Close()
+				return members, nil
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		frontier = next
+	}
+	return members, nil
+}
+
+// GetPageCategories returns the categories a page is in.
+func (c *sqliteClient) GetPageCategories(ctx context.Context, title string) ([]string, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	page, err := resolveLinkPage(ctx, c.db, c.wiki, title, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	// Archives loaded without links have no categories recorded
+	exists, err := sqliteTableExists(ctx, c.db, "main", "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []string{}, nil
+	}
+	return getPageCategories(ctx, c.db, page, placeholder)
+}
+
+// GetPagesInCategory returns the pages in a category and, down to
+// opts.Depth, in its subcategories.
+func (c *sqliteClient) GetPagesInCategory(ctx context.Context, category string, opts CategoryOptions) ([]CategoryMember, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	name := categoryTitle(category)
+	if name == "" {
+		return nil, fmt.Errorf("%w: category name must not be empty", ErrInvalidInput)
+	}
+
+	exists, err := sqliteTableExists(ctx, c.db, "main", "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []CategoryMember{}, nil
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getPagesInCategory(ctx, c.db, c.wiki, name, opts, placeholder)
+}
+
+// GetPageCategories returns the categories a page is in.
+func (c *postgresClient) GetPageCategories(ctx context.Context, title string) ([]string, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveLinkPage(ctx, c.db, c.wiki, title, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := postgresTableExists(ctx, c.db, "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []string{}, nil
+	}
+	return getPageCategories(ctx, c.db, page, placeholder)
+}
+
+// GetPagesInCategory returns the pages in a category and, down to
+// opts.Depth, in its subcategories.
+func (c *postgresClient) GetPagesInCategory(ctx context.Context, category string, opts CategoryOptions) ([]CategoryMember, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	name := categoryTitle(category)
+	if name == "" {
+		return nil, fmt.Errorf("%w: category name must not be empty", ErrInvalidInput)
+	}
+
+	exists, err := postgresTableExists(ctx, c.db, "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []CategoryMember{}, nil
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getPagesInCategory(ctx, c.db, c.wiki, name, opts, placeholder)
+}
@@ -0,0 +1,162 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// writeCategoryArchive writes an archive of categorized pages, with
+// Monsters and Slimes in each other
+func writeCategoryArchive(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "categories.db")
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	pages := []struct {
+		id        int64
+		namespace int
+		title     string
+		content   string
+	}{
+		{1, 0, "Poring", "[[Category:Slimes]] [[Category:Monsters|Poring]] [[Category:Slimes]]"},
+		{2, 0, "Drops", "[[Category:Slimes]]"},
+		{3, 0, "Baphomet", "[[category:monsters]] [[Category:Bosses]]"},
+		{4, 0, "Angeling", "[[Category:Slimes]] [[Category:Bosses]]"},
+		{5, 6, "Poring.png", "[[Category:Slimes]]"},
+		{10, 14, "Monsters", "[[Category:Slimes]]"},
+		{11, 14, "Slimes", "[[Category:Monsters]]"},
+		{12, 14, "Bosses", "[[Category:Monsters]]"},
+		{13, 0, "Uncategorized", "No categories. [[:Category:Monsters]]"},
+	}
+	ctx := context.Background()
+	for _, p := range pages {
+		if err := w.UpsertPage(ctx, &irowiki.Page{ID: p.id, Namespace: p.namespace, Title: p.title}); err != nil {
+			t.Fatalf("UpsertPage failed: %v", err)
+		}
+		rev := &irowiki.Revision{ID: 100 + p.id, PageID: p.id, Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Content: p.content}
+		if err := w.InsertRevision(ctx, rev); err != nil {
+			t.Fatalf("InsertRevision failed: %v", err)
+		}
+	}
+	return path
+}
+
+// TestSQLiteClient_GetPageCategories tests listing the categories of a page
+func TestSQLiteClient_GetPageCategories(t *testing.T) {
+	client, err := irowiki.OpenSQLite(writeCategoryArchive(t))
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		title string
+		want  []string
+	}{
+		{"Poring", []string{"Monsters", "Slimes"}},
+		{"Baphomet", []string{"Bosses", "Monsters"}},
+		{"Category:Slimes", []string{"Monsters"}},
+		{"Uncategorized", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := client.GetPageCategories(ctx, tt.title)
+		if err != nil {
+			t.Fatalf("GetPageCategories(%q) failed: %v", tt.title, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetPageCategories(%q) = %#v; expected %#v", tt.title, got, tt.want)
+		}
+	}
+
+	if _, err := client.GetPageCategories(ctx, "Nonexistent"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestSQLiteClient_GetPagesInCategory tests listing category members, with
+// subcategories descended into down to a depth
+func TestSQLiteClient_GetPagesInCategory(t *testing.T) {
+	client, err := irowiki.OpenSQLite(writeCategoryArchive(t))
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	titles := func(members []irowiki.CategoryMember) []string {
+		var got []string
+		for _, m := range members {
+			got = append(got, m.Title)
+		}
+		return got
+	}
+
+	// Test: Direct members, subcategories included
+	members, err := client.GetPagesInCategory(ctx, "Monsters", irowiki.CategoryOptions{})
+	if err != nil {
+		t.Fatalf("GetPagesInCategory failed: %v", err)
+	}
+	want := []irowiki.CategoryMember{
+		{PageID: 3, Namespace: 0, Title: "Baphomet", Category: "Monsters"},
+		{PageID: 1, Namespace: 0, Title: "Poring", Category: "Monsters"},
+		{PageID: 12, Namespace: 14, Title: "Category:Bosses", Category: "Monsters"},
+		{PageID: 11, Namespace: 14, Title: "Category:Slimes", Category: "Monsters"},
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("unexpected members:\n got %+v\nwant %+v", members, want)
+	}
+
+	// Test: Subcategory members follow, once each, and the cycle back to
+	// Monsters ends the descent
+	members, err = client.GetPagesInCategory(ctx, "Category:Monsters", irowiki.CategoryOptions{Depth: 5})
+	if err != nil {
+		t.Fatalf("GetPagesInCategory failed: %v", err)
+	}
+	expected := []string{"Baphomet", "Poring", "Category:Bosses", "Category:Slimes", "Angeling", "Drops", "File:Poring.png", "Category:Monsters"}
+	if got := titles(members); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if m := members[4]; m.Category != "Bosses" || m.Depth != 1 {
+		t.Errorf("expected Angeling under Bosses at depth 1, got %+v", m)
+	}
+
+	// Test: Namespace filters and limits apply to the members listed
+	members, err = client.GetPagesInCategory(ctx, "monsters", irowiki.CategoryOptions{Depth: 1, Namespaces: []int{0}, Limit: 3})
+	if err != nil {
+		t.Fatalf("GetPagesInCategory failed: %v", err)
+	}
+	if got := titles(members); !reflect.DeepEqual(got, []string{"Baphomet", "Poring", "Angeling"}) {
+		t.Errorf("expected the first 3 main namespace members, got %v", got)
+	}
+
+	// Test: Unused categories are empty
+	members, err = client.GetPagesInCategory(ctx, "Cards", irowiki.CategoryOptions{Depth: 2})
+	if err != nil {
+		t.Fatalf("GetPagesInCategory failed: %v", err)
+	}
+	if members == nil || len(members) != 0 {
+		t.Errorf("expected an empty list, got %#v", members)
+	}
+
+	invalid := []irowiki.CategoryOptions{{Depth: -1}, {Depth: 11}, {Limit: -1}}
+	for _, opts := range invalid {
+		if _, err := client.GetPagesInCategory(ctx, "Monsters", opts); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for %+v, got %v", opts, err)
+		}
+	}
+	if _, err := client.GetPagesInCategory(ctx, "Category:", irowiki.CategoryOptions{}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an empty name, got %v", err)
+	}
+}
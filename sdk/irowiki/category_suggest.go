@@ -0,0 +1,311 @@
+package irowiki
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// suggestNeighbours is the number of most similar categorized pages whose
+// categories SuggestCategories weighs.
+const suggestNeighbours = 50
+
+// suggestPages is the number of example pages a CategorySuggestion lists.
+const suggestPages = 5
+
+// CategorySuggestion is a category proposed for a page by SuggestCategories.
+type CategorySuggestion struct {
+	// Category is the category's name, without the "Category:" prefix.
+	Category string `json:"category"`
+
+	// Score is the share of the similar pages' weight that is in the
+	// category, from 0 to 1; higher is better.
+	Score float64 `json:"score"`
+
+	// Pages are the most similar pages in the category, best first, as
+	// evidence for the suggestion.
+	Pages []string `json:"pages"`
+}
+
+// PageCategories returns the names of the categories wikitext puts its
+// page in, without the "Category:" prefix, in order of appearance and
+// without duplicates. Categories added by templates aren't seen, nor are
+// links in comments and <nowiki>.
+func PageCategories(wikitext string) []string {
+	var names []string
+	for _, l := range extractPageLinks(wikitext) {
+		if name := normalizeTitle(l.target); l.linkType == LinkTypeCategory && name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// categoryTitle returns a category link target as a category name: without
+// the "Category:" prefix, with spaces and the first letter upper-cased.
+func categoryTitle(target string) string {
+	if ns, name := ParseTitle(target); ns == NamespaceCategory {
+		target = name
+	}
+	return normalizeTitle(target)
+}
+
+// CategoryNeighbour is a page similar to the one categories are suggested
+// for, as weighed by RankCategories.
+type CategoryNeighbour struct {
+	// Title is the page's title.
+	Title string
+
+	// Weight is how similar the page is; higher is more similar.
+	Weight float64
+
+	// Categories are the page's categories.
+	Categories []string
+}
+
+// RankCategories turns the categories of a page's neighbours, most similar
+// first, into at most n suggestions, leaving out the categories in exclude
+// (the page's own). A category's score is the share of the neighbours'
+// total weight in it. It implements SuggestCategories for other sources of
+// similar pages, such as a vector index.
+func RankCategories(neighbours []CategoryNeighbour, exclude []string, n int) []CategorySuggestion {
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[categoryTitle(name)] = true
+	}
+
+	var total float64
+	byName := make(map[string]*CategorySuggestion)
+	for _, nb := range neighbours {
+		total += nb.Weight
+		seen := make(map[string]bool, len(nb.Categories))
+		for _, name := range nb.Categories {
+			name = categoryTitle(name)
+			if name == "" || excluded[name] || seen[name] {
+				continue
+			}
+			seen[name] = true
+			s, ok := byName[name]
+			if !ok {
+				s = &CategorySuggestion{Category: name, Pages: []string{}}
+				byName[name] = s
+			}
+			s.Score += nb.Weight
+			if len(s.Pages) < suggestPages {
+				s.Pages = append(s.Pages, nb.Title)
+			}
+		}
+	}
+
+	suggestions := make([]CategorySuggestion, 0, len(byName))
+	for _, s := range byName {
+		if total > 0 {
+			s.Score /= total
+		}
+		suggestions = append(suggestions, *s)
+	}
+	slices.SortFunc(suggestions, func(a, b CategorySuggestion) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Category, b.Category))
+	})
+	return suggestions[:min(n, len(suggestions))]
+}
+
+// suggestCategories proposes categories for page from the categorized pages
+// sharing its templates. A page's similarity is the share of the page's
+// templates it also uses, each weighted by its inverse document frequency so
+// that templates used on most pages, like navigation boxes, count little.
+// The caller checks that the links table exists.
+func suggestCategories(ctx context.Context, db querier, scope wikiScope, page *Page, n int, placeholder func(n int) string) ([]CategorySuggestion, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT link_type, target_title FROM links
+		WHERE source_page_id = `+placeholder(1)+` AND link_type IN ('template', 'category')
+	`, page.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	var templates, own []string
+	for rows.Next() {
+		var linkType, target string
+		if err := rows.Scan(&linkType, &target); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		if linkType == "category" {
+			own = append(own, target)
+		} else if !slices.Contains(templates, target) {
+			templates = append(templates, target)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	if len(templates) == 0 {
+		return []CategorySuggestion{}, nil
+	}
+
+	var pages int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages p WHERE 1 = 1"+scope.filter("p")).Scan(&pages); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	// The categorized pages sharing a template with the page
+	args := []interface{}{page.ID}
+	marks := make([]string, len(templates))
+	for i, t := range templates {
+		args = append(args, t)
+		marks[i] = placeholder(len(args))
+	}
+	rows, err = db.QueryContext(ctx, `
+		SELECT p.page_id, p.title, l.target_title
+		FROM links l
+		JOIN pages p ON p.page_id = l.source_page_id
+		WHERE l.link_type = 'template'
+		  AND l.source_page_id <> `+placeholder(1)+`
+		  AND l.target_title IN (`+strings.Join(marks, ", ")+`)
+		  AND EXISTS (SELECT 1 FROM links c WHERE c.source_page_id = l.source_page_id AND c.link_type = 'category')`+scope.filter("p"), args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	shared := make(map[int64][]string)
+	titles := make(map[int64]string)
+	uses := make(map[string]int, len(templates))
+	for rows.Next() {
+		var id int64
+		var title, template string
+		if err := rows.Scan(&id, &title, &template); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		titles[id] = title
+		if !slices.Contains(shared[id], template) {
+			shared[id] = append(shared[id], template)
+			uses[template]++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	// Uses are counted among categorized pages, plus the page itself
+	idf := make(map[string]float64, len(templates))
+	var possible float64
+	for _, t := range templates {
+		idf[t] = math.Log(1 + float64(pages)/float64(uses[t]+1))
+		possible += idf[t]
+	}
+
+	ids := make([]int64, 0, len(shared))
+	weights := make(map[int64]float64, len(shared))
+	for id, ts := range shared {
+		for _, t := range ts {
+			weights[id] += idf[t]
+		}
+		weights[id] /= possible
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b int64) int {
+		return cmp.Or(cmp.Compare(weights[b], weights[a]), cmp.Compare(titles[a], titles[b]), cmp.Compare(a, b))
+	})
+	ids = ids[:min(suggestNeighbours, len(ids))]
+	if len(ids) == 0 {
+		return []CategorySuggestion{}, nil
+	}
+
+	neighbours := make([]CategoryNeighbour, len(ids))
+	args = args[:0]
+	marks = make([]string, len(ids))
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		neighbours[i] = CategoryNeighbour{Title: titles[id], Weight: weights[id]}
+		args = append(args, id)
+		marks[i] = placeholder(len(args))
+		index[id] = i
+	}
+	rows, err = db.QueryContext(ctx, `
+		SELECT source_page_id, target_title FROM links
+		WHERE link_type = 'category'
+		  AND source_page_id IN (`+strings.Join(marks, ", ")+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var category string
+		if err := rows.Scan(&id, &category); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		nb := &neighbours[index[id]]
+		nb.Categories = append(nb.Categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	return RankCategories(neighbours, own, n), nil
+}
+
+// SuggestCategories proposes categories for a page from the categorized
+// pages sharing its templates.
+func (c *sqliteClient) SuggestCategories(ctx context.Context, title string, n int) ([]CategorySuggestion, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("%w: negative suggestion count", ErrInvalidInput)
+	}
+	if n == 0 {
+		n = 10
+	}
+
+	page, err := c.getPage(ctx, title, true)
+	if err != nil {
+		return nil, err
+	}
+	// Archives loaded without links have nothing to compare
+	exists, err := sqliteTableExists(ctx, c.db, "main", "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []CategorySuggestion{}, nil
+	}
+
+	placeholder := func(int) string { return "?" }
+	return suggestCategories(ctx, c.db, c.wiki, page, n, placeholder)
+}
+
+// SuggestCategories proposes categories for a page from the categorized
+// pages sharing its templates.
+func (c *postgresClient) SuggestCategories(ctx context.Context, title string, n int) ([]CategorySuggestion, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("%w: negative suggestion count", ErrInvalidInput)
+	}
+	if n == 0 {
+		n = 10
+	}
+
+	page, err := c.getPage(ctx, title, true)
+	if err != nil {
+		return nil, err
+	}
+	// Archives loaded without links have nothing to compare
+	exists, err := postgresTableExists(ctx, c.db, "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []CategorySuggestion{}, nil
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return suggestCategories(ctx, c.db, c.wiki, page, n, placeholder)
+}
@@ -0,0 +1,100 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_SuggestCategories tests proposing the categories of the
+// pages sharing a page's templates
+func TestSQLiteClient_SuggestCategories(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Poring shares both its templates with Fabre and one with Lunatic and
+	// Prontera; Main_Page isn't categorized, so it doesn't count
+	for _, stmt := range []string{
+		`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (6, 0, 'Fabre', 0), (7, 0, 'Lunatic', 0)`,
+		`CREATE TABLE links (source_page_id INTEGER NOT NULL, target_title TEXT NOT NULL, link_type TEXT NOT NULL)`,
+		`INSERT INTO links VALUES
+			(3, 'Monster', 'template'), (3, 'Navbox', 'template'), (3, 'Insects', 'category'),
+			(6, 'Monster', 'template'), (6, 'Navbox', 'template'), (6, 'Monsters', 'category'), (6, 'Insects', 'category'),
+			(7, 'Monster', 'template'), (7, 'Category:Monsters', 'category'),
+			(2, 'Navbox', 'template'), (2, 'Cities', 'category'),
+			(1, 'Navbox', 'template')`,
+	} {
+		if _, err := tdb.DB.Exec(stmt); err != nil {
+			t.Fatalf("failed to set up links: %v", err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	suggestions, err := client.SuggestCategories(ctx, "Poring", 0)
+	if err != nil {
+		t.Fatalf("SuggestCategories failed: %v", err)
+	}
+	// Fabre weighs 1, Lunatic and Prontera 0.5; Insects is Poring's own
+	if len(suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", suggestions)
+	}
+	if s := suggestions[0]; s.Category != "Monsters" || math.Abs(s.Score-0.75) > 1e-9 || !slices.Equal(s.Pages, []string{"Fabre", "Lunatic"}) {
+		t.Errorf("expected Monsters first from Fabre and Lunatic, got %+v", s)
+	}
+	if s := suggestions[1]; s.Category != "Cities" || math.Abs(s.Score-0.25) > 1e-9 {
+		t.Errorf("expected Cities second, got %+v", s)
+	}
+
+	if suggestions, err := client.SuggestCategories(ctx, "Poring", 1); err != nil || len(suggestions) != 1 {
+		t.Errorf("expected 1 suggestion, got %+v, %v", suggestions, err)
+	}
+	if suggestions, err := client.SuggestCategories(ctx, "Example.png", 0); err != nil || len(suggestions) != 0 {
+		t.Errorf("expected no suggestions for a page without templates, got %+v, %v", suggestions, err)
+	}
+	if _, err := client.SuggestCategories(ctx, "Nonexistent", 0); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := client.SuggestCategories(ctx, "Poring", -1); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// TestSQLiteClient_SuggestCategories_NoLinks tests archives loaded without
+// links
+func TestSQLiteClient_SuggestCategories_NoLinks(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	suggestions, err := client.SuggestCategories(context.Background(), "Poring", 0)
+	if err != nil {
+		t.Fatalf("SuggestCategories failed: %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("expected no suggestions, got %+v", suggestions)
+	}
+}
+
+// TestPageCategories tests reading the categories of wikitext
+func TestPageCategories(t *testing.T) {
+	got := irowiki.PageCategories("[[Category:Monsters]] [[category: pink_slimes|Poring]]\n[[Category:Monsters]] [[Monsters]]")
+	if want := []string{"Monsters", "Pink slimes"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,140 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Change is one edit in a recent changes feed.
+type Change struct {
+	// RevisionID identifies the edit. MediaWiki assigns revision IDs in
+	// increasing order, so it doubles as a feed cursor.
+	RevisionID int64
+
+	// PageID, Namespace and Title identify the edited page.
+	PageID    int64
+	Namespace int
+	Title     string
+
+	// Timestamp is when the edit was made on the wiki.
+	Timestamp time.Time
+
+	// User and Comment are the editor and edit summary.
+	User    string
+	Comment string
+
+	// Size is the page size after the edit and SizeDelta its change in bytes.
+	Size      int
+	SizeDelta int
+
+	// Minor marks minor edits and New page creations.
+	Minor bool
+	New   bool
+
+	// Bot marks edits by bots (see IsBot and ConnectionOptions.BotUsers).
+	Bot bool
+}
+
+// ChangesOptions selects a window of the recent changes feed.
+type ChangesOptions struct {
+	// AfterRevisionID returns the changes following this revision, oldest
+	// first. With 0, the newest Limit changes are returned, also oldest first.
+	AfterRevisionID int64
+
+	// Limit is the maximum number of changes. Default: 100.
+	Limit int
+
+	// ExcludeBots leaves out edits by bots.
+	ExcludeBots bool
+}
+
+// getRecentChanges reads a window of the changes feed in revision order. bot
+// is the backend's condition for r.user being a bot.
+func getRecentChanges(ctx context.Context, db querier, scope wikiScope, opts ChangesOptions, bot string, placeholder func(n int) string) ([]Change, error) {
+	if opts.AfterRevisionID < 0 || opts.Limit < 0 {
+		return nil, fmt.Errorf("%w: negative revision ID or limit", ErrInvalidInput)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 100
+	}
+
+	order := "DESC"
+	args := []interface{}{}
+	where := "1 = 1"
+	if opts.AfterRevisionID > 0 {
+		order = "ASC"
+		args = append(args, opts.AfterRevisionID)
+		where = "r.revision_id > " + placeholder(len(args))
+	}
+	if opts.ExcludeBots {
+		where += " AND NOT " + bot
+	}
+	args = append(args, opts.Limit)
+
+	query := `
+		SELECT r.revision_id, r.page_id, p.namespace, p.title, r.timestamp,
+		       r.user, r.comment, r.size, pr.size, r.minor, r.parent_id, ` + bot + `
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		LEFT JOIN revisions pr ON pr.revision_id = r.parent_id
+		WHERE ` + where + scope.filter("p") + `
+		ORDER BY r.revision_id ` + order + `
+		LIMIT ` + placeholder(len(args))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var changes []Change
+	for rows.Next() {
+		var c Change
+		var user, comment sql.NullString
+		var parentSize, parentID sql.NullInt64
+
+		if err := rows.Scan(
+			&c.RevisionID, &c.PageID, &c.Namespace, &c.Title, &c.Timestamp,
+			&user, &comment, &c.Size, &parentSize, &c.Minor, &parentID, &c.Bot,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		c.User = user.String
+		c.Comment = comment.String
+		c.New = !parentID.Valid
+		c.SizeDelta = c.Size - int(parentSize.Int64)
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	if order == "DESC" {
+		slices.Reverse(changes)
+	}
+	return changes, nil
+}
+
+// GetRecentChanges returns a window of the recent changes feed.
+func (c *sqliteClient) GetRecentChanges(ctx context.Context, opts ChangesOptions) ([]Change, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getRecentChanges(ctx, c.db, c.wiki, opts, sqliteBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
+
+// GetRecentChanges returns a window of the recent changes feed.
+func (c *postgresClient) GetRecentChanges(ctx context.Context, opts ChangesOptions) ([]Change, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getRecentChanges(ctx, c.db, c.wiki, opts, postgresBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
@@ -0,0 +1,148 @@
+package irowiki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Groupings, for ChangesGroupOptions.GroupBy.
+const (
+	// GroupByPage summarizes the changes to each page.
+	GroupByPage = "page"
+
+	// GroupByEditor summarizes the changes by each editor.
+	GroupByEditor = "editor"
+)
+
+// ChangesGroupOptions configures GetChangesByPeriodGrouped.
+type ChangesGroupOptions struct {
+	// GroupBy is GroupByPage or GroupByEditor.
+	// Default: GroupByPage.
+	GroupBy string
+
+	// Limit is the maximum number of groups, most revisions first.
+	// Set to 0 for default limit (100).
+	Limit int
+
+	// ExcludeBots leaves out edits by bots.
+	ExcludeBots bool
+}
+
+// ChangeGroup summarizes the changes to a page or by an editor in a period.
+type ChangeGroup struct {
+	// PageID, Namespace and Title identify the page of GroupByPage groups.
+	PageID    int64  `json:"page_id,omitempty"`
+	Namespace int    `json:"namespace,omitempty"`
+	Title     string `json:"title,omitempty"`
+
+	// User is the editor of GroupByEditor groups, "" for hidden usernames.
+	User string `json:"user,omitempty"`
+
+	// Revisions is the number of revisions in the group.
+	Revisions int `json:"revisions"`
+
+	// NetSizeDelta is the total change in page size, in bytes. Page
+	// creations count their full size.
+	NetSizeDelta int `json:"net_size_delta"`
+
+	// Editors lists the editors of GroupByPage groups, by name.
+	Editors []string `json:"editors,omitempty"`
+
+	// Pages is the number of pages edited in GroupByEditor groups.
+	Pages int `json:"pages,omitempty"`
+}
+
+// getChangesByPeriodGrouped summarizes the revisions between start and end
+// in a single GROUP BY. editors is the backend's aggregate returning the
+// distinct r.user values as a sorted JSON array, and bot its condition for
+// r.user being a bot.
+func getChangesByPeriodGrouped(ctx context.Context, db querier, scope wikiScope, start, end time.Time, opts ChangesGroupOptions, editors, bot string, placeholder func(n int) string) ([]ChangeGroup, error) {
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("%w: negative limit", ErrInvalidInput)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 100
+	}
+
+	var columns, group string
+	switch opts.GroupBy {
+	case "", GroupByPage:
+		columns = "p.page_id, p.namespace, p.title, " + editors
+		group = "p.page_id, p.namespace, p.title"
+	case GroupByEditor:
+		columns = "COALESCE(r.user, ''), COUNT(DISTINCT r.page_id)"
+		group = "COALESCE(r.user, '')"
+	default:
+		return nil, fmt.Errorf("%w: unknown grouping %q", ErrInvalidInput, opts.GroupBy)
+	}
+
+	query := `
+		SELECT ` + columns + `, COUNT(*), SUM(r.size - COALESCE(pr.size, 0))
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		LEFT JOIN revisions pr ON pr.revision_id = r.parent_id
+		WHERE r.timestamp BETWEEN ` + placeholder(1) + ` AND ` + placeholder(2) + scope.filter("p")
+	if opts.ExcludeBots {
+		query += " AND NOT " + bot
+	}
+	query += `
+		GROUP BY ` + group + `
+		ORDER BY COUNT(*) DESC, ` + group + `
+		LIMIT ` + placeholder(3)
+
+	rows, err := db.QueryContext(ctx, query, start, end, opts.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	groups := []ChangeGroup{}
+	for rows.Next() {
+		var g ChangeGroup
+		var delta *int64
+		if opts.GroupBy == GroupByEditor {
+			err = rows.Scan(&g.User, &g.Pages, &g.Revisions, &delta)
+		} else {
+			var names []byte
+			err = rows.Scan(&g.PageID, &g.Namespace, &g.Title, &names, &g.Revisions, &delta)
+			if err == nil {
+				err = json.Unmarshal(names, &g.Editors)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		if delta != nil {
+			g.NetSizeDelta = int(*delta)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return groups, nil
+}
+
+// GetChangesByPeriodGrouped summarizes the revisions within a time range.
+func (c *sqliteClient) GetChangesByPeriodGrouped(ctx context.Context, start, end time.Time, opts ChangesGroupOptions) ([]ChangeGroup, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	editors := "json_group_array(DISTINCT r.user ORDER BY r.user) FILTER (WHERE r.user IS NOT NULL)"
+	return getChangesByPeriodGrouped(ctx, c.db, c.wiki, start, end, opts, editors, sqliteBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
+
+// GetChangesByPeriodGrouped summarizes the revisions within a time range.
+func (c *postgresClient) GetChangesByPeriodGrouped(ctx context.Context, start, end time.Time, opts ChangesGroupOptions) ([]ChangeGroup, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	editors := "COALESCE(json_agg(DISTINCT r.user ORDER BY r.user) FILTER (WHERE r.user IS NOT NULL), '[]')"
+	return getChangesByPeriodGrouped(ctx, c.db, c.wiki, start, end, opts, editors, postgresBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
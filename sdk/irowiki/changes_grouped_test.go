@@ -0,0 +1,85 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetChangesByPeriodGrouped tests summarizing a period's
+// changes per page and per editor
+func TestSQLiteClient_GetChangesByPeriodGrouped(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// A bot grows Poring by 8 bytes
+	if _, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+		VALUES (107, 3, 104, '2020-01-05 12:00:00', 'UpdateBot', 4, 'bot: update', 'Poring', 40, 'x', 0)`); err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		opts irowiki.ChangesGroupOptions
+		want []irowiki.ChangeGroup
+	}{
+		{"pages", irowiki.ChangesGroupOptions{}, []irowiki.ChangeGroup{
+			{PageID: 1, Title: "Main_Page", Revisions: 2, NetSizeDelta: 25, Editors: []string{"Admin", "Editor"}},
+			{PageID: 2, Title: "Prontera", Revisions: 2, NetSizeDelta: 29, Editors: []string{"Admin", "Editor"}},
+			{PageID: 3, Title: "Poring", Revisions: 2, NetSizeDelta: 40, Editors: []string{"Contributor", "UpdateBot"}},
+			{PageID: 4, Namespace: 6, Title: "Example.png", Revisions: 1, NetSizeDelta: 10, Editors: []string{"Admin"}},
+			{PageID: 5, Title: "Redirect_Test", Revisions: 1, NetSizeDelta: 20, Editors: []string{"Admin"}},
+		}},
+		{"pages without bots", irowiki.ChangesGroupOptions{ExcludeBots: true, Limit: 3}, []irowiki.ChangeGroup{
+			{PageID: 1, Title: "Main_Page", Revisions: 2, NetSizeDelta: 25, Editors: []string{"Admin", "Editor"}},
+			{PageID: 2, Title: "Prontera", Revisions: 2, NetSizeDelta: 29, Editors: []string{"Admin", "Editor"}},
+			{PageID: 3, Title: "Poring", Revisions: 1, NetSizeDelta: 32, Editors: []string{"Contributor"}},
+		}},
+		{"editors", irowiki.ChangesGroupOptions{GroupBy: irowiki.GroupByEditor}, []irowiki.ChangeGroup{
+			{User: "Admin", Revisions: 4, NetSizeDelta: 81, Pages: 4},
+			{User: "Editor", Revisions: 2, NetSizeDelta: 3, Pages: 2},
+			{User: "Contributor", Revisions: 1, NetSizeDelta: 32, Pages: 1},
+			{User: "UpdateBot", Revisions: 1, NetSizeDelta: 8, Pages: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := client.GetChangesByPeriodGrouped(ctx, start, end, tt.opts)
+			if err != nil {
+				t.Fatalf("GetChangesByPeriodGrouped failed: %v", err)
+			}
+			if !reflect.DeepEqual(groups, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, groups)
+			}
+		})
+	}
+
+	// Test: An empty period has no groups
+	groups, err := client.GetChangesByPeriodGrouped(ctx, end, end.AddDate(0, 1, 0), irowiki.ChangesGroupOptions{})
+	if err != nil || len(groups) != 0 {
+		t.Errorf("expected no groups, got %+v, %v", groups, err)
+	}
+
+	// Test: Unknown groupings and negative limits are rejected
+	for _, opts := range []irowiki.ChangesGroupOptions{{GroupBy: "month"}, {Limit: -1}} {
+		if _, err := client.GetChangesByPeriodGrouped(ctx, start, end, opts); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for %+v, got %v", opts, err)
+		}
+	}
+}
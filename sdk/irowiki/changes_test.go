@@ -0,0 +1,59 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetRecentChanges tests reading and following the changes feed
+func TestSQLiteClient_GetRecentChanges(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Without a cursor, the newest changes are returned oldest first
+	changes, err := client.GetRecentChanges(ctx, irowiki.ChangesOptions{Limit: 3})
+	if err != nil {
+		t.Fatalf("GetRecentChanges failed: %v", err)
+	}
+	if len(changes) != 3 || changes[0].RevisionID != 104 || changes[2].RevisionID != 106 {
+		t.Fatalf("expected revisions 104-106, got %+v", changes)
+	}
+	if changes[0].Title != "Poring" || !changes[0].New || changes[0].SizeDelta != 32 {
+		t.Errorf("unexpected page creation: %+v", changes[0])
+	}
+
+	// Test: A cursor returns the following changes with size deltas
+	changes, err = client.GetRecentChanges(ctx, irowiki.ChangesOptions{AfterRevisionID: 102, Limit: 1})
+	if err != nil {
+		t.Fatalf("GetRecentChanges failed: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(changes))
+	}
+	if c := changes[0]; c.RevisionID != 103 || c.Title != "Prontera" || c.New || !c.Minor || c.SizeDelta != -1 {
+		t.Errorf("unexpected change: %+v", c)
+	}
+
+	// Test: Nothing follows the newest change
+	changes, err = client.GetRecentChanges(ctx, irowiki.ChangesOptions{AfterRevisionID: 106})
+	if err != nil || len(changes) != 0 {
+		t.Errorf("expected no changes, got %v, %v", changes, err)
+	}
+
+	// Test: Negative cursors are rejected
+	if _, err := client.GetRecentChanges(ctx, irowiki.ChangesOptions{AfterRevisionID: -1}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Edit classes assigned by ClassifyRevisions (RevisionClassification.Class).
+const (
+	// EditAddition is an edit that grows the page or keeps its size.
+	EditAddition = "addition"
+
+	// EditRemoval is an edit that shrinks the page.
+	EditRemoval = "removal"
+
+	// EditRevert is an edit that restores an earlier revision, or undoes
+	// one according to its edit summary.
+	EditRevert = "revert"
+
+	// EditFormatting is an edit that only reformats the wikitext
+	// (IsCosmeticEdit), or a null edit that leaves it unchanged.
+	EditFormatting = "formatting"
+
+	// EditVandalismLikely is an addition or removal that looks like
+	// vandalism: blanking most of the page, or being reverted when made
+	// anonymously or without an edit summary.
+	EditVandalismLikely = "vandalism_likely"
+)
+
+var (
+	// revertCommentPattern matches edit summaries of reverts, including
+	// MediaWiki's undo and rollback summaries.
+	revertCommentPattern = regexp.MustCompile(`(?i)\b(revert(ed|ing)?|rv|rvv|undo|undid|undoing|rollback|rolled back)\b`)
+
+	// undoCommentPattern captures the revision an undo summary names
+	// ("Undo revision 1234 by ...", "Undid revision 1234 by ...").
+	undoCommentPattern = regexp.MustCompile(`(?i)\bund(?:o|id|oing) revision (\d+)`)
+)
+
+const (
+	// blankingMinSize is the smallest page, in bytes, whose blanking is
+	// considered vandalism; stubs are often legitimately emptied.
+	blankingMinSize = 100
+
+	// blankingRatio is the fraction of the page a blanking edit leaves at most.
+	blankingRatio = 0.1
+)
+
+// RevisionClassification is the label ClassifyRevisions gives a revision.
+type RevisionClassification struct {
+	// RevisionID, Timestamp, User and Comment identify the edit.
+	RevisionID int64     `json:"revision_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	User       string    `json:"user"`
+	Comment    string    `json:"comment,omitempty"`
+
+	// SizeDelta is the change in page size from the previous revision, in bytes.
+	SizeDelta int `json:"size_delta"`
+
+	// Class is one of EditAddition, EditRemoval, EditRevert,
+	// EditFormatting or EditVandalismLikely.
+	Class string `json:"class"`
+
+	// RevertedTo is the earlier revision a revert restored exactly, if any.
+	RevertedTo int64 `json:"reverted_to,omitempty"`
+
+	// Reverted reports whether a later revert undid this edit.
+	Reverted bool `json:"reverted"`
+}
+
+// classifyRevisions labels the revisions of a page, given oldest first.
+func classifyRevisions(revs []Revision) []RevisionClassification {
+	result := make([]RevisionClassification, len(revs))
+	index := make(map[int64]int, len(revs))
+	latestBySHA1 := make(map[string]int, len(revs))
+
+	for i, rev := range revs {
+		c := RevisionClassification{
+			RevisionID: rev.ID,
+			Timestamp:  rev.Timestamp,
+			User:       rev.User,
+			Comment:    rev.Comment,
+			SizeDelta:  rev.Size,
+		}
+
+		var prev *Revision
+		if i > 0 {
+			prev = &revs[i-1]
+			c.SizeDelta = rev.Size - prev.Size
+		}
+
+		restored, identical := latestBySHA1[rev.SHA1]
+		switch {
+		case rev.SHA1 != "" && identical && restored < i-1:
+			// Content matches a revision before the previous one: every
+			// edit in between was undone
+			c.Class = EditRevert
+			c.RevertedTo = revs[restored].ID
+			for j := restored + 1; j < i; j++ {
+				result[j].Reverted = true
+			}
+		case revertCommentPattern.MatchString(rev.Comment):
+			c.Class = EditRevert
+			if m := undoCommentPattern.FindStringSubmatch(rev.Comment); m != nil {
+				if id, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+					if j, ok := index[id]; ok {
+						result[j].Reverted = true
+					}
+				}
+			}
+		case prev != nil && (rev.Content == prev.Content || IsCosmeticEdit(prev.Content, rev.Content)):
+			c.Class = EditFormatting
+		case c.SizeDelta < 0:
+			c.Class = EditRemoval
+		default:
+			c.Class = EditAddition
+		}
+
+		result[i] = c
+		index[rev.ID] = i
+		if rev.SHA1 != "" {
+			latestBySHA1[rev.SHA1] = i
+		}
+	}
+
+	// Vandalism is judged last, once later reverts have marked what they undid
+	for i, rev := range revs {
+		c := &result[i]
+		if c.Class != EditAddition && c.Class != EditRemoval {
+			continue
+		}
+		suspicious := rev.UserID == nil || rev.Comment == ""
+		blanking := i > 0 && revs[i-1].Size >= blankingMinSize &&
+			float64(rev.Size) <= float64(revs[i-1].Size)*blankingRatio &&
+			redirectTarget(rev.Content) == ""
+		if (c.Reverted && (suspicious || blanking)) || (blanking && suspicious) {
+			c.Class = EditVandalismLikely
+		}
+	}
+
+	return result
+}
+
+// classifyPageRevisions loads every revision of the page titled title and
+// classifies it, oldest first.
+func classifyPageRevisions(ctx context.Context, db querier, scope wikiScope, title string, placeholder func(n int) string) ([]RevisionClassification, error) {
+	page, err := resolveTitle(ctx, db, scope, title, false, placeholder)
+	if err != nil {
+		return nil, err
+	}
+
+	revs, err := loadRevisionsForClassification(ctx, db, page.ID, placeholder)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return classifyRevisions(revs), nil
+}
+
+// loadRevisionsForClassification reads the fields classifyRevisions uses of
+// every revision of a page, oldest first.
+func loadRevisionsForClassification(ctx context.Context, db querier, pageID int64, placeholder func(n int) string) ([]Revision, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT revision_id, timestamp, user, user_id, comment, content, size, sha1
+		FROM revisions
+		WHERE page_id = `+placeholder(1)+`
+		ORDER BY timestamp, revision_id
+	`, pageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revs []Revision
+	for rows.Next() {
+		var rev Revision
+		var user, comment sql.NullString
+		var userID sql.NullInt64
+		if err := rows.Scan(&rev.ID, &rev.Timestamp, &user, &userID, &comment, &rev.Content, &rev.Size, &rev.SHA1); err != nil {
+			return nil, err
+		}
+		rev.PageID = pageID
+		rev.User = user.String
+		rev.Comment = comment.String
+		if userID.Valid {
+			uid := int(userID.Int64)
+			rev.UserID = &uid
+		}
+		revs = append(revs, rev)
+	}
+	return revs, rows.Err()
+}
+
+// ClassifyRevisions labels every revision of a page, oldest first.
+func (c *sqliteClient) ClassifyRevisions(ctx context.Context, title string) ([]RevisionClassification, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return classifyPageRevisions(ctx, c.db, c.wiki, title, placeholder)
+}
+
+// ClassifyRevisions labels every revision of a page, oldest first.
+func (c *postgresClient) ClassifyRevisions(ctx context.Context, title string) ([]RevisionClassification, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return classifyPageRevisions(ctx, c.db, c.wiki, title, placeholder)
+}
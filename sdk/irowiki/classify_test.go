@@ -0,0 +1,128 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_ClassifyRevisions tests labelling the edits of a page
+func TestSQLiteClient_ClassifyRevisions(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	intro := "Geffen is the city of magic. " + strings.Repeat("It lies west of Prontera. ", 4)
+	expanded := intro + "\n== Shops ==\nTool dealer."
+	reformatted := intro + "\n==Shops==\nTool  dealer."
+	trimmed := intro
+
+	revisions := []struct {
+		id      int64
+		user    string
+		userID  any
+		comment string
+		content string
+		sha1    string
+	}{
+		{300, "Editor", 1, "create", intro, "a"},
+		{301, "Editor", 1, "add shops", expanded, "b"},
+		{302, "127.0.0.1", nil, "", "lol", "c"},
+		{303, "Editor", 1, "rv", expanded, "b"},
+		{304, "Editor", 1, "tidy", reformatted, "d"},
+		{305, "Editor", 1, "remove shops", trimmed, "e"},
+		{306, "Admin", 2, "Undo revision 305 by Editor", reformatted + " Open daily.", "f"},
+	}
+
+	if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (10, 0, 'Geffen', 0)`); err != nil {
+		t.Fatalf("failed to add page: %v", err)
+	}
+	for i, r := range revisions {
+		_, err := tdb.DB.Exec(`
+			INSERT INTO revisions (revision_id, page_id, timestamp, user, user_id, comment, content, size, sha1)
+			VALUES (?, 10, datetime('2024-01-01', '+' || ? || ' days'), ?, ?, ?, ?, ?, ?)`,
+			r.id, i, r.user, r.userID, r.comment, r.content, len(r.content), r.sha1)
+		if err != nil {
+			t.Fatalf("failed to add revision %d: %v", r.id, err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	t.Run("labels each edit", func(t *testing.T) {
+		classes, err := client.ClassifyRevisions(ctx, "Geffen")
+		if err != nil {
+			t.Fatalf("ClassifyRevisions failed: %v", err)
+		}
+		if len(classes) != len(revisions) {
+			t.Fatalf("expected %d classifications, got %d", len(revisions), len(classes))
+		}
+
+		expected := []struct {
+			class    string
+			reverted bool
+		}{
+			{irowiki.EditAddition, false},
+			{irowiki.EditAddition, false},
+			{irowiki.EditVandalismLikely, true},
+			{irowiki.EditRevert, false},
+			{irowiki.EditFormatting, false},
+			{irowiki.EditRemoval, true},
+			{irowiki.EditRevert, false},
+		}
+		for i, want := range expected {
+			got := classes[i]
+			if got.RevisionID != revisions[i].id {
+				t.Errorf("classification %d: expected revision %d, got %d", i, revisions[i].id, got.RevisionID)
+			}
+			if got.Class != want.class {
+				t.Errorf("revision %d: expected class %q, got %q", got.RevisionID, want.class, got.Class)
+			}
+			if got.Reverted != want.reverted {
+				t.Errorf("revision %d: expected reverted=%v, got %v", got.RevisionID, want.reverted, got.Reverted)
+			}
+		}
+
+		if classes[3].RevertedTo != 301 {
+			t.Errorf("expected revision 303 to restore 301, got %d", classes[3].RevertedTo)
+		}
+		if classes[6].RevertedTo != 0 {
+			t.Errorf("expected partial undo to restore no revision, got %d", classes[6].RevertedTo)
+		}
+		if classes[2].SizeDelta != 3-len(expanded) {
+			t.Errorf("expected size delta %d, got %d", 3-len(expanded), classes[2].SizeDelta)
+		}
+	})
+
+	t.Run("aggregated in page stats", func(t *testing.T) {
+		stats, err := client.GetPageStatsEnhanced(ctx, "Geffen")
+		if err != nil {
+			t.Fatalf("GetPageStatsEnhanced failed: %v", err)
+		}
+		if stats.EditClasses[irowiki.EditRevert] != 2 || stats.RevertCount != 2 {
+			t.Errorf("expected 2 reverts, got %d (revert count %d)", stats.EditClasses[irowiki.EditRevert], stats.RevertCount)
+		}
+		if stats.EditClasses[irowiki.EditVandalismLikely] != 1 {
+			t.Errorf("expected 1 likely vandalism edit, got %d", stats.EditClasses[irowiki.EditVandalismLikely])
+		}
+		if stats.CosmeticEdits != 1 {
+			t.Errorf("expected 1 cosmetic edit, got %d", stats.CosmeticEdits)
+		}
+	})
+
+	t.Run("missing page", func(t *testing.T) {
+		_, err := client.ClassifyRevisions(ctx, "Nonexistent_Page")
+		if !errors.Is(err, irowiki.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
@@ -39,19 +39,139 @@ import (
 
 // Client provides methods to query wiki archive data.
 // All query methods accept a context for cancellation and timeout control.
-// The client is safe for concurrent use by multiple goroutines.
+// The client is safe for concurrent use by multiple goroutines, which is
+// how servers should use it: one client shares its connection pool among
+// all requests. Close may be called while other calls are running; those
+// finish, or fail with ErrClosed or ErrDatabaseError, and every call made
+// after Close fails with ErrClosed. Clients returned by Snapshot are the
+// exception and must not be shared.
+//
+// New capabilities of existing methods are added as variadic options, such
+// as GetPage's PageOption, so calls written against earlier versions keep
+// compiling. Backends whose GetPage predates its options adapt with FromV1;
+// new methods must still be implemented by every backend.
 type Client interface {
+	// GetPage retrieves the latest version of a page by title, configured
+	// by opts (WithContent, WithRedirects). The title may be in any Unicode
+	// normalization, mix spaces and underscores, start lower-case and have
+	// its namespace prefix when the archive stores the page without it; a
+	// page stored under the title as given comes first. Every method taking
+	// a page title finds the page this way.
+	// Returns ErrNotFound if the page doesn't exist.
+	GetPage(ctx context.Context, title string, opts ...PageOption) (*Page, error)
+
+	ClientBase
+}
+
+// ClientBase holds every Client method but GetPage, whose signature gained
+// options. It's shared by Client and ClientV1, and grows with Client.
+type ClientBase interface {
 	// Search performs a search across pages.
 	// Returns pages matching the search criteria with pagination support.
 	Search(ctx context.Context, opts SearchOptions) ([]SearchResult, error)
 
 	// SearchFullText performs full-text search across page content.
-	// Uses the database's full-text search capabilities for relevance ranking.
+	// Uses the database's full-text search capabilities for relevance ranking:
+	// FTS5 on SQLite, tsvector and tsquery on PostgreSQL.
 	SearchFullText(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error)
 
-	// GetPage retrieves the latest version of a page by title.
+	// SearchRevisions searches the content of all revisions, not just the latest.
+	// Every query term must appear in a revision for it to match. Set
+	// opts.GroupByPage to get one best-matching revision per page. With
+	// opts.TimeBudget, a search that runs out of time returns the results
+	// so far with a *PartialResultError to continue from.
+	SearchRevisions(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error)
+
+	// SearchComments searches the edit summaries of every revision, e.g.
+	// all edits mentioning "episode 14". Every term must occur in the
+	// summary; snippets highlight them there. SQLite archives with the
+	// revision_comments_fts index match stemmed words ("episodes" matches
+	// "episode"), others substrings.
+	SearchComments(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error)
+
+	// SearchPaged performs a title search and returns results with pagination metadata.
+	// Total is only computed when opts.IncludeTotalCount is set. When nothing
+	// matches, Suggestion holds a spell-corrected query that does.
+	SearchPaged(ctx context.Context, opts SearchOptions) (*PagedResult, error)
+
+	// SuggestTitles returns up to limit page titles starting with prefix
+	// (case-insensitive; 0 for default 10). If nothing matches, completions for a
+	// spell-corrected prefix are returned with Correction set.
+	SuggestTitles(ctx context.Context, prefix string, limit int) (*TitleSuggestions, error)
+
+	// GetPagesByTitle looks up many titles in a few batched queries and
+	// returns one TitleResolution per title, in order. Titles are matched
+	// as GetPage matches them; missing titles have a nil Page.
+	GetPagesByTitle(ctx context.Context, titles []string) ([]TitleResolution, error)
+
+	// GetPageHTML returns the source wiki's own rendering of a page, for
+	// archives scraped with --capture-html. Check PageHTML.Current: the HTML
+	// may render an older revision than the latest one.
+	// Returns ErrNotFound if the page doesn't exist or has no captured HTML.
+	GetPageHTML(ctx context.Context, title string) (*PageHTML, error)
+
+	// GetModuleDependencies returns the Scribunto (Lua) modules a page needs
+	// to render: those it invokes directly, those invoked by the templates it
+	// transcludes (recursively), and those the modules themselves require.
 	// Returns ErrNotFound if the page doesn't exist.
-	GetPage(ctx context.Context, title string) (*Page, error)
+	GetModuleDependencies(ctx context.Context, title string) ([]ModuleDependency, error)
+
+	// ExtractTemplates parses the latest revision of a page and returns its
+	// calls to a template, such as an item's {{Item}} infobox, in order,
+	// with their parameters. The template may be named with or without its
+	// "Template:" prefix. Templates aren't expanded, so calls a template
+	// makes itself aren't found. Returns ErrNotFound if the page doesn't
+	// exist, and no calls if it doesn't use the template.
+	ExtractTemplates(ctx context.Context, title, templateName string) ([]TemplateCall, error)
+
+	// GetOutgoingLinks lists the links from a page's latest revision: the
+	// pages it links to, the templates it transcludes, the files it embeds
+	// and its categories, ordered by type and target. TargetID is 0 for
+	// targets missing from the archive. Returns ErrNotFound if the page
+	// doesn't exist.
+	GetOutgoingLinks(ctx context.Context, title string) ([]PageLink, error)
+
+	// GetBacklinks lists the pages linking to a page, ordered by namespace
+	// and title, like Special:WhatLinksHere: those linking to it, and for
+	// templates, files and categories those transcluding, embedding or
+	// belonging to it. A page with no backlinks is an orphan. Returns
+	// ErrNotFound if the page doesn't exist.
+	GetBacklinks(ctx context.Context, title string) ([]PageLink, error)
+
+	// GetPageCategories lists the categories a page's latest revision puts
+	// it in with [[Category:...]] links, without the "Category:" prefix and
+	// sorted. Categories added by templates aren't seen. Returns
+	// ErrNotFound if the page doesn't exist.
+	GetPageCategories(ctx context.Context, title string) ([]string, error)
+
+	// GetPagesInCategory lists the pages in a category, named with or
+	// without its "Category:" prefix, ordered by depth, namespace and
+	// title. Subcategories are members too, and with opts.Depth their own
+	// members are listed after them; a page in several of the categories
+	// is listed once, at its shallowest depth. A category nothing is in,
+	// or that doesn't exist, has no members.
+	GetPagesInCategory(ctx context.Context, category string, opts CategoryOptions) ([]CategoryMember, error)
+
+	// ResolveAmbiguousTitle looks up a title, following a redirect, and when
+	// it leads to a disambiguation page lists the pages it links to with
+	// their descriptions, so a search for "Knight" can offer the class, the
+	// monster and the card. Returns ErrNotFound if the page doesn't exist.
+	ResolveAmbiguousTitle(ctx context.Context, title string) (*AmbiguousTitle, error)
+
+	// GetRedirectsTo lists the redirects pointing to a page, ordered by
+	// title. Returns ErrNotFound if the page doesn't exist.
+	GetRedirectsTo(ctx context.Context, title string) ([]Page, error)
+
+	// FindDoubleRedirects lists redirects pointing to another redirect,
+	// ordered by title, like Special:DoubleRedirects. A limit of 0 uses
+	// the default (100).
+	FindDoubleRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error)
+
+	// FindBrokenRedirects lists redirects pointing to a page that isn't in
+	// the archive, ordered by title, like Special:BrokenRedirects. Targets
+	// in namespaces the scrape skipped are reported too. A limit of 0 uses
+	// the default (100).
+	FindBrokenRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error)
 
 	// GetPageByID retrieves the latest version of a page by ID.
 	// Returns ErrNotFound if the page doesn't exist.
@@ -69,6 +189,10 @@ type Client interface {
 	// Returns ErrNotFound if the revision doesn't exist.
 	GetRevision(ctx context.Context, revisionID int64) (*Revision, error)
 
+	// GetRevisionsByID retrieves many revisions in a few batched queries and
+	// returns one entry per ID, in order. Missing revisions are nil.
+	GetRevisionsByID(ctx context.Context, ids []int64) ([]*Revision, error)
+
 	// GetPageAtTime retrieves the page content as it existed at a specific timestamp.
 	// Returns the most recent revision at or before the specified time.
 	// Returns ErrNotFound if the page didn't exist at that time.
@@ -78,6 +202,19 @@ type Client interface {
 	// Useful for analyzing editing activity over a period.
 	GetChangesByPeriod(ctx context.Context, start, end time.Time) ([]Revision, error)
 
+	// GetChangesByPeriodGrouped summarizes the revisions within a time range
+	// per page (revision count, net size delta, editors) or per editor,
+	// most revisions first, computed by the database instead of returning
+	// every revision.
+	// Returns ErrInvalidInput for unknown groupings or a negative limit.
+	GetChangesByPeriodGrouped(ctx context.Context, start, end time.Time, opts ChangesGroupOptions) ([]ChangeGroup, error)
+
+	// GetRecentChanges returns edits in revision order with the titles and
+	// size changes a recent changes feed shows. Poll with the last
+	// RevisionID seen as opts.AfterRevisionID to follow an archive as
+	// incremental scrapes add to it.
+	GetRecentChanges(ctx context.Context, opts ChangesOptions) ([]Change, error)
+
 	// GetEditorActivity retrieves all revisions by a specific user within a time range.
 	// Use for contributor analysis and statistics.
 	GetEditorActivity(ctx context.Context, username string, start, end time.Time) ([]Revision, error)
@@ -98,10 +235,47 @@ type Client interface {
 	// Includes contributor details, size trends, quality metrics, and activity patterns.
 	GetPageStatsEnhanced(ctx context.Context, title string) (*PageStatisticsEnhanced, error)
 
+	// GetPageContributorsTimeline lists the editors of a page and their edit
+	// counts for each month with edits, showing how its upkeep changed hands.
+	// Returns ErrNotFound if the page doesn't exist.
+	GetPageContributorsTimeline(ctx context.Context, title string) (*PageContributorsTimeline, error)
+
+	// GetPageCreationCohorts groups pages by the month of their first
+	// revision, oldest first, with how many were still edited 1, 2 and 5
+	// years later (see CohortYears), to study how the wiki grew.
+	GetPageCreationCohorts(ctx context.Context) ([]PageCohort, error)
+
+	// GetQualityOverview scores the latest revision of every article and
+	// aggregates the scores by namespace, with histograms, stub counts and
+	// the worst and best pages, for maintenance dashboards.
+	GetQualityOverview(ctx context.Context) (*QualityOverview, error)
+
+	// SuggestCategories proposes up to n categories (0 for default 10) for a
+	// poorly categorized page, from the categories of the categorized pages
+	// sharing its templates, best first. Categories the page is already in
+	// aren't proposed. Pages without templates, and archives loaded without
+	// links, give an empty slice. Returns ErrNotFound if the page doesn't exist.
+	SuggestCategories(ctx context.Context, title string, n int) ([]CategorySuggestion, error)
+
 	// GetEditorActivityEnhanced retrieves enhanced activity analysis for an editor.
 	// Includes content statistics, activity patterns, and top pages edited.
 	GetEditorActivityEnhanced(ctx context.Context, username string, start, end time.Time) (*EditorActivity, error)
 
+	// GetEditorActivityWithOptions retrieves the sections of an editor's
+	// activity selected by opts, skipping the aggregations of the others.
+	// Returns ErrNotFound if the editor has no edits in the range.
+	GetEditorActivityWithOptions(ctx context.Context, username string, opts EditorActivityOptions) (*EditorActivity, error)
+
+	// GetTopEditorsForScope ranks the editors of a namespace, a category or
+	// both by edit count, returning at most n (default 10). Pages in no
+	// matching namespace or category give an empty slice.
+	GetTopEditorsForScope(ctx context.Context, scope EditorScope, n int) ([]EditorStat, error)
+
+	// GetActivityHeatmap counts the edits of an editor, a page or the whole
+	// wiki by day of week and hour of day (UTC), as a 7x24 matrix.
+	// Returns ErrNotFound if scope names a page that doesn't exist.
+	GetActivityHeatmap(ctx context.Context, scope ActivityScope) (*ActivityHeatmap, error)
+
 	// GetRevisionDiff computes the diff between two revisions.
 	// Returns a unified diff showing additions and removals.
 	// Returns ErrNotFound if either revision doesn't exist.
@@ -113,6 +287,17 @@ type Client interface {
 	// Returns ErrNotFound if the revision doesn't exist or has no parent.
 	GetConsecutiveDiff(ctx context.Context, revID int64) (*DiffResult, error)
 
+	// CompareRevisions computes the diff between two revisions like
+	// GetRevisionDiff, ignoring whitespace or markup-only changes as opts
+	// selects.
+	CompareRevisions(ctx context.Context, fromRevID, toRevID int64, opts DiffOptions) (*DiffResult, error)
+
+	// ClassifyRevisions labels every revision of a page, oldest first, as a
+	// content addition, removal, revert, formatting change, or likely
+	// vandalism, from size changes, restored content and edit summaries.
+	// Returns ErrNotFound if the page doesn't exist.
+	ClassifyRevisions(ctx context.Context, title string) ([]RevisionClassification, error)
+
 	// GetFile retrieves file metadata by filename.
 	// Returns ErrNotFound if the file doesn't exist.
 	GetFile(ctx context.Context, filename string) (*File, error)
@@ -121,12 +306,49 @@ type Client interface {
 	// Use offset and limit for pagination. Set limit to 0 for default (100).
 	ListFiles(ctx context.Context, offset, limit int) ([]File, error)
 
+	// GetExternalLinks returns the external links of a page, including
+	// liveness results and archived copies recorded by Store.CheckExternalLinks.
+	// Returns ErrNotFound if the page doesn't exist.
+	GetExternalLinks(ctx context.Context, title string) ([]ExternalLink, error)
+
+	// GetArchiveInfo returns metadata describing the archive's source wiki,
+	// license, and the scraper that produced it.
+	// Returns ErrNotFound if the archive predates archive metadata.
+	GetArchiveInfo(ctx context.Context) (*ArchiveInfo, error)
+
+	// ListWikis returns the wikis of the archive: the primary wiki, with ID "",
+	// followed by sister wikis merged in with the scraper's merge-wiki command.
+	// Select one with ConnectionOptions.WikiID.
+	ListWikis(ctx context.Context) ([]Wiki, error)
+
+	// GetEquivalentPage returns the article on targetWiki covering the same
+	// subject as title on wikiID, such as the classic article of a renewal
+	// monster. Interwiki links recorded by merge-wiki are followed in either
+	// direction before falling back to a page with the same title.
+	// Returns ErrNotFound if either page doesn't exist, and ErrInvalidInput
+	// for unknown wikis or when both wikis are the same.
+	GetEquivalentPage(ctx context.Context, wikiID, title, targetWiki string) (*Page, error)
+
+	// ExportRows streams a table to w, writing the column names first and then
+	// one record per row, without loading the table into memory.
+	// Returns ErrInvalidInput for unknown tables, columns, or filter operators.
+	ExportRows(ctx context.Context, opts ExportOptions, w RowWriter) error
+
+	// Snapshot returns a client whose reads all see the archive as it was
+	// when Snapshot was called, for composite operations (reports, exports
+	// across several tables) that must stay consistent while a scraper
+	// writes to the archive. It holds a read transaction (repeatable read
+	// on PostgreSQL) until closed, and is not safe for concurrent use.
+	// Returns ErrInvalidInput if the client is already a snapshot.
+	Snapshot(ctx context.Context) (Client, error)
+
 	// Ping checks if the database connection is alive.
 	// Use for health checks and connection validation.
 	Ping(ctx context.Context) error
 
-	// Close cleanly shuts down the client and releases resources.
-	// After calling Close, the client should not be used.
+	// Close cleanly shuts down the client and releases resources, waiting
+	// for queries in progress to finish. Calls made after Close return
+	// ErrClosed, as does a second Close.
 	Close() error
 }
 
@@ -135,6 +357,12 @@ type SearchOptions struct {
 	// Query is the search term. For full-text search, supports wildcards.
 	Query string
 
+	// MatchMode selects how title searches match Query against titles:
+	// "substring" (default), "prefix", "exact" or "regex" (RE2 syntax for
+	// SQLite, POSIX for PostgreSQL). Prefix, exact and regex matches ignore
+	// case. Full-text searches ignore it.
+	MatchMode string
+
 	// Namespace filters results to a specific namespace.
 	// Use -1 to search across all namespaces (default: -1).
 	Namespace int
@@ -173,6 +401,19 @@ type SearchOptions struct {
 	// ExcludeRedirects excludes redirect pages from results.
 	ExcludeRedirects bool
 
+	// CollapseRedirects lists matching redirects as the page they point to,
+	// once however many redirects lead there, with SearchResult.RedirectedFrom
+	// set. Redirects to pages missing from the archive are listed as is.
+	// Title searches only.
+	CollapseRedirects bool
+
+	// IncludeDeleted includes pages deleted from the live wiki, which are
+	// hidden unless the client was opened with ConnectionOptions.IncludeDeleted.
+	IncludeDeleted bool
+
+	// OnlyDeleted returns only pages deleted from the live wiki.
+	OnlyDeleted bool
+
 	// SortBy specifies the sort field: "relevance", "title", "date", "size".
 	// Default: "relevance" for searches, "title" for listings.
 	SortBy string
@@ -181,6 +422,11 @@ type SearchOptions struct {
 	// Default: "desc" for relevance/date, "asc" for title.
 	SortOrder string
 
+	// Collation selects how titles sort with SortBy "title": CollationBinary
+	// (default) by code point, or CollationUnicode with accented letters
+	// next to their base letters. PostgreSQL needs ICU for CollationUnicode.
+	Collation string
+
 	// MinScore filters results with relevance score >= this value.
 	// Only applies to full-text searches.
 	MinScore float64
@@ -194,6 +440,17 @@ type SearchOptions struct {
 
 	// IncludeTotalCount computes the total result count (may be expensive).
 	IncludeTotalCount bool
+
+	// Proximity requires terms to appear near each other (full-text search only).
+	// It is combined with the query using AND; the query may be empty if set.
+	// Queries may also use the "drops NEAR/5 card" syntax directly.
+	// PostgreSQL clients return ErrNotSupported for proximity searches.
+	Proximity *Proximity
+
+	// SnippetLength is the maximum length of generated snippets in bytes.
+	// Set to 0 for default (200). Ignored by SQLite full-text search,
+	// which uses the FTS5 snippet() function.
+	SnippetLength int
 }
 
 // HistoryOptions configures history queries.
@@ -210,4 +467,9 @@ type HistoryOptions struct {
 	// Limit is the maximum number of results to return.
 	// Set to 0 for default limit (100).
 	Limit int
+
+	// ExcludeContent leaves Revision.Content empty, skipping the wikitext of
+	// every revision when only the metadata is needed. Size still reports
+	// the content length.
+	ExcludeContent bool
 }
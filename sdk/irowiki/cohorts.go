@@ -0,0 +1,138 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CohortYears are the horizons of PageCohort.Surviving, in years.
+var CohortYears = []int{1, 2, 5}
+
+// PageCohort is the pages created in one month and how many of them were
+// still being edited years later.
+type PageCohort struct {
+	// Month is the creation month, "YYYY-MM" in UTC.
+	Month string `json:"month"`
+
+	// Pages is the number of pages created that month.
+	Pages int `json:"pages"`
+
+	// Surviving maps each of CohortYears to the number of the cohort's
+	// pages edited at least that many years after their creation.
+	// Horizons the archive's newest edit hasn't reached for the whole
+	// month are left out.
+	Surviving map[int]int `json:"surviving"`
+}
+
+// cohortDialect holds a backend's expressions for cohort queries.
+type cohortDialect struct {
+	// timestamp converts r.timestamp to a value that orders correctly.
+	timestamp string
+
+	// month formats a timestamp value as "YYYY-MM".
+	month func(col string) string
+
+	// addYears adds n years to a timestamp value.
+	addYears func(col string, n int) string
+
+	// format formats a timestamp value as "2006-01-02 15:04:05".
+	format func(col string) string
+}
+
+// getPageCreationCohorts groups pages by the month of their first revision,
+// counting in SQL those with a revision each of CohortYears later.
+func getPageCreationCohorts(ctx context.Context, db querier, scope wikiScope, d cohortDialect) ([]PageCohort, error) {
+	spans := `
+		SELECT r.page_id, MIN(` + d.timestamp + `) AS created, MAX(` + d.timestamp + `) AS last_edit
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.timestamp IS NOT NULL` + scope.filter("p") + `
+		GROUP BY r.page_id`
+
+	var newestStr sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT "+d.format("MAX(last_edit)")+" FROM ("+spans+") spans").Scan(&newestStr); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	cohorts := []PageCohort{}
+	if !newestStr.Valid {
+		return cohorts, nil
+	}
+	newest, err := time.Parse("2006-01-02 15:04:05", newestStr.String)
+	if err != nil {
+		return nil, fmt.Errorf("%w: newest edit %q: %v", ErrDatabaseError, newestStr.String, err)
+	}
+
+	query := "SELECT " + d.month("created") + ", COUNT(*)"
+	for _, n := range CohortYears {
+		query += ", SUM(CASE WHEN last_edit >= " + d.addYears("created", n) + " THEN 1 ELSE 0 END)"
+	}
+	query += " FROM (" + spans + ") spans GROUP BY 1 ORDER BY 1"
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var month sql.NullString
+		var pages int
+		surviving := make([]int, len(CohortYears))
+		dest := []interface{}{&month, &pages}
+		for i := range surviving {
+			dest = append(dest, &surviving[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		// Timestamps the backend can't parse have no month
+		start, err := time.Parse("2006-01", month.String)
+		if err != nil {
+			continue
+		}
+
+		cohort := PageCohort{Month: month.String, Pages: pages, Surviving: make(map[int]int)}
+		for i, n := range CohortYears {
+			if !start.AddDate(n, 1, 0).After(newest) {
+				cohort.Surviving[n] = surviving[i]
+			}
+		}
+		cohorts = append(cohorts, cohort)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return cohorts, nil
+}
+
+// GetPageCreationCohorts groups pages by creation month.
+func (c *sqliteClient) GetPageCreationCohorts(ctx context.Context) ([]PageCohort, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	// Julian day numbers order timestamps of every stored format
+	return getPageCreationCohorts(ctx, c.db, c.wiki, cohortDialect{
+		timestamp: "julianday(substr(r.timestamp, 1, 19))",
+		month:     func(col string) string { return "strftime('%Y-%m', " + col + ")" },
+		addYears:  func(col string, n int) string { return "julianday(" + col + ", '+" + strconv.Itoa(n) + " years')" },
+		format:    func(col string) string { return "strftime('%Y-%m-%d %H:%M:%S', " + col + ")" },
+	})
+}
+
+// GetPageCreationCohorts groups pages by creation month.
+func (c *postgresClient) GetPageCreationCohorts(ctx context.Context) ([]PageCohort, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	return getPageCreationCohorts(ctx, c.db, c.wiki, cohortDialect{
+		timestamp: "r.timestamp",
+		month:     func(col string) string { return "to_char(" + col + ", 'YYYY-MM')" },
+		addYears:  func(col string, n int) string { return col + " + interval '" + strconv.Itoa(n) + " years'" },
+		format:    func(col string) string { return "to_char(" + col + ", 'YYYY-MM-DD HH24:MI:SS')" },
+	})
+}
@@ -0,0 +1,78 @@
+package irowiki_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetPageCreationCohorts tests grouping pages by creation
+// month with their survival
+func TestSQLiteClient_GetPageCreationCohorts(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// The fixture's five pages were created in January 2020. Main_Page is
+	// edited a year and a half later, Prontera exactly two years later, and
+	// Izlude is created in March 2021
+	for _, stmt := range []string{
+		`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (6, 0, 'Izlude', 0)`,
+		`INSERT INTO revisions (revision_id, page_id, timestamp, user, user_id, comment, content, size, sha1, minor) VALUES
+			(107, 1, '2021-06-01 12:00:00', 'Admin', 1, 'edit', 'Welcome', 7, 'x', 0),
+			(108, 2, '2022-01-03T00:00:00Z', 'Admin', 1, 'edit', 'Prontera', 8, 'x', 0),
+			(109, 6, '2021-03-15 08:00:00', 'Admin', 1, 'edit', 'Izlude', 6, 'x', 0)`,
+	} {
+		if _, err := tdb.DB.Exec(stmt); err != nil {
+			t.Fatalf("failed to insert test data: %v", err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	cohorts, err := client.GetPageCreationCohorts(context.Background())
+	if err != nil {
+		t.Fatalf("GetPageCreationCohorts failed: %v", err)
+	}
+
+	// The newest edit is in January 2022: the 2020 cohort has had a year
+	// but not two, and the 2021 cohort not yet a year
+	want := []irowiki.PageCohort{
+		{Month: "2020-01", Pages: 5, Surviving: map[int]int{1: 2}},
+		{Month: "2021-03", Pages: 1, Surviving: map[int]int{}},
+	}
+	if !reflect.DeepEqual(cohorts, want) {
+		t.Errorf("expected %+v, got %+v", want, cohorts)
+	}
+}
+
+// TestSQLiteClient_GetPageCreationCohorts_Empty tests an archive without
+// revisions
+func TestSQLiteClient_GetPageCreationCohorts_Empty(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	if _, err := tdb.DB.Exec(`DELETE FROM revisions`); err != nil {
+		t.Fatalf("failed to delete revisions: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	cohorts, err := client.GetPageCreationCohorts(context.Background())
+	if err != nil {
+		t.Fatalf("GetPageCreationCohorts failed: %v", err)
+	}
+	if len(cohorts) != 0 {
+		t.Errorf("expected no cohorts, got %+v", cohorts)
+	}
+}
@@ -0,0 +1,67 @@
+package irowiki
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+	"modernc.org/sqlite"
+)
+
+// Title collations for SearchOptions.Collation.
+const (
+	// CollationBinary sorts titles by code point, so "Éclage" sorts after
+	// "Zeny". The default.
+	CollationBinary = "binary"
+
+	// CollationUnicode sorts titles in the root order of the Unicode
+	// Collation Algorithm, which compares letters before accents and case,
+	// so "Éclage" sorts with the titles starting with "E".
+	CollationUnicode = "unicode"
+)
+
+// sqliteUnicodeCollation is the SQLite collation implementing
+// CollationUnicode, registered for every connection.
+const sqliteUnicodeCollation = "IROWIKI_UNICODE"
+
+// postgresUnicodeCollation is PostgreSQL's ICU root collation, which
+// implements CollationUnicode on servers built with ICU.
+const postgresUnicodeCollation = `"und-x-icu"`
+
+// collators are root collators; a Collator isn't safe for concurrent use.
+var collators = sync.Pool{New: func() any { return collate.New(language.Und) }}
+
+func init() {
+	sqlite.MustRegisterCollationUtf8(sqliteUnicodeCollation, compareTitles)
+}
+
+// compareTitles compares titles with CollationUnicode. Titles the collation
+// ranks equal, such as those differing only in normalization, are ordered
+// by code point so the order is total.
+func compareTitles(a, b string) int {
+	c := collators.Get().(*collate.Collator)
+	defer collators.Put(c)
+	if n := c.CompareString(a, b); n != 0 {
+		return n
+	}
+	return strings.Compare(a, b)
+}
+
+// validateCollation checks a SearchOptions.Collation.
+func validateCollation(collation string) error {
+	switch collation {
+	case "", CollationBinary, CollationUnicode:
+		return nil
+	}
+	return fmt.Errorf("invalid collation: must be '%s' or '%s'", CollationBinary, CollationUnicode)
+}
+
+// nfc returns s in Unicode Normalization Form C, the form MediaWiki stores
+// titles and text in, so that an "é" typed as "e" and a combining accent
+// matches the precomposed "é" of the archive.
+func nfc(s string) string {
+	return norm.NFC.String(s)
+}
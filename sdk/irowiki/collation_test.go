@@ -0,0 +1,101 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_Search_Collation tests sorting accented titles with
+// their base letters
+func TestSQLiteClient_Search_Collation(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES
+		(6, 14, 'Zeny', 0), (7, 14, 'Éclage', 0), (8, 14, 'Eden', 0), (9, 14, 'Fild', 0)`); err != nil {
+		t.Fatalf("failed to insert pages: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	titles := func(collation string) string {
+		t.Helper()
+		results, err := client.Search(ctx, irowiki.SearchOptions{Namespace: 14, SortBy: "title", Collation: collation})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Title)
+		}
+		return strings.Join(got, " ")
+	}
+
+	if got := titles(""); got != "Eden Fild Zeny Éclage" {
+		t.Errorf("expected code point order by default, got %q", got)
+	}
+	if got := titles(irowiki.CollationUnicode); got != "Éclage Eden Fild Zeny" {
+		t.Errorf("expected Éclage with the E titles, got %q", got)
+	}
+	if _, err := client.Search(ctx, irowiki.SearchOptions{Collation: "klingon"}); err == nil {
+		t.Error("expected an error for an unknown collation")
+	}
+}
+
+// TestSQLiteClient_GetPage_Normalization tests finding titles typed with
+// combining accents
+func TestSQLiteClient_GetPage_Normalization(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (6, 0, 'Éclage', 0)`); err != nil {
+		t.Fatalf("failed to insert page: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	// "E" followed by a combining acute accent
+	decomposed := "E\u0301clage"
+	page, err := client.GetPage(ctx, decomposed)
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.ID != 6 {
+		t.Errorf("expected Éclage, got %+v", page)
+	}
+
+	results, err := client.GetPagesByTitle(ctx, []string{"e\u0301clage"})
+	if err != nil {
+		t.Fatalf("GetPagesByTitle failed: %v", err)
+	}
+	if !results[0].Found() || results[0].Title != "Éclage" {
+		t.Errorf("expected the decomposed title to resolve, got %+v", results[0])
+	}
+
+	search, err := client.Search(ctx, irowiki.SearchOptions{Query: decomposed})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(search) != 1 || search[0].PageID != 6 {
+		t.Errorf("expected the decomposed query to find Éclage, got %+v", search)
+	}
+
+	if _, err := client.GetPage(ctx, "Eclage"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected accents to still matter for lookups, got %v", err)
+	}
+}
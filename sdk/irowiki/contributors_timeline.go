@@ -0,0 +1,103 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PageContributorsTimeline lists who edited a page in each month of its
+// history, to follow how its upkeep passed between editors.
+type PageContributorsTimeline struct {
+	PageID int64  `json:"page_id"`
+	Title  string `json:"title"`
+
+	// Months holds the months with edits, oldest first. Months without
+	// edits are left out.
+	Months []ContributorMonth `json:"months"`
+}
+
+// ContributorMonth is one month of a PageContributorsTimeline.
+type ContributorMonth struct {
+	// Month is "YYYY-MM", in UTC.
+	Month string `json:"month"`
+
+	// Edits is the number of edits that month.
+	Edits int `json:"edits"`
+
+	// Editors are the month's editors, most edits first. Edits with a
+	// hidden username are counted under "".
+	Editors []EditorCount `json:"editors"`
+}
+
+// EditorCount is an editor's number of edits.
+type EditorCount struct {
+	Username  string `json:"username"`
+	EditCount int    `json:"edit_count"`
+}
+
+// getPageContributorsTimeline counts the edits of title by month and editor
+// in a single GROUP BY. month is the backend's expression extracting "YYYY-MM"
+// from r.timestamp.
+func getPageContributorsTimeline(ctx context.Context, db querier, scope wikiScope, title, month string, placeholder func(n int) string) (*PageContributorsTimeline, error) {
+	page, err := resolveTitle(ctx, db, scope, title, false, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	timeline := &PageContributorsTimeline{PageID: page.ID, Title: page.Title, Months: []ContributorMonth{}}
+
+	query := `
+		SELECT ` + month + `, COALESCE(r.user, ''), COUNT(*)
+		FROM revisions r
+		WHERE r.page_id = ` + placeholder(1) + ` AND r.timestamp IS NOT NULL
+		GROUP BY 1, 2
+		ORDER BY 1, 3 DESC, 2`
+
+	rows, err := db.QueryContext(ctx, query, timeline.PageID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m sql.NullString
+		var editor EditorCount
+		if err := rows.Scan(&m, &editor.Username, &editor.EditCount); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		// Timestamps the backend can't parse have no month
+		if !m.Valid || m.String == "" {
+			continue
+		}
+		if n := len(timeline.Months); n == 0 || timeline.Months[n-1].Month != m.String {
+			timeline.Months = append(timeline.Months, ContributorMonth{Month: m.String})
+		}
+		current := &timeline.Months[len(timeline.Months)-1]
+		current.Edits += editor.EditCount
+		current.Editors = append(current.Editors, editor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return timeline, nil
+}
+
+// GetPageContributorsTimeline lists a page's editors month by month.
+func (c *sqliteClient) GetPageContributorsTimeline(ctx context.Context, title string) (*PageContributorsTimeline, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getPageContributorsTimeline(ctx, c.db, c.wiki, title, sqliteMonth, placeholder)
+}
+
+// GetPageContributorsTimeline lists a page's editors month by month.
+func (c *postgresClient) GetPageContributorsTimeline(ctx context.Context, title string) (*PageContributorsTimeline, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getPageContributorsTimeline(ctx, c.db, c.wiki, title, postgresMonth, placeholder)
+}
@@ -0,0 +1,65 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetPageContributorsTimeline tests listing a page's
+// editors month by month
+func TestSQLiteClient_GetPageContributorsTimeline(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Admin and Editor each edited Main_Page in January 2020; Editor takes
+	// over in February, and someone hidden edits in March
+	for _, rev := range []struct {
+		id   int64
+		ts   string
+		user interface{}
+	}{
+		{107, "2020-02-03 10:00:00", "Editor"},
+		{108, "2020-02-20T08:00:00Z", "Editor"},
+		{109, "2020-02-21 09:00:00", "Admin"},
+		{110, "2020-03-01 00:00:00", nil},
+	} {
+		if _, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+			VALUES (?, 1, ?, ?, NULL, 'edit', 'Welcome', 7, 'x', 0)`, rev.id, rev.ts, rev.user); err != nil {
+			t.Fatalf("failed to insert revision: %v", err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	timeline, err := client.GetPageContributorsTimeline(ctx, "Main_Page")
+	if err != nil {
+		t.Fatalf("GetPageContributorsTimeline failed: %v", err)
+	}
+	if timeline.PageID != 1 || timeline.Title != "Main_Page" {
+		t.Errorf("unexpected page: %d %s", timeline.PageID, timeline.Title)
+	}
+
+	want := []irowiki.ContributorMonth{
+		{Month: "2020-01", Edits: 2, Editors: []irowiki.EditorCount{{"Admin", 1}, {"Editor", 1}}},
+		{Month: "2020-02", Edits: 3, Editors: []irowiki.EditorCount{{"Editor", 2}, {"Admin", 1}}},
+		{Month: "2020-03", Edits: 1, Editors: []irowiki.EditorCount{{"", 1}}},
+	}
+	if !reflect.DeepEqual(timeline.Months, want) {
+		t.Errorf("expected %+v, got %+v", want, timeline.Months)
+	}
+
+	// Test: Unknown pages are not found
+	if _, err := client.GetPageContributorsTimeline(ctx, "Nonexistent"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package irowiki
+
+import "fmt"
+
+// deletedMode selects which pages a query matches by their deletion state.
+type deletedMode int
+
+const (
+	// hideDeleted matches pages that still exist on the live wiki.
+	hideDeleted deletedMode = iota
+
+	// includeDeleted matches every page.
+	includeDeleted
+
+	// onlyDeleted matches pages deleted from the live wiki.
+	onlyDeleted
+)
+
+// newDeletedMode returns the mode for IncludeDeleted and OnlyDeleted
+// options; OnlyDeleted takes precedence.
+func newDeletedMode(include, only bool) deletedMode {
+	switch {
+	case only:
+		return onlyDeleted
+	case include:
+		return includeDeleted
+	default:
+		return hideDeleted
+	}
+}
+
+// forSearch returns the scope of a search, whose options may include
+// deleted pages or select only them.
+func (s wikiScope) forSearch(opts SearchOptions) wikiScope {
+	if mode := newDeletedMode(opts.IncludeDeleted, opts.OnlyDeleted); mode != hideDeleted {
+		s.deleted = mode
+	}
+	return s
+}
+
+// deletedFilter returns a condition, starting with " AND ", limiting the
+// pages table aliased as alias by deletion state, or "" for every page.
+// Archives without deleted_pages have no deleted pages.
+func (s wikiScope) deletedFilter(alias string) string {
+	switch {
+	case s.deleted == includeDeleted:
+		return ""
+	case !s.tombstones && s.deleted == onlyDeleted:
+		return " AND 1 = 0"
+	case !s.tombstones:
+		return ""
+	case s.deleted == onlyDeleted:
+		return fmt.Sprintf(" AND %s.page_id IN (SELECT page_id FROM deleted_pages)", alias)
+	default:
+		return fmt.Sprintf(" AND %s.page_id NOT IN (SELECT page_id FROM deleted_pages)", alias)
+	}
+}
+
+// deletedColumn returns an expression for Page.Deleted of the pages table
+// aliased as alias.
+func (s wikiScope) deletedColumn(alias string) string {
+	if !s.tombstones {
+		return "FALSE"
+	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM deleted_pages d WHERE d.page_id = %s.page_id)", alias)
+}
@@ -0,0 +1,112 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// searchTitles returns the titles found by a title search.
+func searchTitles(t *testing.T, client irowiki.Client, opts irowiki.SearchOptions) []string {
+	t.Helper()
+
+	results, err := client.Search(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.Title)
+	}
+	return titles
+}
+
+// TestSQLiteClient_DeletedPages tests hiding and selecting pages deleted from the live wiki
+func TestSQLiteClient_DeletedPages(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	ctx := context.Background()
+
+	// Test: Archives without tombstones behave as before
+	client, err := irowiki.OpenSQLiteWithOptions(tdb.Path, irowiki.ConnectionOptions{OnlyDeleted: true})
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	if titles := searchTitles(t, client, irowiki.SearchOptions{Query: "Poring", Namespace: -1}); len(titles) != 0 {
+		t.Errorf("expected no deleted pages, got %v", titles)
+	}
+	client.Close()
+
+	_, err = tdb.DB.Exec(`CREATE TABLE deleted_pages (page_id INTEGER PRIMARY KEY, deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO deleted_pages (page_id) VALUES (3)`)
+	if err != nil {
+		t.Fatalf("failed to add tombstones: %v", err)
+	}
+
+	client, err = irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	// Test: Deleted pages are hidden by default
+	if _, err := client.GetPage(ctx, "Poring"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a deleted page, got %v", err)
+	}
+	pages, err := client.ListPages(ctx, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	for _, p := range pages {
+		if p.ID == 3 {
+			t.Errorf("expected ListPages to hide the deleted page")
+		}
+	}
+	if titles := searchTitles(t, client, irowiki.SearchOptions{Query: "o", Namespace: -1}); slices.Contains(titles, "Poring") {
+		t.Errorf("expected search to hide the deleted page, got %v", titles)
+	}
+
+	// Test: Lookups by ID still find deleted pages, marked as such
+	page, err := client.GetPageByID(ctx, 3)
+	if err != nil || !page.Deleted {
+		t.Errorf("expected deleted page 3, got %+v, %v", page, err)
+	}
+	if page, err := client.GetPageByID(ctx, 2); err != nil || page.Deleted {
+		t.Errorf("expected live page 2, got %+v, %v", page, err)
+	}
+
+	// Test: Searches can include deleted pages or select only them
+	if titles := searchTitles(t, client, irowiki.SearchOptions{Query: "o", Namespace: -1, IncludeDeleted: true}); !slices.Contains(titles, "Poring") || !slices.Contains(titles, "Prontera") {
+		t.Errorf("expected live and deleted pages, got %v", titles)
+	}
+	if titles := searchTitles(t, client, irowiki.SearchOptions{Query: "o", Namespace: -1, OnlyDeleted: true}); len(titles) != 1 || titles[0] != "Poring" {
+		t.Errorf("expected only Poring, got %v", titles)
+	}
+
+	// Test: Clients can include deleted pages in every lookup
+	all, err := irowiki.OpenSQLiteWithOptions(tdb.Path, irowiki.ConnectionOptions{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer all.Close()
+	page, err = all.GetPage(ctx, "Poring")
+	if err != nil || !page.Deleted {
+		t.Errorf("expected deleted Poring, got %+v, %v", page, err)
+	}
+
+	// Test: Clients can list only deleted pages
+	deleted, err := irowiki.OpenSQLiteWithOptions(tdb.Path, irowiki.ConnectionOptions{OnlyDeleted: true})
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer deleted.Close()
+	pages, err = deleted.ListPages(ctx, 0, 0, 0)
+	if err != nil || len(pages) != 1 || pages[0].Title != "Poring" {
+		t.Errorf("expected only Poring, got %v, %v", pages, err)
+	}
+}
@@ -5,10 +5,18 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
 )
 
 // GetRevisionDiff computes the diff between two revisions.
 func (c *sqliteClient) GetRevisionDiff(ctx context.Context, fromRevID, toRevID int64) (*DiffResult, error) {
+	return c.CompareRevisions(ctx, fromRevID, toRevID, DiffOptions{})
+}
+
+// CompareRevisions computes the diff between two revisions, ignoring the
+// changes opts selects.
+func (c *sqliteClient) CompareRevisions(ctx context.Context, fromRevID, toRevID int64, opts DiffOptions) (*DiffResult, error) {
 	if err := c.ensureNotClosed(); err != nil {
 		return nil, err
 	}
@@ -29,7 +37,7 @@ func (c *sqliteClient) GetRevisionDiff(ctx context.Context, fromRevID, toRevID i
 		return nil, fmt.Errorf("%w: revisions are for different pages", ErrInvalidInput)
 	}
 
-	return computeDiff(fromRev, toRev), nil
+	return computeDiff(fromRev, toRev, opts), nil
 }
 
 // GetConsecutiveDiff computes the diff from a revision to its parent.
@@ -93,12 +101,92 @@ func (c *sqliteClient) GetConsecutiveDiff(ctx context.Context, revID int64) (*Di
 		fromRev.Comment = comment.String
 	}
 
-	return computeDiff(&fromRev, toRev), nil
+	return computeDiff(&fromRev, toRev, DiffOptions{}), nil
+}
+
+// DiffOptions configures CompareRevisions.
+type DiffOptions struct {
+	// IgnoreWhitespace compares lines with runs of whitespace collapsed and
+	// leading and trailing whitespace trimmed, including inside headings
+	// (==Drops== vs == Drops ==), and ignores blank lines.
+	IgnoreWhitespace bool
+
+	// IgnoreMarkupOnly ignores changes to wikitext that render the same:
+	// bold and italic quotes, and link targets written with underscores or
+	// a lowercase first letter ([[poring_card]] vs [[Poring card|poring card]]).
+	IgnoreMarkupOnly bool
+}
+
+// normalize returns the form of a line that is compared under the options.
+func (o DiffOptions) normalize(line string) string {
+	if o.IgnoreMarkupOnly {
+		line = boldItalicPattern.ReplaceAllString(line, "")
+		line = normalizeLinks(line)
+	}
+	if o.IgnoreWhitespace {
+		line = strings.Join(strings.Fields(line), " ")
+		if h := wikitext.ParseHeading(line); h != nil {
+			marks := strings.Repeat("=", h.Level)
+			line = marks + " " + strings.TrimSpace(line[h.Level:h.End-h.Level]) + " " + marks
+		}
+	}
+	return line
+}
+
+// normalizeLinks rewrites the internal links of a line of wikitext as
+// [[Normalized title|label]], so links that render alike compare equal.
+func normalizeLinks(line string) string {
+	var b strings.Builder
+	last := 0
+	for _, l := range wikitext.Find[*wikitext.Link](wikitext.Parse(line)) {
+		// Links in a file's caption are rewritten with the file link
+		if l.Start < last {
+			continue
+		}
+		target, label, piped := strings.Cut(line[l.Start+2:l.End-2], "|")
+		if !piped {
+			label = strings.TrimPrefix(strings.ReplaceAll(strings.TrimSpace(target), "_", " "), ":")
+		}
+		b.WriteString(line[last:l.Start])
+		b.WriteString("[[" + normalizeTitle(target) + "|" + label + "]]")
+		last = l.End
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// IsCosmeticEdit reports whether two versions of a page's wikitext differ
+// only in whitespace and markup that renders the same, as ignored by
+// DiffOptions.IgnoreWhitespace and IgnoreMarkupOnly. Identical content isn't
+// a cosmetic edit.
+func IsCosmeticEdit(from, to string) bool {
+	if from == to {
+		return false
+	}
+
+	opts := DiffOptions{IgnoreWhitespace: true, IgnoreMarkupOnly: true}
+	var fromLines []string
+	for _, line := range strings.Split(from, "\n") {
+		if line = opts.normalize(line); line != "" {
+			fromLines = append(fromLines, line)
+		}
+	}
+	i := 0
+	for _, line := range strings.Split(to, "\n") {
+		if line = opts.normalize(line); line == "" {
+			continue
+		}
+		if i >= len(fromLines) || fromLines[i] != line {
+			return false
+		}
+		i++
+	}
+	return i == len(fromLines)
 }
 
-// computeDiff generates a unified diff between two revisions.
-// This implements a simple line-by-line diff algorithm.
-func computeDiff(from, to *Revision) *DiffResult {
+// computeDiff generates a unified diff between two revisions, ignoring the
+// changes opts selects. This implements a simple line-by-line diff algorithm.
+func computeDiff(from, to *Revision, opts DiffOptions) *DiffResult {
 	result := &DiffResult{
 		FromRevision:  from.ID,
 		ToRevision:    to.ID,
@@ -110,13 +198,60 @@ func computeDiff(from, to *Revision) *DiffResult {
 	toLines := strings.Split(to.Content, "\n")
 
 	// Compute simple line-by-line diff using LCS
-	diff := simpleDiff(fromLines, toLines)
+	var diff []diffOp
+	if opts == (DiffOptions{}) {
+		diff = simpleDiff(fromLines, toLines)
+	} else {
+		diff = normalizedDiff(fromLines, toLines, opts)
+		result.Cosmetic = from.Content != to.Content && !hasChanges(diff)
+	}
 	result.Unified = formatUnified(diff, fromLines, toLines)
 	result.Stats = computeStats(diff, from.Content, to.Content)
 
 	return result
 }
 
+// normalizedDiff diffs the lines as compared under opts, keeping their
+// original text. Blank lines are dropped when whitespace is ignored.
+func normalizedDiff(fromLines, toLines []string, opts DiffOptions) []diffOp {
+	fromKeys := make([]string, len(fromLines))
+	for i, line := range fromLines {
+		fromKeys[i] = opts.normalize(line)
+	}
+	toKeys := make([]string, len(toLines))
+	for i, line := range toLines {
+		toKeys[i] = opts.normalize(line)
+	}
+
+	var ops []diffOp
+	for _, op := range simpleDiff(fromKeys, toKeys) {
+		switch op.Type {
+		case "insert":
+			if opts.IgnoreWhitespace && op.Text == "" {
+				continue
+			}
+			op.Text = toLines[op.Line-1]
+		default:
+			if op.Type == "delete" && opts.IgnoreWhitespace && op.Text == "" {
+				continue
+			}
+			op.Text = fromLines[op.Line-1]
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// hasChanges reports whether diff operations insert or delete any line.
+func hasChanges(ops []diffOp) bool {
+	for _, op := range ops {
+		if op.Type != "equal" {
+			return true
+		}
+	}
+	return false
+}
+
 // computeDiffFromEmpty generates a diff from an empty state (first revision).
 func computeDiffFromEmpty(to *Revision) *DiffResult {
 	result := &DiffResult{
@@ -308,3 +308,96 @@ func TestDiffStats(t *testing.T) {
 		}
 	})
 }
+
+// TestSQLiteClient_CompareRevisions tests ignoring whitespace and markup-only changes
+func TestSQLiteClient_CompareRevisions(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`
+		INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (10, 0, 'Poring_Card', 0);
+		INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, content, size, sha1) VALUES
+			(300, 10, NULL, '2024-01-01 00:00:00', 'Dropped by [[poring]].
+==Effects==
+LUK +2', 40, 'a'),
+			(301, 10, 300, '2024-01-02 00:00:00', 'Dropped  by [[Poring|poring]].
+
+== Effects ==
+LUK +2', 44, 'b'),
+			(302, 10, 301, '2024-01-03 00:00:00', 'Dropped by ''''''[[Poring|poring]]''''''.
+== Effects ==
+LUK +3', 44, 'c')`)
+	if err != nil {
+		t.Fatalf("failed to add revisions: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	all := irowiki.DiffOptions{IgnoreWhitespace: true, IgnoreMarkupOnly: true}
+
+	// Test: Reformatting is ignored when both options are set
+	diff, err := client.CompareRevisions(ctx, 300, 301, all)
+	if err != nil {
+		t.Fatalf("CompareRevisions failed: %v", err)
+	}
+	if !diff.Cosmetic || diff.Stats.LinesAdded != 0 || diff.Stats.LinesRemoved != 0 {
+		t.Errorf("expected a cosmetic diff, got %+v", diff)
+	}
+
+	// Test: Whitespace alone doesn't cover the link change
+	diff, err = client.CompareRevisions(ctx, 300, 301, irowiki.DiffOptions{IgnoreWhitespace: true})
+	if err != nil {
+		t.Fatalf("CompareRevisions failed: %v", err)
+	}
+	if diff.Cosmetic || diff.Stats.LinesAdded != 1 || diff.Stats.LinesRemoved != 1 {
+		t.Errorf("expected the link line to change, got %+v", diff.Stats)
+	}
+
+	// Test: Substantive changes remain, with their original text
+	diff, err = client.CompareRevisions(ctx, 301, 302, all)
+	if err != nil {
+		t.Fatalf("CompareRevisions failed: %v", err)
+	}
+	if diff.Cosmetic || diff.Stats.LinesAdded != 1 || !strings.Contains(diff.Unified, "+LUK +3") {
+		t.Errorf("expected only the LUK line to change, got %+v\n%s", diff.Stats, diff.Unified)
+	}
+
+	// Test: Without options the diff is unchanged
+	diff, err = client.GetRevisionDiff(ctx, 300, 301)
+	if err != nil {
+		t.Fatalf("GetRevisionDiff failed: %v", err)
+	}
+	if diff.Cosmetic || diff.Stats.LinesAdded == 0 {
+		t.Errorf("expected every reformatted line to change, got %+v", diff.Stats)
+	}
+}
+
+// TestIsCosmeticEdit tests detecting edits that only reformat wikitext
+func TestIsCosmeticEdit(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     bool
+	}{
+		{"identical", "Poring", "Poring", false},
+		{"spacing", "A  pink   slime.\n==Drops==", "A pink slime.\n\n== Drops ==", true},
+		{"emphasis", "A '''pink''' slime.", "A pink slime.", true},
+		{"link form", "See [[poring_card]].", "See [[Poring card|poring card]].", true},
+		{"relabeled link", "See [[Poring card]].", "See [[Poring card|the card]].", false},
+		{"text change", "A pink slime.", "A green slime.", false},
+		{"added line", "A pink slime.", "A pink slime.\nIt bounces.", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := irowiki.IsCosmeticEdit(tt.from, tt.to); got != tt.want {
+				t.Errorf("IsCosmeticEdit(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
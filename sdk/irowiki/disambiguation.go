@@ -0,0 +1,167 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+// boldItalicPattern matches the runs of apostrophes that mark bold and italic text.
+var boldItalicPattern = regexp.MustCompile(`'{2,}`)
+
+// DisambiguationCandidate is one of the pages a disambiguation page lists.
+type DisambiguationCandidate struct {
+	// Title is the linked page, without any #section.
+	Title string `json:"title"`
+
+	// Section is the #section the link points to, if any.
+	Section string `json:"section,omitempty"`
+
+	// Description is the rest of the list item as plain text
+	// (e.g., "a second class of the Swordman").
+	Description string `json:"description,omitempty"`
+
+	// Archived reports whether the linked page is in the archive.
+	Archived bool `json:"archived"`
+}
+
+// AmbiguousTitle is the outcome of ResolveAmbiguousTitle.
+type AmbiguousTitle struct {
+	// Title is the page the title resolved to, after following a redirect.
+	Title string `json:"title"`
+
+	// Ambiguous reports whether that page is a disambiguation page.
+	Ambiguous bool `json:"ambiguous"`
+
+	// Candidates are the pages listed by a disambiguation page, in order.
+	// Empty when the title isn't ambiguous.
+	Candidates []DisambiguationCandidate `json:"candidates"`
+}
+
+// parseDisambiguation extracts the candidates of a disambiguation page: the
+// first article link of each list item, described by the item's remaining text.
+func parseDisambiguation(content string) []DisambiguationCandidate {
+	var candidates []DisambiguationCandidate
+	seen := make(map[string]bool)
+	for _, list := range wikitext.Find[*wikitext.List](wikitext.Parse(content)) {
+		for _, item := range list.Items {
+			if item.Marker[0] != '*' && item.Marker[0] != '#' {
+				continue
+			}
+
+			for i, n := range item.Nodes {
+				link, ok := n.(*wikitext.Link)
+				if !ok {
+					continue
+				}
+				title, section, _ := strings.Cut(link.Target, "#")
+				title = normalizeTitle(strings.TrimPrefix(title, ":"))
+				if title == "" || isNonArticleLink(title) {
+					continue
+				}
+
+				key := title + "#" + section
+				if !seen[key] {
+					seen[key] = true
+					candidates = append(candidates, DisambiguationCandidate{
+						Title:       title,
+						Section:     strings.TrimSpace(section),
+						Description: plainDescription(item.Nodes[i+1:]),
+					})
+				}
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// isNonArticleLink reports whether a link target embeds a file or sets a
+// category rather than pointing to a page.
+func isNonArticleLink(title string) bool {
+	prefix, _, ok := strings.Cut(title, ":")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(prefix)) {
+	case "file", "image", "category", "media":
+		return true
+	}
+	return false
+}
+
+// plainDescription returns the text of the nodes following a candidate's
+// link, without the separators that usually start it (", ", " - ").
+func plainDescription(nodes []wikitext.Node) string {
+	text := boldItalicPattern.ReplaceAllString(wikitext.Plain(nodes), "")
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.TrimLeft(text, ",;:-–— ")
+}
+
+// resolveAmbiguousTitle looks up title, following one redirect, and lists
+// the candidates of the disambiguation page it leads to.
+func resolveAmbiguousTitle(ctx context.Context, db querier, scope wikiScope, title string, placeholder func(n int) string) (*AmbiguousTitle, error) {
+	page, err := resolveTitle(ctx, db, scope, title, true, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	if target := redirectTarget(page.Content); page.IsRedirect && target != "" {
+		redirected, err := resolveTitle(ctx, db, scope, target, true, placeholder)
+		if err != nil && err != ErrNotFound {
+			return nil, err
+		}
+		if err == nil {
+			page = redirected
+		}
+	}
+
+	result := &AmbiguousTitle{
+		Title:      page.Title,
+		Ambiguous:  page.IsDisambiguation,
+		Candidates: []DisambiguationCandidate{},
+	}
+	if !page.IsDisambiguation {
+		return result, nil
+	}
+
+	candidates := parseDisambiguation(page.Content)
+	titles := make([]string, len(candidates))
+	for i, c := range candidates {
+		titles[i] = c.Title
+	}
+	archived, err := getPagesByTitle(ctx, db, scope, titles, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		if archived[i].Found() {
+			candidates[i].Title = archived[i].Title
+			candidates[i].Archived = true
+		}
+	}
+	result.Candidates = candidates
+	return result, nil
+}
+
+// ResolveAmbiguousTitle lists the candidates of a disambiguation page.
+func (c *sqliteClient) ResolveAmbiguousTitle(ctx context.Context, title string) (*AmbiguousTitle, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return resolveAmbiguousTitle(ctx, c.db, c.wiki, title, placeholder)
+}
+
+// ResolveAmbiguousTitle lists the candidates of a disambiguation page.
+func (c *postgresClient) ResolveAmbiguousTitle(ctx context.Context, title string) (*AmbiguousTitle, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return resolveAmbiguousTitle(ctx, c.db, c.wiki, title, placeholder)
+}
@@ -0,0 +1,81 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_ResolveAmbiguousTitle tests listing the candidates of disambiguation pages
+func TestSQLiteClient_ResolveAmbiguousTitle(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`
+		INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES
+			(10, 0, 'Knight (disambiguation)', 0),
+			(11, 0, 'Knight', 1),
+			(12, 0, 'Knight (class)', 0);
+		INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES
+			(300, 10, '2024-01-01 00:00:00', '''''Knight'''' may refer to:
+* [[Knight (class)|Knight]], a second class of the [[Swordman]]
+* [[Knight (monster)]] - an undead knight in [[Glast Heim]] {{Clarify}}
+* [[Knight Card#Effects]]
+<!-- * [[Knight (NPC)]] -->
+[[File:Knight.png]]
+{{Disambig}}', 200, 'x'),
+			(301, 11, '2024-01-01 00:00:00', '#REDIRECT [[Knight (disambiguation)]]', 40, 'x'),
+			(302, 12, '2024-01-01 00:00:00', 'The Knight class.', 17, 'x')`)
+	if err != nil {
+		t.Fatalf("failed to add pages: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Redirects to a disambiguation page are followed
+	result, err := client.ResolveAmbiguousTitle(ctx, "knight")
+	if err != nil {
+		t.Fatalf("ResolveAmbiguousTitle failed: %v", err)
+	}
+	if result.Title != "Knight (disambiguation)" || !result.Ambiguous {
+		t.Errorf("expected the disambiguation page, got %q (ambiguous=%v)", result.Title, result.Ambiguous)
+	}
+
+	// Test: List items yield candidates with plain-text descriptions
+	want := []irowiki.DisambiguationCandidate{
+		{Title: "Knight (class)", Description: "a second class of the Swordman", Archived: true},
+		{Title: "Knight (monster)", Description: "an undead knight in Glast Heim"},
+		{Title: "Knight Card", Section: "Effects"},
+	}
+	if len(result.Candidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %+v", len(want), result.Candidates)
+	}
+	for i, c := range result.Candidates {
+		if c != want[i] {
+			t.Errorf("candidate %d: expected %+v, got %+v", i, want[i], c)
+		}
+	}
+
+	// Test: Other pages aren't ambiguous
+	result, err = client.ResolveAmbiguousTitle(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("ResolveAmbiguousTitle failed: %v", err)
+	}
+	if result.Ambiguous || len(result.Candidates) != 0 {
+		t.Errorf("expected Poring not to be ambiguous, got %+v", result)
+	}
+
+	// Test: Missing pages
+	if _, err := client.ResolveAmbiguousTitle(ctx, "Nonexistent"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
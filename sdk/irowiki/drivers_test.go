@@ -0,0 +1,129 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestOpen tests opening archives through the driver registry
+func TestOpen(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	ctx := context.Background()
+
+	// Test: Built-in drivers are registered
+	for _, name := range []string{"postgres", "postgresql", "sqlite"} {
+		if !slices.Contains(irowiki.Drivers(), name) {
+			t.Errorf("expected built-in driver %q, got %v", name, irowiki.Drivers())
+		}
+	}
+
+	// Test: sqlite:// opens a file
+	client, err := irowiki.Open("sqlite://" + tdb.Path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := client.GetPage(ctx, "Poring"); err != nil {
+		t.Errorf("GetPage failed: %v", err)
+	}
+	client.Close()
+
+	// Test: A third-party driver receives the DSN and options
+	var gotDSN string
+	var gotOpts irowiki.ConnectionOptions
+	irowiki.Register("test-backend", func(dsn string, opts irowiki.ConnectionOptions) (irowiki.Client, error) {
+		gotDSN, gotOpts = dsn, opts
+		return irowiki.OpenSQLiteWithOptions(dsn, opts)
+	})
+	client, err = irowiki.OpenWithOptions("test-backend://"+tdb.Path, irowiki.ConnectionOptions{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("OpenWithOptions failed: %v", err)
+	}
+	client.Close()
+	if gotDSN != tdb.Path || gotOpts.MaxOpenConns != 1 {
+		t.Errorf("expected the driver to get %q and the options, got %q, %+v", tdb.Path, gotDSN, gotOpts)
+	}
+
+	// Test: Registering a name twice panics
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected duplicate Register to panic")
+			}
+		}()
+		irowiki.Register("test-backend", irowiki.OpenSQLiteWithOptions)
+	}()
+
+	// Test: Unknown drivers and empty schemes are rejected
+	for _, location := range []string{"mysql://localhost/irowiki", "://irowiki.db"} {
+		if _, err := irowiki.Open(location); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", location, err)
+		}
+	}
+}
+
+// TestOpen_Detect tests opening archive locations without a driver scheme
+func TestOpen_Detect(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	ctx := context.Background()
+
+	// Test: A SQLite file is detected from its header
+	client, err := irowiki.Open(tdb.Path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := client.GetPage(ctx, "Prontera"); err != nil {
+		t.Errorf("GetPage failed: %v", err)
+	}
+	client.Close()
+
+	// Test: Other files are rejected
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	dump := filepath.Join(dir, "irowiki.dump")
+	bundle := filepath.Join(dir, "irowiki.irowiki")
+	os.WriteFile(notes, []byte("not an archive"), 0o644)
+	os.WriteFile(dump, []byte("PGDMP\x01\x0e\x00"), 0o644)
+	os.WriteFile(bundle, []byte("\x28\xb5\x2f\xfd\x04\x00"), 0o644)
+	for _, location := range []string{notes, dump, bundle} {
+		if _, err := irowiki.Open(location); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", location, err)
+		}
+	}
+
+	// Test: Missing files are reported as not found
+	if _, err := irowiki.Open(filepath.Join(dir, "missing.db")); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Test: http:// URLs open remote archives
+	data, err := os.ReadFile(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer mirror.Close()
+
+	client, err = irowiki.OpenWithOptions(mirror.URL+"/irowiki.db", irowiki.ConnectionOptions{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("OpenWithOptions failed: %v", err)
+	}
+	if _, err := client.GetPage(ctx, "Poring"); err != nil {
+		t.Errorf("GetPage failed: %v", err)
+	}
+	client.Close()
+}
@@ -0,0 +1,74 @@
+package irowiki
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// OpenSQLiteFS opens a SQLite archive stored in a file system such as an
+// embed.FS, so small tools can ship a trimmed sub-archive inside a single
+// self-contained binary. SQLite needs a real file, so the archive is copied
+// to a temporary file that is removed when the client is closed.
+//
+// Example:
+//
+//	//go:embed monsters.db
+//	var archive embed.FS
+//
+//	client, err := irowiki.OpenSQLiteFS(archive, "monsters.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+func OpenSQLiteFS(fsys fs.FS, name string) (Client, error) {
+	return OpenSQLiteFSWithOptions(fsys, name, DefaultSQLiteOptions())
+}
+
+// OpenSQLiteFSWithOptions opens a SQLite archive in a file system with custom connection options.
+func OpenSQLiteFSWithOptions(fsys fs.FS, name string, opts ConnectionOptions) (Client, error) {
+	src, err := fsys.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, fmt.Errorf("%w: failed to open %s: %v", ErrConnectionFailed, name, err)
+	}
+	defer src.Close()
+
+	dir, err := os.MkdirTemp("", "irowiki-")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	local := filepath.Join(dir, path.Base(name))
+
+	if err := copyToFile(local, src); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("%w: failed to copy %s: %v", ErrConnectionFailed, name, err)
+	}
+
+	client, err := OpenSQLiteWithOptions(local, opts)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	client.(*sqliteClient).cleanup = func() error { return os.RemoveAll(dir) }
+	return client, nil
+}
+
+// copyToFile writes the contents of r to a new file at path.
+func copyToFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestOpenSQLiteFS tests opening an archive from a file system
+func TestOpenSQLiteFS(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	data, err := os.ReadFile(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	fsys := fstest.MapFS{"data/irowiki.db": &fstest.MapFile{Data: data}}
+
+	// Test: The archive can be queried
+	client, err := irowiki.OpenSQLiteFS(fsys, "data/irowiki.db")
+	if err != nil {
+		t.Fatalf("OpenSQLiteFS failed: %v", err)
+	}
+	page, err := client.GetPage(context.Background(), "Poring")
+	if err != nil || page.ID != 3 {
+		t.Errorf("expected page 3, got %v, %v", page, err)
+	}
+
+	// Test: Closing removes the temporary copy
+	matches, _ := filepath.Glob(filepath.Join(os.TempDir(), "irowiki-*", "irowiki.db"))
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	for _, m := range matches {
+		if _, err := os.Stat(m); err == nil {
+			t.Errorf("expected %s to be removed", m)
+		}
+	}
+
+	// Test: Missing files report ErrNotFound
+	if _, err := irowiki.OpenSQLiteFS(fsys, "missing.db"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
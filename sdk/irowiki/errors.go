@@ -17,4 +17,37 @@ var (
 
 	// ErrConnectionFailed is returned when database connection fails.
 	ErrConnectionFailed = errors.New("connection failed")
+
+	// ErrNotSupported is returned when a backend doesn't implement an
+	// operation, such as the SQLite-only Store maintenance on PostgreSQL.
+	ErrNotSupported = errors.New("operation not supported")
+
+	// ErrArchiveLocked is returned when another writer holds the archive's
+	// lock. The error is an *ArchiveLockedError naming the holder.
+	ErrArchiveLocked = errors.New("archive is locked")
+
+	// ErrPartialResult is returned with the results found so far when an
+	// operation runs out of its time budget. The error is a
+	// *PartialResultError holding the token to continue with.
+	ErrPartialResult = errors.New("partial result")
 )
+
+// PartialResultError is returned by operations given a time budget, such as
+// SearchRevisions with RevisionSearchOptions.TimeBudget, when the budget
+// runs out before the whole archive is scanned. The operation's results
+// cover the part scanned; pass Continue back to scan the rest.
+// It matches ErrPartialResult with errors.Is.
+type PartialResultError struct {
+	// Continue is the opaque token resuming the operation.
+	Continue string
+}
+
+// Error describes the partial result.
+func (e *PartialResultError) Error() string {
+	return "time budget exceeded; results are partial, continue with " + e.Continue
+}
+
+// Unwrap returns ErrPartialResult.
+func (e *PartialResultError) Unwrap() error {
+	return ErrPartialResult
+}
@@ -0,0 +1,333 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RowWriter receives exported rows. *csv.Writer satisfies it.
+type RowWriter interface {
+	Write(record []string) error
+}
+
+// ExportOptions configures ExportRows.
+type ExportOptions struct {
+	// Table is the table to export: "pages", "revisions", "files", or "links".
+	Table string
+
+	// Columns lists the columns to export, in order. Empty exports every stored
+	// column. Besides stored columns, "year" and "month" (YYYY, YYYY-MM) are
+	// available for revisions and files, "title" for revisions, and "tokens"
+	// for pages and revisions: the estimated tokens of the content, of the
+	// latest revision for pages (see TokenModel).
+	Columns []string
+
+	// Filters restrict the exported rows; all must match.
+	Filters []ExportFilter
+
+	// Limit caps the number of rows exported (0 = all).
+	Limit int
+
+	// TokenModel is the model the "tokens" column estimates for, as
+	// EstimateTokens takes it. Empty estimates for GPT-style tokenizers.
+	TokenModel string
+
+	// Progress, if set, is told the rows exported so far every 1000 rows
+	// and at the end, with stage "export". The total is counted first,
+	// which costs an extra query.
+	Progress Progress
+}
+
+// ExportFilter compares a column with a value, e.g. {"year", "=", "2020"}.
+type ExportFilter struct {
+	// Column is any column valid in ExportOptions.Columns.
+	Column string
+
+	// Operator is one of =, !=, <, <=, >, >=, or ~ (contains, case-insensitive).
+	Operator string
+
+	// Value is compared with the column. Numbers and timestamps are compared
+	// by the database, so "2020-06-01" works against a timestamp column.
+	Value string
+}
+
+// exportFilterPattern matches "column op value" expressions.
+var exportFilterPattern = regexp.MustCompile(`^\s*([A-Za-z_]+)\s*(!=|<=|>=|=|<|>|~)\s*(.*?)\s*$`)
+
+// ParseExportFilter parses a filter expression such as "year=2020",
+// "user != Admin", or "comment~typo". Surrounding quotes on the value are removed.
+func ParseExportFilter(expr string) (ExportFilter, error) {
+	m := exportFilterPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return ExportFilter{}, fmt.Errorf("%w: invalid filter %q: expected column, operator (=, !=, <, <=, >, >=, ~), value", ErrInvalidInput, expr)
+	}
+
+	value := m[3]
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+
+	return ExportFilter{Column: strings.ToLower(m[1]), Operator: m[2], Value: value}, nil
+}
+
+// exportColumn is a column available for export, with its SQL per backend.
+type exportColumn struct {
+	name     string
+	sqlite   string
+	postgres string
+}
+
+// exportTable describes an exportable table. stored columns are the default selection.
+type exportTable struct {
+	stored  []string
+	derived []exportColumn
+	// tokens selects the text the "tokens" column estimates, if the table has one
+	tokens  string
+	orderBy string
+}
+
+// exportTables lists the tables ExportRows can read.
+var exportTables = map[string]exportTable{
+	"pages": {
+		stored:  []string{"page_id", "namespace", "title", "is_redirect", "created_at", "updated_at"},
+		tokens:  "(SELECT r.content FROM revisions r WHERE r.page_id = t.page_id ORDER BY r.timestamp DESC LIMIT 1)",
+		orderBy: "page_id",
+	},
+	"revisions": {
+		stored: []string{"revision_id", "page_id", "parent_id", "timestamp", "user", "user_id", "comment", "content", "size", "sha1", "minor", "tags"},
+		derived: []exportColumn{
+			{"year", "substr(t.timestamp, 1, 4)", "to_char(t.timestamp, 'YYYY')"},
+			{"month", "substr(t.timestamp, 1, 7)", "to_char(t.timestamp, 'YYYY-MM')"},
+			{"title", "(SELECT p.title FROM pages p WHERE p.page_id = t.page_id)", "(SELECT p.title FROM pages p WHERE p.page_id = t.page_id)"},
+		},
+		tokens:  "t.content",
+		orderBy: "revision_id",
+	},
+	"files": {
+		stored: []string{"filename", "url", "descriptionurl", "sha1", "size", "width", "height", "mime_type", "timestamp", "uploader"},
+		derived: []exportColumn{
+			{"year", "substr(t.timestamp, 1, 4)", "to_char(t.timestamp, 'YYYY')"},
+			{"month", "substr(t.timestamp, 1, 7)", "to_char(t.timestamp, 'YYYY-MM')"},
+		},
+		orderBy: "filename",
+	},
+	"links": {
+		stored:  []string{"source_page_id", "target_title", "link_type"},
+		orderBy: "source_page_id, link_type, target_title",
+	},
+}
+
+// ExportTables returns the names of the tables ExportRows supports.
+func ExportTables() []string {
+	return []string{"pages", "revisions", "files", "links"}
+}
+
+// ExportColumns returns the columns ExportRows writes for table when
+// ExportOptions.Columns is empty, or nil if the table isn't exportable.
+func ExportColumns(table string) []string {
+	return append([]string(nil), exportTables[table].stored...)
+}
+
+// Validate checks if the ExportOptions are valid.
+func (o *ExportOptions) Validate() error {
+	table, ok := exportTables[o.Table]
+	if !ok {
+		return fmt.Errorf("invalid table %q: must be one of %s", o.Table, strings.Join(ExportTables(), ", "))
+	}
+	for _, col := range o.Columns {
+		if table.column(col, false) == "" {
+			return fmt.Errorf("unknown column %q for table %s", col, o.Table)
+		}
+	}
+	for _, f := range o.Filters {
+		if table.column(f.Column, false) == "" {
+			return fmt.Errorf("unknown filter column %q for table %s", f.Column, o.Table)
+		}
+		if f.Column == "tokens" {
+			return fmt.Errorf("column tokens can't be filtered: it is estimated after the rows are read")
+		}
+		switch f.Operator {
+		case "=", "!=", "<", "<=", ">", ">=", "~":
+		default:
+			return fmt.Errorf("invalid filter operator %q", f.Operator)
+		}
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must be non-negative")
+	}
+	return nil
+}
+
+// column returns the SQL expression for a column name, or "" if it doesn't exist.
+func (t exportTable) column(name string, postgres bool) string {
+	for _, s := range t.stored {
+		if s == name {
+			return "t." + s
+		}
+	}
+	for _, d := range t.derived {
+		if d.name == name {
+			if postgres {
+				return d.postgres
+			}
+			return d.sqlite
+		}
+	}
+	if name == "tokens" {
+		return t.tokens
+	}
+	return ""
+}
+
+// buildExportQuery builds the export SQL for validated options.
+// placeholder renders the nth (1-based) bind parameter.
+func buildExportQuery(opts ExportOptions, postgres bool, placeholder func(n int) string) (string, []string, []interface{}) {
+	table := exportTables[opts.Table]
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = table.stored
+	}
+
+	selects := make([]string, len(columns))
+	for i, col := range columns {
+		selects[i] = table.column(col, postgres)
+	}
+
+	var args []interface{}
+	conditions := make([]string, len(opts.Filters))
+	for i, f := range opts.Filters {
+		expr := table.column(f.Column, postgres)
+		if f.Operator == "~" {
+			args = append(args, "%"+f.Value+"%")
+			if postgres {
+				conditions[i] = fmt.Sprintf("CAST(%s AS TEXT) ILIKE %s", expr, placeholder(len(args)))
+			} else {
+				conditions[i] = fmt.Sprintf("%s LIKE %s", expr, placeholder(len(args)))
+			}
+			continue
+		}
+		args = append(args, f.Value)
+		conditions[i] = fmt.Sprintf("%s %s %s", expr, f.Operator, placeholder(len(args)))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s t", strings.Join(selects, ", "), opts.Table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	orderBy := strings.Split(table.orderBy, ", ")
+	for i, col := range orderBy {
+		orderBy[i] = "t." + col
+	}
+	query += " ORDER BY " + strings.Join(orderBy, ", ")
+
+	if opts.Limit > 0 {
+		args = append(args, opts.Limit)
+		query += " LIMIT " + placeholder(len(args))
+	}
+
+	return query, columns, args
+}
+
+// exportRows validates opts, writes the header, and streams each row to w.
+func exportRows(ctx context.Context, db querier, opts ExportOptions, w RowWriter, postgres bool, placeholder func(n int) string) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	query, columns, args := buildExportQuery(opts, postgres, placeholder)
+
+	var total int64 = -1
+	if opts.Progress != nil {
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+query+") AS export", args...).Scan(&total); err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	estimate := make([]bool, len(columns))
+	for i, col := range columns {
+		estimate[i] = col == "tokens"
+	}
+
+	record := make([]string, len(columns))
+	var done int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		for i, v := range values {
+			record[i] = formatExportValue(v)
+			if estimate[i] {
+				record[i] = strconv.Itoa(EstimateTokens(record[i], opts.TokenModel))
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+		if done++; done%progressInterval == 0 {
+			reportProgress(opts.Progress, done, total, "export")
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	if done%progressInterval != 0 || done == 0 {
+		reportProgress(opts.Progress, done, total, "export")
+	}
+	return nil
+}
+
+// formatExportValue renders a scanned value as text. NULL becomes an empty
+// string and timestamps use RFC 3339 in UTC so spreadsheets parse them.
+func formatExportValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ExportRows streams a table to w: first the column names, then one record per row.
+func (c *sqliteClient) ExportRows(ctx context.Context, opts ExportOptions, w RowWriter) error {
+	if err := c.ensureNotClosed(); err != nil {
+		return err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return exportRows(ctx, c.db, opts, w, false, placeholder)
+}
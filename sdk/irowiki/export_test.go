@@ -0,0 +1,152 @@
+package irowiki_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_ExportRows tests streaming table exports with column selection and filters
+func TestSQLiteClient_ExportRows(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Selected and derived columns, filtered by user and year
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err = client.ExportRows(ctx, irowiki.ExportOptions{
+		Table:   "revisions",
+		Columns: []string{"revision_id", "title", "timestamp", "year"},
+		Filters: []irowiki.ExportFilter{
+			{Column: "user", Operator: "=", Value: "Editor"},
+			{Column: "year", Operator: "=", Value: "2020"},
+		},
+	}, w)
+	if err != nil {
+		t.Fatalf("ExportRows failed: %v", err)
+	}
+	w.Flush()
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"revision_id", "title", "timestamp", "year"},
+		{"101", "Main_Page", "2020-01-02T00:00:00Z", "2020"},
+		{"103", "Prontera", "2020-01-04T00:00:00Z", "2020"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %d: %v", len(want), len(records), records)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("record %d column %d: expected %q, got %q", i, j, want[i][j], records[i][j])
+			}
+		}
+	}
+
+	// Test: Contains filter and limit, with progress counting the limited rows
+	buf.Reset()
+	w = csv.NewWriter(&buf)
+	var reports []string
+	err = client.ExportRows(ctx, irowiki.ExportOptions{
+		Table:   "revisions",
+		Columns: []string{"revision_id"},
+		Filters: []irowiki.ExportFilter{{Column: "comment", Operator: "~", Value: "CREATED"}},
+		Limit:   2,
+		Progress: irowiki.ProgressFunc(func(done, total int64, stage string) {
+			reports = append(reports, fmt.Sprintf("%s %d/%d", stage, done, total))
+		}),
+	}, w)
+	if err != nil {
+		t.Fatalf("ExportRows failed: %v", err)
+	}
+	w.Flush()
+	if got := buf.String(); got != "revision_id\n102\n104\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+	if len(reports) != 1 || reports[0] != "export 2/2" {
+		t.Errorf("expected a single export 2/2 report, got %v", reports)
+	}
+
+	// Test: Token estimates of a page's latest revision, for the given model
+	for _, model := range []string{"", "all-MiniLM-L6-v2"} {
+		buf.Reset()
+		w = csv.NewWriter(&buf)
+		err = client.ExportRows(ctx, irowiki.ExportOptions{
+			Table:      "pages",
+			Columns:    []string{"title", "tokens"},
+			Filters:    []irowiki.ExportFilter{{Column: "page_id", Operator: "=", Value: "2"}},
+			TokenModel: model,
+		}, w)
+		if err != nil {
+			t.Fatalf("ExportRows failed: %v", err)
+		}
+		w.Flush()
+		want := fmt.Sprintf("title,tokens\nProntera,%d\n", irowiki.EstimateTokens("Prontera is the capital city", model))
+		if got := buf.String(); got != want {
+			t.Errorf("model %q: expected %q, got %q", model, want, got)
+		}
+	}
+
+	// Test: Unknown tables and columns are rejected
+	invalid := []irowiki.ExportOptions{
+		{Table: "users"},
+		{Table: "pages", Columns: []string{"content"}},
+		{Table: "files", Columns: []string{"tokens"}},
+		{Table: "revisions", Filters: []irowiki.ExportFilter{{Column: "tokens", Operator: ">", Value: "100"}}},
+		{Table: "pages", Filters: []irowiki.ExportFilter{{Column: "title; DROP TABLE pages", Operator: "=", Value: "x"}}},
+	}
+	for _, opts := range invalid {
+		if err := client.ExportRows(ctx, opts, csv.NewWriter(&buf)); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for %+v, got %v", opts, err)
+		}
+	}
+}
+
+// TestParseExportFilter tests parsing of filter expressions
+func TestParseExportFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want irowiki.ExportFilter
+	}{
+		{"year=2020", irowiki.ExportFilter{Column: "year", Operator: "=", Value: "2020"}},
+		{"user != Admin", irowiki.ExportFilter{Column: "user", Operator: "!=", Value: "Admin"}},
+		{"size>=100", irowiki.ExportFilter{Column: "size", Operator: ">=", Value: "100"}},
+		{`comment ~ "typo fix"`, irowiki.ExportFilter{Column: "comment", Operator: "~", Value: "typo fix"}},
+		{"Timestamp<2020-06-01", irowiki.ExportFilter{Column: "timestamp", Operator: "<", Value: "2020-06-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := irowiki.ParseExportFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExportFilter failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// Test: Expressions without an operator are rejected
+	if _, err := irowiki.ParseExportFilter("year 2020"); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
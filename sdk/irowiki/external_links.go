@@ -0,0 +1,414 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExternalLink is an outbound http(s) link found in a page's latest revision.
+type ExternalLink struct {
+	// PageID is the page containing the link.
+	PageID int64 `json:"page_id"`
+
+	// URL is the external URL as written in the wikitext.
+	URL string `json:"url"`
+
+	// IsDead is the result of the last liveness check (nil if never checked).
+	IsDead *bool `json:"is_dead,omitempty"`
+
+	// CheckedAt is when liveness was last checked (nil if never checked).
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+
+	// ArchiveURL is the nearest archived copy of a dead link, if one exists.
+	ArchiveURL string `json:"archive_url,omitempty"`
+
+	// ArchiveTimestamp is the capture time of ArchiveURL.
+	ArchiveTimestamp *time.Time `json:"archive_timestamp,omitempty"`
+}
+
+// ArchiveURLs returns a lookup of the archived copies of the dead external
+// links on a page, for render.Options.ArchiveURL: it maps a URL to its copy,
+// or to "" for live links and those without one. Pages without recorded
+// links get an empty lookup.
+func ArchiveURLs(ctx context.Context, client Client, title string) (func(rawURL string) string, error) {
+	links, err := client.GetExternalLinks(ctx, title)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	archived := make(map[string]string)
+	for _, link := range links {
+		if link.IsDead != nil && *link.IsDead && link.ArchiveURL != "" {
+			archived[link.URL] = link.ArchiveURL
+		}
+	}
+	return func(rawURL string) string { return archived[rawURL] }, nil
+}
+
+// Snapshot is an archived copy of a web page.
+type Snapshot struct {
+	// URL is where the archived copy can be viewed.
+	URL string
+
+	// Timestamp is when the copy was captured.
+	Timestamp time.Time
+}
+
+// LinkChecker checks external links and finds archived copies of dead ones.
+// The wayback package provides an implementation backed by the Internet Archive.
+type LinkChecker interface {
+	// IsDead reports whether url no longer serves its content.
+	IsDead(ctx context.Context, url string) (bool, error)
+
+	// NearestSnapshot returns the archived copy of url captured closest to at.
+	// Returns ErrNotFound if no copy exists.
+	NearestSnapshot(ctx context.Context, url string, at time.Time) (*Snapshot, error)
+}
+
+// ExternalLinkOptions configures CheckExternalLinks.
+type ExternalLinkOptions struct {
+	// Limit caps the number of distinct URLs checked in this run (0 = all).
+	Limit int
+
+	// Recheck re-checks URLs that already have a result.
+	Recheck bool
+
+	// Progress, if set, is told the URLs checked so far after each one,
+	// with stage "check".
+	Progress Progress
+}
+
+// Validate checks if the ExternalLinkOptions are valid.
+func (o *ExternalLinkOptions) Validate() error {
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must be non-negative")
+	}
+	return nil
+}
+
+// ExternalLinkResult summarizes a CheckExternalLinks run.
+type ExternalLinkResult struct {
+	// LinksFound is the number of (page, URL) records after syncing from content.
+	LinksFound int64 `json:"links_found"`
+
+	// URLsChecked is the number of distinct URLs checked in this run.
+	URLsChecked int64 `json:"urls_checked"`
+
+	// DeadURLs is the number of checked URLs found dead.
+	DeadURLs int64 `json:"dead_urls"`
+
+	// SnapshotsFound is the number of dead URLs with an archived copy.
+	SnapshotsFound int64 `json:"snapshots_found"`
+}
+
+// externalLinkPattern matches http(s) URLs in wikitext, both bracketed
+// ([http://example.com label]) and bare.
+var externalLinkPattern = regexp.MustCompile(`https?://[^\s\[\]<>"|{}]+`)
+
+// extractExternalLinks returns the distinct external URLs in wikitext, in order of appearance.
+func extractExternalLinks(content string) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, u := range externalLinkPattern.FindAllString(content, -1) {
+		// Trailing punctuation belongs to the sentence, not the URL
+		u = strings.TrimRight(u, ".,;:!?)'")
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// pageLinks pairs a page with the external URLs in its latest revision.
+type pageLinks struct {
+	pageID int64
+	urls   []string
+}
+
+// urlCheck is a URL due for checking and the newest edit of any page using it.
+type urlCheck struct {
+	url      string
+	lastSeen time.Time
+}
+
+// collectURLChecks groups (url, timestamp) rows by URL, keeping the newest timestamp,
+// and returns them sorted by URL and capped at limit (0 = all).
+func collectURLChecks(rows *sql.Rows, limit int) ([]urlCheck, error) {
+	defer rows.Close()
+
+	latest := make(map[string]time.Time)
+	for rows.Next() {
+		var u string
+		var ts time.Time
+		if err := rows.Scan(&u, &ts); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		if ts.After(latest[u]) {
+			latest[u] = ts
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	checks := make([]urlCheck, 0, len(latest))
+	for u, ts := range latest {
+		checks = append(checks, urlCheck{url: u, lastSeen: ts})
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].url < checks[j].url })
+
+	if limit > 0 && len(checks) > limit {
+		checks = checks[:limit]
+	}
+	return checks, nil
+}
+
+// checkURL runs the liveness check and, for dead links, the snapshot lookup.
+func checkURL(ctx context.Context, checker LinkChecker, c urlCheck) (dead bool, snapshot *Snapshot, err error) {
+	dead, err = checker.IsDead(ctx, c.url)
+	if err != nil || !dead {
+		return dead, nil, err
+	}
+
+	snapshot, err = checker.NearestSnapshot(ctx, c.url, c.lastSeen)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return dead, nil, err
+	}
+	return dead, snapshot, nil
+}
+
+// CheckExternalLinks syncs external links from each page's latest revision,
+// checks unchecked URLs with checker, and stores the nearest archived copy of dead ones.
+func (s *sqliteStore) CheckExternalLinks(ctx context.Context, checker LinkChecker, opts ExternalLinkOptions) (*ExternalLinkResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	release, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Older archives predate the table
+	if err := execStatements(ctx, s.db, sqliteExternalLinksSchema); err != nil {
+		return nil, err
+	}
+
+	result := &ExternalLinkResult{}
+
+	if err := s.syncExternalLinks(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM external_links").Scan(&result.LinksFound); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	query := `
+		SELECT e.url, r.timestamp
+		FROM external_links e
+		JOIN revisions r ON r.page_id = e.source_page_id
+	`
+	if !opts.Recheck {
+		query += " WHERE e.checked_at IS NULL"
+	}
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	checks, err := collectURLChecks(rows, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	const update = `
+		UPDATE external_links
+		SET is_dead = ?, checked_at = ?, archive_url = ?, archive_timestamp = ?
+		WHERE url = ?
+	`
+
+	// Network calls happen outside any transaction; each URL is saved as it completes
+	// so an interrupted run keeps its progress.
+	for _, c := range checks {
+		dead, snapshot, err := checkURL(ctx, checker, c)
+		if err != nil {
+			return result, fmt.Errorf("failed to check %s: %w", c.url, err)
+		}
+
+		var archiveURL sql.NullString
+		var archiveTime sql.NullTime
+		if snapshot != nil {
+			archiveURL = sql.NullString{String: snapshot.URL, Valid: true}
+			archiveTime = sql.NullTime{Time: snapshot.Timestamp, Valid: true}
+			result.SnapshotsFound++
+		}
+
+		if _, err := s.db.ExecContext(ctx, update, dead, time.Now().UTC(), archiveURL, archiveTime, c.url); err != nil {
+			return result, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		result.URLsChecked++
+		if dead {
+			result.DeadURLs++
+		}
+		reportProgress(opts.Progress, result.URLsChecked, int64(len(checks)), "check")
+	}
+
+	return result, nil
+}
+
+// syncExternalLinks records the external links in each page's latest revision.
+// Existing records (and their check results) are left untouched.
+func (s *sqliteStore) syncExternalLinks(ctx context.Context) error {
+	const query = `
+		SELECT r.page_id, r.content
+		FROM revisions r
+		WHERE r.revision_id = (
+			SELECT r2.revision_id FROM revisions r2
+			WHERE r2.page_id = r.page_id
+			ORDER BY r2.timestamp DESC
+			LIMIT 1
+		)
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	var pages []pageLinks
+	for rows.Next() {
+		var pageID int64
+		var content string
+		if err := rows.Scan(&pageID, &content); err != nil {
+			rows.Close()
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		if urls := extractExternalLinks(content); len(urls) > 0 {
+			pages = append(pages, pageLinks{pageID: pageID, urls: urls})
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.PrepareContext(ctx, "INSERT OR IGNORE INTO external_links (source_page_id, url) VALUES (?, ?)")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer insert.Close()
+
+	for _, p := range pages {
+		for _, u := range p.urls {
+			if _, err := insert.ExecContext(ctx, p.pageID, u); err != nil {
+				return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// scanExternalLinks reads external_links rows in the column order used by GetExternalLinks.
+func scanExternalLinks(rows *sql.Rows) ([]ExternalLink, error) {
+	defer rows.Close()
+
+	var links []ExternalLink
+	for rows.Next() {
+		var link ExternalLink
+		var dead sql.NullBool
+		var checkedAt, archiveTime sql.NullTime
+		var archiveURL sql.NullString
+
+		if err := rows.Scan(&link.PageID, &link.URL, &dead, &checkedAt, &archiveURL, &archiveTime); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		if dead.Valid {
+			link.IsDead = &dead.Bool
+		}
+		if checkedAt.Valid {
+			link.CheckedAt = &checkedAt.Time
+		}
+		if archiveURL.Valid {
+			link.ArchiveURL = archiveURL.String
+		}
+		if archiveTime.Valid {
+			link.ArchiveTimestamp = &archiveTime.Time
+		}
+
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return links, nil
+}
+
+// GetExternalLinks returns the external links of a page with their check results.
+func (c *sqliteClient) GetExternalLinks(ctx context.Context, title string) ([]ExternalLink, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	pageID := page.ID
+
+	exists, err := sqliteTableExists(ctx, c.db, "main", "external_links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []ExternalLink{}, nil
+	}
+
+	const query = `
+		SELECT source_page_id, url, is_dead, checked_at, archive_url, archive_timestamp
+		FROM external_links
+		WHERE source_page_id = ?
+		ORDER BY url
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	links, err := scanExternalLinks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		links = []ExternalLink{}
+	}
+	return links, nil
+}
@@ -0,0 +1,134 @@
+package irowiki_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// fakeLinkChecker treats URLs containing "dead" as dead and archives only "dead-archived" ones.
+type fakeLinkChecker struct {
+	checked []string
+}
+
+func (f *fakeLinkChecker) IsDead(ctx context.Context, url string) (bool, error) {
+	f.checked = append(f.checked, url)
+	return strings.Contains(url, "dead"), nil
+}
+
+func (f *fakeLinkChecker) NearestSnapshot(ctx context.Context, url string, at time.Time) (*irowiki.Snapshot, error) {
+	if !strings.Contains(url, "archived") {
+		return nil, irowiki.ErrNotFound
+	}
+	return &irowiki.Snapshot{URL: "https://web.archive.org/web/2020/" + url, Timestamp: at}, nil
+}
+
+// TestSQLiteStore_CheckExternalLinks tests syncing, checking and archiving external links
+func TestSQLiteStore_CheckExternalLinks(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`UPDATE revisions SET content = 'Drops: see [http://dead-archived.example.com/poring Poring DB] and http://alive.example.com/. Also http://dead.example.com' WHERE revision_id = 104`)
+	if err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	checker := &fakeLinkChecker{}
+
+	result, err := store.CheckExternalLinks(ctx, checker, irowiki.ExternalLinkOptions{})
+	if err != nil {
+		t.Fatalf("CheckExternalLinks failed: %v", err)
+	}
+
+	if result.LinksFound != 3 || result.URLsChecked != 3 {
+		t.Errorf("expected 3 links found and checked, got %d and %d", result.LinksFound, result.URLsChecked)
+	}
+	if result.DeadURLs != 2 || result.SnapshotsFound != 1 {
+		t.Errorf("expected 2 dead and 1 snapshot, got %d and %d", result.DeadURLs, result.SnapshotsFound)
+	}
+
+	// Test: Checked URLs are skipped on the next run
+	checker.checked = nil
+	result, err = store.CheckExternalLinks(ctx, checker, irowiki.ExternalLinkOptions{})
+	if err != nil {
+		t.Fatalf("CheckExternalLinks failed: %v", err)
+	}
+	if result.URLsChecked != 0 || len(checker.checked) != 0 {
+		t.Errorf("expected no URLs re-checked, got %v", checker.checked)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	links, err := client.GetExternalLinks(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetExternalLinks failed: %v", err)
+	}
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %d", len(links))
+	}
+
+	// Ordered by URL: alive, dead-archived, dead
+	if links[0].IsDead == nil || *links[0].IsDead {
+		t.Errorf("expected %s to be alive", links[0].URL)
+	}
+	if links[1].URL != "http://dead-archived.example.com/poring" {
+		t.Errorf("unexpected URL: %s", links[1].URL)
+	}
+	if links[1].ArchiveURL == "" || links[1].ArchiveTimestamp == nil {
+		t.Errorf("expected archived copy for %s", links[1].URL)
+	}
+	if links[2].ArchiveURL != "" {
+		t.Errorf("expected no archived copy for %s", links[2].URL)
+	}
+
+	// Test: ArchiveURLs looks up the copies of dead links only
+	archiveURL, err := irowiki.ArchiveURLs(ctx, client, "Poring")
+	if err != nil {
+		t.Fatalf("ArchiveURLs failed: %v", err)
+	}
+	if got := archiveURL(links[1].URL); got != links[1].ArchiveURL {
+		t.Errorf("expected %s, got %q", links[1].ArchiveURL, got)
+	}
+	if got := archiveURL(links[0].URL) + archiveURL(links[2].URL); got != "" {
+		t.Errorf("expected no copies of live or unarchived links, got %q", got)
+	}
+	archiveURL, err = irowiki.ArchiveURLs(ctx, client, "Nonexistent")
+	if err != nil || archiveURL(links[1].URL) != "" {
+		t.Errorf("expected an empty lookup for a missing page, got %v", err)
+	}
+}
+
+// TestSQLiteClient_GetExternalLinks_NoTable tests archives without external link data
+func TestSQLiteClient_GetExternalLinks_NoTable(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	links, err := client.GetExternalLinks(context.Background(), "Poring")
+	if err != nil {
+		t.Fatalf("GetExternalLinks failed: %v", err)
+	}
+	if len(links) != 0 {
+		t.Errorf("expected no links, got %d", len(links))
+	}
+}
@@ -0,0 +1,558 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SubArchiveFilter selects the pages copied by ExtractSubArchive.
+// A page is selected if it matches any of the non-empty criteria.
+type SubArchiveFilter struct {
+	// Namespaces selects every page in these namespaces.
+	Namespaces []int
+
+	// Categories selects pages that are members of these categories
+	// (names without the "Category:" prefix).
+	Categories []string
+
+	// Titles selects individual pages by title.
+	Titles []string
+
+	// SkipFiles omits file metadata referenced by the selected pages.
+	SkipFiles bool
+}
+
+// Validate checks if the SubArchiveFilter is valid.
+func (f *SubArchiveFilter) Validate() error {
+	if len(f.Namespaces) == 0 && len(f.Categories) == 0 && len(f.Titles) == 0 {
+		return fmt.Errorf("filter must select at least one namespace, category, or title")
+	}
+	for _, ns := range f.Namespaces {
+		if ns < 0 {
+			return fmt.Errorf("namespace must be non-negative")
+		}
+	}
+	return nil
+}
+
+// ExtractResult summarizes the contents of an extracted sub-archive.
+type ExtractResult struct {
+	// Path is the location of the new archive.
+	Path string `json:"path"`
+
+	// Pages is the number of pages copied.
+	Pages int64 `json:"pages"`
+
+	// Revisions is the number of revisions copied (full history of each page).
+	Revisions int64 `json:"revisions"`
+
+	// Files is the number of file metadata records copied.
+	Files int64 `json:"files"`
+
+	// Links is the number of link records copied.
+	Links int64 `json:"links"`
+
+	// ExternalLinks is the number of external link records copied.
+	ExternalLinks int64 `json:"external_links"`
+
+	// PageHTML is the number of pages whose captured HTML was copied.
+	PageHTML int64 `json:"page_html"`
+}
+
+// extractFileReferences returns the normalized file names embedded in wikitext.
+func extractFileReferences(content string) []string {
+	var names []string
+	for _, l := range extractPageLinks(content) {
+		if name := normalizeTitle(l.target); l.linkType == LinkTypeFile && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// EmbeddedFiles returns the names of the files a page's wikitext embeds
+// with [[File:...]] or [[Image:...]], normalized like titles ("Poring
+// card.png"), in order of first appearance.
+func EmbeddedFiles(wikitext string) []string {
+	var names []string
+	for _, name := range extractFileReferences(wikitext) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// normalizeTitle applies MediaWiki title rules to a title or file name:
+// underscores become spaces and the first letter is upper-cased.
+func normalizeTitle(name string) string {
+	name = strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+	if name == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// ExtractSubArchive writes a new SQLite archive at dest containing only the
+// pages selected by filter, with their full history, links, and referenced files.
+func (s *sqliteStore) ExtractSubArchive(ctx context.Context, dest string, filter SubArchiveFilter) (*ExtractResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return nil, fmt.Errorf("%w: destination %s already exists", ErrInvalidInput, dest)
+	}
+
+	// The extract is indexed the same way as its source
+	tokenize, err := sqliteFTSTokenize(ctx, s.db, "main")
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := [][]string{sqliteSchema, sqliteArchiveMetaSchema, sqliteExternalLinksSchema, sqlitePageHTMLSchema, sqliteWikisSchema, sqliteDeletedPagesSchema, sqlitePageProtectionSchema, sqliteCommentFTSSchema, {sqliteFTSTable("main", tokenize)}}
+	if err := createSQLiteArchive(ctx, dest, schemas...); err != nil {
+		os.Remove(dest)
+		return nil, err
+	}
+
+	result, err := s.copySubArchive(ctx, dest, filter)
+	if err != nil {
+		os.Remove(dest)
+		return nil, err
+	}
+
+	if err := createSQLiteArchive(ctx, dest, sqliteFTSTriggers, sqliteCommentFTSTriggers); err != nil {
+		os.Remove(dest)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// createSQLiteArchive opens (creating if needed) a SQLite file and applies each set of statements.
+func createSQLiteArchive(ctx context.Context, path string, schemas ...[]string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("%w: failed to create archive: %v", ErrConnectionFailed, err)
+	}
+	defer db.Close()
+
+	for _, statements := range schemas {
+		if err := execStatements(ctx, db, statements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copySubArchive attaches dest to a pinned connection and copies the selected rows.
+func (s *sqliteStore) copySubArchive(ctx context.Context, dest string, filter SubArchiveFilter) (*ExtractResult, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS sub", dest); err != nil {
+		return nil, fmt.Errorf("%w: failed to attach destination: %v", ErrDatabaseError, err)
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE sub")
+
+	setup := []string{
+		"DROP TABLE IF EXISTS temp.extract_pages",
+		"DROP TABLE IF EXISTS temp.extract_files",
+		"CREATE TEMP TABLE extract_pages (page_id INTEGER PRIMARY KEY)",
+		"CREATE TEMP TABLE extract_files (filename TEXT PRIMARY KEY)",
+	}
+	for _, stmt := range setup {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+	}
+	defer conn.ExecContext(context.Background(), "DROP TABLE IF EXISTS temp.extract_pages")
+	defer conn.ExecContext(context.Background(), "DROP TABLE IF EXISTS temp.extract_files")
+
+	if err := selectSubArchivePages(ctx, conn, filter); err != nil {
+		return nil, err
+	}
+	if !filter.SkipFiles {
+		if err := selectSubArchiveFiles(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	hasLinks, err := sqliteTableExists(ctx, conn, "main", "links")
+	if err != nil {
+		return nil, err
+	}
+	hasMeta, err := sqliteTableExists(ctx, conn, "main", "archive_meta")
+	if err != nil {
+		return nil, err
+	}
+	hasExternalLinks, err := sqliteTableExists(ctx, conn, "main", "external_links")
+	if err != nil {
+		return nil, err
+	}
+	hasHTML, err := sqliteTableExists(ctx, conn, "main", "page_html")
+	if err != nil {
+		return nil, err
+	}
+	hasWikis, err := sqliteTableExists(ctx, conn, "main", "wikis")
+	if err != nil {
+		return nil, err
+	}
+	hasInterwiki, err := sqliteTableExists(ctx, conn, "main", "interwiki_links")
+	if err != nil {
+		return nil, err
+	}
+	hasDeleted, err := sqliteTableExists(ctx, conn, "main", "deleted_pages")
+	if err != nil {
+		return nil, err
+	}
+	hasProtection, err := sqliteTableExists(ctx, conn, "main", "page_protection")
+	if err != nil {
+		return nil, err
+	}
+	hasTargets, err := sqliteColumnExists(ctx, conn, "pages", "redirect_target")
+	if err != nil {
+		return nil, err
+	}
+
+	// Archives predating sister wikis hold only the primary wiki
+	wikiID := "''"
+	if hasWikis {
+		wikiID = "wiki_id"
+	}
+	// Archives scraped before redirect targets were stored get them parsed
+	target := wikiScope{storedTargets: hasTargets}.redirectTargetColumn("p", sqliteParseTarget)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	result := &ExtractResult{Path: dest}
+
+	copies := []struct {
+		target *int64
+		query  string
+	}{
+		{&result.Pages, `
+			INSERT INTO sub.pages (page_id, namespace, title, is_redirect, redirect_target, wiki_id, created_at, updated_at)
+			SELECT page_id, namespace, title, is_redirect, ` + target + `, ` + wikiID + `, created_at, updated_at
+			FROM main.pages p
+			WHERE page_id IN (SELECT page_id FROM temp.extract_pages)`},
+		{&result.Revisions, `
+			INSERT INTO sub.revisions (revision_id, page_id, parent_id, timestamp, user, user_id,
+			                           comment, content, size, sha1, minor, tags)
+			SELECT revision_id, page_id, parent_id, timestamp, user, user_id,
+			       comment, content, size, sha1, minor, tags
+			FROM main.revisions
+			WHERE page_id IN (SELECT page_id FROM temp.extract_pages)`},
+		{&result.Files, `
+			INSERT INTO sub.files (filename, url, descriptionurl, sha1, size, width, height,
+			                       mime_type, timestamp, uploader)
+			SELECT filename, url, descriptionurl, sha1, size, width, height,
+			       mime_type, timestamp, uploader
+			FROM main.files
+			WHERE REPLACE(filename, '_', ' ') IN (SELECT filename FROM temp.extract_files)`},
+	}
+	if hasLinks {
+		copies = append(copies, struct {
+			target *int64
+			query  string
+		}{&result.Links, `
+			INSERT OR IGNORE INTO sub.links (source_page_id, target_title, link_type)
+			SELECT source_page_id, target_title, link_type
+			FROM main.links
+			WHERE source_page_id IN (SELECT page_id FROM temp.extract_pages)`})
+	}
+
+	if hasExternalLinks {
+		copies = append(copies, struct {
+			target *int64
+			query  string
+		}{&result.ExternalLinks, `
+			INSERT OR IGNORE INTO sub.external_links (source_page_id, url, is_dead, checked_at,
+			                                          archive_url, archive_timestamp)
+			SELECT source_page_id, url, is_dead, checked_at, archive_url, archive_timestamp
+			FROM main.external_links
+			WHERE source_page_id IN (SELECT page_id FROM temp.extract_pages)`})
+	}
+
+	if hasHTML {
+		copies = append(copies, struct {
+			target *int64
+			query  string
+		}{&result.PageHTML, `
+			INSERT INTO sub.page_html (page_id, revision_id, html, fetched_at)
+			SELECT page_id, revision_id, html, fetched_at
+			FROM main.page_html
+			WHERE page_id IN (SELECT page_id FROM temp.extract_pages)`})
+	}
+
+	if hasInterwiki {
+		copies = append(copies, struct {
+			target *int64
+			query  string
+		}{new(int64), `
+			INSERT INTO sub.interwiki_links (source_page_id, target_wiki, target_title)
+			SELECT source_page_id, target_wiki, target_title
+			FROM main.interwiki_links
+			WHERE source_page_id IN (SELECT page_id FROM temp.extract_pages)`})
+	}
+
+	if hasDeleted {
+		copies = append(copies, struct {
+			target *int64
+			query  string
+		}{new(int64), `
+			INSERT INTO sub.deleted_pages (page_id, deleted_at)
+			SELECT page_id, deleted_at
+			FROM main.deleted_pages
+			WHERE page_id IN (SELECT page_id FROM temp.extract_pages)`})
+	}
+
+	if hasProtection {
+		copies = append(copies, struct {
+			target *int64
+			query  string
+		}{new(int64), `
+			INSERT INTO sub.page_protection (page_id, action, level, expiry, fetched_at)
+			SELECT page_id, action, level, expiry, fetched_at
+			FROM main.page_protection
+			WHERE page_id IN (SELECT page_id FROM temp.extract_pages)`})
+	}
+
+	// The extract describes the same source wiki, so it inherits its attribution
+	if hasMeta {
+		const metaQuery = `
+			INSERT OR REPLACE INTO sub.archive_meta (key, value, updated_at)
+			SELECT key, value, updated_at FROM main.archive_meta
+		`
+		if _, err := tx.ExecContext(ctx, metaQuery); err != nil {
+			return nil, fmt.Errorf("%w: failed to copy archive metadata: %v", ErrDatabaseError, err)
+		}
+	}
+	if hasWikis {
+		const wikisQuery = `
+			INSERT INTO sub.wikis (wiki_id, name, base_url, api_url, article_path, id_offset, merged_at)
+			SELECT wiki_id, name, base_url, api_url, article_path, id_offset, merged_at FROM main.wikis
+		`
+		if _, err := tx.ExecContext(ctx, wikisQuery); err != nil {
+			return nil, fmt.Errorf("%w: failed to copy wikis: %v", ErrDatabaseError, err)
+		}
+	}
+
+	for _, c := range copies {
+		res, err := tx.ExecContext(ctx, c.query)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to copy rows: %v", ErrDatabaseError, err)
+		}
+		if *c.target, err = res.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+	}
+
+	// Index the latest revision of each copied page
+	const ftsQuery = `
+		INSERT INTO sub.pages_fts (page_id, title, content)
+		SELECT p.page_id, p.title,
+		       (SELECT r.content FROM sub.revisions r WHERE r.page_id = p.page_id ORDER BY r.timestamp DESC LIMIT 1)
+		FROM sub.pages p
+		WHERE EXISTS (SELECT 1 FROM sub.revisions r WHERE r.page_id = p.page_id)
+	`
+	if _, err := tx.ExecContext(ctx, ftsQuery); err != nil {
+		return nil, fmt.Errorf("%w: failed to build search index: %v", ErrDatabaseError, err)
+	}
+	const commentFTSQuery = `
+		INSERT INTO sub.revision_comments_fts (rowid, comment)
+		SELECT revision_id, comment
+		FROM sub.revisions
+		WHERE comment IS NOT NULL AND comment != ''
+	`
+	if _, err := tx.ExecContext(ctx, commentFTSQuery); err != nil {
+		return nil, fmt.Errorf("%w: failed to build comment index: %v", ErrDatabaseError, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	return result, nil
+}
+
+// selectSubArchivePages fills temp.extract_pages with the pages matching filter.
+func selectSubArchivePages(ctx context.Context, conn *sql.Conn, filter SubArchiveFilter) error {
+	insert := func(query string, args ...interface{}) error {
+		if _, err := conn.ExecContext(ctx, "INSERT OR IGNORE INTO temp.extract_pages (page_id) "+query, args...); err != nil {
+			return fmt.Errorf("%w: failed to select pages: %v", ErrDatabaseError, err)
+		}
+		return nil
+	}
+
+	if len(filter.Namespaces) > 0 {
+		placeholders := make([]string, len(filter.Namespaces))
+		args := make([]interface{}, len(filter.Namespaces))
+		for i, ns := range filter.Namespaces {
+			placeholders[i] = "?"
+			args[i] = ns
+		}
+		query := fmt.Sprintf("SELECT page_id FROM main.pages WHERE namespace IN (%s)", join(placeholders, ","))
+		if err := insert(query, args...); err != nil {
+			return err
+		}
+	}
+
+	for _, title := range filter.Titles {
+		query := "SELECT page_id FROM main.pages WHERE REPLACE(title, '_', ' ') = ?"
+		if err := insert(query, strings.ReplaceAll(title, "_", " ")); err != nil {
+			return err
+		}
+	}
+
+	if len(filter.Categories) == 0 {
+		return nil
+	}
+
+	hasLinks, err := sqliteTableExists(ctx, conn, "main", "links")
+	if err != nil {
+		return err
+	}
+	if !hasLinks {
+		return selectSubArchiveCategoryContent(ctx, conn, filter.Categories)
+	}
+
+	for _, category := range filter.Categories {
+		name := categoryTitle(category)
+		query := `
+			SELECT source_page_id FROM main.links
+			WHERE link_type = 'category'
+			  AND REPLACE(target_title, '_', ' ') IN (?, 'Category:' || ?)
+		`
+		if err := insert(query, name, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectSubArchiveCategoryContent adds the pages in categories to
+// temp.extract_pages for archives without a links table, reading the
+// categories from each page's latest wikitext.
+func selectSubArchiveCategoryContent(ctx context.Context, conn *sql.Conn, categories []string) error {
+	wanted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		wanted[categoryTitle(category)] = true
+	}
+
+	// LIKE only narrows the scan; names are matched whole in Go, so
+	// "Monster" doesn't select the pages in "Monsters"
+	const query = `
+		SELECT page_id, content FROM (
+			SELECT p.page_id,
+			       (SELECT r.content FROM main.revisions r
+			        WHERE r.page_id = p.page_id ORDER BY r.timestamp DESC LIMIT 1) AS content
+			FROM main.pages p
+		)
+		WHERE content LIKE '%Category%'
+	`
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%w: failed to select pages: %v", ErrDatabaseError, err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			rows.Close()
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		for _, name := range PageCategories(content) {
+			if wanted[name] {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	for _, id := range ids {
+		if _, err := conn.ExecContext(ctx, "INSERT OR IGNORE INTO temp.extract_pages (page_id) VALUES (?)", id); err != nil {
+			return fmt.Errorf("%w: failed to select pages: %v", ErrDatabaseError, err)
+		}
+	}
+
+	return nil
+}
+
+// selectSubArchiveFiles fills temp.extract_files with files referenced by the
+// selected pages, plus the files described by selected File: pages.
+func selectSubArchiveFiles(ctx context.Context, conn *sql.Conn) error {
+	const query = `
+		SELECT p.namespace, p.title,
+		       (SELECT r.content FROM main.revisions r WHERE r.page_id = p.page_id ORDER BY r.timestamp DESC LIMIT 1)
+		FROM main.pages p
+		WHERE p.page_id IN (SELECT page_id FROM temp.extract_pages)
+	`
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var namespace int
+		var title string
+		var content sql.NullString
+
+		if err := rows.Scan(&namespace, &title, &content); err != nil {
+			rows.Close()
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		if namespace == NamespaceFile {
+			// Archives may store File: pages with or without the prefix
+			if ns, name := ParseTitle(title); ns == NamespaceFile {
+				title = name
+			}
+			names[normalizeTitle(title)] = true
+		}
+		for _, name := range extractFileReferences(content.String) {
+			names[name] = true
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	for name := range names {
+		if _, err := conn.ExecContext(ctx, "INSERT OR IGNORE INTO temp.extract_files (filename) VALUES (?)", name); err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+	}
+
+	return nil
+}
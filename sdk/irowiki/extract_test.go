@@ -0,0 +1,229 @@
+package irowiki_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteStore_ExtractSubArchive tests namespace and category based extraction
+func TestSQLiteStore_ExtractSubArchive(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`UPDATE revisions SET content = 'Poring is a pink slime. [[File:document.pdf|thumb]] [[Category:Monsters]]' WHERE revision_id = 104`)
+	if err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	dest := filepath.Join(t.TempDir(), "monsters.db")
+
+	result, err := store.ExtractSubArchive(ctx, dest, irowiki.SubArchiveFilter{
+		Categories: []string{"Monsters"},
+		Titles:     []string{"Main_Page"},
+	})
+	if err != nil {
+		t.Fatalf("ExtractSubArchive failed: %v", err)
+	}
+
+	// Poring (by category) and Main_Page (by title) with full history
+	if result.Pages != 2 {
+		t.Errorf("expected 2 pages, got %d", result.Pages)
+	}
+	if result.Revisions != 3 {
+		t.Errorf("expected 3 revisions, got %d", result.Revisions)
+	}
+	if result.Files != 1 {
+		t.Errorf("expected 1 referenced file, got %d", result.Files)
+	}
+
+	// Test: The extracted archive is a valid, searchable archive
+	client, err := irowiki.OpenSQLite(dest)
+	if err != nil {
+		t.Fatalf("failed to open extracted archive: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.GetPage(ctx, "Prontera"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected Prontera to be excluded, got %v", err)
+	}
+	if _, err := client.GetFile(ctx, "Document.pdf"); err != nil {
+		t.Errorf("expected referenced file to be copied: %v", err)
+	}
+
+	results, err := client.SearchFullText(ctx, "slime", irowiki.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchFullText failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Poring" {
+		t.Errorf("expected Poring in search results, got %+v", results)
+	}
+}
+
+// TestSQLiteStore_ExtractSubArchive_CategoryPrefix tests that, read from
+// wikitext, a category doesn't select pages in categories it prefixes
+func TestSQLiteStore_ExtractSubArchive_CategoryPrefix(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	updates := map[int64]string{
+		101: "Welcome! [[category: monster|Main]]",
+		103: "Prontera is the capital city. [[Category:Monster Skills]]",
+		104: "Poring is a pink slime monster. [[Category:Monsters]]",
+	}
+	for id, content := range updates {
+		if _, err := tdb.DB.Exec(`UPDATE revisions SET content = ? WHERE revision_id = ?`, content, id); err != nil {
+			t.Fatalf("failed to update fixture: %v", err)
+		}
+	}
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	dest := filepath.Join(t.TempDir(), "monster.db")
+	result, err := store.ExtractSubArchive(ctx, dest, irowiki.SubArchiveFilter{Categories: []string{"Category:Monster"}})
+	if err != nil {
+		t.Fatalf("ExtractSubArchive failed: %v", err)
+	}
+	if result.Pages != 1 {
+		t.Errorf("expected 1 page, got %d", result.Pages)
+	}
+
+	client, err := irowiki.OpenSQLite(dest)
+	if err != nil {
+		t.Fatalf("failed to open extracted archive: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.GetPage(ctx, "Main_Page"); err != nil {
+		t.Errorf("expected Main_Page to be extracted: %v", err)
+	}
+	for _, title := range []string{"Prontera", "Poring"} {
+		if _, err := client.GetPage(ctx, title); !errors.Is(err, irowiki.ErrNotFound) {
+			t.Errorf("expected %s to be excluded, got %v", title, err)
+		}
+	}
+}
+
+// TestSQLiteStore_ExtractSubArchive_Namespace tests namespace extraction including file pages
+func TestSQLiteStore_ExtractSubArchive_Namespace(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	dest := filepath.Join(t.TempDir(), "files.db")
+	result, err := store.ExtractSubArchive(context.Background(), dest, irowiki.SubArchiveFilter{Namespaces: []int{6}})
+	if err != nil {
+		t.Fatalf("ExtractSubArchive failed: %v", err)
+	}
+
+	if result.Pages != 1 || result.Revisions != 1 {
+		t.Errorf("expected 1 page and 1 revision, got %d and %d", result.Pages, result.Revisions)
+	}
+	if result.Files != 1 {
+		t.Errorf("expected the file page's file to be copied, got %d", result.Files)
+	}
+
+	// The triggers are installed, so the archive stays consistent when edited
+	db, err := sql.Open("sqlite", dest)
+	if err != nil {
+		t.Fatalf("failed to open extracted archive: %v", err)
+	}
+	defer db.Close()
+
+	var triggers int
+	db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger'").Scan(&triggers)
+	if triggers != 7 {
+		t.Errorf("expected 7 FTS triggers (pages and comments), got %d", triggers)
+	}
+
+	// Edit summaries of the copied revisions are indexed
+	var comments, indexed int
+	db.QueryRow("SELECT COUNT(*) FROM revisions WHERE comment != ''").Scan(&comments)
+	db.QueryRow("SELECT COUNT(*) FROM revision_comments_fts").Scan(&indexed)
+	if indexed != comments {
+		t.Errorf("expected %d indexed comments, got %d", comments, indexed)
+	}
+}
+
+// TestSQLiteStore_ExtractSubArchive_InvalidInput tests filter and destination validation
+func TestSQLiteStore_ExtractSubArchive_InvalidInput(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	_, err = store.ExtractSubArchive(ctx, filepath.Join(t.TempDir(), "empty.db"), irowiki.SubArchiveFilter{})
+	if !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for empty filter, got %v", err)
+	}
+
+	_, err = store.ExtractSubArchive(ctx, tdb.Path, irowiki.SubArchiveFilter{Namespaces: []int{0}})
+	if !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for existing destination, got %v", err)
+	}
+}
+
+// TestSQLiteStore_ExtractSubArchive_ReadOnly tests extracting from an
+// archive opened with ReadOnly, which can't be modified
+func TestSQLiteStore_ExtractSubArchive_ReadOnly(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.ReadOnly = true
+	store, err := irowiki.OpenSQLiteStoreWithOptions(tdb.Path, opts)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	result, err := store.ExtractSubArchive(ctx, filepath.Join(t.TempDir(), "poring.db"), irowiki.SubArchiveFilter{Titles: []string{"Poring"}})
+	if err != nil {
+		t.Fatalf("ExtractSubArchive failed: %v", err)
+	}
+	if result.Pages != 1 {
+		t.Errorf("expected 1 page, got %d", result.Pages)
+	}
+
+	if _, err := store.Prune(ctx, irowiki.PrunePolicy{KeepLast: 1}); !errors.Is(err, irowiki.ErrDatabaseError) {
+		t.Errorf("expected ErrDatabaseError modifying a read-only archive, got %v", err)
+	}
+}
+
+// TestEmbeddedFiles tests listing the files embedded in wikitext
+func TestEmbeddedFiles(t *testing.T) {
+	got := irowiki.EmbeddedFiles("[[File:poring_card.png|thumb]] [[image: Map.jpg]] [[File:Poring card.png]] [[Prontera]]")
+	want := []string{"Poring card.png", "Map.jpg"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
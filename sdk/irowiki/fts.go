@@ -0,0 +1,312 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// FTS5 tokenizers supported by RebuildSearchIndex.
+const (
+	// TokenizerPorter applies English stemming on top of unicode61 ("drops" matches "drop").
+	// This is the default and matches the scraper's schema.
+	TokenizerPorter = "porter"
+
+	// TokenizerUnicode61 splits on Unicode word boundaries without stemming, so
+	// proper nouns like "Poring" and "Porings" are kept distinct.
+	TokenizerUnicode61 = "unicode61"
+
+	// TokenizerTrigram indexes every three-character sequence, enabling substring
+	// search ("ronter" matches "Prontera"). Query terms must be at least 3 characters.
+	TokenizerTrigram = "trigram"
+)
+
+// ftsTokenizerKey is the archive_meta key recording the pages_fts tokenize spec.
+const ftsTokenizerKey = "fts_tokenizer"
+
+// defaultFTSTokenize is the tokenize spec used by schema/sqlite/006_fts.sql.
+const defaultFTSTokenize = "porter unicode61"
+
+// FTSOptions configures the tokenizer used when building the full-text index.
+type FTSOptions struct {
+	// Tokenizer is TokenizerPorter (default), TokenizerUnicode61, or TokenizerTrigram.
+	Tokenizer string
+
+	// RemoveDiacritics sets the remove_diacritics option (0, 1, or 2).
+	// Nil uses the tokenizer's default (1 for unicode61/porter, 0 for trigram).
+	RemoveDiacritics *int
+
+	// CaseSensitive makes trigram matching case-sensitive. Only valid for TokenizerTrigram.
+	CaseSensitive bool
+
+	// Progress, if set, is told the pages re-indexed so far every 1000
+	// pages, with stage "reindex".
+	Progress Progress
+}
+
+// Validate checks if the FTSOptions are valid.
+func (o *FTSOptions) Validate() error {
+	switch o.Tokenizer {
+	case "", TokenizerPorter, TokenizerUnicode61, TokenizerTrigram:
+	default:
+		return fmt.Errorf("invalid tokenizer: must be 'porter', 'unicode61', or 'trigram'")
+	}
+
+	if o.RemoveDiacritics != nil && (*o.RemoveDiacritics < 0 || *o.RemoveDiacritics > 2) {
+		return fmt.Errorf("remove_diacritics must be 0, 1, or 2")
+	}
+
+	if o.CaseSensitive && o.Tokenizer != TokenizerTrigram {
+		return fmt.Errorf("case_sensitive is only supported by the trigram tokenizer")
+	}
+	if o.Tokenizer == TokenizerTrigram && o.CaseSensitive && o.RemoveDiacritics != nil && *o.RemoveDiacritics != 0 {
+		return fmt.Errorf("trigram remove_diacritics requires case-insensitive matching")
+	}
+	if o.Tokenizer == TokenizerTrigram && o.RemoveDiacritics != nil && *o.RemoveDiacritics == 2 {
+		return fmt.Errorf("trigram supports remove_diacritics 0 or 1")
+	}
+
+	return nil
+}
+
+// SetDefaults applies default values to FTSOptions.
+func (o *FTSOptions) SetDefaults() {
+	if o.Tokenizer == "" {
+		o.Tokenizer = TokenizerPorter
+	}
+}
+
+// tokenizeSpec returns the FTS5 tokenize argument for these options.
+func (o *FTSOptions) tokenizeSpec() string {
+	var parts []string
+
+	switch o.Tokenizer {
+	case TokenizerTrigram:
+		parts = append(parts, "trigram")
+		if o.CaseSensitive {
+			parts = append(parts, "case_sensitive 1")
+		}
+	case TokenizerUnicode61:
+		parts = append(parts, "unicode61")
+	default:
+		parts = append(parts, "porter unicode61")
+	}
+
+	if o.RemoveDiacritics != nil {
+		parts = append(parts, fmt.Sprintf("remove_diacritics %d", *o.RemoveDiacritics))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// sqliteFTSTable returns the pages_fts DDL for a tokenize spec.
+func sqliteFTSTable(schema, tokenize string) string {
+	return fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s.pages_fts USING fts5(
+		page_id UNINDEXED,
+		title,
+		content,
+		tokenize='%s'
+	)`, schema, tokenize)
+}
+
+// isTrigramTokenize reports whether a tokenize spec uses the trigram tokenizer.
+func isTrigramTokenize(tokenize string) bool {
+	return strings.HasPrefix(strings.TrimSpace(tokenize), "trigram")
+}
+
+// adaptTrigramQuery rewrites an FTS5 query for a trigram index. Trigram
+// indexes already match substrings, so prefix wildcards are dropped, and
+// terms shorter than three characters (which can never match) are removed.
+func adaptTrigramQuery(query string) (string, error) {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if snippetOperators[field] {
+			terms = append(terms, field)
+			continue
+		}
+
+		term := strings.Trim(field, `"*`)
+		if utf8.RuneCountInString(term) < 3 {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+
+	// Drop dangling operators left behind by removed terms
+	for len(terms) > 0 && snippetOperators[terms[0]] {
+		terms = terms[1:]
+	}
+	for len(terms) > 0 && snippetOperators[terms[len(terms)-1]] {
+		terms = terms[:len(terms)-1]
+	}
+
+	if len(terms) == 0 {
+		return "", fmt.Errorf("%w: query terms must be at least 3 characters for a trigram index", ErrInvalidInput)
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// sqliteFTSTokenize returns the tokenize spec recorded in archive_meta,
+// or the schema default if the archive doesn't record one.
+func sqliteFTSTokenize(ctx context.Context, q queryRower, schema string) (string, error) {
+	exists, err := sqliteTableExists(ctx, q, schema, "archive_meta")
+	if err != nil || !exists {
+		return defaultFTSTokenize, err
+	}
+
+	var tokenize string
+	query := fmt.Sprintf("SELECT value FROM %s.archive_meta WHERE key = ?", schema)
+	err = q.QueryRowContext(ctx, query, ftsTokenizerKey).Scan(&tokenize)
+	if err == sql.ErrNoRows {
+		return defaultFTSTokenize, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return tokenize, nil
+}
+
+// RebuildSearchIndex recreates pages_fts with the configured tokenizer,
+// re-indexes the latest revision of every page, and records the choice in archive_meta.
+func (s *sqliteStore) RebuildSearchIndex(ctx context.Context, opts FTSOptions) error {
+	if err := s.ensureNotClosed(); err != nil {
+		return err
+	}
+
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	opts.SetDefaults()
+
+	release, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tokenize := opts.tokenizeSpec()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"DROP TRIGGER IF EXISTS revisions_fts_insert",
+		"DROP TRIGGER IF EXISTS revisions_fts_update",
+		"DROP TRIGGER IF EXISTS pages_fts_update",
+		"DROP TRIGGER IF EXISTS pages_fts_delete",
+		"DROP TABLE IF EXISTS pages_fts",
+		sqliteFTSTable("main", tokenize),
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w: failed to rebuild search index: %v", ErrDatabaseError, err)
+		}
+	}
+
+	if err := reindexPages(ctx, tx, opts.Progress); err != nil {
+		return err
+	}
+
+	statements = append(append([]string{}, sqliteFTSTriggers...), sqliteArchiveMetaSchema...)
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w: failed to rebuild search index: %v", ErrDatabaseError, err)
+		}
+	}
+
+	const record = `
+		INSERT INTO archive_meta (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`
+	if _, err := tx.ExecContext(ctx, record, ftsTokenizerKey, tokenize); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// reindexPages indexes the latest revision of every page in pages_fts, a
+// thousand pages at a time, reporting to progress after each batch.
+func reindexPages(ctx context.Context, tx *sql.Tx, progress Progress) error {
+	const hasRevisions = "EXISTS (SELECT 1 FROM revisions r WHERE r.page_id = p.page_id)"
+
+	var total int64
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages p WHERE "+hasRevisions).Scan(&total); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	var done, lastID int64
+	for done < total {
+		var batchLastID sql.NullInt64
+		err := tx.QueryRowContext(ctx, `
+			SELECT MAX(page_id) FROM (
+				SELECT p.page_id FROM pages p
+				WHERE p.page_id > ? AND `+hasRevisions+`
+				ORDER BY p.page_id
+				LIMIT ?
+			)
+		`, lastID, progressInterval).Scan(&batchLastID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		if !batchLastID.Valid {
+			break
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO pages_fts (page_id, title, content)
+			SELECT p.page_id, p.title,
+			       (SELECT r.content FROM revisions r WHERE r.page_id = p.page_id ORDER BY r.timestamp DESC LIMIT 1)
+			FROM pages p
+			WHERE p.page_id > ? AND p.page_id <= ? AND `+hasRevisions,
+			lastID, batchLastID.Int64)
+		if err != nil {
+			return fmt.Errorf("%w: failed to rebuild search index: %v", ErrDatabaseError, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		done += n
+		lastID = batchLastID.Int64
+		reportProgress(progress, done, total, "reindex")
+	}
+	return nil
+}
+
+// refreshSearchIndex re-indexes the latest revision of the given pages in
+// pages_fts, if the archive has one.
+func refreshSearchIndex(ctx context.Context, tx *sql.Tx, pageIDs []int64) error {
+	if len(pageIDs) == 0 {
+		return nil
+	}
+	exists, err := sqliteTableExists(ctx, tx, "main", "pages_fts")
+	if err != nil || !exists {
+		return err
+	}
+
+	for _, id := range pageIDs {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM pages_fts WHERE page_id = ?", id); err != nil {
+			return fmt.Errorf("%w: failed to refresh search index: %v", ErrDatabaseError, err)
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO pages_fts (page_id, title, content)
+			SELECT p.page_id, p.title,
+			       (SELECT r.content FROM revisions r WHERE r.page_id = p.page_id ORDER BY r.timestamp DESC LIMIT 1)
+			FROM pages p
+			WHERE p.page_id = ? AND EXISTS (SELECT 1 FROM revisions r WHERE r.page_id = p.page_id)`, id)
+		if err != nil {
+			return fmt.Errorf("%w: failed to refresh search index: %v", ErrDatabaseError, err)
+		}
+	}
+	return nil
+}
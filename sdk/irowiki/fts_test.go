@@ -0,0 +1,155 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteStore_RebuildSearchIndex_Trigram tests substring search with a trigram index
+func TestSQLiteStore_RebuildSearchIndex_Trigram(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	var done, total int64
+	err = store.RebuildSearchIndex(ctx, irowiki.FTSOptions{
+		Tokenizer: irowiki.TokenizerTrigram,
+		Progress: irowiki.ProgressFunc(func(d, n int64, stage string) {
+			done, total = d, n
+		}),
+	})
+	if err != nil {
+		t.Fatalf("RebuildSearchIndex failed: %v", err)
+	}
+	if total != 5 || done != total {
+		t.Errorf("expected progress to reach 5/5 pages, got %d/%d", done, total)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	// Test: Substrings match, and prefix wildcards are tolerated
+	results, err := client.SearchFullText(ctx, "ronter*", irowiki.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchFullText failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Prontera" {
+		t.Errorf("expected Prontera for substring query, got %+v", results)
+	}
+
+	// Test: Terms too short for trigrams are rejected
+	_, err = client.SearchFullText(ctx, "ab", irowiki.SearchOptions{})
+	if !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for short query, got %v", err)
+	}
+
+	// Test: The choice is recorded in archive_meta
+	info, err := client.GetArchiveInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetArchiveInfo failed: %v", err)
+	}
+	if info.Meta["fts_tokenizer"] != "trigram" {
+		t.Errorf("expected fts_tokenizer 'trigram', got '%s'", info.Meta["fts_tokenizer"])
+	}
+
+	// Test: Triggers keep the rebuilt index current
+	_, err = tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+		VALUES (200, 3, 104, '2020-02-01 00:00:00', 'Editor', 2, 'Drops', 'Poring drops Jellopy.', 21, 'x', 0)`)
+	if err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+	results, err = client.SearchFullText(ctx, "ellop", irowiki.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchFullText failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Poring" {
+		t.Errorf("expected Poring after edit, got %+v", results)
+	}
+}
+
+// TestSQLiteStore_RebuildSearchIndex_Unicode61 tests disabling stemming
+func TestSQLiteStore_RebuildSearchIndex_Unicode61(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Porter stemming (the default) matches "monsters" to "monster"
+	results, err := client.SearchFullText(ctx, "monsters", irowiki.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchFullText failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 stemmed result, got %d", len(results))
+	}
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	noDiacritics := 2
+	err = store.RebuildSearchIndex(ctx, irowiki.FTSOptions{Tokenizer: irowiki.TokenizerUnicode61, RemoveDiacritics: &noDiacritics})
+	if err != nil {
+		t.Fatalf("RebuildSearchIndex failed: %v", err)
+	}
+
+	results, err = client.SearchFullText(ctx, "monsters", irowiki.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchFullText failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no results without stemming, got %d", len(results))
+	}
+}
+
+// TestSQLiteStore_RebuildSearchIndex_InvalidOptions tests tokenizer option validation
+func TestSQLiteStore_RebuildSearchIndex_InvalidOptions(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	three := 3
+	tests := []struct {
+		name string
+		opts irowiki.FTSOptions
+	}{
+		{"unknown tokenizer", irowiki.FTSOptions{Tokenizer: "icu"}},
+		{"diacritics out of range", irowiki.FTSOptions{RemoveDiacritics: &three}},
+		{"case sensitive without trigram", irowiki.FTSOptions{Tokenizer: irowiki.TokenizerPorter, CaseSensitive: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.RebuildSearchIndex(context.Background(), tt.opts)
+			if !errors.Is(err, irowiki.ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
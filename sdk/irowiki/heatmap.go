@@ -0,0 +1,144 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ActivityScope selects the edits GetActivityHeatmap counts: an editor's
+// (User), a page's (Title), an editor's edits of a page (both), or the whole
+// wiki's (neither). Zero Start and End leave the range open. ExcludeBots
+// leaves out edits by bots.
+type ActivityScope struct {
+	User        string    `json:"user,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Start       time.Time `json:"start,omitzero"`
+	End         time.Time `json:"end,omitzero"`
+	ExcludeBots bool      `json:"exclude_bots,omitempty"`
+}
+
+// ActivityHeatmap counts edits by day of week and hour of day, in UTC, for
+// punch-card charts.
+type ActivityHeatmap struct {
+	// Counts[d][h] is the number of edits on weekday d (time.Sunday is 0)
+	// during hour h.
+	Counts [7][24]int `json:"counts"`
+
+	// Total is the number of edits counted.
+	Total int `json:"total"`
+
+	// Max is the largest count, for scaling colors.
+	Max int `json:"max"`
+}
+
+// Busiest returns the weekday and hour with the most edits, the earliest in
+// the week on ties. It returns Sunday, 0 when there are no edits.
+func (h *ActivityHeatmap) Busiest() (time.Weekday, int) {
+	var day time.Weekday
+	hour := 0
+	for d := range h.Counts {
+		for hr, n := range h.Counts[d] {
+			if n > h.Counts[day][hour] {
+				day, hour = time.Weekday(d), hr
+			}
+		}
+	}
+	return day, hour
+}
+
+// Expressions extracting the weekday (0 is Sunday), hour and month
+// ("YYYY-MM") of r.timestamp. For SQLite, substr drops the fractional seconds
+// and zone suffixes strftime can't parse.
+const (
+	sqliteWeekday   = "CAST(strftime('%w', substr(r.timestamp, 1, 19)) AS INTEGER)"
+	sqliteHour      = "CAST(strftime('%H', substr(r.timestamp, 1, 19)) AS INTEGER)"
+	sqliteMonth     = "strftime('%Y-%m', substr(r.timestamp, 1, 19))"
+	postgresWeekday = "CAST(date_part('dow', r.timestamp) AS INTEGER)"
+	postgresHour    = "CAST(date_part('hour', r.timestamp) AS INTEGER)"
+	postgresMonth   = "to_char(r.timestamp, 'YYYY-MM')"
+)
+
+// getActivityHeatmap counts the edits in as by weekday and hour in a single
+// GROUP BY. weekday and hour are the backend's expressions extracting them
+// from r.timestamp, and bot its condition for r.user being a bot.
+func getActivityHeatmap(ctx context.Context, db querier, scope wikiScope, as ActivityScope, weekday, hour, bot string, placeholder func(n int) string) (*ActivityHeatmap, error) {
+	query := `
+		SELECT ` + weekday + `, ` + hour + `, COUNT(*)
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.timestamp IS NOT NULL` + scope.filter("p")
+	var args []interface{}
+	arg := func(cond string, v interface{}) {
+		args = append(args, v)
+		query += " AND " + cond + placeholder(len(args))
+	}
+
+	if as.Title != "" {
+		page, err := resolveTitle(ctx, db, scope, as.Title, false, placeholder)
+		if err != nil {
+			return nil, err
+		}
+		arg("r.page_id = ", page.ID)
+	}
+	if as.User != "" {
+		arg("r.user = ", as.User)
+	}
+	if !as.Start.IsZero() {
+		arg("r.timestamp >= ", as.Start)
+	}
+	if !as.End.IsZero() {
+		arg("r.timestamp <= ", as.End)
+	}
+	if as.ExcludeBots {
+		query += " AND NOT " + bot
+	}
+	query += " GROUP BY 1, 2"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	heatmap := &ActivityHeatmap{}
+	for rows.Next() {
+		var day, hr sql.NullInt64
+		var count int
+		if err := rows.Scan(&day, &hr, &count); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		// Timestamps the backend can't parse have no bucket
+		if !day.Valid || !hr.Valid || day.Int64 < 0 || day.Int64 > 6 || hr.Int64 < 0 || hr.Int64 > 23 {
+			continue
+		}
+		heatmap.Counts[day.Int64][hr.Int64] += count
+		heatmap.Total += count
+		heatmap.Max = max(heatmap.Max, heatmap.Counts[day.Int64][hr.Int64])
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return heatmap, nil
+}
+
+// GetActivityHeatmap counts the edits in scope by weekday and hour.
+func (c *sqliteClient) GetActivityHeatmap(ctx context.Context, scope ActivityScope) (*ActivityHeatmap, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getActivityHeatmap(ctx, c.db, c.wiki, scope, sqliteWeekday, sqliteHour, sqliteBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
+
+// GetActivityHeatmap counts the edits in scope by weekday and hour.
+func (c *postgresClient) GetActivityHeatmap(ctx context.Context, scope ActivityScope) (*ActivityHeatmap, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getActivityHeatmap(ctx, c.db, c.wiki, scope, postgresWeekday, postgresHour, postgresBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
@@ -0,0 +1,97 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetActivityHeatmap tests counting edits by weekday and
+// hour for the wiki, an editor and a page
+func TestSQLiteClient_GetActivityHeatmap(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// The fixture's edits fall at midnight on 2020-01-01 (a Wednesday)
+	// through 2020-01-07; add two afternoon edits in the formats the
+	// Python scraper writes
+	for _, rev := range []struct {
+		id int64
+		ts string
+	}{{107, "2020-01-08 15:10:00"}, {108, "2020-01-08T15:45:00Z"}} {
+		if _, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+			VALUES (?, 3, ?, 'Admin', 1, 'edit', 'Poring', 6, 'x', 0)`, rev.id, rev.ts); err != nil {
+			t.Fatalf("failed to insert revision: %v", err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		scope irowiki.ActivityScope
+		cells map[[2]int]int
+	}{
+		{"wiki", irowiki.ActivityScope{}, map[[2]int]int{
+			{0, 0}: 1, {1, 0}: 1, {2, 0}: 1, {3, 0}: 1, {3, 15}: 2, {4, 0}: 1, {5, 0}: 1, {6, 0}: 1,
+		}},
+		{"editor", irowiki.ActivityScope{User: "Admin"}, map[[2]int]int{
+			{1, 0}: 1, {2, 0}: 1, {3, 0}: 1, {3, 15}: 2, {5, 0}: 1,
+		}},
+		{"page", irowiki.ActivityScope{Title: "Main_Page"}, map[[2]int]int{{3, 0}: 1, {4, 0}: 1}},
+		{"editor on page", irowiki.ActivityScope{User: "Editor", Title: "Main_Page"}, map[[2]int]int{{4, 0}: 1}},
+		{"range", irowiki.ActivityScope{
+			Start: time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC),
+		}, map[[2]int]int{{0, 0}: 1, {1, 0}: 1}},
+		{"unknown editor", irowiki.ActivityScope{User: "Nobody"}, map[[2]int]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heatmap, err := client.GetActivityHeatmap(ctx, tt.scope)
+			if err != nil {
+				t.Fatalf("GetActivityHeatmap failed: %v", err)
+			}
+
+			total, maxCount := 0, 0
+			for d := range heatmap.Counts {
+				for h, n := range heatmap.Counts[d] {
+					if want := tt.cells[[2]int{d, h}]; n != want {
+						t.Errorf("Counts[%d][%d] = %d, want %d", d, h, n, want)
+					}
+				}
+			}
+			for _, n := range tt.cells {
+				total += n
+				maxCount = max(maxCount, n)
+			}
+			if heatmap.Total != total || heatmap.Max != maxCount {
+				t.Errorf("expected total %d and max %d, got %d and %d", total, maxCount, heatmap.Total, heatmap.Max)
+			}
+		})
+	}
+
+	// Test: Busiest finds the afternoon edits
+	heatmap, err := client.GetActivityHeatmap(ctx, irowiki.ActivityScope{User: "Admin"})
+	if err != nil {
+		t.Fatalf("GetActivityHeatmap failed: %v", err)
+	}
+	if day, hour := heatmap.Busiest(); day != time.Wednesday || hour != 15 {
+		t.Errorf("expected Wednesday 15:00 to be busiest, got %s %d:00", day, hour)
+	}
+
+	// Test: Unknown pages are not found
+	if _, err := client.GetActivityHeatmap(ctx, irowiki.ActivityScope{Title: "Nonexistent"}); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
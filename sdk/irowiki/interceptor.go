@@ -0,0 +1,402 @@
+package irowiki
+
+import (
+	"context"
+	"time"
+)
+
+// Call describes a Client method call seen by an Interceptor.
+type Call struct {
+	// Method is the name of the Client method, such as "GetPage".
+	Method string
+
+	// Args are the method's arguments after the context, in order.
+	Args []any
+}
+
+// Invoker performs a call, returning the method's result (nil for methods
+// that only return an error).
+type Invoker func(ctx context.Context) (any, error)
+
+// Interceptor wraps Client calls. It may inspect or change the context,
+// call next any number of times, or return without calling it, such as to
+// serve a cached result. A result it returns instead of next's must have
+// the method's result type. Concurrent calls run it concurrently, so an
+// interceptor keeping state, such as a cache, must synchronize it.
+type Interceptor func(ctx context.Context, call Call, next Invoker) (any, error)
+
+// interceptedClient runs every call of a Client through an Interceptor.
+type interceptedClient struct {
+	client    Client
+	intercept Interceptor
+}
+
+// WithInterceptor returns a Client that passes every call to client through
+// the interceptors, so concerns such as logging, caching, metrics and
+// access checks can be added without changing callers. The first
+// interceptor is outermost. Close is passed through uncalled.
+//
+// Example:
+//
+//	client = irowiki.WithInterceptor(client, func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+//	    start := time.Now()
+//	    result, err := next(ctx)
+//	    log.Printf("%s took %v", call.Method, time.Since(start))
+//	    return result, err
+//	})
+func WithInterceptor(client Client, interceptors ...Interceptor) Client {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] != nil {
+			client = &interceptedClient{client: client, intercept: interceptors[i]}
+		}
+	}
+	return client
+}
+
+// intercept runs fn through the client's interceptor and converts its
+// result back to the method's type.
+func intercept[T any](c *interceptedClient, ctx context.Context, method string, args []any, fn func(ctx context.Context) (T, error)) (T, error) {
+	result, err := c.intercept(ctx, Call{Method: method, Args: args}, func(ctx context.Context) (any, error) {
+		return fn(ctx)
+	})
+	v, _ := result.(T)
+	return v, err
+}
+
+func (c *interceptedClient) Search(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
+	return intercept(c, ctx, "Search", []any{opts}, func(ctx context.Context) ([]SearchResult, error) {
+		return c.client.Search(ctx, opts)
+	})
+}
+
+func (c *interceptedClient) SearchFullText(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	return intercept(c, ctx, "SearchFullText", []any{query, opts}, func(ctx context.Context) ([]SearchResult, error) {
+		return c.client.SearchFullText(ctx, query, opts)
+	})
+}
+
+func (c *interceptedClient) SearchRevisions(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error) {
+	return intercept(c, ctx, "SearchRevisions", []any{query, opts}, func(ctx context.Context) ([]RevisionSearchResult, error) {
+		return c.client.SearchRevisions(ctx, query, opts)
+	})
+}
+
+func (c *interceptedClient) SearchComments(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error) {
+	return intercept(c, ctx, "SearchComments", []any{query, opts}, func(ctx context.Context) ([]RevisionSearchResult, error) {
+		return c.client.SearchComments(ctx, query, opts)
+	})
+}
+
+func (c *interceptedClient) SearchPaged(ctx context.Context, opts SearchOptions) (*PagedResult, error) {
+	return intercept(c, ctx, "SearchPaged", []any{opts}, func(ctx context.Context) (*PagedResult, error) {
+		return c.client.SearchPaged(ctx, opts)
+	})
+}
+
+func (c *interceptedClient) SuggestTitles(ctx context.Context, prefix string, limit int) (*TitleSuggestions, error) {
+	return intercept(c, ctx, "SuggestTitles", []any{prefix, limit}, func(ctx context.Context) (*TitleSuggestions, error) {
+		return c.client.SuggestTitles(ctx, prefix, limit)
+	})
+}
+
+func (c *interceptedClient) GetPage(ctx context.Context, title string, opts ...PageOption) (*Page, error) {
+	args := []any{title}
+	if len(opts) > 0 {
+		args = append(args, NewPageOptions(opts...))
+	}
+	return intercept(c, ctx, "GetPage", args, func(ctx context.Context) (*Page, error) {
+		return c.client.GetPage(ctx, title, opts...)
+	})
+}
+
+func (c *interceptedClient) GetPagesByTitle(ctx context.Context, titles []string) ([]TitleResolution, error) {
+	return intercept(c, ctx, "GetPagesByTitle", []any{titles}, func(ctx context.Context) ([]TitleResolution, error) {
+		return c.client.GetPagesByTitle(ctx, titles)
+	})
+}
+
+func (c *interceptedClient) GetPageHTML(ctx context.Context, title string) (*PageHTML, error) {
+	return intercept(c, ctx, "GetPageHTML", []any{title}, func(ctx context.Context) (*PageHTML, error) {
+		return c.client.GetPageHTML(ctx, title)
+	})
+}
+
+func (c *interceptedClient) ResolveAmbiguousTitle(ctx context.Context, title string) (*AmbiguousTitle, error) {
+	return intercept(c, ctx, "ResolveAmbiguousTitle", []any{title}, func(ctx context.Context) (*AmbiguousTitle, error) {
+		return c.client.ResolveAmbiguousTitle(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetRedirectsTo(ctx context.Context, title string) ([]Page, error) {
+	return intercept(c, ctx, "GetRedirectsTo", []any{title}, func(ctx context.Context) ([]Page, error) {
+		return c.client.GetRedirectsTo(ctx, title)
+	})
+}
+
+func (c *interceptedClient) FindDoubleRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error) {
+	return intercept(c, ctx, "FindDoubleRedirects", []any{offset, limit}, func(ctx context.Context) (*RedirectReport, error) {
+		return c.client.FindDoubleRedirects(ctx, offset, limit)
+	})
+}
+
+func (c *interceptedClient) FindBrokenRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error) {
+	return intercept(c, ctx, "FindBrokenRedirects", []any{offset, limit}, func(ctx context.Context) (*RedirectReport, error) {
+		return c.client.FindBrokenRedirects(ctx, offset, limit)
+	})
+}
+
+func (c *interceptedClient) GetModuleDependencies(ctx context.Context, title string) ([]ModuleDependency, error) {
+	return intercept(c, ctx, "GetModuleDependencies", []any{title}, func(ctx context.Context) ([]ModuleDependency, error) {
+		return c.client.GetModuleDependencies(ctx, title)
+	})
+}
+
+func (c *interceptedClient) ExtractTemplates(ctx context.Context, title, templateName string) ([]TemplateCall, error) {
+	return intercept(c, ctx, "ExtractTemplates", []any{title, templateName}, func(ctx context.Context) ([]TemplateCall, error) {
+		return c.client.ExtractTemplates(ctx, title, templateName)
+	})
+}
+
+func (c *interceptedClient) GetOutgoingLinks(ctx context.Context, title string) ([]PageLink, error) {
+	return intercept(c, ctx, "GetOutgoingLinks", []any{title}, func(ctx context.Context) ([]PageLink, error) {
+		return c.client.GetOutgoingLinks(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetBacklinks(ctx context.Context, title string) ([]PageLink, error) {
+	return intercept(c, ctx, "GetBacklinks", []any{title}, func(ctx context.Context) ([]PageLink, error) {
+		return c.client.GetBacklinks(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetPageCategories(ctx context.Context, title string) ([]string, error) {
+	return intercept(c, ctx, "GetPageCategories", []any{title}, func(ctx context.Context) ([]string, error) {
+		return c.client.GetPageCategories(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetPagesInCategory(ctx context.Context, category string, opts CategoryOptions) ([]CategoryMember, error) {
+	return intercept(c, ctx, "GetPagesInCategory", []any{category, opts}, func(ctx context.Context) ([]CategoryMember, error) {
+		return c.client.GetPagesInCategory(ctx, category, opts)
+	})
+}
+
+func (c *interceptedClient) GetPageByID(ctx context.Context, id int64) (*Page, error) {
+	return intercept(c, ctx, "GetPageByID", []any{id}, func(ctx context.Context) (*Page, error) {
+		return c.client.GetPageByID(ctx, id)
+	})
+}
+
+func (c *interceptedClient) ListPages(ctx context.Context, namespace int, offset, limit int) ([]Page, error) {
+	return intercept(c, ctx, "ListPages", []any{namespace, offset, limit}, func(ctx context.Context) ([]Page, error) {
+		return c.client.ListPages(ctx, namespace, offset, limit)
+	})
+}
+
+func (c *interceptedClient) GetPageHistory(ctx context.Context, title string, opts HistoryOptions) ([]Revision, error) {
+	return intercept(c, ctx, "GetPageHistory", []any{title, opts}, func(ctx context.Context) ([]Revision, error) {
+		return c.client.GetPageHistory(ctx, title, opts)
+	})
+}
+
+func (c *interceptedClient) GetRevision(ctx context.Context, revisionID int64) (*Revision, error) {
+	return intercept(c, ctx, "GetRevision", []any{revisionID}, func(ctx context.Context) (*Revision, error) {
+		return c.client.GetRevision(ctx, revisionID)
+	})
+}
+
+func (c *interceptedClient) GetRevisionsByID(ctx context.Context, ids []int64) ([]*Revision, error) {
+	return intercept(c, ctx, "GetRevisionsByID", []any{ids}, func(ctx context.Context) ([]*Revision, error) {
+		return c.client.GetRevisionsByID(ctx, ids)
+	})
+}
+
+func (c *interceptedClient) GetPageAtTime(ctx context.Context, title string, timestamp time.Time) (*Revision, error) {
+	return intercept(c, ctx, "GetPageAtTime", []any{title, timestamp}, func(ctx context.Context) (*Revision, error) {
+		return c.client.GetPageAtTime(ctx, title, timestamp)
+	})
+}
+
+func (c *interceptedClient) GetChangesByPeriod(ctx context.Context, start, end time.Time) ([]Revision, error) {
+	return intercept(c, ctx, "GetChangesByPeriod", []any{start, end}, func(ctx context.Context) ([]Revision, error) {
+		return c.client.GetChangesByPeriod(ctx, start, end)
+	})
+}
+
+func (c *interceptedClient) GetChangesByPeriodGrouped(ctx context.Context, start, end time.Time, opts ChangesGroupOptions) ([]ChangeGroup, error) {
+	return intercept(c, ctx, "GetChangesByPeriodGrouped", []any{start, end, opts}, func(ctx context.Context) ([]ChangeGroup, error) {
+		return c.client.GetChangesByPeriodGrouped(ctx, start, end, opts)
+	})
+}
+
+func (c *interceptedClient) GetRecentChanges(ctx context.Context, opts ChangesOptions) ([]Change, error) {
+	return intercept(c, ctx, "GetRecentChanges", []any{opts}, func(ctx context.Context) ([]Change, error) {
+		return c.client.GetRecentChanges(ctx, opts)
+	})
+}
+
+func (c *interceptedClient) GetEditorActivity(ctx context.Context, username string, start, end time.Time) ([]Revision, error) {
+	return intercept(c, ctx, "GetEditorActivity", []any{username, start, end}, func(ctx context.Context) ([]Revision, error) {
+		return c.client.GetEditorActivity(ctx, username, start, end)
+	})
+}
+
+func (c *interceptedClient) GetStatistics(ctx context.Context) (*Statistics, error) {
+	return intercept(c, ctx, "GetStatistics", nil, func(ctx context.Context) (*Statistics, error) {
+		return c.client.GetStatistics(ctx)
+	})
+}
+
+func (c *interceptedClient) GetStatisticsEnhanced(ctx context.Context) (*StatisticsEnhanced, error) {
+	return intercept(c, ctx, "GetStatisticsEnhanced", nil, func(ctx context.Context) (*StatisticsEnhanced, error) {
+		return c.client.GetStatisticsEnhanced(ctx)
+	})
+}
+
+func (c *interceptedClient) GetPageStats(ctx context.Context, title string) (*PageStatistics, error) {
+	return intercept(c, ctx, "GetPageStats", []any{title}, func(ctx context.Context) (*PageStatistics, error) {
+		return c.client.GetPageStats(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetPageStatsEnhanced(ctx context.Context, title string) (*PageStatisticsEnhanced, error) {
+	return intercept(c, ctx, "GetPageStatsEnhanced", []any{title}, func(ctx context.Context) (*PageStatisticsEnhanced, error) {
+		return c.client.GetPageStatsEnhanced(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetPageContributorsTimeline(ctx context.Context, title string) (*PageContributorsTimeline, error) {
+	return intercept(c, ctx, "GetPageContributorsTimeline", []any{title}, func(ctx context.Context) (*PageContributorsTimeline, error) {
+		return c.client.GetPageContributorsTimeline(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetPageCreationCohorts(ctx context.Context) ([]PageCohort, error) {
+	return intercept(c, ctx, "GetPageCreationCohorts", nil, func(ctx context.Context) ([]PageCohort, error) {
+		return c.client.GetPageCreationCohorts(ctx)
+	})
+}
+
+func (c *interceptedClient) GetQualityOverview(ctx context.Context) (*QualityOverview, error) {
+	return intercept(c, ctx, "GetQualityOverview", nil, func(ctx context.Context) (*QualityOverview, error) {
+		return c.client.GetQualityOverview(ctx)
+	})
+}
+
+func (c *interceptedClient) SuggestCategories(ctx context.Context, title string, n int) ([]CategorySuggestion, error) {
+	return intercept(c, ctx, "SuggestCategories", []any{title, n}, func(ctx context.Context) ([]CategorySuggestion, error) {
+		return c.client.SuggestCategories(ctx, title, n)
+	})
+}
+
+func (c *interceptedClient) GetEditorActivityEnhanced(ctx context.Context, username string, start, end time.Time) (*EditorActivity, error) {
+	return intercept(c, ctx, "GetEditorActivityEnhanced", []any{username, start, end}, func(ctx context.Context) (*EditorActivity, error) {
+		return c.client.GetEditorActivityEnhanced(ctx, username, start, end)
+	})
+}
+
+func (c *interceptedClient) GetEditorActivityWithOptions(ctx context.Context, username string, opts EditorActivityOptions) (*EditorActivity, error) {
+	return intercept(c, ctx, "GetEditorActivityWithOptions", []any{username, opts}, func(ctx context.Context) (*EditorActivity, error) {
+		return c.client.GetEditorActivityWithOptions(ctx, username, opts)
+	})
+}
+
+func (c *interceptedClient) GetTopEditorsForScope(ctx context.Context, scope EditorScope, n int) ([]EditorStat, error) {
+	return intercept(c, ctx, "GetTopEditorsForScope", []any{scope, n}, func(ctx context.Context) ([]EditorStat, error) {
+		return c.client.GetTopEditorsForScope(ctx, scope, n)
+	})
+}
+
+func (c *interceptedClient) GetActivityHeatmap(ctx context.Context, scope ActivityScope) (*ActivityHeatmap, error) {
+	return intercept(c, ctx, "GetActivityHeatmap", []any{scope}, func(ctx context.Context) (*ActivityHeatmap, error) {
+		return c.client.GetActivityHeatmap(ctx, scope)
+	})
+}
+
+func (c *interceptedClient) GetRevisionDiff(ctx context.Context, fromRevID, toRevID int64) (*DiffResult, error) {
+	return intercept(c, ctx, "GetRevisionDiff", []any{fromRevID, toRevID}, func(ctx context.Context) (*DiffResult, error) {
+		return c.client.GetRevisionDiff(ctx, fromRevID, toRevID)
+	})
+}
+
+func (c *interceptedClient) GetConsecutiveDiff(ctx context.Context, revID int64) (*DiffResult, error) {
+	return intercept(c, ctx, "GetConsecutiveDiff", []any{revID}, func(ctx context.Context) (*DiffResult, error) {
+		return c.client.GetConsecutiveDiff(ctx, revID)
+	})
+}
+
+func (c *interceptedClient) CompareRevisions(ctx context.Context, fromRevID, toRevID int64, opts DiffOptions) (*DiffResult, error) {
+	return intercept(c, ctx, "CompareRevisions", []any{fromRevID, toRevID, opts}, func(ctx context.Context) (*DiffResult, error) {
+		return c.client.CompareRevisions(ctx, fromRevID, toRevID, opts)
+	})
+}
+
+func (c *interceptedClient) ClassifyRevisions(ctx context.Context, title string) ([]RevisionClassification, error) {
+	return intercept(c, ctx, "ClassifyRevisions", []any{title}, func(ctx context.Context) ([]RevisionClassification, error) {
+		return c.client.ClassifyRevisions(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetFile(ctx context.Context, filename string) (*File, error) {
+	return intercept(c, ctx, "GetFile", []any{filename}, func(ctx context.Context) (*File, error) {
+		return c.client.GetFile(ctx, filename)
+	})
+}
+
+func (c *interceptedClient) ListFiles(ctx context.Context, offset, limit int) ([]File, error) {
+	return intercept(c, ctx, "ListFiles", []any{offset, limit}, func(ctx context.Context) ([]File, error) {
+		return c.client.ListFiles(ctx, offset, limit)
+	})
+}
+
+func (c *interceptedClient) GetExternalLinks(ctx context.Context, title string) ([]ExternalLink, error) {
+	return intercept(c, ctx, "GetExternalLinks", []any{title}, func(ctx context.Context) ([]ExternalLink, error) {
+		return c.client.GetExternalLinks(ctx, title)
+	})
+}
+
+func (c *interceptedClient) GetArchiveInfo(ctx context.Context) (*ArchiveInfo, error) {
+	return intercept(c, ctx, "GetArchiveInfo", nil, func(ctx context.Context) (*ArchiveInfo, error) {
+		return c.client.GetArchiveInfo(ctx)
+	})
+}
+
+func (c *interceptedClient) ListWikis(ctx context.Context) ([]Wiki, error) {
+	return intercept(c, ctx, "ListWikis", nil, func(ctx context.Context) ([]Wiki, error) {
+		return c.client.ListWikis(ctx)
+	})
+}
+
+func (c *interceptedClient) GetEquivalentPage(ctx context.Context, wikiID, title, targetWiki string) (*Page, error) {
+	return intercept(c, ctx, "GetEquivalentPage", []any{wikiID, title, targetWiki}, func(ctx context.Context) (*Page, error) {
+		return c.client.GetEquivalentPage(ctx, wikiID, title, targetWiki)
+	})
+}
+
+func (c *interceptedClient) ExportRows(ctx context.Context, opts ExportOptions, w RowWriter) error {
+	_, err := intercept(c, ctx, "ExportRows", []any{opts, w}, func(ctx context.Context) (any, error) {
+		return nil, c.client.ExportRows(ctx, opts, w)
+	})
+	return err
+}
+
+func (c *interceptedClient) Snapshot(ctx context.Context) (Client, error) {
+	snapshot, err := intercept(c, ctx, "Snapshot", nil, func(ctx context.Context) (Client, error) {
+		return c.client.Snapshot(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &interceptedClient{client: snapshot, intercept: c.intercept}, nil
+}
+
+func (c *interceptedClient) Ping(ctx context.Context) error {
+	_, err := intercept(c, ctx, "Ping", nil, func(ctx context.Context) (any, error) {
+		return nil, c.client.Ping(ctx)
+	})
+	return err
+}
+
+func (c *interceptedClient) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,91 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestWithInterceptor tests wrapping client calls with interceptors
+func TestWithInterceptor(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+
+	ctx := context.Background()
+	var trace []string
+	tracer := func(name string) irowiki.Interceptor {
+		return func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+			trace = append(trace, name+">"+call.Method)
+			result, err := next(ctx)
+			trace = append(trace, name+"<"+call.Method)
+			return result, err
+		}
+	}
+
+	cache := map[string]any{}
+	caching := func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+		if call.Method != "GetPage" {
+			return next(ctx)
+		}
+		key := call.Args[0].(string)
+		if result, ok := cache[key]; ok {
+			return result, nil
+		}
+		result, err := next(ctx)
+		if err == nil {
+			cache[key] = result
+		}
+		return result, err
+	}
+
+	wrapped := irowiki.WithInterceptor(client, tracer("outer"), tracer("inner"), caching)
+	defer wrapped.Close()
+
+	// Test: Interceptors run in order around the call
+	page, err := wrapped.GetPage(ctx, "Poring")
+	if err != nil || page.ID != 3 {
+		t.Fatalf("expected page 3, got %v, %v", page, err)
+	}
+	want := []string{"outer>GetPage", "inner>GetPage", "inner<GetPage", "outer<GetPage"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("expected trace %v, got %v", want, trace)
+	}
+
+	// Test: An interceptor can return a result without calling the client
+	client.Close()
+	cached, err := wrapped.GetPage(ctx, "Poring")
+	if err != nil || cached != page {
+		t.Errorf("expected the cached page, got %v, %v", cached, err)
+	}
+
+	// Test: Errors and nil results reach the caller unchanged
+	if _, err := wrapped.GetPage(ctx, "Prontera"); !errors.Is(err, irowiki.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if err := wrapped.Ping(ctx); !errors.Is(err, irowiki.ErrClosed) {
+		t.Errorf("expected ErrClosed from Ping, got %v", err)
+	}
+
+	// Test: Arguments are passed to interceptors
+	var args []any
+	spy := irowiki.WithInterceptor(client, func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+		args = call.Args
+		return nil, nil
+	})
+	pages, err := spy.ListPages(ctx, 0, 5, 10)
+	if err != nil || pages != nil {
+		t.Errorf("expected no pages, got %v, %v", pages, err)
+	}
+	if !reflect.DeepEqual(args, []any{0, 5, 10}) {
+		t.Errorf("expected args [0 5 10], got %v", args)
+	}
+}
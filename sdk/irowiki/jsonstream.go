@@ -0,0 +1,162 @@
+package irowiki
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"time"
+)
+
+// JSONArrayWriter writes a JSON array to an io.Writer one element at a time,
+// so arrays of millions of revisions or rows never have to be held in memory.
+// Elements go on their own lines:
+//
+//	[
+//	  {"id":1},
+//	  {"id":2}
+//	]
+//
+// Close ends the array; an array without elements is written as "[]".
+type JSONArrayWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+// NewJSONArrayWriter returns a JSONArrayWriter writing to w.
+func NewJSONArrayWriter(w io.Writer) *JSONArrayWriter {
+	return &JSONArrayWriter{w: w}
+}
+
+// Write encodes v as the next element of the array.
+func (a *JSONArrayWriter) Write(v any) error {
+	if a.err != nil {
+		return a.err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		a.err = err
+		return err
+	}
+	return a.WriteRaw(data)
+}
+
+// WriteRaw writes data, which must be valid JSON, as the next element of the
+// array, for elements encoded by the caller.
+func (a *JSONArrayWriter) WriteRaw(data []byte) error {
+	if a.err != nil {
+		return a.err
+	}
+	sep := ",\n  "
+	if a.n == 0 {
+		sep = "[\n  "
+	}
+	if _, a.err = io.WriteString(a.w, sep); a.err == nil {
+		_, a.err = a.w.Write(data)
+	}
+	a.n++
+	return a.err
+}
+
+// Len returns the number of elements written.
+func (a *JSONArrayWriter) Len() int {
+	return a.n
+}
+
+// Close ends the array. It doesn't close the underlying writer.
+func (a *JSONArrayWriter) Close() error {
+	if a.err != nil {
+		return a.err
+	}
+	end := "\n]\n"
+	if a.n == 0 {
+		end = "[]\n"
+	}
+	_, a.err = io.WriteString(a.w, end)
+	return a.err
+}
+
+// EncodeJSONArray writes the values of seq to w as a JSON array, stopping at
+// the first error seq yields. The array is left unterminated on errors, so a
+// truncated stream isn't mistaken for a complete one.
+func EncodeJSONArray[T any](w io.Writer, seq iter.Seq2[T, error]) error {
+	a := NewJSONArrayWriter(w)
+	for v, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := a.Write(v); err != nil {
+			return err
+		}
+	}
+	return a.Close()
+}
+
+// revisionJSON is the JSON form of a revision written by EncodeRevisionsJSON.
+type revisionJSON struct {
+	ID        int64     `json:"id"`
+	PageID    int64     `json:"page_id"`
+	ParentID  *int64    `json:"parent_id"`
+	Timestamp time.Time `json:"timestamp"`
+	User      string    `json:"user"`
+	UserID    *int      `json:"user_id"`
+	Comment   string    `json:"comment"`
+	Content   string    `json:"content,omitempty"`
+	Size      int       `json:"size"`
+	SHA1      string    `json:"sha1"`
+	Minor     bool      `json:"minor"`
+	Tags      []string  `json:"tags,omitempty"`
+}
+
+// EncodeRevisionsJSON writes the revisions of seq to w as a JSON array of
+// objects with snake_case keys ("id", "page_id", "parent_id", ...), as
+// EncodeJSONArray does. Empty content is left out, for revisions read with
+// HistoryOptions.ExcludeContent.
+func EncodeRevisionsJSON(w io.Writer, revisions iter.Seq2[Revision, error]) error {
+	return EncodeJSONArray(w, func(yield func(revisionJSON, error) bool) {
+		for rev, err := range revisions {
+			if !yield(revisionJSON(rev), err) || err != nil {
+				return
+			}
+		}
+	})
+}
+
+// historyBatchSize is the number of revisions PageHistoryRevisions reads at a
+// time.
+const historyBatchSize = 500
+
+// PageHistoryRevisions iterates over a page's history as GetPageHistory
+// returns it, newest first, reading it in batches so only one batch is held
+// in memory. opts.Offset and opts.Limit select the revisions as for
+// GetPageHistory, except that a zero Limit means the whole history.
+func PageHistoryRevisions(ctx context.Context, client Client, title string, opts HistoryOptions) iter.Seq2[Revision, error] {
+	return func(yield func(Revision, error) bool) {
+		remaining := opts.Limit
+		for {
+			batch := opts
+			batch.Limit = historyBatchSize
+			if opts.Limit > 0 {
+				batch.Limit = min(historyBatchSize, remaining)
+			}
+
+			revisions, err := client.GetPageHistory(ctx, title, batch)
+			if err != nil {
+				yield(Revision{}, err)
+				return
+			}
+			for _, rev := range revisions {
+				if !yield(rev, nil) {
+					return
+				}
+			}
+
+			opts.Offset += len(revisions)
+			remaining -= len(revisions)
+			if len(revisions) < batch.Limit || (opts.Limit > 0 && remaining <= 0) {
+				return
+			}
+		}
+	}
+}
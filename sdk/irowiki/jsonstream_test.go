@@ -0,0 +1,120 @@
+package irowiki_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestJSONArrayWriter tests streaming arrays element by element
+func TestJSONArrayWriter(t *testing.T) {
+	var buf bytes.Buffer
+	a := irowiki.NewJSONArrayWriter(&buf)
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("expected empty array, got %q", buf.String())
+	}
+
+	buf.Reset()
+	a = irowiki.NewJSONArrayWriter(&buf)
+	if err := a.Write(map[string]int{"id": 1}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := a.WriteRaw([]byte(`{"id": 2}`)); err != nil {
+		t.Fatalf("WriteRaw failed: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if want := "[\n  {\"id\":1},\n  {\"id\": 2}\n]\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+	if a.Len() != 2 {
+		t.Errorf("expected 2 elements, got %d", a.Len())
+	}
+
+	// Test: Values that can't be encoded are errors
+	if err := irowiki.NewJSONArrayWriter(&buf).Write(func() {}); err == nil {
+		t.Error("expected an error encoding a func")
+	}
+}
+
+// TestEncodeJSONArray_Error tests that failed streams aren't terminated
+func TestEncodeJSONArray_Error(t *testing.T) {
+	failed := errors.New("read failed")
+	seq := func(yield func(int, error) bool) {
+		if yield(1, nil) {
+			yield(0, failed)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := irowiki.EncodeJSONArray(&buf, iter.Seq2[int, error](seq)); !errors.Is(err, failed) {
+		t.Fatalf("expected the stream's error, got %v", err)
+	}
+	if buf.String() != "[\n  1" {
+		t.Errorf("expected an unterminated array, got %q", buf.String())
+	}
+}
+
+// TestEncodeRevisionsJSON tests streaming a page's history
+func TestEncodeRevisionsJSON(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	revisions := irowiki.PageHistoryRevisions(ctx, client, "Prontera", irowiki.HistoryOptions{ExcludeContent: true})
+	if err := irowiki.EncodeRevisionsJSON(&buf, revisions); err != nil {
+		t.Fatalf("EncodeRevisionsJSON failed: %v", err)
+	}
+
+	var out []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("failed to decode output: %v\n%s", err, buf.String())
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(out))
+	}
+	if out[0]["id"] != float64(103) || out[0]["parent_id"] != float64(102) || out[0]["minor"] != true {
+		t.Errorf("unexpected first revision: %v", out[0])
+	}
+	if out[1]["parent_id"] != nil {
+		t.Errorf("expected no parent for the first revision, got %v", out[1]["parent_id"])
+	}
+	if _, ok := out[0]["content"]; ok {
+		t.Error("expected excluded content to be left out")
+	}
+
+	// Test: Offsets and limits select revisions as for GetPageHistory
+	var ids []int64
+	for rev, err := range irowiki.PageHistoryRevisions(ctx, client, "Prontera", irowiki.HistoryOptions{Offset: 1, Limit: 5}) {
+		if err != nil {
+			t.Fatalf("PageHistoryRevisions failed: %v", err)
+		}
+		ids = append(ids, rev.ID)
+	}
+	if len(ids) != 1 || ids[0] != 102 {
+		t.Errorf("expected revision 102, got %v", ids)
+	}
+
+	// Test: Missing pages end the stream with an error
+	err = irowiki.EncodeRevisionsJSON(&buf, irowiki.PageHistoryRevisions(ctx, client, "Nonexistent", irowiki.HistoryOptions{}))
+	if !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,460 @@
+package irowiki
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+// Types of links between pages, as stored in the links table.
+const (
+	// LinkTypePage is a wikilink ([[Target]]).
+	LinkTypePage = "page"
+
+	// LinkTypeTemplate is a transclusion ({{Target}}).
+	LinkTypeTemplate = "template"
+
+	// LinkTypeFile is a file embed ([[File:Target.png]]).
+	LinkTypeFile = "file"
+
+	// LinkTypeCategory is a category membership ([[Category:Target]]).
+	LinkTypeCategory = "category"
+)
+
+// PageLink is a link from one page to another.
+type PageLink struct {
+	// SourceID is the ID of the linking page.
+	SourceID int64 `json:"source_id"`
+
+	// Source is the full title of the linking page.
+	Source string `json:"source"`
+
+	// Target is the full title of the linked page, such as
+	// "Template:Monster" for a transclusion of {{Monster}}.
+	Target string `json:"target"`
+
+	// TargetID is the ID of the linked page, or 0 if the archive doesn't
+	// have it.
+	TargetID int64 `json:"target_id,omitempty"`
+
+	// Type is one of the LinkType constants.
+	Type string `json:"type"`
+}
+
+// RebuildLinksResult summarizes a Store.RebuildLinks run.
+type RebuildLinksResult struct {
+	// Pages is the number of pages whose latest revision was parsed.
+	Pages int64 `json:"pages"`
+
+	// Links is the number of links recorded.
+	Links int64 `json:"links"`
+}
+
+// storedLink is a row of the links table.
+type storedLink struct {
+	target   string
+	linkType string
+}
+
+// extractPageLinks returns the distinct links in wikitext, in the form the
+// scraper stores them: titles with spaces, templates, files and categories
+// without their namespace prefix, and other pages with it. Transclusions
+// of pages outside the Template namespace keep their prefix, with a
+// leading colon for the main namespace ({{:Main Page}}).
+func extractPageLinks(content string) []storedLink {
+	return documentLinks(wikitext.Parse(content))
+}
+
+// documentLinks returns the distinct links of a parsed page, as
+// extractPageLinks does.
+func documentLinks(doc *wikitext.Document) []storedLink {
+	seen := make(map[storedLink]bool)
+	var links []storedLink
+	add := func(target, linkType string) {
+		l := storedLink{strings.ReplaceAll(target, "_", " "), linkType}
+		if target != "" && !seen[l] {
+			seen[l] = true
+			links = append(links, l)
+		}
+	}
+
+	wikitext.Inspect(doc, func(n wikitext.Node) bool {
+		switch n := n.(type) {
+		case *wikitext.Link:
+			page := n.Page()
+			if page == "" {
+				// A link to a section of the page itself
+				return true
+			}
+			ns, title := ParseTitle(page)
+			switch {
+			case strings.HasPrefix(page, ":") || (ns != NamespaceFile && ns != NamespaceCategory):
+				add(FullTitle(ns, title), LinkTypePage)
+			case ns == NamespaceFile:
+				add(title, LinkTypeFile)
+			default:
+				add(title, LinkTypeCategory)
+			}
+		case *wikitext.Template:
+			full := templateTitle(n.Name)
+			if full == "" {
+				// Parser functions aren't pages
+				return true
+			}
+			switch ns, title := ParseTitle(full); ns {
+			case NamespaceTemplate:
+				add(title, LinkTypeTemplate)
+			case NamespaceMain:
+				add(":"+title, LinkTypeTemplate)
+			default:
+				add(full, LinkTypeTemplate)
+			}
+		}
+		return true
+	})
+	return links
+}
+
+// replaceLinks records the links of a page's content in place of those it had.
+func replaceLinks(ctx context.Context, db execer, postgres bool, pageID int64, content string) (int64, error) {
+	if _, err := db.ExecContext(ctx, writerQuery("DELETE FROM links WHERE source_page_id = ?", postgres), pageID); err != nil {
+		return 0, fmt.Errorf("%w: failed to clear links of page %d: %v", ErrDatabaseError, pageID, err)
+	}
+
+	links := extractPageLinks(content)
+	query := writerQuery(`
+		INSERT INTO links (source_page_id, target_title, link_type)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING`, postgres)
+	for _, l := range links {
+		if _, err := db.ExecContext(ctx, query, pageID, l.target, l.linkType); err != nil {
+			return 0, fmt.Errorf("%w: failed to write links of page %d: %v", ErrDatabaseError, pageID, err)
+		}
+	}
+	return int64(len(links)), nil
+}
+
+// rebuildLinks parses the latest revision of every page and replaces the
+// links table with their links, in one transaction.
+func rebuildLinks(ctx context.Context, db *sql.DB, postgres bool) (*RebuildLinksResult, error) {
+	const query = `
+		SELECT r.page_id, r.content
+		FROM revisions r
+		WHERE r.revision_id = (
+			SELECT r2.revision_id FROM revisions r2
+			WHERE r2.page_id = r.page_id
+			ORDER BY r2.timestamp DESC
+			LIMIT 1
+		)
+	`
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	// Links are collected before writing, as SQLite can't write on a
+	// connection while reading from it
+	type pageLinkSet struct {
+		pageID int64
+		links  []storedLink
+	}
+	var pages []pageLinkSet
+	for rows.Next() {
+		var pageID int64
+		var content string
+		if err := rows.Scan(&pageID, &content); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		pages = append(pages, pageLinkSet{pageID, extractPageLinks(content)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM links"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	insert, err := tx.PrepareContext(ctx, writerQuery(`
+		INSERT INTO links (source_page_id, target_title, link_type)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING`, postgres))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer insert.Close()
+
+	result := &RebuildLinksResult{Pages: int64(len(pages))}
+	for _, p := range pages {
+		for _, l := range p.links {
+			if _, err := insert.ExecContext(ctx, p.pageID, l.target, l.linkType); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+			}
+			result.Links++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return result, nil
+}
+
+// linkTargetTitle returns the full title of a stored link's target, or ""
+// for the parser functions older scrapers recorded as templates.
+func linkTargetTitle(l storedLink) string {
+	switch l.linkType {
+	case LinkTypeTemplate:
+		if strings.HasPrefix(l.target, "#") {
+			return ""
+		}
+		if strings.HasPrefix(l.target, ":") {
+			return storedTitle(l.target[1:])
+		}
+		if ns, _ := ParseTitle(l.target); ns != NamespaceMain {
+			return FullTitle(ParseTitle(l.target))
+		}
+		return FullTitle(NamespaceTemplate, l.target)
+	case LinkTypeFile:
+		return FullTitle(NamespaceFile, l.target)
+	case LinkTypeCategory:
+		return FullTitle(NamespaceCategory, l.target)
+	}
+	return FullTitle(ParseTitle(l.target))
+}
+
+// getOutgoingLinks lists the links recorded for a page. The caller checks
+// that the links table exists.
+func getOutgoingLinks(ctx context.Context, db querier, scope wikiScope, page *Page, placeholder func(n int) string) ([]PageLink, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT target_title, link_type FROM links
+		WHERE source_page_id = `+placeholder(1), page.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	source := FullTitle(page.Namespace, page.Title)
+	links := []PageLink{}
+	seen := make(map[PageLink]bool)
+	for rows.Next() {
+		var l storedLink
+		if err := rows.Scan(&l.target, &l.linkType); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		// Archives written by different scrapers may store a link both
+		// with spaces and with underscores
+		link := PageLink{SourceID: page.ID, Source: source, Target: linkTargetTitle(l), Type: l.linkType}
+		if link.Target != "" && !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	if len(links) == 0 {
+		return links, nil
+	}
+
+	targets := make([]string, len(links))
+	for i, l := range links {
+		targets[i] = l.Target
+	}
+	resolved, err := getPagesByTitle(ctx, db, scope, targets, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range resolved {
+		if r.Found() {
+			links[i].TargetID = r.Page.ID
+		}
+	}
+
+	slices.SortFunc(links, func(a, b PageLink) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Target, b.Target))
+	})
+	return links, nil
+}
+
+// getBacklinks lists the pages linking to a page. The caller checks that
+// the links table exists.
+func getBacklinks(ctx context.Context, db querier, scope wikiScope, page *Page, placeholder func(n int) string) ([]PageLink, error) {
+	// Page links name the target with its prefix; templates, files and
+	// categories without it, though older archives may have it
+	ns, title := page.Namespace, page.Title
+	if parsed, name := ParseTitle(title); parsed == ns && ns != NamespaceMain {
+		title = name
+	}
+	full := strings.ReplaceAll(FullTitle(ns, title), "_", " ")
+	bare := strings.ReplaceAll(storedTitle(title), "_", " ")
+	var typed string
+	var targets []string
+	switch ns {
+	case NamespaceTemplate:
+		typed, targets = LinkTypeTemplate, []string{full, bare}
+	case NamespaceFile:
+		typed, targets = LinkTypeFile, []string{full, bare}
+	case NamespaceCategory:
+		typed, targets = LinkTypeCategory, []string{full, bare}
+	case NamespaceMain:
+		// Transclusions of main namespace pages ({{:Title}})
+		typed, targets = LinkTypeTemplate, []string{":" + bare}
+	default:
+		typed, targets = LinkTypeTemplate, []string{full}
+	}
+
+	args := []any{full, typed}
+	marks := make([]string, len(targets))
+	for i, t := range targets {
+		args = append(args, t)
+		marks[i] = placeholder(len(args))
+	}
+	where := "(l.link_type = 'page' AND REPLACE(l.target_title, '_', ' ') = " + placeholder(1) + ")" +
+		" OR (l.link_type = " + placeholder(2) + " AND REPLACE(l.target_title, '_', ' ') IN (" + strings.Join(marks, ", ") + "))"
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT p.page_id, p.namespace, p.title, l.link_type
+		FROM links l
+		JOIN pages p ON p.page_id = l.source_page_id
+		WHERE (`+where+`)`+scope.filter("p")+`
+		ORDER BY p.namespace, p.title, l.link_type`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	target := FullTitle(ns, title)
+	links := []PageLink{}
+	for rows.Next() {
+		var sourceNS int
+		var source string
+		link := PageLink{Target: target, TargetID: page.ID}
+		if err := rows.Scan(&link.SourceID, &sourceNS, &source, &link.Type); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		link.Source = FullTitle(sourceNS, source)
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return links, nil
+}
+
+// resolveLinkPage looks up the page GetOutgoingLinks and GetBacklinks are
+// asked about.
+func resolveLinkPage(ctx context.Context, db querier, scope wikiScope, title string, placeholder func(n int) string) (*Page, error) {
+	return resolveTitle(ctx, db, scope, title, false, placeholder)
+}
+
+// GetOutgoingLinks returns the links from a page.
+func (c *sqliteClient) GetOutgoingLinks(ctx context.Context, title string) ([]PageLink, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	page, err := resolveLinkPage(ctx, c.db, c.wiki, title, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	// Archives loaded without links have none
+	exists, err := sqliteTableExists(ctx, c.db, "main", "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []PageLink{}, nil
+	}
+	return getOutgoingLinks(ctx, c.db, c.wiki, page, placeholder)
+}
+
+// GetBacklinks returns the links to a page.
+func (c *sqliteClient) GetBacklinks(ctx context.Context, title string) ([]PageLink, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	page, err := resolveLinkPage(ctx, c.db, c.wiki, title, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := sqliteTableExists(ctx, c.db, "main", "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []PageLink{}, nil
+	}
+	return getBacklinks(ctx, c.db, c.wiki, page, placeholder)
+}
+
+// GetOutgoingLinks returns the links from a page.
+func (c *postgresClient) GetOutgoingLinks(ctx context.Context, title string) ([]PageLink, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveLinkPage(ctx, c.db, c.wiki, title, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := postgresTableExists(ctx, c.db, "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []PageLink{}, nil
+	}
+	return getOutgoingLinks(ctx, c.db, c.wiki, page, placeholder)
+}
+
+// GetBacklinks returns the links to a page.
+func (c *postgresClient) GetBacklinks(ctx context.Context, title string) ([]PageLink, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveLinkPage(ctx, c.db, c.wiki, title, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := postgresTableExists(ctx, c.db, "links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []PageLink{}, nil
+	}
+	return getBacklinks(ctx, c.db, c.wiki, page, placeholder)
+}
+
+// RebuildLinks replaces the links table with the links parsed from each
+// page's latest revision.
+func (s *sqliteStore) RebuildLinks(ctx context.Context) (*RebuildLinksResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if err := execStatements(ctx, s.db, sqliteSchema); err != nil {
+		return nil, err
+	}
+	return rebuildLinks(ctx, s.db, false)
+}
@@ -0,0 +1,187 @@
+package irowiki_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// writeLinkedArchive writes an archive of pages linking to each other
+func writeLinkedArchive(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "links.db")
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	pages := []struct {
+		id        int64
+		namespace int
+		title     string
+		content   []string
+	}{
+		{1, 0, "Poring", []string{
+			"[[Lunatic]] {{Stub}}",
+			"{{Infobox monster|map=[[Prontera Fields]]}} Found near [[Prontera#Fields|Prontera]], see [[#Drops]].\n" +
+				"{{#if:x|y}} {{:Main Page}} [[File:Poring.png|thumb]] [[:Category:Cards]]\n<!-- [[Hidden]] -->[[Category:Monsters|Poring]]",
+		}},
+		{2, 0, "Prontera", []string{"Home of the [[poring]]s."}},
+		{3, 10, "Infobox_monster", []string{"{{{map|}}}"}},
+		{4, 14, "Monsters", []string{"Monsters of the game."}},
+		{5, 0, "Main_Page", []string{"Welcome"}},
+		{6, 0, "Lonely", []string{"No one links here."}},
+	}
+	ctx := context.Background()
+	revID := int64(100)
+	for _, p := range pages {
+		if err := w.UpsertPage(ctx, &irowiki.Page{ID: p.id, Namespace: p.namespace, Title: p.title}); err != nil {
+			t.Fatalf("UpsertPage failed: %v", err)
+		}
+		for i, content := range p.content {
+			revID++
+			rev := &irowiki.Revision{ID: revID, PageID: p.id, Timestamp: time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC), Content: content}
+			if err := w.InsertRevision(ctx, rev); err != nil {
+				t.Fatalf("InsertRevision failed: %v", err)
+			}
+		}
+	}
+	return path
+}
+
+// TestSQLiteClient_GetOutgoingLinks tests listing the links the writer
+// recorded from a page's latest revision
+func TestSQLiteClient_GetOutgoingLinks(t *testing.T) {
+	client, err := irowiki.OpenSQLite(writeLinkedArchive(t))
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	links, err := client.GetOutgoingLinks(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetOutgoingLinks failed: %v", err)
+	}
+	link := func(target string, targetID int64, linkType string) irowiki.PageLink {
+		return irowiki.PageLink{SourceID: 1, Source: "Poring", Target: target, TargetID: targetID, Type: linkType}
+	}
+	want := []irowiki.PageLink{
+		link("Category:Monsters", 4, irowiki.LinkTypeCategory),
+		link("File:Poring.png", 0, irowiki.LinkTypeFile),
+		link("Category:Cards", 0, irowiki.LinkTypePage),
+		link("Prontera", 2, irowiki.LinkTypePage),
+		link("Prontera_Fields", 0, irowiki.LinkTypePage),
+		link("Main_Page", 5, irowiki.LinkTypeTemplate),
+		link("Template:Infobox_monster", 3, irowiki.LinkTypeTemplate),
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("unexpected links:\n got %+v\nwant %+v", links, want)
+	}
+
+	links, err = client.GetOutgoingLinks(ctx, "Lonely")
+	if err != nil {
+		t.Fatalf("GetOutgoingLinks failed: %v", err)
+	}
+	if links == nil || len(links) != 0 {
+		t.Errorf("expected an empty list, got %#v", links)
+	}
+
+	if _, err := client.GetOutgoingLinks(ctx, "Nonexistent"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestSQLiteClient_GetBacklinks tests listing the pages linking to, transcluding
+// and categorized in a page
+func TestSQLiteClient_GetBacklinks(t *testing.T) {
+	client, err := irowiki.OpenSQLite(writeLinkedArchive(t))
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		title  string
+		target string
+		want   []string
+	}{
+		{"Poring", "Poring", []string{"Prontera page"}},
+		{"Prontera", "Prontera", []string{"Poring page"}},
+		{"Template:Infobox monster", "Template:Infobox_monster", []string{"Poring template"}},
+		{"Category:Monsters", "Category:Monsters", []string{"Poring category"}},
+		{"Main Page", "Main_Page", []string{"Poring template"}},
+		{"Lonely", "Lonely", nil},
+	}
+	for _, tt := range tests {
+		links, err := client.GetBacklinks(ctx, tt.title)
+		if err != nil {
+			t.Fatalf("GetBacklinks(%q) failed: %v", tt.title, err)
+		}
+		var got []string
+		for _, l := range links {
+			if l.Target != tt.target {
+				t.Errorf("GetBacklinks(%q): expected target %q, got %q", tt.title, tt.target, l.Target)
+			}
+			got = append(got, l.Source+" "+l.Type)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetBacklinks(%q) = %v; expected %v", tt.title, got, tt.want)
+		}
+	}
+
+	if _, err := client.GetBacklinks(ctx, "Nonexistent"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestSQLiteStore_RebuildLinks tests recording the links of archives written
+// without them
+func TestSQLiteStore_RebuildLinks(t *testing.T) {
+	path := writeLinkedArchive(t)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	_, err = db.Exec(`DELETE FROM links`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("failed to clear links: %v", err)
+	}
+
+	store, err := irowiki.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore failed: %v", err)
+	}
+	result, err := store.RebuildLinks(context.Background())
+	store.Close()
+	if err != nil {
+		t.Fatalf("RebuildLinks failed: %v", err)
+	}
+	if result.Pages != 6 || result.Links != 8 {
+		t.Errorf("expected 8 links from 6 pages, got %+v", result)
+	}
+
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	links, err := client.GetBacklinks(context.Background(), "Prontera")
+	if err != nil {
+		t.Fatalf("GetBacklinks failed: %v", err)
+	}
+	if len(links) != 1 || links[0].Source != "Poring" {
+		t.Errorf("expected Poring to link to Prontera again, got %+v", links)
+	}
+}
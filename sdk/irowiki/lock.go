@@ -0,0 +1,233 @@
+package irowiki
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// LockHolder describes the writer holding an archive's lock.
+type LockHolder struct {
+	// PID is the holder's process ID; for PostgreSQL archives, the server
+	// process of its session.
+	PID int `json:"pid"`
+
+	// Host is the machine the holder runs on, if known.
+	Host string `json:"host"`
+
+	// Tool names the holder, from its ConnectionOptions.WriterName or, for
+	// the Python scraper, its command ("scraper full").
+	Tool string `json:"tool"`
+
+	// Acquired is when the holder took the lock.
+	Acquired time.Time `json:"acquired"`
+}
+
+// ArchiveLockedError is returned by Store operations that modify an archive
+// while another writer (a scrape, a prune, a bulk load) holds its lock.
+// It matches ErrArchiveLocked with errors.Is.
+type ArchiveLockedError struct {
+	Holder LockHolder
+
+	// Path is the lock file of a SQLite archive, to remove by hand if its
+	// holder died on another host. Empty for PostgreSQL archives, whose
+	// locks end with the holder's session.
+	Path string
+}
+
+// Error describes the holder.
+func (e *ArchiveLockedError) Error() string {
+	since := "unknown"
+	if !e.Holder.Acquired.IsZero() {
+		since = e.Holder.Acquired.UTC().Format(time.RFC3339)
+	}
+	msg := fmt.Sprintf("archive is locked by %s (pid %d on %s) since %s",
+		cmp.Or(e.Holder.Tool, "unknown"), e.Holder.PID, cmp.Or(e.Holder.Host, "unknown host"), since)
+	if e.Path != "" {
+		msg += "; remove " + e.Path + " if that process is gone"
+	}
+	return msg
+}
+
+// Unwrap returns ErrArchiveLocked.
+func (e *ArchiveLockedError) Unwrap() error {
+	return ErrArchiveLocked
+}
+
+// acquireFileLock takes the lock of the SQLite archive at path: a
+// "<archive>.lock" file created exclusively, holding the LockHolder as
+// JSON. The Python scraper takes the same lock. A lock left by a process
+// that died on this host is taken over with takeOverStaleLock; release
+// removes the file.
+func acquireFileLock(path, tool string) (release func(), err error) {
+	lockPath := path + ".lock"
+	host, _ := os.Hostname()
+	data, err := json.Marshal(LockHolder{
+		PID:      os.Getpid(),
+		Host:     host,
+		Tool:     tool,
+		Acquired: time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for range 2 {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			holder, stale, ok := readLockFile(lockPath)
+			if ok && holder.Host == host && holder.PID > 0 && !processAlive(holder.PID) {
+				takeOverStaleLock(lockPath, stale)
+				continue
+			}
+			return nil, &ArchiveLockedError{Holder: holder, Path: lockPath}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to create lock file: %v", ErrDatabaseError, err)
+		}
+
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(lockPath)
+			return nil, fmt.Errorf("%w: failed to write lock file: %v", ErrDatabaseError, err)
+		}
+		return func() { os.Remove(lockPath) }, nil
+	}
+
+	holder, _, _ := readLockFile(lockPath)
+	return nil, &ArchiveLockedError{Holder: holder, Path: lockPath}
+}
+
+// takeOverStaleLock removes the lock file at lockPath if it still holds
+// stale, the contents of a lock whose holder died. Another writer may have
+// taken the lock over and created a new one since stale was read, so the
+// file isn't removed directly: it's renamed aside, which only one writer
+// can do, and linked back if it turns out not to be the stale lock.
+func takeOverStaleLock(lockPath string, stale []byte) {
+	claimed := fmt.Sprintf("%s.%d.stale", lockPath, os.Getpid())
+	if err := os.Rename(lockPath, claimed); err != nil {
+		return
+	}
+	defer os.Remove(claimed)
+
+	if data, err := os.ReadFile(claimed); err == nil && !bytes.Equal(data, stale) {
+		// Linking fails rather than replace a lock taken in the meantime
+		os.Link(claimed, lockPath)
+	}
+}
+
+// readLockFile reads the holder of a lock file and the file's contents,
+// reporting false if it's unreadable (e.g. being written).
+func readLockFile(path string) (LockHolder, []byte, bool) {
+	var holder LockHolder
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return holder, nil, false
+	}
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return LockHolder{}, nil, false
+	}
+	return holder, data, true
+}
+
+// processAlive reports whether a process exists on this host, assuming it
+// does when that can't be checked.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess opens the process, failing for exited ones
+		process.Release()
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// lock takes the archive's lock for an operation modifying it.
+// In-memory archives can't be shared, so they aren't locked.
+func (s *sqliteStore) lock() (release func(), err error) {
+	if s.path == ":memory:" {
+		return func() {}, nil
+	}
+	return acquireFileLock(s.path, s.opts.WriterName)
+}
+
+// advisoryLockKey is the archive's PostgreSQL advisory lock: a key for the
+// SDK and one for the archive's pages table, so archives in different
+// schemas or with different table prefixes lock separately.
+const advisoryLockKey = `hashtext('irowiki'), hashtext(COALESCE(to_regclass('pages')::text, 'pages'))`
+
+// lock takes the archive's advisory lock for an operation modifying it.
+// The lock is held by a session of its own, named after the writer, so
+// other writers can tell who holds it from pg_stat_activity.
+func (s *postgresStore) lock(ctx context.Context) (release func(), err error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	done := func() {
+		conn.ExecContext(context.Background(), "RESET application_name")
+		conn.Close()
+	}
+
+	var locked bool
+	if _, err := conn.ExecContext(ctx, "SELECT set_config('application_name', $1, false)", s.opts.WriterName); err != nil {
+		done()
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock("+advisoryLockKey+")").Scan(&locked); err != nil {
+		done()
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	if !locked {
+		holder, err := advisoryLockHolder(ctx, conn)
+		done()
+		if err != nil {
+			return nil, err
+		}
+		return nil, &ArchiveLockedError{Holder: holder}
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock("+advisoryLockKey+")")
+		done()
+	}, nil
+}
+
+// advisoryLockHolder describes the session holding the archive's advisory
+// lock. The holding session runs nothing after taking the lock, so its
+// latest statement started when it took it.
+func advisoryLockHolder(ctx context.Context, conn *sql.Conn) (LockHolder, error) {
+	var holder LockHolder
+	var acquired sql.NullTime
+	err := conn.QueryRowContext(ctx, `
+		SELECT a.pid, COALESCE(a.client_hostname, host(a.client_addr), ''), a.application_name, a.query_start
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted AND l.objsubid = 2
+		  AND (l.classid, l.objid) = (SELECT hashtext('irowiki')::oid, hashtext(COALESCE(to_regclass('pages')::text, 'pages'))::oid)
+	`).Scan(&holder.PID, &holder.Host, &holder.Tool, &acquired)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released since
+		return holder, nil
+	}
+	if err != nil {
+		return holder, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	holder.Acquired = acquired.Time
+	return holder, nil
+}
@@ -0,0 +1,57 @@
+package irowiki
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTakeOverStaleLock tests that a stale lock is removed only while it's
+// still the lock file, not once another writer has replaced it
+func TestTakeOverStaleLock(t *testing.T) {
+	stale := []byte(`{"pid":1073741824,"host":"here","tool":"crashed"}`)
+	live := []byte(`{"pid":4242,"host":"here","tool":"scraper full"}`)
+
+	tests := []struct {
+		name     string
+		contents []byte
+		want     []byte
+	}{
+		{"still stale", stale, nil},
+		{"taken over since", live, live},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lockPath := filepath.Join(t.TempDir(), "archive.db.lock")
+			if err := os.WriteFile(lockPath, tt.contents, 0o644); err != nil {
+				t.Fatalf("failed to write lock file: %v", err)
+			}
+
+			takeOverStaleLock(lockPath, stale)
+
+			data, err := os.ReadFile(lockPath)
+			switch {
+			case tt.want == nil && !os.IsNotExist(err):
+				t.Errorf("expected lock file to be removed, got %q (%v)", data, err)
+			case tt.want != nil && string(data) != string(tt.want):
+				t.Errorf("expected lock file %q, got %q (%v)", tt.want, data, err)
+			}
+
+			// The claimed file is cleaned up either way
+			entries, err := os.ReadDir(filepath.Dir(lockPath))
+			if err != nil {
+				t.Fatalf("failed to list directory: %v", err)
+			}
+			if want := min(len(tt.want), 1); len(entries) != want {
+				t.Errorf("unexpected files left: %v", entries)
+			}
+		})
+	}
+
+	// Test: A lock another writer already removed isn't recreated
+	lockPath := filepath.Join(t.TempDir(), "archive.db.lock")
+	takeOverStaleLock(lockPath, stale)
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("expected no lock file, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package irowiki_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// writeLockFile locks the archive at path as holder would.
+func writeLockFile(t *testing.T, path string, holder irowiki.LockHolder) {
+	t.Helper()
+
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatalf("failed to encode holder: %v", err)
+	}
+	if err := os.WriteFile(path+".lock", data, 0o644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+}
+
+// TestSQLiteStore_ArchiveLock tests that stores refuse to write archives
+// locked by another writer
+func TestSQLiteStore_ArchiveLock(t *testing.T) {
+	ctx := context.Background()
+	policy := irowiki.PrunePolicy{KeepLast: 1}
+
+	t.Run("locked by another writer", func(t *testing.T) {
+		tdb := testutil.SetupTestDBFile(t)
+		defer tdb.Close()
+
+		acquired := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		writeLockFile(t, tdb.Path, irowiki.LockHolder{PID: 4242, Host: "scraper-host", Tool: "scraper full", Acquired: acquired})
+
+		store, err := irowiki.OpenSQLiteStore(tdb.Path)
+		if err != nil {
+			t.Fatalf("failed to open store: %v", err)
+		}
+		defer store.Close()
+
+		_, err = store.Prune(ctx, policy)
+		if !errors.Is(err, irowiki.ErrArchiveLocked) {
+			t.Fatalf("expected ErrArchiveLocked, got %v", err)
+		}
+		var locked *irowiki.ArchiveLockedError
+		if !errors.As(err, &locked) {
+			t.Fatalf("expected *ArchiveLockedError, got %T", err)
+		}
+		if locked.Holder.Tool != "scraper full" || locked.Holder.PID != 4242 || !locked.Holder.Acquired.Equal(acquired) {
+			t.Errorf("unexpected holder: %+v", locked.Holder)
+		}
+		if !strings.Contains(err.Error(), "scraper full (pid 4242 on scraper-host) since 2024-05-01T12:00:00Z") {
+			t.Errorf("error doesn't describe the holder: %v", err)
+		}
+
+		// Nothing was pruned, and the lock is left alone
+		var count int
+		if err := tdb.DB.QueryRow("SELECT COUNT(*) FROM revisions").Scan(&count); err != nil {
+			t.Fatalf("failed to count revisions: %v", err)
+		}
+		if count != 7 {
+			t.Errorf("expected 7 revisions, got %d", count)
+		}
+		if _, err := os.Stat(tdb.Path + ".lock"); err != nil {
+			t.Errorf("expected lock file to remain: %v", err)
+		}
+
+		// Dry runs don't write, so they don't need the lock
+		if _, err := store.Prune(ctx, irowiki.PrunePolicy{KeepLast: 1, DryRun: true}); err != nil {
+			t.Errorf("dry run failed: %v", err)
+		}
+	})
+
+	t.Run("stale lock taken over", func(t *testing.T) {
+		tdb := testutil.SetupTestDBFile(t)
+		defer tdb.Close()
+
+		host, _ := os.Hostname()
+		writeLockFile(t, tdb.Path, irowiki.LockHolder{PID: 1 << 30, Host: host, Tool: "crashed"})
+
+		store, err := irowiki.OpenSQLiteStore(tdb.Path)
+		if err != nil {
+			t.Fatalf("failed to open store: %v", err)
+		}
+		defer store.Close()
+
+		if _, err := store.Prune(ctx, policy); err != nil {
+			t.Fatalf("prune failed: %v", err)
+		}
+		if _, err := os.Stat(tdb.Path + ".lock"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected lock file to be released, got %v", err)
+		}
+	})
+
+	t.Run("live lock on this host kept", func(t *testing.T) {
+		tdb := testutil.SetupTestDBFile(t)
+		defer tdb.Close()
+
+		host, _ := os.Hostname()
+		writeLockFile(t, tdb.Path, irowiki.LockHolder{PID: os.Getpid(), Host: host, Tool: "bulk"})
+
+		store, err := irowiki.OpenSQLiteStore(tdb.Path)
+		if err != nil {
+			t.Fatalf("failed to open store: %v", err)
+		}
+		defer store.Close()
+
+		if _, err := store.Prune(ctx, policy); !errors.Is(err, irowiki.ErrArchiveLocked) {
+			t.Fatalf("expected ErrArchiveLocked, got %v", err)
+		}
+		if _, err := os.Stat(tdb.Path + ".lock"); err != nil {
+			t.Errorf("expected lock file to remain: %v", err)
+		}
+	})
+
+	t.Run("other archives unaffected", func(t *testing.T) {
+		tdb := testutil.SetupTestDBFile(t)
+		defer tdb.Close()
+
+		store, err := irowiki.OpenSQLiteStoreWithOptions(tdb.Path, irowiki.ConnectionOptions{WriterName: "bulk"})
+		if err != nil {
+			t.Fatalf("failed to open store: %v", err)
+		}
+		defer store.Close()
+
+		src := testutil.SetupTestDBFile(t)
+		defer src.Close()
+		writeLockFile(t, src.Path, irowiki.LockHolder{PID: 4242, Host: "scraper-host", Tool: "scraper incremental"})
+
+		// Only the destination archive is locked
+		if _, err := store.Prune(ctx, policy); err != nil {
+			t.Fatalf("prune failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// requestIDKey is the context key of request IDs.
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying a request or trace ID,
+// which LoggingInterceptor includes in its log lines and errors.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set by ContextWithRequestID,
+// or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// LogOptions configures LoggingInterceptor.
+type LogOptions struct {
+	// Logger receives the log lines. Default: the standard logger.
+	Logger *log.Logger
+
+	// SlowThreshold limits logging to failed calls and calls taking at
+	// least this long. Default: 0 (every call is logged).
+	SlowThreshold time.Duration
+
+	// RequestID extracts the request or trace ID of a call's context, for
+	// IDs stored by tracing or web frameworks.
+	// Default: RequestIDFromContext.
+	RequestID func(ctx context.Context) string
+}
+
+// LoggingInterceptor returns an Interceptor that logs client calls with their
+// duration and request ID, so slow queries in a server can be traced back to
+// the requests that made them. Errors of calls with a request ID are wrapped
+// to include it; errors.Is still matches the original error. With
+// ConnectionOptions.ExplainThreshold set, the plans of the call's slow
+// queries are logged too, whatever SlowThreshold is.
+//
+// Example:
+//
+//	client = irowiki.WithInterceptor(client, irowiki.LoggingInterceptor(irowiki.LogOptions{
+//	    SlowThreshold: 100 * time.Millisecond,
+//	}))
+//	page, err := client.GetPage(irowiki.ContextWithRequestID(ctx, "req-42"), "Poring")
+func LoggingInterceptor(opts LogOptions) Interceptor {
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	if opts.RequestID == nil {
+		opts.RequestID = RequestIDFromContext
+	}
+
+	return func(ctx context.Context, call Call, next Invoker) (any, error) {
+		callCtx, collected := contextWithQueryPlans(ctx)
+		start := time.Now()
+		result, err := next(callCtx)
+		elapsed := time.Since(start)
+
+		id := opts.RequestID(ctx)
+		prefix := "irowiki: "
+		if id != "" {
+			prefix += "[" + id + "] "
+		}
+
+		// Plans of slow queries, with ConnectionOptions.ExplainThreshold
+		collected.mu.Lock()
+		for _, plan := range collected.plans {
+			opts.Logger.Printf("%s%s %s", prefix, formatCall(call), formatQueryPlan(plan))
+		}
+		collected.mu.Unlock()
+
+		if err == nil && elapsed < opts.SlowThreshold {
+			return result, nil
+		}
+		if err != nil {
+			opts.Logger.Printf("%s%s failed after %v: %v", prefix, formatCall(call), elapsed, err)
+			if id != "" {
+				err = fmt.Errorf("%s (request %s): %w", call.Method, id, err)
+			}
+			return result, err
+		}
+		opts.Logger.Printf("%s%s took %v", prefix, formatCall(call), elapsed)
+		return result, nil
+	}
+}
+
+// formatCall formats a call for logs, such as GetPage("Poring").
+func formatCall(call Call) string {
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		switch v := arg.(type) {
+		case string:
+			args[i] = fmt.Sprintf("%q", v)
+		case time.Time:
+			args[i] = v.Format(time.RFC3339)
+		case RowWriter:
+			args[i] = fmt.Sprintf("%T", v)
+		default:
+			args[i] = fmt.Sprintf("%+v", v)
+		}
+	}
+	return call.Method + "(" + strings.Join(args, ", ") + ")"
+}
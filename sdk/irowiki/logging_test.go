@@ -0,0 +1,157 @@
+package irowiki_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestLoggingInterceptor tests logging calls with their request IDs
+func TestLoggingInterceptor(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	var logs bytes.Buffer
+	logged := irowiki.WithInterceptor(client, irowiki.LoggingInterceptor(irowiki.LogOptions{
+		Logger: log.New(&logs, "", 0),
+	}))
+	ctx := irowiki.ContextWithRequestID(context.Background(), "req-42")
+
+	// Test: Calls are logged with the request ID and arguments
+	if _, err := logged.GetPage(ctx, "Poring"); err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if line := logs.String(); !strings.HasPrefix(line, `irowiki: [req-42] GetPage("Poring") took `) {
+		t.Errorf("unexpected log line %q", line)
+	}
+
+	// Test: Errors are logged and wrapped with the request ID
+	logs.Reset()
+	_, err = logged.GetPage(ctx, "Nonexistent")
+	if !errors.Is(err, irowiki.ErrNotFound) || !strings.Contains(err.Error(), "request req-42") {
+		t.Errorf("expected a wrapped ErrNotFound, got %v", err)
+	}
+	if !strings.Contains(logs.String(), `GetPage("Nonexistent") failed after`) {
+		t.Errorf("expected the failure to be logged, got %q", logs.String())
+	}
+
+	// Test: Fast calls aren't logged with a slow threshold, but errors are
+	logs.Reset()
+	slow := irowiki.WithInterceptor(client, irowiki.LoggingInterceptor(irowiki.LogOptions{
+		Logger:        log.New(&logs, "", 0),
+		SlowThreshold: time.Hour,
+		RequestID:     func(context.Context) string { return "trace-7" },
+	}))
+	slow.GetPage(context.Background(), "Poring")
+	if logs.Len() != 0 {
+		t.Errorf("expected no log for a fast call, got %q", logs.String())
+	}
+	slow.GetPage(context.Background(), "Nonexistent")
+	if !strings.Contains(logs.String(), "[trace-7]") {
+		t.Errorf("expected the custom request ID in the log, got %q", logs.String())
+	}
+
+	// Test: Contexts without a request ID have none
+	if id := irowiki.RequestIDFromContext(context.Background()); id != "" {
+		t.Errorf("expected no request ID, got %q", id)
+	}
+}
+
+// TestLoggingInterceptor_QueryPlans tests logging the plans of slow queries
+func TestLoggingInterceptor_QueryPlans(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.ExplainThreshold = time.Nanosecond
+	client, err := irowiki.OpenSQLiteWithOptions(tdb.Path, opts)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWithOptions failed: %v", err)
+	}
+	defer client.Close()
+
+	var logs bytes.Buffer
+	logged := irowiki.WithInterceptor(client, irowiki.LoggingInterceptor(irowiki.LogOptions{
+		Logger:        log.New(&logs, "", 0),
+		SlowThreshold: time.Hour,
+	}))
+
+	// Every query is slow with a 1ns threshold; the call itself isn't
+	if _, err := logged.GetPage(context.Background(), "Poring"); err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	out := logs.String()
+	if !strings.HasPrefix(out, `irowiki: GetPage("Poring") slow query took `) {
+		t.Errorf("expected a slow query of the call to be logged, got %q", out)
+	}
+	if !strings.Contains(out, "SELECT") || !(strings.Contains(out, "SEARCH") || strings.Contains(out, "SCAN")) {
+		t.Errorf("expected the query with its plan, got %q", out)
+	}
+	if strings.Contains(out, `GetPage("Poring") took`) {
+		t.Errorf("expected the fast call not to be logged, got %q", out)
+	}
+}
+
+// TestSQLiteClient_OnQueryPlan tests handing the plans of slow queries run
+// outside a LoggingInterceptor call to OnQueryPlan
+func TestSQLiteClient_OnQueryPlan(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	var mu sync.Mutex
+	var plans []irowiki.QueryPlan
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.ExplainThreshold = time.Nanosecond
+	opts.OnQueryPlan = func(plan irowiki.QueryPlan) {
+		mu.Lock()
+		defer mu.Unlock()
+		plans = append(plans, plan)
+	}
+	client, err := irowiki.OpenSQLiteWithOptions(tdb.Path, opts)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWithOptions failed: %v", err)
+	}
+	defer client.Close()
+
+	mu.Lock()
+	plans = nil
+	mu.Unlock()
+	if _, err := client.GetPage(context.Background(), "Poring"); err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	mu.Lock()
+	if len(plans) == 0 || !strings.Contains(plans[0].Query, "SELECT") || plans[0].Plan == "" {
+		t.Errorf("expected the query with its plan, got %+v", plans)
+	}
+	plans = nil
+	mu.Unlock()
+
+	// Test: Plans of calls through a LoggingInterceptor go to its logger only
+	var logs bytes.Buffer
+	logged := irowiki.WithInterceptor(client, irowiki.LoggingInterceptor(irowiki.LogOptions{Logger: log.New(&logs, "", 0)}))
+	if _, err := logged.GetPage(context.Background(), "Poring"); err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(plans) != 0 {
+		t.Errorf("expected no plans outside the interceptor, got %d", len(plans))
+	}
+	if !strings.Contains(logs.String(), "slow query took") {
+		t.Errorf("expected the interceptor to log the plans, got %q", logs.String())
+	}
+}
@@ -16,6 +16,14 @@ type Page struct {
 	// IsRedirect indicates if this page is a redirect.
 	IsRedirect bool
 
+	// RedirectTarget is the title a redirect points to, without any
+	// #section. Empty for pages that aren't redirects.
+	RedirectTarget string
+
+	// RedirectedFrom is the title GetPage was asked for when it followed
+	// redirects to this page (WithRedirects(Follow)), or "".
+	RedirectedFrom string
+
 	// LatestRevisionID is the revision ID of the current version.
 	LatestRevisionID int64
 
@@ -30,6 +38,19 @@ type Page struct {
 
 	// Comment is the edit summary of the latest revision.
 	Comment string
+
+	// Deleted reports whether an incremental scrape found the page deleted
+	// from the live wiki. Its revisions remain in the archive.
+	Deleted bool
+
+	// IsStub and IsDisambiguation are rendering hints detected from the
+	// stub and disambiguation templates and categories of the latest revision.
+	IsStub           bool
+	IsDisambiguation bool
+
+	// Protection lists the page's protections on the live wiki, for archives
+	// scraped with --capture-protection. Only GetPage and GetPageByID load it.
+	Protection []PageProtection
 }
 
 // Revision represents a single edit/revision of a page.
@@ -127,6 +148,11 @@ type SearchResult struct {
 
 	// MatchType indicates where the match occurred ("title", "content", "fulltext").
 	MatchType string
+
+	// RedirectedFrom is the redirect that led to this page when a title
+	// search collapses redirects (SearchOptions.CollapseRedirects), or ""
+	// if the page matched itself.
+	RedirectedFrom string
 }
 
 // PagedResult wraps search results with pagination metadata.
@@ -145,6 +171,10 @@ type PagedResult struct {
 
 	// HasMore indicates if there are more results beyond this page.
 	HasMore bool
+
+	// Suggestion is a spell-corrected query ("porring" -> "poring") offered when
+	// the query matched nothing but the corrected query matches something.
+	Suggestion string
 }
 
 // PageInfo provides detailed pagination information.
@@ -210,6 +240,74 @@ func (pr *PagedResult) PrevOffset() int {
 	return prev
 }
 
+// ArchiveInfo describes where an archive came from and how it may be reused.
+// Values come from the archive_meta table written by the scraper.
+type ArchiveInfo struct {
+	// WikiName is the source wiki's site name (e.g., "iRO Wiki").
+	WikiName string
+
+	// BaseURL is the base URL of the source wiki (e.g., "https://irowiki.org").
+	BaseURL string
+
+	// APIURL is the MediaWiki API endpoint the archive was scraped from.
+	APIURL string
+
+	// ArticlePath is the article URL pattern (e.g., "/wiki/$1").
+	ArticlePath string
+
+	// Language is the wiki's content language code.
+	Language string
+
+	// Generator is the MediaWiki version string of the source wiki.
+	Generator string
+
+	// LicenseName is the content license (e.g., "CC BY-SA 4.0").
+	LicenseName string
+
+	// LicenseURL links to the full license text.
+	LicenseURL string
+
+	// ScraperVersion is the version of the tool that produced the archive.
+	ScraperVersion string
+
+	// ScrapedAt is when the scrape started (zero if unknown).
+	ScrapedAt time.Time
+
+	// Meta holds every raw key/value pair, including keys not mapped above.
+	Meta map[string]string
+
+	// Integrity is the result of verifying the packaged bundle the archive
+	// was opened from (see the bundle package), or nil if it wasn't.
+	Integrity *ArchiveIntegrity
+}
+
+// ArchiveIntegrity reports how a packaged bundle was verified when the
+// archive was opened from it.
+type ArchiveIntegrity struct {
+	// Bundle is the bundle file.
+	Bundle string
+
+	// FormatVersion is the bundle's format version.
+	FormatVersion int
+
+	// ChecksumsVerified reports that every file matched the bundle's
+	// checksums. Bundles failing it aren't opened, so it's always set.
+	ChecksumsVerified bool
+
+	// Signed reports that the bundle carries a valid signature of its
+	// checksums, made with the key KeyID identifies. Bundles whose
+	// signature doesn't verify aren't opened.
+	Signed bool
+	KeyID  string
+
+	// Trusted reports that the signing key is one of the keys the archive
+	// was opened with as trusted.
+	Trusted bool
+
+	// VerifiedAt is when the bundle's files were verified.
+	VerifiedAt time.Time
+}
+
 // Statistics represents overall wiki statistics.
 type Statistics struct {
 	// TotalPages is the total number of pages.
@@ -283,6 +381,10 @@ type DiffResult struct {
 
 	// Stats contains statistical information about the changes.
 	Stats DiffStats
+
+	// Cosmetic reports whether the revisions differ only in changes the
+	// DiffOptions ignore, so history analysis can skip the edit.
+	Cosmetic bool
 }
 
 // DiffStats contains statistical information about changes between revisions.
@@ -359,6 +461,9 @@ type EditorStat struct {
 	LastEdit    time.Time `json:"last_edit"`
 	MinorEdits  int       `json:"minor_edits"`
 	PagesEdited int       `json:"pages_edited"`
+
+	// Bot marks bot accounts (see IsBot and ConnectionOptions.BotUsers).
+	Bot bool `json:"bot"`
 }
 
 // PageStatisticsEnhanced contains enhanced comprehensive statistics for a page.
@@ -389,10 +494,12 @@ type PageStatisticsEnhanced struct {
 	AvgSize      int `json:"avg_size"`
 
 	// Quality metrics
-	MinorEditPercent float64 `json:"minor_edit_percent"`
-	AvgCommentLength float64 `json:"avg_comment_length"`
-	RevertCount      int     `json:"revert_count"`
-	StabilityScore   float64 `json:"stability_score"`
+	MinorEditPercent float64        `json:"minor_edit_percent"`
+	AvgCommentLength float64        `json:"avg_comment_length"`
+	RevertCount      int            `json:"revert_count"`
+	CosmeticEdits    int            `json:"cosmetic_edits"` // Edits that only reformat wikitext (EditFormatting)
+	EditClasses      map[string]int `json:"edit_classes"`   // Edit class (ClassifyRevisions) -> count
+	StabilityScore   float64        `json:"stability_score"`
 
 	// Activity patterns
 	BusiestMonth string        `json:"busiest_month,omitempty"`
@@ -421,10 +528,23 @@ type EditorActivity struct {
 	LastEdit   time.Time `json:"last_edit"`
 	ActiveDays int       `json:"active_days"`
 
+	// FirstEditedPage is the title of the page the editor first edited.
+	FirstEditedPage string `json:"first_edited_page,omitempty"`
+
 	// Content stats
 	PagesEdited int `json:"pages_edited"`
 	MinorEdits  int `json:"minor_edits"`
 
+	// AvgSizeDelta is the average change in page size per edit, in bytes.
+	// A page creation counts its full size.
+	AvgSizeDelta float64 `json:"avg_size_delta"`
+
+	// CommentRate is the fraction of edits with an edit summary (0-1).
+	CommentRate float64 `json:"comment_rate"`
+
+	// EditsByNamespace counts the editor's edits in each namespace.
+	EditsByNamespace map[int]int `json:"edits_by_namespace,omitempty"`
+
 	// Activity patterns
 	EditsByHour  map[int]int    `json:"edits_by_hour"`
 	EditsByDay   map[string]int `json:"edits_by_day"`
@@ -436,6 +556,46 @@ type EditorActivity struct {
 	TopPages []PageEditStat `json:"top_pages"`
 }
 
+// EditorActivityOptions selects the time range and the sections of an
+// EditorActivity to compute. The basic stats (edit count, first and last
+// edit, active days) are always included; unrequested sections are left
+// zero and cost nothing.
+type EditorActivityOptions struct {
+	// Start and End limit the edits counted (optional).
+	Start time.Time
+	End   time.Time
+
+	// FirstEditedPage includes the page of the editor's first edit.
+	FirstEditedPage bool
+
+	// Content includes PagesEdited, MinorEdits, AvgSizeDelta and CommentRate.
+	Content bool
+
+	// Namespaces includes EditsByNamespace.
+	Namespaces bool
+
+	// Patterns includes the hourly, daily and monthly edit counts.
+	Patterns bool
+
+	// TopPages is the number of most edited pages to include. Set to 0 to
+	// skip them.
+	TopPages int
+}
+
+// AllEditorActivity returns options requesting every section of an
+// EditorActivity between start and end, as GetEditorActivityEnhanced does.
+func AllEditorActivity(start, end time.Time) EditorActivityOptions {
+	return EditorActivityOptions{
+		Start:           start,
+		End:             end,
+		FirstEditedPage: true,
+		Content:         true,
+		Namespaces:      true,
+		Patterns:        true,
+		TopPages:        10,
+	}
+}
+
 // PageEditStat represents editing statistics for a page.
 type PageEditStat struct {
 	PageID    int64     `json:"page_id"`
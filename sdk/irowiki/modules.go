@@ -0,0 +1,175 @@
+package irowiki
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxTransclusionDepth bounds how many levels of nested templates and
+// required modules GetModuleDependencies follows. MediaWiki itself stops
+// expanding at 40; real dependency chains are far shallower.
+const maxTransclusionDepth = 40
+
+var (
+	// invokePattern matches {{#invoke:Module|function...}} calls.
+	invokePattern = regexp.MustCompile(`(?i)\{\{\s*#invoke\s*:\s*([^|{}]+?)\s*\|\s*([^|{}]+?)\s*[|}]`)
+
+	// transclusionPattern matches the name of a {{Template...}} transclusion.
+	// The leading group rejects template parameters ({{{1}}}), and only the
+	// first closing brace is consumed so adjacent transclusions both match.
+	transclusionPattern = regexp.MustCompile(`(?:^|[^{])\{\{\s*([^{}|\n]+?)\s*[|}]`)
+
+	// requirePattern matches Lua require() and mw.loadData() calls that load
+	// another module.
+	requirePattern = regexp.MustCompile(`(?:\brequire|\bmw\.loadData|\bmw\.loadJsonData)\s*\(?\s*["']([Mm]odule\s*:[^"']+)["']`)
+
+	// wikitextCommentPattern matches HTML comments, which don't transclude.
+	wikitextCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// ModuleDependency is a Scribunto (Lua) module a page needs to render,
+// either invoked directly or through a template or another module.
+type ModuleDependency struct {
+	// Module is the module's title (e.g., "Module:Infobox").
+	Module string `json:"module"`
+
+	// Via is the template or module that loads it, or "" when the page
+	// invokes it directly. A module reached several ways lists the nearest.
+	Via string `json:"via,omitempty"`
+
+	// Functions are the functions called with {{#invoke:}}, in order of
+	// first use. Empty for modules only loaded with require or mw.loadData.
+	Functions []string `json:"functions,omitempty"`
+
+	// Archived reports whether the module's source is in the archive.
+	Archived bool `json:"archived"`
+}
+
+// transclusionTitle returns the page a {{name}} transclusion refers to, or ""
+// for parser functions. Names without a known namespace are templates.
+func transclusionTitle(name string) string {
+	for _, prefix := range []string{"subst:", "safesubst:", "msgnw:"} {
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			name = strings.TrimSpace(name[len(prefix):])
+		}
+	}
+
+	switch {
+	case name == "" || strings.HasPrefix(name, "#"):
+		return ""
+	case strings.HasPrefix(name, ":"):
+		return normalizeTitle(name[1:])
+	}
+	if ns, _ := ParseTitle(name); ns != NamespaceMain {
+		return normalizeTitle(name)
+	}
+	return "Template:" + normalizeTitle(name)
+}
+
+// moduleTitle returns the normalized title of a module named in an #invoke
+// or require call, with or without its "Module:" prefix.
+func moduleTitle(name string) string {
+	if ns, title := ParseTitle(name); ns == NamespaceModule {
+		name = title
+	}
+	return "Module:" + normalizeTitle(name)
+}
+
+// getModuleDependencies walks a page's templates and modules breadth-first,
+// so each module's Via is its nearest source.
+func getModuleDependencies(ctx context.Context, db querier, scope wikiScope, title string, placeholder func(n int) string) ([]ModuleDependency, error) {
+	page, err := resolveTitle(ctx, db, scope, title, true, placeholder)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []*ModuleDependency
+	byModule := make(map[string]*ModuleDependency)
+	addModule := func(module, via, function string) {
+		dep := byModule[module]
+		if dep == nil {
+			dep = &ModuleDependency{Module: module, Via: via, Functions: []string{}}
+			byModule[module] = dep
+			deps = append(deps, dep)
+		}
+		if function != "" && !slices.Contains(dep.Functions, function) {
+			dep.Functions = append(dep.Functions, function)
+		}
+	}
+
+	type source struct {
+		title   string
+		content string
+	}
+
+	seen := map[string]bool{normalizeTitle(page.Title): true}
+	current := []source{{"", page.Content}}
+
+	for depth := 0; len(current) > 0 && depth < maxTransclusionDepth; depth++ {
+		var next []string
+		enqueue := func(title string) {
+			if title != "" && !seen[title] {
+				seen[title] = true
+				next = append(next, title)
+			}
+		}
+
+		for _, src := range current {
+			if strings.HasPrefix(src.title, "Module:") {
+				for _, m := range requirePattern.FindAllStringSubmatch(src.content, -1) {
+					module := moduleTitle(m[1])
+					addModule(module, src.title, "")
+					enqueue(module)
+				}
+				continue
+			}
+
+			content := wikitextCommentPattern.ReplaceAllString(src.content, "")
+			for _, m := range invokePattern.FindAllStringSubmatch(content, -1) {
+				module := moduleTitle(m[1])
+				addModule(module, src.title, m[2])
+				enqueue(module)
+			}
+			for _, m := range transclusionPattern.FindAllStringSubmatch(content, -1) {
+				enqueue(transclusionTitle(m[1]))
+			}
+		}
+
+		if len(next) == 0 {
+			break
+		}
+		results, err := getPagesByTitle(ctx, db, scope, next, placeholder)
+		if err != nil {
+			return nil, err
+		}
+
+		current = nil
+		for _, r := range results {
+			if !r.Found() {
+				continue
+			}
+			if dep := byModule[r.Requested]; dep != nil {
+				dep.Archived = true
+			}
+			current = append(current, source{r.Requested, r.Page.Content})
+		}
+	}
+
+	out := make([]ModuleDependency, len(deps))
+	for i, dep := range deps {
+		out[i] = *dep
+	}
+	return out, nil
+}
+
+// GetModuleDependencies returns the Scribunto modules a page depends on.
+func (c *sqliteClient) GetModuleDependencies(ctx context.Context, title string) ([]ModuleDependency, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getModuleDependencies(ctx, c.db, c.wiki, title, placeholder)
+}
@@ -0,0 +1,78 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetModuleDependencies tests finding the Lua modules a page needs
+func TestSQLiteClient_GetModuleDependencies(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	pages := []struct {
+		id        int64
+		namespace int
+		title     string
+		content   string
+	}{
+		{10, 0, "Poring_Card", "{{Infobox monster|name=Poring}}\n{{#invoke:drops|list|Poring}} {{#invoke:Drops|chance}}\n" +
+			"<!-- {{#invoke:Hidden|main}} --> {{{1|}}} {{#if:x|y}} {{Navbox}}"},
+		{11, 10, "Template:Infobox_monster", "{{#invoke:Infobox|monster}} {{Poring_Card}}"},
+		{12, 828, "Module:Infobox", "local args = require('Module:Arguments')\nlocal data = mw.loadData(\"Module:Infobox/data\")"},
+		{13, 828, "Module:Infobox/data", "return {}"},
+		{14, 828, "Module:Drops", "return {}"},
+	}
+	for i, p := range pages {
+		if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (?, ?, ?, 0)`, p.id, p.namespace, p.title); err != nil {
+			t.Fatalf("failed to insert page: %v", err)
+		}
+		_, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES (?, ?, '2021-01-01 00:00:00', ?, ?, 'x')`,
+			200+i, p.id, p.content, len(p.content))
+		if err != nil {
+			t.Fatalf("failed to insert revision: %v", err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Direct invokes, invokes through templates, and requires are found
+	deps, err := client.GetModuleDependencies(ctx, "Poring Card")
+	if err != nil {
+		t.Fatalf("GetModuleDependencies failed: %v", err)
+	}
+	want := []irowiki.ModuleDependency{
+		{Module: "Module:Drops", Functions: []string{"list", "chance"}, Archived: true},
+		{Module: "Module:Infobox", Via: "Template:Infobox monster", Functions: []string{"monster"}, Archived: true},
+		{Module: "Module:Arguments", Via: "Module:Infobox", Functions: []string{}},
+		{Module: "Module:Infobox/data", Via: "Module:Infobox", Functions: []string{}, Archived: true},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("unexpected dependencies:\n got %+v\nwant %+v", deps, want)
+	}
+
+	// Test: Pages without modules have none
+	deps, err = client.GetModuleDependencies(ctx, "Prontera")
+	if err != nil {
+		t.Fatalf("GetModuleDependencies failed: %v", err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("expected no dependencies, got %+v", deps)
+	}
+
+	// Test: Missing pages report ErrNotFound
+	if _, err := client.GetModuleDependencies(ctx, "Nonexistent"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package irowiki
 
-import "time"
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
 
 // ConnectionOptions configures database connections.
 type ConnectionOptions struct {
@@ -41,6 +45,96 @@ type ConnectionOptions struct {
 	// Debug enables detailed connection and query logging.
 	// Default: false.
 	Debug bool
+
+	// ExplainThreshold captures the plan of read queries taking at least
+	// this long, with EXPLAIN QUERY PLAN (SQLite) or EXPLAIN ANALYZE
+	// (PostgreSQL, which runs the query again), so slow queries can be
+	// reported with their plans. Plans are logged by the LoggingInterceptor
+	// of the call that ran the query, or else passed to OnQueryPlan. A
+	// query runs until its rows are closed.
+	// Default: 0 (disabled).
+	ExplainThreshold time.Duration
+
+	// OnQueryPlan receives the plans ExplainThreshold captures of queries
+	// run outside a LoggingInterceptor call. Without it, those queries
+	// aren't explained.
+	// Default: nil.
+	OnQueryPlan func(QueryPlan)
+
+	// WikiID selects the wiki of a multi-wiki archive that title lookups,
+	// page listings and searches use, and that GetArchiveInfo describes.
+	// Lookups by ID, files, statistics and exports cover every wiki.
+	// Opening fails with ErrInvalidInput for IDs not listed by ListWikis.
+	// Default: "" (the primary wiki).
+	WikiID string
+
+	// IncludeDeleted makes title lookups, page listings and searches include
+	// pages that incremental scrapes found deleted from the live wiki. By
+	// default they are hidden, matching the live wiki; Page.Deleted marks them.
+	// Default: false.
+	IncludeDeleted bool
+
+	// OnlyDeleted limits title lookups, page listings and searches to pages
+	// deleted from the live wiki, for studying removed content.
+	// Default: false.
+	OnlyDeleted bool
+
+	// CacheDir is where OpenSQLiteURL keeps downloaded archives, and
+	// ContentURL the content it fetches.
+	// Default: "irowiki" in the user's cache directory (os.UserCacheDir).
+	CacheDir string
+
+	// ContentURL is the address of an "irowiki serve" instance holding the
+	// full archive, for partial clones: SQLite archives whose revisions were
+	// copied without their text, such as the main shard of an archive
+	// sharded with ShardOptions.SeparateText. Text missing locally is
+	// fetched from it on demand and cached in CacheDir.
+	// Default: "" (no fetching).
+	ContentURL string
+
+	// Schema is the PostgreSQL schema holding the archive's tables, for
+	// hosted databases where they can't live in the search path. Ignored
+	// by SQLite.
+	// Default: "" (the connection's search path).
+	Schema string
+
+	// TablePrefix is prepended to the archive's PostgreSQL table names, for
+	// archives sharing a database with other applications ("wiki_" for
+	// wiki_pages, wiki_revisions, ...). Names are case-sensitive. Ignored
+	// by SQLite.
+	// Default: "".
+	TablePrefix string
+
+	// PostgresDriver selects the PostgreSQL driver: PostgresDriverPQ
+	// (lib/pq) or PostgresDriverPgx (pgx, with prepared statement caching
+	// and server-side cancellation of canceled queries). Ignored by SQLite.
+	// Default: PostgresDriverPQ.
+	PostgresDriver string
+
+	// SnapshotReads runs operations made of several queries, such as
+	// GetStatistics, GetStatisticsEnhanced and GetPageStatsEnhanced, in a
+	// snapshot (see Client.Snapshot), so their figures agree with each
+	// other while the archive is being written.
+	// Default: false.
+	SnapshotReads bool
+
+	// BotUsers lists bot accounts in addition to those IsBot recognizes by
+	// name, for Change.Bot, EditorStat.Bot and the ExcludeBots options.
+	// Default: none.
+	BotUsers []string
+
+	// ReadOnly opens a Store without write access to the archive, for
+	// operations that only read it, such as ExtractSubArchive, which writes
+	// a new file. Operations modifying the archive fail with
+	// ErrDatabaseError. Ignored by Client, which is always read-only.
+	// Default: false.
+	ReadOnly bool
+
+	// WriterName identifies this program in the archive lock a Store takes
+	// while it modifies the archive, so other writers finding it locked
+	// can tell who holds it. Ignored by Client.
+	// Default: the executable's name.
+	WriterName string
 }
 
 // DefaultSQLiteOptions returns sensible defaults for SQLite connections.
@@ -106,4 +200,7 @@ func (opts *ConnectionOptions) applyDefaults(isSQLite bool) {
 	if opts.RetryDelay == 0 {
 		opts.RetryDelay = defaults.RetryDelay
 	}
+	if opts.WriterName == "" && len(os.Args) > 0 {
+		opts.WriterName = filepath.Base(os.Args[0])
+	}
 }
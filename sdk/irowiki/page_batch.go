@@ -0,0 +1,287 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxTitlesPerQuery bounds the IN list of one lookup query, well under the
+// bind parameter limits of SQLite and PostgreSQL.
+const maxTitlesPerQuery = 500
+
+// TitleResolution is the outcome of looking up one requested title.
+type TitleResolution struct {
+	// Requested is the title as given.
+	Requested string `json:"requested"`
+
+	// Title is the stored title that matched, which may differ from Requested
+	// in case of the first letter, spacing or underscores. Empty if missing.
+	Title string `json:"title,omitempty"`
+
+	// Page is the latest version of the page, or nil if no page matched.
+	Page *Page `json:"page,omitempty"`
+}
+
+// Found reports whether the title resolved to a page.
+func (r TitleResolution) Found() bool {
+	return r.Page != nil
+}
+
+// titleVariants returns the forms a title may be stored under, most specific
+// first: as given, then normalized the way MediaWiki does (NFC, surrounding
+// whitespace trimmed, runs of spaces and underscores collapsed, first letter
+// upper-cased) with spaces, then with underscores.
+func titleVariants(title string) []string {
+	trimmed := strings.TrimSpace(title)
+	if trimmed == "" {
+		return nil
+	}
+
+	normalized := strings.Join(strings.FieldsFunc(nfc(trimmed), func(r rune) bool {
+		return r == '_' || unicode.IsSpace(r)
+	}), " ")
+	if first, size := utf8.DecodeRuneInString(normalized); first != utf8.RuneError {
+		normalized = string(unicode.ToUpper(first)) + normalized[size:]
+	}
+
+	variants := []string{trimmed}
+	for _, v := range []string{normalized, strings.ReplaceAll(normalized, " ", "_")} {
+		if v != variants[len(variants)-1] && v != trimmed {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// getPagesByTitle resolves titles in batched queries, matching the forms
+// titleCondition matches. Results follow the order of titles; blank titles
+// resolve to nothing.
+func getPagesByTitle(ctx context.Context, db querier, scope wikiScope, titles []string, placeholder func(n int) string) ([]TitleResolution, error) {
+	var lookup []string
+	seen := make(map[string]bool)
+	add := func(variants []string) {
+		for _, v := range variants {
+			if !seen[v] {
+				seen[v] = true
+				lookup = append(lookup, v)
+			}
+		}
+	}
+	for _, title := range titles {
+		if strings.TrimSpace(title) == "" {
+			continue
+		}
+		add(titleForms(title))
+		if ns, name := namespacedTitle(title); ns >= 0 {
+			add(titleForms(name))
+		}
+	}
+
+	pages := make(map[string][]*Page, len(lookup))
+	for start := 0; start < len(lookup); start += maxTitlesPerQuery {
+		batch := lookup[start:min(start+maxTitlesPerQuery, len(lookup))]
+		if err := lookupPages(ctx, db, scope, batch, placeholder, pages); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]TitleResolution, len(titles))
+	for i, title := range titles {
+		results[i].Requested = title
+		if strings.TrimSpace(title) == "" {
+			continue
+		}
+		page := matchPage(pages, titleForms(title), -1)
+		if ns, name := namespacedTitle(title); page == nil && ns >= 0 {
+			page = matchPage(pages, titleForms(name), ns)
+		}
+		if page != nil {
+			results[i].Title = page.Title
+			results[i].Page = page
+		}
+	}
+	return results, nil
+}
+
+// matchPage returns the page stored under the first of the variants that
+// has one. Pages of namespace ns are matched, or of any namespace if it's
+// -1, preferring the main namespace where unprefixed titles are shared.
+func matchPage(pages map[string][]*Page, variants []string, ns int) *Page {
+	for _, v := range variants {
+		var match *Page
+		for _, page := range pages[v] {
+			switch {
+			case ns >= 0 && page.Namespace != ns:
+			case match == nil, page.Namespace == NamespaceMain:
+				match = page
+			}
+		}
+		if match != nil {
+			return match
+		}
+	}
+	return nil
+}
+
+// lookupPages loads the latest version of each page titled in batch into pages.
+func lookupPages(ctx context.Context, db querier, scope wikiScope, batch []string, placeholder func(n int) string, pages map[string][]*Page) error {
+	marks := make([]string, len(batch))
+	args := make([]interface{}, len(batch))
+	for i, title := range batch {
+		marks[i] = placeholder(i + 1)
+		args[i] = title
+	}
+
+	query := `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, ` + scope.deletedColumn("p") + `,
+		       r.revision_id, r.timestamp, r.user, r.comment, r.content
+		FROM pages p
+		LEFT JOIN revisions r ON r.revision_id = (
+			SELECT r2.revision_id FROM revisions r2
+			WHERE r2.page_id = p.page_id
+			ORDER BY r2.timestamp DESC
+			LIMIT 1
+		)
+		WHERE p.title IN (` + strings.Join(marks, ", ") + `)` + scope.filter("p")
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var page Page
+		var revID sql.NullInt64
+		var timestamp sql.NullTime
+		var user, comment, content sql.NullString
+
+		if err := rows.Scan(
+			&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &page.Deleted,
+			&revID, &timestamp, &user, &comment, &content,
+		); err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		page.LatestRevisionID = revID.Int64
+		page.Timestamp = timestamp.Time
+		page.User = user.String
+		page.Comment = comment.String
+		page.Content = content.String
+		classifyPage(&page)
+		pages[page.Title] = append(pages[page.Title], &page)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// getRevisionsByID loads revisions in batched queries. Results follow the
+// order of ids; missing revisions are nil.
+func getRevisionsByID(ctx context.Context, db querier, ids []int64, placeholder func(n int) string) ([]*Revision, error) {
+	var lookup []int64
+	seen := make(map[int64]bool)
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			lookup = append(lookup, id)
+		}
+	}
+
+	revisions := make(map[int64]*Revision, len(lookup))
+	for start := 0; start < len(lookup); start += maxTitlesPerQuery {
+		batch := lookup[start:min(start+maxTitlesPerQuery, len(lookup))]
+		if err := lookupRevisions(ctx, db, batch, placeholder, revisions); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]*Revision, len(ids))
+	for i, id := range ids {
+		results[i] = revisions[id]
+	}
+	return results, nil
+}
+
+// lookupRevisions loads each revision in batch into revisions.
+func lookupRevisions(ctx context.Context, db querier, batch []int64, placeholder func(n int) string, revisions map[int64]*Revision) error {
+	marks := make([]string, len(batch))
+	args := make([]interface{}, len(batch))
+	for i, id := range batch {
+		marks[i] = placeholder(i + 1)
+		args[i] = id
+	}
+
+	query := `
+		SELECT revision_id, page_id, parent_id, timestamp, user, user_id,
+		       comment, content, size, sha1, minor, tags
+		FROM revisions
+		WHERE revision_id IN (` + strings.Join(marks, ", ") + `)`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rev Revision
+		var parentID sql.NullInt64
+		var user sql.NullString
+		var userID sql.NullInt64
+		var comment sql.NullString
+		var tagsJSON sql.NullString
+
+		if err := rows.Scan(
+			&rev.ID, &rev.PageID, &parentID, &rev.Timestamp, &user, &userID,
+			&comment, &rev.Content, &rev.Size, &rev.SHA1, &rev.Minor, &tagsJSON,
+		); err != nil {
+			return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		if parentID.Valid {
+			pid := parentID.Int64
+			rev.ParentID = &pid
+		}
+		if userID.Valid {
+			uid := int(userID.Int64)
+			rev.UserID = &uid
+		}
+		rev.User = user.String
+		rev.Comment = comment.String
+		if tagsJSON.Valid && tagsJSON.String != "" {
+			json.Unmarshal([]byte(tagsJSON.String), &rev.Tags)
+		}
+		revisions[rev.ID] = &rev
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// GetPagesByTitle resolves many titles at once.
+func (c *sqliteClient) GetPagesByTitle(ctx context.Context, titles []string) ([]TitleResolution, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getPagesByTitle(ctx, c.db, c.wiki, titles, placeholder)
+}
+
+// GetRevisionsByID retrieves many revisions at once.
+func (c *sqliteClient) GetRevisionsByID(ctx context.Context, ids []int64) ([]*Revision, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getRevisionsByID(ctx, c.db, ids, placeholder)
+}
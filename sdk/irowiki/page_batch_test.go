@@ -0,0 +1,101 @@
+package irowiki_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetPagesByTitle tests batch title resolution with normalization
+func TestSQLiteClient_GetPagesByTitle(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	titles := []string{"Poring", "main Page", " Prontera ", "Nonexistent", "", "Poring"}
+	results, err := client.GetPagesByTitle(context.Background(), titles)
+	if err != nil {
+		t.Fatalf("GetPagesByTitle failed: %v", err)
+	}
+	if len(results) != len(titles) {
+		t.Fatalf("expected %d results, got %d", len(titles), len(results))
+	}
+
+	// Test: Results follow the request order and report the stored title
+	want := []string{"Poring", "Main_Page", "Prontera", "", "", "Poring"}
+	for i, r := range results {
+		if r.Requested != titles[i] {
+			t.Errorf("result %d: expected requested %q, got %q", i, titles[i], r.Requested)
+		}
+		if r.Title != want[i] || r.Found() != (want[i] != "") {
+			t.Errorf("result %d: expected title %q, got %q (found %v)", i, want[i], r.Title, r.Found())
+		}
+	}
+
+	// Test: Resolved pages carry their latest revision
+	if page := results[2].Page; page.LatestRevisionID != 103 || page.Content != "Prontera is the capital city" {
+		t.Errorf("expected latest revision 103 of Prontera, got %d %q", page.LatestRevisionID, page.Content)
+	}
+
+	// Test: No titles is not an error
+	results, err = client.GetPagesByTitle(context.Background(), nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected empty result, got %v, %v", results, err)
+	}
+}
+
+// TestSQLiteClient_GetRevisionsByID tests batch revision lookup
+func TestSQLiteClient_GetRevisionsByID(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ids := []int64{104, 999, 102, 104}
+	revisions, err := client.GetRevisionsByID(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("GetRevisionsByID failed: %v", err)
+	}
+	if len(revisions) != len(ids) {
+		t.Fatalf("expected %d revisions, got %d", len(ids), len(revisions))
+	}
+
+	// Test: Results follow the request order, with nil for missing revisions
+	for i, rev := range revisions {
+		if ids[i] == 999 {
+			if rev != nil {
+				t.Errorf("revision %d: expected nil, got %+v", i, rev)
+			}
+			continue
+		}
+		if rev == nil || rev.ID != ids[i] {
+			t.Errorf("revision %d: expected ID %d, got %+v", i, ids[i], rev)
+		}
+	}
+
+	// Test: Revisions are loaded in full
+	single, err := client.GetRevision(context.Background(), 102)
+	if err != nil {
+		t.Fatalf("GetRevision failed: %v", err)
+	}
+	if got := revisions[2]; got.PageID != single.PageID || got.Content != single.Content || got.SHA1 != single.SHA1 || !got.Timestamp.Equal(single.Timestamp) {
+		t.Errorf("expected %+v, got %+v", single, got)
+	}
+
+	// Test: No IDs is not an error
+	revisions, err = client.GetRevisionsByID(context.Background(), nil)
+	if err != nil || len(revisions) != 0 {
+		t.Errorf("expected empty result, got %v, %v", revisions, err)
+	}
+}
@@ -0,0 +1,113 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PageProtection is a protection of a page on the live wiki, as captured
+// by the scraper's --capture-protection option.
+type PageProtection struct {
+	// Action is the protected action: "edit", "move", "upload" or "create".
+	Action string
+
+	// Level is the group allowed to perform it (e.g., "autoconfirmed", "sysop").
+	Level string
+
+	// Expiry is when the protection ends; zero for indefinite protection.
+	Expiry time.Time
+}
+
+// classifyPage sets the stub and disambiguation hints of a page from the
+// templates and categories of its latest wikitext: stub templates and
+// categories contain "stub" ({{Stub}}, {{Monster-stub}}, [[Category:Stubs]]),
+// disambiguation ones are {{Disambig}}, {{Disambiguation}}, {{Dab}} and
+// categories containing "disambiguation". Redirects also get their target,
+// unless it was read with the page.
+func classifyPage(page *Page) {
+	if page.IsRedirect && page.RedirectTarget == "" {
+		page.RedirectTarget = redirectTarget(page.Content)
+	}
+	for _, l := range extractPageLinks(page.Content) {
+		name := strings.ToLower(l.target)
+		switch l.linkType {
+		case LinkTypeTemplate:
+			switch {
+			case strings.Contains(name, "stub"):
+				page.IsStub = true
+			case strings.HasPrefix(name, "disambig"), name == "dab", name == "disamb":
+				page.IsDisambiguation = true
+			}
+		case LinkTypeCategory:
+			switch {
+			case strings.Contains(name, "stub"):
+				page.IsStub = true
+			case strings.Contains(name, "disambiguation"):
+				page.IsDisambiguation = true
+			}
+		}
+	}
+}
+
+// getPageProtection reads the protection of a page. The caller checks that
+// the page_protection table exists.
+func getPageProtection(ctx context.Context, db querier, pageID int64, placeholder func(n int) string) ([]PageProtection, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT action, level, expiry
+		FROM page_protection
+		WHERE page_id = `+placeholder(1)+`
+		ORDER BY action
+	`, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var protection []PageProtection
+	for rows.Next() {
+		var p PageProtection
+		var expiry string
+		if err := rows.Scan(&p.Action, &p.Level, &expiry); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		// "infinity" (or anything unparsable) is indefinite
+		if t, err := time.Parse(time.RFC3339, expiry); err == nil {
+			p.Expiry = t
+		}
+		protection = append(protection, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return protection, nil
+}
+
+// addPageHints sets the rendering hints of a page looked up on its own.
+func (c *sqliteClient) addPageHints(ctx context.Context, page *Page) error {
+	classifyPage(page)
+
+	exists, err := sqliteTableExists(ctx, c.db, "main", "page_protection")
+	if err != nil || !exists {
+		return err
+	}
+	page.Protection, err = getPageProtection(ctx, c.db, page.ID, func(int) string { return "?" })
+	return err
+}
+
+// addPageHints sets the rendering hints of a page looked up on its own.
+func (c *postgresClient) addPageHints(ctx context.Context, page *Page) error {
+	classifyPage(page)
+
+	exists, err := postgresTableExists(ctx, c.db, "page_protection")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	page.Protection, err = getPageProtection(ctx, c.db, page.ID, func(n int) string { return fmt.Sprintf("$%d", n) })
+	return err
+}
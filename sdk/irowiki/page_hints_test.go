@@ -0,0 +1,87 @@
+package irowiki_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_PageHints tests the stub, disambiguation and protection hints of pages
+func TestSQLiteClient_PageHints(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1)
+		VALUES (200, 2, '2030-01-01 00:00:00', 'Prontera may refer to:{{disambig}}[[Category:Monster stubs]]', 60, 'x')`)
+	if err != nil {
+		t.Fatalf("failed to add revision: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Archives without protection data have no protection
+	page, err := client.GetPage(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.IsStub || page.IsDisambiguation || page.Protection != nil {
+		t.Errorf("expected no hints for Poring, got stub=%v disambiguation=%v protection=%v",
+			page.IsStub, page.IsDisambiguation, page.Protection)
+	}
+
+	// Test: Templates and categories of the latest revision set the hints
+	page, err = client.GetPage(ctx, "Prontera")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if !page.IsStub || !page.IsDisambiguation {
+		t.Errorf("expected Prontera to be a stub and a disambiguation page, got stub=%v disambiguation=%v",
+			page.IsStub, page.IsDisambiguation)
+	}
+
+	// Test: Listed pages carry the hints too
+	pages, err := client.ListPages(ctx, 0, 0, 100)
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	for _, p := range pages {
+		if p.Title == "Prontera" && !p.IsDisambiguation {
+			t.Error("expected ListPages to flag Prontera as a disambiguation page")
+		}
+	}
+
+	_, err = tdb.DB.Exec(`CREATE TABLE page_protection (page_id INTEGER NOT NULL, action TEXT NOT NULL, level TEXT NOT NULL,
+			expiry TEXT NOT NULL DEFAULT 'infinity', fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (page_id, action));
+		INSERT INTO page_protection (page_id, action, level, expiry) VALUES
+			(3, 'edit', 'sysop', 'infinity'),
+			(3, 'move', 'autoconfirmed', '2030-01-01T00:00:00Z')`)
+	if err != nil {
+		t.Fatalf("failed to add protection: %v", err)
+	}
+
+	// Test: Captured protection is loaded, with indefinite expiry as zero
+	page, err = client.GetPageByID(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetPageByID failed: %v", err)
+	}
+	if len(page.Protection) != 2 {
+		t.Fatalf("expected 2 protections, got %v", page.Protection)
+	}
+	edit, move := page.Protection[0], page.Protection[1]
+	if edit.Action != "edit" || edit.Level != "sysop" || !edit.Expiry.IsZero() {
+		t.Errorf("unexpected edit protection: %+v", edit)
+	}
+	if move.Action != "move" || move.Level != "autoconfirmed" || !move.Expiry.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected move protection: %+v", move)
+	}
+}
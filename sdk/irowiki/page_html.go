@@ -0,0 +1,93 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PageHTML is the source wiki's own rendering of a page, captured by the
+// scraper's optional --capture-html stage (MediaWiki action=parse). Unlike
+// the render package, it reproduces complex templates and extensions exactly.
+type PageHTML struct {
+	PageID int64  `json:"page_id"`
+	Title  string `json:"title"`
+
+	// RevisionID is the revision that was rendered.
+	RevisionID int64 `json:"revision_id"`
+
+	// LatestRevisionID is the page's latest revision in the archive. It
+	// differs from RevisionID when the page changed after the capture.
+	LatestRevisionID int64 `json:"latest_revision_id"`
+
+	// HTML is the rendered body, without <html> or <body> wrappers. Links
+	// are as the source wiki wrote them (e.g., "/wiki/Prontera").
+	HTML string `json:"html"`
+
+	// FetchedAt is when the HTML was captured.
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Current reports whether the HTML renders the page's latest revision.
+func (h *PageHTML) Current() bool {
+	return h.RevisionID == h.LatestRevisionID
+}
+
+// getPageHTML reads the captured HTML of a page. The caller checks that the
+// page_html table exists.
+func getPageHTML(ctx context.Context, db querier, scope wikiScope, title string, placeholder func(n int) string) (*PageHTML, error) {
+	cond, order, args := titleCondition("p", title, 1, placeholder)
+	query := fmt.Sprintf(`
+		SELECT p.page_id, p.title, h.revision_id, h.html, h.fetched_at,
+		       (SELECT r.revision_id FROM revisions r
+		        WHERE r.page_id = p.page_id
+		        ORDER BY r.timestamp DESC, r.revision_id DESC
+		        LIMIT 1)
+		FROM pages p
+		JOIN page_html h ON h.page_id = p.page_id
+		WHERE %s%s
+		ORDER BY %s
+		LIMIT 1
+	`, cond, scope.filter("p"), order)
+
+	var html PageHTML
+	var fetchedAt sql.NullTime
+	var latest sql.NullInt64
+
+	err := db.QueryRowContext(ctx, query, args...).Scan(
+		&html.PageID, &html.Title, &html.RevisionID, &html.HTML, &fetchedAt, &latest,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	if fetchedAt.Valid {
+		html.FetchedAt = fetchedAt.Time
+	}
+	if latest.Valid {
+		html.LatestRevisionID = latest.Int64
+	}
+	return &html, nil
+}
+
+// GetPageHTML returns the wiki-rendered HTML captured for a page.
+func (c *sqliteClient) GetPageHTML(ctx context.Context, title string) (*PageHTML, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	exists, err := sqliteTableExists(ctx, c.db, "main", "page_html")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getPageHTML(ctx, c.db, c.wiki, title, placeholder)
+}
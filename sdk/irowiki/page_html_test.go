@@ -0,0 +1,79 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetPageHTML tests reading wiki-rendered HTML captured by the scraper
+func TestSQLiteClient_GetPageHTML(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Archives without page_html report ErrNotFound
+	if _, err := client.GetPageHTML(ctx, "Poring"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	_, err = tdb.DB.Exec(`CREATE TABLE page_html (
+		page_id INTEGER PRIMARY KEY,
+		revision_id INTEGER NOT NULL,
+		html TEXT NOT NULL,
+		fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		t.Fatalf("failed to create page_html: %v", err)
+	}
+	_, err = tdb.DB.Exec(`INSERT INTO page_html (page_id, revision_id, html, fetched_at) VALUES
+		(3, 104, '<p>Poring is a <b>pink</b> slime monster.</p>', '2024-01-15 10:30:00'),
+		(2, 102, '<p>Prontera is the capital city.</p>', '2024-01-15 10:30:00')`)
+	if err != nil {
+		t.Fatalf("failed to insert page_html: %v", err)
+	}
+
+	// Test: HTML of the latest revision is current
+	html, err := client.GetPageHTML(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetPageHTML failed: %v", err)
+	}
+	if html.PageID != 3 || html.Title != "Poring" || html.RevisionID != 104 {
+		t.Errorf("unexpected page or revision: %+v", html)
+	}
+	if html.HTML != "<p>Poring is a <b>pink</b> slime monster.</p>" {
+		t.Errorf("unexpected HTML %q", html.HTML)
+	}
+	if !html.Current() {
+		t.Errorf("expected HTML to be current, latest revision %d", html.LatestRevisionID)
+	}
+	if html.FetchedAt.Year() != 2024 {
+		t.Errorf("expected fetched_at in 2024, got %v", html.FetchedAt)
+	}
+
+	// Test: HTML of an older revision is returned but not current
+	html, err = client.GetPageHTML(ctx, "Prontera")
+	if err != nil {
+		t.Fatalf("GetPageHTML failed: %v", err)
+	}
+	if html.Current() || html.LatestRevisionID != 103 {
+		t.Errorf("expected stale HTML with latest revision 103, got %+v", html)
+	}
+
+	// Test: Pages without captured HTML and missing pages report ErrNotFound
+	for _, title := range []string{"Main_Page", "Nonexistent"} {
+		if _, err := client.GetPageHTML(ctx, title); !errors.Is(err, irowiki.ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", title, err)
+		}
+	}
+}
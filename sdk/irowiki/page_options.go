@@ -0,0 +1,181 @@
+package irowiki
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// maxRedirectHops is the number of redirects GetPage follows before
+// returning the redirect it reached, so redirect loops end.
+const maxRedirectHops = 5
+
+// RedirectMode selects how GetPage treats redirects.
+type RedirectMode int
+
+const (
+	// NoFollow returns a redirect page itself (the default).
+	NoFollow RedirectMode = iota
+
+	// Follow returns the page a redirect points to, following up to five
+	// redirects, with RedirectedFrom set to the title asked for. Broken
+	// redirects and loops return the last redirect reached.
+	Follow
+)
+
+// PageOptions configures GetPage. Build it with PageOption functions. When
+// a call has options, interceptors get them resolved as GetPage's last
+// argument.
+type PageOptions struct {
+	// Content includes the wikitext of the latest revision (default: true).
+	Content bool
+
+	// Redirects selects how redirects are treated (default: NoFollow).
+	Redirects RedirectMode
+}
+
+// PageOption configures a GetPage call.
+type PageOption func(*PageOptions)
+
+// WithContent sets whether the page's wikitext is loaded. Without it,
+// Content is empty, which keeps lookups cheap when only a page's metadata
+// is needed. The hints read from the wikitext, IsStub and
+// IsDisambiguation, are then unset; RedirectTarget is still set.
+func WithContent(content bool) PageOption {
+	return func(o *PageOptions) {
+		o.Content = content
+	}
+}
+
+// WithRedirects sets how redirects are treated.
+func WithRedirects(mode RedirectMode) PageOption {
+	return func(o *PageOptions) {
+		o.Redirects = mode
+	}
+}
+
+// NewPageOptions applies opts to the defaults.
+func NewPageOptions(opts ...PageOption) PageOptions {
+	o := PageOptions{Content: true, Redirects: NoFollow}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// getPageWithOptions implements GetPage's options over get, a backend's
+// lookup of a page by title, with or without its content.
+func getPageWithOptions(ctx context.Context, title string, opts []PageOption, get func(ctx context.Context, title string, content bool) (*Page, error)) (*Page, error) {
+	o := NewPageOptions(opts...)
+
+	page, err := get(ctx, title, o.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Redirects == Follow {
+		seen := []int64{page.ID}
+		for hops := 0; page.IsRedirect && page.RedirectTarget != "" && hops < maxRedirectHops; hops++ {
+			// Targets are normalized with spaces; archives store underscores
+			target, err := get(ctx, page.RedirectTarget, o.Content)
+			if errors.Is(err, ErrNotFound) {
+				target, err = get(ctx, strings.ReplaceAll(page.RedirectTarget, " ", "_"), o.Content)
+			}
+			if errors.Is(err, ErrNotFound) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if slices.Contains(seen, target.ID) {
+				break
+			}
+			seen = append(seen, target.ID)
+			page = target
+		}
+		// Pages are copied before they're changed: the backend may share
+		// them, such as from a cache
+		if len(seen) > 1 {
+			followed := *page
+			followed.RedirectedFrom = title
+			page = &followed
+		}
+	}
+	return page, nil
+}
+
+// FindPage looks up a page like client.GetPage, accepting spaces and
+// underscores for each other, as titles typed by hand or taken from URLs
+// mix them. The title as given is tried first.
+func FindPage(ctx context.Context, client Client, title string, opts ...PageOption) (*Page, error) {
+	page, err := client.GetPage(ctx, title, opts...)
+	if !errors.Is(err, ErrNotFound) {
+		return page, err
+	}
+
+	alt := strings.ReplaceAll(title, "_", " ")
+	if alt == title {
+		alt = strings.ReplaceAll(title, " ", "_")
+	}
+	if alt == title {
+		return nil, err
+	}
+	return client.GetPage(ctx, alt, opts...)
+}
+
+// ClientV1 is Client with GetPage as it was before its options, for
+// backends implementing that signature. Adapt one with FromV1. It isn't a
+// frozen interface: methods added to Client are added to ClientV1 too.
+type ClientV1 interface {
+	// GetPage retrieves the latest version of a page by title.
+	// Returns ErrNotFound if the page doesn't exist.
+	GetPage(ctx context.Context, title string) (*Page, error)
+
+	ClientBase
+}
+
+// FromV1 adapts a ClientV1 to Client. GetPage's options are implemented
+// over the backend's GetPage, which always loads the content; WithContent
+// only leaves it out of the page returned. The other methods are the
+// backend's own.
+//
+// Example:
+//
+//	func init() {
+//	    irowiki.Register("duckdb", func(dsn string, opts irowiki.ConnectionOptions) (irowiki.Client, error) {
+//	        c, err := openDuckDB(dsn, opts) // a ClientV1
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        return irowiki.FromV1(c), nil
+//	    })
+//	}
+func FromV1(client ClientV1) Client {
+	return &v1Client{ClientBase: client, v1: client}
+}
+
+// v1Client is a ClientV1 adapted to Client.
+type v1Client struct {
+	ClientBase
+	v1 ClientV1
+}
+
+// GetPage retrieves a page through the backend's GetPage.
+func (c *v1Client) GetPage(ctx context.Context, title string, opts ...PageOption) (*Page, error) {
+	return getPageWithOptions(ctx, title, opts, c.getPage)
+}
+
+// getPage looks a page up with the backend's GetPage, copying it without
+// its content unless content is set.
+func (c *v1Client) getPage(ctx context.Context, title string, content bool) (*Page, error) {
+	page, err := c.v1.GetPage(ctx, title)
+	if err != nil || content {
+		return page, err
+	}
+	stripped := *page
+	stripped.Content = ""
+	return &stripped, nil
+}
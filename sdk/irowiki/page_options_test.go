@@ -0,0 +1,144 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetPage_Options tests GetPage's functional options
+func TestSQLiteClient_GetPage_Options(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`
+		UPDATE revisions SET content = '#REDIRECT [[Main Page]]' WHERE revision_id = 106;
+		INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES
+			(20, 0, 'Loop_A', 1),
+			(21, 0, 'Loop_B', 1),
+			(22, 0, 'Broken', 1);
+		INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES
+			(300, 20, '2024-01-01 00:00:00', '#REDIRECT [[Loop B]]', 20, 'x'),
+			(301, 21, '2024-01-01 00:00:00', '#REDIRECT [[Loop A]]', 20, 'x'),
+			(302, 22, '2024-01-01 00:00:00', '#REDIRECT [[Nowhere]]', 21, 'x')`)
+	if err != nil {
+		t.Fatalf("failed to add redirects: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	check := func(client irowiki.Client) {
+		t.Helper()
+		ctx := context.Background()
+
+		// Test: Without options, redirects are returned as themselves
+		page, err := client.GetPage(ctx, "Redirect_Test")
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		if !page.IsRedirect || page.RedirectedFrom != "" {
+			t.Errorf("expected the redirect itself, got %+v", page)
+		}
+
+		page, err = client.GetPage(ctx, "Redirect_Test", irowiki.WithRedirects(irowiki.Follow), irowiki.WithContent(false))
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		if page.Title != "Main_Page" || page.RedirectedFrom != "Redirect_Test" {
+			t.Errorf("expected Main_Page redirected from Redirect_Test, got %q from %q", page.Title, page.RedirectedFrom)
+		}
+		if page.Content != "" || page.LatestRevisionID != 101 {
+			t.Errorf("expected revision 101 without content, got %d: %q", page.LatestRevisionID, page.Content)
+		}
+
+		// Test: Without content, redirects still have their target
+		page, err = client.GetPage(ctx, "Redirect_Test", irowiki.WithContent(false))
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		if page.Content != "" || page.RedirectTarget != "Main Page" {
+			t.Errorf("expected the target without content, got %q: %q", page.RedirectTarget, page.Content)
+		}
+
+		// Test: Loops and broken redirects return the last redirect reached
+		page, err = client.GetPage(ctx, "Loop_A", irowiki.WithRedirects(irowiki.Follow))
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		if page.Title != "Loop_B" || page.RedirectedFrom != "Loop_A" {
+			t.Errorf("expected Loop_B redirected from Loop_A, got %q from %q", page.Title, page.RedirectedFrom)
+		}
+		page, err = client.GetPage(ctx, "Broken", irowiki.WithRedirects(irowiki.Follow))
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		if page.Title != "Broken" || page.RedirectedFrom != "" {
+			t.Errorf("expected the broken redirect itself, got %q from %q", page.Title, page.RedirectedFrom)
+		}
+
+		if _, err := client.GetPage(ctx, "Nowhere", irowiki.WithRedirects(irowiki.Follow)); !errors.Is(err, irowiki.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	}
+
+	check(client)
+
+	// Test: Options pass through interceptors, which see them resolved
+	var seen []any
+	check(irowiki.WithInterceptor(client, func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+		if call.Method == "GetPage" {
+			seen = append(seen, call.Args...)
+		}
+		return next(ctx)
+	}))
+	want := irowiki.PageOptions{Content: false, Redirects: irowiki.Follow}
+	if len(seen) < 3 || seen[0] != "Redirect_Test" || seen[2] != want {
+		t.Errorf("expected the interceptor to see resolved options, got %v", seen)
+	}
+
+	// Test: Backends written against the v1 interface get options from FromV1
+	check(irowiki.FromV1(v1Client{client}))
+}
+
+// TestFindPage tests looking pages up with spaces and underscores mixed
+func TestFindPage(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	for _, title := range []string{"Main_Page", "Main Page"} {
+		page, err := irowiki.FindPage(ctx, client, title, irowiki.WithContent(false))
+		if err != nil {
+			t.Fatalf("FindPage(%q) failed: %v", title, err)
+		}
+		if page.ID != 1 || page.Content != "" {
+			t.Errorf("FindPage(%q): expected page 1 without content, got %d: %q", title, page.ID, page.Content)
+		}
+	}
+
+	if _, err := irowiki.FindPage(ctx, client, "No such page"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// v1Client implements GetPage with its signature before options.
+type v1Client struct {
+	irowiki.Client
+}
+
+func (c v1Client) GetPage(ctx context.Context, title string) (*irowiki.Page, error) {
+	return c.Client.GetPage(ctx, title)
+}
@@ -0,0 +1,279 @@
+package irowiki
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// contentBatchSize is the number of revisions fetched per request, the
+// batch limit of "irowiki serve".
+const contentBatchSize = 1000
+
+// sqliteContentCacheSchema creates the cache of fetched revision text.
+var sqliteContentCacheSchema = []string{
+	`CREATE TABLE IF NOT EXISTS revision_text (
+		revision_id INTEGER PRIMARY KEY,
+		content TEXT NOT NULL
+	)`,
+}
+
+// partialClone is a Client over a local archive missing revision text,
+// which it fetches from a remote archive server and caches.
+type partialClone struct {
+	Client
+	fetcher *contentFetcher
+}
+
+// openPartialClone wraps client so that revision text it lacks is fetched
+// from opts.ContentURL.
+func openPartialClone(client Client, opts ConnectionOptions) (Client, error) {
+	cacheDir, err := remoteCacheDir(opts.CacheDir)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	// One cache per server, shared by the archives cloned from it
+	sum := sha256.Sum256([]byte(opts.ContentURL))
+	path := filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+"-content.db")
+	if err := createSQLiteArchive(context.Background(), path, sqliteContentCacheSchema); err != nil {
+		client.Close()
+		return nil, err
+	}
+	cache, err := sql.Open("sqlite", path)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%w: failed to open content cache: %v", ErrConnectionFailed, err)
+	}
+	cache.SetMaxOpenConns(1)
+
+	fetcher := &contentFetcher{
+		baseURL: strings.TrimSuffix(opts.ContentURL, "/"),
+		http:    http.DefaultClient,
+		cache:   cache,
+	}
+	return &partialClone{Client: WithInterceptor(client, fetcher.intercept), fetcher: fetcher}, nil
+}
+
+// Close closes the local archive and the content cache.
+func (c *partialClone) Close() error {
+	err := c.Client.Close()
+	c.fetcher.cache.Close()
+	return err
+}
+
+// contentFetcher fills in revision text missing from query results.
+type contentFetcher struct {
+	baseURL string
+	http    *http.Client
+	cache   *sql.DB
+
+	// mu serializes fetches, so concurrent calls for the same revisions
+	// fetch them once
+	mu sync.Mutex
+}
+
+// intercept hydrates the results of the Client methods that return
+// revision text.
+func (f *contentFetcher) intercept(ctx context.Context, call Call, next Invoker) (any, error) {
+	result, err := next(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	var targets []contentTarget
+	switch v := result.(type) {
+	case *Page:
+		targets = appendPageTarget(targets, v)
+	case []TitleResolution:
+		for _, res := range v {
+			targets = appendPageTarget(targets, res.Page)
+		}
+	case *Revision:
+		targets = appendRevisionTarget(targets, v)
+	case []*Revision:
+		for _, rev := range v {
+			targets = appendRevisionTarget(targets, rev)
+		}
+	case []Revision:
+		if opts, _ := call.Args[len(call.Args)-1].(HistoryOptions); opts.ExcludeContent {
+			break
+		}
+		for i := range v {
+			targets = appendRevisionTarget(targets, &v[i])
+		}
+	}
+	if len(targets) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int64, len(targets))
+	for i, t := range targets {
+		ids[i] = t.revisionID
+	}
+	content, err := f.content(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		*t.content = content[t.revisionID]
+	}
+	return result, nil
+}
+
+// contentTarget is a result field awaiting the text of a revision.
+type contentTarget struct {
+	revisionID int64
+	content    *string
+}
+
+func appendPageTarget(targets []contentTarget, p *Page) []contentTarget {
+	if p == nil || p.Content != "" || p.LatestRevisionID == 0 {
+		return targets
+	}
+	return append(targets, contentTarget{revisionID: p.LatestRevisionID, content: &p.Content})
+}
+
+func appendRevisionTarget(targets []contentTarget, rev *Revision) []contentTarget {
+	if rev == nil || rev.Content != "" {
+		return targets
+	}
+	return append(targets, contentTarget{revisionID: rev.ID, content: &rev.Content})
+}
+
+// content returns the text of the revisions, from the cache or else the
+// server. Revisions the server doesn't have are left out.
+func (f *contentFetcher) content(ctx context.Context, ids []int64) (map[int64]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, err := f.cached(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	var missing []int64
+	for _, id := range ids {
+		if _, ok := content[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	for start := 0; start < len(missing); start += contentBatchSize {
+		batch := missing[start:min(start+contentBatchSize, len(missing))]
+		fetched, err := f.fetch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.store(ctx, fetched); err != nil {
+			return nil, err
+		}
+		for id, text := range fetched {
+			content[id] = text
+		}
+	}
+	return content, nil
+}
+
+// cached returns the cached text of the revisions.
+func (f *contentFetcher) cached(ctx context.Context, ids []int64) (map[int64]string, error) {
+	content := make(map[int64]string, len(ids))
+	for start := 0; start < len(ids); start += contentBatchSize {
+		batch := ids[start:min(start+contentBatchSize, len(ids))]
+		marks := make([]string, len(batch))
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			marks[i] = "?"
+			args[i] = id
+		}
+		query := "SELECT revision_id, content FROM revision_text WHERE revision_id IN (" + strings.Join(marks, ", ") + ")"
+		rows, err := f.cache.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read content cache: %v", ErrDatabaseError, err)
+		}
+		for rows.Next() {
+			var id int64
+			var text string
+			if err := rows.Scan(&id, &text); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+			}
+			content[id] = text
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+	}
+	return content, nil
+}
+
+// store caches fetched text.
+func (f *contentFetcher) store(ctx context.Context, content map[int64]string) error {
+	tx, err := f.cache.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	for id, text := range content {
+		if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO revision_text (revision_id, content) VALUES (?, ?)", id, text); err != nil {
+			return fmt.Errorf("%w: failed to write content cache: %v", ErrDatabaseError, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// fetch loads the text of the revisions from the server's
+// /revisions:batchGet endpoint.
+func (f *contentFetcher) fetch(ctx context.Context, ids []int64) (map[int64]string, error) {
+	body, err := json.Marshal(map[string][]int64{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.baseURL+"/revisions:batchGet", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid content URL %q: %v", ErrInvalidInput, f.baseURL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to fetch revision content: %v", ErrConnectionFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: failed to fetch revision content: %s", ErrConnectionFailed, resp.Status)
+	}
+
+	var batch struct {
+		Revisions []struct {
+			Requested int64  `json:"requested"`
+			Found     bool   `json:"found"`
+			Content   string `json:"content"`
+		} `json:"revisions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("%w: invalid content response: %v", ErrConnectionFailed, err)
+	}
+
+	content := make(map[int64]string, len(batch.Revisions))
+	for _, rev := range batch.Revisions {
+		if rev.Found {
+			content[rev.Requested] = rev.Content
+		}
+	}
+	return content, nil
+}
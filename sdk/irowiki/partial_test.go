@@ -0,0 +1,123 @@
+package irowiki_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestOpenSQLite_PartialClone tests fetching text missing from an index-only archive
+func TestOpenSQLite_PartialClone(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	full, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer full.Close()
+
+	// A server answering /revisions:batchGet from the full archive
+	var fetched [][]int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/revisions:batchGet" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			IDs []int64 `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fetched = append(fetched, req.IDs)
+		revs, err := full.GetRevisionsByID(r.Context(), req.IDs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		type revisionJSON struct {
+			Requested int64  `json:"requested"`
+			Found     bool   `json:"found"`
+			Content   string `json:"content,omitempty"`
+		}
+		out := make([]revisionJSON, len(revs))
+		for i, rev := range revs {
+			out[i] = revisionJSON{Requested: req.IDs[i], Found: rev != nil}
+			if rev != nil {
+				out[i].Content = rev.Content
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"revisions": out})
+	}))
+	defer srv.Close()
+
+	// The index-only download: the main shard without the text shard
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	if _, err := store.ShardArchive(ctx, dir, irowiki.ShardOptions{
+		Shards:       []irowiki.ShardSpec{{Name: "index"}},
+		SeparateText: true,
+	}); err != nil {
+		t.Fatalf("ShardArchive failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "text.db")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.ContentURL = srv.URL
+	opts.CacheDir = t.TempDir()
+	client, err := irowiki.OpenSQLiteWithOptions(filepath.Join(dir, "index.db"), opts)
+	if err != nil {
+		t.Fatalf("failed to open partial clone: %v", err)
+	}
+	defer client.Close()
+
+	page, err := client.GetPage(ctx, "Prontera")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.Content != "Prontera is the capital city" {
+		t.Errorf("expected fetched content, got %q", page.Content)
+	}
+
+	history, err := client.GetPageHistory(ctx, "Prontera", irowiki.HistoryOptions{})
+	if err != nil {
+		t.Fatalf("GetPageHistory failed: %v", err)
+	}
+	if len(history) != 2 || history[1].Content != "Prontera is the capital city." {
+		t.Errorf("expected history with content, got %+v", history)
+	}
+
+	// Test: Cached text isn't fetched again, and metadata-only history fetches nothing
+	if _, err := client.GetRevision(ctx, 103); err != nil {
+		t.Fatalf("GetRevision failed: %v", err)
+	}
+	if _, err := client.GetPageHistory(ctx, "Main_Page", irowiki.HistoryOptions{ExcludeContent: true}); err != nil {
+		t.Fatalf("GetPageHistory failed: %v", err)
+	}
+	if len(fetched) != 2 || len(fetched[1]) != 1 || fetched[1][0] != 102 {
+		t.Errorf("expected 103 then 102 to be fetched once, got %v", fetched)
+	}
+
+	// Test: Without a content URL the missing text shard fails to open
+	if _, err := irowiki.OpenSQLite(filepath.Join(dir, "index.db")); !errors.Is(err, irowiki.ErrConnectionFailed) {
+		t.Errorf("expected ErrConnectionFailed, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,10 +14,15 @@ import (
 
 // postgresClient implements the Client interface for PostgreSQL databases.
 type postgresClient struct {
-	db     *sql.DB
+	db     querier
+	pool   *sql.DB
 	opts   ConnectionOptions
+	wiki   wikiScope
 	closed bool
 	mu     sync.RWMutex
+
+	// snapshot, if set, is the read transaction db runs in (see Snapshot).
+	snapshot *sql.Tx
 }
 
 // OpenPostgres opens a PostgreSQL database with the specified DSN and default options.
@@ -40,7 +46,7 @@ func OpenPostgres(dsn string) (Client, error) {
 func OpenPostgresWithOptions(dsn string, opts ConnectionOptions) (Client, error) {
 	opts.applyDefaults(false)
 
-	db, err := sql.Open("postgres", dsn)
+	db, err := openPostgresDB(dsn, opts)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to open database: %v", ErrConnectionFailed, err)
 	}
@@ -60,9 +66,18 @@ func OpenPostgresWithOptions(dsn string, opts ConnectionOptions) (Client, error)
 		return nil, fmt.Errorf("%w: failed to ping database: %v", ErrConnectionFailed, err)
 	}
 
+	wiki, err := openPostgresWikiScope(ctx, db, opts.WikiID)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	wiki.deleted = newDeletedMode(opts.IncludeDeleted, opts.OnlyDeleted)
+
 	client := &postgresClient{
 		db:     db,
+		pool:   db,
 		opts:   opts,
+		wiki:   wiki,
 		closed: false,
 	}
 
@@ -87,29 +102,40 @@ func (c *postgresClient) ensureNotClosed() error {
 // - Better full-text search with ts_vector (future enhancement)
 
 // GetPage retrieves the latest version of a page by title.
-func (c *postgresClient) GetPage(ctx context.Context, title string) (*Page, error) {
+func (c *postgresClient) GetPage(ctx context.Context, title string, opts ...PageOption) (*Page, error) {
+	return getPageWithOptions(ctx, title, opts, c.getPage)
+}
+
+// getPage retrieves the latest version of a page by title. Without content,
+// the wikitext isn't loaded; redirects get their target from the query.
+func (c *postgresClient) getPage(ctx context.Context, title string, content bool) (*Page, error) {
 	if err := c.ensureNotClosed(); err != nil {
 		return nil, err
 	}
 
-	const query = `
-		SELECT p.page_id, p.namespace, p.title, p.is_redirect,
-		       r.revision_id, r.timestamp, r.user, r.comment, r.content
+	contentColumn, targetColumn := "r.content", "NULL"
+	if !content {
+		contentColumn, targetColumn = "NULL", c.wiki.redirectTargetColumn("p", postgresRedirectTarget)
+	}
+	cond, order, args := titleCondition("p", title, 1, func(n int) string { return fmt.Sprintf("$%d", n) })
+	query := `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, ` + c.wiki.deletedColumn("p") + `,
+		       r.revision_id, r.timestamp, r.user, r.comment, ` + contentColumn + `, ` + targetColumn + `
 		FROM pages p
 		LEFT JOIN revisions r ON p.page_id = r.page_id
-		WHERE p.title = $1
-		ORDER BY r.timestamp DESC
+		WHERE ` + cond + c.wiki.filter("p") + `
+		ORDER BY ` + order + `, r.timestamp DESC
 		LIMIT 1
 	`
 
 	var page Page
 	var revID sql.NullInt64
 	var timestamp sql.NullTime
-	var user, comment, content sql.NullString
+	var user, comment, text, target sql.NullString
 
-	err := c.db.QueryRowContext(ctx, query, title).Scan(
-		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect,
-		&revID, &timestamp, &user, &comment, &content,
+	err := c.db.QueryRowContext(ctx, query, args...).Scan(
+		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &page.Deleted,
+		&revID, &timestamp, &user, &comment, &text, &target,
 	)
 
 	if err == sql.ErrNoRows {
@@ -132,8 +158,15 @@ func (c *postgresClient) GetPage(ctx context.Context, title string) (*Page, erro
 	if comment.Valid {
 		page.Comment = comment.String
 	}
-	if content.Valid {
-		page.Content = content.String
+	if text.Valid {
+		page.Content = text.String
+	}
+	if target.Valid {
+		page.RedirectTarget = target.String
+	}
+
+	if err := c.addPageHints(ctx, &page); err != nil {
+		return nil, err
 	}
 
 	return &page, nil
@@ -145,8 +178,8 @@ func (c *postgresClient) GetPageByID(ctx context.Context, id int64) (*Page, erro
 		return nil, err
 	}
 
-	const query = `
-		SELECT p.page_id, p.namespace, p.title, p.is_redirect,
+	query := `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, ` + c.wiki.deletedColumn("p") + `,
 		       r.revision_id, r.timestamp, r.user, r.comment, r.content
 		FROM pages p
 		LEFT JOIN revisions r ON p.page_id = r.page_id
@@ -161,7 +194,7 @@ func (c *postgresClient) GetPageByID(ctx context.Context, id int64) (*Page, erro
 	var user, comment, content sql.NullString
 
 	err := c.db.QueryRowContext(ctx, query, id).Scan(
-		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect,
+		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &page.Deleted,
 		&revID, &timestamp, &user, &comment, &content,
 	)
 
@@ -189,6 +222,10 @@ func (c *postgresClient) GetPageByID(ctx context.Context, id int64) (*Page, erro
 		page.Content = content.String
 	}
 
+	if err := c.addPageHints(ctx, &page); err != nil {
+		return nil, err
+	}
+
 	return &page, nil
 }
 
@@ -202,8 +239,8 @@ func (c *postgresClient) ListPages(ctx context.Context, namespace int, offset, l
 		limit = 100
 	}
 
-	const query = `
-		SELECT p.page_id, p.namespace, p.title, p.is_redirect,
+	query := `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, ` + c.wiki.deletedColumn("p") + `,
 		       r.revision_id, r.timestamp, r.user, r.comment, r.content
 		FROM pages p
 		LEFT JOIN LATERAL (
@@ -213,7 +250,7 @@ func (c *postgresClient) ListPages(ctx context.Context, namespace int, offset, l
 			ORDER BY timestamp DESC
 			LIMIT 1
 		) r ON true
-		WHERE p.namespace = $1
+		WHERE p.namespace = $1` + c.wiki.filter("p") + `
 		ORDER BY p.page_id
 		LIMIT $2 OFFSET $3
 	`
@@ -232,7 +269,7 @@ func (c *postgresClient) ListPages(ctx context.Context, namespace int, offset, l
 		var user, comment, content sql.NullString
 
 		err := rows.Scan(
-			&page.ID, &page.Namespace, &page.Title, &page.IsRedirect,
+			&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &page.Deleted,
 			&revID, &timestamp, &user, &comment, &content,
 		)
 		if err != nil {
@@ -256,6 +293,7 @@ func (c *postgresClient) ListPages(ctx context.Context, namespace int, offset, l
 			page.Content = content.String
 		}
 
+		classifyPage(&page)
 		pages = append(pages, page)
 	}
 
@@ -272,34 +310,50 @@ func (c *postgresClient) Search(ctx context.Context, opts SearchOptions) ([]Sear
 		return nil, err
 	}
 
+	if err := validateMatchMode(opts.MatchMode, opts.Query); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
+	}
+	if err := validateCollation(opts.Collation); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
+	}
 	if opts.Limit == 0 {
 		opts.Limit = 100
 	}
+	opts.Query = nfc(opts.Query)
+
+	where, args := c.searchConditions(opts)
+
+	from, redirectedFrom := "pages p", "NULL"
+	if opts.CollapseRedirects {
+		from = "(" + collapsedMatches(c.searchMatches(where), c.wiki.filter("t")) + ") x JOIN pages p ON p.page_id = x.page_id"
+		redirectedFrom, where = "x.redirected_from", "TRUE"
+	}
 
 	query := `
-		SELECT p.page_id, p.namespace, p.title, r.timestamp
-		FROM pages p
+		SELECT p.page_id, p.namespace, p.title, r.timestamp, r.content, ` + redirectedFrom + `
+		FROM ` + from + `
 		LEFT JOIN LATERAL (
-			SELECT timestamp
+			SELECT timestamp, content
 			FROM revisions
 			WHERE page_id = p.page_id
 			ORDER BY timestamp DESC
 			LIMIT 1
 		) r ON true
-		WHERE p.title LIKE $1
+		WHERE ` + where + `
 	`
 
-	args := []interface{}{"%" + opts.Query + "%"}
-	paramCount := 1
-
-	if opts.Namespace >= 0 {
-		paramCount++
-		query += fmt.Sprintf(" AND p.namespace = $%d", paramCount)
-		args = append(args, opts.Namespace)
+	orderBy := "p.page_id"
+	if opts.SortBy == "title" {
+		orderBy = "p.title"
+		if opts.Collation == CollationUnicode {
+			orderBy += " COLLATE " + postgresUnicodeCollation
+		}
+		if opts.SortOrder == "desc" {
+			orderBy += " DESC"
+		}
+		orderBy += ", p.page_id"
 	}
-
-	paramCount++
-	query += fmt.Sprintf(" ORDER BY p.page_id LIMIT $%d OFFSET $%d", paramCount, paramCount+1)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, len(args)+1, len(args)+2)
 	args = append(args, opts.Limit, opts.Offset)
 
 	rows, err := c.db.QueryContext(ctx, query, args...)
@@ -312,15 +366,21 @@ func (c *postgresClient) Search(ctx context.Context, opts SearchOptions) ([]Sear
 	for rows.Next() {
 		var result SearchResult
 		var timestamp sql.NullTime
+		var content, redirectedFrom sql.NullString
 
-		err := rows.Scan(&result.PageID, &result.Namespace, &result.Title, &timestamp)
+		err := rows.Scan(&result.PageID, &result.Namespace, &result.Title, &timestamp, &content, &redirectedFrom)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
 		}
+		result.RedirectedFrom = redirectedFrom.String
 
 		if timestamp.Valid {
 			result.Timestamp = timestamp.Time
 		}
+		if content.Valid {
+			result.Snippet = buildSnippet(content.String, opts.Query, opts.SnippetLength)
+		}
+		result.MatchType = "title"
 
 		results = append(results, result)
 	}
@@ -332,35 +392,277 @@ func (c *postgresClient) Search(ctx context.Context, opts SearchOptions) ([]Sear
 	return results, nil
 }
 
-// SearchFullText performs full-text search across page content.
-func (c *postgresClient) SearchFullText(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
-	// For now, use simple search as full-text search requires ts_vector setup
-	return c.Search(ctx, opts)
+// ExportRows streams a table to w: first the column names, then one record per row.
+func (c *postgresClient) ExportRows(ctx context.Context, opts ExportOptions, w RowWriter) error {
+	if err := c.ensureNotClosed(); err != nil {
+		return err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return exportRows(ctx, c.db, opts, w, true, placeholder)
+}
+
+// SearchRevisions searches the content of every revision, not just the latest.
+func (c *postgresClient) SearchRevisions(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return searchRevisions(ctx, c.db, c.wiki, query, opts, revisionField{column: "r.content"}, placeholder, "ILIKE")
 }
 
-// GetPageHistory retrieves the revision history for a page.
-func (c *postgresClient) GetPageHistory(ctx context.Context, title string, opts HistoryOptions) ([]Revision, error) {
+// SearchComments searches the edit summaries of every revision.
+func (c *postgresClient) SearchComments(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error) {
 	if err := c.ensureNotClosed(); err != nil {
 		return nil, err
 	}
 
-	// First, get the page ID
-	var pageID int64
-	err := c.db.QueryRowContext(ctx, "SELECT page_id FROM pages WHERE title = $1", title).Scan(&pageID)
-	if err == sql.ErrNoRows {
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return searchRevisions(ctx, c.db, c.wiki, query, opts, revisionField{column: "r.comment"}, placeholder, "ILIKE")
+}
+
+// GetPagesByTitle resolves many titles at once.
+func (c *postgresClient) GetPagesByTitle(ctx context.Context, titles []string) ([]TitleResolution, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getPagesByTitle(ctx, c.db, c.wiki, titles, placeholder)
+}
+
+// GetRevisionsByID retrieves many revisions at once.
+func (c *postgresClient) GetRevisionsByID(ctx context.Context, ids []int64) ([]*Revision, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getRevisionsByID(ctx, c.db, ids, placeholder)
+}
+
+// GetPageHTML returns the wiki-rendered HTML captured for a page.
+func (c *postgresClient) GetPageHTML(ctx context.Context, title string) (*PageHTML, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	exists, err := postgresTableExists(ctx, c.db, "page_html")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
 		return nil, ErrNotFound
 	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getPageHTML(ctx, c.db, c.wiki, title, placeholder)
+}
+
+// GetModuleDependencies returns the Scribunto modules a page depends on.
+func (c *postgresClient) GetModuleDependencies(ctx context.Context, title string) ([]ModuleDependency, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getModuleDependencies(ctx, c.db, c.wiki, title, placeholder)
+}
+
+// SearchPaged performs a title search and returns the results with pagination metadata.
+// If the query matches nothing, Suggestion holds a spell-corrected query that does.
+func (c *postgresClient) SearchPaged(ctx context.Context, opts SearchOptions) (*PagedResult, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if opts.Limit == 0 {
+		opts.Limit = 100
+	}
+
+	// Fetch one extra result to determine HasMore without counting
+	fetch := opts
+	fetch.Limit++
+	results, err := c.Search(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	paged := &PagedResult{
+		Results: results,
+		Offset:  opts.Offset,
+		Limit:   opts.Limit,
+	}
+	if len(results) > opts.Limit {
+		paged.Results = results[:opts.Limit]
+		paged.HasMore = true
+	}
+
+	if opts.IncludeTotalCount {
+		total, err := c.countSearchResults(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		paged.Total = total
+	}
+
+	if len(results) == 0 && opts.Query != "" && opts.MatchMode != MatchRegex {
+		correction, err := c.suggestCorrection(ctx, opts.Query)
+		if err != nil {
+			return nil, err
+		}
+		if correction != "" {
+			corrected := opts
+			corrected.Query = correction
+			total, err := c.countSearchResults(ctx, corrected)
+			if err != nil {
+				return nil, err
+			}
+			if total > 0 {
+				paged.Suggestion = correction
+			}
+		}
+	}
+
+	return paged, nil
+}
+
+// searchConditions returns the WHERE conditions of a title search on pages p.
+func (c *postgresClient) searchConditions(opts SearchOptions) (string, []interface{}) {
+	match, matchArg := c.titleMatch(opts)
+	where := match + c.wiki.forSearch(opts).filter("p")
+	args := []interface{}{matchArg}
+
+	if opts.Namespace >= 0 {
+		args = append(args, opts.Namespace)
+		where += fmt.Sprintf(" AND p.namespace = $%d", len(args))
+	}
+	return where, args
+}
+
+// searchMatches lists the pages matching a title search's conditions, with
+// the targets of redirects, for collapsedMatches.
+func (c *postgresClient) searchMatches(where string) string {
+	return `
+		SELECT p.page_id, p.title,
+		       ` + c.wiki.redirectTargetColumn("p", postgresRedirectTarget) + ` AS target,
+		       1.0 AS relevance
+		FROM pages p
+		WHERE ` + where
+}
+
+// titleMatch returns the condition matching titles against the search query.
+// An empty query matches every title, whatever the mode.
+func (c *postgresClient) titleMatch(opts SearchOptions) (string, interface{}) {
+	if opts.Query == "" {
+		opts.MatchMode = MatchSubstring
+	}
+	return titleMatch(opts, "$1", "%s LIKE %s", "%s ILIKE %s", "%s ~* %s")
+}
+
+// countSearchResults counts all title search results, ignoring pagination.
+func (c *postgresClient) countSearchResults(ctx context.Context, opts SearchOptions) (int, error) {
+	where, args := c.searchConditions(opts)
+
+	query := "SELECT COUNT(*) FROM pages p WHERE " + where
+	if opts.CollapseRedirects {
+		query = "SELECT COUNT(*) FROM (" + collapsedMatches(c.searchMatches(where), c.wiki.filter("t")) + ") x"
+	}
+
+	var total int
+	if err := c.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return total, nil
+}
+
+// SuggestTitles returns page titles starting with prefix (case-insensitive).
+// If nothing matches, the prefix is spell-corrected against title words and
+// completions for the corrected spelling are returned along with the correction.
+func (c *postgresClient) SuggestTitles(ctx context.Context, prefix string, limit int) (*TitleSuggestions, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return suggestTitles(ctx, prefix, limit, c.titlesWithPrefix, c.suggestCorrection)
+}
+
+// titlesWithPrefix returns up to limit titles starting with prefix, shortest first.
+func (c *postgresClient) titlesWithPrefix(ctx context.Context, prefix string, limit int) ([]string, error) {
+	query := `
+		SELECT title FROM pages
+		WHERE LOWER(REPLACE(title, '_', ' ')) LIKE $1 ESCAPE '\'` + c.wiki.filter("pages") + `
+		ORDER BY is_redirect, LENGTH(title), title
+		LIMIT $2
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, escapeLike(strings.ToLower(strings.ReplaceAll(prefix, "_", " ")))+"%", limit)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
 	}
+	defer rows.Close()
+
+	titles := []string{}
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		titles = append(titles, title)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return titles, nil
+}
+
+// suggestCorrection returns a corrected spelling of query using title words as
+// the vocabulary, or "" if every word is known.
+func (c *postgresClient) suggestCorrection(ctx context.Context, query string) (string, error) {
+	if len(spellWords(query)) == 0 {
+		return "", nil
+	}
+
+	rows, err := c.db.QueryContext(ctx, "SELECT title FROM pages WHERE 1=1"+c.wiki.filter("pages"))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	vocab, err := titleVocabulary(rows)
+	if err != nil {
+		return "", err
+	}
+
+	// Without a word index, only title words count as known
+	known := func(string) (bool, error) { return false, nil }
+	return correctQuery(query, vocab, known)
+}
+
+// GetPageHistory retrieves the revision history for a page.
+func (c *postgresClient) GetPageHistory(ctx context.Context, title string, opts HistoryOptions) ([]Revision, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	pageID := page.ID
 
 	if opts.Limit == 0 {
 		opts.Limit = 100
 	}
 
+	content := "content"
+	if opts.ExcludeContent {
+		content = "'' AS content"
+	}
+
 	query := `
 		SELECT revision_id, page_id, parent_id, timestamp, user, user_id,
-		       comment, content, size, sha1, minor, tags
+		       comment, ` + content + `, size, sha1, minor, tags
 		FROM revisions
 		WHERE page_id = $1
 	`
@@ -451,15 +753,12 @@ func (c *postgresClient) GetPageAtTime(ctx context.Context, title string, timest
 		return nil, err
 	}
 
-	// First, get the page ID
-	var pageID int64
-	err := c.db.QueryRowContext(ctx, "SELECT page_id FROM pages WHERE title = $1", title).Scan(&pageID)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
-	}
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, err
 	}
+	pageID := page.ID
 
 	const query = `
 		SELECT revision_id, page_id, parent_id, timestamp, user, user_id,
@@ -612,12 +911,18 @@ func (c *postgresClient) GetStatistics(ctx context.Context) (*Statistics, error)
 		return nil, err
 	}
 
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	stats := &Statistics{
 		PagesByNamespace: make(map[int]int64),
 	}
 
 	// Get total pages
-	err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages").Scan(&stats.TotalPages)
+	err = c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages").Scan(&stats.TotalPages)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
 	}
@@ -678,20 +983,15 @@ func (c *postgresClient) GetPageStats(ctx context.Context, title string) (*PageS
 		return nil, err
 	}
 
-	// Get page ID
-	var pageID int64
-	var pageTitle string
-	err := c.db.QueryRowContext(ctx, "SELECT page_id, title FROM pages WHERE title = $1", title).Scan(&pageID, &pageTitle)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
-	}
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, err
 	}
 
 	stats := &PageStatistics{
-		PageID: pageID,
-		Title:  pageTitle,
+		PageID: page.ID,
+		Title:  page.Title,
 	}
 
 	// Get revision count and other stats
@@ -709,7 +1009,7 @@ func (c *postgresClient) GetPageStats(ctx context.Context, title string) (*PageS
 
 	var firstEdit, lastEdit sql.NullTime
 	var avgSize sql.NullFloat64
-	err = c.db.QueryRowContext(ctx, query, pageID).Scan(
+	err = c.db.QueryRowContext(ctx, query, page.ID).Scan(
 		&stats.RevisionCount, &stats.EditorCount, &firstEdit, &lastEdit, &avgSize, &stats.MinorEdits,
 	)
 	if err != nil {
@@ -832,18 +1132,98 @@ func (c *postgresClient) ListFiles(ctx context.Context, offset, limit int) ([]Fi
 	return files, nil
 }
 
+// GetExternalLinks returns the external links of a page with their check results.
+func (c *postgresClient) GetExternalLinks(ctx context.Context, title string) ([]ExternalLink, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	pageID := page.ID
+
+	exists, err := postgresTableExists(ctx, c.db, "external_links")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []ExternalLink{}, nil
+	}
+
+	const query = `
+		SELECT source_page_id, url, is_dead, checked_at, archive_url, archive_timestamp
+		FROM external_links
+		WHERE source_page_id = $1
+		ORDER BY url
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	links, err := scanExternalLinks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		links = []ExternalLink{}
+	}
+	return links, nil
+}
+
+// GetArchiveInfo returns metadata describing the archive's source wiki.
+func (c *postgresClient) GetArchiveInfo(ctx context.Context) (*ArchiveInfo, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	exists, err := postgresTableExists(ctx, c.db, "archive_meta")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	meta, err := readArchiveMeta(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	info := newArchiveInfo(meta)
+	if c.wiki.id != "" {
+		wikis, err := listWikis(ctx, c.db, meta, true)
+		if err != nil {
+			return nil, err
+		}
+		applyWiki(info, wikis, c.wiki)
+	}
+
+	return info, nil
+}
+
 // Ping checks if the database connection is alive.
 func (c *postgresClient) Ping(ctx context.Context) error {
 	if err := c.ensureNotClosed(); err != nil {
 		return err
 	}
 
-	return c.db.PingContext(ctx)
+	return c.pool.PingContext(ctx)
 }
 
 // GetRevisionDiff computes the diff between two revisions.
-// Uses the same implementation as SQLite since the diff logic is database-agnostic.
 func (c *postgresClient) GetRevisionDiff(ctx context.Context, fromRevID, toRevID int64) (*DiffResult, error) {
+	return c.CompareRevisions(ctx, fromRevID, toRevID, DiffOptions{})
+}
+
+// CompareRevisions computes the diff between two revisions, ignoring the
+// changes opts selects.
+// Uses the same implementation as SQLite since the diff logic is database-agnostic.
+func (c *postgresClient) CompareRevisions(ctx context.Context, fromRevID, toRevID int64, opts DiffOptions) (*DiffResult, error) {
 	if err := c.ensureNotClosed(); err != nil {
 		return nil, err
 	}
@@ -864,7 +1244,7 @@ func (c *postgresClient) GetRevisionDiff(ctx context.Context, fromRevID, toRevID
 		return nil, fmt.Errorf("%w: revisions are for different pages", ErrInvalidInput)
 	}
 
-	return computeDiff(fromRev, toRev), nil
+	return computeDiff(fromRev, toRev, opts), nil
 }
 
 // GetConsecutiveDiff computes the diff from a revision to its parent.
@@ -931,34 +1311,7 @@ func (c *postgresClient) GetConsecutiveDiff(ctx context.Context, revID int64) (*
 		json.Unmarshal([]byte(tagsJSON.String), &fromRev.Tags)
 	}
 
-	return computeDiff(&fromRev, toRev), nil
-}
-
-// GetStatisticsEnhanced retrieves comprehensive enhanced wiki statistics for PostgreSQL.
-// Note: This is a stub implementation. Full PostgreSQL-specific implementation coming in future release.
-func (c *postgresClient) GetStatisticsEnhanced(ctx context.Context) (*StatisticsEnhanced, error) {
-	if err := c.ensureNotClosed(); err != nil {
-		return nil, err
-	}
-	return nil, fmt.Errorf("GetStatisticsEnhanced not yet implemented for PostgreSQL backend")
-}
-
-// GetPageStatsEnhanced retrieves enhanced statistics for a specific page for PostgreSQL.
-// Note: This is a stub implementation. Full PostgreSQL-specific implementation coming in future release.
-func (c *postgresClient) GetPageStatsEnhanced(ctx context.Context, title string) (*PageStatisticsEnhanced, error) {
-	if err := c.ensureNotClosed(); err != nil {
-		return nil, err
-	}
-	return nil, fmt.Errorf("GetPageStatsEnhanced not yet implemented for PostgreSQL backend")
-}
-
-// GetEditorActivityEnhanced retrieves enhanced activity for an editor for PostgreSQL.
-// Note: This is a stub implementation. Full PostgreSQL-specific implementation coming in future release.
-func (c *postgresClient) GetEditorActivityEnhanced(ctx context.Context, username string, start, end time.Time) (*EditorActivity, error) {
-	if err := c.ensureNotClosed(); err != nil {
-		return nil, err
-	}
-	return nil, fmt.Errorf("GetEditorActivityEnhanced not yet implemented for PostgreSQL backend")
+	return computeDiff(&fromRev, toRev, DiffOptions{}), nil
 }
 
 // Close cleanly shuts down the client and releases resources.
@@ -972,7 +1325,11 @@ func (c *postgresClient) Close() error {
 
 	c.closed = true
 
-	if err := c.db.Close(); err != nil {
+	if c.snapshot != nil {
+		return c.snapshot.Rollback()
+	}
+
+	if err := c.pool.Close(); err != nil {
 		return fmt.Errorf("%w: failed to close database: %v", ErrDatabaseError, err)
 	}
 
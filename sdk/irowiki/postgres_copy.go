@@ -0,0 +1,152 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BulkLoad copies a SQLite scrape into the archive with COPY.
+func (s *postgresStore) BulkLoad(ctx context.Context, srcPath string, opts BulkLoadOptions) (*BulkLoadResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBulkBatchSize
+	}
+
+	release, err := s.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	src, err := openBulkSource(ctx, srcPath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	plan, err := planBulkLoad(ctx, src, func(table string) (map[string]string, error) {
+		return postgresColumnTypes(ctx, s.db, table)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// COPY and the transaction around it need a single connection
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer conn.Close()
+
+	result := &BulkLoadResult{Rows: make(map[string]int64)}
+	if s.opts.PostgresDriver == PostgresDriverPgx {
+		err = conn.Raw(func(driverConn any) error {
+			return s.copyWithPgx(ctx, driverConn, src, plan, opts, result)
+		})
+	} else {
+		err = copyWithPQ(ctx, conn, src, plan, opts, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// copyWithPQ loads the planned tables in one transaction, one COPY
+// statement per batch.
+func copyWithPQ(ctx context.Context, conn *sql.Conn, src *sql.DB, plan []bulkTable, opts BulkLoadOptions, result *BulkLoadResult) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range plan {
+		n, err := streamBulkTable(ctx, src, table, opts, postgresCopyValue, func(batch [][]any) error {
+			stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table.name, table.columns...))
+			if err != nil {
+				return err
+			}
+			defer stmt.Close()
+
+			for _, row := range batch {
+				if _, err := stmt.ExecContext(ctx, row...); err != nil {
+					return err
+				}
+			}
+			_, err = stmt.ExecContext(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		result.Rows[table.name] = n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// postgresColumnTypes returns the writable columns of a table and their
+// types, or none if the table doesn't exist.
+func postgresColumnTypes(ctx context.Context, db *sql.DB, table string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT attname, format_type(atttypid, atttypmod)
+		FROM pg_attribute
+		WHERE attrelid = to_regclass('%s') AND attnum > 0 AND NOT attisdropped AND attgenerated = ''
+	`, table))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		columns[name] = typ
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return columns, nil
+}
+
+// postgresCopyValue converts a value read from SQLite for a PostgreSQL
+// column of type typ: SQLite keeps booleans as integers, timestamps as text
+// and text in blobs.
+func postgresCopyValue(v any, typ string) any {
+	switch v := v.(type) {
+	case int64:
+		if typ == "boolean" {
+			return v != 0
+		}
+	case []byte:
+		if typ != "bytea" {
+			return string(v)
+		}
+	case string:
+		if strings.HasPrefix(typ, "timestamp") {
+			for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t
+				}
+			}
+		}
+	}
+	return v
+}
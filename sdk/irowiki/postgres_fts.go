@@ -0,0 +1,224 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// postgresFTSConfig is the text search configuration of PostgreSQL
+// full-text search. Its English stemming matches the porter tokenizer
+// SQLite archives use by default.
+const postgresFTSConfig = "english"
+
+// postgresHeadlineOptions makes ts_headline snippets look like those of
+// FTS5's snippet function.
+const postgresHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=10, MaxFragments=1, FragmentDelimiter=..."
+
+// postgresRankWeights are the ts_rank weights of the D, C, B and A labels:
+// titles (A) weigh 10 times as much as content (B), as in the SQLite
+// backend's bm25 ranking.
+const postgresRankWeights = "{0.1, 0.1, 0.1, 1.0}"
+
+// SearchFullText searches the titles and latest content of pages with
+// tsvector and tsquery, computed from the pages as they are searched.
+// The query takes the FTS5 syntax of the SQLite backend: terms, "quoted
+// phrases", prefixes (term*), AND, OR, NOT and title: or content: column
+// filters. Results are ranked by ts_rank, title matches first, and their
+// snippets come from ts_headline. Proximity searches return ErrNotSupported.
+func (c *postgresClient) SearchFullText(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if opts.Proximity != nil || nearInfixPattern.MatchString(query) || strings.Contains(query, "NEAR(") {
+		return nil, fmt.Errorf("%w: proximity search is not implemented for PostgreSQL", ErrNotSupported)
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	query = nfc(query)
+
+	// Validate and apply defaults
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
+	}
+	opts.SetDefaults()
+
+	tsquery := postgresTSQuery(query)
+	if tsquery == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	args := []interface{}{tsquery}
+	filters, args := searchFilters(opts, c.wiki, args, func(n int) string { return fmt.Sprintf("$%d", n) })
+
+	matches := `
+		SELECT p.page_id, p.namespace, p.title, r.timestamp, r.content,
+		       ts_rank('` + postgresRankWeights + `', d.document, q.query) AS relevance, q.query
+		FROM pages p
+		JOIN LATERAL (
+			SELECT timestamp, content
+			FROM revisions
+			WHERE page_id = p.page_id
+			ORDER BY timestamp DESC
+			LIMIT 1
+		) r ON true
+		CROSS JOIN LATERAL (
+			SELECT setweight(to_tsvector('` + postgresFTSConfig + `', REPLACE(p.title, '_', ' ')), 'A') ||
+			       setweight(to_tsvector('` + postgresFTSConfig + `', COALESCE(r.content, '')), 'B') AS document
+		) d
+		CROSS JOIN (SELECT to_tsquery('` + postgresFTSConfig + `', $1) AS query) q
+		WHERE d.document @@ q.query` + filters
+
+	if opts.MinScore != 0 {
+		args = append(args, opts.MinScore)
+		matches += fmt.Sprintf(" AND ts_rank('%s', d.document, q.query) >= $%d", postgresRankWeights, len(args))
+	}
+
+	matches += fmt.Sprintf(" ORDER BY relevance DESC, p.page_id LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, opts.Limit, opts.Offset)
+
+	// Snippets are made for the returned page only
+	sqlQuery := `
+		SELECT m.page_id, m.namespace, m.title, m.timestamp,
+		       ts_headline('` + postgresFTSConfig + `', COALESCE(m.content, ''), m.query, '` + postgresHeadlineOptions + `'),
+		       m.relevance
+		FROM (` + matches + `) m
+		ORDER BY m.relevance DESC, m.page_id
+	`
+
+	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		var timestamp sql.NullTime
+
+		err := rows.Scan(&result.PageID, &result.Namespace, &result.Title, &timestamp, &result.Snippet, &result.Relevance)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		if timestamp.Valid {
+			result.Timestamp = timestamp.Time
+		}
+		result.MatchType = "fulltext"
+
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	return results, nil
+}
+
+// postgresTSQuery translates an FTS5 query to to_tsquery syntax. Terms and
+// phrases are quoted, so to_tsquery stems them and joins a phrase's words
+// with <->; adjacent terms are ANDed and dangling operators dropped. title:
+// and content: restrict a term to the title (A) or content (B) weight.
+// Returns "" for a query without terms.
+func postgresTSQuery(query string) string {
+	var out strings.Builder
+	terms := 0
+	// operand reports whether the output ends with a term or a closing
+	// parenthesis; pending is the operator before the next one
+	operand, pending := false, ""
+	join := func() {
+		switch {
+		case operand && pending == "NOT":
+			out.WriteString(" & !")
+		case operand && pending == "OR":
+			out.WriteString(" | ")
+		case operand:
+			out.WriteString(" & ")
+		case pending == "NOT":
+			out.WriteString("!")
+		}
+		pending = ""
+	}
+	term := func(text, suffix string) {
+		join()
+		out.WriteString("'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(text) + "'" + suffix)
+		operand = true
+		terms++
+	}
+
+	for i := 0; i < len(query); {
+		switch ch := query[i]; {
+		case unicode.IsSpace(rune(ch)):
+			i++
+		case ch == '(':
+			join()
+			out.WriteString("(")
+			operand = false
+			i++
+		case ch == ')':
+			out.WriteString(")")
+			operand, pending = true, ""
+			i++
+		default:
+			// A word or a phrase, optionally after a column filter
+			j := i
+			for j < len(query) && query[j] != '"' && query[j] != '(' && query[j] != ')' && !unicode.IsSpace(rune(query[j])) {
+				j++
+			}
+			word := query[i:j]
+			weight := ""
+			if column, rest, ok := strings.Cut(word, ":"); ok {
+				switch strings.ToLower(column) {
+				case "title":
+					weight, word = "A", rest
+				case "content":
+					weight, word = "B", rest
+				}
+			}
+			if word == "" && j < len(query) && query[j] == '"' {
+				end := strings.IndexByte(query[j+1:], '"')
+				if end < 0 {
+					end = len(query) - j - 1
+				}
+				if phrase := strings.TrimSpace(query[j+1 : j+1+end]); phrase != "" {
+					term(phrase, weightSuffix(weight, false))
+				}
+				i = min(j+end+2, len(query))
+				continue
+			}
+			i = j
+
+			switch word {
+			case "AND", "OR", "NOT":
+				pending = word
+			default:
+				prefix := strings.HasSuffix(word, "*")
+				if word = strings.TrimRight(word, "*"); word != "" {
+					term(word, weightSuffix(weight, prefix))
+				}
+			}
+		}
+	}
+	if terms == 0 {
+		return ""
+	}
+	return out.String()
+}
+
+// weightSuffix returns the to_tsquery suffix of a term restricted to a
+// weight and, for prefix terms, matching any lexeme starting with it.
+func weightSuffix(weight string, prefix bool) string {
+	switch {
+	case prefix:
+		return ":*" + weight
+	case weight != "":
+		return ":" + weight
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgreSQL drivers, for ConnectionOptions.PostgresDriver.
+const (
+	// PostgresDriverPQ uses github.com/lib/pq.
+	PostgresDriverPQ = "pq"
+
+	// PostgresDriverPgx uses github.com/jackc/pgx. Each connection caches
+	// its prepared statements, so repeated queries skip parsing and
+	// planning, and a canceled context cancels the query on the server.
+	PostgresDriverPgx = "pgx"
+)
+
+// pgxCancelDeadline is how long pgx waits for the server to honor a cancel
+// request before giving up on the connection.
+const pgxCancelDeadline = 5 * time.Second
+
+// newPgxConnector returns a pgx connector for dsn. When a query's context
+// is canceled, pgx asks the server to cancel the query, instead of only
+// abandoning the connection as it does by default.
+func newPgxConnector(dsn string) (driver.Connector, error) {
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	config.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{Conn: conn, DeadlineDelay: pgxCancelDeadline}
+	}
+	return stdlib.GetConnector(*config), nil
+}
+
+// copyWithPgx loads the planned tables in one transaction on driverConn,
+// one CopyFrom per batch.
+func (s *postgresStore) copyWithPgx(ctx context.Context, driverConn any, src *sql.DB, plan []bulkTable, opts BulkLoadOptions, result *BulkLoadResult) error {
+	if e, ok := driverConn.(*explainConn); ok {
+		driverConn = e.Conn
+	}
+	if q, ok := driverConn.(*qualifiedConn); ok {
+		driverConn = q.Conn
+	}
+	conn := driverConn.(*stdlib.Conn).Conn()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, table := range plan {
+		name := pgx.Identifier{s.opts.TablePrefix + table.name}
+		if s.opts.Schema != "" {
+			name = pgx.Identifier{s.opts.Schema, s.opts.TablePrefix + table.name}
+		}
+		n, err := streamBulkTable(ctx, src, table, opts, postgresCopyValue, func(batch [][]any) error {
+			_, err := tx.CopyFrom(ctx, name, table.columns, pgx.CopyFromRows(batch))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		result.Rows[table.name] = n
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
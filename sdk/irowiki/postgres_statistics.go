@@ -0,0 +1,881 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// postgresLatestRevisions selects the latest revision of every page.
+const postgresLatestRevisions = `
+	SELECT DISTINCT ON (page_id) page_id, size
+	FROM revisions
+	ORDER BY page_id, timestamp DESC
+`
+
+// GetStatisticsEnhanced retrieves comprehensive archive statistics with additional details.
+func (c *postgresClient) GetStatisticsEnhanced(ctx context.Context) (*StatisticsEnhanced, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	stats := &StatisticsEnhanced{
+		PagesByNamespace: make(map[int]int64),
+		EditsByMonth:     make(map[string]int64),
+	}
+
+	// Get basic counts
+	err = c.getBasicCounts(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basic counts: %w", err)
+	}
+
+	// Get temporal statistics
+	err = c.getTemporalStats(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get temporal stats: %w", err)
+	}
+
+	// Get size statistics
+	err = c.getSizeStats(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get size stats: %w", err)
+	}
+
+	// Get namespace distribution
+	err = c.getNamespaceStats(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace stats: %w", err)
+	}
+
+	// Get top editors
+	err = c.getTopEditors(ctx, stats, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top editors: %w", err)
+	}
+
+	// Get most edited pages
+	err = c.getMostEditedPages(ctx, stats, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most edited pages: %w", err)
+	}
+
+	// Get edit distribution by month
+	err = c.getEditsByMonth(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edits by month: %w", err)
+	}
+
+	return stats, nil
+}
+
+// getBasicCounts retrieves basic count statistics.
+func (c *postgresClient) getBasicCounts(ctx context.Context, stats *StatisticsEnhanced) error {
+	const query = `
+		SELECT
+			(SELECT COUNT(*) FROM pages) as total_pages,
+			(SELECT COUNT(*) FROM revisions) as total_revisions,
+			(SELECT COUNT(*) FROM files) as total_files,
+			(SELECT COUNT(DISTINCT r.user) FROM revisions r WHERE r.user IS NOT NULL) as total_editors
+	`
+
+	return c.db.QueryRowContext(ctx, query).Scan(
+		&stats.TotalPages,
+		&stats.TotalRevisions,
+		&stats.TotalFiles,
+		&stats.TotalEditors,
+	)
+}
+
+// getTemporalStats retrieves temporal statistics.
+func (c *postgresClient) getTemporalStats(ctx context.Context, stats *StatisticsEnhanced) error {
+	const query = `
+		SELECT MIN(timestamp), MAX(timestamp)
+		FROM revisions
+		WHERE timestamp IS NOT NULL
+	`
+
+	var firstEdit, lastEdit sql.NullTime
+	if err := c.db.QueryRowContext(ctx, query).Scan(&firstEdit, &lastEdit); err != nil {
+		return err
+	}
+	stats.FirstEdit = firstEdit.Time
+	stats.LastEdit = lastEdit.Time
+
+	// Calculate active editors (last 30 days from last edit)
+	if !stats.LastEdit.IsZero() {
+		const activeQuery = `
+			SELECT COUNT(DISTINCT r.user)
+			FROM revisions r
+			WHERE r.timestamp >= $1 AND r.user IS NOT NULL
+		`
+		cutoff := stats.LastEdit.Add(-30 * 24 * time.Hour)
+		if err := c.db.QueryRowContext(ctx, activeQuery, cutoff).Scan(&stats.ActiveEditors); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getSizeStats retrieves size statistics of the latest revisions.
+func (c *postgresClient) getSizeStats(ctx context.Context, stats *StatisticsEnhanced) error {
+	// percentile_disc picks the lower of the two middle sizes, as the
+	// SQLite backend does
+	const query = `
+		SELECT
+			COALESCE(SUM(size), 0) as total_size,
+			COALESCE(AVG(size), 0) as avg_size,
+			COALESCE(percentile_disc(0.5) WITHIN GROUP (ORDER BY size), 0) as median_size
+		FROM (` + postgresLatestRevisions + `) latest
+	`
+
+	var avgSize float64
+	err := c.db.QueryRowContext(ctx, query).Scan(&stats.TotalContentSize, &avgSize, &stats.MedianPageSize)
+	if err != nil {
+		return err
+	}
+	stats.AveragePageSize = int(avgSize)
+
+	// Get largest and smallest pages
+	return c.getExtremesPages(ctx, stats)
+}
+
+// getExtremesPages retrieves largest and smallest pages.
+func (c *postgresClient) getExtremesPages(ctx context.Context, stats *StatisticsEnhanced) error {
+	// Ranked by window functions in both directions in one pass; the
+	// smallest page excludes empty pages
+	const query = `
+		SELECT page_id, title, namespace, size, largest, smallest
+		FROM (
+			SELECT p.page_id, p.title, p.namespace, latest.size,
+			       ROW_NUMBER() OVER (ORDER BY latest.size DESC) as largest,
+			       CASE WHEN latest.size > 0 THEN ROW_NUMBER() OVER (PARTITION BY latest.size > 0 ORDER BY latest.size) END as smallest
+			FROM pages p
+			INNER JOIN (` + postgresLatestRevisions + `) latest ON latest.page_id = p.page_id
+		) ranked
+		WHERE largest = 1 OR smallest = 1
+	`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var page PageInfoStat
+		var largest int64
+		var smallest sql.NullInt64
+		if err := rows.Scan(&page.ID, &page.Title, &page.Namespace, &page.Size, &largest, &smallest); err != nil {
+			return err
+		}
+		if largest == 1 {
+			largestPage := page
+			stats.LargestPage = &largestPage
+		}
+		if smallest.Valid && smallest.Int64 == 1 {
+			smallestPage := page
+			stats.SmallestPage = &smallestPage
+		}
+	}
+
+	return rows.Err()
+}
+
+// getNamespaceStats retrieves namespace distribution.
+func (c *postgresClient) getNamespaceStats(ctx context.Context, stats *StatisticsEnhanced) error {
+	const query = `
+		SELECT namespace, COUNT(*) as count
+		FROM pages
+		GROUP BY namespace
+		ORDER BY namespace
+	`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ns int
+		var count int64
+		if err := rows.Scan(&ns, &count); err != nil {
+			return err
+		}
+		stats.PagesByNamespace[ns] = count
+	}
+
+	return rows.Err()
+}
+
+// getTopEditors retrieves top N editors by edit count.
+func (c *postgresClient) getTopEditors(ctx context.Context, stats *StatisticsEnhanced, n int) error {
+	query := `
+		SELECT
+			r.user,
+			COUNT(*) as edit_count,
+			MIN(r.timestamp) as first_edit,
+			MAX(r.timestamp) as last_edit,
+			SUM(CASE WHEN r.minor THEN 1 ELSE 0 END) as minor_edits,
+			COUNT(DISTINCT r.page_id) as pages_edited,
+			` + postgresBotCondition("r.user", c.opts.BotUsers) + ` as bot
+		FROM revisions r
+		WHERE r.user IS NOT NULL
+		GROUP BY r.user
+		ORDER BY edit_count DESC
+		LIMIT $1
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, n)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stats.TopEditors = make([]EditorStat, 0, n)
+	for rows.Next() {
+		var editor EditorStat
+		var firstEdit, lastEdit sql.NullTime
+
+		err := rows.Scan(
+			&editor.Username,
+			&editor.EditCount,
+			&firstEdit,
+			&lastEdit,
+			&editor.MinorEdits,
+			&editor.PagesEdited,
+			&editor.Bot,
+		)
+		if err != nil {
+			return err
+		}
+		editor.FirstEdit = firstEdit.Time
+		editor.LastEdit = lastEdit.Time
+
+		stats.TopEditors = append(stats.TopEditors, editor)
+	}
+
+	return rows.Err()
+}
+
+// getMostEditedPages retrieves top N most edited pages.
+func (c *postgresClient) getMostEditedPages(ctx context.Context, stats *StatisticsEnhanced, n int) error {
+	const query = `
+		SELECT
+			p.page_id,
+			p.title,
+			COUNT(*) as revision_count,
+			COUNT(DISTINCT r.user) as editor_count
+		FROM pages p
+		INNER JOIN revisions r ON p.page_id = r.page_id
+		WHERE r.user IS NOT NULL
+		GROUP BY p.page_id, p.title
+		ORDER BY revision_count DESC
+		LIMIT $1
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, n)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stats.MostEditedPages = make([]PageStat, 0, n)
+	for rows.Next() {
+		var pageStat PageStat
+		err := rows.Scan(
+			&pageStat.PageID,
+			&pageStat.PageTitle,
+			&pageStat.RevisionCount,
+			&pageStat.EditorCount,
+		)
+		if err != nil {
+			return err
+		}
+		stats.MostEditedPages = append(stats.MostEditedPages, pageStat)
+	}
+
+	return rows.Err()
+}
+
+// getEditsByMonth retrieves edit distribution by month.
+func (c *postgresClient) getEditsByMonth(ctx context.Context, stats *StatisticsEnhanced) error {
+	const query = `
+		SELECT to_char(date_trunc('month', timestamp), 'YYYY-MM') as month, COUNT(*) as edit_count
+		FROM revisions
+		WHERE timestamp IS NOT NULL
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var month string
+		var count int64
+		if err := rows.Scan(&month, &count); err != nil {
+			return err
+		}
+		stats.EditsByMonth[month] = count
+	}
+
+	return rows.Err()
+}
+
+// GetPageStatsEnhanced retrieves enhanced statistics for a specific page.
+func (c *postgresClient) GetPageStatsEnhanced(ctx context.Context, title string) (*PageStatisticsEnhanced, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PageStatisticsEnhanced{
+		PageID:        page.ID,
+		Title:         page.Title,
+		PageNamespace: page.Namespace,
+	}
+
+	// Get basic revision statistics
+	err = c.getPageRevisionStats(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision stats: %w", err)
+	}
+
+	// Get contributor statistics
+	err = c.getPageContributorStats(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contributor stats: %w", err)
+	}
+
+	// Get size statistics
+	err = c.getPageSizeStats(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get size stats: %w", err)
+	}
+
+	// Get quality metrics
+	err = c.getPageQualityMetrics(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quality metrics: %w", err)
+	}
+
+	// Get activity patterns
+	err = c.getPageActivityPatterns(ctx, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity patterns: %w", err)
+	}
+
+	return stats, nil
+}
+
+// getPageRevisionStats retrieves basic revision statistics for a page.
+func (c *postgresClient) getPageRevisionStats(ctx context.Context, stats *PageStatisticsEnhanced) error {
+	const query = `
+		SELECT COUNT(*), MIN(timestamp), MAX(timestamp)
+		FROM revisions
+		WHERE page_id = $1
+	`
+
+	var firstEdit, lastEdit sql.NullTime
+	if err := c.db.QueryRowContext(ctx, query, stats.PageID).Scan(&stats.RevisionCount, &firstEdit, &lastEdit); err != nil {
+		return err
+	}
+	stats.FirstEdit = firstEdit.Time
+	stats.LastEdit = lastEdit.Time
+
+	// Calculate average time between edits
+	if stats.RevisionCount > 1 && !stats.FirstEdit.IsZero() && !stats.LastEdit.IsZero() {
+		totalTime := stats.LastEdit.Sub(stats.FirstEdit)
+		stats.AvgTimeBetween = totalTime / time.Duration(stats.RevisionCount-1)
+	}
+
+	return nil
+}
+
+// getPageContributorStats retrieves contributor statistics for a page.
+func (c *postgresClient) getPageContributorStats(ctx context.Context, stats *PageStatisticsEnhanced) error {
+	// Count editors
+	const countQuery = `
+		SELECT
+			COUNT(DISTINCT r.user) as total_editors,
+			COUNT(DISTINCT r.user) FILTER (WHERE r.user_id IS NULL) as anon_editors,
+			COUNT(DISTINCT r.user) FILTER (WHERE r.user_id IS NOT NULL) as reg_editors
+		FROM revisions r
+		WHERE r.page_id = $1 AND r.user IS NOT NULL
+	`
+
+	err := c.db.QueryRowContext(ctx, countQuery, stats.PageID).Scan(
+		&stats.EditorCount,
+		&stats.AnonEditors,
+		&stats.RegisteredEditors,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Get top contributors
+	const topContribQuery = `
+		SELECT
+			r.user,
+			COUNT(*) as edit_count,
+			MIN(r.timestamp) as first_edit,
+			MAX(r.timestamp) as last_edit,
+			SUM(CASE WHEN r.minor THEN 1 ELSE 0 END) as minor_edits
+		FROM revisions r
+		WHERE r.page_id = $1 AND r.user IS NOT NULL
+		GROUP BY r.user
+		ORDER BY edit_count DESC
+		LIMIT 5
+	`
+
+	rows, err := c.db.QueryContext(ctx, topContribQuery, stats.PageID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stats.TopContributors = make([]ContributorStat, 0, 5)
+	for rows.Next() {
+		var contrib ContributorStat
+		var firstEdit, lastEdit sql.NullTime
+
+		err := rows.Scan(
+			&contrib.Username,
+			&contrib.EditCount,
+			&firstEdit,
+			&lastEdit,
+			&contrib.MinorEdits,
+		)
+		if err != nil {
+			return err
+		}
+		contrib.FirstEdit = firstEdit.Time
+		contrib.LastEdit = lastEdit.Time
+
+		contrib.Percentage = float64(contrib.EditCount) / float64(stats.RevisionCount) * 100
+		stats.TopContributors = append(stats.TopContributors, contrib)
+	}
+
+	return rows.Err()
+}
+
+// getPageSizeStats retrieves size statistics for a page.
+func (c *postgresClient) getPageSizeStats(ctx context.Context, stats *PageStatisticsEnhanced) error {
+	// The original and current sizes come from window functions over the
+	// same scan
+	const query = `
+		SELECT
+			MIN(size), MAX(size), AVG(size),
+			MIN(original), MIN(current)
+		FROM (
+			SELECT size,
+			       FIRST_VALUE(size) OVER (ORDER BY timestamp ASC) as original,
+			       FIRST_VALUE(size) OVER (ORDER BY timestamp DESC) as current
+			FROM revisions
+			WHERE page_id = $1
+		) sizes
+	`
+
+	var minSize, maxSize, original, current sql.NullInt64
+	var avgSize sql.NullFloat64
+	err := c.db.QueryRowContext(ctx, query, stats.PageID).Scan(&minSize, &maxSize, &avgSize, &original, &current)
+	if err != nil {
+		return err
+	}
+
+	stats.MinSize = int(minSize.Int64)
+	stats.MaxSize = int(maxSize.Int64)
+	stats.AvgSize = int(avgSize.Float64)
+	stats.OriginalSize = int(original.Int64)
+	stats.CurrentSize = int(current.Int64)
+
+	return nil
+}
+
+// getPageQualityMetrics calculates quality metrics for a page.
+func (c *postgresClient) getPageQualityMetrics(ctx context.Context, stats *PageStatisticsEnhanced) error {
+	const query = `
+		SELECT
+			COALESCE(AVG(CASE WHEN minor THEN 1.0 ELSE 0.0 END) * 100, 0) as minor_percent,
+			COALESCE(AVG(LENGTH(comment)), 0) as avg_comment_len
+		FROM revisions
+		WHERE page_id = $1
+	`
+
+	err := c.db.QueryRowContext(ctx, query, stats.PageID).Scan(
+		&stats.MinorEditPercent,
+		&stats.AvgCommentLength,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Classify edits (ClassifyRevisions) to count reverts and noise edits
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	revs, err := loadRevisionsForClassification(ctx, c.db, stats.PageID, placeholder)
+	if err != nil {
+		return err
+	}
+	stats.EditClasses = make(map[string]int)
+	for _, class := range classifyRevisions(revs) {
+		stats.EditClasses[class.Class]++
+	}
+	stats.RevertCount = stats.EditClasses[EditRevert]
+	stats.CosmeticEdits = stats.EditClasses[EditFormatting]
+
+	// Calculate stability score, leaving out noise edits
+	stats.StabilityScore = calculateStabilityScore(
+		stats.AvgTimeBetween,
+		stats.RevertCount,
+		int(stats.RevisionCount)-stats.CosmeticEdits,
+		stats.MinorEditPercent,
+	)
+
+	return nil
+}
+
+// getPageActivityPatterns identifies activity patterns for a page.
+func (c *postgresClient) getPageActivityPatterns(ctx context.Context, stats *PageStatisticsEnhanced) error {
+	// Edit frequency by month
+	const freqQuery = `
+		SELECT to_char(date_trunc('month', timestamp), 'YYYY-MM') as month, COUNT(*) as count
+		FROM revisions
+		WHERE page_id = $1 AND timestamp IS NOT NULL
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := c.db.QueryContext(ctx, freqQuery, stats.PageID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stats.EditFrequency = make(map[string]int)
+	maxCount := 0
+	var busiestMonth string
+
+	for rows.Next() {
+		var month string
+		var count int
+		if err := rows.Scan(&month, &count); err != nil {
+			return err
+		}
+		stats.EditFrequency[month] = count
+
+		if count > maxCount {
+			maxCount = count
+			busiestMonth = month
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	stats.BusiestMonth = busiestMonth
+
+	// Find longest gap between edits
+	const gapQuery = `
+		SELECT MAX(EXTRACT(EPOCH FROM timestamp - prev_timestamp))
+		FROM (
+			SELECT timestamp, LAG(timestamp) OVER (ORDER BY timestamp) as prev_timestamp
+			FROM revisions
+			WHERE page_id = $1
+		) gaps
+		WHERE prev_timestamp IS NOT NULL
+	`
+
+	var gapSeconds sql.NullFloat64
+	if err := c.db.QueryRowContext(ctx, gapQuery, stats.PageID).Scan(&gapSeconds); err != nil {
+		return err
+	}
+	if gapSeconds.Valid {
+		stats.LongestGap = time.Duration(gapSeconds.Float64 * float64(time.Second))
+	}
+
+	return nil
+}
+
+// GetEditorActivityEnhanced retrieves enhanced activity for an editor.
+func (c *postgresClient) GetEditorActivityEnhanced(ctx context.Context, username string, start, end time.Time) (*EditorActivity, error) {
+	return c.GetEditorActivityWithOptions(ctx, username, AllEditorActivity(start, end))
+}
+
+// GetEditorActivityWithOptions retrieves the requested sections of an
+// editor's activity.
+func (c *postgresClient) GetEditorActivityWithOptions(ctx context.Context, username string, opts EditorActivityOptions) (*EditorActivity, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if opts.TopPages < 0 {
+		return nil, fmt.Errorf("%w: negative top pages", ErrInvalidInput)
+	}
+	start, end := opts.Start, opts.End
+
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	activity := &EditorActivity{
+		Username: username,
+	}
+
+	// Get basic statistics
+	err = c.getEditorBasicStats(ctx, activity, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basic stats: %w", err)
+	}
+
+	// Get the first page edited
+	if opts.FirstEditedPage {
+		err = c.getEditorFirstPage(ctx, activity, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get first edited page: %w", err)
+		}
+	}
+
+	// Get content statistics
+	if opts.Content {
+		err = c.getEditorContentStats(ctx, activity, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get content stats: %w", err)
+		}
+	}
+
+	// Get namespace distribution
+	if opts.Namespaces {
+		err = c.getEditorNamespaces(ctx, activity, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespaces: %w", err)
+		}
+	}
+
+	// Get activity patterns
+	if opts.Patterns {
+		placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+		err = getEditorActivityPatterns(ctx, c.db, c.wiki, activity, start, end, postgresWeekday, postgresHour, postgresMonth, postgresBotCondition("r.user", c.opts.BotUsers), placeholder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get activity patterns: %w", err)
+		}
+	}
+
+	// Get top pages
+	if opts.TopPages > 0 {
+		err = c.getEditorTopPages(ctx, activity, start, end, opts.TopPages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get top pages: %w", err)
+		}
+	}
+
+	return activity, nil
+}
+
+// editorRange appends the conditions limiting revisions aliased as alias to
+// the time range, with their arguments, to query and args.
+func editorRange(query string, args []interface{}, alias string, start, end time.Time) (string, []interface{}) {
+	if !start.IsZero() {
+		args = append(args, start)
+		query += fmt.Sprintf(" AND %s.timestamp >= $%d", alias, len(args))
+	}
+	if !end.IsZero() {
+		args = append(args, end)
+		query += fmt.Sprintf(" AND %s.timestamp <= $%d", alias, len(args))
+	}
+	return query, args
+}
+
+// getEditorBasicStats retrieves basic editor statistics.
+func (c *postgresClient) getEditorBasicStats(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
+	query, args := editorRange(`
+		SELECT
+			COUNT(*) as total_edits,
+			MIN(r.timestamp) as first_edit,
+			MAX(r.timestamp) as last_edit,
+			COUNT(DISTINCT CAST(r.timestamp AS DATE)) as active_days,
+			r.user_id
+		FROM revisions r
+		WHERE r.user = $1
+	`, []interface{}{activity.Username}, "r", start, end)
+	query += " GROUP BY r.user_id"
+
+	var userID sql.NullInt64
+	var firstEdit, lastEdit sql.NullTime
+
+	err := c.db.QueryRowContext(ctx, query, args...).Scan(
+		&activity.TotalEdits,
+		&firstEdit,
+		&lastEdit,
+		&activity.ActiveDays,
+		&userID,
+	)
+
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if userID.Valid {
+		id := userID.Int64
+		activity.UserID = &id
+		activity.IsAnonymous = false
+	} else {
+		activity.IsAnonymous = true
+	}
+	activity.FirstEdit = firstEdit.Time
+	activity.LastEdit = lastEdit.Time
+
+	return nil
+}
+
+// getEditorContentStats retrieves content contribution statistics.
+func (c *postgresClient) getEditorContentStats(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
+	query, args := editorRange(`
+		SELECT
+			COUNT(DISTINCT r.page_id) as pages_edited,
+			SUM(CASE WHEN r.minor THEN 1 ELSE 0 END) as minor_edits,
+			AVG(r.size - COALESCE(pr.size, 0)) as avg_size_delta,
+			AVG(CASE WHEN r.comment IS NOT NULL AND r.comment != '' THEN 1.0 ELSE 0.0 END) as comment_rate
+		FROM revisions r
+		LEFT JOIN revisions pr ON pr.revision_id = r.parent_id
+		WHERE r.user = $1
+	`, []interface{}{activity.Username}, "r", start, end)
+
+	var minorEdits sql.NullInt64
+	var sizeDelta, commentRate sql.NullFloat64
+	err := c.db.QueryRowContext(ctx, query, args...).Scan(
+		&activity.PagesEdited,
+		&minorEdits,
+		&sizeDelta,
+		&commentRate,
+	)
+	activity.MinorEdits = int(minorEdits.Int64)
+	activity.AvgSizeDelta = sizeDelta.Float64
+	activity.CommentRate = commentRate.Float64
+
+	return err
+}
+
+// getEditorFirstPage retrieves the title of the page of the editor's first
+// edit.
+func (c *postgresClient) getEditorFirstPage(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
+	query, args := editorRange(`
+		SELECT p.title
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.user = $1
+	`+c.wiki.filter("p"), []interface{}{activity.Username}, "r", start, end)
+	query += " ORDER BY r.timestamp, r.revision_id LIMIT 1"
+
+	err := c.db.QueryRowContext(ctx, query, args...).Scan(&activity.FirstEditedPage)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// getEditorNamespaces counts the editor's edits in each namespace.
+func (c *postgresClient) getEditorNamespaces(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
+	query, args := editorRange(`
+		SELECT p.namespace, COUNT(*) as count
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.user = $1
+	`+c.wiki.filter("p"), []interface{}{activity.Username}, "r", start, end)
+	query += " GROUP BY p.namespace"
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	activity.EditsByNamespace = make(map[int]int)
+	for rows.Next() {
+		var namespace, count int
+		if err := rows.Scan(&namespace, &count); err != nil {
+			return err
+		}
+		activity.EditsByNamespace[namespace] = count
+	}
+
+	return rows.Err()
+}
+
+// getEditorTopPages retrieves top pages edited by the editor.
+func (c *postgresClient) getEditorTopPages(ctx context.Context, activity *EditorActivity, start, end time.Time, n int) error {
+	query, args := editorRange(`
+		SELECT
+			r.page_id,
+			p.title,
+			COUNT(*) as edit_count,
+			MIN(r.timestamp) as first_edit,
+			MAX(r.timestamp) as last_edit
+		FROM revisions r
+		INNER JOIN pages p ON r.page_id = p.page_id
+		WHERE r.user = $1
+	`, []interface{}{activity.Username}, "r", start, end)
+
+	args = append(args, n)
+	query += fmt.Sprintf(`
+		GROUP BY r.page_id, p.title
+		ORDER BY edit_count DESC
+		LIMIT $%d
+	`, len(args))
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	activity.TopPages = make([]PageEditStat, 0, n)
+	for rows.Next() {
+		var pageStat PageEditStat
+		var firstEdit, lastEdit sql.NullTime
+
+		err := rows.Scan(
+			&pageStat.PageID,
+			&pageStat.PageTitle,
+			&pageStat.EditCount,
+			&firstEdit,
+			&lastEdit,
+		)
+		if err != nil {
+			return err
+		}
+		pageStat.FirstEdit = firstEdit.Time
+		pageStat.LastEdit = lastEdit.Time
+
+		activity.TopPages = append(activity.TopPages, pageStat)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,70 @@
+package irowiki_test
+
+import (
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// openPostgresArchive copies the test database into a schema of the
+// PostgreSQL database testutil.PostgresDSNEnv names and opens a client on
+// it, skipping the test if the variable is unset.
+func openPostgresArchive(t *testing.T, tdb *testutil.TestDB) irowiki.Client {
+	t.Helper()
+
+	pdb := testutil.SetupPostgresTestDB(t, tdb)
+	opts := irowiki.DefaultPostgresOptions()
+	opts.Schema = pdb.Schema
+	client, err := irowiki.OpenPostgresWithOptions(pdb.DSN, opts)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// TestPostgresClient_GetStatisticsEnhanced runs testGetStatisticsEnhanced on PostgreSQL
+func TestPostgresClient_GetStatisticsEnhanced(t *testing.T) {
+	testGetStatisticsEnhanced(t, openPostgresArchive)
+}
+
+// TestPostgresClient_GetPageStatsEnhanced runs testGetPageStatsEnhanced on PostgreSQL
+func TestPostgresClient_GetPageStatsEnhanced(t *testing.T) {
+	testGetPageStatsEnhanced(t, openPostgresArchive)
+}
+
+// TestPostgresClient_GetPageStatsEnhanced_CosmeticEdits runs testGetPageStatsEnhancedCosmeticEdits on PostgreSQL
+func TestPostgresClient_GetPageStatsEnhanced_CosmeticEdits(t *testing.T) {
+	testGetPageStatsEnhancedCosmeticEdits(t, openPostgresArchive)
+}
+
+// TestPostgresClient_GetPageStatsEnhanced_NotFound runs testGetPageStatsEnhancedNotFound on PostgreSQL
+func TestPostgresClient_GetPageStatsEnhanced_NotFound(t *testing.T) {
+	testGetPageStatsEnhancedNotFound(t, openPostgresArchive)
+}
+
+// TestPostgresClient_GetEditorActivityEnhanced runs testGetEditorActivityEnhanced on PostgreSQL
+func TestPostgresClient_GetEditorActivityEnhanced(t *testing.T) {
+	testGetEditorActivityEnhanced(t, openPostgresArchive)
+}
+
+// TestPostgresClient_GetEditorActivityEnhanced_NotFound runs testGetEditorActivityEnhancedNotFound on PostgreSQL
+func TestPostgresClient_GetEditorActivityEnhanced_NotFound(t *testing.T) {
+	testGetEditorActivityEnhancedNotFound(t, openPostgresArchive)
+}
+
+// TestPostgresClient_GetEditorActivityEnhanced_Patterns runs testGetEditorActivityEnhancedPatterns on PostgreSQL
+func TestPostgresClient_GetEditorActivityEnhanced_Patterns(t *testing.T) {
+	testGetEditorActivityEnhancedPatterns(t, openPostgresArchive)
+}
+
+// TestPostgresClient_GetEditorActivityWithOptions runs testGetEditorActivityWithOptions on PostgreSQL
+func TestPostgresClient_GetEditorActivityWithOptions(t *testing.T) {
+	testGetEditorActivityWithOptions(t, openPostgresArchive)
+}
+
+// TestPostgresClient_StatisticsEnhanced_DateRangeFiltering runs testStatisticsEnhancedDateRangeFiltering on PostgreSQL
+func TestPostgresClient_StatisticsEnhanced_DateRangeFiltering(t *testing.T) {
+	testStatisticsEnhancedDateRangeFiltering(t, openPostgresArchive)
+}
@@ -0,0 +1,202 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// postgresStore implements the Store interface for PostgreSQL databases.
+type postgresStore struct {
+	db     *sql.DB
+	opts   ConnectionOptions
+	closed bool
+	mu     sync.RWMutex
+}
+
+// OpenPostgresStore opens a PostgreSQL archive for writing with default options.
+func OpenPostgresStore(dsn string) (Store, error) {
+	return OpenPostgresStoreWithOptions(dsn, DefaultPostgresOptions())
+}
+
+// OpenPostgresStoreWithOptions opens a PostgreSQL archive for writing with custom connection options.
+func OpenPostgresStoreWithOptions(dsn string, opts ConnectionOptions) (Store, error) {
+	opts.applyDefaults(false)
+
+	db, err := openPostgresDB(dsn, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open database: %v", ErrConnectionFailed, err)
+	}
+
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ConnectTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: failed to ping database: %v", ErrConnectionFailed, err)
+	}
+
+	return &postgresStore{db: db, opts: opts}, nil
+}
+
+// ensureNotClosed checks if the store is closed and returns an error if it is.
+func (s *postgresStore) ensureNotClosed() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Prune drops old revisions according to the retention policy.
+func (s *postgresStore) Prune(ctx context.Context, policy PrunePolicy) (*PruneResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	if !policy.DryRun {
+		release, err := s.lock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT r.revision_id, r.page_id, r.parent_id, r.timestamp, r.size
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+	`
+	var args []interface{}
+	if len(policy.Namespaces) > 0 {
+		placeholders := make([]string, len(policy.Namespaces))
+		for i, ns := range policy.Namespaces {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+			args = append(args, ns)
+		}
+		query += fmt.Sprintf(" WHERE p.namespace IN (%s)", join(placeholders, ","))
+	}
+	query += " ORDER BY r.page_id, r.timestamp, r.revision_id FOR UPDATE OF r"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	candidates, err := scanPruneCandidates(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planPrune(candidates, policy)
+	if policy.DryRun {
+		return &plan.result, nil
+	}
+
+	// Re-link parents before deleting so no surviving revision ever points at a missing row
+	update, err := tx.PrepareContext(ctx, "UPDATE revisions SET parent_id = $1 WHERE revision_id = $2")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer update.Close()
+
+	for _, u := range plan.updates {
+		if _, err := update.ExecContext(ctx, u.parentID, u.revisionID); err != nil {
+			return nil, fmt.Errorf("%w: failed to re-link revision %d: %v", ErrDatabaseError, u.revisionID, err)
+		}
+	}
+
+	del, err := tx.PrepareContext(ctx, "DELETE FROM revisions WHERE revision_id = $1")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer del.Close()
+
+	for _, id := range plan.deletes {
+		if _, err := del.ExecContext(ctx, id); err != nil {
+			return nil, fmt.Errorf("%w: failed to delete revision %d: %v", ErrDatabaseError, id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	// VACUUM cannot run inside a transaction block
+	if policy.Vacuum && plan.result.RevisionsDeleted > 0 {
+		if _, err := s.db.ExecContext(ctx, "VACUUM revisions"); err != nil {
+			return &plan.result, fmt.Errorf("%w: vacuum failed: %v", ErrDatabaseError, err)
+		}
+	}
+
+	return &plan.result, nil
+}
+
+// Close cleanly shuts down the store and releases resources.
+func (s *postgresStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	s.closed = true
+
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("%w: failed to close database: %v", ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+// ExtractSubArchive creates a filtered SQLite archive from this database.
+func (s *postgresStore) ExtractSubArchive(ctx context.Context, dest string, filter SubArchiveFilter) (*ExtractResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: ExtractSubArchive is not implemented for PostgreSQL", ErrNotSupported)
+}
+
+// CheckExternalLinks checks external links and records archived copies of dead ones.
+func (s *postgresStore) CheckExternalLinks(ctx context.Context, checker LinkChecker, opts ExternalLinkOptions) (*ExternalLinkResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: CheckExternalLinks is not implemented for PostgreSQL", ErrNotSupported)
+}
+
+// RebuildSearchIndex recreates the full-text index with the configured tokenizer.
+func (s *postgresStore) RebuildSearchIndex(ctx context.Context, opts FTSOptions) error {
+	if err := s.ensureNotClosed(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: RebuildSearchIndex is not implemented for PostgreSQL", ErrNotSupported)
+}
+
+// RebuildLinks replaces the links table with the links parsed from each
+// page's latest revision.
+func (s *postgresStore) RebuildLinks(ctx context.Context) (*RebuildLinksResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return rebuildLinks(ctx, s.db, true)
+}
@@ -0,0 +1,188 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// archiveTablePattern matches the archive's table names in a query, quoted
+// or not. Columns and aliases never share these names.
+var archiveTablePattern = regexp.MustCompile(`"?\b(pages|revisions|files|links|archive_meta|deleted_pages|external_links|interwiki_links|page_html|page_protection|wikis|schema_version|scrape_runs)\b"?`)
+
+// openPostgresDB opens a PostgreSQL connection pool with the configured
+// driver. With a Schema or TablePrefix set, every statement's table names
+// are qualified on their way to the server, so the queries themselves keep
+// the bare names.
+func openPostgresDB(dsn string, opts ConnectionOptions) (*sql.DB, error) {
+	var connector driver.Connector
+	var err error
+	switch opts.PostgresDriver {
+	case "", PostgresDriverPQ:
+		connector, err = pq.NewConnector(dsn)
+	case PostgresDriverPgx:
+		connector, err = newPgxConnector(dsn)
+	default:
+		return nil, fmt.Errorf("unknown PostgreSQL driver %q (want %q or %q)", opts.PostgresDriver, PostgresDriverPQ, PostgresDriverPgx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Schema != "" || opts.TablePrefix != "" {
+		connector = &qualifiedConnector{Connector: connector, schema: opts.Schema, prefix: opts.TablePrefix}
+	}
+	return sql.OpenDB(withQueryPlans(connector, opts, true)), nil
+}
+
+// postgresTableExists reports whether an archive table exists. The name is
+// written into the query rather than passed as an argument, so that a
+// Schema or TablePrefix qualifies it like the other table names.
+func postgresTableExists(ctx context.Context, q queryRower, table string) (bool, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, "SELECT to_regclass('"+table+"') IS NOT NULL").Scan(&exists); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return exists, nil
+}
+
+// qualifyTables rewrites the archive table names in query to
+// schema.prefixname. Names inside string literals are rewritten too, so
+// to_regclass('pages') looks up the qualified table; names after a dot,
+// such as a column of another schema's table, are left alone.
+func qualifyTables(query, schema, prefix string) string {
+	matches := archiveTablePattern.FindAllStringSubmatchIndex(query, -1)
+	if len(matches) == 0 {
+		return query
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if start > 0 && query[start-1] == '.' {
+			continue
+		}
+		quotedStart, quotedEnd := query[start] == '"', query[end-1] == '"'
+		if quotedStart != quotedEnd {
+			// Half of the quotes belongs to a neighbouring identifier
+			if quotedStart {
+				start++
+			} else {
+				end--
+			}
+		}
+
+		b.WriteString(query[last:start])
+		if schema != "" {
+			b.WriteString(pq.QuoteIdentifier(schema))
+			b.WriteByte('.')
+		}
+		b.WriteString(pq.QuoteIdentifier(prefix + query[m[2]:m[3]]))
+		last = end
+	}
+	b.WriteString(query[last:])
+	return b.String()
+}
+
+// qualifiedConnector opens connections that qualify archive table names.
+type qualifiedConnector struct {
+	driver.Connector
+	schema, prefix string
+}
+
+// Connect opens a connection to the database.
+func (c *qualifiedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &qualifiedConn{Conn: conn, connector: c}, nil
+}
+
+// qualifiedConn rewrites the statements it runs with qualifyTables.
+type qualifiedConn struct {
+	driver.Conn
+	connector *qualifiedConnector
+}
+
+func (c *qualifiedConn) qualify(query string) string {
+	return qualifyTables(query, c.connector.schema, c.connector.prefix)
+}
+
+// Prepare prepares a statement.
+func (c *qualifiedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(c.qualify(query))
+}
+
+// PrepareContext prepares a statement.
+func (c *qualifiedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, c.qualify(query))
+	}
+	return c.Prepare(query)
+}
+
+// QueryContext runs a query without preparing it.
+func (c *qualifiedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, c.qualify(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// ExecContext runs a statement without preparing it.
+func (c *qualifiedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, c.qualify(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// BeginTx starts a transaction.
+func (c *qualifiedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, fmt.Errorf("driver does not support transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+// Ping checks the connection is alive.
+func (c *qualifiedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession prepares the connection for reuse.
+func (c *qualifiedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue lets the driver convert query arguments itself.
+func (c *qualifiedConn) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+// IsValid reports whether the connection can be reused.
+func (c *qualifiedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
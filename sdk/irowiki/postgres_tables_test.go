@@ -0,0 +1,52 @@
+package irowiki
+
+import "testing"
+
+// TestQualifyTables tests rewriting archive table names to a schema and
+// prefix, and leaving everything else in the query alone
+func TestQualifyTables(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"alias", "SELECT p.title FROM pages p JOIN revisions r ON r.page_id = p.page_id",
+			`SELECT p.title FROM "archive"."w_pages" p JOIN "archive"."w_revisions" r ON r.page_id = p.page_id`},
+		{"alias named like a column", "SELECT l.target_title FROM links l WHERE l.source_page_id = $1",
+			`SELECT l.target_title FROM "archive"."w_links" l WHERE l.source_page_id = $1`},
+		{"literal", "SELECT to_regclass('page_html') IS NOT NULL",
+			`SELECT to_regclass('"archive"."w_page_html"') IS NOT NULL`},
+		{"quoted", `SELECT COUNT(*) FROM "revisions"`,
+			`SELECT COUNT(*) FROM "archive"."w_revisions"`},
+		{"quoted neighbour", `SELECT "pages".title, "user" FROM revisions`,
+			`SELECT "archive"."w_pages".title, "user" FROM "archive"."w_revisions"`},
+		{"after a dot", "SELECT other.pages.title FROM other.pages",
+			"SELECT other.pages.title FROM other.pages"},
+		{"longer names", "SELECT * FROM pages_fts WHERE pages_fts MATCH $1 AND page_id IN (SELECT page_id FROM revisions_fts)",
+			"SELECT * FROM pages_fts WHERE pages_fts MATCH $1 AND page_id IN (SELECT page_id FROM revisions_fts)"},
+		{"no tables", "SELECT 1", "SELECT 1"},
+	}
+	for _, table := range []string{
+		"pages", "revisions", "files", "links", "archive_meta", "deleted_pages", "external_links",
+		"interwiki_links", "page_html", "page_protection", "wikis", "schema_version", "scrape_runs",
+	} {
+		tests = append(tests, struct {
+			name  string
+			query string
+			want  string
+		}{table, "SELECT COUNT(*) FROM " + table + " t", `SELECT COUNT(*) FROM "archive"."w_` + table + `" t`})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qualifyTables(tt.query, "archive", "w_"); got != tt.want {
+				t.Errorf("qualifyTables(%q)\n got %s\nwant %s", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// Test: Without a schema, names only get the prefix
+	if got := qualifyTables("SELECT * FROM pages", "", "w_"); got != `SELECT * FROM "w_pages"` {
+		t.Errorf("expected only the prefix, got %s", got)
+	}
+}
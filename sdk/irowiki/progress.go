@@ -0,0 +1,41 @@
+package irowiki
+
+// Progress receives progress reports from long operations, such as
+// Store.BulkLoad, Client.ExportRows and Store.RebuildSearchIndex, for CLIs
+// rendering progress bars and servers reporting job status.
+//
+// Vector indexing and schema migrations don't take one, as the SDK does
+// neither: the vector module only searches the collection
+// scripts/vectorize_wiki.py builds, which servers run with server.CommandJob,
+// and the scraper applies the migrations in schema/sqlite.
+type Progress interface {
+	// OnProgress reports that done of total units (rows, pages) of stage
+	// are complete. total is -1 when it isn't known. Reports come from the
+	// goroutine running the operation, which waits for OnProgress to return.
+	OnProgress(done, total int64, stage string)
+}
+
+// ProgressFunc adapts a function to the Progress interface.
+//
+// Example:
+//
+//	opts.Progress = irowiki.ProgressFunc(func(done, total int64, stage string) {
+//	    fmt.Fprintf(os.Stderr, "\r%s: %d/%d", stage, done, total)
+//	})
+type ProgressFunc func(done, total int64, stage string)
+
+// OnProgress calls f(done, total, stage).
+func (f ProgressFunc) OnProgress(done, total int64, stage string) {
+	f(done, total, stage)
+}
+
+// reportProgress reports to p, if set.
+func reportProgress(p Progress, done, total int64, stage string) {
+	if p != nil {
+		p.OnProgress(done, total, stage)
+	}
+}
+
+// progressInterval is the number of rows between the progress reports of
+// operations that stream rows one at a time.
+const progressInterval = 1000
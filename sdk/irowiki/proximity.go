@@ -0,0 +1,151 @@
+package irowiki
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultNearDistance is the FTS5 default for NEAR groups without an explicit distance.
+const defaultNearDistance = 10
+
+// Proximity restricts full-text matches to pages where all terms appear
+// within Distance tokens of each other, optionally within a single column.
+//
+// Example: drops within 5 words of card in page content:
+//
+//	opts := irowiki.SearchOptions{
+//	    Proximity: &irowiki.Proximity{
+//	        Terms:    []string{"drops", "card"},
+//	        Distance: 5,
+//	        Column:   "content",
+//	    },
+//	}
+type Proximity struct {
+	// Terms are the words or phrases that must appear near each other (at least two).
+	Terms []string
+
+	// Distance is the maximum number of tokens between terms.
+	// Set to 0 for default (10).
+	Distance int
+
+	// Column limits matching to "title" or "content". Empty matches either.
+	Column string
+}
+
+// Validate checks if the Proximity options are valid.
+func (p *Proximity) Validate() error {
+	if len(p.Terms) < 2 {
+		return fmt.Errorf("proximity requires at least two terms")
+	}
+	for _, term := range p.Terms {
+		if strings.TrimSpace(term) == "" {
+			return fmt.Errorf("proximity terms cannot be empty")
+		}
+	}
+	if p.Distance < 0 {
+		return fmt.Errorf("proximity distance must be non-negative")
+	}
+	switch p.Column {
+	case "", "title", "content":
+	default:
+		return fmt.Errorf("invalid proximity column: must be 'title' or 'content'")
+	}
+	return nil
+}
+
+// ftsExpression translates the proximity into an FTS5 NEAR group,
+// e.g. {content} : NEAR("drops" "card", 5).
+func (p *Proximity) ftsExpression() string {
+	phrases := make([]string, len(p.Terms))
+	for i, term := range p.Terms {
+		phrases[i] = ftsPhrase(term)
+	}
+
+	distance := p.Distance
+	if distance == 0 {
+		distance = defaultNearDistance
+	}
+
+	expr := fmt.Sprintf("NEAR(%s, %d)", strings.Join(phrases, " "), distance)
+	if p.Column != "" {
+		expr = "{" + p.Column + "} : " + expr
+	}
+	return expr
+}
+
+// ftsPhrase quotes text as an FTS5 phrase so operators and punctuation are matched literally.
+func ftsPhrase(text string) string {
+	return `"` + strings.ReplaceAll(strings.TrimSpace(text), `"`, `""`) + `"`
+}
+
+// nearInfixPattern matches the FTS3/4-style "term NEAR/n term" proximity syntax.
+var nearInfixPattern = regexp.MustCompile(`("[^"]*"|[^\s"()]+)\s+NEAR(?:/(\d+))?\s+("[^"]*"|[^\s"()]+)`)
+
+// translateNearSyntax rewrites "drops NEAR/5 card" into the FTS5 form
+// NEAR("drops" "card", 5). Chains ("a NEAR/3 b NEAR/3 c") and column-scoped
+// operands are rejected, since FTS5 cannot express them in a single group.
+func translateNearSyntax(query string) (string, error) {
+	var translateErr error
+
+	translated := nearInfixPattern.ReplaceAllStringFunc(query, func(match string) string {
+		m := nearInfixPattern.FindStringSubmatch(match)
+		left, right := m[1], m[3]
+
+		for _, operand := range []string{left, right} {
+			if snippetOperators[operand] {
+				translateErr = fmt.Errorf("%w: NEAR requires a term on each side", ErrInvalidInput)
+			}
+			if !strings.HasPrefix(operand, `"`) && strings.Contains(operand, ":") {
+				translateErr = fmt.Errorf("%w: NEAR operands cannot be column-scoped; use Proximity.Column", ErrInvalidInput)
+			}
+		}
+
+		distance := defaultNearDistance
+		if m[2] != "" {
+			distance, _ = strconv.Atoi(m[2])
+		}
+
+		return fmt.Sprintf("NEAR(%s %s, %d)", nearOperand(left), nearOperand(right), distance)
+	})
+	if translateErr != nil {
+		return "", translateErr
+	}
+
+	// Anything left over is a dangling or chained operator
+	for _, field := range strings.Fields(translated) {
+		if field == "NEAR" || strings.HasPrefix(field, "NEAR/") {
+			return "", fmt.Errorf("%w: NEAR requires a term on each side", ErrInvalidInput)
+		}
+	}
+
+	return translated, nil
+}
+
+// nearOperand quotes an infix NEAR operand, keeping a trailing prefix wildcard.
+func nearOperand(operand string) string {
+	if strings.HasSuffix(operand, "*") {
+		return ftsPhrase(strings.Trim(operand, `"*`)) + "*"
+	}
+	return ftsPhrase(strings.Trim(operand, `"`))
+}
+
+// fullTextMatch combines a query with the proximity constraint into one FTS5 expression.
+func fullTextMatch(query string, proximity *Proximity) (string, error) {
+	query = strings.TrimSpace(query)
+	if query != "" {
+		var err error
+		if query, err = translateNearSyntax(query); err != nil {
+			return "", err
+		}
+	}
+
+	if proximity == nil {
+		return query, nil
+	}
+	if query == "" {
+		return proximity.ftsExpression(), nil
+	}
+	return "(" + query + ") AND " + proximity.ftsExpression(), nil
+}
@@ -0,0 +1,75 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_SearchFullText_Proximity tests NEAR queries via SearchOptions.Proximity and NEAR/n syntax
+func TestSQLiteClient_SearchFullText_Proximity(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Content: "Poring is a pink slime monster." (4 tokens between poring and monster)
+	tests := []struct {
+		name      string
+		query     string
+		proximity *irowiki.Proximity
+		want      int
+	}{
+		{"infix within distance", "poring NEAR/4 monster", nil, 1},
+		{"infix too far apart", "poring NEAR/3 monster", nil, 0},
+		{"proximity without query", "", &irowiki.Proximity{Terms: []string{"pink", "monster"}, Distance: 1}, 1},
+		{"proximity combined with query", "slime", &irowiki.Proximity{Terms: []string{"pink", "monster"}, Column: "content"}, 1},
+		{"proximity scoped to title", "", &irowiki.Proximity{Terms: []string{"pink", "monster"}, Column: "title"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := client.SearchFullText(ctx, tt.query, irowiki.SearchOptions{Proximity: tt.proximity})
+			if err != nil {
+				t.Fatalf("SearchFullText failed: %v", err)
+			}
+			if len(results) != tt.want {
+				t.Fatalf("expected %d results, got %d", tt.want, len(results))
+			}
+			if tt.want > 0 && results[0].Title != "Poring" {
+				t.Errorf("expected Poring, got %s", results[0].Title)
+			}
+		})
+	}
+
+	// Test: Chained and dangling NEAR operators are rejected
+	for _, query := range []string{"poring NEAR/3 pink NEAR/3 monster", "poring NEAR/3"} {
+		_, err := client.SearchFullText(ctx, query, irowiki.SearchOptions{})
+		if !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for %q, got %v", query, err)
+		}
+	}
+
+	// Test: Invalid proximity options are rejected
+	_, err = client.SearchFullText(ctx, "", irowiki.SearchOptions{
+		Proximity: &irowiki.Proximity{Terms: []string{"poring"}},
+	})
+	if err == nil {
+		t.Error("expected error for single-term proximity")
+	}
+	_, err = client.SearchFullText(ctx, "", irowiki.SearchOptions{
+		Proximity: &irowiki.Proximity{Terms: []string{"pink", "monster"}, Column: "author"},
+	})
+	if err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
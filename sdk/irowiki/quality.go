@@ -0,0 +1,216 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+const (
+	// qualityBucketWidth is the score range of each histogram bucket.
+	qualityBucketWidth = 10
+
+	// qualityExtremes is the number of worst and best pages in a QualityOverview.
+	qualityExtremes = 10
+)
+
+// PageQuality is the quality score of a page.
+type PageQuality struct {
+	PageID    int64  `json:"page_id"`
+	Title     string `json:"title"`
+	Namespace int    `json:"namespace"`
+
+	// Score rates the latest revision from 0 to 100 (see GetQualityOverview).
+	Score float64 `json:"score"`
+
+	// Size is the latest revision's size in bytes.
+	Size int `json:"size"`
+
+	// IsStub reports whether the page is marked as a stub.
+	IsStub bool `json:"is_stub"`
+}
+
+// QualityBucket counts the pages scoring from Min up to Max (inclusive
+// for the last bucket).
+type QualityBucket struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Count int `json:"count"`
+}
+
+// NamespaceQuality summarizes the quality of the pages of a namespace.
+type NamespaceQuality struct {
+	Namespace    int             `json:"namespace"`
+	PageCount    int             `json:"page_count"`
+	AverageScore float64         `json:"average_score"`
+	StubCount    int             `json:"stub_count"`
+	Histogram    []QualityBucket `json:"histogram"`
+}
+
+// QualityOverview summarizes page quality across the archive, for
+// maintenance dashboards. Redirects and disambiguation pages aren't scored.
+type QualityOverview struct {
+	PageCount    int             `json:"page_count"`
+	AverageScore float64         `json:"average_score"`
+	StubCount    int             `json:"stub_count"`
+	Histogram    []QualityBucket `json:"histogram"`
+
+	// Namespaces breaks the overview down by namespace, in namespace order.
+	Namespaces []NamespaceQuality `json:"namespaces"`
+
+	// Worst and Best are the lowest and highest scoring pages, worst and
+	// best first, ties in title order.
+	Worst []PageQuality `json:"worst"`
+	Best  []PageQuality `json:"best"`
+}
+
+// pageQualityScore rates a page's latest wikitext from 0 to 100: up to 40
+// points for length (full marks at 5000 bytes), 15 for sections (4
+// headings), 15 for internal links (10), 10 for being categorized, 10 for
+// having several editors (3) and 10 for not being a stub.
+func pageQualityScore(content string, size, editors int, isStub bool) float64 {
+	doc := wikitext.Parse(content)
+	headings, links := len(wikitext.Find[*wikitext.Heading](doc)), 0
+	for _, l := range wikitext.Find[*wikitext.Link](doc) {
+		if !isNonArticleLink(l.Target) {
+			links++
+		}
+	}
+	categorized := false
+	for _, l := range documentLinks(doc) {
+		categorized = categorized || l.linkType == LinkTypeCategory
+	}
+
+	score := 40*math.Min(float64(size)/5000, 1) +
+		15*math.Min(float64(headings)/4, 1) +
+		15*math.Min(float64(links)/10, 1) +
+		10*math.Min(float64(editors)/3, 1)
+	if categorized {
+		score += 10
+	}
+	if !isStub {
+		score += 10
+	}
+	return math.Round(score*10) / 10
+}
+
+// newQualityHistogram returns empty buckets covering scores 0 to 100.
+func newQualityHistogram() []QualityBucket {
+	buckets := make([]QualityBucket, 100/qualityBucketWidth)
+	for i := range buckets {
+		buckets[i] = QualityBucket{Min: i * qualityBucketWidth, Max: (i + 1) * qualityBucketWidth}
+	}
+	return buckets
+}
+
+// addToHistogram counts score in its bucket; 100 goes in the last one.
+func addToHistogram(buckets []QualityBucket, score float64) {
+	i := min(int(score)/qualityBucketWidth, len(buckets)-1)
+	buckets[i].Count++
+}
+
+// getQualityOverview scores the latest revision of every page in the scope.
+func getQualityOverview(ctx context.Context, db querier, scope wikiScope) (*QualityOverview, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT p.page_id, p.namespace, p.title, r.content, r.size,
+		       (SELECT COUNT(DISTINCT e.user) FROM revisions e WHERE e.page_id = p.page_id) AS editors
+		FROM pages p
+		JOIN revisions r ON r.revision_id = (
+			SELECT r2.revision_id FROM revisions r2
+			WHERE r2.page_id = p.page_id
+			ORDER BY r2.timestamp DESC, r2.revision_id DESC
+			LIMIT 1
+		)
+		WHERE NOT p.is_redirect`+scope.filter("p")+`
+		ORDER BY p.title
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var pages []PageQuality
+	for rows.Next() {
+		var page Page
+		var size, editors int
+		if err := rows.Scan(&page.ID, &page.Namespace, &page.Title, &page.Content, &size, &editors); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		classifyPage(&page)
+		if page.IsDisambiguation {
+			continue
+		}
+		pages = append(pages, PageQuality{
+			PageID:    page.ID,
+			Title:     page.Title,
+			Namespace: page.Namespace,
+			Score:     pageQualityScore(page.Content, size, editors, page.IsStub),
+			Size:      size,
+			IsStub:    page.IsStub,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	overview := &QualityOverview{
+		Histogram:  newQualityHistogram(),
+		Namespaces: []NamespaceQuality{},
+		PageCount:  len(pages),
+	}
+	namespaces := make(map[int]*NamespaceQuality)
+	var total float64
+	for _, p := range pages {
+		ns, ok := namespaces[p.Namespace]
+		if !ok {
+			ns = &NamespaceQuality{Namespace: p.Namespace, Histogram: newQualityHistogram()}
+			namespaces[p.Namespace] = ns
+		}
+		ns.PageCount++
+		ns.AverageScore += p.Score
+		addToHistogram(ns.Histogram, p.Score)
+		addToHistogram(overview.Histogram, p.Score)
+		total += p.Score
+		if p.IsStub {
+			ns.StubCount++
+			overview.StubCount++
+		}
+	}
+	if len(pages) > 0 {
+		overview.AverageScore = math.Round(total/float64(len(pages))*10) / 10
+	}
+	for _, ns := range namespaces {
+		ns.AverageScore = math.Round(ns.AverageScore/float64(ns.PageCount)*10) / 10
+		overview.Namespaces = append(overview.Namespaces, *ns)
+	}
+	sort.Slice(overview.Namespaces, func(i, j int) bool {
+		return overview.Namespaces[i].Namespace < overview.Namespaces[j].Namespace
+	})
+
+	// pages is in title order, so stable sorts break ties by title
+	n := min(qualityExtremes, len(pages))
+	sort.SliceStable(pages, func(i, j int) bool { return pages[i].Score > pages[j].Score })
+	overview.Best = append([]PageQuality{}, pages[:n]...)
+	sort.SliceStable(pages, func(i, j int) bool { return pages[i].Score < pages[j].Score })
+	overview.Worst = append([]PageQuality{}, pages[:n]...)
+	return overview, nil
+}
+
+// GetQualityOverview summarizes page quality across the archive.
+func (c *sqliteClient) GetQualityOverview(ctx context.Context) (*QualityOverview, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return getQualityOverview(ctx, c.db, c.wiki)
+}
+
+// GetQualityOverview summarizes page quality across the archive.
+func (c *postgresClient) GetQualityOverview(ctx context.Context) (*QualityOverview, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return getQualityOverview(ctx, c.db, c.wiki)
+}
@@ -0,0 +1,103 @@
+package irowiki_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetQualityOverview tests scoring and aggregating page quality
+func TestSQLiteClient_GetQualityOverview(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// A complete article: long, sectioned, linked, categorized, with 3 editors
+	var article strings.Builder
+	for i := 1; i <= 4; i++ {
+		fmt.Fprintf(&article, "== Section %d ==\n", i)
+		for j := 0; j < 3; j++ {
+			fmt.Fprintf(&article, "Geffen is linked to [[Town %d%d]]. %s\n", i, j, strings.Repeat("Magic city text. ", 100))
+		}
+	}
+	article.WriteString("[[Category:Towns]]")
+
+	pages := []struct {
+		id      int64
+		title   string
+		content string
+		editors []string
+	}{
+		{10, "Geffen", article.String(), []string{"Admin", "Editor", "Contributor"}},
+		{11, "Payon", "{{Stub}} Payon is a town.", []string{"Editor"}},
+		{12, "Alberta (disambiguation)", "{{Disambig}}\n* [[Alberta]], a town", []string{"Editor"}},
+	}
+	revID := int64(300)
+	for _, p := range pages {
+		if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (?, 0, ?, 0)`, p.id, p.title); err != nil {
+			t.Fatalf("failed to add page: %v", err)
+		}
+		for i, user := range p.editors {
+			_, err := tdb.DB.Exec(`
+				INSERT INTO revisions (revision_id, page_id, timestamp, user, comment, content, size, sha1)
+				VALUES (?, ?, datetime('2024-01-01', '+' || ? || ' days'), ?, 'edit', ?, ?, ?)`,
+				revID, p.id, i, user, p.content, len(p.content), fmt.Sprintf("q%d", revID))
+			if err != nil {
+				t.Fatalf("failed to add revision: %v", err)
+			}
+			revID++
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	overview, err := client.GetQualityOverview(context.Background())
+	if err != nil {
+		t.Fatalf("GetQualityOverview failed: %v", err)
+	}
+
+	// 4 fixture articles plus Geffen and Payon; redirects and disambiguation pages aren't scored
+	if overview.PageCount != 6 {
+		t.Errorf("expected 6 scored pages, got %d", overview.PageCount)
+	}
+	if overview.StubCount != 1 {
+		t.Errorf("expected 1 stub, got %d", overview.StubCount)
+	}
+
+	if len(overview.Best) == 0 || overview.Best[0].Title != "Geffen" || overview.Best[0].Score != 100 {
+		t.Errorf("expected Geffen to score 100 at the top, got %+v", overview.Best)
+	}
+	if len(overview.Worst) == 0 || overview.Worst[0].Title != "Payon" || !overview.Worst[0].IsStub {
+		t.Errorf("expected the Payon stub to score lowest, got %+v", overview.Worst)
+	}
+	for _, p := range overview.Worst {
+		if p.Title == "Alberta (disambiguation)" || p.Title == "Redirect_Test" {
+			t.Errorf("expected %s not to be scored", p.Title)
+		}
+	}
+
+	histogramTotal := 0
+	for _, b := range overview.Histogram {
+		histogramTotal += b.Count
+	}
+	if len(overview.Histogram) != 10 || histogramTotal != 6 {
+		t.Errorf("expected 10 buckets counting 6 pages, got %d buckets counting %d", len(overview.Histogram), histogramTotal)
+	}
+	if last := overview.Histogram[len(overview.Histogram)-1]; last.Count != 1 {
+		t.Errorf("expected the top bucket to count Geffen, got %+v", last)
+	}
+
+	if len(overview.Namespaces) != 2 || overview.Namespaces[0].Namespace != 0 || overview.Namespaces[1].Namespace != 6 {
+		t.Fatalf("expected namespaces 0 and 6, got %+v", overview.Namespaces)
+	}
+	if overview.Namespaces[0].PageCount != 5 || overview.Namespaces[0].StubCount != 1 {
+		t.Errorf("expected 5 pages and 1 stub in the main namespace, got %+v", overview.Namespaces[0])
+	}
+}
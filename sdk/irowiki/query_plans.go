@@ -0,0 +1,267 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryPlan is the plan of a slow query, captured when
+// ConnectionOptions.ExplainThreshold is set.
+type QueryPlan struct {
+	// Query is the SQL statement.
+	Query string
+
+	// Duration is how long the query ran, from being sent to its rows
+	// being closed.
+	Duration time.Duration
+
+	// Plan is the output of EXPLAIN QUERY PLAN (SQLite) or EXPLAIN ANALYZE
+	// (PostgreSQL), one line per plan node, indented by depth.
+	Plan string
+}
+
+// queryPlansKey is the context key of the plans collected for a call.
+type queryPlansKey struct{}
+
+// queryPlans collects the plans of a call's slow queries.
+type queryPlans struct {
+	mu    sync.Mutex
+	plans []QueryPlan
+}
+
+// contextWithQueryPlans returns a copy of ctx collecting the plans of slow
+// queries run with it.
+func contextWithQueryPlans(ctx context.Context) (context.Context, *queryPlans) {
+	collected := &queryPlans{}
+	return context.WithValue(ctx, queryPlansKey{}, collected), collected
+}
+
+// reportQueryPlan hands a plan to the LoggingInterceptor of the call that
+// ran the query, or else to onPlan. Without either, the plan is dropped.
+func reportQueryPlan(ctx context.Context, plan QueryPlan, onPlan func(QueryPlan)) {
+	if collected, ok := ctx.Value(queryPlansKey{}).(*queryPlans); ok {
+		collected.mu.Lock()
+		collected.plans = append(collected.plans, plan)
+		collected.mu.Unlock()
+		return
+	}
+	if onPlan != nil {
+		onPlan(plan)
+	}
+}
+
+// wantsQueryPlan reports whether a plan of a query run with ctx would be
+// reported, so queries nobody reports aren't explained.
+func wantsQueryPlan(ctx context.Context, onPlan func(QueryPlan)) bool {
+	_, ok := ctx.Value(queryPlansKey{}).(*queryPlans)
+	return ok || onPlan != nil
+}
+
+// formatQueryPlan formats a plan for logs.
+func formatQueryPlan(plan QueryPlan) string {
+	return fmt.Sprintf("slow query took %v: %s\n%s", plan.Duration, strings.Join(strings.Fields(plan.Query), " "), plan.Plan)
+}
+
+// withQueryPlans wraps connector to capture the plans of slow queries when
+// opts.ExplainThreshold is set.
+func withQueryPlans(connector driver.Connector, opts ConnectionOptions, postgres bool) driver.Connector {
+	if opts.ExplainThreshold <= 0 {
+		return connector
+	}
+	return &explainConnector{Connector: connector, threshold: opts.ExplainThreshold, onPlan: opts.OnQueryPlan, postgres: postgres}
+}
+
+// explainConnector opens connections that explain slow queries.
+type explainConnector struct {
+	driver.Connector
+	threshold time.Duration
+	onPlan    func(QueryPlan)
+	postgres  bool
+}
+
+// Connect opens a connection to the database.
+func (c *explainConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &explainConn{Conn: conn, connector: c}, nil
+}
+
+// explainConn times the queries it runs and explains slow ones. Only
+// queries run without preparing them, as database/sql runs QueryContext,
+// are timed.
+type explainConn struct {
+	driver.Conn
+	connector *explainConnector
+}
+
+// QueryContext runs a query without preparing it.
+func (c *explainConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if err != nil || !explainable(query) {
+		return rows, err
+	}
+	return &explainRows{Rows: rows, conn: c, ctx: ctx, query: query, args: args, start: start}, nil
+}
+
+// PrepareContext prepares a statement.
+func (c *explainConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// ExecContext runs a statement without preparing it.
+func (c *explainConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// BeginTx starts a transaction.
+func (c *explainConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, fmt.Errorf("driver does not support transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+// Ping checks the connection is alive.
+func (c *explainConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession prepares the connection for reuse.
+func (c *explainConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue lets the driver convert query arguments itself.
+func (c *explainConn) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+// IsValid reports whether the connection can be reused.
+func (c *explainConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// explainable reports whether a query can be explained without side
+// effects, as EXPLAIN ANALYZE runs the statement.
+func explainable(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "WITH":
+		return true
+	}
+	return false
+}
+
+// explainRows times a query until its rows are closed.
+type explainRows struct {
+	driver.Rows
+	conn  *explainConn
+	ctx   context.Context
+	query string
+	args  []driver.NamedValue
+	start time.Time
+}
+
+// Close closes the rows and explains the query if it was slow. The
+// connection is still reserved for the rows, so the plan is taken on it.
+func (r *explainRows) Close() error {
+	err := r.Rows.Close()
+	elapsed := time.Since(r.start)
+	onPlan := r.conn.connector.onPlan
+	if elapsed < r.conn.connector.threshold || r.ctx.Err() != nil || !wantsQueryPlan(r.ctx, onPlan) {
+		return err
+	}
+
+	plan, explainErr := r.conn.explain(r.ctx, r.query, r.args)
+	if explainErr != nil {
+		plan = "(no plan: " + explainErr.Error() + ")"
+	}
+	reportQueryPlan(r.ctx, QueryPlan{Query: r.query, Duration: elapsed, Plan: plan}, onPlan)
+	return err
+}
+
+// explain returns the plan of a query.
+func (c *explainConn) explain(ctx context.Context, query string, args []driver.NamedValue) (string, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return "", fmt.Errorf("driver cannot run queries")
+	}
+	prefix := "EXPLAIN QUERY PLAN "
+	if c.connector.postgres {
+		prefix = "EXPLAIN ANALYZE "
+	}
+	rows, err := q.QueryContext(ctx, prefix+query, args)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	// SQLite's plan is rows of (id, parent, notused, detail); PostgreSQL's
+	// is one line of text per row
+	depth := map[int64]int{}
+	var lines []string
+	dest := make([]driver.Value, len(rows.Columns()))
+	for {
+		if err := rows.Next(dest); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if c.connector.postgres {
+			lines = append(lines, planText(dest[0]))
+			continue
+		}
+		if len(dest) < 4 {
+			return "", fmt.Errorf("unexpected query plan columns %v", rows.Columns())
+		}
+		id, _ := dest[0].(int64)
+		parent, _ := dest[1].(int64)
+		depth[id] = depth[parent] + 1
+		lines = append(lines, strings.Repeat("  ", depth[id]-1)+planText(dest[3]))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// planText converts a plan column to text.
+func planText(v driver.Value) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
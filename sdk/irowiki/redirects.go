@@ -0,0 +1,276 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// redirectPattern matches the target of a #REDIRECT [[Target]] page.
+var redirectPattern = regexp.MustCompile(`(?i)^\s*#redirect\s*:?\s*\[\[([^|\]\n]+)`)
+
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("irowiki_redirect_target", 1, sqliteRedirectTarget)
+}
+
+// redirectTarget returns the normalized title a redirect's wikitext points
+// to, without any #section, or "" if content isn't a redirect.
+func redirectTarget(content string) string {
+	m := redirectPattern.FindStringSubmatch(content)
+	if m == nil {
+		return ""
+	}
+	title, _, _ := strings.Cut(m[1], "#")
+	return normalizeTitle(strings.TrimPrefix(strings.TrimSpace(title), ":"))
+}
+
+// sqliteRedirectTarget implements irowiki_redirect_target(content), which
+// is NULL for content that isn't a redirect.
+func sqliteRedirectTarget(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	content, _ := args[0].(string)
+	if target := redirectTarget(content); target != "" {
+		return target, nil
+	}
+	return nil, nil
+}
+
+// redirectTargetColumn returns the target of the pages table aliased as
+// alias when it's a redirect, or NULL. Archives scraped before targets were
+// stored parse the latest revision with parse, the backend's equivalent of
+// redirectTarget.
+func (s wikiScope) redirectTargetColumn(alias string, parse func(content string) string) string {
+	if s.storedTargets {
+		return alias + ".redirect_target"
+	}
+	latest := fmt.Sprintf("(SELECT r.content FROM revisions r WHERE r.page_id = %s.page_id ORDER BY r.timestamp DESC LIMIT 1)", alias)
+	return fmt.Sprintf("CASE WHEN %s.is_redirect THEN %s END", alias, parse(latest))
+}
+
+// sqliteParseTarget is the parse function of redirectTargetColumn for SQLite.
+func sqliteParseTarget(content string) string {
+	return "irowiki_redirect_target(" + content + ")"
+}
+
+// postgresRedirectTarget is the PostgreSQL equivalent of redirectTarget
+// for the content expression.
+func postgresRedirectTarget(content string) string {
+	target := `btrim(replace((regexp_match(` + content + `, '^\s*#redirect\s*:?\s*\[\[:?([^|\]#\n]+)', 'i'))[1], '_', ' '))`
+	return `NULLIF(upper(left(` + target + `, 1)) || substr(` + target + `, 2), '')`
+}
+
+// collapsedMatches groups the rows of a title search's matches (page_id,
+// title, target, relevance) by the page they lead to: a redirect whose
+// target is in the archive counts as a match of its target. The result has
+// page_id, relevance, and redirected_from naming the redirect a page was
+// reached through, unless the page also matched itself. targetFilter
+// restricts the targets (alias t) to the client's scope.
+func collapsedMatches(matches, targetFilter string) string {
+	return `
+		SELECT COALESCE(t.page_id, m.page_id) AS page_id,
+		       MAX(m.relevance) AS relevance,
+		       CASE WHEN COUNT(*) > COUNT(t.page_id) THEN NULL
+		            ELSE MIN(m.title) END AS redirected_from
+		FROM (` + matches + `) m
+		LEFT JOIN pages t ON m.target IS NOT NULL
+		     AND t.title IN (m.target, REPLACE(m.target, ' ', '_'))` + targetFilter + `
+		GROUP BY COALESCE(t.page_id, m.page_id)`
+}
+
+// getRedirectsTo lists the redirects pointing to the page titled title,
+// ordered by title. parse is the backend's redirectTargetColumn parser.
+func getRedirectsTo(ctx context.Context, db querier, scope wikiScope, title string, parse func(content string) string, placeholder func(n int) string) ([]Page, error) {
+	page, err := resolveTitle(ctx, db, scope, title, false, placeholder)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT p.title FROM pages p
+		WHERE p.is_redirect AND `+scope.redirectTargetColumn("p", parse)+` = `+placeholder(1)+scope.filter("p")+`
+		ORDER BY p.title
+	`, normalizeTitle(page.Title))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		titles = append(titles, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	redirects, err := getPagesByTitle(ctx, db, scope, titles, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]Page, 0, len(redirects))
+	for _, r := range redirects {
+		if r.Found() {
+			pages = append(pages, *r.Page)
+		}
+	}
+	return pages, nil
+}
+
+// GetRedirectsTo lists the redirects pointing to a page.
+func (c *sqliteClient) GetRedirectsTo(ctx context.Context, title string) ([]Page, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getRedirectsTo(ctx, c.db, c.wiki, title, sqliteParseTarget, placeholder)
+}
+
+// GetRedirectsTo lists the redirects pointing to a page.
+func (c *postgresClient) GetRedirectsTo(ctx context.Context, title string) ([]Page, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getRedirectsTo(ctx, c.db, c.wiki, title, postgresRedirectTarget, placeholder)
+}
+
+// RedirectIssue is a redirect listed by FindDoubleRedirects or
+// FindBrokenRedirects.
+type RedirectIssue struct {
+	// PageID and Title identify the redirect.
+	PageID int64  `json:"page_id"`
+	Title  string `json:"title"`
+
+	// Target is the title the redirect points to.
+	Target string `json:"target"`
+
+	// FinalTarget is where Target redirects in turn, for double redirects.
+	FinalTarget string `json:"final_target,omitempty"`
+}
+
+// RedirectReport is a page of a redirect maintenance report.
+type RedirectReport struct {
+	// Issues lists the redirects of this page, ordered by title.
+	Issues []RedirectIssue `json:"issues"`
+
+	// Total is the number of redirects in the whole report.
+	Total int `json:"total"`
+
+	// Offset and Limit are the pagination the report was read with.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+
+	// HasMore indicates if there are more redirects beyond this page.
+	HasMore bool `json:"has_more"`
+}
+
+// findRedirectIssues lists the double redirects (double=true) or broken
+// redirects of the scope, like Special:DoubleRedirects and
+// Special:BrokenRedirects. parse is the backend's redirectTargetColumn parser.
+func findRedirectIssues(ctx context.Context, db querier, scope wikiScope, double bool, offset, limit int, parse func(content string) string, placeholder func(n int) string) (*RedirectReport, error) {
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("%w: offset and limit must be non-negative", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = 100
+	}
+
+	redirects := `
+		SELECT p.page_id, p.title, ` + scope.redirectTargetColumn("p", parse) + ` AS target
+		FROM pages p
+		WHERE p.is_redirect` + scope.filter("p")
+	targetMatch := "t.title IN (x.target, REPLACE(x.target, ' ', '_'))" + scope.filter("t")
+
+	var from, finalTarget string
+	if double {
+		from = `(` + redirects + `) x
+			JOIN pages t ON ` + targetMatch + ` AND t.is_redirect`
+		finalTarget = scope.redirectTargetColumn("t", parse)
+	} else {
+		from = `(` + redirects + `) x
+			WHERE x.target IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM pages t WHERE ` + targetMatch + `)`
+		finalTarget = "NULL"
+	}
+
+	report := &RedirectReport{Issues: []RedirectIssue{}, Offset: offset, Limit: limit}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+from).Scan(&report.Total); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT x.page_id, x.title, x.target, `+finalTarget+`
+		FROM `+from+`
+		ORDER BY x.title
+		LIMIT `+placeholder(1)+` OFFSET `+placeholder(2), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var issue RedirectIssue
+		var final sql.NullString
+		if err := rows.Scan(&issue.PageID, &issue.Title, &issue.Target, &final); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		issue.FinalTarget = final.String
+		report.Issues = append(report.Issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	report.HasMore = offset+len(report.Issues) < report.Total
+	return report, nil
+}
+
+// FindDoubleRedirects lists redirects pointing to another redirect.
+func (c *sqliteClient) FindDoubleRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return findRedirectIssues(ctx, c.db, c.wiki, true, offset, limit, sqliteParseTarget, placeholder)
+}
+
+// FindBrokenRedirects lists redirects pointing to a page that isn't in the archive.
+func (c *sqliteClient) FindBrokenRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return findRedirectIssues(ctx, c.db, c.wiki, false, offset, limit, sqliteParseTarget, placeholder)
+}
+
+// FindDoubleRedirects lists redirects pointing to another redirect.
+func (c *postgresClient) FindDoubleRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return findRedirectIssues(ctx, c.db, c.wiki, true, offset, limit, postgresRedirectTarget, placeholder)
+}
+
+// FindBrokenRedirects lists redirects pointing to a page that isn't in the archive.
+func (c *postgresClient) FindBrokenRedirects(ctx context.Context, offset, limit int) (*RedirectReport, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return findRedirectIssues(ctx, c.db, c.wiki, false, offset, limit, postgresRedirectTarget, placeholder)
+}
@@ -0,0 +1,174 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetRedirectsTo tests listing the redirects into a page
+func TestSQLiteClient_GetRedirectsTo(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`
+		INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES
+			(20, 0, 'Poring_Monster', 1),
+			(21, 0, 'Pink_Monster', 1),
+			(22, 0, 'Monster_Nowhere', 1);
+		INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES
+			(300, 20, '2024-01-01 00:00:00', '#REDIRECT [[poring]]', 20, 'x'),
+			(301, 21, '2024-01-01 00:00:00', '#REDIRECT [[Poring#Drops]]', 26, 'x'),
+			(302, 22, '2024-01-01 00:00:00', '#REDIRECT [[Nowhere]]', 21, 'x')`)
+	if err != nil {
+		t.Fatalf("failed to add redirects: %v", err)
+	}
+
+	check := func(client irowiki.Client) {
+		t.Helper()
+		ctx := context.Background()
+
+		pages, err := client.GetRedirectsTo(ctx, "Poring")
+		if err != nil {
+			t.Fatalf("GetRedirectsTo failed: %v", err)
+		}
+		if len(pages) != 2 || pages[0].Title != "Pink_Monster" || pages[1].Title != "Poring_Monster" {
+			t.Fatalf("expected Pink_Monster and Poring_Monster, got %+v", pages)
+		}
+		if pages[0].RedirectTarget != "Poring" {
+			t.Errorf("expected RedirectTarget Poring, got %q", pages[0].RedirectTarget)
+		}
+
+		// Test: Pages without redirects into them
+		pages, err = client.GetRedirectsTo(ctx, "Prontera")
+		if err != nil {
+			t.Fatalf("GetRedirectsTo failed: %v", err)
+		}
+		if len(pages) != 0 {
+			t.Errorf("expected no redirects, got %+v", pages)
+		}
+
+		// Test: Missing pages
+		if _, err := client.GetRedirectsTo(ctx, "Nowhere"); !errors.Is(err, irowiki.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	}
+
+	// Test: Archives without stored targets parse the latest revisions
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	check(client)
+
+	page, err := client.GetPage(context.Background(), "Monster_Nowhere")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.RedirectTarget != "Nowhere" {
+		t.Errorf("expected RedirectTarget Nowhere, got %q", page.RedirectTarget)
+	}
+	page, err = client.GetPage(context.Background(), "Prontera")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.RedirectTarget != "" {
+		t.Errorf("expected no RedirectTarget, got %q", page.RedirectTarget)
+	}
+	client.Close()
+
+	// Test: Stored targets are used when the column exists
+	_, err = tdb.DB.Exec(`
+		ALTER TABLE pages ADD COLUMN redirect_target TEXT;
+		UPDATE pages SET redirect_target = 'Poring' WHERE page_id IN (20, 21);
+		UPDATE pages SET redirect_target = 'Nowhere' WHERE page_id = 22`)
+	if err != nil {
+		t.Fatalf("failed to store targets: %v", err)
+	}
+	client, err = irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	check(client)
+}
+
+// TestSQLiteClient_RedirectReports tests listing double and broken redirects
+func TestSQLiteClient_RedirectReports(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`
+		INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES
+			(20, 0, 'Poring_Monster', 1),
+			(21, 0, 'Pink_Monster', 1),
+			(22, 0, 'Monster_Nowhere', 1),
+			(23, 0, 'Monster_Elsewhere', 1);
+		INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES
+			(300, 20, '2024-01-01 00:00:00', '#REDIRECT [[Poring]]', 20, 'x'),
+			(301, 21, '2024-01-01 00:00:00', '#REDIRECT [[Poring_Monster]]', 28, 'x'),
+			(302, 22, '2024-01-01 00:00:00', '#REDIRECT [[Nowhere]]', 21, 'x'),
+			(303, 23, '2024-01-01 00:00:00', '#REDIRECT [[Monster Nowhere#Drops]]', 35, 'x')`)
+	if err != nil {
+		t.Fatalf("failed to add redirects: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Redirects to redirects, with where the chain ends
+	report, err := client.FindDoubleRedirects(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("FindDoubleRedirects failed: %v", err)
+	}
+	want := []irowiki.RedirectIssue{
+		{PageID: 23, Title: "Monster_Elsewhere", Target: "Monster Nowhere", FinalTarget: "Nowhere"},
+		{PageID: 21, Title: "Pink_Monster", Target: "Poring Monster", FinalTarget: "Poring"},
+	}
+	if report.Total != 2 || len(report.Issues) != 2 || report.HasMore || report.Limit != 100 {
+		t.Fatalf("expected 2 double redirects, got %+v", report)
+	}
+	for i, issue := range report.Issues {
+		if issue != want[i] {
+			t.Errorf("issue %d: expected %+v, got %+v", i, want[i], issue)
+		}
+	}
+
+	// Test: Redirects to missing pages; unparseable redirects are skipped
+	report, err = client.FindBrokenRedirects(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("FindBrokenRedirects failed: %v", err)
+	}
+	if report.Total != 1 || len(report.Issues) != 1 || report.Issues[0].Title != "Monster_Nowhere" || report.Issues[0].Target != "Nowhere" {
+		t.Fatalf("expected Monster_Nowhere to be broken, got %+v", report)
+	}
+
+	// Test: Pagination
+	report, err = client.FindDoubleRedirects(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("FindDoubleRedirects failed: %v", err)
+	}
+	if report.Total != 2 || len(report.Issues) != 1 || report.Issues[0].Title != "Pink_Monster" || report.HasMore {
+		t.Errorf("expected the second double redirect only, got %+v", report)
+	}
+	report, err = client.FindDoubleRedirects(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("FindDoubleRedirects failed: %v", err)
+	}
+	if !report.HasMore {
+		t.Errorf("expected more double redirects, got %+v", report)
+	}
+
+	// Test: Invalid pagination
+	if _, err := client.FindBrokenRedirects(ctx, -1, 0); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package irowiki
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body, as signed for GETs.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// OpenSQLiteURL opens a SQLite archive kept in object storage or on a web
+// server, for deployments without a pre-provisioned volume. The archive is
+// downloaded into a local cache (ConnectionOptions.CacheDir) and revalidated
+// with its ETag on later opens, so it's only fetched again after it changes;
+// if the server can't be reached, a cached copy is used.
+//
+// Supported URLs:
+//   - s3://bucket/key, signed with AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+//     and AWS_SESSION_TOKEN if set (anonymous otherwise) for AWS_REGION.
+//     AWS_ENDPOINT_URL selects an S3-compatible service such as MinIO or R2.
+//   - gs://bucket/object, anonymous or with GOOGLE_OAUTH_ACCESS_TOKEN.
+//   - http:// and https:// URLs, such as an "irowiki serve --download" mirror.
+//
+// Example:
+//
+//	client, err := irowiki.OpenSQLiteURL("s3://archives/irowiki.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+func OpenSQLiteURL(rawURL string) (Client, error) {
+	return OpenSQLiteURLWithOptions(rawURL, DefaultSQLiteOptions())
+}
+
+// OpenSQLiteURLWithOptions opens a remote SQLite archive with custom connection options.
+func OpenSQLiteURLWithOptions(rawURL string, opts ConnectionOptions) (Client, error) {
+	opts.applyDefaults(true)
+
+	local, err := fetchRemoteArchive(context.Background(), rawURL, opts.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return OpenSQLiteWithOptions(local, opts)
+}
+
+// fetchRemoteArchive brings the cached copy of rawURL up to date and returns its path.
+func fetchRemoteArchive(ctx context.Context, rawURL, cacheDir string) (string, error) {
+	req, err := remoteArchiveRequest(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir, err = remoteCacheDir(cacheDir)
+	if err != nil {
+		return "", err
+	}
+
+	// One cache entry per URL, named after the object for easier inspection
+	sum := sha256.Sum256([]byte(rawURL))
+	cached := filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+"-"+path.Base(req.URL.Path))
+	etagFile := cached + ".etag"
+
+	_, statErr := os.Stat(cached)
+	haveCache := statErr == nil
+	if etag, err := os.ReadFile(etagFile); err == nil && haveCache {
+		req.Header.Set("If-None-Match", string(etag))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if haveCache {
+			return cached, nil
+		}
+		return "", fmt.Errorf("%w: failed to fetch %s: %v", ErrConnectionFailed, rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && haveCache:
+		return cached, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, rawURL)
+	case resp.StatusCode != http.StatusOK:
+		if haveCache && resp.StatusCode >= 500 {
+			return cached, nil
+		}
+		return "", fmt.Errorf("%w: failed to fetch %s: %s", ErrConnectionFailed, rawURL, resp.Status)
+	}
+
+	// Download beside the cache entry and swap it in, so a failed transfer
+	// never leaves a truncated archive behind
+	tmp, err := os.CreateTemp(cacheDir, ".download-*")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), cached)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to download %s: %v", ErrConnectionFailed, rawURL, err)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		os.WriteFile(etagFile, []byte(etag), 0o644)
+	} else {
+		os.Remove(etagFile)
+	}
+	return cached, nil
+}
+
+// remoteCacheDir creates the cache directory, defaulting to "irowiki" in
+// the user cache directory, and returns it.
+func remoteCacheDir(cacheDir string) (string, error) {
+	if cacheDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("%w: no cache directory: %v", ErrConnectionFailed, err)
+		}
+		cacheDir = filepath.Join(dir, "irowiki")
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	return cacheDir, nil
+}
+
+// remoteArchiveRequest builds the GET request for an archive URL, mapping
+// s3:// and gs:// to their HTTPS endpoints and signing it when credentials
+// are configured.
+func remoteArchiveRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid archive URL %q: %v", ErrInvalidInput, rawURL, err)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+
+	var target string
+	switch u.Scheme {
+	case "http", "https":
+		target = u.String()
+	case "s3":
+		if u.Host == "" || key == "" {
+			return nil, fmt.Errorf("%w: %q is not s3://bucket/key", ErrInvalidInput, rawURL)
+		}
+		if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+			// S3-compatible services generally only support path-style URLs
+			target = strings.TrimSuffix(endpoint, "/") + "/" + u.Host + "/" + escapeKey(key)
+		} else {
+			target = "https://" + u.Host + ".s3." + awsRegion() + ".amazonaws.com/" + escapeKey(key)
+		}
+	case "gs":
+		if u.Host == "" || key == "" {
+			return nil, fmt.Errorf("%w: %q is not gs://bucket/object", ErrInvalidInput, rawURL)
+		}
+		target = "https://storage.googleapis.com/" + u.Host + "/" + escapeKey(key)
+	default:
+		return nil, fmt.Errorf("%w: unsupported archive URL scheme %q (want s3, gs, http or https)", ErrInvalidInput, u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid archive URL %q: %v", ErrInvalidInput, rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "s3":
+		if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+			signS3Request(req, id, secret, os.Getenv("AWS_SESSION_TOKEN"), awsRegion(), time.Now())
+		}
+	case "gs":
+		if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return req, nil
+}
+
+// signS3Request adds AWS Signature Version 4 headers to a bodiless S3 request.
+func signS3Request(req *http.Request, accessKey, secretKey, sessionToken, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	headers := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	values := []string{req.URL.Host, emptyPayloadHash, amzDate}
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+		headers = append(headers, "x-amz-security-token")
+		values = append(values, sessionToken)
+	}
+
+	var canonicalHeaders strings.Builder
+	for i, h := range headers {
+		canonicalHeaders.WriteString(h + ":" + values[i] + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{day, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapeKey escapes an object key for use in a URL path the way Signature
+// Version 4 expects: everything but unreserved characters and slashes.
+func escapeKey(key string) string {
+	var b strings.Builder
+	for _, c := range []byte(key) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// awsRegion returns the configured AWS region, defaulting to us-east-1.
+func awsRegion() string {
+	if region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"); region != "" {
+		return region
+	}
+	return "us-east-1"
+}
+
+// firstEnv returns the first non-empty of the named environment variables.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestOpenSQLiteURL tests opening archives from object storage through the local cache
+func TestOpenSQLiteURL(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	archive, err := os.ReadFile(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	var downloads atomic.Int32
+	var authorization atomic.Value
+	bucket := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		if r.URL.Path != "/archives/snapshots/irowiki.db" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Write(archive)
+	}))
+	defer bucket.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL", bucket.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.CacheDir = t.TempDir()
+	open := func(rawURL string) (irowiki.Client, error) {
+		t.Helper()
+		return irowiki.OpenSQLiteURLWithOptions(rawURL, opts)
+	}
+
+	// Test: The archive is downloaded with a signed request and opened
+	client, err := open("s3://archives/snapshots/irowiki.db")
+	if err != nil {
+		t.Fatalf("OpenSQLiteURL failed: %v", err)
+	}
+	page, err := client.GetPage(context.Background(), "Poring")
+	if err != nil || page.ID != 3 {
+		t.Errorf("expected page 3, got %v, %v", page, err)
+	}
+	client.Close()
+
+	auth, _ := authorization.Load().(string)
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("unexpected Authorization header %q", auth)
+	}
+
+	// Test: An unchanged archive is served from the cache
+	client, err = open("s3://archives/snapshots/irowiki.db")
+	if err != nil {
+		t.Fatalf("OpenSQLiteURL failed: %v", err)
+	}
+	client.Close()
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("expected 1 download, got %d", n)
+	}
+
+	// Test: Missing objects report ErrNotFound
+	if _, err := open("s3://archives/missing.db"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Test: The cached copy is used when storage can't be reached
+	bucket.Close()
+	client, err = open("s3://archives/snapshots/irowiki.db")
+	if err != nil {
+		t.Fatalf("expected the cached archive, got %v", err)
+	}
+	client.Close()
+
+	// Test: Unsupported and incomplete URLs are rejected
+	for _, rawURL := range []string{"ftp://host/irowiki.db", "s3://archives", "gs:///irowiki.db"} {
+		if _, err := open(rawURL); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", rawURL, err)
+		}
+	}
+}
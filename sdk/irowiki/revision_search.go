@@ -0,0 +1,366 @@
+package irowiki
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// revisionSearchBatch is the number of pages a revision search with a time
+// budget scans between checks of the budget.
+const revisionSearchBatch = 1000
+
+// RevisionSearchOptions configures SearchRevisions.
+type RevisionSearchOptions struct {
+	// Namespaces limits results to pages in these namespaces. Empty searches all.
+	Namespaces []int
+
+	// User limits results to revisions by this editor.
+	User string
+
+	// Start filters revisions made on or after this time (optional).
+	Start time.Time
+
+	// End filters revisions made on or before this time (optional).
+	End time.Time
+
+	// GroupByPage collapses results to one row per page: the best-matching
+	// revision, with MatchingRevisions counting all matches on that page.
+	// Prevents heavily edited pages from flooding results.
+	GroupByPage bool
+
+	// Offset is the number of results to skip (for pagination).
+	Offset int
+
+	// Limit is the maximum number of results to return.
+	// Set to 0 for default limit (20).
+	Limit int
+
+	// SnippetLength is the maximum length of generated snippets in bytes.
+	// Set to 0 for default (200).
+	SnippetLength int
+
+	// TimeBudget bounds the search's running time. Pages are scanned in
+	// batches; when the budget runs out with pages left, the results so far
+	// are returned with a *PartialResultError, whose token passed as
+	// Continue searches the remaining pages. Each part is ranked on its own.
+	// Can't be combined with Offset.
+	// Default: 0 (no budget).
+	TimeBudget time.Duration
+
+	// Continue resumes a search that ran out of its TimeBudget.
+	Continue string
+}
+
+// Validate checks if the RevisionSearchOptions are valid.
+func (o *RevisionSearchOptions) Validate() error {
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must be non-negative")
+	}
+	if o.Limit > 1000 {
+		return fmt.Errorf("limit must not exceed 1000")
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("offset must be non-negative")
+	}
+	if !o.Start.IsZero() && !o.End.IsZero() && o.Start.After(o.End) {
+		return fmt.Errorf("start must be before or equal to end")
+	}
+	if o.SnippetLength < 0 {
+		return fmt.Errorf("snippet_length must be non-negative")
+	}
+	if o.TimeBudget < 0 {
+		return fmt.Errorf("time_budget must be non-negative")
+	}
+	if o.Offset > 0 && (o.TimeBudget > 0 || o.Continue != "") {
+		return fmt.Errorf("offset can't be combined with time_budget or continue")
+	}
+	if _, err := decodeContinueToken(o.Continue); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetDefaults applies default values to RevisionSearchOptions.
+func (o *RevisionSearchOptions) SetDefaults() {
+	if o.Limit == 0 {
+		o.Limit = 20
+	}
+}
+
+// RevisionSearchResult is a revision whose content matches a search.
+type RevisionSearchResult struct {
+	// RevisionID is the matching revision.
+	RevisionID int64 `json:"revision_id"`
+
+	// PageID is the page the revision belongs to.
+	PageID int64 `json:"page_id"`
+
+	// Namespace is the page's MediaWiki namespace.
+	Namespace int `json:"namespace"`
+
+	// Title is the page title.
+	Title string `json:"title"`
+
+	// Timestamp is when the revision was made.
+	Timestamp time.Time `json:"timestamp"`
+
+	// User is the editor who made the revision.
+	User string `json:"user"`
+
+	// Comment is the edit summary.
+	Comment string `json:"comment"`
+
+	// Snippet shows the match in context, with terms wrapped in <mark> tags.
+	Snippet string `json:"snippet"`
+
+	// Relevance is the number of query term occurrences in the revision (higher is better).
+	Relevance float64 `json:"relevance"`
+
+	// MatchingRevisions is the number of the page's revisions matching the query.
+	MatchingRevisions int `json:"matching_revisions"`
+}
+
+// revisionField is the revision column a revision search matches: content
+// for SearchRevisions, comment for SearchComments. index names a full-text
+// index of the column whose rowid is the revision_id, or is "" to match
+// with like.
+type revisionField struct {
+	column string
+	index  string
+}
+
+// pageRange limits a revision search to the pages with IDs after after and
+// up to through. Zero values don't limit.
+type pageRange struct {
+	after, through int64
+}
+
+// buildRevisionSearchQuery builds the revision search SQL shared by both backends.
+// placeholder renders the nth (1-based) bind parameter; like is the
+// case-insensitive LIKE operator. Every term must occur in the searched field.
+func buildRevisionSearchQuery(terms []string, opts RevisionSearchOptions, scope wikiScope, field revisionField, pages pageRange, placeholder func(n int) string, like string) (string, []interface{}) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return placeholder(len(args))
+	}
+
+	// Occurrences of each term: the length removed by deleting it, divided by its length
+	relevance := make([]string, len(terms))
+	for i, term := range terms {
+		lower := strings.ToLower(term)
+		relevance[i] = fmt.Sprintf("(LENGTH(LOWER(%[1]s)) - LENGTH(REPLACE(LOWER(%[1]s), %[2]s, ''))) * 1.0 / %[3]s",
+			field.column, arg(lower), arg(utf8.RuneCountInString(lower)))
+	}
+
+	conditions := make([]string, 0, len(terms)+4)
+	if field.index != "" {
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		}
+		conditions = append(conditions, fmt.Sprintf("r.revision_id IN (SELECT rowid FROM %[1]s WHERE %[1]s MATCH %[2]s)",
+			field.index, arg(strings.Join(quoted, " "))))
+	} else {
+		for _, term := range terms {
+			conditions = append(conditions, fmt.Sprintf("%s %s %s", field.column, like, arg("%"+term+"%")))
+		}
+	}
+	if len(opts.Namespaces) > 0 {
+		placeholders := make([]string, len(opts.Namespaces))
+		for i, ns := range opts.Namespaces {
+			placeholders[i] = arg(ns)
+		}
+		conditions = append(conditions, fmt.Sprintf("p.namespace IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if opts.User != "" {
+		conditions = append(conditions, "r.user = "+arg(opts.User))
+	}
+	if !opts.Start.IsZero() {
+		conditions = append(conditions, "r.timestamp >= "+arg(opts.Start))
+	}
+	if !opts.End.IsZero() {
+		conditions = append(conditions, "r.timestamp <= "+arg(opts.End))
+	}
+	if pages.after > 0 {
+		conditions = append(conditions, "r.page_id > "+arg(pages.after))
+	}
+	if pages.through > 0 {
+		conditions = append(conditions, "r.page_id <= "+arg(pages.through))
+	}
+
+	query := fmt.Sprintf(`
+		WITH matches AS (
+			SELECT r.revision_id, r.page_id, p.namespace, p.title, r.timestamp,
+			       r.user AS username, r.comment, %s AS matched,
+			       %s AS relevance
+			FROM revisions r
+			JOIN pages p ON p.page_id = r.page_id
+			WHERE %s%s
+		), ranked AS (
+			SELECT m.*,
+			       ROW_NUMBER() OVER (PARTITION BY page_id ORDER BY relevance DESC, timestamp DESC, revision_id DESC) AS page_rank,
+			       COUNT(*) OVER (PARTITION BY page_id) AS page_matches
+			FROM matches m
+		)
+		SELECT revision_id, page_id, namespace, title, timestamp, username, comment, matched, relevance, page_matches
+		FROM ranked
+	`, field.column, strings.Join(relevance, " + "), strings.Join(conditions, " AND "), scope.filter("p"))
+
+	if opts.GroupByPage {
+		query += " WHERE page_rank = 1"
+	}
+
+	query += " ORDER BY relevance DESC, timestamp DESC, revision_id DESC"
+	query += fmt.Sprintf(" LIMIT %s OFFSET %s", arg(opts.Limit), arg(opts.Offset))
+
+	return query, args
+}
+
+// searchRevisions validates a revision search, runs it, and scans the results.
+// Snippets are taken from the searched field.
+func searchRevisions(ctx context.Context, db querier, scope wikiScope, query string, opts RevisionSearchOptions, field revisionField, placeholder func(n int) string, like string) ([]RevisionSearchResult, error) {
+	terms := snippetTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: query cannot be empty", ErrInvalidInput)
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	opts.SetDefaults()
+
+	if opts.TimeBudget == 0 && opts.Continue == "" {
+		return runRevisionSearch(ctx, db, scope, query, terms, opts, field, pageRange{}, placeholder, like)
+	}
+
+	after, _ := decodeContinueToken(opts.Continue)
+	deadline := time.Now().Add(opts.TimeBudget)
+	results := []RevisionSearchResult{}
+	for {
+		// The last page of the batch, or none for the last batch
+		var through int64
+		err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT page_id FROM pages WHERE page_id > %s ORDER BY page_id LIMIT 1 OFFSET %d",
+			placeholder(1), revisionSearchBatch-1), after).Scan(&through)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		batch, err := runRevisionSearch(ctx, db, scope, query, terms, opts, field, pageRange{after: after, through: through}, placeholder, like)
+		if err != nil {
+			return nil, err
+		}
+		// Batches hold whole pages, so each page's group is complete
+		results = append(results, batch...)
+		slices.SortStableFunc(results, func(a, b RevisionSearchResult) int {
+			return cmp.Or(cmp.Compare(b.Relevance, a.Relevance), b.Timestamp.Compare(a.Timestamp), cmp.Compare(b.RevisionID, a.RevisionID))
+		})
+		results = results[:min(len(results), opts.Limit)]
+
+		if through == 0 {
+			return results, nil
+		}
+		after = through
+		if opts.TimeBudget > 0 && time.Now().After(deadline) {
+			return results, &PartialResultError{Continue: encodeContinueToken(after)}
+		}
+	}
+}
+
+// runRevisionSearch runs a revision search over a range of pages and scans
+// the results.
+func runRevisionSearch(ctx context.Context, db querier, scope wikiScope, query string, terms []string, opts RevisionSearchOptions, field revisionField, pages pageRange, placeholder func(n int) string, like string) ([]RevisionSearchResult, error) {
+	sqlQuery, args := buildRevisionSearchQuery(terms, opts, scope, field, pages, placeholder, like)
+
+	rows, err := db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	results := []RevisionSearchResult{}
+	for rows.Next() {
+		var result RevisionSearchResult
+		var user, comment, matched sql.NullString
+
+		err := rows.Scan(&result.RevisionID, &result.PageID, &result.Namespace, &result.Title, &result.Timestamp,
+			&user, &comment, &matched, &result.Relevance, &result.MatchingRevisions)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		result.User = user.String
+		result.Comment = comment.String
+		result.Snippet = buildSnippet(matched.String, query, opts.SnippetLength)
+
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return results, nil
+}
+
+// encodeContinueToken returns the token continuing a scan after the page
+// with the given ID.
+func encodeContinueToken(after int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("page:" + strconv.FormatInt(after, 10)))
+}
+
+// decodeContinueToken returns the page ID a continuation token resumes
+// after, or 0 for an empty token.
+func decodeContinueToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		if id, ok := strings.CutPrefix(string(data), "page:"); ok {
+			if after, err := strconv.ParseInt(id, 10, 64); err == nil && after > 0 {
+				return after, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid continuation token %q", token)
+}
+
+// SearchRevisions searches the content of every revision, not just the latest.
+func (c *sqliteClient) SearchRevisions(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	// SQLite's LIKE is already case-insensitive for ASCII
+	placeholder := func(int) string { return "?" }
+	return searchRevisions(ctx, c.db, c.wiki, query, opts, revisionField{column: "r.content"}, placeholder, "LIKE")
+}
+
+// SearchComments searches the edit summaries of every revision, using the
+// revision_comments_fts index when the archive has one.
+func (c *sqliteClient) SearchComments(ctx context.Context, query string, opts RevisionSearchOptions) ([]RevisionSearchResult, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	field := revisionField{column: "r.comment"}
+	indexed, err := sqliteTableExists(ctx, c.db, "main", "revision_comments_fts")
+	if err != nil {
+		return nil, err
+	}
+	if indexed {
+		field.index = "revision_comments_fts"
+	}
+
+	placeholder := func(int) string { return "?" }
+	return searchRevisions(ctx, c.db, c.wiki, query, opts, field, placeholder, "LIKE")
+}
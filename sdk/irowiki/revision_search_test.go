@@ -0,0 +1,241 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_SearchRevisions tests searching historical revision content
+func TestSQLiteClient_SearchRevisions(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Every matching revision is returned, newest first on equal relevance
+	results, err := client.SearchRevisions(ctx, "WIKI", irowiki.RevisionSearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchRevisions failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].RevisionID != 101 || results[1].RevisionID != 100 {
+		t.Errorf("expected revisions [101 100], got [%d %d]", results[0].RevisionID, results[1].RevisionID)
+	}
+	if results[0].MatchingRevisions != 2 {
+		t.Errorf("expected MatchingRevisions 2, got %d", results[0].MatchingRevisions)
+	}
+	if results[0].Snippet != "Welcome to the iRO <mark>wiki</mark>!" {
+		t.Errorf("unexpected snippet: %s", results[0].Snippet)
+	}
+
+	// Test: Filters apply to the revision, not the page
+	results, err = client.SearchRevisions(ctx, "wiki", irowiki.RevisionSearchOptions{User: "Admin"})
+	if err != nil {
+		t.Fatalf("SearchRevisions failed: %v", err)
+	}
+	if len(results) != 1 || results[0].RevisionID != 100 {
+		t.Errorf("expected only revision 100 by Admin, got %+v", results)
+	}
+
+	// Test: Every term must appear
+	results, err = client.SearchRevisions(ctx, "capital monster", irowiki.RevisionSearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchRevisions failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+
+	// Test: Empty query is rejected
+	_, err = client.SearchRevisions(ctx, " ", irowiki.RevisionSearchOptions{})
+	if !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// TestSQLiteClient_SearchRevisions_GroupByPage tests collapsing revision matches to one per page
+func TestSQLiteClient_SearchRevisions_GroupByPage(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// A heavily edited page that mentions "city" in several revisions
+	_, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+		VALUES (200, 2, 103, '2020-02-01 00:00:00', 'Editor', 2, 'Expand', 'Prontera is the capital city. The city has a castle.', 52, 'x', 0)`)
+	if err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	results, err := client.SearchRevisions(ctx, "city", irowiki.RevisionSearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchRevisions failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 ungrouped results, got %d", len(results))
+	}
+
+	// Test: One row per page, the best match, with the page's match count
+	results, err = client.SearchRevisions(ctx, "city", irowiki.RevisionSearchOptions{GroupByPage: true})
+	if err != nil {
+		t.Fatalf("SearchRevisions failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 grouped result, got %d", len(results))
+	}
+	if results[0].RevisionID != 200 {
+		t.Errorf("expected best match revision 200, got %d", results[0].RevisionID)
+	}
+	if results[0].Relevance != 2 {
+		t.Errorf("expected relevance 2, got %f", results[0].Relevance)
+	}
+	if results[0].MatchingRevisions != 3 {
+		t.Errorf("expected MatchingRevisions 3, got %d", results[0].MatchingRevisions)
+	}
+}
+
+// TestSQLiteClient_SearchComments tests searching edit summaries
+func TestSQLiteClient_SearchComments(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`
+		INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, comment, content, size, sha1) VALUES
+			(300, 2, 102, '2024-01-01 00:00:00', 'Editor', 'Added episode 14 quest rewards', 'a', 1, 'a'),
+			(301, 3, 104, '2024-01-02 00:00:00', 'Admin', 'Episode 14: new drops', 'b', 1, 'b'),
+			(302, 3, 301, '2024-01-03 00:00:00', 'Admin', 'Updated episodes list for 14.2', 'c', 1, 'c')`)
+	if err != nil {
+		t.Fatalf("failed to add revisions: %v", err)
+	}
+
+	search := func(query string, opts irowiki.RevisionSearchOptions) []irowiki.RevisionSearchResult {
+		t.Helper()
+		client, err := irowiki.OpenSQLite(tdb.Path)
+		if err != nil {
+			t.Fatalf("failed to open client: %v", err)
+		}
+		defer client.Close()
+
+		results, err := client.SearchComments(context.Background(), query, opts)
+		if err != nil {
+			t.Fatalf("SearchComments failed: %v", err)
+		}
+		return results
+	}
+
+	// Test: Without an index, every term must occur as a substring
+	results := search("episode 14", irowiki.RevisionSearchOptions{})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	results = search("episode 14", irowiki.RevisionSearchOptions{User: "Editor"})
+	if len(results) != 1 || results[0].RevisionID != 300 {
+		t.Fatalf("expected revision 300, got %+v", results)
+	}
+	if results[0].Snippet != "Added <mark>episode</mark> <mark>14</mark> quest rewards" {
+		t.Errorf("unexpected snippet: %s", results[0].Snippet)
+	}
+
+	// Test: The full-text index matches words, with stemming
+	_, err = tdb.DB.Exec(`
+		CREATE VIRTUAL TABLE revision_comments_fts USING fts5(comment, tokenize='porter unicode61');
+		INSERT INTO revision_comments_fts (rowid, comment)
+		SELECT revision_id, comment FROM revisions WHERE comment IS NOT NULL AND comment != ''`)
+	if err != nil {
+		t.Fatalf("failed to index comments: %v", err)
+	}
+	results = search("episodes drop", irowiki.RevisionSearchOptions{})
+	if len(results) != 1 || results[0].RevisionID != 301 || results[0].Title != "Poring" {
+		t.Errorf("expected revision 301 of Poring, got %+v", results)
+	}
+
+	// Test: Revisions matching on the same page can be grouped
+	results = search("episode", irowiki.RevisionSearchOptions{GroupByPage: true})
+	if len(results) != 2 {
+		t.Errorf("expected one result per page, got %+v", results)
+	}
+}
+
+// TestSQLiteClient_SearchRevisions_TimeBudget tests returning partial results
+// with a continuation token when the time budget runs out
+func TestSQLiteClient_SearchRevisions_TimeBudget(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Enough pages for two batches, with a match in the second
+	_, err := tdb.DB.Exec(`
+		WITH RECURSIVE ids(id) AS (SELECT 10 UNION ALL SELECT id + 1 FROM ids WHERE id < 1509)
+		INSERT INTO pages (page_id, namespace, title, is_redirect) SELECT id, 0, 'Filler_' || id, 0 FROM ids`)
+	if err == nil {
+		_, err = tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (2000, 0, 'Wiki_Help', 0)`)
+	}
+	if err == nil {
+		_, err = tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, user, content, size, sha1) VALUES (200, 2000, '2024-01-01 00:00:00', 'Helper', 'How to edit the wiki', 20, 'x')`)
+	}
+	if err != nil {
+		t.Fatalf("failed to insert page: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	// Test: The budget runs out after the first batch
+	results, err := client.SearchRevisions(ctx, "wiki", irowiki.RevisionSearchOptions{TimeBudget: time.Nanosecond})
+	var partial *irowiki.PartialResultError
+	if !errors.As(err, &partial) || !errors.Is(err, irowiki.ErrPartialResult) {
+		t.Fatalf("expected a partial result, got %v", err)
+	}
+	if len(results) != 2 || results[0].RevisionID != 101 {
+		t.Errorf("expected the first batch's 2 matches, got %+v", results)
+	}
+
+	// Test: Continuing scans the rest
+	results, err = client.SearchRevisions(ctx, "wiki", irowiki.RevisionSearchOptions{Continue: partial.Continue, TimeBudget: time.Minute})
+	if err != nil {
+		t.Fatalf("SearchRevisions failed: %v", err)
+	}
+	if len(results) != 1 || results[0].RevisionID != 200 {
+		t.Errorf("expected the second batch's match, got %+v", results)
+	}
+
+	// Test: Without running out, budgeted searches match unbudgeted ones
+	results, err = client.SearchRevisions(ctx, "wiki", irowiki.RevisionSearchOptions{TimeBudget: time.Minute, GroupByPage: true})
+	if err != nil {
+		t.Fatalf("SearchRevisions failed: %v", err)
+	}
+	if len(results) != 2 || results[0].MatchingRevisions+results[1].MatchingRevisions != 3 {
+		t.Errorf("expected 2 pages with 3 matches, got %+v", results)
+	}
+
+	for _, opts := range []irowiki.RevisionSearchOptions{
+		{Continue: "bogus"},
+		{TimeBudget: time.Second, Offset: 10},
+	} {
+		if _, err := client.SearchRevisions(ctx, "wiki", opts); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for %+v, got %v", opts, err)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// sqliteSchema mirrors the migration files in schema/sqlite and is used when
+// the SDK needs to create a new archive (for example, ExtractSubArchive).
+// Keep it in sync with the SQL files when the schema changes; the schema
+// tests compare the two. The optional tables and pages_fts (whose tokenizer
+// is configurable) are created separately.
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS pages (
+		page_id INTEGER PRIMARY KEY,
+		namespace INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL,
+		is_redirect BOOLEAN NOT NULL DEFAULT 0,
+		redirect_target TEXT,
+		wiki_id TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(wiki_id, namespace, title),
+		CHECK(namespace >= 0)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_pages_title ON pages(title)`,
+	`CREATE INDEX IF NOT EXISTS idx_pages_namespace ON pages(namespace)`,
+	`CREATE INDEX IF NOT EXISTS idx_pages_redirect ON pages(is_redirect) WHERE is_redirect = TRUE`,
+	`CREATE INDEX IF NOT EXISTS idx_pages_redirect_target ON pages(redirect_target) WHERE redirect_target IS NOT NULL`,
+
+	`CREATE TABLE IF NOT EXISTS revisions (
+		revision_id INTEGER PRIMARY KEY,
+		page_id INTEGER NOT NULL,
+		parent_id INTEGER,
+		timestamp TIMESTAMP NOT NULL,
+		user TEXT,
+		user_id INTEGER,
+		comment TEXT,
+		content TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		sha1 TEXT NOT NULL,
+		minor BOOLEAN DEFAULT 0,
+		tags TEXT,
+		FOREIGN KEY (page_id) REFERENCES pages(page_id) ON DELETE CASCADE,
+		FOREIGN KEY (parent_id) REFERENCES revisions(revision_id) ON DELETE SET NULL,
+		CHECK(size >= 0)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_rev_page_time ON revisions(page_id, timestamp DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_rev_timestamp ON revisions(timestamp)`,
+	`CREATE INDEX IF NOT EXISTS idx_rev_parent ON revisions(parent_id) WHERE parent_id IS NOT NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_rev_sha1 ON revisions(sha1)`,
+	`CREATE INDEX IF NOT EXISTS idx_rev_user ON revisions(user_id) WHERE user_id IS NOT NULL`,
+
+	`CREATE TABLE IF NOT EXISTS files (
+		filename TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		descriptionurl TEXT NOT NULL,
+		sha1 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		width INTEGER,
+		height INTEGER,
+		mime_type TEXT NOT NULL,
+		timestamp TIMESTAMP NOT NULL,
+		uploader TEXT,
+		CHECK(size >= 0),
+		CHECK(width IS NULL OR width > 0),
+		CHECK(height IS NULL OR height > 0)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_files_sha1 ON files(sha1)`,
+	`CREATE INDEX IF NOT EXISTS idx_files_timestamp ON files(timestamp)`,
+	`CREATE INDEX IF NOT EXISTS idx_files_mime ON files(mime_type)`,
+	`CREATE INDEX IF NOT EXISTS idx_files_uploader ON files(uploader) WHERE uploader IS NOT NULL`,
+
+	`CREATE TABLE IF NOT EXISTS links (
+		source_page_id INTEGER NOT NULL,
+		target_title TEXT NOT NULL,
+		link_type TEXT NOT NULL,
+		UNIQUE(source_page_id, target_title, link_type),
+		CHECK(link_type IN ('page', 'template', 'file', 'category'))
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_links_source ON links(source_page_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_links_target ON links(target_title)`,
+	`CREATE INDEX IF NOT EXISTS idx_links_type ON links(link_type)`,
+	`CREATE INDEX IF NOT EXISTS idx_links_type_target ON links(link_type, target_title)`,
+
+	`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		description TEXT NOT NULL
+	)`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (1, 'Initial schema: pages, revisions, files, links, scrape_metadata')`,
+
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (2, 'Add archive_meta key/value table')`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (3, 'Add external_links with archived snapshot URLs')`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (4, 'Add page_html with wiki-rendered HTML')`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (5, 'Add wikis for sister wikis merged into one archive')`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (6, 'Add interwiki_links mapping pages across sister wikis')`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (7, 'Add deleted_pages tombstones')`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (8, 'Add page_protection levels')`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (9, 'Add pages.redirect_target')`,
+}
+
+// sqliteArchiveMetaSchema creates the archive_meta table. It is kept separate
+// so maintenance operations can record metadata in archives that predate it.
+var sqliteArchiveMetaSchema = []string{
+	`CREATE TABLE IF NOT EXISTS archive_meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+}
+
+// sqliteExternalLinksSchema creates the external_links table. It is kept
+// separate so CheckExternalLinks can add it to archives that predate it.
+var sqliteExternalLinksSchema = []string{
+	`CREATE TABLE IF NOT EXISTS external_links (
+		source_page_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		is_dead BOOLEAN,
+		checked_at TIMESTAMP,
+		archive_url TEXT,
+		archive_timestamp TIMESTAMP,
+		UNIQUE(source_page_id, url)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_extlinks_source ON external_links(source_page_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_extlinks_url ON external_links(url)`,
+	`CREATE INDEX IF NOT EXISTS idx_extlinks_unchecked ON external_links(checked_at) WHERE checked_at IS NULL`,
+}
+
+// sqlitePageHTMLSchema creates the page_html table, which only archives
+// scraped with --capture-html populate.
+var sqlitePageHTMLSchema = []string{
+	`CREATE TABLE IF NOT EXISTS page_html (
+		page_id INTEGER PRIMARY KEY,
+		revision_id INTEGER NOT NULL,
+		html TEXT NOT NULL,
+		fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_page_html_revision ON page_html(revision_id)`,
+}
+
+// sqliteDeletedPagesSchema creates the deleted_pages table, where
+// incremental scrapes record pages deleted from the live wiki.
+var sqliteDeletedPagesSchema = []string{
+	`CREATE TABLE IF NOT EXISTS deleted_pages (
+		page_id INTEGER PRIMARY KEY,
+		deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+	)`,
+}
+
+// sqlitePageProtectionSchema creates the page_protection table, which only
+// archives scraped with --capture-protection populate.
+var sqlitePageProtectionSchema = []string{
+	`CREATE TABLE IF NOT EXISTS page_protection (
+		page_id INTEGER NOT NULL,
+		action TEXT NOT NULL,
+		level TEXT NOT NULL,
+		expiry TEXT NOT NULL DEFAULT 'infinity',
+		fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (page_id, action),
+		FOREIGN KEY (page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+	)`,
+}
+
+// sqliteWikisSchema creates the wikis table, which lists the sister wikis
+// merged into an archive by the scraper's merge-wiki command, and the
+// interwiki_links mapping between them.
+var sqliteWikisSchema = []string{
+	`CREATE TABLE IF NOT EXISTS wikis (
+		wiki_id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		base_url TEXT NOT NULL,
+		api_url TEXT,
+		article_path TEXT,
+		id_offset INTEGER NOT NULL UNIQUE,
+		merged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		CHECK(wiki_id <> ''),
+		CHECK(id_offset > 0)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_pages_wiki ON pages(wiki_id)`,
+	`CREATE TABLE IF NOT EXISTS interwiki_links (
+		source_page_id INTEGER NOT NULL,
+		target_wiki TEXT NOT NULL,
+		target_title TEXT NOT NULL,
+		PRIMARY KEY (source_page_id, target_wiki),
+		FOREIGN KEY (source_page_id) REFERENCES pages(page_id) ON DELETE CASCADE
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_interwiki_target ON interwiki_links(target_wiki, target_title)`,
+}
+
+// sqliteFTSTriggers keep pages_fts in sync with the latest revision of each page.
+// They are created after bulk loads so the initial copy doesn't pay for them per row.
+var sqliteFTSTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS revisions_fts_insert
+	AFTER INSERT ON revisions
+	BEGIN
+		DELETE FROM pages_fts WHERE page_id = NEW.page_id;
+		INSERT INTO pages_fts (page_id, title, content)
+		SELECT p.page_id, p.title, NEW.content
+		FROM pages p
+		WHERE p.page_id = NEW.page_id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS revisions_fts_update
+	AFTER UPDATE ON revisions
+	BEGIN
+		DELETE FROM pages_fts WHERE page_id = NEW.page_id;
+		INSERT INTO pages_fts (page_id, title, content)
+		SELECT p.page_id, p.title, NEW.content
+		FROM pages p
+		WHERE p.page_id = NEW.page_id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS pages_fts_update
+	AFTER UPDATE OF title ON pages
+	BEGIN
+		DELETE FROM pages_fts WHERE page_id = NEW.page_id;
+		INSERT INTO pages_fts (page_id, title, content)
+		SELECT NEW.page_id, NEW.title, r.content
+		FROM revisions r
+		WHERE r.page_id = NEW.page_id
+		ORDER BY r.timestamp DESC
+		LIMIT 1;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS pages_fts_delete
+	AFTER DELETE ON pages
+	BEGIN
+		DELETE FROM pages_fts WHERE page_id = OLD.page_id;
+	END`,
+}
+
+// sqliteCommentFTSSchema creates revision_comments_fts, the full-text index
+// of edit summaries searched by SearchComments. Its rowid is the revision_id.
+var sqliteCommentFTSSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS revision_comments_fts USING fts5(
+		comment,
+		tokenize='porter unicode61'
+	)`,
+	`INSERT OR IGNORE INTO schema_version (version, description)
+	 VALUES (10, 'Add revision_comments_fts edit summary index')`,
+}
+
+// sqliteCommentFTSTriggers keep revision_comments_fts in sync with revisions.
+// Like sqliteFTSTriggers, they are created after bulk loads.
+var sqliteCommentFTSTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS revision_comments_fts_insert
+	AFTER INSERT ON revisions
+	BEGIN
+		DELETE FROM revision_comments_fts WHERE rowid = NEW.revision_id;
+		INSERT INTO revision_comments_fts (rowid, comment)
+		SELECT NEW.revision_id, NEW.comment
+		WHERE NEW.comment IS NOT NULL AND NEW.comment != '';
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS revision_comments_fts_update
+	AFTER UPDATE OF comment ON revisions
+	BEGIN
+		DELETE FROM revision_comments_fts WHERE rowid = OLD.revision_id;
+		INSERT INTO revision_comments_fts (rowid, comment)
+		SELECT NEW.revision_id, NEW.comment
+		WHERE NEW.comment IS NOT NULL AND NEW.comment != '';
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS revision_comments_fts_delete
+	AFTER DELETE ON revisions
+	BEGIN
+		DELETE FROM revision_comments_fts WHERE rowid = OLD.revision_id;
+	END`,
+}
+
+// execStatements runs each statement in order, stopping at the first failure.
+func execStatements(ctx context.Context, db *sql.DB, statements []string) error {
+	for _, stmt := range statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w: failed to execute schema: %v", ErrDatabaseError, err)
+		}
+	}
+	return nil
+}
+
+// queryRower is satisfied by *sql.DB, *sql.Conn and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqliteTableExists reports whether a table exists in the given schema ("main", "temp", ...).
+func sqliteTableExists(ctx context.Context, q queryRower, schema, table string) (bool, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s.sqlite_master WHERE type = 'table' AND name = ?", schema)
+
+	var count int
+	if err := q.QueryRowContext(ctx, query, table).Scan(&count); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,146 @@
+package irowiki_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// scraperTables are the tables only the scraper uses, to resume runs,
+// which archives the SDK creates don't have.
+var scraperTables = map[string]bool{"scrape_runs": true, "scrape_page_status": true}
+
+// describeSchema lists the tables, indexes and triggers of a SQLite
+// database with the columns of each table and index, and the versions in
+// schema_version, in a form that doesn't depend on how the SQL was written.
+func describeSchema(t *testing.T, db *sql.DB) map[string]string {
+	t.Helper()
+
+	ctx := context.Background()
+	rows, err := db.QueryContext(ctx, `
+		SELECT type, name, tbl_name FROM sqlite_master
+		WHERE name NOT LIKE 'sqlite_%'
+		ORDER BY name`)
+	if err != nil {
+		t.Fatalf("failed to list the schema: %v", err)
+	}
+	type object struct{ kind, name, table string }
+	var objects []object
+	for rows.Next() {
+		var o object
+		if err := rows.Scan(&o.kind, &o.name, &o.table); err != nil {
+			t.Fatalf("failed to scan the schema: %v", err)
+		}
+		objects = append(objects, o)
+	}
+	rows.Close()
+
+	describe := func(query string, args ...any) string {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			t.Fatalf("failed to describe %v: %v", args, err)
+		}
+		defer rows.Close()
+		cols, _ := rows.Columns()
+		var out []any
+		for rows.Next() {
+			values := make([]any, len(cols))
+			ptrs := make([]any, len(cols))
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatalf("failed to scan %v: %v", args, err)
+			}
+			out = append(out, values)
+		}
+		return fmt.Sprint(out...)
+	}
+
+	schema := make(map[string]string)
+	for _, o := range objects {
+		if scraperTables[o.table] {
+			continue
+		}
+		key := o.kind + " " + o.name
+		switch o.kind {
+		case "table":
+			schema[key] = describe(`SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?)`, o.name)
+		case "index":
+			partial := describe(`SELECT "unique", partial FROM pragma_index_list(?) WHERE name = ?`, o.table, o.name)
+			schema[key] = o.table + " " + partial + " " + describe(`SELECT name FROM pragma_index_info(?) ORDER BY seqno`, o.name)
+		default:
+			schema[key] = o.table
+		}
+	}
+	schema["schema_version"] = describe(`SELECT version FROM schema_version ORDER BY version`)
+	return schema
+}
+
+// openMigratedArchive creates an archive at path by applying the migration
+// files in schema/sqlite, triggers included, as the scraper does.
+func openMigratedArchive(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	files, err := filepath.Glob("../../schema/sqlite/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("failed to find the migration files: %v", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	for _, file := range files {
+		statements, err := os.ReadFile(file)
+		if err != nil {
+			db.Close()
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if _, err := db.Exec(string(statements)); err != nil {
+			db.Close()
+			t.Fatalf("failed to apply %s: %v", file, err)
+		}
+	}
+	return db
+}
+
+// TestSQLiteSchema_MatchesMigrations tests that archives the SDK creates
+// have the schema the migration files in schema/sqlite create, which the
+// SDK keeps its own copy of
+func TestSQLiteSchema_MatchesMigrations(t *testing.T) {
+	db := openMigratedArchive(t, filepath.Join(t.TempDir(), "migrated.db"))
+	defer db.Close()
+
+	created := filepath.Join(t.TempDir(), "created.db")
+	w, err := irowiki.OpenSQLiteWriter(created)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	sdk, err := sql.Open("sqlite", created)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer sdk.Close()
+
+	want, got := describeSchema(t, db), describeSchema(t, sdk)
+	for name, w := range want {
+		if g, ok := got[name]; !ok {
+			t.Errorf("%s is missing from archives the SDK creates", name)
+		} else if g != w {
+			t.Errorf("%s differs:\n got %s\nwant %s", name, g, w)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s isn't in the migration files", name)
+		}
+	}
+}
@@ -42,6 +42,20 @@ func (opts *SearchOptions) Validate() error {
 		}
 	}
 
+	if opts.Proximity != nil {
+		if err := opts.Proximity.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := validateMatchMode(opts.MatchMode, opts.Query); err != nil {
+		return err
+	}
+
+	if opts.SnippetLength < 0 {
+		return fmt.Errorf("snippet_length must be non-negative")
+	}
+
 	// Validate redirect filters (only one can be true)
 	conflictCount := 0
 	if opts.OnlyRedirects {
@@ -73,6 +87,10 @@ func (opts *SearchOptions) Validate() error {
 		}
 	}
 
+	if err := validateCollation(opts.Collation); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -82,6 +100,9 @@ func (opts *SearchOptions) SetDefaults() {
 		opts.Limit = 20
 	}
 
+	// Archives store titles and text in NFC, as MediaWiki does
+	opts.Query = nfc(opts.Query)
+
 	if opts.SortBy == "" {
 		if opts.Query != "" {
 			opts.SortBy = "relevance"
@@ -110,6 +110,23 @@ func TestSearchOptions_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid sort_order",
 		},
+		{
+			name: "invalid match mode",
+			opts: irowiki.SearchOptions{
+				MatchMode: "fuzzy",
+			},
+			wantErr: true,
+			errMsg:  "invalid match_mode",
+		},
+		{
+			name: "invalid regex",
+			opts: irowiki.SearchOptions{
+				Query:     "Poring(",
+				MatchMode: irowiki.MatchRegex,
+			},
+			wantErr: true,
+			errMsg:  "invalid regex query",
+		},
 	}
 
 	for _, tt := range tests {
@@ -386,6 +403,51 @@ func TestSearch_TitleSearch(t *testing.T) {
 			},
 			wantCount: 1,
 		},
+		{
+			name: "prefix match",
+			opts: irowiki.SearchOptions{
+				Query:     "p",
+				MatchMode: irowiki.MatchPrefix,
+				SortBy:    "title",
+			},
+			wantCount:  2,
+			wantTitles: []string{"Poring", "Prontera"},
+		},
+		{
+			name: "prefix match treats wildcards literally",
+			opts: irowiki.SearchOptions{
+				Query:     "Main%",
+				MatchMode: irowiki.MatchPrefix,
+			},
+			wantCount: 0,
+		},
+		{
+			name: "exact match",
+			opts: irowiki.SearchOptions{
+				Query:     "PORING",
+				MatchMode: irowiki.MatchExact,
+			},
+			wantCount:  1,
+			wantTitles: []string{"Poring"},
+		},
+		{
+			name: "exact match excludes longer titles",
+			opts: irowiki.SearchOptions{
+				Query:     "Main",
+				MatchMode: irowiki.MatchExact,
+			},
+			wantCount: 0,
+		},
+		{
+			name: "regex match",
+			opts: irowiki.SearchOptions{
+				Query:     "^(main|redirect)_",
+				MatchMode: irowiki.MatchRegex,
+				SortBy:    "title",
+			},
+			wantCount:  2,
+			wantTitles: []string{"Main_Page", "Redirect_Test"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -734,3 +796,72 @@ func equalSlices(a, b []string) bool {
 func ptrTime(t time.Time) *time.Time {
 	return &t
 }
+
+// TestSearch_CollapseRedirects tests listing redirects as their target page
+func TestSearch_CollapseRedirects(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`
+		INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES
+			(20, 0, 'Poring_Monster', 1),
+			(21, 0, 'Pink_Monster', 1),
+			(22, 0, 'Monster_Nowhere', 1);
+		INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES
+			(400, 20, '2024-01-01 00:00:00', '#REDIRECT [[poring]]', 20, 'x'),
+			(401, 21, '2024-01-01 00:00:00', '#redirect [[Poring#Drops]]', 26, 'x'),
+			(402, 22, '2024-01-01 00:00:00', '#REDIRECT [[Nowhere]]', 21, 'x')`)
+	if err != nil {
+		t.Fatalf("failed to add redirects: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Redirects to the same page collapse onto it
+	results, err := client.Search(ctx, irowiki.SearchOptions{Query: "Monster", Namespace: -1, CollapseRedirects: true, SortBy: "title"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].Title != "Monster_Nowhere" || results[0].RedirectedFrom != "" {
+		t.Errorf("expected the broken redirect as is, got %+v", results[0])
+	}
+	if results[1].Title != "Poring" || results[1].RedirectedFrom != "Pink_Monster" || results[1].Snippet == "" {
+		t.Errorf("expected Poring redirected from Pink_Monster, got %+v", results[1])
+	}
+
+	// Test: Pages matching themselves aren't marked as redirected
+	results, err = client.Search(ctx, irowiki.SearchOptions{Query: "Poring", Namespace: -1, CollapseRedirects: true})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Poring" || results[0].RedirectedFrom != "" {
+		t.Errorf("expected only Poring, got %+v", results)
+	}
+
+	// Test: Totals count collapsed results
+	paged, err := client.SearchPaged(ctx, irowiki.SearchOptions{Query: "Monster", Namespace: -1, CollapseRedirects: true, IncludeTotalCount: true})
+	if err != nil {
+		t.Fatalf("SearchPaged failed: %v", err)
+	}
+	if paged.Total != 2 {
+		t.Errorf("expected a total of 2, got %d", paged.Total)
+	}
+
+	// Test: Without the option, every redirect is listed
+	results, err = client.Search(ctx, irowiki.SearchOptions{Query: "Monster", Namespace: -1})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("expected 3 results, got %d", len(results))
+	}
+}
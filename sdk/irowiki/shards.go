@@ -0,0 +1,544 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ShardSpec describes one file of a sharded archive.
+type ShardSpec struct {
+	// Name names the shard's file, <name>.db. Names are lowercase letters,
+	// digits, '-' and '_'.
+	Name string
+
+	// Namespaces are the namespaces whose pages the shard holds. The first
+	// shard, the main shard, leaves them empty: it holds the pages of every
+	// namespace the other shards don't list.
+	Namespaces []int
+}
+
+// DefaultShards splits an archive into its main content, File pages and
+// talk pages.
+var DefaultShards = []ShardSpec{
+	{Name: "main"},
+	{Name: "files", Namespaces: []int{6, 7}},
+	{Name: "talk", Namespaces: []int{1, 3, 5, 9, 11, 13, 15}},
+}
+
+// TextShardName is the name of the shard ShardOptions.SeparateText adds.
+const TextShardName = "text"
+
+// maxShards bounds the shards of an archive, which are attached to every
+// connection; SQLite attaches at most 10 databases.
+const maxShards = 8
+
+// shardNamePattern matches valid shard names.
+var shardNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ShardOptions configures Store.ShardArchive.
+type ShardOptions struct {
+	// Shards lists the shard files, the main shard first.
+	// Default: DefaultShards.
+	Shards []ShardSpec
+
+	// SeparateText moves the wikitext of every revision to its own shard,
+	// text.db, so the other shards hold only metadata.
+	SeparateText bool
+}
+
+// Validate checks if the ShardOptions are valid.
+func (o *ShardOptions) Validate() error {
+	if len(o.Shards) > maxShards {
+		return fmt.Errorf("at most %d shards are supported", maxShards)
+	}
+	names := make(map[string]bool)
+	namespaces := make(map[int]string)
+	for i, shard := range o.Shards {
+		if !shardNamePattern.MatchString(shard.Name) {
+			return fmt.Errorf("invalid shard name %q", shard.Name)
+		}
+		if names[shard.Name] || (o.SeparateText && shard.Name == TextShardName) {
+			return fmt.Errorf("duplicate shard name %q", shard.Name)
+		}
+		names[shard.Name] = true
+
+		if i == 0 && len(shard.Namespaces) > 0 {
+			return fmt.Errorf("the main shard %q must not list namespaces", shard.Name)
+		}
+		if i > 0 && len(shard.Namespaces) == 0 {
+			return fmt.Errorf("shard %q must list its namespaces", shard.Name)
+		}
+		for _, ns := range shard.Namespaces {
+			if ns < 0 {
+				return fmt.Errorf("namespace must be non-negative")
+			}
+			if other, dup := namespaces[ns]; dup {
+				return fmt.Errorf("namespace %d is in shards %q and %q", ns, other, shard.Name)
+			}
+			namespaces[ns] = shard.Name
+		}
+	}
+	return nil
+}
+
+// ShardFile summarizes one file written by ShardArchive.
+type ShardFile struct {
+	// Name is the shard's name.
+	Name string `json:"name"`
+
+	// Path is the location of the file.
+	Path string `json:"path"`
+
+	// Pages is the number of pages in the shard.
+	Pages int64 `json:"pages"`
+
+	// Revisions is the number of revisions in the shard, or with their
+	// text in the text shard.
+	Revisions int64 `json:"revisions"`
+}
+
+// ShardResult summarizes a ShardArchive.
+type ShardResult struct {
+	// Shards are the files written, the main shard first and the text
+	// shard last.
+	Shards []ShardFile `json:"shards"`
+}
+
+// shardManifestKey is the archive_meta key of a main shard listing the
+// other files of its archive.
+const shardManifestKey = "shards"
+
+// shardManifest lists the files of a sharded archive, relative to the main
+// shard's directory.
+type shardManifest struct {
+	Shards []string `json:"shards"`
+	Text   string   `json:"text,omitempty"`
+}
+
+// shardTables are the tables split by namespace, with the column holding
+// their rows' page. Every other table lives in the main shard.
+var shardTables = []struct {
+	name, pageColumn string
+}{
+	{"pages", "page_id"},
+	{"revisions", "page_id"},
+	{"links", "source_page_id"},
+	{"external_links", "source_page_id"},
+	{"page_html", "page_id"},
+	{"interwiki_links", "source_page_id"},
+	{"deleted_pages", "page_id"},
+	{"page_protection", "page_id"},
+}
+
+// sqliteRevisionTextSchema creates the text shard's table.
+var sqliteRevisionTextSchema = []string{
+	`CREATE TABLE IF NOT EXISTS revision_text (
+		revision_id INTEGER PRIMARY KEY,
+		content TEXT NOT NULL
+	)`,
+}
+
+// ShardArchive splits the archive into SQLite files in dir, one per shard.
+func (s *sqliteStore) ShardArchive(ctx context.Context, dir string, opts ShardOptions) (*ShardResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if len(opts.Shards) == 0 {
+		opts.Shards = DefaultShards
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	result := &ShardResult{}
+	for _, shard := range opts.Shards {
+		result.Shards = append(result.Shards, ShardFile{Name: shard.Name, Path: filepath.Join(dir, shard.Name+".db")})
+	}
+	if opts.SeparateText {
+		result.Shards = append(result.Shards, ShardFile{Name: TextShardName, Path: filepath.Join(dir, TextShardName+".db")})
+	}
+	for _, f := range result.Shards {
+		if _, err := os.Stat(f.Path); err == nil {
+			return nil, fmt.Errorf("%w: destination %s already exists", ErrInvalidInput, f.Path)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	if err := s.writeShards(ctx, result, opts); err != nil {
+		for _, f := range result.Shards {
+			os.Remove(f.Path)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeShards creates the shard files and copies each table's rows into
+// them.
+func (s *sqliteStore) writeShards(ctx context.Context, result *ShardResult, opts ShardOptions) error {
+	// The main shard is indexed the same way as its source
+	tokenize, err := sqliteFTSTokenize(ctx, s.db, "main")
+	if err != nil {
+		return err
+	}
+	schemas := [][]string{sqliteSchema, sqliteArchiveMetaSchema, sqliteExternalLinksSchema, sqlitePageHTMLSchema, sqliteWikisSchema, sqliteDeletedPagesSchema, sqlitePageProtectionSchema, sqliteCommentFTSSchema, {sqliteFTSTable("main", tokenize)}}
+	for i := range opts.Shards {
+		if err := createSQLiteArchive(ctx, result.Shards[i].Path, schemas...); err != nil {
+			return err
+		}
+	}
+	if opts.SeparateText {
+		if err := createSQLiteArchive(ctx, result.Shards[len(opts.Shards)].Path, sqliteRevisionTextSchema); err != nil {
+			return err
+		}
+	}
+
+	// Tables and columns missing from the source are skipped, as in BulkLoad
+	dest, err := sql.Open("sqlite", result.Shards[0].Path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	plan, err := planBulkLoad(ctx, s.db, func(table string) (map[string]string, error) {
+		return sqliteColumnTypes(ctx, dest, table)
+	})
+	dest.Close()
+	if err != nil {
+		return err
+	}
+
+	if err := s.copyShards(ctx, result, opts, plan); err != nil {
+		return err
+	}
+
+	for i := range opts.Shards {
+		if err := createSQLiteArchive(ctx, result.Shards[i].Path, sqliteFTSTriggers, sqliteCommentFTSTriggers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyShards attaches the shard files to a pinned connection and copies the
+// planned tables into them in one transaction.
+func (s *sqliteStore) copyShards(ctx context.Context, result *ShardResult, opts ShardOptions, plan []bulkTable) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer conn.Close()
+
+	for i, f := range result.Shards {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ATTACH DATABASE ? AS shard%d", i), f.Path); err != nil {
+			return fmt.Errorf("%w: failed to attach shard: %v", ErrDatabaseError, err)
+		}
+		defer conn.ExecContext(context.Background(), fmt.Sprintf("DETACH DATABASE shard%d", i))
+	}
+
+	// Each shard's pages, by namespace
+	var claimed []string
+	conditions := make([]string, len(opts.Shards))
+	for i, shard := range opts.Shards[1:] {
+		var list []string
+		for _, ns := range shard.Namespaces {
+			list = append(list, strconv.Itoa(ns))
+		}
+		conditions[i+1] = "namespace IN (" + strings.Join(list, ", ") + ")"
+		claimed = append(claimed, list...)
+	}
+	conditions[0] = "1 = 1"
+	if len(claimed) > 0 {
+		conditions[0] = "namespace NOT IN (" + strings.Join(claimed, ", ") + ")"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	copyRows := func(query string) (int64, error) {
+		res, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return 0, fmt.Errorf("%w: failed to copy rows: %v", ErrDatabaseError, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		return n, nil
+	}
+
+	for _, table := range plan {
+		columns := `"` + strings.Join(table.columns, `", "`) + `"`
+		pageColumn := ""
+		for _, t := range shardTables {
+			if t.name == table.name {
+				pageColumn = t.pageColumn
+			}
+		}
+
+		switch {
+		case table.name == "files":
+			if _, err := copyRows(`INSERT INTO shard0.files (` + columns + `) SELECT ` + columns + ` FROM main.files`); err != nil {
+				return err
+			}
+		case pageColumn == "":
+			// Every shard keeps the archive's metadata and wikis
+			for i := range opts.Shards {
+				if _, err := copyRows(fmt.Sprintf(`INSERT OR REPLACE INTO shard%d.%s (%s) SELECT %s FROM main.%s`, i, table.name, columns, columns, table.name)); err != nil {
+					return err
+				}
+			}
+		default:
+			selected := columns
+			if table.name == "revisions" && opts.SeparateText {
+				selected = strings.Replace(columns, `"content"`, `'' AS "content"`, 1)
+			}
+			for i := range opts.Shards {
+				where := conditions[i]
+				if table.name != "pages" {
+					where = pageColumn + " IN (SELECT page_id FROM main.pages WHERE " + conditions[i] + ")"
+				}
+				n, err := copyRows(fmt.Sprintf(`INSERT INTO shard%d.%s (%s) SELECT %s FROM main.%s WHERE %s`, i, table.name, columns, selected, table.name, where))
+				if err != nil {
+					return err
+				}
+				switch table.name {
+				case "pages":
+					result.Shards[i].Pages = n
+				case "revisions":
+					result.Shards[i].Revisions = n
+				}
+			}
+		}
+	}
+
+	if opts.SeparateText {
+		text := len(result.Shards) - 1
+		n, err := copyRows(fmt.Sprintf(`INSERT INTO shard%d.revision_text (revision_id, content) SELECT revision_id, content FROM main.revisions`, text))
+		if err != nil {
+			return err
+		}
+		result.Shards[text].Revisions = n
+	}
+
+	// The main shard indexes the whole archive, as full-text searches
+	// can't span files
+	const ftsQuery = `
+		INSERT INTO shard0.pages_fts (page_id, title, content)
+		SELECT p.page_id, p.title,
+		       (SELECT r.content FROM main.revisions r WHERE r.page_id = p.page_id ORDER BY r.timestamp DESC LIMIT 1)
+		FROM main.pages p
+		WHERE EXISTS (SELECT 1 FROM main.revisions r WHERE r.page_id = p.page_id)
+	`
+	if _, err := tx.ExecContext(ctx, ftsQuery); err != nil {
+		return fmt.Errorf("%w: failed to build search index: %v", ErrDatabaseError, err)
+	}
+	const commentFTSQuery = `
+		INSERT INTO shard0.revision_comments_fts (rowid, comment)
+		SELECT revision_id, comment
+		FROM main.revisions
+		WHERE comment IS NOT NULL AND comment != ''
+	`
+	if _, err := tx.ExecContext(ctx, commentFTSQuery); err != nil {
+		return fmt.Errorf("%w: failed to build comment index: %v", ErrDatabaseError, err)
+	}
+
+	manifest := shardManifest{}
+	for _, f := range result.Shards[1:] {
+		if f.Name == TextShardName && opts.SeparateText {
+			manifest.Text = filepath.Base(f.Path)
+		} else {
+			manifest.Shards = append(manifest.Shards, filepath.Base(f.Path))
+		}
+	}
+	value, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	const manifestQuery = `
+		INSERT OR REPLACE INTO shard0.archive_meta (key, value, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+	`
+	if _, err := tx.ExecContext(ctx, manifestQuery, shardManifestKey, string(value)); err != nil {
+		return fmt.Errorf("%w: failed to record shards: %v", ErrDatabaseError, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// ShardArchive splits the archive into SQLite shards.
+func (s *postgresStore) ShardArchive(ctx context.Context, dir string, opts ShardOptions) (*ShardResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: ShardArchive is not implemented for PostgreSQL", ErrNotSupported)
+}
+
+// openSQLiteShards returns db, opened on the SQLite file at path, unless the
+// file is the main shard of a sharded archive. Then it reopens the archive
+// with every connection attaching the other shards, behind temporary views
+// named after the split tables, so queries see the whole archive.
+func openSQLiteShards(ctx context.Context, db *sql.DB, path, dsn string, opts ConnectionOptions) (*sql.DB, error) {
+	exists, err := sqliteTableExists(ctx, db, "main", "archive_meta")
+	if err != nil || !exists {
+		return db, err
+	}
+	var value string
+	err = db.QueryRowContext(ctx, "SELECT value FROM archive_meta WHERE key = ?", shardManifestKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	var manifest shardManifest
+	if err := json.Unmarshal([]byte(value), &manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid shard list %q: %v", ErrDatabaseError, value, err)
+	}
+
+	// Only tables the main shard has are split
+	var tables []string
+	for _, t := range shardTables {
+		exists, err := sqliteTableExists(ctx, db, "main", t.name)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			tables = append(tables, t.name)
+		}
+	}
+	revisionColumns, err := sqliteColumnNames(ctx, db, "revisions")
+	if err != nil {
+		return nil, err
+	}
+
+	var setup []string
+	attach := func(name, alias string) error {
+		file := filepath.Join(filepath.Dir(path), name)
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("%w: missing shard %s: %v", ErrConnectionFailed, file, err)
+		}
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+		uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: "mode=ro"}).String()
+		setup = append(setup, fmt.Sprintf("ATTACH DATABASE '%s' AS %s", strings.ReplaceAll(uri, "'", "''"), alias))
+		return nil
+	}
+	for i, name := range manifest.Shards {
+		if err := attach(name, fmt.Sprintf("shard%d", i+1)); err != nil {
+			return nil, err
+		}
+	}
+	if manifest.Text != "" {
+		// Partial clones leave the text shard out and fetch the text instead
+		_, err := os.Stat(filepath.Join(filepath.Dir(path), manifest.Text))
+		if opts.ContentURL != "" && errors.Is(err, os.ErrNotExist) {
+			manifest.Text = ""
+		} else if err := attach(manifest.Text, "shard_text"); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, table := range tables {
+		union := "SELECT * FROM main." + table
+		for i := range manifest.Shards {
+			union += fmt.Sprintf(" UNION ALL SELECT * FROM shard%d.%s", i+1, table)
+		}
+		if table == "revisions" && manifest.Text != "" && slices.Contains(revisionColumns, "content") {
+			columns := make([]string, len(revisionColumns))
+			for i, column := range revisionColumns {
+				columns[i] = "r." + column
+				if column == "content" {
+					columns[i] = "COALESCE(t.content, r.content) AS content"
+				}
+			}
+			union = "SELECT " + strings.Join(columns, ", ") + " FROM (" + union + ") r LEFT JOIN shard_text.revision_text t ON t.revision_id = r.revision_id"
+		}
+		setup = append(setup, "CREATE TEMP VIEW "+table+" AS "+union)
+	}
+
+	connector := withQueryPlans(&sqliteSetupConnector{driver: db.Driver(), dsn: dsn, setup: setup}, opts, false)
+	db.Close()
+	db = sql.OpenDB(connector)
+	configureSQLitePool(db, opts)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: failed to attach shards: %v", ErrConnectionFailed, err)
+	}
+	return db, nil
+}
+
+// sqliteColumnNames returns the columns of a table in order.
+func sqliteColumnNames(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?) ORDER BY cid", table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return columns, nil
+}
+
+// sqliteSetupConnector opens SQLite connections that run setup statements,
+// such as ATTACH, before they're used.
+type sqliteSetupConnector struct {
+	driver driver.Driver
+	dsn    string
+	setup  []string
+}
+
+// Connect opens a connection and runs the setup statements on it.
+func (c *sqliteSetupConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	exec, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("sqlite driver cannot execute statements")
+	}
+	for _, stmt := range c.setup {
+		if _, err := exec.ExecContext(ctx, stmt, nil); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// Driver returns the underlying driver.
+func (c *sqliteSetupConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,129 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteStore_ShardArchive tests that a sharded archive reads like the original
+func TestSQLiteStore_ShardArchive(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	result, err := store.ShardArchive(ctx, dir, irowiki.ShardOptions{SeparateText: true})
+	if err != nil {
+		t.Fatalf("ShardArchive failed: %v", err)
+	}
+
+	// Main, files, talk and text, with Example.png in the files shard
+	want := []irowiki.ShardFile{
+		{Name: "main", Path: filepath.Join(dir, "main.db"), Pages: 4, Revisions: 6},
+		{Name: "files", Path: filepath.Join(dir, "files.db"), Pages: 1, Revisions: 1},
+		{Name: "talk", Path: filepath.Join(dir, "talk.db")},
+		{Name: "text", Path: filepath.Join(dir, "text.db"), Revisions: 7},
+	}
+	if len(result.Shards) != len(want) {
+		t.Fatalf("expected %d shards, got %+v", len(want), result.Shards)
+	}
+	for i, w := range want {
+		if result.Shards[i] != w {
+			t.Errorf("shard %d: expected %+v, got %+v", i, w, result.Shards[i])
+		}
+	}
+
+	// Test: Opening the main shard attaches the others
+	client, err := irowiki.OpenSQLite(filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatalf("failed to open sharded archive: %v", err)
+	}
+	defer client.Close()
+
+	page, err := client.GetPage(ctx, "Example.png")
+	if err != nil {
+		t.Fatalf("GetPage from the files shard failed: %v", err)
+	}
+	if page.Namespace != 6 || page.Content != "Image file" {
+		t.Errorf("expected Example.png with its text, got %+v", page)
+	}
+
+	history, err := client.GetPageHistory(ctx, "Prontera", irowiki.HistoryOptions{})
+	if err != nil {
+		t.Fatalf("GetPageHistory failed: %v", err)
+	}
+	if len(history) != 2 || history[0].Content == "" {
+		t.Errorf("expected 2 revisions with text, got %+v", history)
+	}
+
+	results, err := client.SearchFullText(ctx, "slime", irowiki.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchFullText failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Poring" {
+		t.Errorf("expected Poring in search results, got %+v", results)
+	}
+
+	// Test: A missing shard fails to open
+	if err := os.Remove(filepath.Join(dir, "text.db")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := irowiki.OpenSQLite(filepath.Join(dir, "main.db")); !errors.Is(err, irowiki.ErrConnectionFailed) {
+		t.Errorf("expected ErrConnectionFailed for a missing shard, got %v", err)
+	}
+}
+
+// TestSQLiteStore_ShardArchive_Invalid tests shard option validation
+func TestSQLiteStore_ShardArchive_Invalid(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		name string
+		opts irowiki.ShardOptions
+	}{
+		{"invalid name", irowiki.ShardOptions{Shards: []irowiki.ShardSpec{{Name: "../main"}}}},
+		{"duplicate name", irowiki.ShardOptions{Shards: []irowiki.ShardSpec{{Name: "main"}, {Name: "main", Namespaces: []int{6}}}}},
+		{"reserved text name", irowiki.ShardOptions{Shards: []irowiki.ShardSpec{{Name: "main"}, {Name: "text", Namespaces: []int{6}}}, SeparateText: true}},
+		{"main with namespaces", irowiki.ShardOptions{Shards: []irowiki.ShardSpec{{Name: "main", Namespaces: []int{0}}}}},
+		{"shard without namespaces", irowiki.ShardOptions{Shards: []irowiki.ShardSpec{{Name: "main"}, {Name: "files"}}}},
+		{"namespace in two shards", irowiki.ShardOptions{Shards: []irowiki.ShardSpec{{Name: "main"}, {Name: "a", Namespaces: []int{6}}, {Name: "b", Namespaces: []int{6}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if _, err := store.ShardArchive(context.Background(), dir, tt.opts); !errors.Is(err, irowiki.ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+
+	// Test: Existing files are not overwritten
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "files.db"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ShardArchive(context.Background(), dir, irowiki.ShardOptions{}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an existing shard, got %v", err)
+	}
+}
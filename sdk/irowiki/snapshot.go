@@ -0,0 +1,91 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// querier runs the queries of a client: on the connection pool, or on the
+// read transaction of a snapshot.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// beginSnapshot starts the read-only transaction of a snapshot. PostgreSQL
+// needs repeatable read for every statement to see the same snapshot;
+// SQLite transactions are always serializable.
+func beginSnapshot(ctx context.Context, pool *sql.DB, isolation sql.IsolationLevel) (*sql.Tx, error) {
+	tx, err := pool.BeginTx(ctx, &sql.TxOptions{Isolation: isolation, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to start snapshot: %v", ErrDatabaseError, err)
+	}
+	return tx, nil
+}
+
+// readSnapshot returns a snapshot of c for a composite operation if
+// ConnectionOptions.SnapshotReads is set, and c otherwise, with a function
+// releasing it.
+func (c *sqliteClient) readSnapshot(ctx context.Context) (*sqliteClient, func(), error) {
+	if !c.opts.SnapshotReads || c.snapshot != nil {
+		return c, func() {}, nil
+	}
+	snapshot, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot.(*sqliteClient), func() { snapshot.Close() }, nil
+}
+
+// readSnapshot returns a snapshot of c for a composite operation if
+// ConnectionOptions.SnapshotReads is set, and c otherwise, with a function
+// releasing it.
+func (c *postgresClient) readSnapshot(ctx context.Context) (*postgresClient, func(), error) {
+	if !c.opts.SnapshotReads || c.snapshot != nil {
+		return c, func() {}, nil
+	}
+	snapshot, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot.(*postgresClient), func() { snapshot.Close() }, nil
+}
+
+// Snapshot returns a client reading from a consistent snapshot of the archive.
+func (c *sqliteClient) Snapshot(ctx context.Context) (Client, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if c.snapshot != nil {
+		return nil, fmt.Errorf("%w: client is already a snapshot", ErrInvalidInput)
+	}
+
+	tx, err := beginSnapshot(ctx, c.pool, sql.LevelDefault)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite defers taking the snapshot to the first read
+	var n int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master").Scan(&n); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: failed to start snapshot: %v", ErrDatabaseError, err)
+	}
+	return &sqliteClient{db: tx, pool: c.pool, opts: c.opts, wiki: c.wiki, snapshot: tx}, nil
+}
+
+// Snapshot returns a client reading from a consistent snapshot of the archive.
+func (c *postgresClient) Snapshot(ctx context.Context) (Client, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if c.snapshot != nil {
+		return nil, fmt.Errorf("%w: client is already a snapshot", ErrInvalidInput)
+	}
+
+	tx, err := beginSnapshot(ctx, c.pool, sql.LevelRepeatableRead)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresClient{db: tx, pool: c.pool, opts: c.opts, wiki: c.wiki, snapshot: tx}, nil
+}
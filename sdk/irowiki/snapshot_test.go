@@ -0,0 +1,75 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_Snapshot tests that snapshots don't see later writes
+func TestSQLiteClient_Snapshot(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// WAL lets the scraper write while snapshots are open
+	if _, err := tdb.DB.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		t.Fatalf("failed to enable WAL: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLiteWithOptions(tdb.Path, irowiki.ConnectionOptions{SnapshotReads: true})
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	snapshot, err := client.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (10, 0, 'Geffen', 0)`); err != nil {
+		t.Fatalf("failed to add page: %v", err)
+	}
+
+	// Test: The snapshot reads the archive as it was
+	if _, err := snapshot.GetPage(ctx, "Geffen"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected snapshot not to see the new page, got %v", err)
+	}
+	stats, err := snapshot.GetStatistics(ctx)
+	if err != nil {
+		t.Fatalf("GetStatistics failed: %v", err)
+	}
+	if stats.TotalPages != 5 {
+		t.Errorf("expected 5 pages in the snapshot, got %d", stats.TotalPages)
+	}
+
+	// Test: Snapshots can't be nested
+	if _, err := snapshot.Snapshot(ctx); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+
+	// Test: Closing the snapshot leaves the client open
+	if err := snapshot.Close(); err != nil {
+		t.Errorf("failed to close snapshot: %v", err)
+	}
+	if _, err := snapshot.GetPage(ctx, "Poring"); !errors.Is(err, irowiki.ErrClosed) {
+		t.Errorf("expected ErrClosed from closed snapshot, got %v", err)
+	}
+
+	// Test: The client sees the write, including through SnapshotReads
+	if _, err := client.GetPage(ctx, "Geffen"); err != nil {
+		t.Errorf("GetPage failed: %v", err)
+	}
+	stats, err = client.GetStatistics(ctx)
+	if err != nil {
+		t.Fatalf("GetStatistics failed: %v", err)
+	}
+	if stats.TotalPages != 6 {
+		t.Errorf("expected 6 pages, got %d", stats.TotalPages)
+	}
+}
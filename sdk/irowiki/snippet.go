@@ -0,0 +1,193 @@
+package irowiki
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultSnippetLength is the snippet length used when SearchOptions.SnippetLength is 0.
+	defaultSnippetLength = 200
+
+	// snippetOpen and snippetClose wrap highlighted terms, matching the FTS5 snippet() markers.
+	snippetOpen  = "<mark>"
+	snippetClose = "</mark>"
+
+	// snippetEllipsis marks text trimmed from either end of a snippet.
+	snippetEllipsis = "..."
+)
+
+// snippetOperators are FTS query keywords that are not search terms.
+var snippetOperators = map[string]bool{
+	"AND":  true,
+	"OR":   true,
+	"NOT":  true,
+	"NEAR": true,
+}
+
+// snippetTerms splits a search query into the terms to highlight.
+// Quotes, wildcards, column filters and FTS operators are stripped.
+func snippetTerms(query string) []string {
+	fields := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-' && r != ':'
+	})
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, f := range fields {
+		// Drop column filters ("title:poring" -> "poring") and stray punctuation
+		if i := strings.LastIndex(f, ":"); i >= 0 {
+			f = f[i+1:]
+		}
+		f = strings.Trim(f, "'-")
+		if f == "" || snippetOperators[f] {
+			continue
+		}
+		key := strings.ToLower(f)
+		if !seen[key] {
+			seen[key] = true
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+// snippetPattern compiles a case-insensitive pattern matching any of terms.
+// Longer terms are tried first so "poring" wins over "por".
+func snippetPattern(terms []string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	for i := 1; i < len(quoted); i++ {
+		for j := i; j > 0 && len(quoted[j]) > len(quoted[j-1]); j-- {
+			quoted[j], quoted[j-1] = quoted[j-1], quoted[j]
+		}
+	}
+
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
+
+// buildSnippet extracts a query-aware snippet from content: the sentence
+// containing the first match, trimmed to maxLen bytes around the match at
+// word boundaries, with every query term wrapped in <mark> tags.
+// If no term occurs in content, the snippet is taken from the beginning.
+func buildSnippet(content, query string, maxLen int) string {
+	if maxLen <= 0 {
+		maxLen = defaultSnippetLength
+	}
+
+	content = strings.Join(strings.Fields(content), " ")
+	if content == "" {
+		return ""
+	}
+
+	pattern := snippetPattern(snippetTerms(query))
+
+	matchStart, matchEnd := 0, 0
+	if pattern != nil {
+		if loc := pattern.FindStringIndex(content); loc != nil {
+			matchStart, matchEnd = loc[0], loc[1]
+		}
+	}
+
+	start := sentenceStart(content, matchStart)
+	end := sentenceEnd(content, matchEnd)
+
+	// Sentence too long: center a maxLen window on the match instead
+	if end-start > maxLen {
+		pad := (maxLen - (matchEnd - matchStart)) / 2
+		if pad < 0 {
+			pad = 0
+		}
+		winStart := matchStart - pad
+		if winStart < start {
+			winStart = start
+		}
+		winEnd := winStart + maxLen
+		if winEnd > end {
+			winEnd = end
+			if winEnd-maxLen > start {
+				winStart = winEnd - maxLen
+			}
+		}
+		if winStart > start {
+			start = wordStartAfter(content, winStart, matchStart)
+		}
+		if winEnd < end {
+			end = wordEndBefore(content, winEnd, matchEnd)
+		}
+	}
+
+	snippet := content[start:end]
+	if pattern != nil {
+		snippet = pattern.ReplaceAllString(snippet, snippetOpen+"$0"+snippetClose)
+	}
+
+	if start > 0 {
+		snippet = snippetEllipsis + snippet
+	}
+	if end < len(content) {
+		snippet += snippetEllipsis
+	}
+	return snippet
+}
+
+// sentenceStart returns the start of the sentence containing pos.
+func sentenceStart(content string, pos int) int {
+	for i := pos - 1; i > 0; i-- {
+		if content[i] == ' ' && isSentenceEnd(content[i-1]) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// sentenceEnd returns the end of the sentence containing pos (after its terminator).
+func sentenceEnd(content string, pos int) int {
+	for i := pos; i < len(content); i++ {
+		if isSentenceEnd(content[i]) && (i+1 == len(content) || content[i+1] == ' ') {
+			return i + 1
+		}
+	}
+	return len(content)
+}
+
+// isSentenceEnd reports whether b terminates a sentence.
+func isSentenceEnd(b byte) bool {
+	return b == '.' || b == '!' || b == '?'
+}
+
+// wordStartAfter moves pos forward to the start of the next word, never past limit.
+func wordStartAfter(content string, pos, limit int) int {
+	for i := pos; i < limit; i++ {
+		if content[i] == ' ' {
+			return i + 1
+		}
+	}
+	return alignRuneStart(content, pos)
+}
+
+// wordEndBefore moves pos back to the end of the previous word, never before limit.
+func wordEndBefore(content string, pos, limit int) int {
+	for i := pos; i > limit; i-- {
+		if content[i] == ' ' {
+			return i
+		}
+	}
+	return alignRuneStart(content, pos)
+}
+
+// alignRuneStart moves pos back to the start of a UTF-8 rune.
+func alignRuneStart(content string, pos int) int {
+	for pos > 0 && pos < len(content) && !utf8.RuneStart(content[pos]) {
+		pos--
+	}
+	return pos
+}
@@ -0,0 +1,110 @@
+package irowiki_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_Search_Snippet tests query-aware snippets for title search
+func TestSQLiteClient_Search_Snippet(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	long := "Prontera is the capital city of the Rune-Midgarts Kingdom. " +
+		"Many adventurers start their journey here. " +
+		"The Prontera Castle sits at the north end of the city, and the Kafra Corporation " +
+		"headquarters can be found nearby along with several shops selling potions, " +
+		"weapons and armor for novice adventurers of every class and level."
+	_, err := tdb.DB.Exec("UPDATE revisions SET content = ? WHERE revision_id = 103", long)
+	if err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Matched terms are highlighted within their sentence
+	results, err := client.Search(ctx, irowiki.SearchOptions{Query: "poring", Namespace: -1})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].Snippet != "<mark>Poring</mark> is a pink slime monster." {
+		t.Errorf("unexpected snippet: %q", results[0].Snippet)
+	}
+
+	// Test: Long sentences are trimmed around the match at word boundaries
+	results, err = client.Search(ctx, irowiki.SearchOptions{Query: "Prontera", Namespace: -1, SnippetLength: 80})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	snippet := results[0].Snippet
+	if !strings.HasPrefix(snippet, "<mark>Prontera</mark> is the capital city") {
+		t.Errorf("expected snippet to start at the first match, got %q", snippet)
+	}
+	if !strings.HasSuffix(snippet, "...") {
+		t.Errorf("expected trailing ellipsis, got %q", snippet)
+	}
+	if strings.Contains(snippet, "adventurers") {
+		t.Errorf("expected snippet to stop at the first sentence, got %q", snippet)
+	}
+
+	// Test: Negative snippet length is rejected
+	_, err = client.Search(ctx, irowiki.SearchOptions{Query: "Prontera", SnippetLength: -1})
+	if err == nil {
+		t.Error("expected error for negative snippet length")
+	}
+}
+
+// TestSQLiteClient_Search_SnippetWindow tests snippets centered on a match deep in a long sentence
+func TestSQLiteClient_Search_SnippetWindow(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	content := "Intro. " + strings.Repeat("filler words go here ", 20) + "the Poring drops jellopy " + strings.Repeat("and more filler text ", 20) + "end."
+	_, err := tdb.DB.Exec("UPDATE revisions SET content = ? WHERE revision_id = 104", content)
+	if err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	results, err := client.Search(context.Background(), irowiki.SearchOptions{Query: "Poring", Namespace: -1, SnippetLength: 60})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	snippet := results[0].Snippet
+	if !strings.HasPrefix(snippet, "...") || !strings.HasSuffix(snippet, "...") {
+		t.Errorf("expected ellipses on both ends, got %q", snippet)
+	}
+	if !strings.Contains(snippet, "<mark>Poring</mark> drops jellopy") {
+		t.Errorf("expected highlighted match, got %q", snippet)
+	}
+	plain := strings.NewReplacer("<mark>", "", "</mark>", "", "...", "").Replace(snippet)
+	if len(plain) > 60 {
+		t.Errorf("expected snippet text within 60 bytes, got %d: %q", len(plain), plain)
+	}
+}
@@ -0,0 +1,408 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TitleSuggestions is the result of SuggestTitles.
+type TitleSuggestions struct {
+	// Titles are page titles completing the input, best first.
+	Titles []string `json:"titles"`
+
+	// Correction is the input with misspelled words corrected ("porring" -> "poring"),
+	// set when the input has no completions but a corrected spelling does.
+	// Titles then complete the corrected input.
+	Correction string `json:"correction,omitempty"`
+}
+
+// spellCandidate is a known word that a misspelling may be corrected to.
+type spellCandidate struct {
+	// freq is the number of documents (or titles) containing the word.
+	freq int64
+
+	// title is set if the word appears in a page title; title words are
+	// real spellings, while FTS vocabulary may contain stems.
+	title bool
+}
+
+// spellVocabulary maps lower-cased words to candidate information.
+type spellVocabulary map[string]*spellCandidate
+
+// add records a word occurrence.
+func (v spellVocabulary) add(word string, freq int64, title bool) {
+	c, ok := v[word]
+	if !ok {
+		c = &spellCandidate{}
+		v[word] = c
+	}
+	c.freq += freq
+	c.title = c.title || title
+}
+
+// addTitle records every word of a page title.
+func (v spellVocabulary) addTitle(title string) {
+	for _, w := range spellWords(strings.ReplaceAll(title, "_", " ")) {
+		v.add(w, 1, true)
+	}
+}
+
+// spellWords splits text into lower-cased words.
+func spellWords(text string) []string {
+	return spellWordPattern.FindAllString(strings.ToLower(text), -1)
+}
+
+// maxEditDistance is the largest correction allowed for a word of n runes.
+func maxEditDistance(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n <= 4:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance computes the optimal string alignment (Damerau-Levenshtein)
+// distance between a and b, giving up once it exceeds limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+// correctWord returns the best correction for word, or "" if none is close enough.
+// Candidates are ranked by edit distance, then title words before index-only
+// terms, then frequency.
+func correctWord(word string, vocab spellVocabulary) string {
+	maxDist := maxEditDistance(utf8.RuneCountInString(word))
+	if maxDist == 0 {
+		return ""
+	}
+
+	best := ""
+	bestDist := maxDist + 1
+	var bestCand *spellCandidate
+
+	for term, cand := range vocab {
+		d := editDistance(word, term, maxDist)
+		if d == 0 || d > maxDist {
+			continue
+		}
+
+		better := d < bestDist
+		if d == bestDist && bestCand != nil {
+			switch {
+			case cand.title != bestCand.title:
+				better = cand.title
+			case cand.freq != bestCand.freq:
+				better = cand.freq > bestCand.freq
+			default:
+				better = term < best
+			}
+		}
+
+		if better {
+			best, bestDist, bestCand = term, d, cand
+		}
+	}
+
+	return best
+}
+
+// spellWordPattern matches the words of a query.
+var spellWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// correctQuery replaces each unknown word in query with its best correction,
+// keeping operators, punctuation and the capitalization of the first letter.
+// known reports whether a word already matches something in the archive.
+// Returns "" if nothing was corrected.
+func correctQuery(query string, vocab spellVocabulary, known func(word string) (bool, error)) (string, error) {
+	var b strings.Builder
+	last := 0
+	changed := false
+
+	for _, loc := range spellWordPattern.FindAllStringIndex(query, -1) {
+		original := query[loc[0]:loc[1]]
+		if snippetOperators[original] {
+			continue
+		}
+
+		w := strings.ToLower(original)
+		if c, ok := vocab[w]; ok && c.title {
+			continue
+		}
+
+		ok, err := known(w)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			continue
+		}
+
+		fix := correctWord(w, vocab)
+		if fix == "" {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(original); unicode.IsUpper(r) {
+			first, size := utf8.DecodeRuneInString(fix)
+			fix = string(unicode.ToUpper(first)) + fix[size:]
+		}
+
+		b.WriteString(query[last:loc[0]])
+		b.WriteString(fix)
+		last = loc[1]
+		changed = true
+	}
+
+	if !changed {
+		return "", nil
+	}
+	b.WriteString(query[last:])
+	return b.String(), nil
+}
+
+// titleVocabulary builds a vocabulary from rows of page titles.
+func titleVocabulary(rows *sql.Rows) (spellVocabulary, error) {
+	defer rows.Close()
+
+	vocab := make(spellVocabulary)
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		vocab.addTitle(title)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return vocab, nil
+}
+
+// sqliteSpellVocabulary loads title words plus FTS vocabulary terms close in
+// length to the words being corrected. Trigram indexes have no word vocabulary,
+// so only titles are used for them.
+func sqliteSpellVocabulary(ctx context.Context, conn *sql.Conn, scope wikiScope, words []string, trigram bool) (spellVocabulary, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT title FROM pages WHERE 1=1"+scope.filter("pages"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	vocab, err := titleVocabulary(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if trigram || len(words) == 0 {
+		return vocab, nil
+	}
+
+	// fts5vocab tables are per-connection, so the caller pins one
+	const createVocab = "CREATE VIRTUAL TABLE IF NOT EXISTS temp.pages_fts_vocab USING fts5vocab(main, pages_fts, row)"
+	if _, err := conn.ExecContext(ctx, createVocab); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	minLen, maxLen := utf8.RuneCountInString(words[0]), 0
+	for _, w := range words {
+		n := utf8.RuneCountInString(w)
+		minLen = min(minLen, n)
+		maxLen = max(maxLen, n)
+	}
+
+	rows, err = conn.QueryContext(ctx,
+		"SELECT term, doc FROM temp.pages_fts_vocab WHERE length(term) BETWEEN ? AND ?",
+		minLen-2, maxLen+2,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var term string
+		var doc int64
+		if err := rows.Scan(&term, &doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		vocab.add(term, doc, false)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	return vocab, nil
+}
+
+// suggestCorrection returns a corrected spelling of query, or "" if every word is known.
+func (c *sqliteClient) suggestCorrection(ctx context.Context, query string) (string, error) {
+	words := spellWords(query)
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	conn, err := c.pool.Conn(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer conn.Close()
+
+	tokenize, err := sqliteFTSTokenize(ctx, conn, "main")
+	if err != nil {
+		return "", err
+	}
+	trigram := isTrigramTokenize(tokenize)
+
+	vocab, err := sqliteSpellVocabulary(ctx, conn, c.wiki, words, trigram)
+	if err != nil {
+		return "", err
+	}
+
+	// A word is known if the index matches it, which accounts for stemming
+	known := func(word string) (bool, error) {
+		if trigram && utf8.RuneCountInString(word) < 3 {
+			return true, nil
+		}
+		var one int
+		err := conn.QueryRowContext(ctx,
+			"SELECT 1 FROM pages_fts WHERE pages_fts MATCH ? LIMIT 1",
+			`"`+strings.ReplaceAll(word, `"`, `""`)+`"`,
+		).Scan(&one)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		return true, nil
+	}
+
+	return correctQuery(query, vocab, known)
+}
+
+// SuggestTitles returns page titles starting with prefix (case-insensitive).
+// If nothing matches, the prefix is spell-corrected and completions for the
+// corrected spelling are returned along with the correction.
+func (c *sqliteClient) SuggestTitles(ctx context.Context, prefix string, limit int) (*TitleSuggestions, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	return suggestTitles(ctx, prefix, limit, c.titlesWithPrefix, c.suggestCorrection)
+}
+
+// suggestTitles implements SuggestTitles on top of a backend's prefix lookup and spelling correction.
+func suggestTitles(
+	ctx context.Context,
+	prefix string,
+	limit int,
+	lookup func(ctx context.Context, prefix string, limit int) ([]string, error),
+	correct func(ctx context.Context, query string) (string, error),
+) (*TitleSuggestions, error) {
+	prefix = nfc(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix cannot be empty", ErrInvalidInput)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: limit must be non-negative", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = 10
+	}
+
+	titles, err := lookup(ctx, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := &TitleSuggestions{Titles: titles}
+	if len(titles) > 0 {
+		return suggestions, nil
+	}
+
+	correction, err := correct(ctx, prefix)
+	if err != nil || correction == "" {
+		return suggestions, err
+	}
+
+	titles, err = lookup(ctx, correction, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(titles) > 0 {
+		suggestions.Titles = titles
+		suggestions.Correction = correction
+	}
+
+	return suggestions, nil
+}
+
+// titlesWithPrefix returns up to limit titles starting with prefix, shortest first.
+func (c *sqliteClient) titlesWithPrefix(ctx context.Context, prefix string, limit int) ([]string, error) {
+	query := `
+		SELECT title FROM pages
+		WHERE LOWER(REPLACE(title, '_', ' ')) LIKE ? ESCAPE '\'` + c.wiki.filter("pages") + `
+		ORDER BY is_redirect, LENGTH(title), title
+		LIMIT ?
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, escapeLike(strings.ToLower(strings.ReplaceAll(prefix, "_", " ")))+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	titles := []string{}
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		titles = append(titles, title)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return titles, nil
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally (with ESCAPE '\').
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
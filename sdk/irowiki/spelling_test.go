@@ -0,0 +1,120 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_SuggestTitles tests title completion with spelling correction
+func TestSQLiteClient_SuggestTitles(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Prefix matches are case-insensitive and need no correction
+	suggestions, err := client.SuggestTitles(ctx, "pron", 0)
+	if err != nil {
+		t.Fatalf("SuggestTitles failed: %v", err)
+	}
+	if len(suggestions.Titles) != 1 || suggestions.Titles[0] != "Prontera" {
+		t.Errorf("expected [Prontera], got %v", suggestions.Titles)
+	}
+	if suggestions.Correction != "" {
+		t.Errorf("expected no correction, got '%s'", suggestions.Correction)
+	}
+
+	// Test: Misspellings are corrected from the archive vocabulary
+	suggestions, err = client.SuggestTitles(ctx, "porring", 0)
+	if err != nil {
+		t.Fatalf("SuggestTitles failed: %v", err)
+	}
+	if suggestions.Correction != "poring" {
+		t.Errorf("expected correction 'poring', got '%s'", suggestions.Correction)
+	}
+	if len(suggestions.Titles) != 1 || suggestions.Titles[0] != "Poring" {
+		t.Errorf("expected [Poring], got %v", suggestions.Titles)
+	}
+
+	// Test: Transpositions count as a single edit, and capitalization is kept
+	suggestions, err = client.SuggestTitles(ctx, "Pornog", 0)
+	if err != nil {
+		t.Fatalf("SuggestTitles failed: %v", err)
+	}
+	if suggestions.Correction != "Poring" {
+		t.Errorf("expected correction 'Poring', got '%s'", suggestions.Correction)
+	}
+
+	// Test: Unrelated input yields nothing
+	suggestions, err = client.SuggestTitles(ctx, "zzzzzz", 0)
+	if err != nil {
+		t.Fatalf("SuggestTitles failed: %v", err)
+	}
+	if len(suggestions.Titles) != 0 || suggestions.Correction != "" {
+		t.Errorf("expected no suggestions, got %+v", suggestions)
+	}
+
+	// Test: Empty prefix is rejected
+	_, err = client.SuggestTitles(ctx, "  ", 0)
+	if !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for empty prefix, got %v", err)
+	}
+}
+
+// TestSQLiteClient_SearchPaged tests paginated search metadata and spelling suggestions
+func TestSQLiteClient_SearchPaged(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: HasMore and Total reflect results beyond the page
+	paged, err := client.SearchPaged(ctx, irowiki.SearchOptions{
+		Query:             "a",
+		Limit:             1,
+		IncludeTotalCount: true,
+	})
+	if err != nil {
+		t.Fatalf("SearchPaged failed: %v", err)
+	}
+	if len(paged.Results) != 1 {
+		t.Errorf("expected 1 result, got %d", len(paged.Results))
+	}
+	if !paged.HasMore {
+		t.Error("expected HasMore")
+	}
+	if paged.Total < 2 {
+		t.Errorf("expected Total >= 2, got %d", paged.Total)
+	}
+	if paged.Suggestion != "" {
+		t.Errorf("expected no suggestion, got '%s'", paged.Suggestion)
+	}
+
+	// Test: A misspelled query with no results suggests a correction
+	paged, err = client.SearchPaged(ctx, irowiki.SearchOptions{Query: "porring"})
+	if err != nil {
+		t.Fatalf("SearchPaged failed: %v", err)
+	}
+	if len(paged.Results) != 0 {
+		t.Errorf("expected no results, got %d", len(paged.Results))
+	}
+	if paged.Suggestion != "poring" {
+		t.Errorf("expected suggestion 'poring', got '%s'", paged.Suggestion)
+	}
+}
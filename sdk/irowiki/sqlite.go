@@ -13,10 +13,19 @@ import (
 
 // sqliteClient implements the Client interface for SQLite databases.
 type sqliteClient struct {
-	db     *sql.DB
+	db     querier
+	pool   *sql.DB
 	opts   ConnectionOptions
+	wiki   wikiScope
 	closed bool
 	mu     sync.RWMutex
+
+	// cleanup, if set, runs after the database is closed, such as to
+	// remove the temporary copy made by OpenSQLiteFS.
+	cleanup func() error
+
+	// snapshot, if set, is the read transaction db runs in (see Snapshot).
+	snapshot *sql.Tx
 }
 
 // OpenSQLite opens a SQLite database at the specified path with default options.
@@ -47,12 +56,14 @@ func OpenSQLiteWithOptions(path string, opts ConnectionOptions) (Client, error)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to open database: %v", ErrConnectionFailed, err)
 	}
+	if opts.ExplainThreshold > 0 {
+		connector := withQueryPlans(&sqliteSetupConnector{driver: db.Driver(), dsn: dsn}, opts, false)
+		db.Close()
+		db = sql.OpenDB(connector)
+	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(opts.MaxOpenConns)
-	db.SetMaxIdleConns(opts.MaxIdleConns)
-	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
-	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	configureSQLitePool(db, opts)
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), opts.ConnectTimeout)
@@ -63,15 +74,45 @@ func OpenSQLiteWithOptions(path string, opts ConnectionOptions) (Client, error)
 		return nil, fmt.Errorf("%w: failed to ping database: %v", ErrConnectionFailed, err)
 	}
 
+	// The main shard of a sharded archive attaches the others
+	if path != ":memory:" {
+		sharded, err := openSQLiteShards(ctx, db, path, dsn, opts)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		db = sharded
+	}
+
+	wiki, err := openSQLiteWikiScope(ctx, db, opts.WikiID)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	wiki.deleted = newDeletedMode(opts.IncludeDeleted, opts.OnlyDeleted)
+
 	client := &sqliteClient{
 		db:     db,
+		pool:   db,
 		opts:   opts,
+		wiki:   wiki,
 		closed: false,
 	}
 
+	if opts.ContentURL != "" {
+		return openPartialClone(client, opts)
+	}
 	return client, nil
 }
 
+// configureSQLitePool applies the pool settings of opts to db.
+func configureSQLitePool(db *sql.DB, opts ConnectionOptions) {
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+}
+
 // ensureNotClosed checks if the client is closed and returns an error if it is.
 func (c *sqliteClient) ensureNotClosed() error {
 	c.mu.RLock()
@@ -84,29 +125,40 @@ func (c *sqliteClient) ensureNotClosed() error {
 }
 
 // GetPage retrieves the latest version of a page by title.
-func (c *sqliteClient) GetPage(ctx context.Context, title string) (*Page, error) {
+func (c *sqliteClient) GetPage(ctx context.Context, title string, opts ...PageOption) (*Page, error) {
+	return getPageWithOptions(ctx, title, opts, c.getPage)
+}
+
+// getPage retrieves the latest version of a page by title. Without content,
+// the wikitext isn't loaded; redirects get their target from the query.
+func (c *sqliteClient) getPage(ctx context.Context, title string, content bool) (*Page, error) {
 	if err := c.ensureNotClosed(); err != nil {
 		return nil, err
 	}
 
-	const query = `
-		SELECT p.page_id, p.namespace, p.title, p.is_redirect,
-		       r.revision_id, r.timestamp, r.user, r.comment, r.content
+	contentColumn, targetColumn := "r.content", "NULL"
+	if !content {
+		contentColumn, targetColumn = "NULL", c.wiki.redirectTargetColumn("p", sqliteParseTarget)
+	}
+	cond, order, args := titleCondition("p", title, 1, func(int) string { return "?" })
+	query := `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, ` + c.wiki.deletedColumn("p") + `,
+		       r.revision_id, r.timestamp, r.user, r.comment, ` + contentColumn + `, ` + targetColumn + `
 		FROM pages p
 		LEFT JOIN revisions r ON p.page_id = r.page_id
-		WHERE p.title = ?
-		ORDER BY r.timestamp DESC
+		WHERE ` + cond + c.wiki.filter("p") + `
+		ORDER BY ` + order + `, r.timestamp DESC
 		LIMIT 1
 	`
 
 	var page Page
 	var revID sql.NullInt64
 	var timestamp sql.NullTime
-	var user, comment, content sql.NullString
+	var user, comment, text, target sql.NullString
 
-	err := c.db.QueryRowContext(ctx, query, title).Scan(
-		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect,
-		&revID, &timestamp, &user, &comment, &content,
+	err := c.db.QueryRowContext(ctx, query, args...).Scan(
+		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &page.Deleted,
+		&revID, &timestamp, &user, &comment, &text, &target,
 	)
 
 	if err == sql.ErrNoRows {
@@ -129,8 +181,15 @@ func (c *sqliteClient) GetPage(ctx context.Context, title string) (*Page, error)
 	if comment.Valid {
 		page.Comment = comment.String
 	}
-	if content.Valid {
-		page.Content = content.String
+	if text.Valid {
+		page.Content = text.String
+	}
+	if target.Valid {
+		page.RedirectTarget = target.String
+	}
+
+	if err := c.addPageHints(ctx, &page); err != nil {
+		return nil, err
 	}
 
 	return &page, nil
@@ -142,8 +201,8 @@ func (c *sqliteClient) GetPageByID(ctx context.Context, id int64) (*Page, error)
 		return nil, err
 	}
 
-	const query = `
-		SELECT p.page_id, p.namespace, p.title, p.is_redirect,
+	query := `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, ` + c.wiki.deletedColumn("p") + `,
 		       r.revision_id, r.timestamp, r.user, r.comment, r.content
 		FROM pages p
 		LEFT JOIN revisions r ON p.page_id = r.page_id
@@ -158,7 +217,7 @@ func (c *sqliteClient) GetPageByID(ctx context.Context, id int64) (*Page, error)
 	var user, comment, content sql.NullString
 
 	err := c.db.QueryRowContext(ctx, query, id).Scan(
-		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect,
+		&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &page.Deleted,
 		&revID, &timestamp, &user, &comment, &content,
 	)
 
@@ -186,6 +245,10 @@ func (c *sqliteClient) GetPageByID(ctx context.Context, id int64) (*Page, error)
 		page.Content = content.String
 	}
 
+	if err := c.addPageHints(ctx, &page); err != nil {
+		return nil, err
+	}
+
 	return &page, nil
 }
 
@@ -199,8 +262,8 @@ func (c *sqliteClient) ListPages(ctx context.Context, namespace int, offset, lim
 		limit = 100
 	}
 
-	const query = `
-		SELECT p.page_id, p.namespace, p.title, p.is_redirect,
+	query := `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, ` + c.wiki.deletedColumn("p") + `,
 		       r.revision_id, r.timestamp, r.user, r.comment, r.content
 		FROM pages p
 		LEFT JOIN (
@@ -208,7 +271,7 @@ func (c *sqliteClient) ListPages(ctx context.Context, namespace int, offset, lim
 			       ROW_NUMBER() OVER (PARTITION BY page_id ORDER BY timestamp DESC) as rn
 			FROM revisions
 		) r ON p.page_id = r.page_id AND r.rn = 1
-		WHERE p.namespace = ?
+		WHERE p.namespace = ?` + c.wiki.filter("p") + `
 		ORDER BY p.page_id
 		LIMIT ? OFFSET ?
 	`
@@ -227,7 +290,7 @@ func (c *sqliteClient) ListPages(ctx context.Context, namespace int, offset, lim
 		var user, comment, content sql.NullString
 
 		err := rows.Scan(
-			&page.ID, &page.Namespace, &page.Title, &page.IsRedirect,
+			&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &page.Deleted,
 			&revID, &timestamp, &user, &comment, &content,
 		)
 		if err != nil {
@@ -251,6 +314,7 @@ func (c *sqliteClient) ListPages(ctx context.Context, namespace int, offset, lim
 			page.Content = content.String
 		}
 
+		classifyPage(&page)
 		pages = append(pages, page)
 	}
 
@@ -273,6 +337,103 @@ func (c *sqliteClient) Search(ctx context.Context, opts SearchOptions) ([]Search
 	}
 	opts.SetDefaults()
 
+	return c.searchTitles(ctx, opts)
+}
+
+// SearchPaged performs a title search and returns the results with pagination metadata.
+// If the query matches nothing, Suggestion holds a spell-corrected query that does.
+func (c *sqliteClient) SearchPaged(ctx context.Context, opts SearchOptions) (*PagedResult, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
+	}
+	opts.SetDefaults()
+
+	// Fetch one extra result to determine HasMore without counting
+	fetch := opts
+	fetch.Limit++
+	results, err := c.searchTitles(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	paged := &PagedResult{
+		Results: results,
+		Offset:  opts.Offset,
+		Limit:   opts.Limit,
+	}
+	if len(results) > opts.Limit {
+		paged.Results = results[:opts.Limit]
+		paged.HasMore = true
+	}
+
+	if opts.IncludeTotalCount {
+		total, err := c.countSearchResults(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		paged.Total = total
+	}
+
+	if len(results) == 0 && opts.Query != "" && opts.MatchMode != MatchRegex {
+		correction, err := c.suggestCorrection(ctx, opts.Query)
+		if err != nil {
+			return nil, err
+		}
+		if correction != "" {
+			corrected := opts
+			corrected.Query = correction
+			total, err := c.countSearchResults(ctx, corrected)
+			if err != nil {
+				return nil, err
+			}
+			if total > 0 {
+				paged.Suggestion = correction
+			}
+		}
+	}
+
+	return paged, nil
+}
+
+// countSearchResults counts all title search results, ignoring pagination.
+func (c *sqliteClient) countSearchResults(ctx context.Context, opts SearchOptions) (int, error) {
+	if opts.CollapseRedirects {
+		matches, args := c.buildSearchMatches(opts)
+		query := "SELECT COUNT(*) FROM (" + collapsedMatches(matches, c.wiki.filter("t")) + ")"
+
+		var total int
+		if err := c.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		return total, nil
+	}
+
+	query := "SELECT COUNT(*) FROM pages p WHERE 1=1"
+	var args []interface{}
+
+	if opts.Query != "" {
+		cond, arg := c.titleMatch(opts)
+		query += " AND " + cond
+		args = append(args, arg)
+	}
+
+	filterQuery, filterArgs := c.buildFilters(opts)
+	query += filterQuery
+	args = append(args, filterArgs...)
+
+	var total int
+	if err := c.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return total, nil
+}
+
+// searchTitles runs a title search with validated options.
+func (c *sqliteClient) searchTitles(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
 	// Build query with filters
 	query, args := c.buildSearchQuery(opts)
 
@@ -286,9 +447,9 @@ func (c *sqliteClient) Search(ctx context.Context, opts SearchOptions) ([]Search
 	for rows.Next() {
 		var result SearchResult
 		var timestamp sql.NullTime
-		var snippet sql.NullString
+		var content, redirectedFrom sql.NullString
 
-		err := rows.Scan(&result.PageID, &result.Namespace, &result.Title, &timestamp, &snippet, &result.Relevance)
+		err := rows.Scan(&result.PageID, &result.Namespace, &result.Title, &timestamp, &content, &result.Relevance, &redirectedFrom)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
 		}
@@ -296,9 +457,10 @@ func (c *sqliteClient) Search(ctx context.Context, opts SearchOptions) ([]Search
 		if timestamp.Valid {
 			result.Timestamp = timestamp.Time
 		}
-		if snippet.Valid {
-			result.Snippet = snippet.String
+		if content.Valid {
+			result.Snippet = buildSnippet(content.String, opts.Query, opts.SnippetLength)
 		}
+		result.RedirectedFrom = redirectedFrom.String
 		result.MatchType = "title"
 
 		results = append(results, result)
@@ -313,6 +475,10 @@ func (c *sqliteClient) Search(ctx context.Context, opts SearchOptions) ([]Search
 
 // buildSearchQuery constructs the SQL query for title search with filters.
 func (c *sqliteClient) buildSearchQuery(opts SearchOptions) (string, []interface{}) {
+	if opts.CollapseRedirects {
+		return c.buildCollapsedSearchQuery(opts)
+	}
+
 	var args []interface{}
 
 	// Pre-allocate for relevance calculation args
@@ -329,12 +495,13 @@ func (c *sqliteClient) buildSearchQuery(opts SearchOptions) (string, []interface
 			p.namespace,
 			p.title,
 			(SELECT r2.timestamp FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) as timestamp,
-			(SELECT SUBSTR(r2.content, 1, 100) FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) as snippet,
+			(SELECT r2.content FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) as content,
 			CASE 
 				WHEN p.title LIKE ? THEN 10.0
 				WHEN LOWER(p.title) LIKE LOWER(?) THEN 5.0
 				ELSE 1.0
-			END as relevance
+			END as relevance,
+			NULL as redirected_from
 		FROM pages p
 		WHERE 1=1
 	`
@@ -344,8 +511,9 @@ func (c *sqliteClient) buildSearchQuery(opts SearchOptions) (string, []interface
 
 	// Add query filter if provided (case-insensitive)
 	if opts.Query != "" {
-		query += " AND LOWER(p.title) LIKE LOWER(?)"
-		args = append(args, "%"+opts.Query+"%")
+		cond, arg := c.titleMatch(opts)
+		query += " AND " + cond
+		args = append(args, arg)
 	}
 
 	// Add filters
@@ -363,73 +531,136 @@ func (c *sqliteClient) buildSearchQuery(opts SearchOptions) (string, []interface
 	return query, args
 }
 
+// buildSearchMatches constructs the query listing the pages matching a title
+// search before redirects are collapsed onto their targets.
+func (c *sqliteClient) buildSearchMatches(opts SearchOptions) (string, []interface{}) {
+	query := `
+		SELECT
+			p.page_id,
+			p.title,
+			` + c.wiki.redirectTargetColumn("p", sqliteParseTarget) + ` as target,
+			CASE
+				WHEN p.title LIKE ? THEN 10.0
+				WHEN LOWER(p.title) LIKE LOWER(?) THEN 5.0
+				ELSE 1.0
+			END as relevance
+		FROM pages p
+		WHERE 1=1
+	`
+	args := []interface{}{opts.Query, opts.Query}
+
+	if opts.Query != "" {
+		cond, arg := c.titleMatch(opts)
+		query += " AND " + cond
+		args = append(args, arg)
+	}
+
+	filterQuery, filterArgs := c.buildFilters(opts)
+	query += filterQuery
+	args = append(args, filterArgs...)
+
+	return query, args
+}
+
+// buildCollapsedSearchQuery constructs the title search query that lists
+// redirects as their target page.
+func (c *sqliteClient) buildCollapsedSearchQuery(opts SearchOptions) (string, []interface{}) {
+	matches, args := c.buildSearchMatches(opts)
+
+	query := `
+		SELECT
+			p.page_id,
+			p.namespace,
+			p.title,
+			(SELECT r2.timestamp FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) as timestamp,
+			(SELECT r2.content FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) as content,
+			x.relevance,
+			x.redirected_from
+		FROM (` + collapsedMatches(matches, c.wiki.filter("t")) + `) x
+		JOIN pages p ON p.page_id = x.page_id
+	`
+	query += c.buildSortClause(opts)
+
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, opts.Limit, opts.Offset)
+
+	return query, args
+}
+
+// titleMatch returns the condition matching titles against the search query.
+func (c *sqliteClient) titleMatch(opts SearchOptions) (string, interface{}) {
+	const like = "LOWER(%s) LIKE LOWER(%s)"
+	return titleMatch(opts, "?", like, like, "irowiki_regexp(%[2]s, %[1]s)")
+}
+
 // buildFilters constructs WHERE clause conditions from SearchOptions.
 func (c *sqliteClient) buildFilters(opts SearchOptions) (string, []interface{}) {
+	return searchFilters(opts, c.wiki, nil, func(int) string { return "?" })
+}
+
+// searchFilters returns the conditions, starting with " AND ", limiting the
+// pages aliased as p to the namespaces, dates, sizes and redirects selected
+// by opts, and args with the conditions' arguments appended.
+func searchFilters(opts SearchOptions, scope wikiScope, args []interface{}, placeholder func(n int) string) (string, []interface{}) {
 	var conditions []string
-	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return placeholder(len(args))
+	}
 
 	// Namespace filters
 	if len(opts.Namespaces) > 0 {
 		placeholders := make([]string, len(opts.Namespaces))
 		for i, ns := range opts.Namespaces {
-			placeholders[i] = "?"
-			args = append(args, ns)
+			placeholders[i] = arg(ns)
 		}
 		conditions = append(conditions, fmt.Sprintf("p.namespace IN (%s)", join(placeholders, ",")))
 	} else if opts.Namespace >= 0 {
-		conditions = append(conditions, "p.namespace = ?")
-		args = append(args, opts.Namespace)
+		conditions = append(conditions, "p.namespace = "+arg(opts.Namespace))
 	}
 
 	if len(opts.ExcludeNamespaces) > 0 {
 		placeholders := make([]string, len(opts.ExcludeNamespaces))
 		for i, ns := range opts.ExcludeNamespaces {
-			placeholders[i] = "?"
-			args = append(args, ns)
+			placeholders[i] = arg(ns)
 		}
 		conditions = append(conditions, fmt.Sprintf("p.namespace NOT IN (%s)", join(placeholders, ",")))
 	}
 
 	// Date range filters (based on latest revision timestamp)
 	if opts.CreatedAfter != nil {
-		conditions = append(conditions, "(SELECT MIN(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) >= ?")
-		args = append(args, *opts.CreatedAfter)
+		conditions = append(conditions, "(SELECT MIN(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) >= "+arg(*opts.CreatedAfter))
 	}
 	if opts.CreatedBefore != nil {
-		conditions = append(conditions, "(SELECT MIN(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) <= ?")
-		args = append(args, *opts.CreatedBefore)
+		conditions = append(conditions, "(SELECT MIN(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) <= "+arg(*opts.CreatedBefore))
 	}
 	if opts.ModifiedAfter != nil {
-		conditions = append(conditions, "(SELECT MAX(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) >= ?")
-		args = append(args, *opts.ModifiedAfter)
+		conditions = append(conditions, "(SELECT MAX(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) >= "+arg(*opts.ModifiedAfter))
 	}
 	if opts.ModifiedBefore != nil {
-		conditions = append(conditions, "(SELECT MAX(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) <= ?")
-		args = append(args, *opts.ModifiedBefore)
+		conditions = append(conditions, "(SELECT MAX(r2.timestamp) FROM revisions r2 WHERE r2.page_id = p.page_id) <= "+arg(*opts.ModifiedBefore))
 	}
 
 	// Size filters (use subquery to get latest revision size)
 	if opts.MinSize != nil {
-		conditions = append(conditions, "(SELECT r2.size FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) >= ?")
-		args = append(args, *opts.MinSize)
+		conditions = append(conditions, "(SELECT r2.size FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) >= "+arg(*opts.MinSize))
 	}
 	if opts.MaxSize != nil {
-		conditions = append(conditions, "(SELECT r2.size FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) <= ?")
-		args = append(args, *opts.MaxSize)
+		conditions = append(conditions, "(SELECT r2.size FROM revisions r2 WHERE r2.page_id = p.page_id ORDER BY r2.timestamp DESC LIMIT 1) <= "+arg(*opts.MaxSize))
 	}
 
 	// Redirect filters
 	if opts.OnlyRedirects {
-		conditions = append(conditions, "p.is_redirect = 1")
+		conditions = append(conditions, "p.is_redirect")
 	} else if opts.ExcludeRedirects {
-		conditions = append(conditions, "p.is_redirect = 0")
+		conditions = append(conditions, "NOT p.is_redirect")
 	}
 
 	if len(conditions) == 0 {
-		return "", nil
+		return scope.forSearch(opts).filter("p"), args
 	}
 
-	return " AND " + join(conditions, " AND "), args
+	return " AND " + join(conditions, " AND ") + scope.forSearch(opts).filter("p"), args
 }
 
 // buildSortClause constructs ORDER BY clause from SearchOptions.
@@ -449,6 +680,9 @@ func (c *sqliteClient) buildSortClause(opts SearchOptions) string {
 		fallthrough
 	default:
 		orderBy += "p.title"
+		if opts.Collation == CollationUnicode {
+			orderBy += " COLLATE " + sqliteUnicodeCollation
+		}
 	}
 
 	if opts.SortOrder == "asc" {
@@ -466,9 +700,10 @@ func (c *sqliteClient) SearchFullText(ctx context.Context, query string, opts Se
 		return nil, err
 	}
 
-	if query == "" {
+	if query == "" && opts.Proximity == nil {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
+	query = nfc(query)
 
 	// Validate and apply defaults
 	if err := opts.Validate(); err != nil {
@@ -476,8 +711,27 @@ func (c *sqliteClient) SearchFullText(ctx context.Context, query string, opts Se
 	}
 	opts.SetDefaults()
 
+	// Trigram indexes need a different query syntax than word tokenizers
+	tokenize, err := sqliteFTSTokenize(ctx, c.db, "main")
+	if err != nil {
+		return nil, err
+	}
+
+	var match string
+	if isTrigramTokenize(tokenize) {
+		// Trigram token positions aren't word positions, so NEAR distances would be meaningless
+		if opts.Proximity != nil || nearInfixPattern.MatchString(query) {
+			return nil, fmt.Errorf("%w: proximity search is not supported by a trigram index", ErrInvalidInput)
+		}
+		if match, err = adaptTrigramQuery(query); err != nil {
+			return nil, err
+		}
+	} else if match, err = fullTextMatch(query, opts.Proximity); err != nil {
+		return nil, err
+	}
+
 	// Build FTS query
-	sqlQuery, args := c.buildFullTextQuery(query, opts)
+	sqlQuery, args := c.buildFullTextQuery(match, opts)
 
 	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
@@ -567,23 +821,25 @@ func (c *sqliteClient) GetPageHistory(ctx context.Context, title string, opts Hi
 		return nil, err
 	}
 
-	// First, get the page ID
-	var pageID int64
-	err := c.db.QueryRowContext(ctx, "SELECT page_id FROM pages WHERE title = ?", title).Scan(&pageID)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
-	}
+	placeholder := func(int) string { return "?" }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, err
 	}
+	pageID := page.ID
 
 	if opts.Limit == 0 {
 		opts.Limit = 100
 	}
 
+	content := "content"
+	if opts.ExcludeContent {
+		content = "'' AS content"
+	}
+
 	query := `
 		SELECT revision_id, page_id, parent_id, timestamp, user, user_id,
-		       comment, content, size, sha1, minor, tags
+		       comment, ` + content + `, size, sha1, minor, tags
 		FROM revisions
 		WHERE page_id = ?
 	`
@@ -670,15 +926,12 @@ func (c *sqliteClient) GetPageAtTime(ctx context.Context, title string, timestam
 		return nil, err
 	}
 
-	// First, get the page ID
-	var pageID int64
-	err := c.db.QueryRowContext(ctx, "SELECT page_id FROM pages WHERE title = ?", title).Scan(&pageID)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
-	}
+	placeholder := func(int) string { return "?" }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, err
 	}
+	pageID := page.ID
 
 	const query = `
 		SELECT revision_id, page_id, parent_id, timestamp, user, user_id,
@@ -831,12 +1084,18 @@ func (c *sqliteClient) GetStatistics(ctx context.Context) (*Statistics, error) {
 		return nil, err
 	}
 
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	stats := &Statistics{
 		PagesByNamespace: make(map[int]int64),
 	}
 
 	// Get total pages
-	err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages").Scan(&stats.TotalPages)
+	err = c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages").Scan(&stats.TotalPages)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
 	}
@@ -905,20 +1164,15 @@ func (c *sqliteClient) GetPageStats(ctx context.Context, title string) (*PageSta
 		return nil, err
 	}
 
-	// Get page ID
-	var pageID int64
-	var pageTitle string
-	err := c.db.QueryRowContext(ctx, "SELECT page_id, title FROM pages WHERE title = ?", title).Scan(&pageID, &pageTitle)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
-	}
+	placeholder := func(int) string { return "?" }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, err
 	}
 
 	stats := &PageStatistics{
-		PageID: pageID,
-		Title:  pageTitle,
+		PageID: page.ID,
+		Title:  page.Title,
 	}
 
 	// Get revision count and other stats
@@ -936,7 +1190,7 @@ func (c *sqliteClient) GetPageStats(ctx context.Context, title string) (*PageSta
 
 	var firstEditStr, lastEditStr sql.NullString
 	var avgSize sql.NullFloat64
-	err = c.db.QueryRowContext(ctx, query, pageID).Scan(
+	err = c.db.QueryRowContext(ctx, query, page.ID).Scan(
 		&stats.RevisionCount, &stats.EditorCount, &firstEditStr, &lastEditStr, &avgSize, &stats.MinorEdits,
 	)
 	if err != nil {
@@ -1067,13 +1321,44 @@ func (c *sqliteClient) ListFiles(ctx context.Context, offset, limit int) ([]File
 	return files, nil
 }
 
+// GetArchiveInfo returns metadata describing the archive's source wiki.
+func (c *sqliteClient) GetArchiveInfo(ctx context.Context) (*ArchiveInfo, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	exists, err := sqliteTableExists(ctx, c.db, "main", "archive_meta")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	meta, err := readArchiveMeta(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	info := newArchiveInfo(meta)
+	if c.wiki.id != "" {
+		wikis, err := listWikis(ctx, c.db, meta, true)
+		if err != nil {
+			return nil, err
+		}
+		applyWiki(info, wikis, c.wiki)
+	}
+
+	return info, nil
+}
+
 // Ping checks if the database connection is alive.
 func (c *sqliteClient) Ping(ctx context.Context) error {
 	if err := c.ensureNotClosed(); err != nil {
 		return err
 	}
 
-	return c.db.PingContext(ctx)
+	return c.pool.PingContext(ctx)
 }
 
 // Close cleanly shuts down the client and releases resources.
@@ -1087,7 +1372,17 @@ func (c *sqliteClient) Close() error {
 
 	c.closed = true
 
-	if err := c.db.Close(); err != nil {
+	if c.snapshot != nil {
+		return c.snapshot.Rollback()
+	}
+
+	err := c.pool.Close()
+	if c.cleanup != nil {
+		if cleanupErr := c.cleanup(); err == nil {
+			err = cleanupErr
+		}
+	}
+	if err != nil {
 		return fmt.Errorf("%w: failed to close database: %v", ErrDatabaseError, err)
 	}
 
@@ -0,0 +1,232 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sync"
+)
+
+// sqliteStore implements the Store interface for SQLite databases.
+type sqliteStore struct {
+	db     *sql.DB
+	path   string
+	opts   ConnectionOptions
+	closed bool
+	mu     sync.RWMutex
+}
+
+// OpenSQLiteStore opens a SQLite archive for writing with default options.
+//
+// Example:
+//
+//	store, err := irowiki.OpenSQLiteStore("irowiki.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer store.Close()
+func OpenSQLiteStore(path string) (Store, error) {
+	return OpenSQLiteStoreWithOptions(path, DefaultSQLiteOptions())
+}
+
+// OpenSQLiteStoreWithOptions opens a SQLite archive for writing with custom connection options.
+func OpenSQLiteStoreWithOptions(path string, opts ConnectionOptions) (Store, error) {
+	opts.applyDefaults(true)
+
+	dsn := path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	if opts.ReadOnly && path != ":memory:" {
+		// The driver only passes mode=ro to SQLite in file: URIs. Databases
+		// attached later, such as an extract's destination, stay writable.
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+		uri := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: "mode=ro&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"}
+		dsn = uri.String()
+	}
+	if path == ":memory:" {
+		dsn = path
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open database: %v", ErrConnectionFailed, err)
+	}
+
+	// A single writer connection avoids SQLITE_BUSY between pooled connections
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ConnectTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: failed to ping database: %v", ErrConnectionFailed, err)
+	}
+
+	return &sqliteStore{db: db, path: path, opts: opts}, nil
+}
+
+// ensureNotClosed checks if the store is closed and returns an error if it is.
+func (s *sqliteStore) ensureNotClosed() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Prune drops old revisions according to the retention policy.
+func (s *sqliteStore) Prune(ctx context.Context, policy PrunePolicy) (*PruneResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	if !policy.DryRun {
+		release, err := s.lock()
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT r.revision_id, r.page_id, r.parent_id, r.timestamp, r.size
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+	`
+	var args []interface{}
+	if len(policy.Namespaces) > 0 {
+		placeholders := make([]string, len(policy.Namespaces))
+		for i, ns := range policy.Namespaces {
+			placeholders[i] = "?"
+			args = append(args, ns)
+		}
+		query += fmt.Sprintf(" WHERE p.namespace IN (%s)", join(placeholders, ","))
+	}
+	query += " ORDER BY r.page_id, r.timestamp, r.revision_id"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	candidates, err := scanPruneCandidates(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planPrune(candidates, policy)
+	if policy.DryRun {
+		return &plan.result, nil
+	}
+
+	// Re-link parents before deleting so no surviving revision ever points at a missing row
+	update, err := tx.PrepareContext(ctx, "UPDATE revisions SET parent_id = ? WHERE revision_id = ?")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer update.Close()
+
+	for _, u := range plan.updates {
+		if _, err := update.ExecContext(ctx, u.parentID, u.revisionID); err != nil {
+			return nil, fmt.Errorf("%w: failed to re-link revision %d: %v", ErrDatabaseError, u.revisionID, err)
+		}
+	}
+
+	del, err := tx.PrepareContext(ctx, "DELETE FROM revisions WHERE revision_id = ?")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer del.Close()
+
+	for _, id := range plan.deletes {
+		if _, err := del.ExecContext(ctx, id); err != nil {
+			return nil, fmt.Errorf("%w: failed to delete revision %d: %v", ErrDatabaseError, id, err)
+		}
+	}
+
+	// The search index triggers index a revision's content whenever it's
+	// updated, so re-linking left older content indexed for these pages
+	var relinked []int64
+	for _, u := range plan.updates {
+		if len(relinked) == 0 || relinked[len(relinked)-1] != u.pageID {
+			relinked = append(relinked, u.pageID)
+		}
+	}
+	if err := refreshSearchIndex(ctx, tx, relinked); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	if policy.Vacuum && plan.result.RevisionsDeleted > 0 {
+		if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+			return &plan.result, fmt.Errorf("%w: vacuum failed: %v", ErrDatabaseError, err)
+		}
+	}
+
+	return &plan.result, nil
+}
+
+// scanPruneCandidates reads prune candidates from rows and closes them.
+func scanPruneCandidates(rows *sql.Rows) ([]pruneCandidate, error) {
+	defer rows.Close()
+
+	var candidates []pruneCandidate
+	for rows.Next() {
+		var c pruneCandidate
+		var parentID sql.NullInt64
+
+		if err := rows.Scan(&c.id, &c.pageID, &parentID, &c.timestamp, &c.size); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		if parentID.Valid {
+			pid := parentID.Int64
+			c.parentID = &pid
+		}
+		candidates = append(candidates, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	return candidates, nil
+}
+
+// Close cleanly shuts down the store and releases resources.
+func (s *sqliteStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	s.closed = true
+
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("%w: failed to close database: %v", ErrDatabaseError, err)
+	}
+
+	return nil
+}
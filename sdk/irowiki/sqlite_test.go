@@ -146,6 +146,20 @@ func TestSQLiteClient_GetPageHistory(t *testing.T) {
 		t.Error("expected revisions in reverse chronological order")
 	}
 
+	// Test: ExcludeContent keeps the metadata but not the wikitext
+	opts.ExcludeContent = true
+	light, err := client.GetPageHistory(ctx, "Main_Page", opts)
+	if err != nil {
+		t.Fatalf("GetPageHistory failed: %v", err)
+	}
+	if len(light) != len(history) || light[0].ID != history[0].ID || light[0].Size != history[0].Size {
+		t.Errorf("expected the same revisions without content, got %+v", light)
+	}
+	if len(light) > 0 && light[0].Content != "" {
+		t.Errorf("expected empty content, got %q", light[0].Content)
+	}
+	opts.ExcludeContent = false
+
 	// Test: Get history for non-existent page
 	_, err = client.GetPageHistory(ctx, "NonExistent", opts)
 	if err != irowiki.ErrNotFound {
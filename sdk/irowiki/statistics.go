@@ -14,13 +14,19 @@ func (c *sqliteClient) GetStatisticsEnhanced(ctx context.Context) (*StatisticsEn
 		return nil, err
 	}
 
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	stats := &StatisticsEnhanced{
 		PagesByNamespace: make(map[int]int64),
 		EditsByMonth:     make(map[string]int64),
 	}
 
 	// Get basic counts
-	err := c.getBasicCounts(ctx, stats)
+	err = c.getBasicCounts(ctx, stats)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get basic counts: %w", err)
 	}
@@ -269,13 +275,14 @@ func (c *sqliteClient) getTopEditors(ctx context.Context, stats *StatisticsEnhan
 			datetime(MIN(timestamp)) as first_edit,
 			datetime(MAX(timestamp)) as last_edit,
 			SUM(CASE WHEN minor = 1 THEN 1 ELSE 0 END) as minor_edits,
-			COUNT(DISTINCT page_id) as pages_edited
+			COUNT(DISTINCT page_id) as pages_edited,
+			%s as bot
 		FROM revisions
 		WHERE user IS NOT NULL
 		GROUP BY user
 		ORDER BY edit_count DESC
 		LIMIT %d
-	`, n)
+	`, sqliteBotCondition("user", c.opts.BotUsers), n)
 
 	rows, err := c.db.QueryContext(ctx, query)
 	if err != nil {
@@ -295,6 +302,7 @@ func (c *sqliteClient) getTopEditors(ctx context.Context, stats *StatisticsEnhan
 			&lastEdit,
 			&editor.MinorEdits,
 			&editor.PagesEdited,
+			&editor.Bot,
 		)
 		if err != nil {
 			return err
@@ -400,22 +408,22 @@ func (c *sqliteClient) GetPageStatsEnhanced(ctx context.Context, title string) (
 		return nil, err
 	}
 
-	// Get page ID
-	var pageID int64
-	var pageTitle string
-	var namespace int
-	err := c.db.QueryRowContext(ctx, "SELECT page_id, title, namespace FROM pages WHERE title = ?", title).Scan(&pageID, &pageTitle, &namespace)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
+
+	placeholder := func(int) string { return "?" }
+	page, err := resolveTitle(ctx, c.db, c.wiki, title, false, placeholder)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		return nil, err
 	}
 
 	stats := &PageStatisticsEnhanced{
-		PageID:        pageID,
-		Title:         pageTitle,
-		PageNamespace: namespace,
+		PageID:        page.ID,
+		Title:         page.Title,
+		PageNamespace: page.Namespace,
 	}
 
 	// Get basic revision statistics
@@ -634,23 +642,23 @@ func (c *sqliteClient) getPageQualityMetrics(ctx context.Context, stats *PageSta
 		return err
 	}
 
-	// Detect reverts (simple: look for identical SHA1 hashes)
-	const revertQuery = `
-		SELECT COUNT(*)
-		FROM revisions r1
-		INNER JOIN revisions r2 ON r1.page_id = r2.page_id 
-			AND r1.sha1 = r2.sha1 
-			AND r1.revision_id < r2.revision_id
-		WHERE r1.page_id = ?
-	`
-
-	c.db.QueryRowContext(ctx, revertQuery, stats.PageID).Scan(&stats.RevertCount)
+	// Classify edits (ClassifyRevisions) to count reverts and noise edits
+	revs, err := loadRevisionsForClassification(ctx, c.db, stats.PageID, func(int) string { return "?" })
+	if err != nil {
+		return err
+	}
+	stats.EditClasses = make(map[string]int)
+	for _, class := range classifyRevisions(revs) {
+		stats.EditClasses[class.Class]++
+	}
+	stats.RevertCount = stats.EditClasses[EditRevert]
+	stats.CosmeticEdits = stats.EditClasses[EditFormatting]
 
-	// Calculate stability score
+	// Calculate stability score, leaving out noise edits
 	stats.StabilityScore = calculateStabilityScore(
 		stats.AvgTimeBetween,
 		stats.RevertCount,
-		int(stats.RevisionCount),
+		int(stats.RevisionCount)-stats.CosmeticEdits,
 		stats.MinorEditPercent,
 	)
 
@@ -765,36 +773,74 @@ func (c *sqliteClient) getPageActivityPatterns(ctx context.Context, stats *PageS
 
 // GetEditorActivityEnhanced retrieves enhanced activity for an editor.
 func (c *sqliteClient) GetEditorActivityEnhanced(ctx context.Context, username string, start, end time.Time) (*EditorActivity, error) {
+	return c.GetEditorActivityWithOptions(ctx, username, AllEditorActivity(start, end))
+}
+
+// GetEditorActivityWithOptions retrieves the requested sections of an
+// editor's activity.
+func (c *sqliteClient) GetEditorActivityWithOptions(ctx context.Context, username string, opts EditorActivityOptions) (*EditorActivity, error) {
 	if err := c.ensureNotClosed(); err != nil {
 		return nil, err
 	}
+	if opts.TopPages < 0 {
+		return nil, fmt.Errorf("%w: negative top pages", ErrInvalidInput)
+	}
+	start, end := opts.Start, opts.End
+
+	c, release, err := c.readSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	activity := &EditorActivity{
 		Username: username,
 	}
 
 	// Get basic statistics
-	err := c.getEditorBasicStats(ctx, activity, start, end)
+	err = c.getEditorBasicStats(ctx, activity, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get basic stats: %w", err)
 	}
 
+	// Get the first page edited
+	if opts.FirstEditedPage {
+		err = c.getEditorFirstPage(ctx, activity, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get first edited page: %w", err)
+		}
+	}
+
 	// Get content statistics
-	err = c.getEditorContentStats(ctx, activity, start, end)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get content stats: %w", err)
+	if opts.Content {
+		err = c.getEditorContentStats(ctx, activity, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get content stats: %w", err)
+		}
+	}
+
+	// Get namespace distribution
+	if opts.Namespaces {
+		err = c.getEditorNamespaces(ctx, activity, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespaces: %w", err)
+		}
 	}
 
 	// Get activity patterns
-	err = c.getEditorActivityPatterns(ctx, activity, start, end)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get activity patterns: %w", err)
+	if opts.Patterns {
+		err = c.getEditorActivityPatterns(ctx, activity, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get activity patterns: %w", err)
+		}
 	}
 
 	// Get top pages
-	err = c.getEditorTopPages(ctx, activity, start, end, 10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get top pages: %w", err)
+	if opts.TopPages > 0 {
+		err = c.getEditorTopPages(ctx, activity, start, end, opts.TopPages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get top pages: %w", err)
+		}
 	}
 
 	return activity, nil
@@ -875,99 +921,184 @@ func (c *sqliteClient) getEditorBasicStats(ctx context.Context, activity *Editor
 func (c *sqliteClient) getEditorContentStats(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
 	query := `
 		SELECT 
-			COUNT(DISTINCT page_id) as pages_edited,
-			SUM(CASE WHEN minor = 1 THEN 1 ELSE 0 END) as minor_edits
-		FROM revisions
-		WHERE user = ?
+			COUNT(DISTINCT r.page_id) as pages_edited,
+			SUM(CASE WHEN r.minor = 1 THEN 1 ELSE 0 END) as minor_edits,
+			AVG(r.size - COALESCE(pr.size, 0)) as avg_size_delta,
+			AVG(CASE WHEN r.comment IS NOT NULL AND r.comment != '' THEN 1.0 ELSE 0.0 END) as comment_rate
+		FROM revisions r
+		LEFT JOIN revisions pr ON pr.revision_id = r.parent_id
+		WHERE r.user = ?
 	`
 
 	args := []interface{}{activity.Username}
 
 	if !start.IsZero() {
-		query += " AND timestamp >= ?"
+		query += " AND r.timestamp >= ?"
 		args = append(args, start)
 	}
 	if !end.IsZero() {
-		query += " AND timestamp <= ?"
+		query += " AND r.timestamp <= ?"
 		args = append(args, end)
 	}
 
+	var sizeDelta, commentRate sql.NullFloat64
 	err := c.db.QueryRowContext(ctx, query, args...).Scan(
 		&activity.PagesEdited,
 		&activity.MinorEdits,
+		&sizeDelta,
+		&commentRate,
 	)
+	activity.AvgSizeDelta = sizeDelta.Float64
+	activity.CommentRate = commentRate.Float64
 
 	return err
 }
 
-// getEditorActivityPatterns retrieves activity patterns.
-func (c *sqliteClient) getEditorActivityPatterns(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
+// getEditorFirstPage retrieves the title of the page of the editor's first
+// edit.
+func (c *sqliteClient) getEditorFirstPage(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
 	query := `
-		SELECT 
-			CAST(strftime('%H', timestamp) AS INTEGER) as hour,
-			strftime('%w', timestamp) as day_of_week,
-			strftime('%Y-%m', timestamp) as month
-		FROM revisions
-		WHERE user = ? AND timestamp IS NOT NULL
-	`
+		SELECT p.title
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.user = ?
+	` + c.wiki.filter("p")
 
 	args := []interface{}{activity.Username}
 
 	if !start.IsZero() {
-		query += " AND timestamp >= ?"
+		query += " AND r.timestamp >= ?"
 		args = append(args, start)
 	}
 	if !end.IsZero() {
-		query += " AND timestamp <= ?"
+		query += " AND r.timestamp <= ?"
 		args = append(args, end)
 	}
 
+	query += " ORDER BY r.timestamp, r.revision_id LIMIT 1"
+
+	err := c.db.QueryRowContext(ctx, query, args...).Scan(&activity.FirstEditedPage)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// getEditorNamespaces counts the editor's edits in each namespace.
+func (c *sqliteClient) getEditorNamespaces(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
+	query := `
+		SELECT p.namespace, COUNT(*) as count
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.user = ?
+	` + c.wiki.filter("p")
+
+	args := []interface{}{activity.Username}
+
+	if !start.IsZero() {
+		query += " AND r.timestamp >= ?"
+		args = append(args, start)
+	}
+	if !end.IsZero() {
+		query += " AND r.timestamp <= ?"
+		args = append(args, end)
+	}
+
+	query += " GROUP BY p.namespace"
+
 	rows, err := c.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 
+	activity.EditsByNamespace = make(map[int]int)
+	for rows.Next() {
+		var namespace, count int
+		if err := rows.Scan(&namespace, &count); err != nil {
+			return err
+		}
+		activity.EditsByNamespace[namespace] = count
+	}
+
+	return rows.Err()
+}
+
+// getEditorActivityPatterns retrieves activity patterns.
+func (c *sqliteClient) getEditorActivityPatterns(ctx context.Context, activity *EditorActivity, start, end time.Time) error {
+	placeholder := func(int) string { return "?" }
+	return getEditorActivityPatterns(ctx, c.db, c.wiki, activity, start, end, sqliteWeekday, sqliteHour, sqliteMonth, sqliteBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
+
+// getEditorActivityPatterns fills activity's hourly, daily and monthly edit
+// counts. The database returns only the buckets: the weekday and hour counts
+// come from the editor's heatmap and the months from a second GROUP BY.
+func getEditorActivityPatterns(ctx context.Context, db querier, scope wikiScope, activity *EditorActivity, start, end time.Time, weekday, hour, month, bot string, placeholder func(n int) string) error {
+	heatmap, err := getActivityHeatmap(ctx, db, scope, ActivityScope{User: activity.Username, Start: start, End: end}, weekday, hour, bot, placeholder)
+	if err != nil {
+		return err
+	}
+
 	activity.EditsByHour = make(map[int]int)
 	activity.EditsByDay = make(map[string]int)
 	activity.EditsByMonth = make(map[string]int)
 
 	dayNames := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
-	maxHourCount := 0
-	maxDayCount := 0
-
-	for rows.Next() {
-		var hour sql.NullInt64
-		var dayOfWeek, month sql.NullString
-
-		if err := rows.Scan(&hour, &dayOfWeek, &month); err != nil {
-			return err
-		}
-
-		if hour.Valid {
-			h := int(hour.Int64)
-			activity.EditsByHour[h]++
-			if activity.EditsByHour[h] > maxHourCount {
-				maxHourCount = activity.EditsByHour[h]
-				activity.BusiestHour = h
+	for d, hours := range heatmap.Counts {
+		for h, n := range hours {
+			if n == 0 {
+				continue
 			}
+			activity.EditsByHour[h] += n
+			activity.EditsByDay[dayNames[d]] += n
 		}
+	}
 
-		if dayOfWeek.Valid && dayOfWeek.String != "" {
-			// Convert day number (0-6) to name
-			if len(dayOfWeek.String) == 1 && dayOfWeek.String[0] >= '0' && dayOfWeek.String[0] <= '6' {
-				dayIdx := int(dayOfWeek.String[0] - '0')
-				dayName := dayNames[dayIdx]
-				activity.EditsByDay[dayName]++
-				if activity.EditsByDay[dayName] > maxDayCount {
-					maxDayCount = activity.EditsByDay[dayName]
-					activity.BusiestDay = dayName
-				}
-			}
+	// Ties go to the earliest hour and day of the week
+	maxHourCount, maxDayCount := 0, 0
+	for h := range 24 {
+		if n := activity.EditsByHour[h]; n > maxHourCount {
+			maxHourCount = n
+			activity.BusiestHour = h
+		}
+	}
+	for _, day := range dayNames {
+		if n := activity.EditsByDay[day]; n > maxDayCount {
+			maxDayCount = n
+			activity.BusiestDay = day
 		}
+	}
 
-		if month.Valid && month.String != "" {
-			activity.EditsByMonth[month.String]++
+	query := `
+		SELECT ` + month + `, COUNT(*)
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.user = ` + placeholder(1) + ` AND r.timestamp IS NOT NULL` + scope.filter("p")
+	args := []interface{}{activity.Username}
+	if !start.IsZero() {
+		args = append(args, start)
+		query += " AND r.timestamp >= " + placeholder(len(args))
+	}
+	if !end.IsZero() {
+		args = append(args, end)
+		query += " AND r.timestamp <= " + placeholder(len(args))
+	}
+	query += " GROUP BY 1"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m sql.NullString
+		var count int
+		if err := rows.Scan(&m, &count); err != nil {
+			return err
+		}
+		if m.Valid && m.String != "" {
+			activity.EditsByMonth[m.String] = count
 		}
 	}
 
@@ -0,0 +1,83 @@
+package irowiki
+
+import (
+	"slices"
+	"time"
+)
+
+// StatisticsDelta is the growth between two statistics snapshots of an
+// archive, as computed by CompareStatistics. Counts are signed: a snapshot
+// taken after pruning or extraction can have fewer pages or revisions.
+type StatisticsDelta struct {
+	// OldLastEdit and NewLastEdit are the newest edits in the earlier and
+	// later snapshots, bounding the period the growth covers.
+	OldLastEdit time.Time `json:"old_last_edit"`
+	NewLastEdit time.Time `json:"new_last_edit"`
+
+	PagesAdded            int64 `json:"pages_added"`
+	RevisionsAdded        int64 `json:"revisions_added"`
+	FilesAdded            int64 `json:"files_added"`
+	EditorsAdded          int64 `json:"editors_added"`
+	ContentSizeChange     int64 `json:"content_size_change"`
+	AveragePageSizeChange int   `json:"average_page_size_change"`
+
+	// PagesByNamespace holds the change in page count for each namespace
+	// whose count changed.
+	PagesByNamespace map[int]int64 `json:"namespace_changes"`
+
+	// EditsByMonth holds the additional edits for each month ("YYYY-MM")
+	// whose count changed, including months new in the later snapshot.
+	EditsByMonth map[string]int64 `json:"edits_by_month"`
+
+	// NewTopEditors lists editors in the later snapshot's TopEditors that
+	// were not in the earlier one's, in ranking order.
+	NewTopEditors []string `json:"new_top_editors"`
+}
+
+// CompareStatistics reports how an archive grew from the before snapshot to
+// the after one. Either may be nil, which compares against an empty archive.
+func CompareStatistics(before, after *StatisticsEnhanced) *StatisticsDelta {
+	if before == nil {
+		before = &StatisticsEnhanced{}
+	}
+	if after == nil {
+		after = &StatisticsEnhanced{}
+	}
+
+	delta := &StatisticsDelta{
+		OldLastEdit:           before.LastEdit,
+		NewLastEdit:           after.LastEdit,
+		PagesAdded:            after.TotalPages - before.TotalPages,
+		RevisionsAdded:        after.TotalRevisions - before.TotalRevisions,
+		FilesAdded:            after.TotalFiles - before.TotalFiles,
+		EditorsAdded:          after.TotalEditors - before.TotalEditors,
+		ContentSizeChange:     after.TotalContentSize - before.TotalContentSize,
+		AveragePageSizeChange: after.AveragePageSize - before.AveragePageSize,
+		PagesByNamespace:      mapDelta(before.PagesByNamespace, after.PagesByNamespace),
+		EditsByMonth:          mapDelta(before.EditsByMonth, after.EditsByMonth),
+		NewTopEditors:         []string{},
+	}
+
+	for _, editor := range after.TopEditors {
+		if !slices.ContainsFunc(before.TopEditors, func(e EditorStat) bool { return e.Username == editor.Username }) {
+			delta.NewTopEditors = append(delta.NewTopEditors, editor.Username)
+		}
+	}
+	return delta
+}
+
+// mapDelta returns after minus before for every key whose value changed.
+func mapDelta[K comparable](before, after map[K]int64) map[K]int64 {
+	delta := make(map[K]int64)
+	for k, v := range after {
+		if d := v - before[k]; d != 0 {
+			delta[k] = d
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok && v != 0 {
+			delta[k] = -v
+		}
+	}
+	return delta
+}
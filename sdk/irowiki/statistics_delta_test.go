@@ -0,0 +1,67 @@
+package irowiki_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestCompareStatistics tests growth reporting between two snapshots
+func TestCompareStatistics(t *testing.T) {
+	before := &irowiki.StatisticsEnhanced{
+		TotalPages:       5,
+		TotalRevisions:   7,
+		TotalFiles:       1,
+		TotalEditors:     3,
+		TotalContentSize: 150,
+		LastEdit:         time.Date(2020, 1, 7, 0, 0, 0, 0, time.UTC),
+		PagesByNamespace: map[int]int64{0: 4, 6: 1},
+		EditsByMonth:     map[string]int64{"2020-01": 7},
+		TopEditors:       []irowiki.EditorStat{{Username: "Admin"}, {Username: "Editor"}},
+	}
+	after := &irowiki.StatisticsEnhanced{
+		TotalPages:       8,
+		TotalRevisions:   17,
+		TotalFiles:       1,
+		TotalEditors:     4,
+		TotalContentSize: 420,
+		LastEdit:         time.Date(2020, 2, 20, 0, 0, 0, 0, time.UTC),
+		PagesByNamespace: map[int]int64{0: 6, 6: 1, 14: 1},
+		EditsByMonth:     map[string]int64{"2020-01": 8, "2020-02": 9},
+		TopEditors:       []irowiki.EditorStat{{Username: "Newbie"}, {Username: "Admin"}},
+	}
+
+	delta := irowiki.CompareStatistics(before, after)
+
+	// Test: Totals are differenced
+	if delta.PagesAdded != 3 || delta.RevisionsAdded != 10 || delta.FilesAdded != 0 || delta.EditorsAdded != 1 || delta.ContentSizeChange != 270 {
+		t.Errorf("unexpected totals: %+v", delta)
+	}
+	if !delta.OldLastEdit.Equal(before.LastEdit) || !delta.NewLastEdit.Equal(after.LastEdit) {
+		t.Errorf("expected last edits to bound the period, got %v and %v", delta.OldLastEdit, delta.NewLastEdit)
+	}
+
+	// Test: Distributions only list changes
+	if len(delta.PagesByNamespace) != 2 || delta.PagesByNamespace[0] != 2 || delta.PagesByNamespace[14] != 1 {
+		t.Errorf("unexpected namespace changes: %v", delta.PagesByNamespace)
+	}
+	if len(delta.EditsByMonth) != 2 || delta.EditsByMonth["2020-01"] != 1 || delta.EditsByMonth["2020-02"] != 9 {
+		t.Errorf("unexpected monthly changes: %v", delta.EditsByMonth)
+	}
+
+	// Test: New top editors are reported
+	if len(delta.NewTopEditors) != 1 || delta.NewTopEditors[0] != "Newbie" {
+		t.Errorf("expected Newbie as new top editor, got %v", delta.NewTopEditors)
+	}
+
+	// Test: A nil snapshot compares against an empty archive
+	delta = irowiki.CompareStatistics(nil, before)
+	if delta.PagesAdded != 5 || len(delta.NewTopEditors) != 2 {
+		t.Errorf("expected growth from empty, got %+v", delta)
+	}
+	delta = irowiki.CompareStatistics(after, nil)
+	if delta.PagesAdded != -8 || delta.PagesByNamespace[14] != -1 {
+		t.Errorf("expected shrinkage to empty, got %+v", delta)
+	}
+}
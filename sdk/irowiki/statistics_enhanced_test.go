@@ -2,6 +2,8 @@ package irowiki_test
 
 import (
 	"context"
+	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -9,16 +11,73 @@ import (
 	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
 )
 
-// TestSQLiteClient_GetStatisticsEnhanced tests comprehensive enhanced archive statistics
-func TestSQLiteClient_GetStatisticsEnhanced(t *testing.T) {
-	tdb := testutil.SetupTestDBFile(t)
-	defer tdb.Close()
+// openArchive opens a client on a test database, for running the
+// statistics tests on each backend.
+type openArchive func(t *testing.T, tdb *testutil.TestDB) irowiki.Client
+
+// openSQLiteArchive opens a client on the test database itself.
+func openSQLiteArchive(t *testing.T, tdb *testutil.TestDB) irowiki.Client {
+	t.Helper()
 
 	client, err := irowiki.OpenSQLite(tdb.Path)
 	if err != nil {
 		t.Fatalf("failed to open client: %v", err)
 	}
-	defer client.Close()
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// TestSQLiteClient_GetStatisticsEnhanced runs testGetStatisticsEnhanced on SQLite
+func TestSQLiteClient_GetStatisticsEnhanced(t *testing.T) {
+	testGetStatisticsEnhanced(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_GetPageStatsEnhanced runs testGetPageStatsEnhanced on SQLite
+func TestSQLiteClient_GetPageStatsEnhanced(t *testing.T) {
+	testGetPageStatsEnhanced(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_GetPageStatsEnhanced_CosmeticEdits runs testGetPageStatsEnhancedCosmeticEdits on SQLite
+func TestSQLiteClient_GetPageStatsEnhanced_CosmeticEdits(t *testing.T) {
+	testGetPageStatsEnhancedCosmeticEdits(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_GetPageStatsEnhanced_NotFound runs testGetPageStatsEnhancedNotFound on SQLite
+func TestSQLiteClient_GetPageStatsEnhanced_NotFound(t *testing.T) {
+	testGetPageStatsEnhancedNotFound(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_GetEditorActivityEnhanced runs testGetEditorActivityEnhanced on SQLite
+func TestSQLiteClient_GetEditorActivityEnhanced(t *testing.T) {
+	testGetEditorActivityEnhanced(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_GetEditorActivityEnhanced_NotFound runs testGetEditorActivityEnhancedNotFound on SQLite
+func TestSQLiteClient_GetEditorActivityEnhanced_NotFound(t *testing.T) {
+	testGetEditorActivityEnhancedNotFound(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_GetEditorActivityEnhanced_Patterns runs testGetEditorActivityEnhancedPatterns on SQLite
+func TestSQLiteClient_GetEditorActivityEnhanced_Patterns(t *testing.T) {
+	testGetEditorActivityEnhancedPatterns(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_GetEditorActivityWithOptions runs testGetEditorActivityWithOptions on SQLite
+func TestSQLiteClient_GetEditorActivityWithOptions(t *testing.T) {
+	testGetEditorActivityWithOptions(t, openSQLiteArchive)
+}
+
+// TestSQLiteClient_StatisticsEnhanced_DateRangeFiltering runs testStatisticsEnhancedDateRangeFiltering on SQLite
+func TestSQLiteClient_StatisticsEnhanced_DateRangeFiltering(t *testing.T) {
+	testStatisticsEnhancedDateRangeFiltering(t, openSQLiteArchive)
+}
+
+// testGetStatisticsEnhanced tests comprehensive enhanced archive statistics
+func testGetStatisticsEnhanced(t *testing.T, open openArchive) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client := open(t, tdb)
 
 	ctx := context.Background()
 
@@ -122,16 +181,12 @@ func TestSQLiteClient_GetStatisticsEnhanced(t *testing.T) {
 	}
 }
 
-// TestSQLiteClient_GetPageStatsEnhanced tests detailed enhanced page statistics
-func TestSQLiteClient_GetPageStatsEnhanced(t *testing.T) {
+// testGetPageStatsEnhanced tests detailed enhanced page statistics
+func testGetPageStatsEnhanced(t *testing.T, open openArchive) {
 	tdb := testutil.SetupTestDBFile(t)
 	defer tdb.Close()
 
-	client, err := irowiki.OpenSQLite(tdb.Path)
-	if err != nil {
-		t.Fatalf("failed to open client: %v", err)
-	}
-	defer client.Close()
+	client := open(t, tdb)
 
 	ctx := context.Background()
 
@@ -221,36 +276,55 @@ func TestSQLiteClient_GetPageStatsEnhanced(t *testing.T) {
 	}
 }
 
-// TestSQLiteClient_GetPageStatsEnhanced_NotFound tests enhanced stats for non-existent page
-func TestSQLiteClient_GetPageStatsEnhanced_NotFound(t *testing.T) {
+// testGetPageStatsEnhancedCosmeticEdits tests counting edits that only reformat wikitext
+func testGetPageStatsEnhancedCosmeticEdits(t *testing.T, open openArchive) {
 	tdb := testutil.SetupTestDBFile(t)
 	defer tdb.Close()
 
-	client, err := irowiki.OpenSQLite(tdb.Path)
+	_, err := tdb.DB.Exec(`
+		INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (10, 0, 'Drops', 0);
+		INSERT INTO revisions (revision_id, page_id, timestamp, user, comment, content, size, sha1) VALUES
+			(300, 10, '2024-01-01 00:00:00', 'Editor', 'new', 'Drops [[poring_card]].', 22, 'a'),
+			(301, 10, '2024-01-02 00:00:00', 'Editor', 'fmt', 'Drops  [[Poring card|poring card]].', 35, 'b'),
+			(302, 10, '2024-01-03 00:00:00', 'Editor', 'add', 'Drops [[Poring card]] and jellopy.', 33, 'c'),
+			(303, 10, '2024-01-04 00:00:00', 'Editor', 'fmt', 'Drops [[Poring card]] and jellopy.' || char(10), 34, 'd')`)
 	if err != nil {
-		t.Fatalf("failed to open client: %v", err)
+		t.Fatalf("failed to add revisions: %v", err)
 	}
-	defer client.Close()
+
+	client := open(t, tdb)
+
+	stats, err := client.GetPageStatsEnhanced(context.Background(), "Drops")
+	if err != nil {
+		t.Fatalf("GetPageStatsEnhanced failed: %v", err)
+	}
+	if stats.CosmeticEdits != 2 {
+		t.Errorf("expected 2 cosmetic edits, got %d", stats.CosmeticEdits)
+	}
+}
+
+// testGetPageStatsEnhancedNotFound tests enhanced stats for non-existent page
+func testGetPageStatsEnhancedNotFound(t *testing.T, open openArchive) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client := open(t, tdb)
 
 	ctx := context.Background()
 
 	// Test: Get enhanced stats for non-existent page
-	_, err = client.GetPageStatsEnhanced(ctx, "NonExistentPage123")
+	_, err := client.GetPageStatsEnhanced(ctx, "NonExistentPage123")
 	if err != irowiki.ErrNotFound {
 		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
 
-// TestSQLiteClient_GetEditorActivityEnhanced tests enhanced editor activity analysis
-func TestSQLiteClient_GetEditorActivityEnhanced(t *testing.T) {
+// testGetEditorActivityEnhanced tests enhanced editor activity analysis
+func testGetEditorActivityEnhanced(t *testing.T, open openArchive) {
 	tdb := testutil.SetupTestDBFile(t)
 	defer tdb.Close()
 
-	client, err := irowiki.OpenSQLite(tdb.Path)
-	if err != nil {
-		t.Fatalf("failed to open client: %v", err)
-	}
-	defer client.Close()
+	client := open(t, tdb)
 
 	ctx := context.Background()
 
@@ -351,16 +425,12 @@ func TestSQLiteClient_GetEditorActivityEnhanced(t *testing.T) {
 		activity.BusiestHour, activity.BusiestDay)
 }
 
-// TestSQLiteClient_GetEditorActivityEnhanced_NotFound tests enhanced activity for non-existent editor
-func TestSQLiteClient_GetEditorActivityEnhanced_NotFound(t *testing.T) {
+// testGetEditorActivityEnhancedNotFound tests enhanced activity for non-existent editor
+func testGetEditorActivityEnhancedNotFound(t *testing.T, open openArchive) {
 	tdb := testutil.SetupTestDBFile(t)
 	defer tdb.Close()
 
-	client, err := irowiki.OpenSQLite(tdb.Path)
-	if err != nil {
-		t.Fatalf("failed to open client: %v", err)
-	}
-	defer client.Close()
+	client := open(t, tdb)
 
 	ctx := context.Background()
 
@@ -368,22 +438,121 @@ func TestSQLiteClient_GetEditorActivityEnhanced_NotFound(t *testing.T) {
 	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
 
-	_, err = client.GetEditorActivityEnhanced(ctx, "NonExistentEditor123", start, end)
+	_, err := client.GetEditorActivityEnhanced(ctx, "NonExistentEditor123", start, end)
 	if err != irowiki.ErrNotFound {
 		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
 
-// TestSQLiteClient_StatisticsEnhanced_DateRangeFiltering tests enhanced activity with date filtering
-func TestSQLiteClient_StatisticsEnhanced_DateRangeFiltering(t *testing.T) {
+// testGetEditorActivityEnhancedPatterns tests the hourly, daily
+// and monthly edit counts
+func testGetEditorActivityEnhancedPatterns(t *testing.T, open openArchive) {
 	tdb := testutil.SetupTestDBFile(t)
 	defer tdb.Close()
 
-	client, err := irowiki.OpenSQLite(tdb.Path)
+	// Admin edits at midnight on Wed, Fri, Mon and Tue in January 2020; add
+	// two Tuesday afternoon edits in February
+	for id, ts := range map[int64]string{107: "2020-02-04 15:10:00", 108: "2020-02-04T15:45:00Z"} {
+		if _, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+			VALUES (?, 3, ?, 'Admin', 1, 'edit', 'Poring', 6, 'x', 0)`, id, ts); err != nil {
+			t.Fatalf("failed to insert revision: %v", err)
+		}
+	}
+
+	client := open(t, tdb)
+
+	activity, err := client.GetEditorActivityEnhanced(context.Background(), "Admin", time.Time{}, time.Time{})
 	if err != nil {
-		t.Fatalf("failed to open client: %v", err)
+		t.Fatalf("GetEditorActivityEnhanced failed: %v", err)
+	}
+
+	if len(activity.EditsByHour) != 2 || activity.EditsByHour[0] != 4 || activity.EditsByHour[15] != 2 {
+		t.Errorf("unexpected hourly edits: %v", activity.EditsByHour)
+	}
+	wantDays := map[string]int{"Mon": 1, "Tue": 3, "Wed": 1, "Fri": 1}
+	if len(activity.EditsByDay) != len(wantDays) {
+		t.Errorf("unexpected daily edits: %v", activity.EditsByDay)
+	}
+	for day, n := range wantDays {
+		if activity.EditsByDay[day] != n {
+			t.Errorf("expected %d edits on %s, got %d", n, day, activity.EditsByDay[day])
+		}
+	}
+	if len(activity.EditsByMonth) != 2 || activity.EditsByMonth["2020-01"] != 4 || activity.EditsByMonth["2020-02"] != 2 {
+		t.Errorf("unexpected monthly edits: %v", activity.EditsByMonth)
+	}
+	if activity.BusiestHour != 0 || activity.BusiestDay != "Tue" {
+		t.Errorf("expected busiest 00:00 on Tue, got %02d:00 on %s", activity.BusiestHour, activity.BusiestDay)
+	}
+}
+
+// testGetEditorActivityWithOptions tests the optional sections
+// of editor activity
+func testGetEditorActivityWithOptions(t *testing.T, open openArchive) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Editor changed Main_Page by +4 and Prontera by -1 bytes with summaries;
+	// add an unexplained +4 to Example.png
+	if _, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+		VALUES (107, 4, 105, '2020-01-08 00:00:00', 'Editor', 2, '', 'Image file v2', 14, 'x', 0)`); err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+
+	client := open(t, tdb)
+	ctx := context.Background()
+
+	// Test: Every section
+	activity, err := client.GetEditorActivityWithOptions(ctx, "Editor", irowiki.AllEditorActivity(time.Time{}, time.Time{}))
+	if err != nil {
+		t.Fatalf("GetEditorActivityWithOptions failed: %v", err)
+	}
+	if activity.TotalEdits != 3 || activity.FirstEditedPage != "Main_Page" || activity.PagesEdited != 3 {
+		t.Errorf("unexpected activity: %+v", activity)
+	}
+	if math.Abs(activity.AvgSizeDelta-7.0/3) > 1e-9 || math.Abs(activity.CommentRate-2.0/3) > 1e-9 {
+		t.Errorf("expected size delta 2.33 and comment rate 0.67, got %.2f and %.2f", activity.AvgSizeDelta, activity.CommentRate)
+	}
+	if len(activity.EditsByNamespace) != 2 || activity.EditsByNamespace[0] != 2 || activity.EditsByNamespace[6] != 1 {
+		t.Errorf("unexpected namespaces: %v", activity.EditsByNamespace)
+	}
+	if len(activity.EditsByHour) == 0 || len(activity.TopPages) != 3 {
+		t.Errorf("expected patterns and 3 top pages, got %v and %v", activity.EditsByHour, activity.TopPages)
+	}
+
+	// Test: Page creations count their full size
+	activity, err = client.GetEditorActivityWithOptions(ctx, "Admin", irowiki.EditorActivityOptions{Content: true})
+	if err != nil {
+		t.Fatalf("GetEditorActivityWithOptions failed: %v", err)
 	}
-	defer client.Close()
+	if activity.AvgSizeDelta != (21+30+10+20)/4.0 || activity.CommentRate != 1 {
+		t.Errorf("expected size delta 20.25 and comment rate 1, got %.2f and %.2f", activity.AvgSizeDelta, activity.CommentRate)
+	}
+
+	// Test: Unrequested sections are skipped
+	activity, err = client.GetEditorActivityWithOptions(ctx, "Editor", irowiki.EditorActivityOptions{TopPages: 1})
+	if err != nil {
+		t.Fatalf("GetEditorActivityWithOptions failed: %v", err)
+	}
+	if activity.TotalEdits != 3 || len(activity.TopPages) != 1 {
+		t.Errorf("expected basic stats and 1 top page, got %+v", activity)
+	}
+	if activity.FirstEditedPage != "" || activity.PagesEdited != 0 || activity.EditsByNamespace != nil || activity.EditsByHour != nil {
+		t.Errorf("expected unrequested sections to be empty, got %+v", activity)
+	}
+
+	// Test: Negative top pages are rejected
+	if _, err := client.GetEditorActivityWithOptions(ctx, "Editor", irowiki.EditorActivityOptions{TopPages: -1}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// testStatisticsEnhancedDateRangeFiltering tests enhanced activity with date filtering
+func testStatisticsEnhancedDateRangeFiltering(t *testing.T, open openArchive) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client := open(t, tdb)
 
 	ctx := context.Background()
 
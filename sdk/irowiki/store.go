@@ -0,0 +1,205 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Store provides write access to an archive for maintenance operations.
+// Unlike Client, which always opens archives read-only, a Store opens the
+// database read-write and should only be used by tools that intend to modify it.
+// The store is safe for concurrent use by multiple goroutines.
+type Store interface {
+	// Prune drops old revisions according to the retention policy.
+	// The latest revision of every page is always kept, and parent IDs of the
+	// surviving revisions are re-linked so that history remains a valid chain.
+	Prune(ctx context.Context, policy PrunePolicy) (*PruneResult, error)
+
+	// ExtractSubArchive creates a new SQLite archive at dest containing only the
+	// pages selected by filter, with their full history and referenced files.
+	// dest must not already exist. PostgreSQL stores return ErrNotSupported.
+	ExtractSubArchive(ctx context.Context, dest string, filter SubArchiveFilter) (*ExtractResult, error)
+
+	// CheckExternalLinks records the external links in each page's latest revision,
+	// checks unchecked URLs with checker, and stores the nearest archived copy of
+	// dead ones so renderers can link to it instead. PostgreSQL stores return
+	// ErrNotSupported.
+	CheckExternalLinks(ctx context.Context, checker LinkChecker, opts ExternalLinkOptions) (*ExternalLinkResult, error)
+
+	// RebuildSearchIndex recreates the full-text index with the configured
+	// tokenizer and records the choice in archive_meta so SearchFullText can
+	// adapt its query syntax. PostgreSQL stores return ErrNotSupported.
+	RebuildSearchIndex(ctx context.Context, opts FTSOptions) error
+
+	// RebuildLinks replaces the links table with the links parsed from each
+	// page's latest revision, for archives whose links are missing or were
+	// recorded by an older scraper. ArchiveWriter keeps links current.
+	RebuildLinks(ctx context.Context) (*RebuildLinksResult, error)
+
+	// BulkLoad copies a full scrape, the SQLite archive at srcPath, into
+	// this archive in batches within one transaction, so a failed load
+	// leaves the archive unchanged. Tables and columns missing on either
+	// side are skipped. PostgreSQL stores stream the rows with COPY; SQLite
+	// stores insert them with a prepared statement.
+	BulkLoad(ctx context.Context, srcPath string, opts BulkLoadOptions) (*BulkLoadResult, error)
+
+	// ShardArchive splits the archive into SQLite files in dir, one per
+	// shard of opts, with pages placed by namespace. Opening the main shard
+	// with OpenSQLite attaches the others, so clients see the whole archive.
+	// The shard files must not already exist. PostgreSQL stores return
+	// ErrNotSupported.
+	ShardArchive(ctx context.Context, dir string, opts ShardOptions) (*ShardResult, error)
+
+	// Analyze inspects the archive's table sizes, indexes and full-text
+	// indexes, and recommends what would speed up the SDK's queries.
+	// Archives made by older scrapers lack several indexes it assumes.
+	// PostgreSQL stores return ErrNotSupported.
+	Analyze(ctx context.Context) (*ArchiveAnalysis, error)
+
+	// ApplyRecommended carries out Analyze's recommendations of missing
+	// indexes and planner statistics. Building a missing full-text index is
+	// left to RebuildSearchIndex and reported in Remaining. PostgreSQL stores
+	// return ErrNotSupported.
+	ApplyRecommended(ctx context.Context) (*ApplyResult, error)
+
+	// Close cleanly shuts down the store and releases resources.
+	// After calling Close, the store should not be used.
+	Close() error
+}
+
+// PrunePolicy configures which revisions Prune keeps.
+// A revision is kept if any of the enabled rules match it.
+type PrunePolicy struct {
+	// KeepFirst keeps the first revision (page creation) of every page.
+	KeepFirst bool
+
+	// KeepLast keeps the N most recent revisions of every page.
+	// Set to 0 to disable this rule (the latest revision is still kept).
+	KeepLast int
+
+	// KeepAfter keeps all revisions made on or after this time.
+	// Zero value disables this rule.
+	KeepAfter time.Time
+
+	// Namespaces restricts pruning to pages in these namespaces.
+	// Empty means all namespaces.
+	Namespaces []int
+
+	// DryRun computes the result without modifying the archive.
+	DryRun bool
+
+	// Vacuum reclaims the freed disk space after pruning (SQLite VACUUM,
+	// PostgreSQL VACUUM on the revisions table). Ignored for dry runs.
+	Vacuum bool
+}
+
+// Validate checks if the PrunePolicy is valid.
+func (p *PrunePolicy) Validate() error {
+	if p.KeepLast < 0 {
+		return fmt.Errorf("keep_last must be non-negative")
+	}
+	if p.KeepLast == 0 && p.KeepAfter.IsZero() {
+		return fmt.Errorf("either keep_last or keep_after must be set")
+	}
+	return nil
+}
+
+// PruneResult summarizes the outcome of a Prune operation.
+type PruneResult struct {
+	// PagesAffected is the number of pages that lost at least one revision.
+	PagesAffected int64 `json:"pages_affected"`
+
+	// RevisionsDeleted is the number of revisions removed.
+	RevisionsDeleted int64 `json:"revisions_deleted"`
+
+	// RevisionsKept is the number of revisions remaining in scope.
+	RevisionsKept int64 `json:"revisions_kept"`
+
+	// ParentsRelinked is the number of revisions whose parent ID was rewritten.
+	ParentsRelinked int64 `json:"parents_relinked"`
+
+	// BytesFreed is the total content size of the removed revisions.
+	BytesFreed int64 `json:"bytes_freed"`
+
+	// DryRun indicates no changes were written.
+	DryRun bool `json:"dry_run"`
+}
+
+// pruneCandidate is the minimal revision information needed to plan a prune.
+type pruneCandidate struct {
+	id        int64
+	pageID    int64
+	parentID  *int64
+	timestamp time.Time
+	size      int64
+}
+
+// parentUpdate rewrites the parent of a surviving revision.
+type parentUpdate struct {
+	pageID     int64
+	revisionID int64
+	parentID   *int64
+}
+
+// prunePlan is the set of changes computed by planPrune.
+type prunePlan struct {
+	deletes []int64
+	updates []parentUpdate
+	result  PruneResult
+}
+
+// planPrune decides which revisions to delete and how to re-link parents.
+// Candidates must be ordered by page ID and then chronologically (oldest first).
+func planPrune(revs []pruneCandidate, policy PrunePolicy) *prunePlan {
+	plan := &prunePlan{}
+
+	for start := 0; start < len(revs); {
+		end := start
+		for end < len(revs) && revs[end].pageID == revs[start].pageID {
+			end++
+		}
+		planPagePrune(revs[start:end], policy, plan)
+		start = end
+	}
+
+	plan.result.DryRun = policy.DryRun
+	return plan
+}
+
+// planPagePrune plans the prune of a single page's chronological history.
+func planPagePrune(history []pruneCandidate, policy PrunePolicy, plan *prunePlan) {
+	n := len(history)
+	deleted := make(map[int64]bool)
+	var prevKept *int64
+
+	for i, rev := range history {
+		keep := i == n-1 ||
+			(policy.KeepFirst && i == 0) ||
+			(policy.KeepLast > 0 && i >= n-policy.KeepLast) ||
+			(!policy.KeepAfter.IsZero() && !rev.timestamp.Before(policy.KeepAfter))
+
+		if !keep {
+			plan.deletes = append(plan.deletes, rev.id)
+			plan.result.RevisionsDeleted++
+			plan.result.BytesFreed += rev.size
+			deleted[rev.id] = true
+			continue
+		}
+
+		plan.result.RevisionsKept++
+
+		// Only rewrite parents that point at a deleted revision; the nearest
+		// surviving predecessor takes its place (or none for the oldest survivor).
+		if rev.parentID != nil && deleted[*rev.parentID] {
+			plan.updates = append(plan.updates, parentUpdate{pageID: rev.pageID, revisionID: rev.id, parentID: prevKept})
+			plan.result.ParentsRelinked++
+		}
+		id := rev.id
+		prevKept = &id
+	}
+
+	if len(deleted) > 0 {
+		plan.result.PagesAffected++
+	}
+}
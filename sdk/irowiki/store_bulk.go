@@ -0,0 +1,258 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// defaultBulkBatchSize is the number of rows BulkLoad sends per batch.
+const defaultBulkBatchSize = 10000
+
+// bulkLoadTables are the tables BulkLoad copies, parents before the tables
+// referencing them.
+var bulkLoadTables = []string{
+	"pages", "revisions", "files", "links",
+	"archive_meta", "wikis", "deleted_pages", "page_protection",
+	"external_links", "page_html", "interwiki_links",
+}
+
+// BulkLoadOptions configures Store.BulkLoad.
+type BulkLoadOptions struct {
+	// BatchSize is the number of rows sent to the database at a time.
+	// Default: 10000.
+	BatchSize int
+
+	// Progress, if set, is told after each batch the rows loaded into the
+	// table being loaded (the stage) so far, and its total rows.
+	Progress Progress
+}
+
+// Validate checks if the BulkLoadOptions are valid.
+func (o *BulkLoadOptions) Validate() error {
+	if o.BatchSize < 0 {
+		return fmt.Errorf("batch_size must be non-negative")
+	}
+	return nil
+}
+
+// BulkLoadResult summarizes a BulkLoad.
+type BulkLoadResult struct {
+	// Rows is the number of rows loaded into each table.
+	Rows map[string]int64 `json:"rows"`
+}
+
+// bulkTable is a table BulkLoad copies: the columns the source and
+// destination share, in source order, and the destination column types.
+type bulkTable struct {
+	name    string
+	columns []string
+	types   []string
+	total   int64
+}
+
+// planBulkLoad lists the tables to copy from src, a SQLite scrape, given
+// the destination's columns (and their types) for a table, or none if the
+// destination lacks it.
+func planBulkLoad(ctx context.Context, src *sql.DB, destColumns func(table string) (map[string]string, error)) ([]bulkTable, error) {
+	var plan []bulkTable
+	for _, name := range bulkLoadTables {
+		exists, err := sqliteTableExists(ctx, src, "main", name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		dest, err := destColumns(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(dest) == 0 {
+			continue
+		}
+
+		rows, err := src.QueryContext(ctx, "SELECT name FROM pragma_table_info(?) ORDER BY cid", name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		table := bulkTable{name: name}
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+			}
+			if typ, ok := dest[column]; ok {
+				table.columns = append(table.columns, column)
+				table.types = append(table.types, typ)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+
+		if err := src.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+name).Scan(&table.total); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		plan = append(plan, table)
+	}
+	return plan, nil
+}
+
+// streamBulkTable reads a table from src in rowid order and passes its rows
+// to flush in batches, converted with convert for the destination types.
+func streamBulkTable(ctx context.Context, src *sql.DB, table bulkTable, opts BulkLoadOptions, convert func(v any, typ string) any, flush func(batch [][]any) error) (int64, error) {
+	quoted := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		quoted[i] = `"` + column + `"`
+	}
+	rows, err := src.QueryContext(ctx, "SELECT "+strings.Join(quoted, ", ")+" FROM "+table.name+" ORDER BY rowid")
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	var loaded int64
+	batch := make([][]any, 0, opts.BatchSize)
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := flush(batch); err != nil {
+			return fmt.Errorf("%w: failed to load %s: %v", ErrDatabaseError, table.name, err)
+		}
+		loaded += int64(len(batch))
+		batch = batch[:0]
+		reportProgress(opts.Progress, loaded, table.total, table.name)
+		return nil
+	}
+
+	for rows.Next() {
+		values := make([]any, len(table.columns))
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return loaded, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		for i, v := range values {
+			values[i] = convert(v, table.types[i])
+		}
+		batch = append(batch, values)
+		if len(batch) == opts.BatchSize {
+			if err := send(); err != nil {
+				return loaded, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return loaded, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return loaded, send()
+}
+
+// BulkLoad copies a SQLite scrape into the archive with batched INSERTs.
+func (s *sqliteStore) BulkLoad(ctx context.Context, srcPath string, opts BulkLoadOptions) (*BulkLoadResult, error) {
+	if err := s.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBulkBatchSize
+	}
+
+	release, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	src, err := openBulkSource(ctx, srcPath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	plan, err := planBulkLoad(ctx, src, func(table string) (map[string]string, error) {
+		return sqliteColumnTypes(ctx, s.db, table)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	result := &BulkLoadResult{Rows: make(map[string]int64)}
+	for _, table := range plan {
+		query := fmt.Sprintf(`INSERT INTO %s ("%s") VALUES (%s)`, table.name,
+			strings.Join(table.columns, `", "`), strings.TrimSuffix(strings.Repeat("?, ", len(table.columns)), ", "))
+		insert, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		n, err := streamBulkTable(ctx, src, table, opts, func(v any, _ string) any { return v }, func(batch [][]any) error {
+			for _, row := range batch {
+				if _, err := insert.ExecContext(ctx, row...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		insert.Close()
+		if err != nil {
+			return nil, err
+		}
+		result.Rows[table.name] = n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return result, nil
+}
+
+// sqliteColumnTypes returns the columns of a table and their declared
+// types, or none if the table doesn't exist.
+func sqliteColumnTypes(ctx context.Context, db *sql.DB, table string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, type FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		columns[name] = typ
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return columns, nil
+}
+
+// openBulkSource opens the SQLite scrape BulkLoad reads from.
+func openBulkSource(ctx context.Context, path string) (*sql.DB, error) {
+	src, err := sql.Open("sqlite", path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open source: %v", ErrConnectionFailed, err)
+	}
+	if err := src.PingContext(ctx); err != nil {
+		src.Close()
+		return nil, fmt.Errorf("%w: failed to open source: %v", ErrConnectionFailed, err)
+	}
+	return src, nil
+}
@@ -0,0 +1,85 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteStore_BulkLoad tests loading a scrape into an empty archive
+func TestSQLiteStore_BulkLoad(t *testing.T) {
+	src := testutil.SetupTestDBFile(t)
+	defer src.Close()
+
+	dest := testutil.SetupTestDBFile(t)
+	defer dest.Close()
+	for _, table := range []string{"files", "revisions", "pages"} {
+		if _, err := dest.DB.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("failed to empty %s: %v", table, err)
+		}
+	}
+
+	store, err := irowiki.OpenSQLiteStore(dest.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	// Test: Every row is loaded, with progress after each batch
+	var batches []int64
+	result, err := store.BulkLoad(ctx, src.Path, irowiki.BulkLoadOptions{
+		BatchSize: 3,
+		Progress: irowiki.ProgressFunc(func(loaded, total int64, table string) {
+			if table == "revisions" {
+				batches = append(batches, loaded)
+				if total != 7 {
+					t.Errorf("expected 7 revisions in total, got %d", total)
+				}
+			}
+		}),
+	})
+	if err != nil {
+		t.Fatalf("BulkLoad failed: %v", err)
+	}
+	if result.Rows["pages"] != 5 || result.Rows["revisions"] != 7 {
+		t.Errorf("expected 5 pages and 7 revisions, got %v", result.Rows)
+	}
+	if len(batches) != 3 || batches[2] != 7 {
+		t.Errorf("expected progress at 3, 6 and 7 revisions, got %v", batches)
+	}
+
+	client, err := irowiki.OpenSQLite(dest.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	page, err := client.GetPage(ctx, "Prontera")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.Content == "" {
+		t.Error("expected loaded page to have content")
+	}
+
+	// Test: A failed load leaves the archive unchanged
+	if _, err := store.BulkLoad(ctx, src.Path, irowiki.BulkLoadOptions{}); !errors.Is(err, irowiki.ErrDatabaseError) {
+		t.Errorf("expected ErrDatabaseError loading duplicate rows, got %v", err)
+	}
+	var revisions int
+	if err := dest.DB.QueryRow("SELECT COUNT(*) FROM revisions").Scan(&revisions); err != nil {
+		t.Fatalf("failed to count revisions: %v", err)
+	}
+	if revisions != 7 {
+		t.Errorf("expected 7 revisions after failed load, got %d", revisions)
+	}
+
+	// Test: Negative batch sizes are rejected
+	if _, err := store.BulkLoad(ctx, src.Path, irowiki.BulkLoadOptions{BatchSize: -1}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
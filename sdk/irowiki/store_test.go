@@ -0,0 +1,199 @@
+package irowiki_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// addMainPageRevisions extends Main_Page history to five revisions.
+func addMainPageRevisions(t *testing.T, tdb *testutil.TestDB) {
+	t.Helper()
+
+	baseTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	extra := []struct {
+		id     int64
+		parent int64
+		offset time.Duration
+	}{
+		{1001, 101, 48 * time.Hour},
+		{1002, 1001, 96 * time.Hour},
+		{1003, 1002, 144 * time.Hour},
+	}
+
+	for _, r := range extra {
+		_, err := tdb.DB.Exec(
+			`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+			 VALUES (?, 1, ?, ?, 'Editor', 2, 'More edits', 'Welcome!', 8, 'x', 0)`,
+			r.id, r.parent, baseTime.Add(r.offset),
+		)
+		if err != nil {
+			t.Fatalf("failed to insert revision: %v", err)
+		}
+	}
+}
+
+// TestSQLiteStore_Prune_KeepFirstAndLast tests pruning with first + last N retention
+func TestSQLiteStore_Prune_KeepFirstAndLast(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+	addMainPageRevisions(t, tdb)
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	// Test: Dry run reports without deleting
+	result, err := store.Prune(ctx, irowiki.PrunePolicy{KeepFirst: true, KeepLast: 2, DryRun: true})
+	if err != nil {
+		t.Fatalf("Prune dry run failed: %v", err)
+	}
+	if result.RevisionsDeleted != 2 {
+		t.Errorf("expected 2 revisions to delete, got %d", result.RevisionsDeleted)
+	}
+	if !result.DryRun {
+		t.Error("expected DryRun to be set")
+	}
+
+	var count int
+	tdb.DB.QueryRow("SELECT COUNT(*) FROM revisions WHERE page_id = 1").Scan(&count)
+	if count != 5 {
+		t.Fatalf("dry run modified the archive: expected 5 revisions, got %d", count)
+	}
+
+	// Test: Real prune keeps 100 (first), 1002 and 1003 (last 2)
+	result, err = store.Prune(ctx, irowiki.PrunePolicy{KeepFirst: true, KeepLast: 2})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.PagesAffected != 1 {
+		t.Errorf("expected 1 page affected, got %d", result.PagesAffected)
+	}
+	if result.RevisionsDeleted != 2 {
+		t.Errorf("expected 2 revisions deleted, got %d", result.RevisionsDeleted)
+	}
+	if result.ParentsRelinked != 1 {
+		t.Errorf("expected 1 parent relinked, got %d", result.ParentsRelinked)
+	}
+
+	var parent int64
+	if err := tdb.DB.QueryRow("SELECT parent_id FROM revisions WHERE revision_id = 1002").Scan(&parent); err != nil {
+		t.Fatalf("failed to query parent: %v", err)
+	}
+	if parent != 100 {
+		t.Errorf("expected revision 1002 to be re-linked to 100, got %d", parent)
+	}
+}
+
+// TestSQLiteStore_Prune_KeepAfter tests pruning by date
+func TestSQLiteStore_Prune_KeepAfter(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+	addMainPageRevisions(t, tdb)
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	cutoff := time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC)
+	result, err := store.Prune(context.Background(), irowiki.PrunePolicy{KeepAfter: cutoff})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	// Main_Page loses 100, 101 and 1001; Prontera loses 102. Single-revision pages keep their latest.
+	if result.RevisionsDeleted != 4 {
+		t.Errorf("expected 4 revisions deleted, got %d", result.RevisionsDeleted)
+	}
+	if result.PagesAffected != 2 {
+		t.Errorf("expected 2 pages affected, got %d", result.PagesAffected)
+	}
+
+	var parent *int64
+	if err := tdb.DB.QueryRow("SELECT parent_id FROM revisions WHERE revision_id = 1002").Scan(&parent); err != nil {
+		t.Fatalf("failed to query parent: %v", err)
+	}
+	if parent != nil {
+		t.Errorf("expected oldest surviving revision to have no parent, got %d", *parent)
+	}
+}
+
+// TestSQLiteStore_Prune_SearchIndex tests that pruning an archive with the
+// search index triggers of schema/sqlite leaves the latest revision indexed,
+// though re-linking parents fires them with older revisions
+func TestSQLiteStore_Prune_SearchIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.db")
+	db := openMigratedArchive(t, path)
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT INTO pages (page_id, namespace, title) VALUES (1, 0, 'Poring')"); err != nil {
+		t.Fatalf("failed to insert page: %v", err)
+	}
+	baseTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= 6; i++ {
+		var parent any
+		if i > 1 {
+			parent = i - 1
+		}
+		_, err := db.Exec(
+			`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, content, size, sha1)
+			 VALUES (?, 1, ?, ?, ?, 8, 'x')`,
+			i, parent, baseTime.Add(time.Duration(i)*time.Hour), fmt.Sprintf("content%d", i),
+		)
+		if err != nil {
+			t.Fatalf("failed to insert revision: %v", err)
+		}
+	}
+
+	store, err := irowiki.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	// Revision 5 is re-linked to 1, which fires the update trigger
+	result, err := store.Prune(context.Background(), irowiki.PrunePolicy{KeepFirst: true, KeepLast: 2})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.ParentsRelinked != 1 {
+		t.Fatalf("expected 1 parent relinked, got %d", result.ParentsRelinked)
+	}
+
+	var content string
+	var count int
+	if err := db.QueryRow("SELECT content, COUNT(*) FROM pages_fts WHERE page_id = 1").Scan(&content, &count); err != nil {
+		t.Fatalf("failed to query search index: %v", err)
+	}
+	if count != 1 || content != "content6" {
+		t.Errorf("expected the latest revision indexed once, got %q (%d rows)", content, count)
+	}
+}
+
+// TestSQLiteStore_Prune_InvalidPolicy tests policy validation
+func TestSQLiteStore_Prune_InvalidPolicy(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	_, err = store.Prune(context.Background(), irowiki.PrunePolicy{KeepFirst: true})
+	if err == nil {
+		t.Error("expected error for policy without keep_last or keep_after")
+	}
+}
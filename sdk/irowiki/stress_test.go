@@ -0,0 +1,198 @@
+package irowiki_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// The stress test runs briefly with every "go test"; raise these, with
+// -race, to hammer a shared client harder:
+//
+//	go test ./irowiki -race -run Stress -stress.goroutines=64 -stress.iterations=500
+var (
+	stressGoroutines = flag.Int("stress.goroutines", 8, "goroutines sharing a client in the stress test")
+	stressIterations = flag.Int("stress.iterations", 10, "rounds of mixed reads each stress test goroutine makes")
+)
+
+// stressReads are the calls the stress test mixes. Those whose result in
+// the test fixture is easily told check it, to catch mixed-up rows.
+var stressReads = []struct {
+	name string
+	call func(ctx context.Context, c irowiki.Client) error
+}{
+	{"GetPage", func(ctx context.Context, c irowiki.Client) error {
+		page, err := c.GetPage(ctx, "Prontera")
+		if err == nil && page.ID != 2 {
+			err = fmt.Errorf("got page %d", page.ID)
+		}
+		return err
+	}},
+	{"Search", func(ctx context.Context, c irowiki.Client) error {
+		_, err := c.Search(ctx, irowiki.SearchOptions{Query: "Por", Namespace: -1, Limit: 5})
+		return err
+	}},
+	{"SearchRegex", func(ctx context.Context, c irowiki.Client) error {
+		// Each goroutine compiles its own pattern, racing for the regexp cache
+		_, err := c.Search(ctx, irowiki.SearchOptions{Query: fmt.Sprintf("^(P|M).{0,%d}", ctx.Value(stressWorker{}).(int)%5+3), MatchMode: irowiki.MatchRegex, Namespace: -1})
+		return err
+	}},
+	{"GetPagesByTitle", func(ctx context.Context, c irowiki.Client) error {
+		resolved, err := c.GetPagesByTitle(ctx, []string{"main Page", "Poring", "Nonexistent"})
+		if err == nil && (!resolved[0].Found() || resolved[2].Found()) {
+			err = fmt.Errorf("got %+v", resolved)
+		}
+		return err
+	}},
+	{"GetPageHistory", func(ctx context.Context, c irowiki.Client) error {
+		revs, err := c.GetPageHistory(ctx, "Main_Page", irowiki.HistoryOptions{})
+		if err == nil && len(revs) != 2 {
+			err = fmt.Errorf("got %d revisions", len(revs))
+		}
+		return err
+	}},
+	{"GetRevisionDiff", func(ctx context.Context, c irowiki.Client) error {
+		_, err := c.GetRevisionDiff(ctx, 100, 101)
+		return err
+	}},
+	{"GetStatistics", func(ctx context.Context, c irowiki.Client) error {
+		stats, err := c.GetStatistics(ctx)
+		if err == nil && stats.TotalPages != 5 {
+			err = fmt.Errorf("got %d pages", stats.TotalPages)
+		}
+		return err
+	}},
+	{"GetBacklinks", func(ctx context.Context, c irowiki.Client) error {
+		_, err := c.GetBacklinks(ctx, "Main_Page")
+		return err
+	}},
+	{"ExtractTemplates", func(ctx context.Context, c irowiki.Client) error {
+		_, err := c.ExtractTemplates(ctx, "Poring", "Infobox monster")
+		return err
+	}},
+	{"ExportRows", func(ctx context.Context, c irowiki.Client) error {
+		return c.ExportRows(ctx, irowiki.ExportOptions{Table: "revisions", Columns: []string{"revision_id", "tokens"}}, csv.NewWriter(io.Discard))
+	}},
+	{"Snapshot", func(ctx context.Context, c irowiki.Client) error {
+		snap, err := c.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		defer snap.Close()
+		_, err = snap.GetPage(ctx, "Poring")
+		return err
+	}},
+}
+
+// stressWorker is the context key of the stress test goroutine's number.
+type stressWorker struct{}
+
+// stressClient hammers client with mixed reads from concurrent goroutines
+// and returns the errors of failed calls.
+func stressClient(client irowiki.Client, goroutines, iterations int) []error {
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			ctx := context.WithValue(context.Background(), stressWorker{}, g)
+			for i := 0; i < iterations; i++ {
+				// Each goroutine walks the calls from a different start
+				read := stressReads[(g+i)%len(stressReads)]
+				if err := read.call(ctx, client); err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("%s: %w", read.name, err))
+					mu.Unlock()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	return errs
+}
+
+// TestSQLiteClient_Stress tests that one client serves concurrent mixed
+// reads, with and without interceptors and query plan capture
+func TestSQLiteClient_Stress(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.ExplainThreshold = time.Nanosecond
+	clients := map[string]func() (irowiki.Client, error){
+		"plain": func() (irowiki.Client, error) { return irowiki.OpenSQLite(tdb.Path) },
+		"intercepted": func() (irowiki.Client, error) {
+			client, err := irowiki.OpenSQLiteWithOptions(tdb.Path, opts)
+			if err != nil {
+				return nil, err
+			}
+			logger := log.New(io.Discard, "", 0)
+			return irowiki.WithInterceptor(client, irowiki.LoggingInterceptor(irowiki.LogOptions{Logger: logger})), nil
+		},
+	}
+	for name, open := range clients {
+		t.Run(name, func(t *testing.T) {
+			client, err := open()
+			if err != nil {
+				t.Fatalf("failed to open client: %v", err)
+			}
+			defer client.Close()
+
+			for _, err := range stressClient(client, *stressGoroutines, *stressIterations) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestSQLiteClient_CloseDuringReads tests that closing a client in use ends
+// its calls with errors rather than panics or hangs, and that calls after
+// Close fail with ErrClosed
+func TestSQLiteClient_CloseDuringReads(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+
+	done := make(chan []error)
+	go func() {
+		done <- stressClient(client, *stressGoroutines, *stressIterations)
+	}()
+	time.Sleep(time.Millisecond)
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Calls that were running may fail either way, depending on whether
+	// their first query had started
+	for _, err := range <-done {
+		if !errors.Is(err, irowiki.ErrClosed) && !errors.Is(err, irowiki.ErrDatabaseError) {
+			t.Errorf("expected ErrClosed or ErrDatabaseError, got %v", err)
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), stressWorker{}, 0)
+	for _, read := range stressReads {
+		if err := read.call(ctx, client); !errors.Is(err, irowiki.ErrClosed) {
+			t.Errorf("%s: expected ErrClosed after Close, got %v", read.name, err)
+		}
+	}
+	if err := client.Close(); !errors.Is(err, irowiki.ErrClosed) {
+		t.Errorf("expected ErrClosed from a second Close, got %v", err)
+	}
+}
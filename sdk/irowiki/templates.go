@@ -0,0 +1,82 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+// TemplateCall is one use of a template on a page, such as the {{Monster}}
+// infobox of a monster's page, with its parameters.
+type TemplateCall struct {
+	// Name is the template's name as written on the page.
+	Name string `json:"name"`
+
+	// Params maps the names of the parameters, or their positions ("1",
+	// "2", ...) for positional ones, to their wikitext, trimmed and without
+	// comments. Of parameters given twice, the last wins, as in MediaWiki.
+	Params map[string]string `json:"params"`
+
+	// Line is the line of the page's wikitext the call starts on.
+	Line int `json:"line"`
+}
+
+// templateTitle returns the full title, as archives store it, of the page a
+// {{name}} transclusion refers to, or "" for parser functions.
+func templateTitle(name string) string {
+	title := transclusionTitle(strings.TrimSpace(name))
+	if title == "" {
+		return ""
+	}
+	return FullTitle(ParseTitle(title))
+}
+
+// extractTemplates parses the latest revision of a page and returns its
+// calls to a template, including calls nested in other templates.
+func extractTemplates(ctx context.Context, db querier, scope wikiScope, title, templateName string, placeholder func(n int) string) ([]TemplateCall, error) {
+	want := templateTitle(templateName)
+	if want == "" {
+		return nil, fmt.Errorf("%w: template name must not be empty or a parser function", ErrInvalidInput)
+	}
+
+	page, err := resolveTitle(ctx, db, scope, title, true, placeholder)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := wikitext.Parse(page.Content)
+	calls := []TemplateCall{}
+	for _, t := range wikitext.Find[*wikitext.Template](doc) {
+		if templateTitle(t.Name) != want {
+			continue
+		}
+		call := TemplateCall{Name: t.Name, Params: make(map[string]string, len(t.Params)), Line: doc.Line(t)}
+		for _, p := range t.Params {
+			call.Params[p.Name] = strings.TrimSpace(wikitextCommentPattern.ReplaceAllString(p.Value, ""))
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// ExtractTemplates returns the calls to a template on a page, with their parameters.
+func (c *sqliteClient) ExtractTemplates(ctx context.Context, title, templateName string) ([]TemplateCall, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return extractTemplates(ctx, c.db, c.wiki, title, templateName, placeholder)
+}
+
+// ExtractTemplates returns the calls to a template on a page, with their parameters.
+func (c *postgresClient) ExtractTemplates(ctx context.Context, title, templateName string) ([]TemplateCall, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return extractTemplates(ctx, c.db, c.wiki, title, templateName, placeholder)
+}
@@ -0,0 +1,74 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_ExtractTemplates tests reading infobox parameters from a page
+func TestSQLiteClient_ExtractTemplates(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	content := "{{Infobox monster\n| name = Poring\n| hp = 50 <!-- kRO: 55 -->\n| drops = {{Item|Jellopy}}\n}}\n" +
+		"'''Poring''' is a [[monster]].\n{{Template:infobox_monster|Angeling|hp=55|hp=60}}"
+	if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (10, 0, 'Poring_Family', 0)`); err != nil {
+		t.Fatalf("failed to insert page: %v", err)
+	}
+	_, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES (200, 10, '2021-01-01 00:00:00', ?, ?, 'x')`,
+		content, len(content))
+	if err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Calls are found however the template is named
+	calls, err := client.ExtractTemplates(ctx, "Poring Family", "Template:Infobox_monster")
+	if err != nil {
+		t.Fatalf("ExtractTemplates failed: %v", err)
+	}
+	want := []irowiki.TemplateCall{
+		{Name: "Infobox monster", Params: map[string]string{"name": "Poring", "hp": "50", "drops": "{{Item|Jellopy}}"}, Line: 1},
+		{Name: "Template:infobox_monster", Params: map[string]string{"1": "Angeling", "hp": "60"}, Line: 7},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("unexpected calls:\n got %+v\nwant %+v", calls, want)
+	}
+
+	// Test: Nested calls are found
+	calls, err = client.ExtractTemplates(ctx, "Poring_Family", "item")
+	if err != nil {
+		t.Fatalf("ExtractTemplates failed: %v", err)
+	}
+	if len(calls) != 1 || calls[0].Params["1"] != "Jellopy" {
+		t.Errorf("expected the nested Item call, got %+v", calls)
+	}
+
+	// Test: Pages without the template have no calls
+	calls, err = client.ExtractTemplates(ctx, "Prontera", "Infobox monster")
+	if err != nil {
+		t.Fatalf("ExtractTemplates failed: %v", err)
+	}
+	if calls == nil || len(calls) != 0 {
+		t.Errorf("expected an empty list, got %#v", calls)
+	}
+
+	if _, err := client.ExtractTemplates(ctx, "Nonexistent", "Infobox monster"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := client.ExtractTemplates(ctx, "Poring_Family", "#if:"); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for a parser function, got %v", err)
+	}
+}
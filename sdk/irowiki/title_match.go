@@ -0,0 +1,95 @@
+package irowiki
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"modernc.org/sqlite"
+)
+
+// Title search match modes (SearchOptions.MatchMode).
+const (
+	MatchSubstring = "substring"
+	MatchPrefix    = "prefix"
+	MatchExact     = "exact"
+	MatchRegex     = "regex"
+)
+
+func init() {
+	// SQLite has no built-in REGEXP implementation. The name is prefixed so
+	// applications registering their own regexp() don't collide with it.
+	sqlite.MustRegisterDeterministicScalarFunction("irowiki_regexp", 2, sqliteRegexp)
+}
+
+// regexpCacheSize is the number of patterns regexpCache holds before it's
+// emptied.
+const regexpCacheSize = 64
+
+// regexpCache holds the patterns compiled by sqliteRegexp, which a search
+// applies to every row. It holds several so concurrent searches with
+// different patterns don't recompile theirs for every row, and matches run
+// outside the lock, as a Regexp is safe for concurrent use.
+var regexpCache struct {
+	sync.RWMutex
+	patterns map[string]*regexp.Regexp
+}
+
+// sqliteRegexp implements irowiki_regexp(pattern, text), a case-insensitive
+// regular expression match.
+func sqliteRegexp(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	pattern, _ := args[0].(string)
+	text, _ := args[1].(string)
+
+	regexpCache.RLock()
+	re := regexpCache.patterns[pattern]
+	regexpCache.RUnlock()
+	if re == nil {
+		var err error
+		if re, err = regexp.Compile("(?i)" + pattern); err != nil {
+			return nil, err
+		}
+		regexpCache.Lock()
+		if regexpCache.patterns == nil || len(regexpCache.patterns) >= regexpCacheSize {
+			regexpCache.patterns = make(map[string]*regexp.Regexp, regexpCacheSize)
+		}
+		regexpCache.patterns[pattern] = re
+		regexpCache.Unlock()
+	}
+	return re.MatchString(text), nil
+}
+
+// validateMatchMode checks a title search's match mode, and that a regex
+// query compiles.
+func validateMatchMode(mode, query string) error {
+	switch mode {
+	case "", MatchSubstring, MatchPrefix, MatchExact:
+		return nil
+	case MatchRegex:
+		if _, err := regexp.Compile(query); err != nil {
+			return fmt.Errorf("invalid regex query: %v", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid match_mode: must be 'substring', 'prefix', 'exact', or 'regex'")
+	}
+}
+
+// titleMatch returns the condition matching p.title against the query of a
+// title search, using placeholder for its argument. substring is the
+// backend's historical substring condition, like its case-insensitive LIKE
+// condition and regex its case-insensitive regular expression condition,
+// each formatted with the column and placeholder.
+func titleMatch(opts SearchOptions, placeholder string, substring, like, regex string) (string, interface{}) {
+	switch opts.MatchMode {
+	case MatchPrefix:
+		return fmt.Sprintf(like, "p.title", placeholder) + ` ESCAPE '\'`, escapeLike(opts.Query) + "%"
+	case MatchExact:
+		return fmt.Sprintf("LOWER(p.title) = LOWER(%s)", placeholder), opts.Query
+	case MatchRegex:
+		return fmt.Sprintf(regex, "p.title", placeholder), opts.Query
+	default:
+		return fmt.Sprintf(substring, "p.title", placeholder), "%" + opts.Query + "%"
+	}
+}
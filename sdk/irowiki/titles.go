@@ -0,0 +1,237 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Numbers of the namespaces the SDK treats specially.
+const (
+	NamespaceMain     = 0
+	NamespaceFile     = 6
+	NamespaceTemplate = 10
+	NamespaceCategory = 14
+	NamespaceModule   = 828
+)
+
+// NamespaceTable maps a wiki's namespace numbers to their names, for
+// converting between full titles ("File:Example.png") and the namespace
+// and title pairs archives store.
+type NamespaceTable struct {
+	// names are the canonical names, with spaces
+	names map[int]string
+
+	// ids are the namespaces of the lower-cased names and aliases
+	ids map[string]int
+}
+
+// NewNamespaceTable creates a table from a wiki's namespace names, such as
+// those listed by the API's siteinfo, and aliases, such as "Image" for the
+// File namespace. Names may be written with spaces or underscores.
+func NewNamespaceTable(names map[int]string, aliases map[string]int) *NamespaceTable {
+	t := &NamespaceTable{names: make(map[int]string, len(names)), ids: make(map[string]int, len(names)+len(aliases))}
+	for ns, name := range names {
+		name = spaced(name)
+		t.names[ns] = name
+		if name != "" {
+			t.ids[strings.ToLower(name)] = ns
+		}
+	}
+	for alias, ns := range aliases {
+		if alias = spaced(alias); alias != "" {
+			t.ids[strings.ToLower(alias)] = ns
+		}
+	}
+	return t
+}
+
+// StandardNamespaces is the table of MediaWiki's standard namespaces and
+// Scribunto's Module namespace. The project namespace goes by its
+// canonical name, "Project", and File by its old name, "Image", too.
+var StandardNamespaces = NewNamespaceTable(map[int]string{
+	0:   "",
+	1:   "Talk",
+	2:   "User",
+	3:   "User talk",
+	4:   "Project",
+	5:   "Project talk",
+	6:   "File",
+	7:   "File talk",
+	8:   "MediaWiki",
+	9:   "MediaWiki talk",
+	10:  "Template",
+	11:  "Template talk",
+	12:  "Help",
+	13:  "Help talk",
+	14:  "Category",
+	15:  "Category talk",
+	828: "Module",
+	829: "Module talk",
+}, map[string]int{"Image": 6, "Image talk": 7})
+
+// Name returns the name of a namespace, "" for the main namespace, and
+// whether the table knows it.
+func (t *NamespaceTable) Name(ns int) (string, bool) {
+	name, ok := t.names[ns]
+	return name, ok
+}
+
+// ParseTitle splits a full title into its namespace and its title within
+// the namespace, in the form archives store titles: without the prefix,
+// with underscores and the first letter upper-cased. A title without a
+// known prefix is in the main namespace, and a leading colon, as in
+// [[:Category:Monsters]], is dropped.
+func (t *NamespaceTable) ParseTitle(full string) (ns int, title string) {
+	full = strings.TrimPrefix(strings.TrimSpace(full), ":")
+	if prefix, rest, ok := strings.Cut(full, ":"); ok {
+		if id, ok := t.ids[strings.ToLower(spaced(prefix))]; ok && id != NamespaceMain {
+			return id, storedTitle(rest)
+		}
+	}
+	return NamespaceMain, storedTitle(full)
+}
+
+// FullTitle returns a namespace's title with the namespace's prefix, with
+// underscores as archives store titles. Titles already carrying the prefix,
+// as in archives that store titles with it, and titles of the main
+// namespace or of namespaces the table doesn't know get no prefix.
+func (t *NamespaceTable) FullTitle(ns int, title string) string {
+	name, ok := t.names[ns]
+	if !ok || name == "" {
+		return storedTitle(title)
+	}
+	if parsed, _ := t.ParseTitle(title); parsed == ns {
+		return storedTitle(title)
+	}
+	return underscoredTitle(name) + ":" + storedTitle(title)
+}
+
+// ParseTitle splits a full title into its namespace and title with the
+// standard namespaces. See NamespaceTable.ParseTitle.
+//
+// Example:
+//
+//	ns, title := irowiki.ParseTitle("File:Example.png") // 6, "Example.png"
+func ParseTitle(full string) (ns int, title string) {
+	return StandardNamespaces.ParseTitle(full)
+}
+
+// FullTitle returns a title with the prefix of its standard namespace.
+// See NamespaceTable.FullTitle.
+//
+// Example:
+//
+//	irowiki.FullTitle(14, "Monsters") // "Category:Monsters"
+func FullTitle(ns int, title string) string {
+	return StandardNamespaces.FullTitle(ns, title)
+}
+
+// namespacedTitle returns the namespace and unprefixed title a page may be
+// stored under when it isn't stored under the title as given, or -1 for a
+// title without a namespace prefix, which matches no page.
+func namespacedTitle(title string) (int, string) {
+	ns, name := ParseTitle(title)
+	if ns == NamespaceMain {
+		return -1, ""
+	}
+	return ns, name
+}
+
+// titleForms returns the titles a page named title may be stored under: as
+// given, in NFC, and the variants titleVariants lists.
+func titleForms(title string) []string {
+	forms := []string{title}
+	for _, v := range append([]string{nfc(title)}, titleVariants(title)...) {
+		if !slices.Contains(forms, v) {
+			forms = append(forms, v)
+		}
+	}
+	return forms
+}
+
+// titleCondition returns the condition by which every lookup of a page by
+// title finds it, for the pages table aliased as alias: stored under one of
+// the title's forms, or, for a title with a namespace prefix, under one of
+// the forms of its unprefixed title in the namespace ParseTitle finds. order
+// ranks the page stored under the title as given first, then pages of the
+// main namespace, where unprefixed titles are shared, as GetPagesByTitle
+// does for titles matching more than one. Placeholders are numbered from n.
+func titleCondition(alias, title string, n int, placeholder func(n int) string) (cond, order string, args []any) {
+	in := func(titles []string) string {
+		marks := make([]string, len(titles))
+		for i, t := range titles {
+			args = append(args, t)
+			marks[i] = placeholder(n + len(args) - 1)
+		}
+		return alias + ".title IN (" + strings.Join(marks, ", ") + ")"
+	}
+	cond = in(titleForms(title))
+	if ns, name := namespacedTitle(title); ns >= 0 {
+		args = append(args, ns)
+		cond += " OR (" + alias + ".namespace = " + placeholder(n+len(args)-1) + " AND " + in(titleForms(name)) + ")"
+	}
+	args = append(args, title)
+	order = alias + ".title = " + placeholder(n+len(args)-1) + " DESC, " + alias + ".namespace = 0 DESC"
+	return "(" + cond + ")", order, args
+}
+
+// resolveTitle returns the ID, namespace, title and redirect flag of the
+// page a title names, found as GetPage finds it, or ErrNotFound. With
+// content, the page also has the wikitext of its latest revision and the
+// hints classifyPage sets from it.
+func resolveTitle(ctx context.Context, db querier, scope wikiScope, title string, content bool, placeholder func(n int) string) (*Page, error) {
+	contentColumn := "NULL"
+	if content {
+		contentColumn = "(SELECT r.content FROM revisions r WHERE r.page_id = p.page_id ORDER BY r.timestamp DESC, r.revision_id DESC LIMIT 1)"
+	}
+	cond, order, args := titleCondition("p", title, 1, placeholder)
+	var page Page
+	var text sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT p.page_id, p.namespace, p.title, p.is_redirect, `+contentColumn+`
+		FROM pages p
+		WHERE `+cond+scope.filter("p")+`
+		ORDER BY `+order+`
+		LIMIT 1`, args...).Scan(&page.ID, &page.Namespace, &page.Title, &page.IsRedirect, &text)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	if content {
+		page.Content = text.String
+		classifyPage(&page)
+	}
+	return &page, nil
+}
+
+// storedTitle returns a title as archives store it: in NFC, trimmed, with
+// runs of spaces and underscores collapsed to one underscore, and the first
+// letter upper-cased.
+func storedTitle(title string) string {
+	title = underscoredTitle(nfc(title))
+	if first, size := utf8.DecodeRuneInString(title); first != utf8.RuneError {
+		title = string(unicode.ToUpper(first)) + title[size:]
+	}
+	return title
+}
+
+// underscoredTitle trims a title and collapses its runs of spaces and
+// underscores to one underscore.
+func underscoredTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(title, func(r rune) bool {
+		return r == '_' || unicode.IsSpace(r)
+	}), "_")
+}
+
+// spaced trims a namespace name and collapses its runs of spaces and
+// underscores to one space.
+func spaced(name string) string {
+	return strings.ReplaceAll(underscoredTitle(name), "_", " ")
+}
@@ -0,0 +1,196 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestParseTitle tests splitting full titles into namespace and title
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		full  string
+		ns    int
+		title string
+	}{
+		{"File:Example.png", 6, "Example.png"},
+		{"image: poring card.png", 6, "Poring_card.png"},
+		{"Category_talk:Monsters", 15, "Monsters"},
+		{":Category:Monsters", 14, "Monsters"},
+		{"Main Page", 0, "Main_Page"},
+		{"Poring: The Movie", 0, "Poring:_The_Movie"},
+		{"Module:Infobox/data", 828, "Infobox/data"},
+	}
+	for _, tt := range tests {
+		ns, title := irowiki.ParseTitle(tt.full)
+		if ns != tt.ns || title != tt.title {
+			t.Errorf("ParseTitle(%q) = %d, %q; expected %d, %q", tt.full, ns, title, tt.ns, tt.title)
+		}
+	}
+}
+
+// TestFullTitle tests prefixing titles with their namespace
+func TestFullTitle(t *testing.T) {
+	tests := []struct {
+		ns    int
+		title string
+		full  string
+	}{
+		{14, "Monsters", "Category:Monsters"},
+		{3, "some editor", "User_talk:Some_editor"},
+		{6, "File:Example.png", "File:Example.png"},
+		{0, "Main Page", "Main_Page"},
+		{3000, "Guide", "Guide"},
+	}
+	for _, tt := range tests {
+		if full := irowiki.FullTitle(tt.ns, tt.title); full != tt.full {
+			t.Errorf("FullTitle(%d, %q) = %q; expected %q", tt.ns, tt.title, full, tt.full)
+		}
+	}
+
+	table := irowiki.NewNamespaceTable(map[int]string{0: "", 4: "iRO Wiki", 3000: "Guide"}, map[string]int{"Project": 4})
+	if full := table.FullTitle(4, "About"); full != "iRO_Wiki:About" {
+		t.Errorf("expected the wiki's project namespace name, got %q", full)
+	}
+	if ns, title := table.ParseTitle("project:About"); ns != 4 || title != "About" {
+		t.Errorf("expected the Project alias to parse, got %d, %q", ns, title)
+	}
+	if ns, title := table.ParseTitle("Guide:Leveling"); ns != 3000 || title != "Leveling" {
+		t.Errorf("expected the custom namespace to parse, got %d, %q", ns, title)
+	}
+}
+
+// TestSQLiteClient_GetPage_NamespacePrefix tests looking up pages stored
+// without their namespace prefix by their full title
+func TestSQLiteClient_GetPage_NamespacePrefix(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	for _, title := range []string{"File:Example.png", "Image:Example.png", "Example.png"} {
+		page, err := client.GetPage(ctx, title)
+		if err != nil {
+			t.Fatalf("GetPage(%q) failed: %v", title, err)
+		}
+		if page.ID != 4 || page.Namespace != 6 {
+			t.Errorf("GetPage(%q): expected Example.png, got %+v", title, page)
+		}
+	}
+
+	// Poring is in the main namespace
+	if _, err := client.GetPage(ctx, "Category:Poring"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound in the wrong namespace, got %v", err)
+	}
+
+	results, err := client.GetPagesByTitle(ctx, []string{"File:Example.png", "Category:Poring", "Poring"})
+	if err != nil {
+		t.Fatalf("GetPagesByTitle failed: %v", err)
+	}
+	if !results[0].Found() || results[0].Page.ID != 4 {
+		t.Errorf("expected File:Example.png to resolve, got %+v", results[0])
+	}
+	if results[1].Found() {
+		t.Errorf("expected Category:Poring not to resolve, got %+v", results[1].Page)
+	}
+	if !results[2].Found() || results[2].Page.ID != 3 {
+		t.Errorf("expected Poring to resolve, got %+v", results[2])
+	}
+}
+
+// TestSQLiteClient_TitleLookups tests that methods taking a page title find
+// pages as GetPage does: typed with combining accents, with a namespace
+// prefix, or with spaces and a lower-case first letter
+func TestSQLiteClient_TitleLookups(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	if _, err := tdb.DB.Exec(`INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES (6, 0, 'Éclage', 0)`); err != nil {
+		t.Fatalf("failed to insert page: %v", err)
+	}
+	_, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, timestamp, user, content, size, sha1) VALUES (107, 6, '2020-01-08 00:00:00', 'Admin', 'Éclage is a [[Poring]].', 24, 'x')`)
+	if err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		title string
+		id    int64
+	}{
+		{"E\u0301clage", 6},
+		{"File:Example.png", 4},
+		{"main Page", 1},
+	}
+	for _, tt := range tests {
+		history, err := client.GetPageHistory(ctx, tt.title, irowiki.HistoryOptions{})
+		if err != nil {
+			t.Fatalf("GetPageHistory(%q) failed: %v", tt.title, err)
+		}
+		if len(history) == 0 || history[0].PageID != tt.id {
+			t.Errorf("GetPageHistory(%q): expected page %d, got %+v", tt.title, tt.id, history)
+		}
+
+		rev, err := client.GetPageAtTime(ctx, tt.title, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("GetPageAtTime(%q) failed: %v", tt.title, err)
+		}
+		if rev.PageID != tt.id {
+			t.Errorf("GetPageAtTime(%q): expected page %d, got %d", tt.title, tt.id, rev.PageID)
+		}
+
+		stats, err := client.GetPageStats(ctx, tt.title)
+		if err != nil {
+			t.Fatalf("GetPageStats(%q) failed: %v", tt.title, err)
+		}
+		if stats.PageID != tt.id {
+			t.Errorf("GetPageStats(%q): expected page %d, got %d", tt.title, tt.id, stats.PageID)
+		}
+
+		timeline, err := client.GetPageContributorsTimeline(ctx, tt.title)
+		if err != nil {
+			t.Fatalf("GetPageContributorsTimeline(%q) failed: %v", tt.title, err)
+		}
+		if timeline.PageID != tt.id {
+			t.Errorf("GetPageContributorsTimeline(%q): expected page %d, got %d", tt.title, tt.id, timeline.PageID)
+		}
+
+		if _, err := client.GetOutgoingLinks(ctx, tt.title); err != nil {
+			t.Errorf("GetOutgoingLinks(%q) failed: %v", tt.title, err)
+		}
+	}
+
+	titles := make([]string, len(tests))
+	for i, tt := range tests {
+		titles[i] = tt.title
+	}
+	results, err := client.GetPagesByTitle(ctx, titles)
+	if err != nil {
+		t.Fatalf("GetPagesByTitle failed: %v", err)
+	}
+	for i, tt := range tests {
+		if !results[i].Found() || results[i].Page.ID != tt.id {
+			t.Errorf("GetPagesByTitle(%q): expected page %d, got %+v", tt.title, tt.id, results[i])
+		}
+	}
+
+	// Titles matching no page in any form are still not found
+	if _, err := client.GetPageHistory(ctx, "Category:Poring", irowiki.HistoryOptions{}); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound in the wrong namespace, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package irowiki
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wordPieceModels are name fragments of embedding models using BERT's
+// WordPiece tokenizer, such as the sentence-transformers models the vector
+// index is built with.
+var wordPieceModels = []string{"minilm", "mpnet", "bert", "bge-", "e5-", "gte-", "multi-qa", "msmarco", "paraphrase"}
+
+// EstimateTokens estimates the number of tokens model's tokenizer splits
+// content into, for budgeting RAG context and embedding costs without a
+// tokenizer. Sentence-transformers and BERT models ("all-MiniLM-L6-v2",
+// "bge-small-en", ...) are estimated as WordPiece, including the [CLS] and
+// [SEP] tokens they add; any other model, or "", as a GPT-style byte pair
+// encoding. The estimate is rough: words are priced by length rather than
+// looked up in a vocabulary. Empty content has no tokens.
+func EstimateTokens(content, model string) int {
+	if content == "" {
+		return 0
+	}
+	wordPiece := false
+	model = strings.ToLower(model)
+	for _, name := range wordPieceModels {
+		if strings.Contains(model, name) {
+			wordPiece = true
+			break
+		}
+	}
+
+	tokens := 0
+	var letters, digits, symbols int
+	flush := func() {
+		if wordPiece {
+			// WordPiece splits off every punctuation mark
+			tokens += (letters+4)/5 + (digits+1)/2 + symbols
+		} else {
+			// Byte pair encodings merge common words and runs of markup
+			// such as "]]" or "=="; numbers split into groups of 3 digits
+			tokens += (letters+5)/6 + (digits+2)/3 + (symbols+1)/2
+		}
+		letters, digits, symbols = 0, 0, 0
+	}
+
+	newline := false
+	for _, r := range content {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			if digits > 0 || symbols > 0 {
+				flush()
+			}
+			letters++
+		case unicode.IsDigit(r):
+			if letters > 0 || symbols > 0 {
+				flush()
+			}
+			digits++
+		case unicode.IsSpace(r):
+			flush()
+			// Whitespace joins the next word, except runs of line breaks
+			if r == '\n' {
+				if !newline && !wordPiece {
+					tokens++
+				}
+				newline = true
+			}
+			continue
+		default:
+			if letters > 0 || digits > 0 {
+				flush()
+			}
+			symbols++
+		}
+		newline = false
+	}
+	flush()
+
+	if wordPiece {
+		tokens += 2
+	}
+	return tokens
+}
@@ -0,0 +1,41 @@
+package irowiki_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestEstimateTokens tests estimating token counts per tokenizer family
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		content string
+		model   string
+		want    int
+	}{
+		{"", "", 0},
+		{"", "all-MiniLM-L6-v2", 0},
+		// Hello , world !
+		{"Hello, world!", "gpt-4o", 4},
+		// [CLS] hello , world ! [SEP]
+		{"Hello, world!", "sentence-transformers/all-MiniLM-L6-v2", 6},
+		{"Hello, world!", "BAAI/BGE-small-en-v1.5", 6},
+		// Runs of line breaks are one token
+		{"Drops\n\n\nPoring", "", 3},
+		{"1234567", "", 3},
+		{"ポリン", "", 3},
+	}
+	for _, tt := range tests {
+		if got := irowiki.EstimateTokens(tt.content, tt.model); got != tt.want {
+			t.Errorf("EstimateTokens(%q, %q) = %d; expected %d", tt.content, tt.model, got, tt.want)
+		}
+	}
+
+	// Estimates grow with the text
+	page := strings.Repeat("'''Poring''' is a [[monster]] found in [[Prontera Fields]].\n", 100)
+	short, long := irowiki.EstimateTokens(page[:len(page)/2], ""), irowiki.EstimateTokens(page, "")
+	if short == 0 || long < 2*short-1 || long > 2*short+1 {
+		t.Errorf("expected the estimate to double with the text, got %d and %d", short, long)
+	}
+}
@@ -0,0 +1,143 @@
+package irowiki
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EditorScope selects the pages whose editors GetTopEditorsForScope ranks.
+// With both fields set, pages must match both; with neither, the whole wiki
+// is ranked.
+type EditorScope struct {
+	// Namespaces limits the pages to these namespaces.
+	Namespaces []int `json:"namespaces,omitempty"`
+
+	// Category limits the pages to members of a category, with or without
+	// the "Category:" prefix ("Monsters" or "Category:Monsters").
+	Category string `json:"category,omitempty"`
+
+	// ExcludeBots leaves bots out of the ranking.
+	ExcludeBots bool `json:"exclude_bots,omitempty"`
+}
+
+// getTopEditorsForScope ranks the editors of the pages in es by edit count,
+// breaking ties by name. Category membership comes from the links table. bot
+// is the backend's condition for r.user being a bot.
+func getTopEditorsForScope(ctx context.Context, db querier, scope wikiScope, es EditorScope, n int, bot string, placeholder func(n int) string) ([]EditorStat, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: negative editor count", ErrInvalidInput)
+	}
+	if n == 0 {
+		n = 10
+	}
+
+	query := `
+		SELECT r.user, COUNT(*), MIN(r.timestamp), MAX(r.timestamp),
+		       SUM(CASE WHEN r.minor THEN 1 ELSE 0 END), COUNT(DISTINCT r.page_id), ` + bot + `
+		FROM revisions r
+		JOIN pages p ON p.page_id = r.page_id
+		WHERE r.user IS NOT NULL` + scope.filter("p")
+	var args []interface{}
+
+	if len(es.Namespaces) > 0 {
+		marks := make([]string, len(es.Namespaces))
+		for i, ns := range es.Namespaces {
+			args = append(args, ns)
+			marks[i] = placeholder(len(args))
+		}
+		query += " AND p.namespace IN (" + strings.Join(marks, ", ") + ")"
+	}
+	if es.Category != "" {
+		name := categoryTitle(es.Category)
+		args = append(args, name, "Category:"+name)
+		query += `
+			AND p.page_id IN (
+				SELECT source_page_id FROM links
+				WHERE link_type = 'category'
+				  AND REPLACE(target_title, '_', ' ') IN (` + placeholder(len(args)-1) + `, ` + placeholder(len(args)) + `)
+			)`
+	}
+
+	if es.ExcludeBots {
+		query += " AND NOT " + bot
+	}
+
+	args = append(args, n)
+	query += `
+		GROUP BY r.user
+		ORDER BY COUNT(*) DESC, r.user
+		LIMIT ` + placeholder(len(args))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	editors := []EditorStat{}
+	for rows.Next() {
+		var editor EditorStat
+		var firstEdit, lastEdit interface{}
+		if err := rows.Scan(&editor.Username, &editor.EditCount, &firstEdit, &lastEdit, &editor.MinorEdits, &editor.PagesEdited, &editor.Bot); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		editor.FirstEdit = aggregateTime(firstEdit)
+		editor.LastEdit = aggregateTime(lastEdit)
+		editors = append(editors, editor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return editors, nil
+}
+
+// aggregateTime converts the MIN or MAX of a timestamp column, which SQLite
+// returns as the stored text, to a time. Unparseable values are zero.
+func aggregateTime(v interface{}) time.Time {
+	switch v := v.(type) {
+	case time.Time:
+		return v
+	case []byte:
+		return aggregateTime(string(v))
+	case string:
+		for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// GetTopEditorsForScope ranks the editors of the pages in scope.
+func (c *sqliteClient) GetTopEditorsForScope(ctx context.Context, scope EditorScope, n int) ([]EditorStat, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	placeholder := func(int) string { return "?" }
+	return getTopEditorsForScope(ctx, c.db, c.wiki, scope, n, sqliteBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
+
+// GetTopEditorsForScope ranks the editors of the pages in scope.
+func (c *postgresClient) GetTopEditorsForScope(ctx context.Context, scope EditorScope, n int) ([]EditorStat, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	// Archives loaded without links have no category members
+	if scope.Category != "" {
+		exists, err := postgresTableExists(ctx, c.db, "links")
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return []EditorStat{}, nil
+		}
+	}
+
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	return getTopEditorsForScope(ctx, c.db, c.wiki, scope, n, postgresBotCondition("r.user", c.opts.BotUsers), placeholder)
+}
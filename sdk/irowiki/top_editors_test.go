@@ -0,0 +1,84 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteClient_GetTopEditorsForScope tests ranking the editors of a
+// namespace and a category
+func TestSQLiteClient_GetTopEditorsForScope(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	// Poring and Prontera are in the Monsters category, written both ways
+	for _, stmt := range []string{
+		`CREATE TABLE links (source_page_id INTEGER NOT NULL, target_title TEXT NOT NULL, link_type TEXT NOT NULL)`,
+		`INSERT INTO links VALUES (3, 'Category:Monsters', 'category'), (2, 'Monsters', 'category'), (1, 'Monsters', 'page')`,
+	} {
+		if _, err := tdb.DB.Exec(stmt); err != nil {
+			t.Fatalf("failed to set up links: %v", err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		scope irowiki.EditorScope
+		n     int
+		want  []string
+	}{
+		{"wiki", irowiki.EditorScope{}, 0, []string{"Admin", "Editor", "Contributor"}},
+		{"namespace", irowiki.EditorScope{Namespaces: []int{6}}, 0, []string{"Admin"}},
+		{"category", irowiki.EditorScope{Category: "Monsters"}, 0, []string{"Admin", "Contributor", "Editor"}},
+		{"prefixed category", irowiki.EditorScope{Category: "Category:Monsters"}, 2, []string{"Admin", "Contributor"}},
+		{"category in namespace", irowiki.EditorScope{Namespaces: []int{6}, Category: "Monsters"}, 0, []string{}},
+		{"unknown category", irowiki.EditorScope{Category: "Cards"}, 0, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editors, err := client.GetTopEditorsForScope(ctx, tt.scope, tt.n)
+			if err != nil {
+				t.Fatalf("GetTopEditorsForScope failed: %v", err)
+			}
+			got := []string{}
+			for _, e := range editors {
+				got = append(got, e.Username)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Test: Stats cover only the scope's pages
+	editors, err := client.GetTopEditorsForScope(ctx, irowiki.EditorScope{Namespaces: []int{0}}, 1)
+	if err != nil {
+		t.Fatalf("GetTopEditorsForScope failed: %v", err)
+	}
+	admin := editors[0]
+	if admin.EditCount != 3 || admin.PagesEdited != 3 || admin.MinorEdits != 0 {
+		t.Errorf("unexpected stats: %+v", admin)
+	}
+	if !admin.FirstEdit.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) || !admin.LastEdit.Equal(time.Date(2020, 1, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected edit range: %v to %v", admin.FirstEdit, admin.LastEdit)
+	}
+
+	// Test: Negative counts are rejected
+	if _, err := client.GetTopEditorsForScope(ctx, irowiki.EditorScope{}, -1); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
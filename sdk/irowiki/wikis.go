@@ -0,0 +1,357 @@
+package irowiki
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Wiki is a source wiki of an archive. Most archives hold a single wiki;
+// sister wikis (such as iRO Wiki Classic) merged in with the scraper's
+// merge-wiki command are listed after it. The same title may exist in each.
+type Wiki struct {
+	// ID selects the wiki in ConnectionOptions.WikiID. The primary wiki,
+	// described by archive_meta, has the ID "".
+	ID string `json:"id"`
+
+	// Name is the wiki's site name (e.g., "iRO Wiki Classic").
+	Name string `json:"name"`
+
+	// BaseURL is the base URL of the wiki (e.g., "https://irowiki.org").
+	BaseURL string `json:"base_url"`
+
+	// APIURL is the wiki's MediaWiki API endpoint, if known.
+	APIURL string `json:"api_url,omitempty"`
+
+	// ArticlePath is the article URL pattern (e.g., "/classic/$1"), if known.
+	ArticlePath string `json:"article_path,omitempty"`
+
+	// IDOffset is added to the wiki's own page and revision IDs so they
+	// stay unique archive-wide. It is 0 for the primary wiki.
+	IDOffset int64 `json:"id_offset"`
+}
+
+// SourceID returns the ID a page or revision of the wiki has on the wiki
+// itself, for building API requests or oldid links.
+func (w *Wiki) SourceID(id int64) int64 {
+	return id - w.IDOffset
+}
+
+// wikiScope restricts title lookups, page listings and searches to one wiki
+// of an archive holding several, and to pages that weren't deleted from the
+// live wiki. The zero value, used for archives without pages.wiki_id or
+// deleted_pages, matches every page.
+type wikiScope struct {
+	id      string
+	enabled bool
+
+	// tombstones reports whether the archive has a deleted_pages table, and
+	// deleted which pages listed there are matched.
+	tombstones bool
+	deleted    deletedMode
+
+	// storedTargets reports whether pages has a redirect_target column.
+	storedTargets bool
+}
+
+// filter returns a condition, starting with " AND ", limiting the pages table
+// aliased as alias to the scoped wiki, or "" when there is nothing to limit.
+// The ID is checked against the wikis table when the client opens.
+func (s wikiScope) filter(alias string) string {
+	cond := s.deletedFilter(alias)
+	if !s.enabled {
+		return cond
+	}
+	return fmt.Sprintf(" AND %s.wiki_id = '%s'", alias, strings.ReplaceAll(s.id, "'", "''")) + cond
+}
+
+// newWikiScope returns the scope for ConnectionOptions.WikiID. hasColumn
+// reports whether pages has a wiki_id column and hasWikis whether the wikis
+// table exists.
+func newWikiScope(ctx context.Context, db querier, id string, hasColumn, hasWikis bool, placeholder func(n int) string) (wikiScope, error) {
+	if id == "" {
+		return wikiScope{enabled: hasColumn}, nil
+	}
+	if !hasColumn || !hasWikis {
+		return wikiScope{}, fmt.Errorf("%w: unknown wiki %q: the archive holds a single wiki", ErrInvalidInput, id)
+	}
+
+	var found int
+	err := db.QueryRowContext(ctx, "SELECT 1 FROM wikis WHERE wiki_id = "+placeholder(1), id).Scan(&found)
+	if err == sql.ErrNoRows {
+		return wikiScope{}, fmt.Errorf("%w: unknown wiki %q", ErrInvalidInput, id)
+	}
+	if err != nil {
+		return wikiScope{}, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return wikiScope{id: id, enabled: true}, nil
+}
+
+// listWikis returns the primary wiki, described by meta, followed by the
+// wikis merged into the archive when the wikis table exists.
+func listWikis(ctx context.Context, db querier, meta map[string]string, hasWikis bool) ([]Wiki, error) {
+	wikis := []Wiki{{
+		Name:        meta["wiki_name"],
+		BaseURL:     meta["base_url"],
+		APIURL:      meta["api_url"],
+		ArticlePath: meta["article_path"],
+	}}
+	if !hasWikis {
+		return wikis, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT wiki_id, name, base_url, api_url, article_path, id_offset
+		FROM wikis
+		ORDER BY id_offset
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var wiki Wiki
+		var apiURL, articlePath sql.NullString
+		if err := rows.Scan(&wiki.ID, &wiki.Name, &wiki.BaseURL, &apiURL, &articlePath, &wiki.IDOffset); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		wiki.APIURL = apiURL.String
+		wiki.ArticlePath = articlePath.String
+		wikis = append(wikis, wiki)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return wikis, nil
+}
+
+// applyWiki points info at the scoped wiki, so PageURL links to the wiki the
+// client reads from.
+func applyWiki(info *ArchiveInfo, wikis []Wiki, scope wikiScope) {
+	for _, wiki := range wikis {
+		if wiki.ID != scope.id || wiki.ID == "" {
+			continue
+		}
+		info.WikiName = wiki.Name
+		info.BaseURL = wiki.BaseURL
+		info.APIURL = wiki.APIURL
+		info.ArticlePath = wiki.ArticlePath
+	}
+}
+
+// sqliteColumnExists reports whether a table of the main database has a column.
+func sqliteColumnExists(ctx context.Context, db queryRower, table, column string) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return n > 0, nil
+}
+
+// openSQLiteWikiScope detects sister wikis in a SQLite archive.
+func openSQLiteWikiScope(ctx context.Context, db querier, id string) (wikiScope, error) {
+	hasColumn, err := sqliteColumnExists(ctx, db, "pages", "wiki_id")
+	if err != nil {
+		return wikiScope{}, err
+	}
+	hasWikis, err := sqliteTableExists(ctx, db, "main", "wikis")
+	if err != nil {
+		return wikiScope{}, err
+	}
+	hasDeleted, err := sqliteTableExists(ctx, db, "main", "deleted_pages")
+	if err != nil {
+		return wikiScope{}, err
+	}
+	hasTargets, err := sqliteColumnExists(ctx, db, "pages", "redirect_target")
+	if err != nil {
+		return wikiScope{}, err
+	}
+
+	scope, err := newWikiScope(ctx, db, id, hasColumn, hasWikis, func(int) string { return "?" })
+	scope.tombstones = hasDeleted
+	scope.storedTargets = hasTargets
+	return scope, err
+}
+
+// ListWikis returns the archive's primary wiki followed by any merged sister wikis.
+func (c *sqliteClient) ListWikis(ctx context.Context) ([]Wiki, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	meta := map[string]string{}
+	hasMeta, err := sqliteTableExists(ctx, c.db, "main", "archive_meta")
+	if err != nil {
+		return nil, err
+	}
+	if hasMeta {
+		if meta, err = readArchiveMeta(ctx, c.db); err != nil {
+			return nil, err
+		}
+	}
+	hasWikis, err := sqliteTableExists(ctx, c.db, "main", "wikis")
+	if err != nil {
+		return nil, err
+	}
+	return listWikis(ctx, c.db, meta, hasWikis)
+}
+
+// openPostgresWikiScope detects sister wikis in a PostgreSQL archive.
+func openPostgresWikiScope(ctx context.Context, db querier, id string) (wikiScope, error) {
+	var hasColumn, hasWikis, hasDeleted, hasTargets bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_attribute
+			WHERE attrelid = to_regclass('pages') AND attname = 'wiki_id' AND NOT attisdropped
+		), to_regclass('wikis') IS NOT NULL, to_regclass('deleted_pages') IS NOT NULL, EXISTS (
+			SELECT 1 FROM pg_attribute
+			WHERE attrelid = to_regclass('pages') AND attname = 'redirect_target' AND NOT attisdropped
+		)
+	`).Scan(&hasColumn, &hasWikis, &hasDeleted, &hasTargets)
+	if err != nil {
+		return wikiScope{}, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	scope, err := newWikiScope(ctx, db, id, hasColumn, hasWikis, func(n int) string { return fmt.Sprintf("$%d", n) })
+	scope.tombstones = hasDeleted
+	scope.storedTargets = hasTargets
+	return scope, err
+}
+
+// ListWikis returns the archive's primary wiki followed by any merged sister wikis.
+func (c *postgresClient) ListWikis(ctx context.Context) ([]Wiki, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+
+	var hasMeta, hasWikis bool
+	err := c.db.QueryRowContext(ctx, "SELECT to_regclass('archive_meta') IS NOT NULL, to_regclass('wikis') IS NOT NULL").Scan(&hasMeta, &hasWikis)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	meta := map[string]string{}
+	if hasMeta {
+		if meta, err = readArchiveMeta(ctx, c.db); err != nil {
+			return nil, err
+		}
+	}
+	return listWikis(ctx, c.db, meta, hasWikis)
+}
+
+// equivalentPageID returns the ID of the page on the to wiki matching title on
+// the from wiki. Interwiki links from the page are preferred, then links back
+// to it, then a page with the same title.
+func equivalentPageID(ctx context.Context, db querier, from, to wikiScope, hasLinks bool, title string, placeholder func(n int) string) (int64, error) {
+	var pageID int64
+	err := db.QueryRowContext(ctx, "SELECT p.page_id FROM pages p WHERE p.title = "+placeholder(1)+from.filter("p"), title).Scan(&pageID)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+
+	type lookup struct {
+		query string
+		args  []any
+	}
+	var lookups []lookup
+	if hasLinks {
+		lookups = append(lookups,
+			lookup{`
+				SELECT t.page_id
+				FROM interwiki_links l
+				JOIN pages t ON t.title = l.target_title AND t.wiki_id = l.target_wiki
+				WHERE l.source_page_id = ` + placeholder(1) + ` AND l.target_wiki = ` + placeholder(2),
+				[]any{pageID, to.id}},
+			lookup{`
+				SELECT l.source_page_id
+				FROM interwiki_links l
+				JOIN pages s ON s.page_id = l.source_page_id
+				WHERE l.target_wiki = ` + placeholder(1) + ` AND l.target_title = ` + placeholder(2) + to.filter("s") + `
+				ORDER BY l.source_page_id
+				LIMIT 1`,
+				[]any{from.id, title}},
+		)
+	}
+	cond, order, args := titleCondition("p", title, 1, placeholder)
+	lookups = append(lookups, lookup{
+		"SELECT p.page_id FROM pages p WHERE " + cond + to.filter("p") + " ORDER BY " + order + " LIMIT 1",
+		args,
+	})
+
+	for _, l := range lookups {
+		var id int64
+		err := db.QueryRowContext(ctx, l.query, l.args...).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+		}
+		return id, nil
+	}
+	return 0, ErrNotFound
+}
+
+// GetEquivalentPage returns the article on targetWiki matching a page on wikiID.
+func (c *sqliteClient) GetEquivalentPage(ctx context.Context, wikiID, title, targetWiki string) (*Page, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if wikiID == targetWiki {
+		return nil, fmt.Errorf("%w: source and target wiki are both %q", ErrInvalidInput, wikiID)
+	}
+
+	from, err := openSQLiteWikiScope(ctx, c.db, wikiID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := openSQLiteWikiScope(ctx, c.db, targetWiki)
+	if err != nil {
+		return nil, err
+	}
+	hasLinks, err := sqliteTableExists(ctx, c.db, "main", "interwiki_links")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := equivalentPageID(ctx, c.db, from, to, hasLinks, title, func(int) string { return "?" })
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPageByID(ctx, id)
+}
+
+// GetEquivalentPage returns the article on targetWiki matching a page on wikiID.
+func (c *postgresClient) GetEquivalentPage(ctx context.Context, wikiID, title, targetWiki string) (*Page, error) {
+	if err := c.ensureNotClosed(); err != nil {
+		return nil, err
+	}
+	if wikiID == targetWiki {
+		return nil, fmt.Errorf("%w: source and target wiki are both %q", ErrInvalidInput, wikiID)
+	}
+
+	from, err := openPostgresWikiScope(ctx, c.db, wikiID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := openPostgresWikiScope(ctx, c.db, targetWiki)
+	if err != nil {
+		return nil, err
+	}
+	hasLinks, err := postgresTableExists(ctx, c.db, "interwiki_links")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := equivalentPageID(ctx, c.db, from, to, hasLinks, title, func(n int) string { return fmt.Sprintf("$%d", n) })
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPageByID(ctx, id)
+}
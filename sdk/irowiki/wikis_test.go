@@ -0,0 +1,197 @@
+package irowiki_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// addSisterWiki merges a "classic" wiki holding its own Poring into a test archive,
+// as the scraper's merge-wiki command does.
+func addSisterWiki(t *testing.T, tdb *testutil.TestDB) {
+	t.Helper()
+
+	statements := []string{
+		`CREATE TABLE pages_migrated (
+			page_id INTEGER PRIMARY KEY,
+			namespace INTEGER NOT NULL DEFAULT 0,
+			title TEXT NOT NULL,
+			is_redirect BOOLEAN NOT NULL DEFAULT 0,
+			wiki_id TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(wiki_id, namespace, title)
+		)`,
+		`INSERT INTO pages_migrated (page_id, namespace, title, is_redirect, created_at, updated_at)
+		 SELECT page_id, namespace, title, is_redirect, created_at, updated_at FROM pages`,
+		`DROP TABLE pages`,
+		`ALTER TABLE pages_migrated RENAME TO pages`,
+		`CREATE TABLE archive_meta (key TEXT PRIMARY KEY, value TEXT NOT NULL, updated_at TIMESTAMP)`,
+		`INSERT INTO archive_meta (key, value) VALUES
+			('wiki_name', 'iRO Wiki'),
+			('base_url', 'https://irowiki.org')`,
+		`CREATE TABLE wikis (
+			wiki_id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			base_url TEXT NOT NULL,
+			api_url TEXT,
+			article_path TEXT,
+			id_offset INTEGER NOT NULL UNIQUE,
+			merged_at TIMESTAMP
+		)`,
+		`INSERT INTO wikis (wiki_id, name, base_url, article_path, id_offset)
+		 VALUES ('classic', 'iRO Wiki Classic', 'https://irowiki.org', '/classic/$1', 1000000000)`,
+		`INSERT INTO pages (page_id, namespace, title, wiki_id) VALUES (1000000003, 0, 'Poring', 'classic')`,
+		`INSERT INTO revisions (revision_id, page_id, timestamp, user, content, size, sha1)
+		 VALUES (1000000104, 1000000003, '2010-06-01 12:00:00', 'OldTimer', 'Poring on the classic server.', 29, 'classic1')`,
+	}
+	for _, stmt := range statements {
+		if _, err := tdb.DB.Exec(stmt); err != nil {
+			t.Fatalf("failed to add sister wiki: %v", err)
+		}
+	}
+}
+
+// TestSQLiteClient_WikiID tests scoping a client to one wiki of a multi-wiki archive
+func TestSQLiteClient_WikiID(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+	addSisterWiki(t, tdb)
+
+	ctx := context.Background()
+
+	// Test: The default scope is the primary wiki
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	page, err := client.GetPage(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.ID != 3 {
+		t.Errorf("expected primary page 3, got %d", page.ID)
+	}
+
+	// Test: ListWikis lists the primary wiki first
+	wikis, err := client.ListWikis(ctx)
+	if err != nil {
+		t.Fatalf("ListWikis failed: %v", err)
+	}
+	if len(wikis) != 2 {
+		t.Fatalf("expected 2 wikis, got %d", len(wikis))
+	}
+	if wikis[0].ID != "" || wikis[0].Name != "iRO Wiki" {
+		t.Errorf("unexpected primary wiki: %+v", wikis[0])
+	}
+	if wikis[1].ID != "classic" || wikis[1].IDOffset != 1000000000 {
+		t.Errorf("unexpected sister wiki: %+v", wikis[1])
+	}
+	if id := wikis[1].SourceID(1000000003); id != 3 {
+		t.Errorf("expected source ID 3, got %d", id)
+	}
+
+	// Test: A scoped client reads the sister wiki's pages and links to it
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.WikiID = "classic"
+	classic, err := irowiki.OpenSQLiteWithOptions(tdb.Path, opts)
+	if err != nil {
+		t.Fatalf("failed to open classic client: %v", err)
+	}
+	defer classic.Close()
+
+	page, err = classic.GetPage(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.ID != 1000000003 || page.Content != "Poring on the classic server." {
+		t.Errorf("unexpected classic page: %+v", page)
+	}
+	if _, err := classic.GetPage(ctx, "Prontera"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a primary-only page, got %v", err)
+	}
+
+	info, err := classic.GetArchiveInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetArchiveInfo failed: %v", err)
+	}
+	if url := info.PageURL("Poring"); url != "https://irowiki.org/classic/Poring" {
+		t.Errorf("unexpected page URL %q", url)
+	}
+
+	// Test: Unknown wikis are rejected when opening
+	opts.WikiID = "renewal"
+	if _, err := irowiki.OpenSQLiteWithOptions(tdb.Path, opts); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// TestSQLiteClient_GetEquivalentPage tests jumping between the articles of sister wikis
+func TestSQLiteClient_GetEquivalentPage(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+	addSisterWiki(t, tdb)
+
+	statements := []string{
+		`INSERT INTO pages (page_id, namespace, title, wiki_id) VALUES (1000000002, 0, 'Prontera (Old)', 'classic')`,
+		`CREATE TABLE interwiki_links (
+			source_page_id INTEGER NOT NULL,
+			target_wiki TEXT NOT NULL,
+			target_title TEXT NOT NULL,
+			PRIMARY KEY (source_page_id, target_wiki)
+		)`,
+		`INSERT INTO interwiki_links VALUES (2, 'classic', 'Prontera (Old)')`,
+	}
+	for _, stmt := range statements {
+		if _, err := tdb.DB.Exec(stmt); err != nil {
+			t.Fatalf("failed to add interwiki links: %v", err)
+		}
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Test: Interwiki links are followed in both directions, then titles are matched
+	tests := []struct {
+		from, title, to string
+		wantID          int64
+	}{
+		{"", "Prontera", "classic", 1000000002},
+		{"classic", "Prontera (Old)", "", 2},
+		{"", "Poring", "classic", 1000000003},
+		{"classic", "Poring", "", 3},
+	}
+	for _, tt := range tests {
+		page, err := client.GetEquivalentPage(ctx, tt.from, tt.title, tt.to)
+		if err != nil {
+			t.Errorf("%q on %q: GetEquivalentPage failed: %v", tt.title, tt.from, err)
+			continue
+		}
+		if page.ID != tt.wantID {
+			t.Errorf("%q on %q: expected page %d, got %d", tt.title, tt.from, tt.wantID, page.ID)
+		}
+	}
+
+	// Test: Pages without a counterpart report ErrNotFound
+	if _, err := client.GetEquivalentPage(ctx, "", "Main_Page", "classic"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Test: Unknown and identical wikis are rejected
+	if _, err := client.GetEquivalentPage(ctx, "", "Poring", "renewal"); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an unknown wiki, got %v", err)
+	}
+	if _, err := client.GetEquivalentPage(ctx, "classic", "Poring", "classic"); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for the same wiki, got %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+// Package wikitext parses MediaWiki wikitext, such as the content of an
+// archived revision, into a syntax tree of templates, headings, links,
+// tables and lists, so structured data can be read from pages without
+// matching regular expressions against the raw text.
+//
+// The parser is forgiving, as MediaWiki is: every input parses, and
+// constructs that aren't closed, like a "{{" without its "}}", are kept
+// as text. Templates aren't expanded, so content they would add isn't
+// seen.
+//
+//	doc := wikitext.Parse(page.Content)
+//	for _, t := range wikitext.Find[*wikitext.Template](doc) {
+//	    if t.Name == "Monster" {
+//	        fmt.Println(t.Value("hp"))
+//	    }
+//	}
+package wikitext
+
+import "strings"
+
+// Node is a node of the syntax tree.
+type Node interface {
+	// Pos returns the node's byte offsets in the parsed source.
+	Pos() Span
+}
+
+// Span is the byte range [Start, End) of a node in the parsed source.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Pos returns the span itself, so nodes embedding it implement Node.
+func (s Span) Pos() Span {
+	return s
+}
+
+// Document is the root of a parsed page.
+type Document struct {
+	Span
+	Nodes []Node
+
+	// Source is the parsed wikitext.
+	Source string
+}
+
+// Text is plain text, including the markup the parser doesn't model, such
+// as bold and italic quotes and HTML.
+type Text struct {
+	Span
+	Value string
+}
+
+// Comment is an HTML comment (<!-- ... -->).
+type Comment struct {
+	Span
+
+	// Value is the comment's text, without the delimiters.
+	Value string
+}
+
+// Heading is a section heading (== Drops ==).
+type Heading struct {
+	Span
+
+	// Level is the number of equals signs, from 1 to 6.
+	Level int
+
+	Nodes []Node
+}
+
+// Text returns the heading's plain text.
+func (h *Heading) Text() string {
+	return strings.TrimSpace(Plain(h.Nodes))
+}
+
+// Template is a template transclusion or parser function call ({{Name|...}}).
+type Template struct {
+	Span
+
+	// Name is the template's name as written, trimmed, such as "Monster"
+	// or "#if: {{{1|}}}".
+	Name string
+
+	Params []*Param
+}
+
+// Param returns the template's parameter with the given name, "1" for the
+// first positional one, or nil. As in MediaWiki, the last of parameters
+// sharing a name wins.
+func (t *Template) Param(name string) *Param {
+	for i := len(t.Params) - 1; i >= 0; i-- {
+		if t.Params[i].Name == name {
+			return t.Params[i]
+		}
+	}
+	return nil
+}
+
+// Value returns the wikitext of the parameter with the given name, or ""
+// if the template doesn't have it.
+func (t *Template) Value(name string) string {
+	if p := t.Param(name); p != nil {
+		return p.Value
+	}
+	return ""
+}
+
+// Param is a template parameter, named (|hp=50) or positional (|50).
+type Param struct {
+	Span
+
+	// Name is the parameter's name, or its position ("1", "2", ...) for
+	// positional parameters.
+	Name string
+
+	// Named reports whether the parameter was given by name.
+	Named bool
+
+	// Value is the parameter's wikitext, trimmed for named parameters as
+	// MediaWiki does.
+	Value string
+
+	Nodes []Node
+}
+
+// Argument is a template argument reference ({{{1|default}}}), as found in
+// the source of templates.
+type Argument struct {
+	Span
+	Name string
+
+	// Default is the value used when the argument isn't given, or nil.
+	Default []Node
+}
+
+// Link is an internal link ([[Target|text]]), including category links
+// and file embeds.
+type Link struct {
+	Span
+
+	// Target is the linked title as written, with any #section.
+	Target string
+
+	// Nodes are the link's text, or for files their options and caption.
+	Nodes []Node
+}
+
+// Page returns the title of the page the link points to, without its
+// #section. irowiki.ParseTitle splits it into namespace and title.
+func (l *Link) Page() string {
+	page, _, _ := strings.Cut(l.Target, "#")
+	return strings.TrimSpace(page)
+}
+
+// Text returns the text the link is shown with: its label, or its target.
+func (l *Link) Text() string {
+	if len(l.Nodes) > 0 {
+		return Plain(l.Nodes)
+	}
+	return strings.TrimPrefix(l.Target, ":")
+}
+
+// ExternalLink is a bracketed external link ([https://example.org text]).
+type ExternalLink struct {
+	Span
+	URL   string
+	Nodes []Node
+}
+
+// Tag is an extension or structural tag MediaWiki treats specially, such
+// as <ref>, <nowiki> or <gallery>.
+type Tag struct {
+	Span
+
+	// Name is the tag's lower-cased name.
+	Name string
+
+	// Attrs are the tag's attributes as written.
+	Attrs string
+
+	// Content is the text between the opening and closing tags.
+	Content string
+
+	// Nodes is the parsed content of tags whose content is wikitext, such
+	// as <ref>; nil for tags whose content isn't, such as <nowiki>.
+	Nodes []Node
+}
+
+// Table is a wikitext table ({| ... |}).
+type Table struct {
+	Span
+	Attrs   string
+	Caption []Node
+	Rows    []*TableRow
+}
+
+// TableRow is a table row, started by |- or implicitly by the first cell.
+type TableRow struct {
+	Span
+	Attrs string
+	Cells []*TableCell
+}
+
+// TableCell is a data (|) or header (!) cell.
+type TableCell struct {
+	Span
+	Header bool
+	Attrs  string
+	Nodes  []Node
+}
+
+// List is a run of list lines, bulleted (*), numbered (#), indented (:)
+// or defined (;).
+type List struct {
+	Span
+	Items []*ListItem
+}
+
+// ListItem is one line of a list.
+type ListItem struct {
+	Span
+
+	// Marker is the line's prefix, such as "*" or "#*" for a bullet
+	// nested in a numbered item.
+	Marker string
+
+	Nodes []Node
+}
+
+// Depth returns the item's nesting level, 1 for top-level items.
+func (i *ListItem) Depth() int {
+	return len(i.Marker)
+}
+
+// Line returns the 1-based line of the source a node starts on.
+func (d *Document) Line(n Node) int {
+	return strings.Count(d.Source[:min(n.Pos().Start, len(d.Source))], "\n") + 1
+}
+
+// Plain returns the text nodes would be read as: text, link labels,
+// headings, list items and table cells, without templates, arguments or
+// comments.
+func Plain(nodes []Node) string {
+	var b strings.Builder
+	var write func(nodes []Node)
+	write = func(nodes []Node) {
+		for _, n := range nodes {
+			switch n := n.(type) {
+			case *Text:
+				b.WriteString(n.Value)
+			case *Heading:
+				write(n.Nodes)
+			case *Link:
+				b.WriteString(n.Text())
+			case *ExternalLink:
+				if len(n.Nodes) > 0 {
+					write(n.Nodes)
+				} else {
+					b.WriteString(n.URL)
+				}
+			case *Tag:
+				switch {
+				case n.Nodes != nil:
+					write(n.Nodes)
+				case n.Name == "nowiki" || n.Name == "pre":
+					b.WriteString(n.Content)
+				}
+			case *List:
+				for i, item := range n.Items {
+					if i > 0 {
+						b.WriteString("\n")
+					}
+					write(item.Nodes)
+				}
+			case *Table:
+				for _, row := range n.Rows {
+					for i, cell := range row.Cells {
+						if i > 0 {
+							b.WriteString("\t")
+						}
+						write(cell.Nodes)
+					}
+					b.WriteString("\n")
+				}
+			}
+		}
+	}
+	write(nodes)
+	return b.String()
+}
@@ -0,0 +1,555 @@
+package wikitext
+
+import (
+	"strconv"
+	"strings"
+)
+
+// rawTags are the tags whose content is kept as written rather than parsed.
+var rawTags = map[string]bool{
+	"nowiki":          true,
+	"pre":             true,
+	"math":            true,
+	"source":          true,
+	"syntaxhighlight": true,
+	"gallery":         true,
+	"templatedata":    true,
+}
+
+// parsedTags are the tags whose content is wikitext.
+var parsedTags = map[string]bool{
+	"ref":         true,
+	"references":  true,
+	"includeonly": true,
+	"noinclude":   true,
+	"onlyinclude": true,
+}
+
+// urlSchemes are the schemes bracketed external links are recognized with.
+var urlSchemes = []string{"http://", "https://", "ftp://", "irc://", "mailto:", "//"}
+
+// Parse parses wikitext into a document. It never fails: wikitext that
+// isn't well formed parses as text.
+func Parse(src string) *Document {
+	p := newParser(src, 0, len(src))
+	return &Document{Span: Span{0, len(src)}, Nodes: p.parseNodes(nil, true), Source: src}
+}
+
+// ParseHeading parses a line of wikitext as a section heading, returning
+// nil if the line isn't one, for code that reads wikitext line by line.
+func ParseHeading(line string) *Heading {
+	if !strings.HasPrefix(line, "=") {
+		return nil
+	}
+	doc := Parse(line)
+	if len(doc.Nodes) == 0 || strings.ContainsRune(line, '\n') {
+		return nil
+	}
+	h, _ := doc.Nodes[0].(*Heading)
+	return h
+}
+
+// maxDepth is how deeply constructs nest before those inside are parsed as
+// text.
+const maxDepth = 40
+
+// construct identifies an attempt to parse a construct at an offset.
+type construct struct {
+	kind byte
+	pos  int
+}
+
+// attempted is the outcome of parsing a construct: the node and the offset
+// it ends at, or a nil node if the construct wasn't closed.
+type attempted struct {
+	node Node
+	end  int
+}
+
+type parser struct {
+	src string
+	pos int
+	end int
+
+	// depth is the number of constructs being parsed
+	depth int
+
+	// done are the outcomes of the constructs parsed, so the text of an
+	// unclosed construct, which is parsed again as the text it is, reuses
+	// the constructs found in it.
+	done map[construct]attempted
+
+	// unclosed are the closing delimiters a construct was looked for to
+	// the end of the source without finding. Constructs closed by them
+	// that aren't done yet are given up on: they would scan the same text
+	// for them, and parsing each of a run of unclosed "{{" to the end of
+	// the source would take quadratic time.
+	unclosed map[string]bool
+}
+
+func newParser(src string, pos, end int) *parser {
+	return &parser{src: src, pos: pos, end: end, done: make(map[construct]attempted), unclosed: make(map[string]bool)}
+}
+
+// sub returns a parser for the part of the source between start and end,
+// nested in the current construct.
+func (p *parser) sub(start, end int) *parser {
+	sub := newParser(p.src, start, end)
+	sub.depth = p.depth
+	return sub
+}
+
+// has reports whether the source continues with s.
+func (p *parser) has(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:p.end], s)
+}
+
+// atLineStart reports whether the parser is at the start of a line.
+func (p *parser) atLineStart() bool {
+	return p.pos == 0 || p.src[p.pos-1] == '\n'
+}
+
+// lineEnd returns the offset of the end of the current line.
+func (p *parser) lineEnd() int {
+	if i := strings.IndexByte(p.src[p.pos:p.end], '\n'); i >= 0 {
+		return p.pos + i
+	}
+	return p.end
+}
+
+// skipSpaces advances past spaces and tabs.
+func (p *parser) skipSpaces() {
+	for p.pos < p.end && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// parseNodes parses until the end of the source or until stop reports the
+// enclosing construct ends. With block set, headings, lists and tables are
+// recognized at the start of lines.
+func (p *parser) parseNodes(stop func() bool, block bool) []Node {
+	var nodes []Node
+	text := -1
+	flush := func(end int) {
+		if text >= 0 && end > text {
+			nodes = append(nodes, &Text{Span: Span{text, end}, Value: p.src[text:end]})
+		}
+		text = -1
+	}
+
+	for p.pos < p.end {
+		if stop != nil && stop() {
+			break
+		}
+		start := p.pos
+		var n Node
+		if block && p.atLineStart() {
+			n = p.parseBlock()
+		}
+		if n == nil {
+			n = p.parseInline()
+		}
+		if n != nil {
+			flush(start)
+			nodes = append(nodes, n)
+			continue
+		}
+		if text < 0 {
+			text = p.pos
+		}
+		p.pos++
+	}
+	flush(p.pos)
+	return nodes
+}
+
+// parseBlock parses a heading, list or table starting the current line.
+func (p *parser) parseBlock() Node {
+	switch c := p.src[p.pos]; {
+	case c == '=':
+		return p.parseHeading()
+	case strings.IndexByte("*#:;", c) >= 0:
+		return p.parseList()
+	}
+	start := p.pos
+	p.skipSpaces()
+	if p.has("{|") {
+		return p.parseTable(start)
+	}
+	p.pos = start
+	return nil
+}
+
+// parseInline parses a comment, template, argument, link or tag at the
+// current offset.
+func (p *parser) parseInline() Node {
+	switch {
+	case p.has("<!--"):
+		return p.parseComment()
+	case p.has("{{{"):
+		if n := p.parseArgument(); n != nil {
+			return n
+		}
+		return p.parseTemplate()
+	case p.has("{{"):
+		return p.parseTemplate()
+	case p.has("[["):
+		return p.parseLink()
+	case p.has("["):
+		return p.parseExternalLink()
+	case p.has("<"):
+		return p.parseTag()
+	}
+	return nil
+}
+
+// attempt parses a construct closed by closer at the current offset, or
+// returns the outcome of having parsed it before. If parse returns nil, the
+// offset is restored.
+func (p *parser) attempt(kind byte, closer string, parse func(start int) Node) Node {
+	c := construct{kind, p.pos}
+	if a, ok := p.done[c]; ok {
+		p.pos = a.end
+		return a.node
+	}
+	if p.unclosed[closer] || p.depth >= maxDepth {
+		return nil
+	}
+
+	p.depth++
+	n := parse(c.pos)
+	p.depth--
+	if n == nil {
+		if p.pos >= p.end {
+			p.unclosed[closer] = true
+		}
+		p.pos = c.pos
+	}
+	p.done[c] = attempted{n, p.pos}
+	return n
+}
+
+func (p *parser) parseComment() Node {
+	start := p.pos
+	end := p.end
+	value := p.src[start+4 : end]
+	if i := strings.Index(value, "-->"); i >= 0 {
+		value = value[:i]
+		end = start + 4 + i + 3
+	}
+	p.pos = end
+	return &Comment{Span: Span{start, end}, Value: value}
+}
+
+func (p *parser) parseTemplate() Node {
+	return p.attempt('t', "}}", func(start int) Node {
+		p.pos += 2
+		closed := func() bool { return p.has("|") || p.has("}}") || p.unclosed["}}"] }
+		p.parseNodes(closed, false)
+		t := &Template{Name: strings.TrimSpace(p.src[start+2 : p.pos])}
+
+		position := 0
+		for p.has("|") {
+			p.pos++
+			paramStart := p.pos
+			nodes := p.parseNodes(closed, true)
+			param := &Param{Span: Span{paramStart, p.pos}, Value: p.src[paramStart:p.pos], Nodes: nodes}
+			if name, value, ok := splitParam(param); ok {
+				param.Name, param.Named, param.Value = name, true, value
+			} else {
+				position++
+				param.Name = strconv.Itoa(position)
+			}
+			t.Params = append(t.Params, param)
+		}
+		if !p.has("}}") {
+			return nil
+		}
+		p.pos += 2
+		t.Span = Span{start, p.pos}
+		return t
+	})
+}
+
+// splitParam splits a named parameter's "name=value" at the first equals
+// sign of its leading text, replacing its leading text node with the part
+// after the sign. It reports false for positional parameters.
+func splitParam(param *Param) (name, value string, ok bool) {
+	if len(param.Nodes) == 0 {
+		return "", "", false
+	}
+	first, isText := param.Nodes[0].(*Text)
+	if !isText {
+		return "", "", false
+	}
+	i := strings.IndexByte(first.Value, '=')
+	if i < 0 {
+		return "", "", false
+	}
+	rest := &Text{Span: Span{first.Start + i + 1, first.End}, Value: first.Value[i+1:]}
+	if rest.Value == "" {
+		param.Nodes = param.Nodes[1:]
+	} else {
+		param.Nodes = append([]Node{rest}, param.Nodes[1:]...)
+	}
+	offset := first.Start - param.Start
+	return strings.TrimSpace(first.Value[:i]), strings.TrimSpace(param.Value[offset+i+1:]), true
+}
+
+func (p *parser) parseArgument() Node {
+	return p.attempt('a', "}}}", func(start int) Node {
+		p.pos += 3
+		p.parseNodes(func() bool { return p.has("|") || p.has("}}}") || p.unclosed["}}}"] }, false)
+		a := &Argument{Name: strings.TrimSpace(p.src[start+3 : p.pos])}
+		if p.has("|") {
+			p.pos++
+			a.Default = p.parseNodes(func() bool { return p.has("}}}") || p.unclosed["}}}"] }, false)
+			if a.Default == nil {
+				a.Default = []Node{}
+			}
+		}
+		if !p.has("}}}") {
+			return nil
+		}
+		p.pos += 3
+		a.Span = Span{start, p.pos}
+		return a
+	})
+}
+
+func (p *parser) parseLink() Node {
+	return p.attempt('l', "]]", func(start int) Node {
+		p.pos += 2
+		p.parseNodes(func() bool { return p.has("|") || p.has("]]") || p.has("\n") || p.unclosed["]]"] }, false)
+		l := &Link{Target: strings.TrimSpace(p.src[start+2 : p.pos])}
+		if p.has("|") {
+			p.pos++
+			l.Nodes = p.parseNodes(func() bool { return p.has("]]") || p.unclosed["]]"] }, false)
+		}
+		if !p.has("]]") || l.Target == "" {
+			return nil
+		}
+		p.pos += 2
+		l.Span = Span{start, p.pos}
+		return l
+	})
+}
+
+func (p *parser) parseExternalLink() Node {
+	rest := p.src[p.pos+1 : p.end]
+	scheme := false
+	for _, s := range urlSchemes {
+		if len(rest) >= len(s) && strings.EqualFold(rest[:len(s)], s) {
+			scheme = true
+			break
+		}
+	}
+	if !scheme {
+		return nil
+	}
+	return p.attempt('e', "]", func(start int) Node {
+		p.pos++
+		for p.pos < p.end && strings.IndexByte(" \t\n]", p.src[p.pos]) < 0 {
+			p.pos++
+		}
+		l := &ExternalLink{URL: p.src[start+1 : p.pos]}
+		if p.has(" ") || p.has("\t") {
+			p.skipSpaces()
+			l.Nodes = p.parseNodes(func() bool { return p.has("]") || p.has("\n") || p.unclosed["]"] }, false)
+		}
+		if !p.has("]") {
+			return nil
+		}
+		p.pos++
+		l.Span = Span{start, p.pos}
+		return l
+	})
+}
+
+func (p *parser) parseTag() Node {
+	rest := p.src[p.pos+1 : p.end]
+	n := 0
+	for n < len(rest) && (rest[n] >= 'a' && rest[n] <= 'z' || rest[n] >= 'A' && rest[n] <= 'Z') {
+		n++
+	}
+	name := strings.ToLower(rest[:n])
+	if !rawTags[name] && !parsedTags[name] {
+		return nil
+	}
+	return p.attempt('<', "</"+name, func(start int) Node {
+		p.pos += 1 + n
+		gt := strings.IndexByte(p.src[p.pos:p.end], '>')
+		if gt < 0 {
+			return nil
+		}
+		attrs := p.src[p.pos : p.pos+gt]
+		p.pos += gt + 1
+		t := &Tag{Name: name}
+		if strings.HasSuffix(attrs, "/") {
+			t.Attrs = strings.TrimSpace(strings.TrimSuffix(attrs, "/"))
+			t.Span = Span{start, p.pos}
+			return t
+		}
+		t.Attrs = strings.TrimSpace(attrs)
+
+		contentStart := p.pos
+		contentEnd := p.closingTag(name)
+		if contentEnd < 0 {
+			p.unclosed["</"+name] = true
+			return nil
+		}
+		if parsedTags[name] {
+			sub := p.sub(contentStart, contentEnd)
+			t.Nodes = sub.parseNodes(nil, true)
+			if t.Nodes == nil {
+				t.Nodes = []Node{}
+			}
+		}
+		t.Content = p.src[contentStart:contentEnd]
+		p.pos = contentEnd
+		if gt := strings.IndexByte(p.src[p.pos:p.end], '>'); gt >= 0 {
+			p.pos += gt + 1
+		} else {
+			p.pos = p.end
+		}
+		t.Span = Span{start, p.pos}
+		return t
+	})
+}
+
+// closingTag returns the offset of the first closing tag of the given name
+// after the current offset, or -1.
+func (p *parser) closingTag(name string) int {
+	lower := strings.ToLower(p.src[p.pos:p.end])
+	closing := "</" + name
+	for offset := 0; ; {
+		i := strings.Index(lower[offset:], closing)
+		if i < 0 {
+			return -1
+		}
+		after := offset + i + len(closing)
+		if after == len(lower) || strings.IndexByte("> \t\n", lower[after]) >= 0 {
+			return p.pos + offset + i
+		}
+		offset = after
+	}
+}
+
+func (p *parser) parseHeading() Node {
+	start := p.pos
+	end := p.lineEnd()
+	line := strings.TrimRight(p.src[start:end], " \t\r")
+	leading := len(line) - len(strings.TrimLeft(line, "="))
+	trailing := len(line) - len(strings.TrimRight(line, "="))
+	level := min(leading, trailing, 6)
+	if level == 0 || len(line) <= 2*level {
+		return nil
+	}
+	sub := p.sub(start+level, start+len(line)-level)
+	h := &Heading{Span: Span{start, start + len(line)}, Level: level, Nodes: sub.parseNodes(nil, false)}
+	p.pos = start + len(line)
+	return h
+}
+
+func (p *parser) parseList() Node {
+	l := &List{Span: Span{Start: p.pos}}
+	for {
+		itemStart := p.pos
+		for p.pos < p.end && strings.IndexByte("*#:;", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		item := &ListItem{Marker: p.src[itemStart:p.pos]}
+		p.skipSpaces()
+		item.Nodes = p.parseNodes(func() bool { return p.has("\n") }, false)
+		item.Span = Span{itemStart, p.pos}
+		l.Items = append(l.Items, item)
+		l.End = p.pos
+
+		if !p.has("\n") || p.pos+1 >= p.end || strings.IndexByte("*#:;", p.src[p.pos+1]) < 0 {
+			return l
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseTable(start int) Node {
+	p.pos += 2
+	t := &Table{Attrs: strings.TrimSpace(p.src[p.pos:p.lineEnd()])}
+	p.pos = p.lineEnd()
+	var row *TableRow
+	for p.pos < p.end {
+		if p.src[p.pos] == '\n' {
+			p.pos++
+		}
+		lineStart := p.pos
+		p.skipSpaces()
+		switch {
+		case p.has("|}"):
+			p.pos += 2
+			t.Span = Span{start, p.pos}
+			return t
+		case p.has("|+"):
+			p.pos += 2
+			t.Caption = p.parseNodes(func() bool { return p.has("\n") }, false)
+		case p.has("|-"):
+			for p.has("-") || p.has("|-") {
+				p.pos++
+			}
+			row = &TableRow{Span: Span{lineStart, p.lineEnd()}, Attrs: strings.TrimSpace(p.src[p.pos:p.lineEnd()])}
+			t.Rows = append(t.Rows, row)
+			p.pos = p.lineEnd()
+		case p.has("|") || p.has("!"):
+			header := p.has("!")
+			if row == nil {
+				row = &TableRow{Span: Span{lineStart, lineStart}}
+				t.Rows = append(t.Rows, row)
+			}
+			for {
+				cell := p.parseCell(header)
+				row.Cells = append(row.Cells, cell)
+				row.End = cell.End
+				if !p.has("||") && !(header && p.has("!!")) {
+					break
+				}
+			}
+		default:
+			p.pos = p.lineEnd()
+		}
+	}
+	t.Span = Span{start, p.pos}
+	return t
+}
+
+// parseCell parses a table cell, the offset at its leading marker: |, !,
+// || or !!. The cell runs to the next marker, which may be on a later line.
+func (p *parser) parseCell(header bool) *TableCell {
+	start := p.pos
+	if p.has("||") || p.has("!!") {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	cell := &TableCell{Header: header}
+	ends := func() bool {
+		if p.has("||") || header && p.has("!!") {
+			return true
+		}
+		if !p.has("\n") {
+			return false
+		}
+		rest := strings.TrimLeft(p.src[p.pos+1:p.end], " \t")
+		return strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, "!")
+	}
+
+	// A single bar on the marker's line ends the cell's attributes
+	contentStart, lineEnd := p.pos, p.lineEnd()
+	nodes := p.parseNodes(func() bool { return ends() || p.pos < lineEnd && p.has("|") }, true)
+	if p.pos < lineEnd && p.has("|") && !p.has("||") {
+		cell.Attrs = strings.TrimSpace(p.src[contentStart:p.pos])
+		p.pos++
+		nodes = p.parseNodes(ends, true)
+	}
+	cell.Nodes = nodes
+	cell.Span = Span{start, p.pos}
+	return cell
+}
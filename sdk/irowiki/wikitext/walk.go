@@ -0,0 +1,104 @@
+package wikitext
+
+// Visitor is called for each node Walk reaches. If Visit returns a non-nil
+// visitor w, Walk visits the node's children with w and then calls
+// w.Visit(nil).
+type Visitor interface {
+	Visit(n Node) (w Visitor)
+}
+
+// Walk traverses a tree depth-first, in source order, starting with v.Visit(n).
+func Walk(v Visitor, n Node) {
+	if v = v.Visit(n); v == nil {
+		return
+	}
+
+	switch n := n.(type) {
+	case *Document:
+		walkNodes(v, n.Nodes)
+	case *Heading:
+		walkNodes(v, n.Nodes)
+	case *Template:
+		for _, param := range n.Params {
+			Walk(v, param)
+		}
+	case *Param:
+		walkNodes(v, n.Nodes)
+	case *Argument:
+		walkNodes(v, n.Default)
+	case *Link:
+		walkNodes(v, n.Nodes)
+	case *ExternalLink:
+		walkNodes(v, n.Nodes)
+	case *Tag:
+		walkNodes(v, n.Nodes)
+	case *Table:
+		walkNodes(v, n.Caption)
+		for _, row := range n.Rows {
+			Walk(v, row)
+		}
+	case *TableRow:
+		for _, cell := range n.Cells {
+			Walk(v, cell)
+		}
+	case *TableCell:
+		walkNodes(v, n.Nodes)
+	case *List:
+		for _, item := range n.Items {
+			Walk(v, item)
+		}
+	case *ListItem:
+		walkNodes(v, n.Nodes)
+	}
+
+	v.Visit(nil)
+}
+
+func walkNodes(v Visitor, nodes []Node) {
+	for _, n := range nodes {
+		Walk(v, n)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(n Node) Visitor {
+	if f(n) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a tree depth-first, in source order, calling f for each
+// node and, after a node's children, f(nil). The children of nodes for
+// which f returns false are skipped.
+//
+// Example:
+//
+//	wikitext.Inspect(doc, func(n wikitext.Node) bool {
+//	    if h, ok := n.(*wikitext.Heading); ok {
+//	        fmt.Println(h.Level, h.Text())
+//	    }
+//	    return true
+//	})
+func Inspect(n Node, f func(Node) bool) {
+	Walk(inspector(f), n)
+}
+
+// Find returns the nodes of type T in a tree, in source order, including
+// those nested in other nodes of type T, such as templates passed as
+// parameters of templates.
+//
+// Example:
+//
+//	links := wikitext.Find[*wikitext.Link](doc)
+func Find[T Node](n Node) []T {
+	var found []T
+	Inspect(n, func(n Node) bool {
+		if t, ok := n.(T); ok {
+			found = append(found, t)
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,248 @@
+package wikitext_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+const monsterPage = `{{Monster
+| name = Poring
+| hp = 50
+| drops = {{Item|Jellopy}}, [[Apple]]
+}}
+'''Poring''' is a [[Monsters|monster]] found in [[Prontera Fields]].<!-- stats from kRO -->
+
+== Drops ==
+* [[Jellopy]]
+** 70%
+# [https://example.org Example]
+
+{| class="wikitable"
+|+ Stats
+! Level !! HP
+|-
+| 1 || 50
+|-
+| style="color:red" | 2
+| multi
+line
+|}
+[[Category:Monsters]]`
+
+// TestParse tests parsing the constructs of a page
+func TestParse(t *testing.T) {
+	doc := wikitext.Parse(monsterPage)
+
+	templates := wikitext.Find[*wikitext.Template](doc)
+	if len(templates) != 2 || templates[0].Name != "Monster" || templates[1].Name != "Item" {
+		t.Fatalf("expected the Monster and Item templates, got %+v", templates)
+	}
+	monster := templates[0]
+	if monster.Value("name") != "Poring" || monster.Value("hp") != "50" {
+		t.Errorf("expected the named parameters trimmed, got %q and %q", monster.Value("name"), monster.Value("hp"))
+	}
+	if drops := monster.Value("drops"); drops != "{{Item|Jellopy}}, [[Apple]]" {
+		t.Errorf("unexpected drops value %q", drops)
+	}
+	if p := templates[1].Param("1"); p == nil || p.Named || p.Value != "Jellopy" {
+		t.Errorf("expected a positional parameter, got %+v", p)
+	}
+
+	var targets []string
+	for _, l := range wikitext.Find[*wikitext.Link](doc) {
+		targets = append(targets, l.Target)
+	}
+	expected := "Apple,Monsters,Prontera Fields,Jellopy,Category:Monsters"
+	if got := strings.Join(targets, ","); got != expected {
+		t.Errorf("expected links %s, got %s", expected, got)
+	}
+
+	headings := wikitext.Find[*wikitext.Heading](doc)
+	if len(headings) != 1 || headings[0].Level != 2 || headings[0].Text() != "Drops" {
+		t.Errorf("expected the Drops heading, got %+v", headings)
+	}
+	if line := doc.Line(headings[0]); line != 8 {
+		t.Errorf("expected the heading on line 8, got %d", line)
+	}
+
+	lists := wikitext.Find[*wikitext.List](doc)
+	if len(lists) != 1 || len(lists[0].Items) != 3 {
+		t.Fatalf("expected one list of 3 items, got %+v", lists)
+	}
+	if item := lists[0].Items[1]; item.Depth() != 2 || wikitext.Plain(item.Nodes) != "70%" {
+		t.Errorf("expected a nested item, got %+v", item)
+	}
+	external := wikitext.Find[*wikitext.ExternalLink](doc)
+	if len(external) != 1 || external[0].URL != "https://example.org" || wikitext.Plain(external[0].Nodes) != "Example" {
+		t.Errorf("expected the external link, got %+v", external)
+	}
+
+	comments := wikitext.Find[*wikitext.Comment](doc)
+	if len(comments) != 1 || comments[0].Value != " stats from kRO " {
+		t.Errorf("expected the comment, got %+v", comments)
+	}
+}
+
+// TestParse_Table tests parsing table rows and cells
+func TestParse_Table(t *testing.T) {
+	tables := wikitext.Find[*wikitext.Table](wikitext.Parse(monsterPage))
+	if len(tables) != 1 {
+		t.Fatalf("expected one table, got %d", len(tables))
+	}
+	table := tables[0]
+	if table.Attrs != `class="wikitable"` || strings.TrimSpace(wikitext.Plain(table.Caption)) != "Stats" {
+		t.Errorf("unexpected attributes %q or caption %+v", table.Attrs, table.Caption)
+	}
+	if len(table.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(table.Rows))
+	}
+
+	var cells [][]string
+	for _, row := range table.Rows {
+		var texts []string
+		for _, cell := range row.Cells {
+			texts = append(texts, strings.TrimSpace(wikitext.Plain(cell.Nodes)))
+		}
+		cells = append(cells, texts)
+	}
+	expected := [][]string{{"Level", "HP"}, {"1", "50"}, {"2", "multi\nline"}}
+	for i := range expected {
+		if strings.Join(cells[i], "|") != strings.Join(expected[i], "|") {
+			t.Errorf("row %d: expected %q, got %q", i, expected[i], cells[i])
+		}
+	}
+	if !table.Rows[0].Cells[0].Header || table.Rows[1].Cells[0].Header {
+		t.Errorf("expected only the first row to be headers")
+	}
+	if attrs := table.Rows[2].Cells[0].Attrs; attrs != `style="color:red"` {
+		t.Errorf("expected the cell's attributes, got %q", attrs)
+	}
+}
+
+// TestParse_Malformed tests that unclosed constructs parse as text
+func TestParse_Malformed(t *testing.T) {
+	src := "{{Unclosed|a=[[b]] {{{{ [[no end"
+	doc := wikitext.Parse(src)
+	if templates := wikitext.Find[*wikitext.Template](doc); len(templates) != 0 {
+		t.Errorf("expected no templates, got %+v", templates)
+	}
+	links := wikitext.Find[*wikitext.Link](doc)
+	if len(links) != 1 || links[0].Target != "b" {
+		t.Errorf("expected the closed link, got %+v", links)
+	}
+
+	var b strings.Builder
+	for _, n := range doc.Nodes {
+		span := n.Pos()
+		b.WriteString(src[span.Start:span.End])
+	}
+	if b.String() != src {
+		t.Errorf("expected the nodes to cover the source, got %q", b.String())
+	}
+
+	// Constructs inside an unclosed one are still found
+	doc = wikitext.Parse("{{Unclosed|<ref>a}}</ref> {{Closed}}")
+	templates := wikitext.Find[*wikitext.Template](doc)
+	if len(templates) != 1 || templates[0].Name != "Closed" {
+		t.Errorf("expected only the closed template, got %+v", templates)
+	}
+	if tags := wikitext.Find[*wikitext.Tag](doc); len(tags) != 1 || tags[0].Content != "a}}" {
+		t.Errorf("expected the ref, got %+v", tags)
+	}
+}
+
+// TestParse_Tags tests parsing extension tags and template arguments
+func TestParse_Tags(t *testing.T) {
+	doc := wikitext.Parse("<nowiki>{{not a template}}</nowiki> {{{1|[[Default]]}}}<ref name=\"a\">See [[Poring]].</ref><references/>")
+
+	tags := wikitext.Find[*wikitext.Tag](doc)
+	if len(tags) != 3 {
+		t.Fatalf("expected 3 tags, got %+v", tags)
+	}
+	if tags[0].Name != "nowiki" || tags[0].Content != "{{not a template}}" || tags[0].Nodes != nil {
+		t.Errorf("expected the nowiki content kept as written, got %+v", tags[0])
+	}
+	if tags[1].Name != "ref" || tags[1].Attrs != `name="a"` {
+		t.Errorf("expected the ref and its attributes, got %+v", tags[1])
+	}
+	if tags[2].Name != "references" || tags[2].Content != "" {
+		t.Errorf("expected the self-closing references tag, got %+v", tags[2])
+	}
+	if len(wikitext.Find[*wikitext.Template](doc)) != 0 {
+		t.Errorf("expected no templates inside nowiki")
+	}
+
+	args := wikitext.Find[*wikitext.Argument](doc)
+	if len(args) != 1 || args[0].Name != "1" {
+		t.Fatalf("expected the argument, got %+v", args)
+	}
+	var targets []string
+	for _, l := range wikitext.Find[*wikitext.Link](doc) {
+		targets = append(targets, l.Target)
+	}
+	if strings.Join(targets, ",") != "Default,Poring" {
+		t.Errorf("expected the links in the default and the ref, got %v", targets)
+	}
+}
+
+// TestInspect tests skipping children and the closing nil call
+func TestInspect(t *testing.T) {
+	doc := wikitext.Parse("{{Outer|{{Inner}}}} [[Link#Section]]")
+
+	var names []string
+	nils := 0
+	wikitext.Inspect(doc, func(n wikitext.Node) bool {
+		switch n := n.(type) {
+		case nil:
+			nils++
+		case *wikitext.Template:
+			names = append(names, n.Name)
+			return false
+		}
+		return true
+	})
+	if strings.Join(names, ",") != "Outer" {
+		t.Errorf("expected the inner template skipped, got %v", names)
+	}
+	// Document, Link and the text between them; the template's children
+	// are skipped, so it gets no closing call
+	if nils != 3 {
+		t.Errorf("expected 3 closing calls, got %d", nils)
+	}
+
+	if page := wikitext.Find[*wikitext.Link](doc)[0].Page(); page != "Link" {
+		t.Errorf("expected the page without its section, got %q", page)
+	}
+}
+
+// TestParseHeading tests parsing single lines as headings
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		text  string
+	}{
+		{"== Drops ==", 2, "Drops"},
+		{"==Drops==  ", 2, "Drops"},
+		{"=== Unbalanced ==", 2, "= Unbalanced"},
+		{"== [[Poring]] card ==", 2, "Poring card"},
+		{"==Drops== trailing", 0, ""},
+		{"==", 0, ""},
+		{"Text", 0, ""},
+		{"== Two ==\n== Lines ==", 0, ""},
+	}
+	for _, tt := range tests {
+		h := wikitext.ParseHeading(tt.line)
+		if tt.level == 0 {
+			if h != nil {
+				t.Errorf("ParseHeading(%q) = %+v; expected nil", tt.line, h)
+			}
+			continue
+		}
+		if h == nil || h.Level != tt.level || h.Text() != tt.text {
+			t.Errorf("ParseHeading(%q) = %+v; expected level %d %q", tt.line, h, tt.level, tt.text)
+		}
+	}
+}
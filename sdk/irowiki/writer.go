@@ -0,0 +1,408 @@
+package irowiki
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ArchiveWriter populates an archive from the SDK's models, so the scraper
+// and other tools can build SQLite and PostgreSQL archives without writing
+// SQL against the schema.
+//
+// A writer holds the archive's lock until it is closed, like a scrape.
+// Insert a page's revisions oldest first: the full-text index and the
+// page's links follow the last revision inserted.
+type ArchiveWriter interface {
+	// UpsertPage inserts a page, or updates the namespace, title and
+	// redirect of the page with the same ID. Only the page's identity is
+	// written; its content comes from InsertRevision. Titles are stored in
+	// Unicode Normalization Form C.
+	UpsertPage(ctx context.Context, page *Page) error
+
+	// InsertRevision inserts a revision of an existing page. Revisions are
+	// immutable, so inserting one already in the archive does nothing. An
+	// empty Size and SHA1 are computed from Content.
+	InsertRevision(ctx context.Context, rev *Revision) error
+
+	// InsertFile inserts file metadata, replacing the record of a file
+	// with the same name.
+	InsertFile(ctx context.Context, file *File) error
+
+	// Batch calls fn with a writer whose writes are made in a single
+	// transaction, committed if fn returns nil and rolled back otherwise.
+	// Batches inside fn join its transaction.
+	Batch(ctx context.Context, fn func(w ArchiveWriter) error) error
+
+	// Close releases the archive's lock and closes the database.
+	Close() error
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// archiveWriter implements ArchiveWriter for both backends. Queries are
+// written with ? placeholders and numbered for PostgreSQL.
+type archiveWriter struct {
+	db       *sql.DB
+	postgres bool
+	links    bool
+	release  func()
+	closed   bool
+	mu       sync.RWMutex
+}
+
+// OpenSQLiteWriter opens a SQLite archive for writing with default options,
+// creating it if it doesn't exist.
+//
+// Example:
+//
+//	w, err := irowiki.OpenSQLiteWriter("irowiki.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer w.Close()
+func OpenSQLiteWriter(path string) (ArchiveWriter, error) {
+	return OpenSQLiteWriterWithOptions(path, DefaultSQLiteOptions())
+}
+
+// OpenSQLiteWriterWithOptions opens a SQLite archive for writing with custom
+// connection options, creating it if it doesn't exist.
+func OpenSQLiteWriterWithOptions(path string, opts ConnectionOptions) (ArchiveWriter, error) {
+	store, err := OpenSQLiteStoreWithOptions(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	s := store.(*sqliteStore)
+
+	release, err := s.lock()
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	// Statements are IF NOT EXISTS, so an existing archive keeps its tables
+	// and its pages_fts tokenizer
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.ConnectTimeout)
+	defer cancel()
+	schemas := [][]string{sqliteSchema, sqliteArchiveMetaSchema, sqliteExternalLinksSchema, sqlitePageHTMLSchema, sqliteWikisSchema, sqliteDeletedPagesSchema, sqlitePageProtectionSchema, sqliteCommentFTSSchema, {sqliteFTSTable("main", defaultFTSTokenize)}, sqliteFTSTriggers, sqliteCommentFTSTriggers}
+	for _, statements := range schemas {
+		if err := execStatements(ctx, s.db, statements); err != nil {
+			release()
+			s.Close()
+			return nil, err
+		}
+	}
+
+	return &archiveWriter{db: s.db, links: true, release: release}, nil
+}
+
+// OpenPostgresWriter opens a PostgreSQL archive for writing with default
+// options. The archive's tables must already exist.
+func OpenPostgresWriter(dsn string) (ArchiveWriter, error) {
+	return OpenPostgresWriterWithOptions(dsn, DefaultPostgresOptions())
+}
+
+// OpenPostgresWriterWithOptions opens a PostgreSQL archive for writing with
+// custom connection options. The archive's tables must already exist.
+func OpenPostgresWriterWithOptions(dsn string, opts ConnectionOptions) (ArchiveWriter, error) {
+	store, err := OpenPostgresStoreWithOptions(dsn, opts)
+	if err != nil {
+		return nil, err
+	}
+	s := store.(*postgresStore)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.ConnectTimeout)
+	defer cancel()
+	release, err := s.lock(ctx)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	// Archives without a links table are written without links
+	links, err := postgresTableExists(ctx, s.db, "links")
+	if err != nil {
+		release()
+		s.Close()
+		return nil, err
+	}
+
+	return &archiveWriter{db: s.db, postgres: true, links: links, release: release}, nil
+}
+
+// ensureNotClosed checks if the writer is closed and returns an error if it is.
+func (w *archiveWriter) ensureNotClosed() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// UpsertPage inserts or updates a page.
+func (w *archiveWriter) UpsertPage(ctx context.Context, page *Page) error {
+	if err := w.ensureNotClosed(); err != nil {
+		return err
+	}
+	return upsertPage(ctx, w.db, w.postgres, page)
+}
+
+// InsertRevision inserts a revision.
+func (w *archiveWriter) InsertRevision(ctx context.Context, rev *Revision) error {
+	if err := w.ensureNotClosed(); err != nil {
+		return err
+	}
+	return insertRevision(ctx, w.db, w.postgres, w.links, rev)
+}
+
+// InsertFile inserts or replaces file metadata.
+func (w *archiveWriter) InsertFile(ctx context.Context, file *File) error {
+	if err := w.ensureNotClosed(); err != nil {
+		return err
+	}
+	return insertFile(ctx, w.db, w.postgres, file)
+}
+
+// Batch runs fn in a transaction.
+func (w *archiveWriter) Batch(ctx context.Context, fn func(w ArchiveWriter) error) error {
+	if err := w.ensureNotClosed(); err != nil {
+		return err
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: failed to begin transaction: %v", ErrDatabaseError, err)
+	}
+	if err := fn(&txWriter{tx: tx, postgres: w.postgres, links: w.links}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: failed to commit transaction: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// Close releases the lock and closes the database.
+func (w *archiveWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	w.closed = true
+	w.release()
+
+	if err := w.db.Close(); err != nil {
+		return fmt.Errorf("%w: failed to close database: %v", ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+// txWriter is the writer passed to a Batch, writing in its transaction.
+type txWriter struct {
+	tx       *sql.Tx
+	postgres bool
+	links    bool
+}
+
+// UpsertPage inserts or updates a page in the transaction.
+func (w *txWriter) UpsertPage(ctx context.Context, page *Page) error {
+	return upsertPage(ctx, w.tx, w.postgres, page)
+}
+
+// InsertRevision inserts a revision in the transaction.
+func (w *txWriter) InsertRevision(ctx context.Context, rev *Revision) error {
+	return insertRevision(ctx, w.tx, w.postgres, w.links, rev)
+}
+
+// InsertFile inserts or replaces file metadata in the transaction.
+func (w *txWriter) InsertFile(ctx context.Context, file *File) error {
+	return insertFile(ctx, w.tx, w.postgres, file)
+}
+
+// Batch calls fn in the enclosing transaction.
+func (w *txWriter) Batch(ctx context.Context, fn func(w ArchiveWriter) error) error {
+	return fn(w)
+}
+
+// Close fails: the writer belongs to the Batch that created it.
+func (w *txWriter) Close() error {
+	return fmt.Errorf("%w: cannot close a writer inside Batch", ErrInvalidInput)
+}
+
+// upsertPage writes a page's identity.
+func upsertPage(ctx context.Context, db execer, postgres bool, page *Page) error {
+	if page == nil {
+		return fmt.Errorf("%w: page is nil", ErrInvalidInput)
+	}
+	if page.ID <= 0 {
+		return fmt.Errorf("%w: page ID must be positive, got %d", ErrInvalidInput, page.ID)
+	}
+	if page.Namespace < 0 {
+		return fmt.Errorf("%w: namespace must be non-negative, got %d", ErrInvalidInput, page.Namespace)
+	}
+	if strings.TrimSpace(page.Title) == "" {
+		return fmt.Errorf("%w: page %d has no title", ErrInvalidInput, page.ID)
+	}
+
+	var target sql.NullString
+	if page.IsRedirect && page.RedirectTarget != "" {
+		target = sql.NullString{String: nfc(page.RedirectTarget), Valid: true}
+	}
+
+	query := `
+		INSERT INTO pages (page_id, namespace, title, is_redirect, redirect_target)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (page_id) DO UPDATE SET
+			namespace = excluded.namespace,
+			title = excluded.title,
+			is_redirect = excluded.is_redirect,
+			redirect_target = excluded.redirect_target,
+			updated_at = CURRENT_TIMESTAMP`
+	if _, err := db.ExecContext(ctx, writerQuery(query, postgres), page.ID, page.Namespace, nfc(page.Title), page.IsRedirect, target); err != nil {
+		return fmt.Errorf("%w: failed to write page %d: %v", ErrDatabaseError, page.ID, err)
+	}
+	return nil
+}
+
+// insertRevision writes a revision, filling in its size and hash. With links
+// set, the page's links are replaced with those of a newly inserted revision.
+func insertRevision(ctx context.Context, db execer, postgres, links bool, rev *Revision) error {
+	if rev == nil {
+		return fmt.Errorf("%w: revision is nil", ErrInvalidInput)
+	}
+	if rev.ID <= 0 {
+		return fmt.Errorf("%w: revision ID must be positive, got %d", ErrInvalidInput, rev.ID)
+	}
+	if rev.PageID <= 0 {
+		return fmt.Errorf("%w: revision %d has no page ID", ErrInvalidInput, rev.ID)
+	}
+	if rev.Timestamp.IsZero() {
+		return fmt.Errorf("%w: revision %d has no timestamp", ErrInvalidInput, rev.ID)
+	}
+	if rev.Size < 0 {
+		return fmt.Errorf("%w: revision %d has negative size", ErrInvalidInput, rev.ID)
+	}
+
+	size := rev.Size
+	if size == 0 {
+		size = len(rev.Content)
+	}
+	hash := rev.SHA1
+	if hash == "" {
+		sum := sha1.Sum([]byte(rev.Content))
+		hash = hex.EncodeToString(sum[:])
+	}
+	var tags sql.NullString
+	if len(rev.Tags) > 0 {
+		data, err := json.Marshal(rev.Tags)
+		if err != nil {
+			return fmt.Errorf("%w: failed to encode tags: %v", ErrInvalidInput, err)
+		}
+		tags = sql.NullString{String: string(data), Valid: true}
+	}
+	var user, comment sql.NullString
+	if rev.User != "" {
+		user = sql.NullString{String: rev.User, Valid: true}
+	}
+	if rev.Comment != "" {
+		comment = sql.NullString{String: rev.Comment, Valid: true}
+	}
+
+	query := `
+		INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (revision_id) DO NOTHING`
+	if postgres {
+		query = strings.Replace(query, " user,", ` "user",`, 1)
+	}
+	result, err := db.ExecContext(ctx, writerQuery(query, postgres),
+		rev.ID, rev.PageID, rev.ParentID, rev.Timestamp.UTC(), user, rev.UserID, comment,
+		rev.Content, size, hash, rev.Minor, tags)
+	if err != nil {
+		return fmt.Errorf("%w: failed to write revision %d: %v", ErrDatabaseError, rev.ID, err)
+	}
+	if n, err := result.RowsAffected(); !links || err != nil || n == 0 {
+		return nil
+	}
+	_, err = replaceLinks(ctx, db, postgres, rev.PageID, rev.Content)
+	return err
+}
+
+// insertFile writes file metadata.
+func insertFile(ctx context.Context, db execer, postgres bool, file *File) error {
+	if file == nil {
+		return fmt.Errorf("%w: file is nil", ErrInvalidInput)
+	}
+	if strings.TrimSpace(file.Filename) == "" {
+		return fmt.Errorf("%w: file has no name", ErrInvalidInput)
+	}
+	if file.URL == "" {
+		return fmt.Errorf("%w: file %s has no URL", ErrInvalidInput, file.Filename)
+	}
+	if file.Timestamp.IsZero() {
+		return fmt.Errorf("%w: file %s has no timestamp", ErrInvalidInput, file.Filename)
+	}
+	if file.Size < 0 {
+		return fmt.Errorf("%w: file %s has negative size", ErrInvalidInput, file.Filename)
+	}
+
+	var uploader sql.NullString
+	if file.Uploader != "" {
+		uploader = sql.NullString{String: file.Uploader, Valid: true}
+	}
+
+	query := `
+		INSERT INTO files (filename, url, descriptionurl, sha1, size, width, height, mime_type, timestamp, uploader)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (filename) DO UPDATE SET
+			url = excluded.url,
+			descriptionurl = excluded.descriptionurl,
+			sha1 = excluded.sha1,
+			size = excluded.size,
+			width = excluded.width,
+			height = excluded.height,
+			mime_type = excluded.mime_type,
+			timestamp = excluded.timestamp,
+			uploader = excluded.uploader`
+	_, err := db.ExecContext(ctx, writerQuery(query, postgres),
+		file.Filename, file.URL, file.DescriptionURL, file.SHA1, file.Size, file.Width, file.Height,
+		file.MimeType, file.Timestamp.UTC(), uploader)
+	if err != nil {
+		return fmt.Errorf("%w: failed to write file %s: %v", ErrDatabaseError, file.Filename, err)
+	}
+	return nil
+}
+
+// writerQuery numbers the ? placeholders of query for PostgreSQL.
+func writerQuery(query string, postgres bool) string {
+	if !postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,202 @@
+package irowiki_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// TestSQLiteWriter_BuildArchive tests building a new archive from models
+func TestSQLiteWriter_BuildArchive(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "new.db")
+
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("failed to open writer: %v", err)
+	}
+
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	width := 64
+	err = w.Batch(ctx, func(w irowiki.ArchiveWriter) error {
+		if err := w.UpsertPage(ctx, &irowiki.Page{ID: 1, Title: "Poring"}); err != nil {
+			return err
+		}
+		if err := w.InsertRevision(ctx, &irowiki.Revision{
+			ID: 10, PageID: 1, Timestamp: ts, User: "Admin", Comment: "Created",
+			Content: "Poring is a slime.", Tags: []string{"mobile edit"},
+		}); err != nil {
+			return err
+		}
+		parent := int64(10)
+		if err := w.InsertRevision(ctx, &irowiki.Revision{
+			ID: 11, PageID: 1, ParentID: &parent, Timestamp: ts.Add(time.Hour), User: "Editor",
+			Content: "Poring is a pink slime monster.",
+		}); err != nil {
+			return err
+		}
+		return w.InsertFile(ctx, &irowiki.File{
+			Filename: "Poring.png", URL: "https://irowiki.org/images/Poring.png",
+			SHA1: "abc", Size: 1024, Width: &width, Height: &width, MimeType: "image/png", Timestamp: ts,
+		})
+	})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	// Test: Upserting an existing page renames it
+	if err := w.UpsertPage(ctx, &irowiki.Page{ID: 1, Title: "Poring (monster)"}); err != nil {
+		t.Fatalf("UpsertPage failed: %v", err)
+	}
+	// Test: Re-inserting a revision is a no-op
+	if err := w.InsertRevision(ctx, &irowiki.Revision{ID: 10, PageID: 1, Timestamp: ts, Content: "changed"}); err != nil {
+		t.Fatalf("InsertRevision of an existing revision failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer client.Close()
+
+	page, err := client.GetPage(ctx, "Poring (monster)")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.LatestRevisionID != 11 || page.Content != "Poring is a pink slime monster." {
+		t.Errorf("expected latest revision 11, got %d: %q", page.LatestRevisionID, page.Content)
+	}
+
+	rev, err := client.GetRevision(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetRevision failed: %v", err)
+	}
+	if rev.Content != "Poring is a slime." {
+		t.Errorf("expected the original revision to be kept, got %q", rev.Content)
+	}
+	if rev.Size != len("Poring is a slime.") {
+		t.Errorf("expected size computed from content, got %d", rev.Size)
+	}
+	if sum := sha1.Sum([]byte("Poring is a slime.")); rev.SHA1 != hex.EncodeToString(sum[:]) {
+		t.Errorf("expected a computed SHA-1, got %q", rev.SHA1)
+	}
+	if len(rev.Tags) != 1 || rev.Tags[0] != "mobile edit" {
+		t.Errorf("expected tags to round-trip, got %v", rev.Tags)
+	}
+
+	file, err := client.GetFile(ctx, "Poring.png")
+	if err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	if file.Size != 1024 || file.Width == nil || *file.Width != 64 {
+		t.Errorf("unexpected file: %+v", file)
+	}
+
+	// Test: Writes are indexed for full-text search
+	results, err := client.SearchFullText(ctx, "pink", irowiki.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchFullText failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Poring (monster)" {
+		t.Errorf("expected the renamed page to be found, got %+v", results)
+	}
+}
+
+// TestSQLiteWriter_BatchRollback tests that a failed batch writes nothing
+func TestSQLiteWriter_BatchRollback(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "new.db")
+
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("failed to open writer: %v", err)
+	}
+	defer w.Close()
+
+	err = w.Batch(ctx, func(w irowiki.ArchiveWriter) error {
+		if err := w.UpsertPage(ctx, &irowiki.Page{ID: 1, Title: "Poring"}); err != nil {
+			return err
+		}
+		return w.InsertRevision(ctx, &irowiki.Revision{ID: 10, PageID: 1})
+	})
+	if !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a revision without timestamp, got %v", err)
+	}
+
+	// Test: The page from the failed batch was rolled back, so a revision
+	// referencing it violates the foreign key
+	err = w.InsertRevision(ctx, &irowiki.Revision{ID: 10, PageID: 1, Timestamp: time.Now(), Content: "x"})
+	if !errors.Is(err, irowiki.ErrDatabaseError) {
+		t.Errorf("expected ErrDatabaseError for a missing page, got %v", err)
+	}
+}
+
+// TestSQLiteWriter_Validation tests rejection of incomplete models
+func TestSQLiteWriter_Validation(t *testing.T) {
+	ctx := context.Background()
+
+	w, err := irowiki.OpenSQLiteWriter(filepath.Join(t.TempDir(), "new.db"))
+	if err != nil {
+		t.Fatalf("failed to open writer: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"page without ID", w.UpsertPage(ctx, &irowiki.Page{Title: "Poring"})},
+		{"page without title", w.UpsertPage(ctx, &irowiki.Page{ID: 1})},
+		{"negative namespace", w.UpsertPage(ctx, &irowiki.Page{ID: 1, Title: "Poring", Namespace: -1})},
+		{"revision without page", w.InsertRevision(ctx, &irowiki.Revision{ID: 1, Timestamp: time.Now()})},
+		{"file without URL", w.InsertFile(ctx, &irowiki.File{Filename: "a.png", Timestamp: time.Now()})},
+		{"nil file", w.InsertFile(ctx, nil)},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, irowiki.ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", tt.name, tt.err)
+		}
+	}
+
+	// Test: Writers inside a batch can't be closed
+	err = w.Batch(ctx, func(w irowiki.ArchiveWriter) error { return w.Close() })
+	if !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput closing a batch writer, got %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := w.UpsertPage(ctx, &irowiki.Page{ID: 1, Title: "Poring"}); !errors.Is(err, irowiki.ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+}
+
+// TestSQLiteWriter_Locked tests that a writer holds the archive lock
+func TestSQLiteWriter_Locked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.db")
+
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("failed to open writer: %v", err)
+	}
+
+	if _, err := irowiki.OpenSQLiteWriter(path); !errors.Is(err, irowiki.ErrArchiveLocked) {
+		t.Errorf("expected ErrArchiveLocked for a second writer, got %v", err)
+	}
+
+	w.Close()
+	w2, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("expected the lock to be released on Close, got %v", err)
+	}
+	w2.Close()
+}
@@ -0,0 +1,179 @@
+// Package jsonschema generates JSON Schemas (draft 2020-12) describing how
+// encoding/json encodes Go types, so consumers in other languages can
+// validate and generate code against the SDK's and the CLI's JSON output.
+//
+// Schemas follow encoding/json's rules: field names come from json tags,
+// fields tagged omitempty are optional, embedded structs are flattened, and
+// nil pointers, slices and maps are allowed to be null.
+//
+//	schema := jsonschema.For(irowiki.Page{})
+//	out, _ := json.MarshalIndent(schema, "", "  ")
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Only the keywords the generator emits are modeled.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// For returns the schema of v's type, titled with the type's name.
+func For(v any) *Schema {
+	t := reflect.TypeOf(v)
+	s := (&generator{visiting: make(map[reflect.Type]bool)}).schema(t)
+	s.Schema = Draft
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	s.Title = t.Name()
+	return s
+}
+
+// generator tracks the struct types being expanded so recursive types end
+// in an unconstrained schema instead of looping.
+type generator struct {
+	visiting map[reflect.Type]bool
+}
+
+// schema returns the schema of values of type t.
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler):
+		// Custom encodings can produce anything
+		return &Schema{}
+	case t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string
+			return nullable(&Schema{Type: "string"})
+		}
+		return nullable(&Schema{Type: "array", Items: g.schema(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		s := &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s.PropertyNames = &Schema{Pattern: `^-?[0-9]+$`}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s.PropertyNames = &Schema{Pattern: `^[0-9]+$`}
+		}
+		return nullable(s)
+	case reflect.Struct:
+		return g.object(t)
+	}
+	// Interfaces hold any value
+	return &Schema{}
+}
+
+// object returns the schema of a struct type.
+func (g *generator) object(t reflect.Type) *Schema {
+	if g.visiting[t] {
+		return &Schema{}
+	}
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema), Required: []string{}}
+	g.fields(t, s)
+	return s
+}
+
+// fields adds the properties encoding/json writes for t's fields to s,
+// flattening untagged embedded structs.
+func (g *generator) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := g.schema(ft)
+		if hasOption(opts, "string") {
+			prop = &Schema{Type: "string"}
+		}
+		s.Properties[name] = prop
+		if !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// hasOption reports whether a json tag's comma-separated options include opt.
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable allows null in addition to the types s allows.
+func nullable(s *Schema) *Schema {
+	if typ, ok := s.Type.(string); ok {
+		s.Type = []string{typ, "null"}
+	}
+	return s
+}
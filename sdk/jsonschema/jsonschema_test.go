@@ -0,0 +1,85 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/jsonschema"
+)
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type node struct {
+	base
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Created  time.Time         `json:"created"`
+	Parent   *node             `json:"parent"`
+	Children []node            `json:"children"`
+	Counts   map[int]int       `json:"counts"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Size     int64             `json:"size,string"`
+	Hidden   string            `json:"-"`
+	Plain    bool
+	internal int
+}
+
+// TestFor tests schema generation from encoding/json's rules
+func TestFor(t *testing.T) {
+	s := jsonschema.For(node{})
+
+	// Test: The root names the dialect and the type
+	if s.Schema != jsonschema.Draft || s.Title != "node" || s.Type != "object" {
+		t.Errorf("unexpected root: %+v", s)
+	}
+
+	// Test: Embedded fields are flattened; skipped and unexported fields are absent
+	want := []string{"id", "name", "note", "created", "parent", "children", "counts", "labels", "size", "Plain"}
+	if len(s.Properties) != len(want) {
+		t.Errorf("expected %d properties, got %d", len(want), len(s.Properties))
+	}
+	for _, name := range want {
+		if s.Properties[name] == nil {
+			t.Errorf("missing property %q", name)
+		}
+	}
+
+	// Test: omitempty fields aren't required
+	wantRequired := []string{"id", "name", "created", "parent", "children", "counts", "size", "Plain"}
+	if !reflect.DeepEqual(s.Required, wantRequired) {
+		t.Errorf("expected required %v, got %v", wantRequired, s.Required)
+	}
+
+	// Test: Property types follow the Go types
+	tests := []struct {
+		name   string
+		typ    any
+		format string
+	}{
+		{"id", "integer", ""},
+		{"created", "string", "date-time"},
+		{"children", []string{"array", "null"}, ""},
+		{"counts", []string{"object", "null"}, ""},
+		{"size", "string", ""},
+		{"Plain", "boolean", ""},
+	}
+	for _, tt := range tests {
+		prop := s.Properties[tt.name]
+		if !reflect.DeepEqual(prop.Type, tt.typ) || prop.Format != tt.format {
+			t.Errorf("%s: expected %v %q, got %v %q", tt.name, tt.typ, tt.format, prop.Type, prop.Format)
+		}
+	}
+
+	// Test: Integer map keys are constrained to numbers
+	if s.Properties["counts"].PropertyNames == nil {
+		t.Error("expected propertyNames on an int-keyed map")
+	}
+
+	// Test: Recursive types end in an unconstrained schema
+	if parent := s.Properties["parent"]; parent.Properties != nil || parent.Type != nil {
+		t.Errorf("expected unconstrained recursive schema, got %+v", parent)
+	}
+}
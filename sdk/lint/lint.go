@@ -0,0 +1,171 @@
+// Package lint scans the latest revisions of an archive's pages for
+// wikitext problems and produces a maintenance report, like the cleanup
+// lists wiki editors keep by hand.
+//
+// Checks are Rules. The built-in ones find unclosed templates, deprecated
+// template parameters, pages without categories, embeds of files the
+// archive doesn't have, and headings skipping levels; editors add their
+// own by implementing Rule or with NewRule:
+//
+//	rules := append(lint.DefaultRules(client),
+//	    lint.DeprecatedParameters(map[string][]string{"Monster": {"hp2"}}),
+//	    lint.NewRule("todo", func(ctx context.Context, page *irowiki.Page) ([]lint.Issue, error) {
+//	        if strings.Contains(page.Content, "TODO") {
+//	            return []lint.Issue{{Message: "page has a TODO"}}, nil
+//	        }
+//	        return nil, nil
+//	    }),
+//	)
+//	report, err := lint.Run(ctx, client, rules, lint.Options{})
+package lint
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// pageBatch is the number of pages Run reads at a time.
+const pageBatch = 500
+
+// Issue is a problem a Rule found on a page.
+type Issue struct {
+	// Rule is the name of the rule that found the issue. Run sets it.
+	Rule string `json:"rule"`
+
+	// Title is the page's title. Run sets it.
+	Title string `json:"title"`
+
+	// Line is the 1-based line of the wikitext the issue is on, or 0 if it
+	// concerns the whole page.
+	Line int `json:"line,omitempty"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// Rule is a check of a page's latest wikitext.
+type Rule interface {
+	// Name identifies the rule in reports, such as "unclosed-template".
+	Name() string
+
+	// Check returns the issues the rule finds on a page. An error stops Run.
+	Check(ctx context.Context, page *irowiki.Page) ([]Issue, error)
+}
+
+// NewRule returns a Rule named name that runs check.
+func NewRule(name string, check func(ctx context.Context, page *irowiki.Page) ([]Issue, error)) Rule {
+	return &funcRule{name: name, check: check}
+}
+
+// funcRule is a Rule made by NewRule.
+type funcRule struct {
+	name  string
+	check func(ctx context.Context, page *irowiki.Page) ([]Issue, error)
+}
+
+func (r *funcRule) Name() string {
+	return r.name
+}
+
+func (r *funcRule) Check(ctx context.Context, page *irowiki.Page) ([]Issue, error) {
+	return r.check(ctx, page)
+}
+
+// Options configures Run.
+type Options struct {
+	// Namespaces are the namespaces scanned. Default: the main namespace (0).
+	Namespaces []int
+
+	// Progress receives a report after each batch of pages (stage "lint").
+	// Totals are -1. Optional.
+	Progress irowiki.Progress
+}
+
+// Validate checks if the Options are valid.
+func (o *Options) Validate() error {
+	for _, ns := range o.Namespaces {
+		if ns < 0 {
+			return fmt.Errorf("namespaces must be non-negative, got %d", ns)
+		}
+	}
+	return nil
+}
+
+// Report is the result of Run.
+type Report struct {
+	// Pages is the number of pages scanned. Redirects aren't scanned.
+	Pages int `json:"pages"`
+
+	// Issues are the issues found, by page title, then line.
+	Issues []Issue `json:"issues"`
+
+	// Counts is the number of issues found by each rule, including rules
+	// that found none.
+	Counts map[string]int `json:"counts"`
+}
+
+// Run checks the latest revision of every page of the selected namespaces
+// against the rules.
+func Run(ctx context.Context, client irowiki.Client, rules []Rule, opts Options) (*Report, error) {
+	if client == nil {
+		return nil, fmt.Errorf("%w: client is required", irowiki.ErrInvalidInput)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: at least one rule is required", irowiki.ErrInvalidInput)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", irowiki.ErrInvalidInput, err)
+	}
+	namespaces := opts.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []int{0}
+	}
+
+	report := &Report{Issues: []Issue{}, Counts: make(map[string]int, len(rules))}
+	for _, rule := range rules {
+		report.Counts[rule.Name()] = 0
+	}
+
+	for _, ns := range namespaces {
+		for offset := 0; ; offset += pageBatch {
+			pages, err := client.ListPages(ctx, ns, offset, pageBatch)
+			if err != nil {
+				return nil, err
+			}
+			for i := range pages {
+				page := &pages[i]
+				if page.IsRedirect {
+					continue
+				}
+				report.Pages++
+				for _, rule := range rules {
+					issues, err := rule.Check(ctx, page)
+					if err != nil {
+						return nil, fmt.Errorf("rule %s failed on %s: %w", rule.Name(), page.Title, err)
+					}
+					for _, issue := range issues {
+						issue.Rule = rule.Name()
+						issue.Title = page.Title
+						report.Issues = append(report.Issues, issue)
+						report.Counts[issue.Rule]++
+					}
+				}
+			}
+			if opts.Progress != nil {
+				opts.Progress.OnProgress(int64(report.Pages), -1, "lint")
+			}
+			if len(pages) < pageBatch {
+				break
+			}
+		}
+	}
+
+	slices.SortStableFunc(report.Issues, func(a, b Issue) int {
+		return cmp.Or(cmp.Compare(a.Title, b.Title), cmp.Compare(a.Line, b.Line))
+	})
+	return report, nil
+}
@@ -0,0 +1,157 @@
+package lint_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/lint"
+)
+
+// TestRun tests the built-in rules on the fixture archive
+func TestRun(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	poring := strings.Join([]string{
+		"{{Monster|name=Poring|hp2=50|drops={{Item|Jellopy}}}}",
+		"Poring is a pink slime monster. [[File:Example.png]] [[File:Missing card.png]]",
+		"=== Drops ===",
+		"{{Infobox|<!-- {{ not a template -->",
+		"== Trivia ==",
+		"<nowiki>}}</nowiki>",
+		"[[Category:Monsters]]",
+	}, "\n")
+	if _, err := tdb.DB.Exec(`UPDATE revisions SET content = ? WHERE revision_id = 104`, poring); err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+	if _, err := tdb.DB.Exec(`UPDATE revisions SET content = 'Prontera is the capital city. [[Category:Cities]]' WHERE revision_id = 103`); err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	rules := append(lint.DefaultRules(client),
+		lint.DeprecatedParameters(map[string][]string{"monster": {"hp2"}}),
+		lint.NewRule("short-page", func(ctx context.Context, page *irowiki.Page) ([]lint.Issue, error) {
+			if len(page.Content) < 25 {
+				return []lint.Issue{{Message: "page is short"}}, nil
+			}
+			return nil, nil
+		}),
+	)
+	report, err := lint.Run(context.Background(), client, rules, lint.Options{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Main_Page, Prontera and Poring; the redirect isn't scanned
+	if report.Pages != 3 {
+		t.Errorf("expected 3 pages, got %d", report.Pages)
+	}
+
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.Title+":"+issue.Rule)
+	}
+	want := []string{
+		"Main_Page:missing-category",
+		"Main_Page:short-page",
+		"Poring:deprecated-parameter",
+		"Poring:broken-file",
+		"Poring:heading-jump",
+		"Poring:unclosed-template",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected issues %v, got %v", want, got)
+	}
+
+	for _, issue := range report.Issues {
+		switch issue.Rule {
+		case "broken-file":
+			if issue.Line != 2 || !strings.Contains(issue.Message, "Missing card.png") {
+				t.Errorf("unexpected broken file issue %+v", issue)
+			}
+		case "heading-jump":
+			if issue.Line != 3 || !strings.Contains(issue.Message, `"Drops" is level 3 after level 1`) {
+				t.Errorf("unexpected heading issue %+v", issue)
+			}
+		case "unclosed-template":
+			if issue.Line != 4 {
+				t.Errorf("expected the unclosed template on line 4, got %+v", issue)
+			}
+		case "deprecated-parameter":
+			if issue.Line != 1 || !strings.Contains(issue.Message, `"hp2"`) {
+				t.Errorf("unexpected deprecated parameter issue %+v", issue)
+			}
+		}
+	}
+	if report.Counts["heading-jump"] != 1 || report.Counts["missing-category"] != 1 {
+		t.Errorf("unexpected counts %v", report.Counts)
+	}
+}
+
+// TestMissingCategoriesWithSuggestions tests naming suggested categories
+// in missing category issues
+func TestMissingCategoriesWithSuggestions(t *testing.T) {
+	var asked []string
+	suggest := func(ctx context.Context, title string, n int) ([]irowiki.CategorySuggestion, error) {
+		asked = append(asked, title)
+		return []irowiki.CategorySuggestion{{Category: "Monsters"}, {Category: "Slimes"}}[:n], nil
+	}
+	rule := lint.MissingCategoriesWithSuggestions(suggest, 2)
+	if rule.Name() != "missing-category" {
+		t.Errorf("expected the missing-category rule, got %q", rule.Name())
+	}
+
+	issues, err := rule.Check(context.Background(), &irowiki.Page{Title: "Poring", Content: "A pink slime."})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Message != "page has no category; consider Monsters, Slimes" {
+		t.Errorf("expected one issue naming the suggestions, got %+v", issues)
+	}
+
+	issues, err = rule.Check(context.Background(), &irowiki.Page{Title: "Prontera", Content: "[[Category:Cities]]"})
+	if err != nil || len(issues) != 0 {
+		t.Errorf("expected no issue for a categorized page, got %+v, %v", issues, err)
+	}
+	if len(asked) != 1 {
+		t.Errorf("expected suggestions only for the uncategorized page, asked for %v", asked)
+	}
+}
+
+// TestRun_Invalid tests argument validation and rule errors
+func TestRun_Invalid(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if _, err := lint.Run(ctx, client, nil, lint.Options{}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput without rules, got %v", err)
+	}
+	if _, err := lint.Run(ctx, client, lint.DefaultRules(client), lint.Options{Namespaces: []int{-1}}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for a negative namespace, got %v", err)
+	}
+
+	errBoom := errors.New("boom")
+	failing := lint.NewRule("failing", func(ctx context.Context, page *irowiki.Page) ([]lint.Issue, error) {
+		return nil, errBoom
+	})
+	if _, err := lint.Run(ctx, client, []lint.Rule{failing}, lint.Options{}); !errors.Is(err, errBoom) {
+		t.Errorf("expected the rule's error, got %v", err)
+	}
+}
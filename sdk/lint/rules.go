@@ -0,0 +1,336 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+// DefaultRules returns the built-in rules that need no configuration:
+// UnclosedTemplates, MissingCategories, BrokenFileEmbeds and HeadingJumps.
+func DefaultRules(client irowiki.Client) []Rule {
+	return []Rule{UnclosedTemplates(), MissingCategories(), BrokenFileEmbeds(client), HeadingJumps()}
+}
+
+// UnclosedTemplates returns the rule "unclosed-template", which finds
+// templates and template parameters opened with {{ or {{{ and never closed,
+// and closing braces without an opening.
+func UnclosedTemplates() Rule {
+	return NewRule("unclosed-template", func(ctx context.Context, page *irowiki.Page) ([]Issue, error) {
+		var issues []Issue
+		scan := scanTemplates(page.Content)
+		for _, offset := range scan.unclosed {
+			what := "template"
+			if strings.HasPrefix(page.Content[offset:], "{{{") {
+				what = "template parameter"
+			}
+			issues = append(issues, Issue{Line: lineAt(page.Content, offset), Message: what + " is never closed"})
+		}
+		for _, offset := range scan.unmatched {
+			issues = append(issues, Issue{Line: lineAt(page.Content, offset), Message: "}} closes no template"})
+		}
+		return issues, nil
+	})
+}
+
+// DeprecatedParameters returns the rule "deprecated-parameter", which finds
+// uses of deprecated named parameters. deprecated maps template names, as
+// written without the "Template:" prefix, to their deprecated parameters.
+func DeprecatedParameters(deprecated map[string][]string) Rule {
+	normalized := make(map[string][]string, len(deprecated))
+	for name, params := range deprecated {
+		normalized[normalizeTitle(name)] = params
+	}
+	return NewRule("deprecated-parameter", func(ctx context.Context, page *irowiki.Page) ([]Issue, error) {
+		var issues []Issue
+		for _, call := range scanTemplates(page.Content).calls {
+			params, ok := normalized[call.name]
+			if !ok {
+				continue
+			}
+			for _, param := range call.params {
+				for _, d := range params {
+					if param == d {
+						issues = append(issues, Issue{
+							Line:    lineAt(page.Content, call.offset),
+							Message: fmt.Sprintf("{{%s}} parameter %q is deprecated", call.name, param),
+						})
+					}
+				}
+			}
+		}
+		return issues, nil
+	})
+}
+
+// MissingCategories returns the rule "missing-category", which finds
+// articles (main namespace pages) without a [[Category:...]] link.
+// Categories added by templates aren't seen.
+func MissingCategories() Rule {
+	return NewRule("missing-category", func(ctx context.Context, page *irowiki.Page) ([]Issue, error) {
+		if page.Namespace != 0 || len(irowiki.PageCategories(page.Content)) > 0 {
+			return nil, nil
+		}
+		return []Issue{{Message: "page has no category"}}, nil
+	})
+}
+
+// CategorySuggester proposes up to n categories for a page, like
+// irowiki.Client.SuggestCategories and archive.Archive.SuggestCategories.
+type CategorySuggester func(ctx context.Context, title string, n int) ([]irowiki.CategorySuggestion, error)
+
+// MissingCategoriesWithSuggestions returns the "missing-category" rule of
+// MissingCategories, with the names of up to n categories suggest proposes
+// for the page in each issue's message. Use it instead of MissingCategories.
+func MissingCategoriesWithSuggestions(suggest CategorySuggester, n int) Rule {
+	missing := MissingCategories()
+	return NewRule(missing.Name(), func(ctx context.Context, page *irowiki.Page) ([]Issue, error) {
+		issues, err := missing.Check(ctx, page)
+		if err != nil || len(issues) == 0 {
+			return issues, err
+		}
+		suggestions, err := suggest(ctx, page.Title, n)
+		if err != nil {
+			return nil, err
+		}
+		if len(suggestions) > 0 {
+			names := make([]string, len(suggestions))
+			for i, s := range suggestions {
+				names[i] = s.Category
+			}
+			issues[0].Message += "; consider " + strings.Join(names, ", ")
+		}
+		return issues, nil
+	})
+}
+
+// BrokenFileEmbeds returns the rule "broken-file", which finds embeds of
+// files the archive has no metadata for, such as files deleted from the
+// wiki or misspelled names.
+func BrokenFileEmbeds(client irowiki.Client) Rule {
+	var mu sync.Mutex
+	known := make(map[string]bool)
+
+	exists := func(ctx context.Context, name string) (bool, error) {
+		mu.Lock()
+		found, ok := known[name]
+		mu.Unlock()
+		if ok {
+			return found, nil
+		}
+		// Archives store file names with underscores
+		for _, filename := range []string{strings.ReplaceAll(name, " ", "_"), name} {
+			_, err := client.GetFile(ctx, filename)
+			if err == nil {
+				found = true
+				break
+			}
+			if !errors.Is(err, irowiki.ErrNotFound) {
+				return false, err
+			}
+		}
+		mu.Lock()
+		known[name] = found
+		mu.Unlock()
+		return found, nil
+	}
+
+	return NewRule("broken-file", func(ctx context.Context, page *irowiki.Page) ([]Issue, error) {
+		var issues []Issue
+		seen := make(map[string]bool)
+		doc := wikitext.Parse(page.Content)
+		for _, l := range wikitext.Find[*wikitext.Link](doc) {
+			ns, title := irowiki.ParseTitle(l.Page())
+			if ns != irowiki.NamespaceFile || strings.HasPrefix(l.Page(), ":") {
+				continue
+			}
+			name := normalizeTitle(title)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			found, err := exists(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				issues = append(issues, Issue{Line: doc.Line(l), Message: fmt.Sprintf("file %q isn't in the archive", name)})
+			}
+		}
+		return issues, nil
+	})
+}
+
+// HeadingJumps returns the rule "heading-jump", which finds headings more
+// than one level below the heading before them, such as a === heading
+// directly under the page title or a == heading, which breaks the table of
+// contents and screen reader navigation.
+func HeadingJumps() Rule {
+	return NewRule("heading-jump", func(ctx context.Context, page *irowiki.Page) ([]Issue, error) {
+		var issues []Issue
+		// The page title is the level 1 heading
+		previous := 1
+		doc := wikitext.Parse(page.Content)
+		for _, h := range wikitext.Find[*wikitext.Heading](doc) {
+			if h.Level > previous+1 {
+				text := strings.TrimSpace(page.Content[h.Start+h.Level : h.End-h.Level])
+				issues = append(issues, Issue{
+					Line:    doc.Line(h),
+					Message: fmt.Sprintf("heading %q is level %d after level %d", text, h.Level, previous),
+				})
+			}
+			previous = h.Level
+		}
+		return issues, nil
+	})
+}
+
+// templateCall is a template used in wikitext.
+type templateCall struct {
+	// name is the normalized template name
+	name string
+
+	// params are the names of its named parameters
+	params []string
+
+	// offset is where the call starts
+	offset int
+}
+
+// templateScan is the result of scanTemplates.
+type templateScan struct {
+	calls []templateCall
+
+	// unclosed are the offsets of {{ and {{{ never closed, unmatched those
+	// of }} closing nothing
+	unclosed  []int
+	unmatched []int
+}
+
+// scanTemplates finds the template calls of wikitext, skipping comments
+// and <nowiki> sections. Parser functions ({{#if:...}}) and magic words
+// ({{PAGENAME}}, {{subst:...}}) count as calls, with names as written.
+func scanTemplates(content string) templateScan {
+	type frame struct {
+		start int
+
+		// braces is 2 for a template, 3 for a parameter
+		braces int
+	}
+	var scan templateScan
+	var stack []frame
+
+	for i := 0; i < len(content); {
+		rest := content[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i = skipPast(content, i, "-->")
+		case hasPrefixFold(rest, "<nowiki>"):
+			i = skipPast(content, i, "</nowiki>")
+		case strings.HasPrefix(rest, "{{{"):
+			stack = append(stack, frame{start: i, braces: 3})
+			i += 3
+		case strings.HasPrefix(rest, "{{"):
+			stack = append(stack, frame{start: i, braces: 2})
+			i += 2
+		case strings.HasPrefix(rest, "}}}") && len(stack) > 0 && stack[len(stack)-1].braces == 3:
+			stack = stack[:len(stack)-1]
+			i += 3
+		case strings.HasPrefix(rest, "}}"):
+			if len(stack) > 0 && stack[len(stack)-1].braces == 2 {
+				f := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if call, ok := parseTemplateCall(content[f.start+2 : i]); ok {
+					call.offset = f.start
+					scan.calls = append(scan.calls, call)
+				}
+			} else {
+				scan.unmatched = append(scan.unmatched, i)
+			}
+			i += 2
+		default:
+			i++
+		}
+	}
+	for _, f := range stack {
+		scan.unclosed = append(scan.unclosed, f.start)
+	}
+	return scan
+}
+
+// parseTemplateCall parses the text between a template's braces.
+func parseTemplateCall(inner string) (templateCall, bool) {
+	parts := splitTopLevel(inner)
+	name := normalizeTitle(parts[0])
+	if name == "" {
+		return templateCall{}, false
+	}
+	call := templateCall{name: name}
+	for _, part := range parts[1:] {
+		if key, _, ok := strings.Cut(part, "="); ok {
+			if key = strings.TrimSpace(key); key != "" && !strings.ContainsAny(key, "{[") {
+				call.params = append(call.params, key)
+			}
+		}
+	}
+	return call, true
+}
+
+// splitTopLevel splits a template call at the pipes outside nested
+// templates and links.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"), strings.HasPrefix(s[i:], "[["):
+			depth++
+			i++
+		case (strings.HasPrefix(s[i:], "}}") || strings.HasPrefix(s[i:], "]]")) && depth > 0:
+			depth--
+			i++
+		case s[i] == '|' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// skipPast returns the offset after the first end at or after i, or the
+// end of content if there's none.
+func skipPast(content string, i int, end string) int {
+	j := strings.Index(strings.ToLower(content[i:]), end)
+	if j < 0 {
+		return len(content)
+	}
+	return i + j + len(end)
+}
+
+// hasPrefixFold reports whether s starts with prefix, ignoring case.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// lineAt returns the 1-based line of an offset.
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+// normalizeTitle applies MediaWiki title rules to a name: underscores
+// become spaces and the first letter is upper-cased.
+func normalizeTitle(name string) string {
+	name = strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+	if name == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
@@ -0,0 +1,240 @@
+// Package render converts MediaWiki wikitext into safe HTML for display,
+// or into plain text (Text) and Markdown (Markdown) for other outputs.
+//
+// It covers the markup found in most wiki articles (headings, emphasis,
+// internal and external links, lists, horizontal rules and paragraphs).
+// Templates, tables and parser functions are not expanded; templates are
+// removed so their parameters don't clutter the output. All text is
+// HTML-escaped, so the output is safe to embed in a page.
+//
+//	html := render.HTML(page.Content, render.Options{})
+package render
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+// Options configures rendering.
+type Options struct {
+	// PageURL returns the href for an internal link target.
+	// Default: "/wiki/" followed by the escaped title with spaces as underscores.
+	PageURL func(title string) string
+
+	// ArchiveURL returns an archived copy of an external URL, or "" if the
+	// link is alive or has no copy. When set, dead links point to the archive
+	// and keep the original URL as a secondary "original" link.
+	ArchiveURL func(rawURL string) string
+}
+
+// DefaultPageURL is the PageURL used when Options.PageURL is nil.
+func DefaultPageURL(title string) string {
+	title = strings.ReplaceAll(strings.TrimSpace(title), " ", "_")
+	return "/wiki/" + strings.ReplaceAll(url.PathEscape(title), "%2F", "/")
+}
+
+var (
+	// commentPattern matches HTML comments, which are never displayed.
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// internalLinkPattern matches [[Target]], [[Target|label]] and a trailing
+	// suffix ("[[Poring]]s" links Poring with the label "Porings").
+	internalLinkPattern = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]*))?\]\]([a-z]*)`)
+
+	// externalLinkPattern matches [http://url label] and [http://url].
+	externalLinkPattern = regexp.MustCompile(`\[(https?://[^\s\]]+)(?:\s+([^\]]*))?\]`)
+
+	// bareURLPattern matches bare URLs not already inside an href.
+	bareURLPattern = regexp.MustCompile(`(^|[\s(])(https?://[^\s<>"\[\]]+)`)
+
+	// boldItalicPattern, boldPattern and italicPattern match wikitext emphasis
+	// after escaping, which encodes apostrophes as &#39;.
+	boldItalicPattern = regexp.MustCompile(`(?:&#39;){5}(.+?)(?:&#39;){5}`)
+	boldPattern       = regexp.MustCompile(`(?:&#39;){3}(.+?)(?:&#39;){3}`)
+	italicPattern     = regexp.MustCompile(`(?:&#39;){2}(.+?)(?:&#39;){2}`)
+)
+
+// HTML renders wikitext as HTML.
+func HTML(wikitext string, opts Options) string {
+	if opts.PageURL == nil {
+		opts.PageURL = DefaultPageURL
+	}
+
+	text := commentPattern.ReplaceAllString(wikitext, "")
+	text = stripTemplates(text)
+
+	r := &renderer{opts: opts}
+	for _, line := range strings.Split(text, "\n") {
+		r.line(strings.TrimRight(line, " \t\r"))
+	}
+	r.closeParagraph()
+	r.closeLists(0)
+
+	return r.out.String()
+}
+
+// renderer accumulates block-level state while rendering line by line.
+type renderer struct {
+	opts      Options
+	out       strings.Builder
+	paragraph []string
+	lists     []byte
+}
+
+// line renders a single line of wikitext.
+func (r *renderer) line(line string) {
+	h := wikitext.ParseHeading(line)
+	switch {
+	case line == "":
+		r.closeParagraph()
+		r.closeLists(0)
+
+	case strings.HasPrefix(line, "----"):
+		r.closeParagraph()
+		r.closeLists(0)
+		r.out.WriteString("<hr>\n")
+
+	case h != nil:
+		r.closeParagraph()
+		r.closeLists(0)
+		tag := strconv.Itoa(h.Level)
+		r.out.WriteString("<h" + tag + ">" + r.inline(headingText(line, h)) + "</h" + tag + ">\n")
+
+	case line[0] == '*' || line[0] == '#':
+		r.closeParagraph()
+		markers := line[:len(line)-len(strings.TrimLeft(line, "*#"))]
+		r.openLists(markers)
+		r.out.WriteString("<li>" + r.inline(strings.TrimSpace(line[len(markers):])) + "</li>\n")
+
+	default:
+		r.closeLists(0)
+		r.paragraph = append(r.paragraph, r.inline(line))
+	}
+}
+
+// openLists adjusts the open <ul>/<ol> stack to match markers such as "*#".
+func (r *renderer) openLists(markers string) {
+	common := 0
+	for common < len(r.lists) && common < len(markers) && r.lists[common] == markers[common] {
+		common++
+	}
+	r.closeLists(common)
+	for i := common; i < len(markers); i++ {
+		if markers[i] == '#' {
+			r.out.WriteString("<ol>\n")
+		} else {
+			r.out.WriteString("<ul>\n")
+		}
+		r.lists = append(r.lists, markers[i])
+	}
+}
+
+// closeLists closes open lists until depth remain.
+func (r *renderer) closeLists(depth int) {
+	for len(r.lists) > depth {
+		if r.lists[len(r.lists)-1] == '#' {
+			r.out.WriteString("</ol>\n")
+		} else {
+			r.out.WriteString("</ul>\n")
+		}
+		r.lists = r.lists[:len(r.lists)-1]
+	}
+}
+
+// closeParagraph flushes pending paragraph lines.
+func (r *renderer) closeParagraph() {
+	if len(r.paragraph) == 0 {
+		return
+	}
+	r.out.WriteString("<p>" + strings.Join(r.paragraph, "\n") + "</p>\n")
+	r.paragraph = nil
+}
+
+// inline renders inline markup. Text is escaped first; link targets are
+// unescaped and re-escaped where they are emitted.
+func (r *renderer) inline(text string) string {
+	text = html.EscapeString(text)
+
+	text = boldItalicPattern.ReplaceAllString(text, "<b><i>$1</i></b>")
+	text = boldPattern.ReplaceAllString(text, "<b>$1</b>")
+	text = italicPattern.ReplaceAllString(text, "<i>$1</i>")
+
+	text = internalLinkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := internalLinkPattern.FindStringSubmatch(m)
+		target := html.UnescapeString(strings.TrimSpace(parts[1]))
+
+		// Category and file links are metadata or media, not prose
+		lower := strings.ToLower(target)
+		if strings.HasPrefix(lower, "category:") || strings.HasPrefix(lower, "file:") || strings.HasPrefix(lower, "image:") {
+			return ""
+		}
+
+		label := parts[2]
+		if label == "" {
+			label = html.EscapeString(strings.TrimPrefix(target, ":"))
+		}
+		label += parts[3]
+
+		href := r.opts.PageURL(strings.TrimPrefix(target, ":"))
+		return `<a href="` + html.EscapeString(href) + `">` + label + `</a>`
+	})
+
+	text = externalLinkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := externalLinkPattern.FindStringSubmatch(m)
+		label := parts[2]
+		if label == "" {
+			label = parts[1]
+		}
+		return r.externalLink(html.UnescapeString(parts[1]), label)
+	})
+
+	text = bareURLPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := bareURLPattern.FindStringSubmatch(m)
+		rawURL := strings.TrimRight(html.UnescapeString(parts[2]), ".,;:!?)")
+		trailing := html.EscapeString(strings.TrimPrefix(html.UnescapeString(parts[2]), rawURL))
+		return parts[1] + r.externalLink(rawURL, html.EscapeString(rawURL)) + trailing
+	})
+
+	return text
+}
+
+// externalLink renders an external link, pointing dead links at their archived copy.
+// label must already be escaped.
+func (r *renderer) externalLink(rawURL, label string) string {
+	if r.opts.ArchiveURL != nil {
+		if archived := r.opts.ArchiveURL(rawURL); archived != "" {
+			return `<a class="external archived" href="` + html.EscapeString(archived) + `" rel="nofollow">` + label +
+				`</a> <small>(<a class="external dead" href="` + html.EscapeString(rawURL) + `" rel="nofollow">original</a>)</small>`
+		}
+	}
+	return `<a class="external" href="` + html.EscapeString(rawURL) + `" rel="nofollow">` + label + `</a>`
+}
+
+// headingText returns the source of a heading line between its = marks.
+func headingText(line string, h *wikitext.Heading) string {
+	return strings.TrimSpace(line[h.Level : h.End-h.Level])
+}
+
+// stripTemplates removes {{...}} templates, including nested ones.
+func stripTemplates(text string) string {
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "{{"):
+			depth++
+			i++
+		case depth > 0 && strings.HasPrefix(text[i:], "}}"):
+			depth--
+			i++
+		case depth == 0:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package render_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/render"
+)
+
+// TestHTML tests rendering of common wikitext markup
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		wikitext string
+		want     string
+	}{
+		{
+			name:     "paragraphs and emphasis",
+			wikitext: "'''Poring''' is a ''pink'' monster.\n\nIt drops '''''Jellopy'''''.",
+			want:     "<p><b>Poring</b> is a <i>pink</i> monster.</p>\n<p>It drops <b><i>Jellopy</i></b>.</p>\n",
+		},
+		{
+			name:     "headings",
+			wikitext: "== Drops ==\n=== Common ===",
+			want:     "<h2>Drops</h2>\n<h3>Common</h3>\n",
+		},
+		{
+			name:     "internal links",
+			wikitext: "See [[Prontera]], [[Poring Card|the card]] and [[Poring]]s.",
+			want:     `<p>See <a href="/wiki/Prontera">Prontera</a>, <a href="/wiki/Poring_Card">the card</a> and <a href="/wiki/Poring">Porings</a>.</p>` + "\n",
+		},
+		{
+			name:     "categories and templates are dropped",
+			wikitext: "{{Infobox|name={{PAGENAME}}}}Text[[Category:Monsters]]",
+			want:     "<p>Text</p>\n",
+		},
+		{
+			name:     "nested lists",
+			wikitext: "* Jellopy\n** 70%\n# First",
+			want:     "<ul>\n<li>Jellopy</li>\n<ul>\n<li>70%</li>\n</ul>\n</ul>\n<ol>\n<li>First</li>\n</ol>\n",
+		},
+		{
+			name:     "external links",
+			wikitext: "[http://example.com/a?b=1&c=2 Example] and http://example.org.",
+			want: `<p><a class="external" href="http://example.com/a?b=1&amp;c=2" rel="nofollow">Example</a> and ` +
+				`<a class="external" href="http://example.org" rel="nofollow">http://example.org</a>.</p>` + "\n",
+		},
+		{
+			name:     "markup is escaped",
+			wikitext: `<script>alert("x")</script> [[<b>]]`,
+			want:     "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <a href=\"/wiki/%3Cb%3E\">&lt;b&gt;</a></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render.HTML(tt.wikitext, render.Options{})
+			if got != tt.want {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestHTML_ArchiveURL tests that dead links point at their archived copy
+func TestHTML_ArchiveURL(t *testing.T) {
+	opts := render.Options{
+		ArchiveURL: func(rawURL string) string {
+			if rawURL == "http://dead.example.com" {
+				return "https://web.archive.org/web/2019/http://dead.example.com"
+			}
+			return ""
+		},
+	}
+
+	got := render.HTML("[http://dead.example.com Guide] [http://alive.example.com Other]", opts)
+
+	if !strings.Contains(got, `<a class="external archived" href="https://web.archive.org/web/2019/http://dead.example.com" rel="nofollow">Guide</a>`) {
+		t.Errorf("expected dead link to point at the archive, got %s", got)
+	}
+	if !strings.Contains(got, `href="http://dead.example.com" rel="nofollow">original</a>`) {
+		t.Errorf("expected original URL to be kept, got %s", got)
+	}
+	if !strings.Contains(got, `<a class="external" href="http://alive.example.com" rel="nofollow">Other</a>`) {
+		t.Errorf("expected live link unchanged, got %s", got)
+	}
+}
+
+// TestText tests plain-text rendering of common wikitext markup
+func TestText(t *testing.T) {
+	tests := []struct {
+		name     string
+		wikitext string
+		want     string
+	}{
+		{
+			name:     "paragraphs and emphasis",
+			wikitext: "'''Poring''' is a ''pink''\nmonster.\n\nIt drops Jellopy.",
+			want:     "Poring is a pink monster.\n\nIt drops Jellopy.\n",
+		},
+		{
+			name:     "headings are underlined",
+			wikitext: "== Drops ==\nJellopy",
+			want:     "Drops\n═════\n\nJellopy\n",
+		},
+		{
+			name:     "links keep their labels",
+			wikitext: "See [[Prontera]], [[Poring Card|the card]], [[Poring]]s and [http://example.com Example].[[Category:Monsters]]",
+			want:     "See Prontera, the card, Porings and Example <http://example.com>.\n",
+		},
+		{
+			name:     "nested and numbered lists",
+			wikitext: "* Jellopy\n** 70%\n# First\n# Second",
+			want:     "• Jellopy\n  • 70%\n1. First\n2. Second\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render.Text(tt.wikitext, render.Options{})
+			if got != tt.want {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestMarkdown tests Markdown rendering of common wikitext markup
+func TestMarkdown(t *testing.T) {
+	wikitext := "== Drops ==\n'''Poring''' drops ''[[Jellopy]]'' ([http://example.com source]).\n* Apple\n** 10%\n----"
+	want := "## Drops\n\n**Poring** drops *[Jellopy](/wiki/Jellopy)* ([source](http://example.com)).\n\n- Apple\n   - 10%\n\n---\n"
+
+	got := render.Markdown(wikitext, render.Options{PageURL: render.DefaultPageURL})
+	if got != want {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, got)
+	}
+
+	// Test: Without PageURL internal links are plain labels
+	got = render.Markdown("See [[Prontera|the capital]].", render.Options{})
+	if got != "See the capital.\n" {
+		t.Errorf("expected plain label, got %q", got)
+	}
+}
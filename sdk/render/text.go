@@ -0,0 +1,199 @@
+package render
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki/wikitext"
+)
+
+var (
+	// emphasisPattern matches runs of apostrophes used for bold and italics.
+	emphasisPattern = regexp.MustCompile(`'{2,5}`)
+
+	// rawBoldItalicPattern, rawBoldPattern and rawItalicPattern match
+	// wikitext emphasis in unescaped text.
+	rawBoldItalicPattern = regexp.MustCompile(`'{5}(.+?)'{5}`)
+	rawBoldPattern       = regexp.MustCompile(`'{3}(.+?)'{3}`)
+	rawItalicPattern     = regexp.MustCompile(`'{2}(.+?)'{2}`)
+)
+
+// Text renders wikitext as plain text for terminals and other non-HTML
+// displays: headings are underlined, lists are indented with bullets or
+// numbers, and links are reduced to their labels. External links keep their
+// URL in angle brackets; dead links with an archived copy (Options.ArchiveURL)
+// show the archive URL instead. Options.PageURL is not used.
+// Lines are not wrapped.
+func Text(wikitext string, opts Options) string {
+	return renderText(wikitext, &textRenderer{opts: opts})
+}
+
+// Markdown renders wikitext as CommonMark. Internal links become Markdown
+// links when Options.PageURL is set and plain labels otherwise; dead external
+// links point at their archived copy when Options.ArchiveURL has one.
+// Markdown punctuation in the page text is not escaped.
+func Markdown(wikitext string, opts Options) string {
+	return renderText(wikitext, &textRenderer{opts: opts, markdown: true})
+}
+
+// renderText drives a textRenderer over each line of wikitext.
+func renderText(wikitext string, t *textRenderer) string {
+	text := commentPattern.ReplaceAllString(wikitext, "")
+	text = stripTemplates(text)
+
+	for _, line := range strings.Split(text, "\n") {
+		t.line(strings.TrimRight(line, " \t\r"))
+	}
+	t.closeParagraph()
+
+	return strings.TrimRight(t.out.String(), "\n") + "\n"
+}
+
+// textRenderer accumulates block-level state while rendering plain text or
+// Markdown.
+type textRenderer struct {
+	opts      Options
+	markdown  bool
+	out       strings.Builder
+	paragraph []string
+	markers   string
+	counters  []int
+	inList    bool
+}
+
+// line renders a single line of wikitext.
+func (t *textRenderer) line(line string) {
+	h := wikitext.ParseHeading(line)
+	switch {
+	case line == "":
+		t.closeParagraph()
+		t.closeList()
+
+	case strings.HasPrefix(line, "----"):
+		t.closeParagraph()
+		t.closeList()
+		if t.markdown {
+			t.out.WriteString("---\n\n")
+		} else {
+			t.out.WriteString(strings.Repeat("─", 40) + "\n\n")
+		}
+
+	case h != nil:
+		t.closeParagraph()
+		t.closeList()
+		heading := t.inline(headingText(line, h))
+		if t.markdown {
+			t.out.WriteString(strings.Repeat("#", h.Level) + " " + heading + "\n\n")
+			break
+		}
+		underline := "─"
+		if h.Level <= 2 {
+			underline = "═"
+		}
+		t.out.WriteString(heading + "\n" + strings.Repeat(underline, utf8.RuneCountInString(heading)) + "\n\n")
+
+	case line[0] == '*' || line[0] == '#':
+		t.closeParagraph()
+		markers := line[:len(line)-len(strings.TrimLeft(line, "*#"))]
+		indent := "  "
+		if t.markdown {
+			indent = "   "
+		}
+		t.out.WriteString(strings.Repeat(indent, len(markers)-1) + t.bullet(markers) + " " + t.inline(strings.TrimSpace(line[len(markers):])) + "\n")
+		t.inList = true
+
+	default:
+		t.closeList()
+		t.paragraph = append(t.paragraph, t.inline(line))
+	}
+}
+
+// bullet returns the marker for a list item, numbering ordered lists per depth.
+func (t *textRenderer) bullet(markers string) string {
+	depth := len(markers)
+	if !strings.HasPrefix(markers, t.markers[:min(len(t.markers), depth)]) {
+		t.counters = nil
+	}
+	for len(t.counters) < depth {
+		t.counters = append(t.counters, 0)
+	}
+	t.counters = t.counters[:depth]
+	t.counters[depth-1]++
+	t.markers = markers
+
+	if markers[depth-1] == '#' {
+		return strconv.Itoa(t.counters[depth-1]) + "."
+	}
+	if t.markdown {
+		return "-"
+	}
+	return "•"
+}
+
+// closeList ends the current list with a blank line.
+func (t *textRenderer) closeList() {
+	if t.inList {
+		t.out.WriteString("\n")
+		t.inList = false
+	}
+	t.markers = ""
+	t.counters = nil
+}
+
+// closeParagraph flushes pending paragraph lines as one line of text.
+func (t *textRenderer) closeParagraph() {
+	if len(t.paragraph) == 0 {
+		return
+	}
+	t.out.WriteString(strings.Join(t.paragraph, " ") + "\n\n")
+	t.paragraph = nil
+}
+
+// inline reduces inline markup to plain text or converts it to Markdown.
+func (t *textRenderer) inline(text string) string {
+	if t.markdown {
+		text = rawBoldItalicPattern.ReplaceAllString(text, "***$1***")
+		text = rawBoldPattern.ReplaceAllString(text, "**$1**")
+		text = rawItalicPattern.ReplaceAllString(text, "*$1*")
+	} else {
+		text = emphasisPattern.ReplaceAllString(text, "")
+	}
+
+	text = internalLinkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := internalLinkPattern.FindStringSubmatch(m)
+		target := strings.TrimSpace(parts[1])
+
+		lower := strings.ToLower(target)
+		if strings.HasPrefix(lower, "category:") || strings.HasPrefix(lower, "file:") || strings.HasPrefix(lower, "image:") {
+			return ""
+		}
+
+		label := parts[2]
+		if label == "" {
+			label = strings.TrimPrefix(target, ":")
+		}
+		if t.markdown && t.opts.PageURL != nil {
+			return "[" + label + parts[3] + "](" + t.opts.PageURL(target) + ")"
+		}
+		return label + parts[3]
+	})
+
+	return externalLinkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := externalLinkPattern.FindStringSubmatch(m)
+		href := parts[1]
+		if t.opts.ArchiveURL != nil {
+			if archived := t.opts.ArchiveURL(href); archived != "" {
+				href = archived
+			}
+		}
+		switch {
+		case parts[2] == "":
+			return "<" + href + ">"
+		case t.markdown:
+			return "[" + parts[2] + "](" + href + ")"
+		}
+		return parts[2] + " <" + href + ">"
+	})
+}
@@ -0,0 +1,337 @@
+// Package report generates "state of the archive" digests from an archive's
+// edit history, for posting to community forums.
+//
+// A digest covers one calendar month (UTC): the pages created, the biggest
+// edits, the most active editors, and the pages whose editing picked up
+// compared with the month before. It renders as Markdown or HTML:
+//
+//	month := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+//	digest, err := report.MonthlyDigest(ctx, client, month, report.Options{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	report.WriteMarkdown(os.Stdout, digest)
+package report
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// Options configures MonthlyDigest.
+type Options struct {
+	// Limit is the number of entries in each section. Default: 10.
+	Limit int
+
+	// PageURL links page titles. Default: the page on the source wiki, when
+	// the archive's metadata has its base URL; otherwise titles aren't linked.
+	PageURL func(title string) string
+}
+
+// Digest is a month's summary of an archive.
+type Digest struct {
+	// WikiName is the archived wiki's name, if the archive records it.
+	WikiName string `json:"wiki_name,omitempty"`
+
+	// Month is the month covered ("2006-01"), from Start up to End.
+	Month string    `json:"month"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Edits, Editors and PagesEdited count the month's activity.
+	Edits       int `json:"edits"`
+	Editors     int `json:"editors"`
+	PagesEdited int `json:"pages_edited"`
+
+	// BytesChanged is the net change in the size of the edited pages.
+	BytesChanged int `json:"bytes_changed"`
+
+	// NewPageCount is the number of pages created; NewPages lists the first
+	// of them, oldest first.
+	NewPageCount int       `json:"new_page_count"`
+	NewPages     []NewPage `json:"new_pages"`
+
+	// BiggestEdits are the edits to existing pages that added the most text.
+	BiggestEdits []Edit `json:"biggest_edits"`
+
+	// TopEditors are the editors with the most edits.
+	TopEditors []Editor `json:"top_editors"`
+
+	// TrendingPages are the pages whose edit count grew the most over the
+	// previous month.
+	TrendingPages []TrendingPage `json:"trending_pages"`
+}
+
+// NewPage is a page created during the month.
+type NewPage struct {
+	Title   string    `json:"title"`
+	URL     string    `json:"url,omitempty"`
+	Creator string    `json:"creator"`
+	Created time.Time `json:"created"`
+	Size    int       `json:"size"`
+}
+
+// Edit is a single edit.
+type Edit struct {
+	RevisionID int64     `json:"revision_id"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url,omitempty"`
+	User       string    `json:"user"`
+	Timestamp  time.Time `json:"timestamp"`
+	SizeDelta  int       `json:"size_delta"`
+	Comment    string    `json:"comment,omitempty"`
+}
+
+// Editor is an editor's activity during the month.
+type Editor struct {
+	Username     string `json:"username"`
+	Edits        int    `json:"edits"`
+	PagesEdited  int    `json:"pages_edited"`
+	BytesChanged int    `json:"bytes_changed"`
+}
+
+// TrendingPage is a page edited more than in the previous month.
+type TrendingPage struct {
+	Title         string `json:"title"`
+	URL           string `json:"url,omitempty"`
+	Edits         int    `json:"edits"`
+	PreviousEdits int    `json:"previous_edits"`
+	Editors       int    `json:"editors"`
+}
+
+// MonthlyDigest summarizes the edits made to an archive during the month
+// containing month (in UTC). It reads from a snapshot of the archive, so
+// the figures agree even while a scraper writes to it.
+func MonthlyDigest(ctx context.Context, client irowiki.Client, month time.Time, opts Options) (*Digest, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+
+	// Read from one snapshot, unless client already is one
+	snapshot, err := client.Snapshot(ctx)
+	if err == nil {
+		defer snapshot.Close()
+		client = snapshot
+	} else if !errors.Is(err, irowiki.ErrInvalidInput) {
+		return nil, err
+	}
+
+	month = month.UTC()
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	digest := &Digest{
+		Month:         start.Format("2006-01"),
+		Start:         start,
+		End:           end,
+		NewPages:      []NewPage{},
+		BiggestEdits:  []Edit{},
+		TopEditors:    []Editor{},
+		TrendingPages: []TrendingPage{},
+	}
+
+	info, err := client.GetArchiveInfo(ctx)
+	if err != nil && !errors.Is(err, irowiki.ErrNotFound) {
+		return nil, err
+	}
+	if info != nil {
+		digest.WikiName = info.WikiName
+		if opts.PageURL == nil && info.BaseURL != "" {
+			opts.PageURL = info.PageURL
+		}
+	}
+	if opts.PageURL == nil {
+		opts.PageURL = func(string) string { return "" }
+	}
+
+	revisions, err := revisionsBetween(ctx, client, start, end)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := revisionsBetween(ctx, client, start.AddDate(0, -1, 0), start)
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := pageTitles(ctx, client, revisions)
+	if err != nil {
+		return nil, err
+	}
+	deltas, err := sizeDeltas(ctx, client, revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	editors := make(map[string]*Editor)
+	editorPages := make(map[string]map[int64]bool)
+	pageEdits := make(map[int64]int)
+	pageEditors := make(map[int64]map[string]bool)
+	var edits []Edit
+	for _, rev := range revisions {
+		title, ok := titles[rev.PageID]
+		if !ok {
+			// Hidden by the client, e.g. deleted from the live wiki
+			continue
+		}
+		delta := deltas[rev.ID]
+
+		digest.Edits++
+		digest.BytesChanged += delta
+		pageEdits[rev.PageID]++
+		if pageEditors[rev.PageID] == nil {
+			pageEditors[rev.PageID] = make(map[string]bool)
+		}
+		pageEditors[rev.PageID][rev.User] = true
+
+		e := editors[rev.User]
+		if e == nil {
+			e = &Editor{Username: rev.User}
+			editors[rev.User] = e
+			editorPages[rev.User] = make(map[int64]bool)
+		}
+		e.Edits++
+		e.BytesChanged += delta
+		editorPages[rev.User][rev.PageID] = true
+
+		if rev.ParentID == nil {
+			digest.NewPageCount++
+			if len(digest.NewPages) < opts.Limit {
+				digest.NewPages = append(digest.NewPages, NewPage{
+					Title:   title,
+					URL:     opts.PageURL(title),
+					Creator: rev.User,
+					Created: rev.Timestamp.UTC(),
+					Size:    rev.Size,
+				})
+			}
+		} else if delta > 0 {
+			edits = append(edits, Edit{
+				RevisionID: rev.ID,
+				Title:      title,
+				URL:        opts.PageURL(title),
+				User:       rev.User,
+				Timestamp:  rev.Timestamp.UTC(),
+				SizeDelta:  delta,
+				Comment:    rev.Comment,
+			})
+		}
+	}
+	digest.Editors = len(editors)
+	digest.PagesEdited = len(pageEdits)
+
+	slices.SortStableFunc(edits, func(a, b Edit) int { return cmp.Compare(b.SizeDelta, a.SizeDelta) })
+	digest.BiggestEdits = append(digest.BiggestEdits, edits[:min(opts.Limit, len(edits))]...)
+
+	for user, e := range editors {
+		e.PagesEdited = len(editorPages[user])
+		digest.TopEditors = append(digest.TopEditors, *e)
+	}
+	slices.SortFunc(digest.TopEditors, func(a, b Editor) int {
+		return cmp.Or(cmp.Compare(b.Edits, a.Edits), cmp.Compare(a.Username, b.Username))
+	})
+	digest.TopEditors = digest.TopEditors[:min(opts.Limit, len(digest.TopEditors))]
+
+	previousEdits := make(map[int64]int)
+	for _, rev := range previous {
+		previousEdits[rev.PageID]++
+	}
+	for pageID, n := range pageEdits {
+		if n > previousEdits[pageID] {
+			digest.TrendingPages = append(digest.TrendingPages, TrendingPage{
+				Title:         titles[pageID],
+				URL:           opts.PageURL(titles[pageID]),
+				Edits:         n,
+				PreviousEdits: previousEdits[pageID],
+				Editors:       len(pageEditors[pageID]),
+			})
+		}
+	}
+	slices.SortFunc(digest.TrendingPages, func(a, b TrendingPage) int {
+		return cmp.Or(
+			cmp.Compare(b.Edits-b.PreviousEdits, a.Edits-a.PreviousEdits),
+			cmp.Compare(b.Edits, a.Edits),
+			cmp.Compare(a.Title, b.Title),
+		)
+	})
+	digest.TrendingPages = digest.TrendingPages[:min(opts.Limit, len(digest.TrendingPages))]
+
+	return digest, nil
+}
+
+// revisionsBetween returns the revisions made from start up to (excluding)
+// end, oldest first.
+func revisionsBetween(ctx context.Context, client irowiki.Client, start, end time.Time) ([]irowiki.Revision, error) {
+	revisions, err := client.GetChangesByPeriod(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	revisions = slices.DeleteFunc(revisions, func(rev irowiki.Revision) bool { return !rev.Timestamp.Before(end) })
+	slices.SortStableFunc(revisions, func(a, b irowiki.Revision) int {
+		return cmp.Or(a.Timestamp.Compare(b.Timestamp), cmp.Compare(a.ID, b.ID))
+	})
+	return revisions, nil
+}
+
+// pageTitles looks up the titles of the pages revisions belong to. Pages
+// the client doesn't return are left out.
+func pageTitles(ctx context.Context, client irowiki.Client, revisions []irowiki.Revision) (map[int64]string, error) {
+	titles := make(map[int64]string)
+	missing := make(map[int64]bool)
+	for _, rev := range revisions {
+		if _, ok := titles[rev.PageID]; ok || missing[rev.PageID] {
+			continue
+		}
+		page, err := client.GetPageByID(ctx, rev.PageID)
+		if errors.Is(err, irowiki.ErrNotFound) {
+			missing[rev.PageID] = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		titles[rev.PageID] = page.Title
+	}
+	return titles, nil
+}
+
+// sizeDeltas returns the change in page size made by each revision, reading
+// the parents of revisions whose parent was made before the period.
+func sizeDeltas(ctx context.Context, client irowiki.Client, revisions []irowiki.Revision) (map[int64]int, error) {
+	sizes := make(map[int64]int, len(revisions))
+	for _, rev := range revisions {
+		sizes[rev.ID] = rev.Size
+	}
+
+	var parents []int64
+	for _, rev := range revisions {
+		if rev.ParentID != nil {
+			if _, ok := sizes[*rev.ParentID]; !ok {
+				parents = append(parents, *rev.ParentID)
+			}
+		}
+	}
+	if len(parents) > 0 {
+		found, err := client.GetRevisionsByID(ctx, parents)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range found {
+			if parent != nil {
+				sizes[parent.ID] = parent.Size
+			}
+		}
+	}
+
+	deltas := make(map[int64]int, len(revisions))
+	for _, rev := range revisions {
+		deltas[rev.ID] = rev.Size
+		if rev.ParentID != nil {
+			deltas[rev.ID] = rev.Size - sizes[*rev.ParentID]
+		}
+	}
+	return deltas, nil
+}
@@ -0,0 +1,134 @@
+package report_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/report"
+)
+
+// TestMonthlyDigest tests summarizing a month of the fixture archive
+func TestMonthlyDigest(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	t.Run("month with edits", func(t *testing.T) {
+		// Any time in the month selects it
+		digest, err := report.MonthlyDigest(ctx, client, time.Date(2020, time.January, 15, 12, 0, 0, 0, time.UTC), report.Options{})
+		if err != nil {
+			t.Fatalf("MonthlyDigest failed: %v", err)
+		}
+
+		if digest.Month != "2020-01" || digest.Edits != 7 || digest.Editors != 3 || digest.PagesEdited != 5 {
+			t.Errorf("unexpected totals: %+v", digest)
+		}
+		if digest.NewPageCount != 5 || len(digest.NewPages) != 5 || digest.NewPages[0].Title != "Main_Page" {
+			t.Errorf("expected 5 new pages starting with Main_Page, got %+v", digest.NewPages)
+		}
+
+		// Only revision 101 added text to an existing page
+		if len(digest.BiggestEdits) != 1 || digest.BiggestEdits[0].RevisionID != 101 || digest.BiggestEdits[0].SizeDelta != 4 {
+			t.Errorf("expected revision 101 (+4) as the only big edit, got %+v", digest.BiggestEdits)
+		}
+
+		if len(digest.TopEditors) != 3 || digest.TopEditors[0].Username != "Admin" || digest.TopEditors[0].Edits != 4 {
+			t.Errorf("expected Admin to lead with 4 edits, got %+v", digest.TopEditors)
+		}
+		if digest.TopEditors[0].PagesEdited != 4 {
+			t.Errorf("expected Admin to have edited 4 pages, got %d", digest.TopEditors[0].PagesEdited)
+		}
+
+		if len(digest.TrendingPages) != 5 || digest.TrendingPages[0].Title != "Main_Page" || digest.TrendingPages[0].Edits != 2 {
+			t.Errorf("expected Main_Page to trend first with 2 edits, got %+v", digest.TrendingPages)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		digest, err := report.MonthlyDigest(ctx, client, time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), report.Options{Limit: 2})
+		if err != nil {
+			t.Fatalf("MonthlyDigest failed: %v", err)
+		}
+		if len(digest.NewPages) != 2 || digest.NewPageCount != 5 || len(digest.TopEditors) != 2 || len(digest.TrendingPages) != 2 {
+			t.Errorf("expected sections of 2 entries, got %+v", digest)
+		}
+	})
+
+	t.Run("quiet month", func(t *testing.T) {
+		digest, err := report.MonthlyDigest(ctx, client, time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC), report.Options{})
+		if err != nil {
+			t.Fatalf("MonthlyDigest failed: %v", err)
+		}
+		if digest.Edits != 0 || len(digest.TopEditors) != 0 || len(digest.TrendingPages) != 0 {
+			t.Errorf("expected an empty digest, got %+v", digest)
+		}
+	})
+}
+
+// TestWriteDigest tests rendering a digest as Markdown and HTML
+func TestWriteDigest(t *testing.T) {
+	digest := &report.Digest{
+		WikiName:     "iRO Wiki",
+		Month:        "2024-05",
+		Start:        time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+		Edits:        1,
+		Editors:      1,
+		PagesEdited:  1,
+		BytesChanged: 1500,
+		BiggestEdits: []report.Edit{{
+			RevisionID: 1,
+			Title:      "Poring_Card",
+			URL:        "https://irowiki.org/wiki/Poring_Card",
+			User:       "Editor",
+			Timestamp:  time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC),
+			SizeDelta:  1500,
+			Comment:    "<b>drops</b> *all*",
+		}},
+		TopEditors: []report.Editor{{Username: "Some_User", Edits: 1, PagesEdited: 1, BytesChanged: 1500}},
+	}
+
+	var md strings.Builder
+	if err := report.WriteMarkdown(&md, digest); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	for _, want := range []string{
+		"# iRO Wiki: May 2024 digest",
+		"(+1,500 bytes)",
+		"## Biggest edits",
+		"- [Poring Card](<https://irowiki.org/wiki/Poring_Card>): +1,500 bytes by Editor on 2024-05-02",
+		`("\<b\>drops\</b\> \*all\*")`,
+		`| Some\_User | 1 | 1 | +1,500 |`,
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("expected Markdown to contain %q, got:\n%s", want, md.String())
+		}
+	}
+	if strings.Contains(md.String(), "## New pages") {
+		t.Error("expected empty sections to be left out")
+	}
+
+	var html strings.Builder
+	if err := report.WriteHTML(&html, digest); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	for _, want := range []string{
+		"<h1>iRO Wiki: May 2024 digest</h1>",
+		`<a href="https://irowiki.org/wiki/Poring_Card">Poring Card</a>`,
+		"&lt;b&gt;drops&lt;/b&gt;",
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("expected HTML to contain %q, got:\n%s", want, html.String())
+		}
+	}
+}
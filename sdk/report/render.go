@@ -0,0 +1,120 @@
+package report
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// funcs are the template functions shared by the Markdown and HTML digests.
+var funcs = map[string]any{
+	"date":   func(t time.Time) string { return t.Format("2006-01-02") },
+	"month":  func(t time.Time) string { return t.Format("January 2006") },
+	"title":  func(title string) string { return strings.ReplaceAll(title, "_", " ") },
+	"signed": signed,
+	"md":     escapeMarkdown,
+}
+
+// signed formats a byte count with its sign ("+1,200", "-35").
+func signed(n int) string {
+	if n > 0 {
+		return "+" + formatInt(n)
+	}
+	return formatInt(n)
+}
+
+// formatInt formats n with commas between groups of three digits.
+func formatInt(n int) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + s
+}
+
+// markdownEscaper escapes the characters that would start Markdown
+// formatting in titles, usernames and edit summaries.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "|", `\|`, "#", `\#`,
+)
+
+// escapeMarkdown escapes s for use as Markdown text.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+var markdownTemplate = template.Must(template.New("digest.md").Funcs(funcs).Parse(`# {{with .WikiName}}{{md .}}: {{end}}{{month .Start}} digest
+
+{{.Edits}} edits by {{.Editors}} editors to {{.PagesEdited}} pages ({{signed .BytesChanged}} bytes), including {{.NewPageCount}} new pages.
+{{- define "page"}}{{if .URL}}[{{md (title .Title)}}](<{{.URL}}>){{else}}{{md (title .Title)}}{{end}}{{end}}
+{{with .NewPages}}
+## New pages
+
+{{range .}}- {{template "page" .}}, by {{md .Creator}} on {{date .Created}} ({{.Size}} bytes)
+{{end}}{{end}}
+{{- with .BiggestEdits}}
+## Biggest edits
+
+{{range .}}- {{template "page" .}}: {{signed .SizeDelta}} bytes by {{md .User}} on {{date .Timestamp}}{{with .Comment}} ("{{md .}}"){{end}}
+{{end}}{{end}}
+{{- with .TopEditors}}
+## Top editors
+
+| Editor | Edits | Pages | Bytes |
+|---|---:|---:|---:|
+{{range .}}| {{md .Username}} | {{.Edits}} | {{.PagesEdited}} | {{signed .BytesChanged}} |
+{{end}}{{end}}
+{{- with .TrendingPages}}
+## Trending pages
+
+{{range .}}- {{template "page" .}}: {{.Edits}} edits by {{.Editors}} editors ({{.PreviousEdits}} the month before)
+{{end}}{{end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(funcs).Parse(`<h1>{{with .WikiName}}{{.}}: {{end}}{{month .Start}} digest</h1>
+<p>{{.Edits}} edits by {{.Editors}} editors to {{.PagesEdited}} pages ({{signed .BytesChanged}} bytes), including {{.NewPageCount}} new pages.</p>
+{{- define "page"}}{{if .URL}}<a href="{{.URL}}">{{title .Title}}</a>{{else}}{{title .Title}}{{end}}{{end}}
+{{with .NewPages}}
+<h2>New pages</h2>
+<ul>
+{{range .}}<li>{{template "page" .}}, by {{.Creator}} on {{date .Created}} ({{.Size}} bytes)</li>
+{{end}}</ul>
+{{- end}}
+{{with .BiggestEdits}}
+<h2>Biggest edits</h2>
+<ul>
+{{range .}}<li>{{template "page" .}}: {{signed .SizeDelta}} bytes by {{.User}} on {{date .Timestamp}}{{with .Comment}} (&ldquo;{{.}}&rdquo;){{end}}</li>
+{{end}}</ul>
+{{- end}}
+{{with .TopEditors}}
+<h2>Top editors</h2>
+<table>
+<tr><th>Editor</th><th>Edits</th><th>Pages</th><th>Bytes</th></tr>
+{{range .}}<tr><td>{{.Username}}</td><td>{{.Edits}}</td><td>{{.PagesEdited}}</td><td>{{signed .BytesChanged}}</td></tr>
+{{end}}</table>
+{{- end}}
+{{with .TrendingPages}}
+<h2>Trending pages</h2>
+<ul>
+{{range .}}<li>{{template "page" .}}: {{.Edits}} edits by {{.Editors}} editors ({{.PreviousEdits}} the month before)</li>
+{{end}}</ul>
+{{- end}}
+`))
+
+// WriteMarkdown renders a digest as Markdown, as accepted by most forums.
+func WriteMarkdown(w io.Writer, d *Digest) error {
+	return markdownTemplate.Execute(w, d)
+}
+
+// WriteHTML renders a digest as an HTML fragment, to embed in a page or post.
+// Titles, usernames and edit summaries are escaped.
+func WriteHTML(w io.Writer, d *Digest) error {
+	return htmlTemplate.Execute(w, d)
+}
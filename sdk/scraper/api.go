@@ -0,0 +1,141 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// apiResponse is an action=query response in JSON format version 2.
+type apiResponse struct {
+	Query    apiQuery                   `json:"query"`
+	Continue map[string]json.RawMessage `json:"continue"`
+	Error    *APIError                  `json:"error"`
+}
+
+type apiQuery struct {
+	AllPages      []apiPage         `json:"allpages"`
+	Pages         []apiPage         `json:"pages"`
+	AllImages     []apiImage        `json:"allimages"`
+	RecentChanges []apiRecentChange `json:"recentchanges"`
+
+	Namespaces       map[string]apiNamespace `json:"namespaces"`
+	NamespaceAliases []apiNamespaceAlias     `json:"namespacealiases"`
+}
+
+type apiNamespace struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Canonical string `json:"canonical"`
+}
+
+type apiNamespaceAlias struct {
+	ID    int    `json:"id"`
+	Alias string `json:"alias"`
+}
+
+type apiPage struct {
+	PageID    int64         `json:"pageid"`
+	NS        int           `json:"ns"`
+	Title     string        `json:"title"`
+	Redirect  bool          `json:"redirect"`
+	Missing   bool          `json:"missing"`
+	Invalid   bool          `json:"invalid"`
+	Revisions []apiRevision `json:"revisions"`
+}
+
+type apiRevision struct {
+	RevID      int64    `json:"revid"`
+	ParentID   int64    `json:"parentid"`
+	Timestamp  string   `json:"timestamp"`
+	User       string   `json:"user"`
+	UserID     *int     `json:"userid"`
+	UserHidden bool     `json:"userhidden"`
+	Comment    string   `json:"comment"`
+	Size       int      `json:"size"`
+	SHA1       string   `json:"sha1"`
+	Minor      bool     `json:"minor"`
+	Tags       []string `json:"tags"`
+	Slots      struct {
+		Main struct {
+			Content string `json:"content"`
+		} `json:"main"`
+	} `json:"slots"`
+}
+
+type apiRecentChange struct {
+	Type      string `json:"type"`
+	PageID    int64  `json:"pageid"`
+	RevID     int64  `json:"revid"`
+	Timestamp string `json:"timestamp"`
+}
+
+type apiImage struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DescriptionURL string `json:"descriptionurl"`
+	SHA1           string `json:"sha1"`
+	Size           int    `json:"size"`
+	Width          int    `json:"width"`
+	Height         int    `json:"height"`
+	Mime           string `json:"mime"`
+	Timestamp      string `json:"timestamp"`
+	User           string `json:"user"`
+}
+
+// revision converts an API revision of a page.
+func (r apiRevision) revision(pageID int64) (*irowiki.Revision, error) {
+	ts, err := time.Parse(time.RFC3339, r.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q of revision %d: %w", r.Timestamp, r.RevID, err)
+	}
+	rev := &irowiki.Revision{
+		ID:        r.RevID,
+		PageID:    pageID,
+		Timestamp: ts,
+		User:      r.User,
+		UserID:    r.UserID,
+		Comment:   r.Comment,
+		Content:   r.Slots.Main.Content,
+		Size:      r.Size,
+		SHA1:      r.SHA1,
+		Minor:     r.Minor,
+		Tags:      r.Tags,
+	}
+	// The API gives 0 for a page's first revision
+	if r.ParentID > 0 {
+		parent := r.ParentID
+		rev.ParentID = &parent
+	}
+	// Hidden users are stored as anonymous
+	if r.UserHidden {
+		rev.User, rev.UserID = "", nil
+	}
+	return rev, nil
+}
+
+// file converts an API file.
+func (i apiImage) file() (*irowiki.File, error) {
+	ts, err := time.Parse(time.RFC3339, i.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q of file %s: %w", i.Timestamp, i.Name, err)
+	}
+	file := &irowiki.File{
+		Filename:       i.Name,
+		URL:            i.URL,
+		DescriptionURL: i.DescriptionURL,
+		SHA1:           i.SHA1,
+		Size:           i.Size,
+		MimeType:       i.Mime,
+		Timestamp:      ts,
+		Uploader:       i.User,
+	}
+	// Files without dimensions, such as PDFs, report 0
+	if i.Width > 0 && i.Height > 0 {
+		width, height := i.Width, i.Height
+		file.Width, file.Height = &width, &height
+	}
+	return file, nil
+}
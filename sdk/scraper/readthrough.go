@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// ReadThrough returns an interceptor that turns an archive into a lazily
+// populated cache of the wiki. When GetPage doesn't find a page in the
+// archive, the interceptor fetches the page's history from the wiki by
+// title, writes it to w, and serves the page from the archive. Later reads
+// of the page are served from the archive without contacting the wiki.
+//
+// Pages the wiki doesn't have either are still irowiki.ErrNotFound, and
+// so are titles the wiki normalizes differently from the archive, such as
+// a lower-case first letter. A failed fetch is returned with
+// irowiki.ErrConnectionFailed or an *APIError. Other methods are passed
+// through.
+//
+// Example:
+//
+//	client = irowiki.WithInterceptor(client, s.ReadThrough(w))
+//	page, err := client.GetPage(ctx, "Poring") // fetched on a miss
+func (c *Client) ReadThrough(w irowiki.ArchiveWriter) irowiki.Interceptor {
+	return func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+		result, err := next(ctx)
+		if call.Method != "GetPage" || !errors.Is(err, irowiki.ErrNotFound) {
+			return result, err
+		}
+		title, _ := call.Args[0].(string)
+		if title == "" {
+			return result, err
+		}
+
+		if _, err := c.ScrapeTitle(ctx, w, title); err != nil {
+			if errors.Is(err, irowiki.ErrNotFound) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to fetch %q from the wiki: %w", title, err)
+		}
+		return next(ctx)
+	}
+}
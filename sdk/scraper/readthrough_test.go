@@ -0,0 +1,90 @@
+package scraper_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/scraper"
+)
+
+// TestReadThrough tests fetching pages missing from an archive from the wiki
+func TestReadThrough(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		q := r.URL.Query()
+		var resp any
+		switch {
+		case q.Get("prop") == "revisions" && q.Get("titles") == "Poring":
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+				"pageid": 2, "ns": 0, "title": "Poring",
+				"revisions": []any{
+					map[string]any{"revid": 20, "parentid": 0, "timestamp": "2024-01-01T00:00:00Z", "user": "Editor", "slots": map[string]any{"main": map[string]any{"content": "A slime."}}},
+					map[string]any{"revid": 21, "parentid": 20, "timestamp": "2024-01-02T00:00:00Z", "user": "Editor", "slots": map[string]any{"main": map[string]any{"content": "A pink slime monster."}}},
+				},
+			}}}}
+		case q.Get("prop") == "revisions" && q.Get("titles") == "Spam":
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{"ns": 0, "title": "Spam", "missing": true}}}}
+		default:
+			resp = map[string]any{"error": map[string]any{"code": "badrequest", "info": r.URL.RawQuery}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cache.db")
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	s, err := scraper.NewClient(scraper.Options{BaseURL: server.URL, RequestsPerSecond: 1000})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client = irowiki.WithInterceptor(client, s.ReadThrough(w))
+	ctx := context.Background()
+
+	page, err := client.GetPage(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.ID != 2 || page.Content != "A pink slime monster." {
+		t.Errorf("expected the page's latest revision from the wiki, got %+v", page)
+	}
+
+	// The page is now in the archive, with its history
+	if _, err := client.GetPage(ctx, "Poring"); err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request to the wiki, got %d", n)
+	}
+	revs, err := client.GetPageHistory(ctx, "Poring", irowiki.HistoryOptions{})
+	if err != nil || len(revs) != 2 {
+		t.Errorf("expected 2 revisions in the archive, got %d, %v", len(revs), err)
+	}
+
+	if _, err := client.GetPage(ctx, "Spam"); !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a page missing from the wiki, got %v", err)
+	}
+
+	var apiErr *scraper.APIError
+	if _, err := client.GetPage(ctx, "Lunatic"); !errors.As(err, &apiErr) {
+		t.Errorf("expected the wiki's error, got %v", err)
+	}
+}
@@ -0,0 +1,615 @@
+// Package scraper crawls a MediaWiki site through its API and writes what
+// it finds to an irowiki.ArchiveWriter, so an archive can be built without
+// the Python scraper.
+//
+// A crawl lists every page of the configured namespaces (list=allpages),
+// fetches each page's full history (prop=revisions), then lists the site's
+// files (list=allimages):
+//
+//	w, err := irowiki.OpenSQLiteWriter("irowiki.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer w.Close()
+//
+//	s, err := scraper.NewClient(scraper.Options{BaseURL: "https://irowiki.org"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	result, err := s.Scrape(ctx, w)
+//
+// Sync keeps an archive fresh afterwards from the wiki's recent changes,
+// and ReadThrough fills it in lazily as pages are read.
+//
+// Requests are rate-limited and retried with exponential backoff on rate
+// limiting (429), server errors and timeouts. Revisions are immutable, so
+// scraping into an existing archive adds only the new ones.
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// DefaultAPIPath is the path of api.php on MediaWiki sites.
+const DefaultAPIPath = "/w/api.php"
+
+// DefaultNamespaces are the namespaces crawled by default: the standard
+// namespaces from Main (0) to Category talk (15), as the Python scraper.
+var DefaultNamespaces = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// Options configures a Client.
+type Options struct {
+	// BaseURL is the wiki's address, such as "https://irowiki.org". Required.
+	BaseURL string
+
+	// APIPath is the path of api.php under BaseURL. Default: "/w/api.php".
+	APIPath string
+
+	// HTTPClient performs requests. Default: a client with Timeout.
+	HTTPClient *http.Client
+
+	// UserAgent is sent with every request. Default: "iRO-Wiki-Scraper-SDK".
+	UserAgent string
+
+	// Timeout bounds each request when HTTPClient is not set. Default: 30 seconds.
+	Timeout time.Duration
+
+	// RequestsPerSecond limits the request rate. Default: 1.
+	RequestsPerSecond float64
+
+	// MaxRetries is the number of attempts per request. Default: 3.
+	MaxRetries int
+
+	// RetryDelay is the backoff before the first retry, doubling with each
+	// one. Default: 5 seconds.
+	RetryDelay time.Duration
+
+	// Namespaces are the namespaces crawled. Default: DefaultNamespaces.
+	Namespaces []int
+
+	// PageLimit is the number of pages or files listed per request.
+	// Default: 500, the API's maximum.
+	PageLimit int
+
+	// RevisionLimit is the number of revisions fetched per request.
+	// Default: 50, the API's maximum with content.
+	RevisionLimit int
+
+	// SkipFiles skips listing the site's files.
+	SkipFiles bool
+
+	// Progress receives a report after each page (stage "pages") and each
+	// batch of files (stage "files"). Totals are -1. Optional.
+	Progress irowiki.Progress
+}
+
+// Validate checks if the Options are valid.
+func (o *Options) Validate() error {
+	if o.BaseURL == "" {
+		return fmt.Errorf("base_url is required")
+	}
+	u, err := url.Parse(o.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base_url must be an http or https URL, got %q", o.BaseURL)
+	}
+	if o.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests_per_second must be non-negative")
+	}
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must be non-negative")
+	}
+	if o.PageLimit < 0 || o.PageLimit > 500 {
+		return fmt.Errorf("page_limit must be between 1 and 500")
+	}
+	if o.RevisionLimit < 0 || o.RevisionLimit > 50 {
+		return fmt.Errorf("revision_limit must be between 1 and 50")
+	}
+	for _, ns := range o.Namespaces {
+		if ns < 0 {
+			return fmt.Errorf("namespaces must be non-negative, got %d", ns)
+		}
+	}
+	return nil
+}
+
+// SetDefaults applies default values to unset options.
+func (o *Options) SetDefaults() {
+	if o.APIPath == "" {
+		o.APIPath = DefaultAPIPath
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 30 * time.Second
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{Timeout: o.Timeout}
+	}
+	if o.UserAgent == "" {
+		o.UserAgent = "iRO-Wiki-Scraper-SDK"
+	}
+	if o.RequestsPerSecond == 0 {
+		o.RequestsPerSecond = 1
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	}
+	if o.RetryDelay == 0 {
+		o.RetryDelay = 5 * time.Second
+	}
+	if len(o.Namespaces) == 0 {
+		o.Namespaces = DefaultNamespaces
+	}
+	if o.PageLimit == 0 {
+		o.PageLimit = 500
+	}
+	if o.RevisionLimit == 0 {
+		o.RevisionLimit = 50
+	}
+}
+
+// APIError is an error returned by the MediaWiki API.
+type APIError struct {
+	Code string `json:"code"`
+	Info string `json:"info"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %s: %s", e.Code, e.Info)
+}
+
+// Result summarizes a crawl.
+type Result struct {
+	// Pages, Revisions and Files are the numbers written. Revisions
+	// already in the archive are counted too.
+	Pages     int64
+	Revisions int64
+	Files     int64
+
+	// Missing is the number of changed pages Sync found deleted from the
+	// wiki since, which it leaves in the archive.
+	Missing int64
+
+	// Latest is the time of the latest change Sync saw, to pass as since
+	// to the next Sync. It's zero after Scrape, or if nothing changed.
+	Latest time.Time
+}
+
+// Client crawls a MediaWiki site.
+// It is safe for concurrent use by multiple goroutines, which share its
+// rate limit.
+type Client struct {
+	opts     Options
+	endpoint string
+
+	// interval is the minimum time between requests
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+
+	// namespaces is the wiki's namespace table, fetched when first needed
+	nsMu       sync.Mutex
+	namespaces *irowiki.NamespaceTable
+}
+
+// NewClient creates a Client with the given options.
+// Returns irowiki.ErrInvalidInput if the options are invalid.
+func NewClient(opts Options) (*Client, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", irowiki.ErrInvalidInput, err)
+	}
+	opts.SetDefaults()
+	return &Client{
+		opts:     opts,
+		endpoint: strings.TrimSuffix(opts.BaseURL, "/") + "/" + strings.TrimPrefix(opts.APIPath, "/"),
+		interval: time.Duration(float64(time.Second) / opts.RequestsPerSecond),
+	}, nil
+}
+
+// Scrape crawls the site into w. Each page is written with its revisions
+// in one batch, so an interrupted crawl leaves no page half-written; run
+// it again to resume. It returns what was written so far with any error.
+func (c *Client) Scrape(ctx context.Context, w irowiki.ArchiveWriter) (*Result, error) {
+	if w == nil {
+		return nil, fmt.Errorf("%w: writer is required", irowiki.ErrInvalidInput)
+	}
+	result := &Result{}
+
+	for _, ns := range c.opts.Namespaces {
+		err := c.listPages(ctx, ns, func(p apiPage) error {
+			revisions, err := c.ScrapePage(ctx, w, p.PageID)
+			if err != nil {
+				return err
+			}
+			result.Pages++
+			result.Revisions += int64(revisions)
+			if c.opts.Progress != nil {
+				c.opts.Progress.OnProgress(result.Pages, -1, "pages")
+			}
+			return nil
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to scrape namespace %d: %w", ns, err)
+		}
+	}
+
+	if !c.opts.SkipFiles {
+		if err := c.scrapeFiles(ctx, w, time.Time{}, result); err != nil {
+			return result, fmt.Errorf("failed to scrape files: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// ScrapePage fetches a page's history and writes the page with its
+// revisions, oldest first, in one batch. It returns the number of
+// revisions written. Returns irowiki.ErrNotFound if the page doesn't exist.
+func (c *Client) ScrapePage(ctx context.Context, w irowiki.ArchiveWriter, pageID int64) (int, error) {
+	if pageID <= 0 {
+		return 0, fmt.Errorf("%w: page ID must be positive, got %d", irowiki.ErrInvalidInput, pageID)
+	}
+	return c.scrapePage(ctx, w, pageRef{id: pageID}, time.Time{})
+}
+
+// ScrapeTitle fetches the history of the page with the given title and
+// writes it as ScrapePage does. The wiki normalizes the title, so the page
+// is written under its canonical title. Returns irowiki.ErrNotFound if the
+// page doesn't exist.
+func (c *Client) ScrapeTitle(ctx context.Context, w irowiki.ArchiveWriter, title string) (int, error) {
+	if strings.TrimSpace(title) == "" {
+		return 0, fmt.Errorf("%w: title is required", irowiki.ErrInvalidInput)
+	}
+	return c.scrapePage(ctx, w, pageRef{title: title}, time.Time{})
+}
+
+// pageRef identifies a page to fetch by ID or, if the ID is zero, by title.
+type pageRef struct {
+	id    int64
+	title string
+}
+
+func (r pageRef) String() string {
+	if r.id != 0 {
+		return fmt.Sprintf("page %d", r.id)
+	}
+	return fmt.Sprintf("page %q", r.title)
+}
+
+// scrapePage writes a page with its revisions made since the given time,
+// or all of them if it's zero.
+func (c *Client) scrapePage(ctx context.Context, w irowiki.ArchiveWriter, ref pageRef, since time.Time) (int, error) {
+	var page *irowiki.Page
+	var revisions []*irowiki.Revision
+	params := url.Values{
+		"prop":    {"revisions"},
+		"rvprop":  {"ids|timestamp|user|userid|comment|size|sha1|flags|tags|content"},
+		"rvslots": {"main"},
+		"rvlimit": {strconv.Itoa(c.opts.RevisionLimit)},
+		// Oldest first, as the archive's full-text index follows the last
+		// revision written
+		"rvdir": {"newer"},
+	}
+	if ref.id != 0 {
+		params.Set("pageids", strconv.FormatInt(ref.id, 10))
+	} else {
+		params.Set("titles", ref.title)
+	}
+	if !since.IsZero() {
+		params.Set("rvstart", apiTimestamp(since))
+	}
+	err := c.query(ctx, params, func(q *apiQuery) error {
+		if len(q.Pages) == 0 {
+			return nil
+		}
+		p := q.Pages[0]
+		if p.Missing || p.Invalid {
+			return fmt.Errorf("%w: %s", irowiki.ErrNotFound, ref)
+		}
+		if page == nil {
+			title, err := c.archiveTitle(ctx, p.NS, p.Title)
+			if err != nil {
+				return err
+			}
+			page = &irowiki.Page{ID: p.PageID, Namespace: p.NS, Title: title, IsRedirect: p.Redirect}
+		}
+		for _, r := range p.Revisions {
+			rev, err := r.revision(p.PageID)
+			if err != nil {
+				return err
+			}
+			revisions = append(revisions, rev)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if page == nil {
+		return 0, fmt.Errorf("%w: %s", irowiki.ErrNotFound, ref)
+	}
+
+	err = w.Batch(ctx, func(w irowiki.ArchiveWriter) error {
+		if err := w.UpsertPage(ctx, page); err != nil {
+			return err
+		}
+		for _, rev := range revisions {
+			if err := w.InsertRevision(ctx, rev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(revisions), nil
+}
+
+// listPages calls fn for each page of a namespace.
+func (c *Client) listPages(ctx context.Context, ns int, fn func(apiPage) error) error {
+	params := url.Values{
+		"list":        {"allpages"},
+		"apnamespace": {strconv.Itoa(ns)},
+		"aplimit":     {strconv.Itoa(c.opts.PageLimit)},
+	}
+	return c.query(ctx, params, func(q *apiQuery) error {
+		for _, p := range q.AllPages {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// scrapeFiles writes the site's files uploaded since the given time, or
+// all of them if it's zero, a batch per request.
+func (c *Client) scrapeFiles(ctx context.Context, w irowiki.ArchiveWriter, since time.Time, result *Result) error {
+	params := url.Values{
+		"list":    {"allimages"},
+		"aiprop":  {"url|size|sha1|mime|timestamp|user|dimensions"},
+		"ailimit": {strconv.Itoa(c.opts.PageLimit)},
+		"aisort":  {"name"},
+	}
+	if !since.IsZero() {
+		params.Set("aisort", "timestamp")
+		params.Set("aidir", "newer")
+		params.Set("aistart", apiTimestamp(since))
+	}
+	return c.query(ctx, params, func(q *apiQuery) error {
+		files := make([]*irowiki.File, 0, len(q.AllImages))
+		for _, image := range q.AllImages {
+			file, err := image.file()
+			if err != nil {
+				return err
+			}
+			files = append(files, file)
+		}
+		err := w.Batch(ctx, func(w irowiki.ArchiveWriter) error {
+			for _, file := range files {
+				if err := w.InsertFile(ctx, file); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		result.Files += int64(len(files))
+		if c.opts.Progress != nil {
+			c.opts.Progress.OnProgress(result.Files, -1, "files")
+		}
+		return nil
+	})
+}
+
+// query runs an action=query request, calling fn with each batch of
+// results until the API has no more.
+func (c *Client) query(ctx context.Context, params url.Values, fn func(*apiQuery) error) error {
+	var cont map[string]string
+	for {
+		req := url.Values{}
+		for k, v := range params {
+			req[k] = v
+		}
+		for k, v := range cont {
+			req.Set(k, v)
+		}
+
+		var resp apiResponse
+		if err := c.get(ctx, req, &resp); err != nil {
+			return err
+		}
+		if err := fn(&resp.Query); err != nil {
+			return err
+		}
+		if len(resp.Continue) == 0 {
+			return nil
+		}
+
+		cont = make(map[string]string, len(resp.Continue))
+		for k, v := range resp.Continue {
+			var s string
+			if err := json.Unmarshal(v, &s); err != nil {
+				// Numeric continuation values
+				s = string(v)
+			}
+			cont[k] = s
+		}
+	}
+}
+
+// get performs an action=query request, retrying transient failures.
+func (c *Client) get(ctx context.Context, params url.Values, out *apiResponse) error {
+	params.Set("action", "query")
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+	if !params.Has("continue") {
+		// Opts in to the continuation format of MediaWiki 1.21+
+		params.Set("continue", "")
+	}
+	target := c.endpoint + "?" + params.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt < c.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.opts.RetryDelay<<(attempt-1)); err != nil {
+				return err
+			}
+		}
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+
+		retry, err := c.do(ctx, target, out)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("%w: %v after %d attempts", irowiki.ErrConnectionFailed, lastErr, c.opts.MaxRetries)
+}
+
+// do performs a request, reporting whether a failure is worth retrying.
+func (c *Client) do(ctx context.Context, target string, out *apiResponse) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true, err
+		}
+		return false, fmt.Errorf("%w: %v", irowiki.ErrConnectionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		io.Copy(io.Discard, resp.Body)
+		return true, fmt.Errorf("HTTP %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return false, fmt.Errorf("%w: HTTP %d from %s", irowiki.ErrConnectionFailed, resp.StatusCode, c.endpoint)
+	}
+
+	*out = apiResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("invalid API response: %w", err)
+	}
+	if out.Error != nil {
+		if out.Error.Code == "maxlag" || out.Error.Code == "ratelimited" {
+			return true, out.Error
+		}
+		return false, out.Error
+	}
+	return false, nil
+}
+
+// wait blocks until the rate limit allows another request.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	at := c.next
+	if at.Before(now) {
+		at = now
+	}
+	c.next = at.Add(c.interval)
+	c.mu.Unlock()
+
+	return sleep(ctx, time.Until(at))
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// apiTimestamp formats a time for the API.
+func apiTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// archiveTitle returns the title of a page in namespace ns as archives
+// store it: without the namespace prefix, with underscores. The standard
+// namespaces are recognized without asking the wiki.
+func (c *Client) archiveTitle(ctx context.Context, ns int, title string) (string, error) {
+	if ns == irowiki.NamespaceMain {
+		return strings.ReplaceAll(title, " ", "_"), nil
+	}
+	table := irowiki.StandardNamespaces
+	if parsed, _ := table.ParseTitle(title); parsed != ns {
+		var err error
+		if table, err = c.NamespaceTable(ctx); err != nil {
+			return "", err
+		}
+	}
+	_, name := table.ParseTitle(title)
+	return name, nil
+}
+
+// NamespaceTable returns the wiki's namespaces, with its own names and
+// aliases (meta=siteinfo) as well as the canonical ones. It is fetched
+// once and kept for the Client's lifetime.
+func (c *Client) NamespaceTable(ctx context.Context) (*irowiki.NamespaceTable, error) {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+	if c.namespaces != nil {
+		return c.namespaces, nil
+	}
+
+	params := url.Values{
+		"meta":   {"siteinfo"},
+		"siprop": {"namespaces|namespacealiases"},
+	}
+	var resp apiResponse
+	if err := c.get(ctx, params, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch namespaces: %w", err)
+	}
+
+	names := make(map[int]string, len(resp.Query.Namespaces))
+	aliases := make(map[string]int, len(resp.Query.NamespaceAliases))
+	for _, ns := range resp.Query.Namespaces {
+		names[ns.ID] = ns.Name
+		if ns.Canonical != "" {
+			aliases[ns.Canonical] = ns.ID
+		}
+	}
+	for _, alias := range resp.Query.NamespaceAliases {
+		aliases[alias.Alias] = alias.ID
+	}
+	c.namespaces = irowiki.NewNamespaceTable(names, aliases)
+	return c.namespaces, nil
+}
@@ -0,0 +1,293 @@
+package scraper_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/scraper"
+)
+
+// fakeWiki serves a two-page wiki through a MediaWiki API, paging every
+// list one item at a time
+func fakeWiki(t *testing.T, failures int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/w/api.php", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if n <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("action") != "query" || q.Get("formatversion") != "2" {
+			t.Errorf("unexpected request %s", r.URL.RawQuery)
+		}
+
+		var resp any
+		switch {
+		case q.Get("list") == "allpages" && q.Get("apnamespace") != "0":
+			resp = map[string]any{"query": map[string]any{"allpages": []any{}}}
+		case q.Get("list") == "allpages" && q.Get("apcontinue") == "":
+			resp = map[string]any{
+				"continue": map[string]any{"apcontinue": "Poring", "continue": "-||"},
+				"query":    map[string]any{"allpages": []any{map[string]any{"pageid": 1, "ns": 0, "title": "Main Page"}}},
+			}
+		case q.Get("list") == "allpages":
+			resp = map[string]any{"query": map[string]any{"allpages": []any{map[string]any{"pageid": 2, "ns": 0, "title": "Poring"}}}}
+		case q.Get("prop") == "revisions" && q.Get("pageids") == "1":
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+				"pageid": 1, "ns": 0, "title": "Main Page", "redirect": true,
+				"revisions": []any{map[string]any{
+					"revid": 10, "parentid": 0, "timestamp": "2024-01-01T00:00:00Z", "user": "Admin", "userid": 1,
+					"comment": "redirect", "size": 20, "sha1": "abc", "slots": map[string]any{"main": map[string]any{"content": "#REDIRECT [[Poring]]"}},
+				}},
+			}}}}
+		case q.Get("prop") == "revisions" && q.Get("pageids") == "2" && q.Get("rvcontinue") == "":
+			if q.Get("rvdir") != "newer" {
+				t.Errorf("expected revisions oldest first, got rvdir=%q", q.Get("rvdir"))
+			}
+			resp = map[string]any{
+				"continue": map[string]any{"rvcontinue": "20240102000000|21", "continue": "||"},
+				"query": map[string]any{"pages": []any{map[string]any{
+					"pageid": 2, "ns": 0, "title": "Poring",
+					"revisions": []any{map[string]any{
+						"revid": 20, "parentid": 0, "timestamp": "2024-01-01T00:00:00Z", "user": "Editor", "userid": 2,
+						"comment": "created", "minor": false, "tags": []string{}, "slots": map[string]any{"main": map[string]any{"content": "A slime."}},
+					}},
+				}}},
+			}
+		case q.Get("prop") == "revisions" && q.Get("pageids") == "2":
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+				"pageid": 2, "ns": 0, "title": "Poring",
+				"revisions": []any{map[string]any{
+					"revid": 21, "parentid": 20, "timestamp": "2024-01-02T00:00:00Z", "userhidden": true,
+					"comment": "expanded", "minor": true, "tags": []string{"mobile edit"}, "slots": map[string]any{"main": map[string]any{"content": "A pink slime monster."}},
+				}},
+			}}}}
+		case q.Get("list") == "allimages":
+			resp = map[string]any{"query": map[string]any{"allimages": []any{map[string]any{
+				"name": "Poring_card.png", "url": "https://example.org/images/Poring_card.png", "descriptionurl": "https://example.org/wiki/File:Poring_card.png",
+				"sha1": "def", "size": 1024, "width": 75, "height": 100, "mime": "image/png", "timestamp": "2024-01-03T00:00:00Z", "user": "Uploader",
+			}}}}
+		default:
+			resp = map[string]any{"error": map[string]any{"code": "badrequest", "info": "unexpected request"}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// TestScrape tests crawling a wiki into an archive
+func TestScrape(t *testing.T) {
+	server, _ := fakeWiki(t, 1)
+	path := filepath.Join(t.TempDir(), "scraped.db")
+
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+
+	var reports int
+	s, err := scraper.NewClient(scraper.Options{
+		BaseURL:           server.URL,
+		RequestsPerSecond: 1000,
+		RetryDelay:        1,
+		Progress: irowiki.ProgressFunc(func(done, total int64, stage string) {
+			reports++
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	// The first request fails with a server error and is retried
+	result, err := s.Scrape(context.Background(), w)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if result.Pages != 2 || result.Revisions != 3 || result.Files != 1 {
+		t.Errorf("expected 2 pages, 3 revisions and 1 file, got %+v", result)
+	}
+	if reports != 3 {
+		t.Errorf("expected 3 progress reports, got %d", reports)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	page, err := client.GetPage(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.Content != "A pink slime monster." || page.User != "" {
+		t.Errorf("expected the latest revision by a hidden user, got %+v", page)
+	}
+
+	revs, err := client.GetPageHistory(ctx, "Poring", irowiki.HistoryOptions{})
+	if err != nil {
+		t.Fatalf("GetPageHistory failed: %v", err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(revs))
+	}
+	for _, rev := range revs {
+		if rev.ID == 21 && (rev.ParentID == nil || *rev.ParentID != 20 || !rev.Minor || len(rev.Tags) != 1) {
+			t.Errorf("unexpected revision 21: %+v", rev)
+		}
+		if rev.ID == 20 && rev.ParentID != nil {
+			t.Errorf("expected revision 20 to have no parent, got %d", *rev.ParentID)
+		}
+	}
+
+	main, err := client.GetPage(ctx, "Main_Page")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if !main.IsRedirect {
+		t.Errorf("expected Main_Page to be a redirect, got %+v", main)
+	}
+
+	file, err := client.GetFile(ctx, "Poring_card.png")
+	if err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	if file.Size != 1024 || file.Width == nil || *file.Width != 75 || file.Uploader != "Uploader" {
+		t.Errorf("unexpected file %+v", file)
+	}
+}
+
+// TestScrape_APIError tests that API errors end the crawl
+func TestScrape_APIError(t *testing.T) {
+	server, _ := fakeWiki(t, 0)
+	w, err := irowiki.OpenSQLiteWriter(filepath.Join(t.TempDir(), "scraped.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	s, err := scraper.NewClient(scraper.Options{BaseURL: server.URL, RequestsPerSecond: 1000, RetryDelay: 1})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = s.ScrapePage(context.Background(), w, 99)
+	var apiErr *scraper.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "badrequest" {
+		t.Errorf("expected an API error, got %v", err)
+	}
+}
+
+// TestScrape_Retries tests giving up on persistent server errors
+func TestScrape_Retries(t *testing.T) {
+	server, requests := fakeWiki(t, 100)
+	w, err := irowiki.OpenSQLiteWriter(filepath.Join(t.TempDir(), "scraped.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	s, err := scraper.NewClient(scraper.Options{BaseURL: server.URL, RequestsPerSecond: 1000, RetryDelay: 1, MaxRetries: 2})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := s.Scrape(context.Background(), w); !errors.Is(err, irowiki.ErrConnectionFailed) {
+		t.Errorf("expected ErrConnectionFailed, got %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
+
+// TestScrapePage_NamespacePrefix tests storing titles without their
+// namespace prefix, looking up the wiki's own namespaces when needed
+func TestScrapePage_NamespacePrefix(t *testing.T) {
+	var siteinfo int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var resp any
+		switch {
+		case q.Get("meta") == "siteinfo":
+			atomic.AddInt32(&siteinfo, 1)
+			resp = map[string]any{"query": map[string]any{
+				"namespaces": map[string]any{
+					"0":    map[string]any{"id": 0, "name": ""},
+					"6":    map[string]any{"id": 6, "name": "File", "canonical": "File"},
+					"3000": map[string]any{"id": 3000, "name": "Guide"},
+				},
+				"namespacealiases": []any{map[string]any{"id": 6, "alias": "Image"}},
+			}}
+		case q.Get("pageids") == "4":
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{"pageid": 4, "ns": 6, "title": "File:Poring card.png"}}}}
+		case q.Get("pageids") == "5":
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{"pageid": 5, "ns": 3000, "title": "Guide:Leveling"}}}}
+		default:
+			resp = map[string]any{"error": map[string]any{"code": "badrequest", "info": r.URL.RawQuery}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "scraped.db")
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	s, err := scraper.NewClient(scraper.Options{BaseURL: server.URL, RequestsPerSecond: 1000})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	ctx := context.Background()
+	for _, id := range []int64{4, 5} {
+		if _, err := s.ScrapePage(ctx, w, id); err != nil {
+			t.Fatalf("ScrapePage(%d) failed: %v", id, err)
+		}
+	}
+	if n := atomic.LoadInt32(&siteinfo); n != 1 {
+		t.Errorf("expected the namespaces to be fetched once, for the custom namespace, got %d", n)
+	}
+
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+	for _, title := range []string{"Poring_card.png", "Leveling"} {
+		if _, err := client.GetPage(ctx, title); err != nil {
+			t.Errorf("expected %q stored without its prefix, got %v", title, err)
+		}
+	}
+}
+
+// TestNewClient_InvalidOptions tests option validation
+func TestNewClient_InvalidOptions(t *testing.T) {
+	for _, opts := range []scraper.Options{
+		{},
+		{BaseURL: "ftp://example.org"},
+		{BaseURL: "https://example.org", RevisionLimit: 100},
+		{BaseURL: "https://example.org", Namespaces: []int{-1}},
+	} {
+		if _, err := scraper.NewClient(opts); !errors.Is(err, irowiki.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for %+v, got %v", opts, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// Sync brings an archive up to date with the wiki's changes since the given
+// time, without a full crawl. It lists the edits and page creations in the
+// configured namespaces (list=recentchanges), writes each changed page with
+// only its revisions made since, then writes the files uploaded since.
+//
+// MediaWiki keeps recent changes for a limited time (90 days by default),
+// so archives older than that need Scrape. Pass the returned Result.Latest
+// as since to the next Sync; revisions already in the archive are skipped,
+// so overlapping windows are harmless.
+//
+// Example:
+//
+//	info, err := client.GetArchiveInfo(ctx)
+//	// ...
+//	result, err := s.Sync(ctx, w, info.ScrapedAt)
+func (c *Client) Sync(ctx context.Context, w irowiki.ArchiveWriter, since time.Time) (*Result, error) {
+	if w == nil {
+		return nil, fmt.Errorf("%w: writer is required", irowiki.ErrInvalidInput)
+	}
+	if since.IsZero() {
+		return nil, fmt.Errorf("%w: since is required; use Scrape for a full crawl", irowiki.ErrInvalidInput)
+	}
+	result := &Result{}
+
+	pages, err := c.recentChanges(ctx, since, result)
+	if err != nil {
+		return result, fmt.Errorf("failed to list recent changes: %w", err)
+	}
+
+	for _, pageID := range pages {
+		revisions, err := c.scrapePage(ctx, w, pageRef{id: pageID}, since)
+		if errors.Is(err, irowiki.ErrNotFound) {
+			result.Missing++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to sync page %d: %w", pageID, err)
+		}
+		result.Pages++
+		result.Revisions += int64(revisions)
+		if c.opts.Progress != nil {
+			c.opts.Progress.OnProgress(result.Pages+result.Missing, int64(len(pages)), "pages")
+		}
+	}
+
+	if !c.opts.SkipFiles {
+		if err := c.scrapeFiles(ctx, w, since, result); err != nil {
+			return result, fmt.Errorf("failed to sync files: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// recentChanges returns the IDs of the pages edited or created since the
+// given time, in order of their first change, and records the latest
+// change's time in result.
+func (c *Client) recentChanges(ctx context.Context, since time.Time, result *Result) ([]int64, error) {
+	namespaces := make([]string, len(c.opts.Namespaces))
+	for i, ns := range c.opts.Namespaces {
+		namespaces[i] = strconv.Itoa(ns)
+	}
+	params := url.Values{
+		"list":        {"recentchanges"},
+		"rcprop":      {"ids|timestamp"},
+		"rctype":      {"edit|new"},
+		"rcnamespace": {strings.Join(namespaces, "|")},
+		"rcstart":     {apiTimestamp(since)},
+		"rcdir":       {"newer"},
+		"rclimit":     {strconv.Itoa(c.opts.PageLimit)},
+	}
+
+	var pages []int64
+	seen := make(map[int64]bool)
+	err := c.query(ctx, params, func(q *apiQuery) error {
+		for _, change := range q.RecentChanges {
+			if ts, err := time.Parse(time.RFC3339, change.Timestamp); err == nil && ts.After(result.Latest) {
+				result.Latest = ts
+			}
+			if change.PageID <= 0 || seen[change.PageID] {
+				continue
+			}
+			seen[change.PageID] = true
+			pages = append(pages, change.PageID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
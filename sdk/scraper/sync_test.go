@@ -0,0 +1,135 @@
+package scraper_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/scraper"
+)
+
+// TestSync tests appending recent changes to an archive
+func TestSync(t *testing.T) {
+	since := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var resp any
+		switch {
+		case q.Get("list") == "recentchanges":
+			if q.Get("rcstart") != "2024-02-01T00:00:00Z" || q.Get("rcdir") != "newer" {
+				t.Errorf("unexpected recent changes request %s", r.URL.RawQuery)
+			}
+			resp = map[string]any{"query": map[string]any{"recentchanges": []any{
+				map[string]any{"type": "edit", "pageid": 2, "revid": 22, "timestamp": "2024-02-02T00:00:00Z"},
+				map[string]any{"type": "new", "pageid": 3, "revid": 30, "timestamp": "2024-02-03T00:00:00Z"},
+				map[string]any{"type": "edit", "pageid": 2, "revid": 23, "timestamp": "2024-02-04T00:00:00Z"},
+			}}}
+		case q.Get("prop") == "revisions" && q.Get("pageids") == "2":
+			if q.Get("rvstart") != "2024-02-01T00:00:00Z" {
+				t.Errorf("expected only revisions since the last sync, got rvstart=%q", q.Get("rvstart"))
+			}
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+				"pageid": 2, "ns": 0, "title": "Poring",
+				"revisions": []any{
+					map[string]any{"revid": 22, "parentid": 20, "timestamp": "2024-02-02T00:00:00Z", "user": "Editor", "slots": map[string]any{"main": map[string]any{"content": "A pink slime."}}},
+					map[string]any{"revid": 23, "parentid": 22, "timestamp": "2024-02-04T00:00:00Z", "user": "Editor", "slots": map[string]any{"main": map[string]any{"content": "A pink slime monster."}}},
+				},
+			}}}}
+		case q.Get("prop") == "revisions" && q.Get("pageids") == "3":
+			// Deleted since it was created
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{"pageid": 0, "ns": 0, "title": "Spam", "missing": true}}}}
+		case q.Get("list") == "allimages":
+			if q.Get("aisort") != "timestamp" || q.Get("aistart") != "2024-02-01T00:00:00Z" {
+				t.Errorf("expected files uploaded since the last sync, got %s", r.URL.RawQuery)
+			}
+			resp = map[string]any{"query": map[string]any{"allimages": []any{map[string]any{
+				"name": "Poring.gif", "url": "https://example.org/images/Poring.gif", "size": 512, "mime": "image/gif", "timestamp": "2024-02-05T00:00:00Z", "user": "Uploader",
+			}}}}
+		default:
+			resp = map[string]any{"error": map[string]any{"code": "badrequest", "info": r.URL.RawQuery}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "archive.db")
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	ctx := context.Background()
+
+	// The archive as scraped before since
+	if err := w.UpsertPage(ctx, &irowiki.Page{ID: 2, Title: "Poring"}); err != nil {
+		t.Fatalf("UpsertPage failed: %v", err)
+	}
+	if err := w.InsertRevision(ctx, &irowiki.Revision{ID: 20, PageID: 2, Timestamp: since.Add(-time.Hour), Content: "A slime."}); err != nil {
+		t.Fatalf("InsertRevision failed: %v", err)
+	}
+
+	s, err := scraper.NewClient(scraper.Options{BaseURL: server.URL, RequestsPerSecond: 1000})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	result, err := s.Sync(ctx, w, since)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Pages != 1 || result.Revisions != 2 || result.Missing != 1 || result.Files != 1 {
+		t.Errorf("expected 1 page, 2 revisions, 1 missing page and 1 file, got %+v", result)
+	}
+	if want := time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC); !result.Latest.Equal(want) {
+		t.Errorf("expected the latest change at %v, got %v", want, result.Latest)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	page, err := client.GetPage(ctx, "Poring")
+	if err != nil {
+		t.Fatalf("GetPage failed: %v", err)
+	}
+	if page.LatestRevisionID != 23 || page.Content != "A pink slime monster." {
+		t.Errorf("expected the synced revision to be latest, got %+v", page)
+	}
+	revs, err := client.GetPageHistory(ctx, "Poring", irowiki.HistoryOptions{})
+	if err != nil {
+		t.Fatalf("GetPageHistory failed: %v", err)
+	}
+	if len(revs) != 3 {
+		t.Errorf("expected the old revision and 2 new ones, got %d", len(revs))
+	}
+	if _, err := client.GetFile(ctx, "Poring.gif"); err != nil {
+		t.Errorf("expected the uploaded file, got %v", err)
+	}
+}
+
+// TestSync_RequiresSince tests that a zero since is rejected
+func TestSync_RequiresSince(t *testing.T) {
+	w, err := irowiki.OpenSQLiteWriter(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	s, err := scraper.NewClient(scraper.Options{BaseURL: "https://example.org"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := s.Sync(context.Background(), w, time.Time{}); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
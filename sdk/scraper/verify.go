@@ -0,0 +1,241 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// verifyBatch is the number of pages whose latest revisions are fetched
+// per request, the API's maximum for a list of page IDs.
+const verifyBatch = 50
+
+// VerifyResult reports how well a sample of an archive matches the wiki.
+type VerifyResult struct {
+	// SnapshotAt is the archive's scrape time, which the pages were
+	// compared at. It's zero for archives that don't record it, whose
+	// pages were compared with the wiki's latest revisions.
+	SnapshotAt time.Time
+
+	// Sampled is the number of pages compared.
+	Sampled int
+
+	// Matched is the number of pages whose latest archived revision was the
+	// wiki's revision at SnapshotAt.
+	Matched int
+
+	// ChangedSince is the number of sampled pages edited on the wiki after
+	// SnapshotAt. They aren't drift, as the archive predates the edits.
+	ChangedSince int
+
+	// Missing is the number of sampled pages the wiki no longer has. They
+	// aren't counted in DriftPercent, as deletion times aren't known.
+	Missing int
+
+	// Drifted are the pages whose latest archived revision wasn't the
+	// wiki's revision at SnapshotAt.
+	Drifted []Drift
+
+	// DriftPercent is the share of the sampled pages the wiki still has that
+	// drifted, from 0 to 100.
+	DriftPercent float64
+}
+
+// Drift is a page whose archive doesn't match the wiki.
+type Drift struct {
+	PageID int64
+	Title  string
+
+	// ArchiveRevisionID and ArchiveSHA1 identify the latest archived revision.
+	ArchiveRevisionID int64
+	ArchiveSHA1       string
+
+	// LiveRevisionID and LiveSHA1 identify the wiki's revision at the
+	// archive's snapshot time. LiveRevisionID is 0 if the page didn't
+	// exist then.
+	LiveRevisionID int64
+	LiveSHA1       string
+}
+
+// VerifyAgainstLive compares a random sample of the archive's pages in the
+// configured namespaces with the wiki, as a quick check that the archive
+// matches the wiki at the time it's labeled with. A page matches if its
+// latest archived revision has the ID and SHA-1 of the wiki's revision at
+// the archive's scrape time (GetArchiveInfo's ScrapedAt), so later edits
+// to the wiki don't count as drift. Pages known to be deleted are skipped.
+// A sample of 0 or more pages than the archive has checks every page.
+func (c *Client) VerifyAgainstLive(ctx context.Context, archive irowiki.Client, sample int) (*VerifyResult, error) {
+	if archive == nil {
+		return nil, fmt.Errorf("%w: archive is required", irowiki.ErrInvalidInput)
+	}
+	if sample < 0 {
+		return nil, fmt.Errorf("%w: sample must be non-negative", irowiki.ErrInvalidInput)
+	}
+
+	// Archives without metadata are compared with the latest revisions
+	info, err := archive.GetArchiveInfo(ctx)
+	if errors.Is(err, irowiki.ErrNotFound) {
+		info, err = &irowiki.ArchiveInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &VerifyResult{SnapshotAt: info.ScrapedAt}
+
+	pages, err := samplePages(ctx, archive, c.opts.Namespaces, sample)
+	if err != nil {
+		return nil, err
+	}
+	result.Sampled = len(pages)
+
+	for start := 0; start < len(pages); start += verifyBatch {
+		batch := pages[start:min(start+verifyBatch, len(pages))]
+		if err := c.verifyBatch(ctx, archive, batch, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if present := result.Sampled - result.Missing; present > 0 {
+		result.DriftPercent = 100 * float64(len(result.Drifted)) / float64(present)
+	}
+	return result, nil
+}
+
+// samplePages picks n pages of the namespaces at random, or all of them
+// if n is 0, by reservoir sampling as they're listed.
+func samplePages(ctx context.Context, archive irowiki.Client, namespaces []int, n int) ([]irowiki.Page, error) {
+	const pageSize = 500
+	var sampled []irowiki.Page
+	seen := 0
+	for _, ns := range namespaces {
+		for offset := 0; ; offset += pageSize {
+			pages, err := archive.ListPages(ctx, ns, offset, pageSize)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range pages {
+				if p.Deleted || p.LatestRevisionID == 0 {
+					continue
+				}
+				seen++
+				switch {
+				case n == 0 || len(sampled) < n:
+					sampled = append(sampled, p)
+				default:
+					if i := rand.IntN(seen); i < n {
+						sampled[i] = p
+					}
+				}
+			}
+			if len(pages) < pageSize {
+				break
+			}
+		}
+	}
+	return sampled, nil
+}
+
+// verifyBatch compares a batch of pages with their latest revisions on the
+// wiki, looking up the revision at the snapshot time of those edited since.
+func (c *Client) verifyBatch(ctx context.Context, archive irowiki.Client, pages []irowiki.Page, result *VerifyResult) error {
+	ids := make([]int64, len(pages))
+	pageIDs := make([]string, len(pages))
+	for i, p := range pages {
+		ids[i] = p.LatestRevisionID
+		pageIDs[i] = strconv.FormatInt(p.ID, 10)
+	}
+	revs, err := archive.GetRevisionsByID(ctx, ids)
+	if err != nil {
+		return err
+	}
+	sha1s := make(map[int64]string, len(revs))
+	for _, rev := range revs {
+		if rev != nil {
+			sha1s[rev.ID] = rev.SHA1
+		}
+	}
+
+	live := make(map[int64]apiPage, len(pages))
+	params := url.Values{
+		"prop":    {"revisions"},
+		"pageids": {strings.Join(pageIDs, "|")},
+		"rvprop":  {"ids|timestamp|sha1"},
+	}
+	err = c.query(ctx, params, func(q *apiQuery) error {
+		for _, p := range q.Pages {
+			if prev, ok := live[p.PageID]; ok && len(p.Revisions) == 0 {
+				p.Revisions = prev.Revisions
+			}
+			live[p.PageID] = p
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		p, ok := live[page.ID]
+		if !ok || p.Missing || p.Invalid || len(p.Revisions) == 0 {
+			result.Missing++
+			continue
+		}
+		rev := p.Revisions[0]
+
+		if rev.RevID != page.LatestRevisionID && !result.SnapshotAt.IsZero() {
+			ts, err := time.Parse(time.RFC3339, rev.Timestamp)
+			if err != nil {
+				return fmt.Errorf("invalid timestamp %q of revision %d: %w", rev.Timestamp, rev.RevID, err)
+			}
+			if ts.After(result.SnapshotAt) {
+				result.ChangedSince++
+				if rev, err = c.revisionAt(ctx, page.ID, result.SnapshotAt); err != nil {
+					return err
+				}
+			}
+		}
+
+		archived := sha1s[page.LatestRevisionID]
+		if rev.RevID == page.LatestRevisionID && (archived == "" || rev.SHA1 == "" || archived == rev.SHA1) {
+			result.Matched++
+			continue
+		}
+		result.Drifted = append(result.Drifted, Drift{
+			PageID:            page.ID,
+			Title:             page.Title,
+			ArchiveRevisionID: page.LatestRevisionID,
+			ArchiveSHA1:       archived,
+			LiveRevisionID:    rev.RevID,
+			LiveSHA1:          rev.SHA1,
+		})
+	}
+	return nil
+}
+
+// revisionAt returns a page's latest revision made at or before t, or a
+// zero revision if the page didn't exist then.
+func (c *Client) revisionAt(ctx context.Context, pageID int64, t time.Time) (apiRevision, error) {
+	params := url.Values{
+		"prop":    {"revisions"},
+		"pageids": {strconv.FormatInt(pageID, 10)},
+		"rvprop":  {"ids|timestamp|sha1"},
+		"rvstart": {apiTimestamp(t)},
+		"rvdir":   {"older"},
+		"rvlimit": {"1"},
+	}
+	var resp apiResponse
+	if err := c.get(ctx, params, &resp); err != nil {
+		return apiRevision{}, err
+	}
+	if len(resp.Query.Pages) == 0 || len(resp.Query.Pages[0].Revisions) == 0 {
+		return apiRevision{}, nil
+	}
+	return resp.Query.Pages[0].Revisions[0], nil
+}
@@ -0,0 +1,106 @@
+package scraper_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/scraper"
+	_ "modernc.org/sqlite"
+)
+
+// TestVerifyAgainstLive tests comparing an archive with the wiki at its
+// snapshot time
+func TestVerifyAgainstLive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var resp any
+		switch {
+		case q.Get("prop") == "revisions" && q.Get("pageids") == "1|2|3|4":
+			resp = map[string]any{"query": map[string]any{"pages": []any{
+				map[string]any{"pageid": 1, "ns": 0, "title": "Match", "revisions": []any{map[string]any{"revid": 10, "timestamp": "2024-01-01T00:00:00Z", "sha1": "a"}}},
+				map[string]any{"pageid": 2, "ns": 0, "title": "Edited", "revisions": []any{map[string]any{"revid": 22, "timestamp": "2024-03-01T00:00:00Z", "sha1": "c"}}},
+				map[string]any{"pageid": 3, "ns": 0, "title": "Stale", "revisions": []any{map[string]any{"revid": 31, "timestamp": "2024-01-15T00:00:00Z", "sha1": "e"}}},
+				map[string]any{"pageid": 4, "missing": true},
+			}}}
+		case q.Get("prop") == "revisions" && q.Get("pageids") == "2":
+			if q.Get("rvstart") != "2024-02-01T00:00:00Z" || q.Get("rvdir") != "older" {
+				t.Errorf("expected the revision at the snapshot, got %s", r.URL.RawQuery)
+			}
+			resp = map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+				"pageid": 2, "ns": 0, "title": "Edited", "revisions": []any{map[string]any{"revid": 20, "timestamp": "2024-01-01T00:00:00Z", "sha1": "b"}},
+			}}}}
+		default:
+			resp = map[string]any{"error": map[string]any{"code": "badrequest", "info": r.URL.RawQuery}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "archive.db")
+	w, err := irowiki.OpenSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteWriter failed: %v", err)
+	}
+	ctx := context.Background()
+	sha1s := []string{"a", "b", "d", "f"}
+	for i, title := range []string{"Match", "Edited", "Stale", "Gone"} {
+		id := int64(i + 1)
+		if err := w.UpsertPage(ctx, &irowiki.Page{ID: id, Title: title}); err != nil {
+			t.Fatalf("UpsertPage failed: %v", err)
+		}
+		rev := &irowiki.Revision{ID: id * 10, PageID: id, Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Content: title, SHA1: sha1s[i]}
+		if err := w.InsertRevision(ctx, rev); err != nil {
+			t.Fatalf("InsertRevision failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO archive_meta (key, value) VALUES ('scraped_at', '2024-02-01T00:00:00Z')`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("failed to set scraped_at: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer client.Close()
+
+	s, err := scraper.NewClient(scraper.Options{BaseURL: server.URL, RequestsPerSecond: 1000, Namespaces: []int{0}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	result, err := s.VerifyAgainstLive(ctx, client, 0)
+	if err != nil {
+		t.Fatalf("VerifyAgainstLive failed: %v", err)
+	}
+	if result.Sampled != 4 || result.Matched != 2 || result.ChangedSince != 1 || result.Missing != 1 {
+		t.Errorf("expected 4 sampled, 2 matched, 1 changed since and 1 missing, got %+v", result)
+	}
+	if len(result.Drifted) != 1 || result.Drifted[0].Title != "Stale" || result.Drifted[0].LiveRevisionID != 31 {
+		t.Errorf("expected Stale to have drifted, got %+v", result.Drifted)
+	}
+	if result.DriftPercent < 33.3 || result.DriftPercent > 33.4 {
+		t.Errorf("expected a third of the pages to have drifted, got %.2f%%", result.DriftPercent)
+	}
+
+	if _, err := s.VerifyAgainstLive(ctx, client, -1); !errors.Is(err, irowiki.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for a negative sample, got %v", err)
+	}
+}
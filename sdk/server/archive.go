@@ -0,0 +1,305 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// archiveFile is a download served by /archive.
+type archiveFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+	sum     []byte
+
+	// source is the modification time of the archive a sub-archive was
+	// extracted from, to regenerate it after the archive changes.
+	source time.Time
+}
+
+// handleArchive serves the archive file for mirrors, or with namespace,
+// category or title parameters a sub-archive extracted from it. Range
+// requests resume interrupted transfers; ETag and Repr-Digest carry the
+// file's SHA-256 so downloads can be verified.
+func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
+	if s.opts.ArchivePath == "" {
+		s.notFound(w, r, "Archive downloads are not enabled on this server.")
+		return
+	}
+
+	filter, err := parseSubArchiveFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.archiveDownload(r, filter)
+	if err != nil {
+		if errors.Is(err, irowiki.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.serverError(w, r, err)
+		return
+	}
+
+	f, err := os.Open(file.path)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	name := filepath.Base(s.opts.ArchivePath)
+	if filter != nil {
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + "-subset" + filepath.Ext(name)
+	}
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("ETag", `"`+hex.EncodeToString(file.sum)+`"`)
+	w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(file.sum)+":")
+	http.ServeContent(w, r, name, file.modTime, f)
+}
+
+// parseSubArchiveFilter reads a sub-archive selection from query parameters,
+// returning nil when the whole archive is wanted.
+func parseSubArchiveFilter(query url.Values) (*irowiki.SubArchiveFilter, error) {
+	filter := &irowiki.SubArchiveFilter{
+		Categories: query["category"],
+		Titles:     query["title"],
+		SkipFiles:  query.Get("skip_files") == "true",
+	}
+	for _, v := range query["namespace"] {
+		ns, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace %q", v)
+		}
+		filter.Namespaces = append(filter.Namespaces, ns)
+	}
+
+	if len(filter.Namespaces) == 0 && len(filter.Categories) == 0 && len(filter.Titles) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// download is a file served by /archive, being prepared or ready.
+type download struct {
+	// ready is closed once file or err is set
+	ready chan struct{}
+	file  *archiveFile
+	err   error
+
+	// used is when the download was last served, to evict the least
+	// recently used sub-archive
+	used time.Time
+}
+
+// archiveDownload returns the file to serve, checksumming the archive or
+// extracting the sub-archive once per change of the archive file. Requests
+// for the same file share its preparation, which runs under the server's
+// context so that requesters giving up don't fail it for the others.
+func (s *Server) archiveDownload(r *http.Request, filter *irowiki.SubArchiveFilter) (*archiveFile, error) {
+	info, err := os.Stat(s.opts.ArchivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ""
+	if filter != nil {
+		slices.Sort(filter.Namespaces)
+		slices.Sort(filter.Categories)
+		slices.Sort(filter.Titles)
+		key = fmt.Sprintf("%v|%q|%q|%v", filter.Namespaces, filter.Categories, filter.Titles, filter.SkipFiles)
+	}
+
+	s.downloadsMu.Lock()
+	d := s.downloads[key]
+	if d != nil {
+		select {
+		case <-d.ready:
+			if d.err == nil && d.file.current(info, filter != nil) {
+				d.used = time.Now()
+				s.downloadsMu.Unlock()
+				return d.file, nil
+			}
+			s.removeDownload(key)
+			d = nil
+		default:
+		}
+	}
+	if d != nil {
+		s.downloadsMu.Unlock()
+		select {
+		case <-d.ready:
+			return d.file, d.err
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+	}
+
+	d = &download{ready: make(chan struct{}), used: time.Now()}
+	if s.downloads == nil {
+		s.downloads = make(map[string]*download)
+	}
+	s.downloads[key] = d
+	dest := ""
+	if filter != nil {
+		if dest, err = s.subArchivePath(); err != nil {
+			delete(s.downloads, key)
+			s.downloadsMu.Unlock()
+			return nil, err
+		}
+		s.evictSubArchives()
+	}
+	s.downloadsMu.Unlock()
+
+	d.file, d.err = s.prepareDownload(info, filter, dest)
+	close(d.ready)
+	if d.err != nil {
+		s.downloadsMu.Lock()
+		if s.downloads[key] == d {
+			delete(s.downloads, key)
+		}
+		s.downloadsMu.Unlock()
+	}
+	return d.file, d.err
+}
+
+// current reports whether the file was prepared from the archive as it is.
+func (f *archiveFile) current(archive os.FileInfo, extracted bool) bool {
+	if extracted {
+		return f.source.Equal(archive.ModTime())
+	}
+	return f.size == archive.Size() && f.modTime.Equal(archive.ModTime())
+}
+
+// prepareDownload checksums the archive, or extracts the sub-archive
+// selected by filter to dest and checksums that.
+func (s *Server) prepareDownload(info os.FileInfo, filter *irowiki.SubArchiveFilter, dest string) (*archiveFile, error) {
+	file := &archiveFile{path: s.opts.ArchivePath, source: info.ModTime()}
+	if filter != nil {
+		if err := s.extractSubArchive(*filter, dest); err != nil {
+			return nil, err
+		}
+		file.path = dest
+	}
+
+	f, err := os.Open(file.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if file.size, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	if info, err = f.Stat(); err != nil {
+		return nil, err
+	}
+	file.modTime = info.ModTime()
+	file.sum = h.Sum(nil)
+	return file, nil
+}
+
+// subArchivePath returns a new file name in the server's temporary
+// directory. The caller holds downloadsMu.
+func (s *Server) subArchivePath() (string, error) {
+	if s.tempDir == "" {
+		dir, err := os.MkdirTemp("", "irowiki-server-")
+		if err != nil {
+			return "", err
+		}
+		s.tempDir = dir
+	}
+	s.extracts++
+	return filepath.Join(s.tempDir, fmt.Sprintf("subset-%d.db", s.extracts)), nil
+}
+
+// evictSubArchives removes the least recently downloaded sub-archives
+// beyond Options.MaxSubArchives. Those still being extracted are kept.
+// The caller holds downloadsMu.
+func (s *Server) evictSubArchives() {
+	var keys []string
+	for key := range s.downloads {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return s.downloads[a].used.Compare(s.downloads[b].used)
+	})
+	excess := len(keys) - s.opts.MaxSubArchives
+	for _, key := range keys {
+		if excess <= 0 {
+			return
+		}
+		select {
+		case <-s.downloads[key].ready:
+			s.removeDownload(key)
+			excess--
+		default:
+		}
+	}
+}
+
+// removeDownload forgets a prepared download, removing its file if it's
+// a sub-archive. Transfers already serving the file keep it open. The
+// caller holds downloadsMu.
+func (s *Server) removeDownload(key string) {
+	if d := s.downloads[key]; key != "" && d.err == nil {
+		os.Remove(d.file.path)
+	}
+	delete(s.downloads, key)
+}
+
+// extractSubArchive writes the pages selected by filter to dest, reading
+// the archive without write access.
+func (s *Server) extractSubArchive(filter irowiki.SubArchiveFilter, dest string) error {
+	opts := irowiki.DefaultSQLiteOptions()
+	opts.ReadOnly = true
+	store, err := irowiki.OpenSQLiteStoreWithOptions(s.opts.ArchivePath, opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	_, err = store.ExtractSubArchive(s.ctx, dest, filter)
+	return err
+}
+
+// Close cancels the jobs still queued or running, waiting for them to
+// stop, and the sub-archive extracts running for downloads, and removes the
+// sub-archives generated.
+func (s *Server) Close() error {
+	s.cancel()
+	if s.jobs != nil {
+		s.jobs.stop()
+	}
+
+	s.downloadsMu.Lock()
+	defer s.downloadsMu.Unlock()
+
+	s.downloads = nil
+	if s.tempDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(s.tempDir)
+	s.tempDir = ""
+	return err
+}
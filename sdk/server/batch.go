@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxBatchSize bounds the identifiers of one batch request.
+const maxBatchSize = 1000
+
+// maxBatchBody bounds the size of a batch request body in bytes.
+const maxBatchBody = 1 << 20
+
+// pageJSON is a page in batch responses.
+type pageJSON struct {
+	Requested  string    `json:"requested"`
+	Found      bool      `json:"found"`
+	ID         int64     `json:"id,omitempty"`
+	Namespace  int       `json:"namespace,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	IsRedirect bool      `json:"is_redirect,omitempty"`
+	Deleted    bool      `json:"deleted,omitempty"`
+	IsStub     bool      `json:"is_stub,omitempty"`
+	Disambig   bool      `json:"is_disambiguation,omitempty"`
+	RevisionID int64     `json:"revision_id,omitempty"`
+	Timestamp  time.Time `json:"timestamp,omitzero"`
+	User       string    `json:"user,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	Content    string    `json:"content,omitempty"`
+}
+
+// revisionJSON is a revision in batch responses.
+type revisionJSON struct {
+	Requested int64     `json:"requested"`
+	Found     bool      `json:"found"`
+	PageID    int64     `json:"page_id,omitempty"`
+	ParentID  *int64    `json:"parent_id,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	User      string    `json:"user,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Content   string    `json:"content,omitempty"`
+	Size      int       `json:"size,omitempty"`
+	SHA1      string    `json:"sha1,omitempty"`
+	Minor     bool      `json:"minor,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+}
+
+// handlePagesBatchGet resolves {"titles": [...]} to the latest version of
+// each page, in request order.
+func (s *Server) handlePagesBatchGet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Titles []string `json:"titles"`
+	}
+	if !decodeBatch(w, r, &req, func() int { return len(req.Titles) }) {
+		return
+	}
+
+	results, err := s.client.GetPagesByTitle(r.Context(), req.Titles)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	pages := make([]pageJSON, len(results))
+	for i, res := range results {
+		pages[i] = pageJSON{Requested: res.Requested, Found: res.Found()}
+		if p := res.Page; p != nil {
+			pages[i].ID = p.ID
+			pages[i].Namespace = p.Namespace
+			pages[i].Title = p.Title
+			pages[i].IsRedirect = p.IsRedirect
+			pages[i].Deleted = p.Deleted
+			pages[i].IsStub = p.IsStub
+			pages[i].Disambig = p.IsDisambiguation
+			pages[i].RevisionID = p.LatestRevisionID
+			pages[i].Timestamp = p.Timestamp
+			pages[i].User = p.User
+			pages[i].Comment = p.Comment
+			pages[i].Content = p.Content
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pages": pages})
+}
+
+// handleRevisionsBatchGet loads {"ids": [...]} revisions, in request order.
+func (s *Server) handleRevisionsBatchGet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs []int64 `json:"ids"`
+	}
+	if !decodeBatch(w, r, &req, func() int { return len(req.IDs) }) {
+		return
+	}
+
+	results, err := s.client.GetRevisionsByID(r.Context(), req.IDs)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	revisions := make([]revisionJSON, len(results))
+	for i, rev := range results {
+		revisions[i] = revisionJSON{Requested: req.IDs[i], Found: rev != nil}
+		if rev != nil {
+			revisions[i].PageID = rev.PageID
+			revisions[i].ParentID = rev.ParentID
+			revisions[i].Timestamp = rev.Timestamp
+			revisions[i].User = rev.User
+			revisions[i].Comment = rev.Comment
+			revisions[i].Content = rev.Content
+			revisions[i].Size = rev.Size
+			revisions[i].SHA1 = rev.SHA1
+			revisions[i].Minor = rev.Minor
+			revisions[i].Tags = rev.Tags
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revisions": revisions})
+}
+
+// decodeBatch reads a JSON batch request into req, answering 400 for
+// malformed bodies and 413 when size reports more than maxBatchSize entries.
+func decodeBatch(w http.ResponseWriter, r *http.Request, req any, size func() int) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return false
+	}
+	if n := size(); n > maxBatchSize {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("too many identifiers: %d (max %d)", n, maxBatchSize)})
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// maxChangesBacklog bounds the changes replayed to a new stream.
+const maxChangesBacklog = 500
+
+// changeJSON is the data of a change event.
+type changeJSON struct {
+	RevisionID int64     `json:"revision_id"`
+	PageID     int64     `json:"page_id"`
+	Namespace  int       `json:"namespace"`
+	Title      string    `json:"title"`
+	Timestamp  time.Time `json:"timestamp"`
+	User       string    `json:"user"`
+	Comment    string    `json:"comment"`
+	Size       int       `json:"size"`
+	SizeDelta  int       `json:"size_delta"`
+	Minor      bool      `json:"minor"`
+	New        bool      `json:"new"`
+	Bot        bool      `json:"bot"`
+}
+
+// handleChangesStream streams edits as server-sent events while incremental
+// scrapes add them to the archive. Each event's ID is its revision ID, so a
+// reconnecting EventSource resumes from Last-Event-ID without gaps. New
+// streams start from the current edit, after replaying ?backlog= changes.
+// ?exclude_bots=true leaves out bot edits.
+func (s *Server) handleChangesStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	ctx := r.Context()
+	excludeBots := r.URL.Query().Get("exclude_bots") == "true"
+
+	var after int64
+	resume := r.Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = r.URL.Query().Get("after")
+	}
+	if resume != "" {
+		id, err := strconv.ParseInt(resume, 10, 64)
+		if err != nil || id < 0 {
+			http.Error(w, "invalid event ID", http.StatusBadRequest)
+			return
+		}
+		after = id
+	}
+
+	var backlog []irowiki.Change
+	if after == 0 {
+		limit := min(max(queryInt(r, "backlog"), 1), maxChangesBacklog)
+		changes, err := s.client.GetRecentChanges(ctx, irowiki.ChangesOptions{Limit: limit, ExcludeBots: excludeBots})
+		if err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+		if len(changes) > 0 {
+			after = changes[len(changes)-1].RevisionID
+		}
+		if queryInt(r, "backlog") > 0 {
+			backlog = changes
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", s.opts.PollInterval.Milliseconds())
+
+	send := func(changes []irowiki.Change) bool {
+		for _, c := range changes {
+			data, err := json.Marshal(changeJSON(c))
+			if err != nil {
+				s.logError(r, err)
+				return false
+			}
+			fmt.Fprintf(w, "id: %d\nevent: change\ndata: %s\n\n", c.RevisionID, data)
+			after = c.RevisionID
+		}
+		return rc.Flush() == nil
+	}
+	if !send(backlog) {
+		return
+	}
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	idle := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changes, err := s.client.GetRecentChanges(ctx, irowiki.ChangesOptions{AfterRevisionID: after, ExcludeBots: excludeBots})
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logError(r, err)
+			}
+			return
+		}
+		if len(changes) == 0 {
+			// Comments keep proxies from closing a quiet stream
+			if time.Since(idle) < 30*time.Second {
+				continue
+			}
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if !send(changes) {
+			return
+		}
+		idle = time.Now()
+	}
+}
@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/arrowipc"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/render"
+)
+
+// searchData is the data for the search page.
+type searchData struct {
+	Results    []irowiki.SearchResult
+	Suggestion string
+	Offset     int
+	NextOffset int
+	PrevOffset int
+	HasMore    bool
+}
+
+// handleSearch serves the search box and full-text results.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		s.render(w, r, http.StatusOK, time.Time{}, "search", "", searchData{})
+		return
+	}
+
+	offset := queryInt(r, "offset")
+	opts := irowiki.SearchOptions{Query: query, Limit: s.opts.PageSize + 1, Offset: offset}
+
+	results, err := s.client.SearchFullText(r.Context(), query, opts)
+	if err != nil {
+		// Full-text syntax errors shouldn't fail the page; fall back to title search
+		results, err = s.client.Search(r.Context(), opts)
+		if err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+	}
+
+	data := searchData{Results: results, Offset: offset, PrevOffset: max(offset-s.opts.PageSize, 0)}
+	if len(results) > s.opts.PageSize {
+		data.Results = results[:s.opts.PageSize]
+		data.HasMore = true
+		data.NextOffset = offset + s.opts.PageSize
+	}
+
+	if len(results) == 0 && offset == 0 {
+		paged, err := s.client.SearchPaged(r.Context(), irowiki.SearchOptions{Query: query, Limit: 1})
+		if err == nil {
+			data.Suggestion = paged.Suggestion
+		}
+	}
+
+	s.render(w, r, http.StatusOK, time.Time{}, "search", "Search: "+query, data)
+}
+
+// pageViewData is the data for page and revision views.
+type pageViewData struct {
+	Title     string
+	HTML      template.HTML
+	Revision  *irowiki.Revision
+	SourceURL string
+	Latest    bool
+
+	// Badges label stubs, disambiguation pages and protected pages.
+	Badges []string
+
+	// HasCaptured is set when the archive holds the source wiki's own
+	// rendering of the page; Captured is set when that is what HTML shows.
+	HasCaptured bool
+	Captured    *irowiki.PageHTML
+}
+
+// handlePage renders the latest revision of a page.
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	page, err := irowiki.FindPage(r.Context(), s.client, r.PathValue("title"))
+	if errors.Is(err, irowiki.ErrNotFound) {
+		s.notFound(w, r, "page "+displayTitle(r.PathValue("title")))
+		return
+	}
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	captured, err := s.client.GetPageHTML(r.Context(), page.Title)
+	if err != nil && !errors.Is(err, irowiki.ErrNotFound) {
+		s.serverError(w, r, err)
+		return
+	}
+
+	data := pageViewData{
+		Title:  page.Title,
+		Latest: true,
+		Revision: &irowiki.Revision{
+			ID:        page.LatestRevisionID,
+			PageID:    page.ID,
+			Timestamp: page.Timestamp,
+			User:      page.User,
+			Comment:   page.Comment,
+		},
+		Badges:      pageBadges(page),
+		HasCaptured: captured != nil,
+	}
+	if s.info != nil {
+		data.SourceURL = s.info.PageURL(page.Title)
+	}
+
+	modified := page.Timestamp
+	if captured != nil && captured.FetchedAt.After(modified) {
+		modified = captured.FetchedAt
+	}
+
+	if captured != nil && r.URL.Query().Get("view") == "wiki" {
+		// The HTML was produced by another site; don't let scripts in it run here
+		w.Header().Set("Content-Security-Policy", "script-src 'none'")
+		data.HTML = template.HTML(captured.HTML)
+		data.Captured = captured
+	} else {
+		data.HTML, err = s.renderContent(r.Context(), page.Title, page.Content)
+		if err != nil {
+			s.serverError(w, r, err)
+			return
+		}
+	}
+
+	s.render(w, r, http.StatusOK, modified, "page", displayTitle(page.Title), data)
+}
+
+// pageBadges returns the labels shown under a page's title.
+func pageBadges(page *irowiki.Page) []string {
+	var badges []string
+	if page.IsDisambiguation {
+		badges = append(badges, "Disambiguation")
+	}
+	if page.IsStub {
+		badges = append(badges, "Stub")
+	}
+	for _, p := range page.Protection {
+		switch {
+		case p.Action == "edit" && p.Level == "sysop":
+			badges = append(badges, "Protected")
+		case p.Action == "edit" && p.Level == "autoconfirmed":
+			badges = append(badges, "Semi-protected")
+		case p.Action == "edit":
+			badges = append(badges, "Protected ("+p.Level+")")
+		default:
+			badges = append(badges, strings.ToUpper(p.Action[:1])+p.Action[1:]+"-protected")
+		}
+	}
+	return badges
+}
+
+// handleRevision renders an old revision of a page.
+func (s *Server) handleRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.notFound(w, r, "revision "+r.PathValue("id"))
+		return
+	}
+
+	rev, err := s.client.GetRevision(r.Context(), id)
+	if errors.Is(err, irowiki.ErrNotFound) {
+		s.notFound(w, r, "revision "+r.PathValue("id"))
+		return
+	}
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	page, err := s.client.GetPageByID(r.Context(), rev.PageID)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	html, err := s.renderContent(r.Context(), page.Title, rev.Content)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	data := pageViewData{
+		Title:    page.Title,
+		HTML:     html,
+		Revision: rev,
+		Latest:   rev.ID == page.LatestRevisionID,
+	}
+	s.render(w, r, http.StatusOK, rev.Timestamp, "page", displayTitle(page.Title), data)
+}
+
+// historyData is the data for the history page.
+type historyData struct {
+	Title      string
+	Revisions  []irowiki.Revision
+	NextOffset int
+	HasMore    bool
+}
+
+// handleHistory lists a page's revisions, newest first. With ?format=json or
+// ?format=arrow it streams the page's whole history as a JSON array or an
+// Arrow IPC stream instead.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	page, err := irowiki.FindPage(r.Context(), s.client, r.PathValue("title"))
+	if errors.Is(err, irowiki.ErrNotFound) {
+		s.notFound(w, r, "page "+displayTitle(r.PathValue("title")))
+		return
+	}
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format == "json" || format == "arrow" {
+		revisions := irowiki.PageHistoryRevisions(r.Context(), s.client, page.Title, irowiki.HistoryOptions{ExcludeContent: true})
+		// Once streaming has begun the status can't change, so errors only
+		// leave the stream unterminated
+		if format == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			err = irowiki.EncodeRevisionsJSON(w, revisions)
+		} else {
+			w.Header().Set("Content-Type", arrowipc.MediaType)
+			err = arrowipc.EncodeRevisions(w, revisions)
+		}
+		if err != nil && r.Context().Err() == nil {
+			s.logError(r, err)
+		}
+		return
+	}
+
+	offset := queryInt(r, "offset")
+	revisions, err := s.client.GetPageHistory(r.Context(), page.Title, irowiki.HistoryOptions{
+		Offset:         offset,
+		Limit:          s.opts.PageSize + 1,
+		ExcludeContent: true,
+	})
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	data := historyData{Title: page.Title, Revisions: revisions}
+	if len(revisions) > s.opts.PageSize {
+		data.Revisions = revisions[:s.opts.PageSize]
+		data.HasMore = true
+		data.NextOffset = offset + s.opts.PageSize
+	}
+
+	s.render(w, r, http.StatusOK, page.Timestamp, "history", "History: "+displayTitle(page.Title), data)
+}
+
+// diffLine is one line of a unified diff with its display class.
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+// diffData is the data for the diff page.
+type diffData struct {
+	Title string
+	Diff  *irowiki.DiffResult
+	Lines []diffLine
+}
+
+// handleDiff shows the change made by a revision (/diff/{id}) or between
+// two revisions (/diff?from=&to=).
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	var diff *irowiki.DiffResult
+	var err error
+
+	if idParam := r.PathValue("id"); idParam != "" {
+		id, parseErr := strconv.ParseInt(idParam, 10, 64)
+		if parseErr != nil {
+			s.notFound(w, r, "revision "+idParam)
+			return
+		}
+		diff, err = s.client.GetConsecutiveDiff(r.Context(), id)
+	} else {
+		from, fromErr := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		to, toErr := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+		if fromErr != nil || toErr != nil {
+			http.Error(w, "from and to must be revision IDs", http.StatusBadRequest)
+			return
+		}
+		diff, err = s.client.GetRevisionDiff(r.Context(), from, to)
+	}
+
+	switch {
+	case errors.Is(err, irowiki.ErrNotFound):
+		s.notFound(w, r, "revision")
+		return
+	case errors.Is(err, irowiki.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		s.serverError(w, r, err)
+		return
+	}
+
+	data := diffData{Diff: diff, Lines: diffLines(diff.Unified)}
+	var modified time.Time
+	if rev, err := s.client.GetRevision(r.Context(), diff.ToRevision); err == nil {
+		modified = rev.Timestamp
+		if page, err := s.client.GetPageByID(r.Context(), rev.PageID); err == nil {
+			data.Title = page.Title
+		}
+	}
+
+	s.render(w, r, http.StatusOK, modified, "diff", "Diff: "+displayTitle(data.Title), data)
+}
+
+// renderContent renders wikitext, pointing dead external links at archived copies.
+func (s *Server) renderContent(ctx context.Context, title, content string) (template.HTML, error) {
+	archiveURL, err := irowiki.ArchiveURLs(ctx, s.client, title)
+	if err != nil {
+		return "", err
+	}
+
+	html := render.HTML(content, render.Options{ArchiveURL: archiveURL})
+	return template.HTML(html), nil
+}
+
+// diffLines classifies unified diff lines for display.
+func diffLines(unified string) []diffLine {
+	var lines []diffLine
+	for _, text := range strings.Split(strings.TrimRight(unified, "\n"), "\n") {
+		class := "context"
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+			class = "file"
+		case strings.HasPrefix(text, "@@"):
+			class = "hunk"
+		case strings.HasPrefix(text, "+"):
+			class = "add"
+		case strings.HasPrefix(text, "-"):
+			class = "del"
+		}
+		lines = append(lines, diffLine{Class: class, Text: text})
+	}
+	return lines
+}
+
+// queryInt parses a non-negative integer query parameter, defaulting to 0.
+func queryInt(r *http.Request, name string) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
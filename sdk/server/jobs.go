@@ -0,0 +1,379 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// maxFinishedJobs is the number of finished jobs whose status is kept.
+const maxFinishedJobs = 50
+
+// refreshStatsJob is the name of the built-in job caching the quality
+// dashboard.
+const refreshStatsJob = "refresh-stats"
+
+// JobFunc is a maintenance task started through the /jobs endpoints. It
+// reports its progress to progress and should return promptly, with
+// ctx.Err(), once ctx is canceled.
+type JobFunc func(ctx context.Context, progress irowiki.Progress) error
+
+// Job states.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// Job is the status of a job, as returned by the /jobs endpoints.
+type Job struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// State is JobQueued, JobRunning, JobSucceeded, JobFailed or JobCanceled.
+	State string `json:"state"`
+
+	// Stage, Done and Total are the job's latest progress report. Total is
+	// -1 when the job can't tell.
+	Stage string `json:"stage,omitempty"`
+	Done  int64  `json:"done"`
+	Total int64  `json:"total"`
+
+	// Error is why a failed job failed.
+	Error string `json:"error,omitempty"`
+
+	Created  time.Time `json:"created"`
+	Started  time.Time `json:"started,omitzero"`
+	Finished time.Time `json:"finished,omitzero"`
+}
+
+// jobRun is a job started through the API.
+type jobRun struct {
+	status Job
+	cancel context.CancelFunc
+}
+
+// jobRunner runs jobs one at a time, in the order they were started; most
+// take the archive's writer lock, so running them together would only
+// fail them.
+type jobRunner struct {
+	funcs map[string]JobFunc
+
+	// turn is held by the running job
+	turn chan struct{}
+
+	mu      sync.Mutex
+	nextID  int
+	runs    []*jobRun
+	running sync.WaitGroup
+}
+
+// newJobRunner returns a runner for funcs.
+func newJobRunner(funcs map[string]JobFunc) *jobRunner {
+	return &jobRunner{funcs: funcs, turn: make(chan struct{}, 1)}
+}
+
+// names returns the names of the jobs that can be started, sorted.
+func (jr *jobRunner) names() []string {
+	names := make([]string, 0, len(jr.funcs))
+	for name := range jr.funcs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// start queues a run of the job called name.
+func (jr *jobRunner) start(name string) (Job, bool) {
+	fn, ok := jr.funcs[name]
+	if !ok {
+		return Job{}, false
+	}
+
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	jr.nextID++
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &jobRun{
+		status: Job{ID: strconv.Itoa(jr.nextID), Name: name, State: JobQueued, Total: -1, Created: time.Now().UTC()},
+		cancel: cancel,
+	}
+	jr.runs = append(jr.runs, run)
+	jr.prune()
+
+	jr.running.Add(1)
+	go jr.run(ctx, run, fn)
+	return run.status, true
+}
+
+// stop cancels every run and waits for them to finish.
+func (jr *jobRunner) stop() {
+	jr.mu.Lock()
+	for _, run := range jr.runs {
+		run.cancel()
+	}
+	jr.mu.Unlock()
+	jr.running.Wait()
+}
+
+// run waits for its turn, then runs fn and records the outcome.
+func (jr *jobRunner) run(ctx context.Context, run *jobRun, fn JobFunc) {
+	defer jr.running.Done()
+	defer run.cancel()
+
+	select {
+	case jr.turn <- struct{}{}:
+		defer func() { <-jr.turn }()
+	case <-ctx.Done():
+		jr.finish(run, ctx.Err())
+		return
+	}
+
+	jr.update(run, func(j *Job) {
+		j.State = JobRunning
+		j.Started = time.Now().UTC()
+	})
+	err := fn(ctx, irowiki.ProgressFunc(func(done, total int64, stage string) {
+		jr.update(run, func(j *Job) {
+			j.Stage, j.Done, j.Total = stage, done, total
+		})
+	}))
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	jr.finish(run, err)
+}
+
+// finish records the outcome of a run.
+func (jr *jobRunner) finish(run *jobRun, err error) {
+	jr.update(run, func(j *Job) {
+		j.Finished = time.Now().UTC()
+		switch {
+		case err == nil:
+			j.State = JobSucceeded
+		case errors.Is(err, context.Canceled):
+			j.State = JobCanceled
+		default:
+			j.State = JobFailed
+			j.Error = err.Error()
+		}
+	})
+}
+
+// update changes the status of run.
+func (jr *jobRunner) update(run *jobRun, change func(j *Job)) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+	change(&run.status)
+}
+
+// prune forgets the oldest finished runs beyond maxFinishedJobs. jr.mu
+// must be held.
+func (jr *jobRunner) prune() {
+	finished := 0
+	for _, run := range jr.runs {
+		if !run.status.Finished.IsZero() {
+			finished++
+		}
+	}
+	jr.runs = slices.DeleteFunc(jr.runs, func(run *jobRun) bool {
+		if finished > maxFinishedJobs && !run.status.Finished.IsZero() {
+			finished--
+			return true
+		}
+		return false
+	})
+}
+
+// list returns the status of every known run, oldest first.
+func (jr *jobRunner) list() []Job {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	jobs := make([]Job, len(jr.runs))
+	for i, run := range jr.runs {
+		jobs[i] = run.status
+	}
+	return jobs
+}
+
+// get returns the status of the run with id.
+func (jr *jobRunner) get(id string) (Job, bool) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	for _, run := range jr.runs {
+		if run.status.ID == id {
+			return run.status, true
+		}
+	}
+	return Job{}, false
+}
+
+// cancel cancels the run with id. Finished runs are left as they are.
+func (jr *jobRunner) cancel(id string) (Job, bool) {
+	jr.mu.Lock()
+	var found *jobRun
+	for _, run := range jr.runs {
+		if run.status.ID == id {
+			found = run
+		}
+	}
+	jr.mu.Unlock()
+
+	if found == nil {
+		return Job{}, false
+	}
+	found.cancel()
+	return jr.get(id)
+}
+
+// authorizeAdmin reports whether r carries the admin token, answering 401
+// if not.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.AdminToken)) == 1 {
+		return true
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="jobs"`)
+	writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "admin token required"})
+	return false
+}
+
+// handleJobs lists the jobs that can be started and the recent runs.
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"available": s.jobs.names(), "jobs": s.jobs.list()})
+}
+
+// handleStartJob starts {"name": ...}, answering 202 with its status.
+func (s *Server) handleStartJob(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if !decodeBatch(w, r, &req, func() int { return 0 }) {
+		return
+	}
+
+	job, ok := s.jobs.start(req.Name)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown job %q (available: %s)", req.Name, strings.Join(s.jobs.names(), ", "))})
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleJob reports the status of a job.
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	job, ok := s.jobs.get(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such job"})
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleCancelJob cancels a queued or running job.
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	job, ok := s.jobs.cancel(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such job"})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// refreshStats recomputes the quality dashboard's data and caches it.
+func (s *Server) refreshStats(ctx context.Context, progress irowiki.Progress) error {
+	progress.OnProgress(0, 1, "quality")
+	overview, err := s.client.GetQualityOverview(ctx)
+	if err != nil {
+		return err
+	}
+	s.statsMu.Lock()
+	s.quality = overview
+	s.statsMu.Unlock()
+	progress.OnProgress(1, 1, "quality")
+	return nil
+}
+
+// ReindexJob rebuilds the archive's full-text index with opts (see
+// irowiki.Store.RebuildSearchIndex).
+func ReindexJob(store irowiki.Store, opts irowiki.FTSOptions) JobFunc {
+	return func(ctx context.Context, progress irowiki.Progress) error {
+		opts.Progress = progress
+		return store.RebuildSearchIndex(ctx, opts)
+	}
+}
+
+// LinkCheckJob checks the archive's external links with checker (see
+// irowiki.Store.CheckExternalLinks).
+func LinkCheckJob(store irowiki.Store, checker irowiki.LinkChecker, opts irowiki.ExternalLinkOptions) JobFunc {
+	return func(ctx context.Context, progress irowiki.Progress) error {
+		opts.Progress = progress
+		_, err := store.CheckExternalLinks(ctx, checker, opts)
+		return err
+	}
+}
+
+// CommandJob runs a program, for maintenance done outside the SDK such as
+// re-indexing the vector database (scripts/vectorize_wiki.py). Canceling
+// the job kills the program; its output is discarded, except for the end
+// of its stderr, which becomes the error of a failed run.
+func CommandJob(name string, arg ...string) JobFunc {
+	return func(ctx context.Context, progress irowiki.Progress) error {
+		progress.OnProgress(0, -1, "running")
+		cmd := exec.CommandContext(ctx, name, arg...)
+		var stderr tailBuffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if msg := strings.TrimSpace(string(stderr.buf)); msg != "" {
+				return fmt.Errorf("%s: %w: %s", name, err, msg)
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+}
+
+// tailBuffer keeps the last kilobyte written to it.
+type tailBuffer struct {
+	buf []byte
+}
+
+// Write appends p, dropping the oldest bytes beyond a kilobyte.
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > 1024 {
+		b.buf = b.buf[len(b.buf)-1024:]
+	}
+	return len(p), nil
+}
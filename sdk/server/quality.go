@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// qualityData is the data for the quality dashboard.
+type qualityData struct {
+	*irowiki.QualityOverview
+
+	// Peak is the largest histogram bucket, which bars are scaled to.
+	Peak int
+}
+
+// handleQuality serves the maintenance dashboard: page quality scores by
+// namespace, with the worst and best pages.
+func (s *Server) handleQuality(w http.ResponseWriter, r *http.Request) {
+	overview, err := s.qualityOverview(r)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	data := qualityData{QualityOverview: overview}
+	for _, b := range overview.Histogram {
+		data.Peak = max(data.Peak, b.Count)
+	}
+	s.render(w, r, http.StatusOK, time.Time{}, "quality", "Page quality", data)
+}
+
+// handleQualityJSON serves the dashboard's data as JSON.
+func (s *Server) handleQualityJSON(w http.ResponseWriter, r *http.Request) {
+	overview, err := s.qualityOverview(r)
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, overview)
+}
+
+// qualityOverview returns the overview cached by the refresh-stats job,
+// or computes it if the job hasn't run.
+func (s *Server) qualityOverview(r *http.Request) (*irowiki.QualityOverview, error) {
+	s.statsMu.Lock()
+	overview := s.quality
+	s.statsMu.Unlock()
+	if overview != nil {
+		return overview, nil
+	}
+	return s.client.GetQualityOverview(r.Context())
+}
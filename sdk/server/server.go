@@ -0,0 +1,375 @@
+// Package server serves an iRO Wiki archive as a read-only website.
+//
+// The UI (templates and stylesheet) is embedded in the binary, so an archive
+// file is all that is needed to browse it:
+//
+//	client, err := irowiki.OpenSQLite("irowiki.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	srv, err := server.New(client, server.Options{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Fatal(http.ListenAndServe(":8080", srv))
+//
+// Pages: search (/), page view (/wiki/{title}), history (/history/{title},
+// or the whole history as a streamed JSON array or Arrow IPC stream with
+// ?format=json or ?format=arrow),
+// old revisions (/revision/{id}), diffs (/diff/{id}, /diff?from=&to=) and a
+// page quality dashboard for maintainers (/quality, or /quality.json).
+//
+// For frontends rendering many link previews, POST /pages:batchGet with
+// {"titles": [...]} and POST /revisions:batchGet with {"ids": [...]} return
+// JSON for up to 1000 pages or revisions per request, in request order.
+// GET /changes/stream is a server-sent event stream of edits, for live
+// recent changes as incremental scrapes update the archive.
+//
+// With Options.AdminToken set, admins maintain the archive through jobs
+// run in the background, one at a time: GET /jobs lists the jobs and their
+// runs, POST /jobs with {"name": ...} starts one, GET /jobs/{id} polls its
+// status and progress, and DELETE /jobs/{id} cancels it. Requests need an
+// "Authorization: Bearer <token>" header.
+//
+// With Options.ArchivePath set, GET /archive downloads the archive file, or
+// a sub-archive with ?namespace=, ?category= or ?title=, for mirrors; the
+// most recently downloaded sub-archives are kept (see MaxSubArchives). Range
+// requests resume interrupted transfers and the ETag and Repr-Digest headers
+// carry the file's SHA-256.
+//
+// Responses carry an ETag digest of the page and, for revision-based pages,
+// a Last-Modified time, so polling clients revalidate with If-None-Match or
+// If-Modified-Since and get 304 Not Modified until the archive changes.
+//
+// Every request gets an ID, taken from its X-Request-ID header or generated,
+// which is echoed in the response, prefixed to logged errors and set on the
+// context passed to the client (see irowiki.ContextWithRequestID).
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/render"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+//go:embed static
+var staticFS embed.FS
+
+// Options configures the web UI.
+type Options struct {
+	// Title is shown in the header. Default: the archive's wiki name, or "iRO Wiki Archive".
+	Title string
+
+	// PageSize is the number of search results and history entries per page. Default: 50.
+	PageSize int
+
+	// Logger receives errors that are reported to users as a generic 500 page.
+	// Default: the standard logger.
+	Logger *log.Logger
+
+	// PollInterval is how often /changes/stream checks the archive for new
+	// edits. Default: 5 seconds.
+	PollInterval time.Duration
+
+	// ArchivePath is the SQLite file that /archive serves to mirrors, along
+	// with sub-archives extracted from it. Default: "" (downloads disabled).
+	ArchivePath string
+
+	// MaxSubArchives is the number of sub-archives extracted for /archive
+	// downloads that are kept for later requests; the least recently
+	// downloaded is removed to make room. Default: 8.
+	MaxSubArchives int
+
+	// Jobs are the maintenance jobs admins can start through /jobs, by
+	// name; see ReindexJob, LinkCheckJob and CommandJob. A "refresh-stats"
+	// job caching the quality dashboard is always added. The job endpoints
+	// are only served with an AdminToken. Default: none.
+	Jobs map[string]JobFunc
+
+	// AdminToken is the bearer token the /jobs endpoints require.
+	// Default: "" (job endpoints disabled).
+	AdminToken string
+}
+
+// SetDefaults applies default values to unset options.
+func (o *Options) SetDefaults() {
+	if o.Title == "" {
+		o.Title = "iRO Wiki Archive"
+	}
+	if o.PageSize == 0 {
+		o.PageSize = 50
+	}
+	if o.Logger == nil {
+		o.Logger = log.Default()
+	}
+	if o.PollInterval == 0 {
+		o.PollInterval = 5 * time.Second
+	}
+	if o.MaxSubArchives == 0 {
+		o.MaxSubArchives = 8
+	}
+}
+
+// Server is an http.Handler serving the web UI. It only reads from the
+// archive, except through the maintenance jobs it is given.
+type Server struct {
+	client    irowiki.Client
+	opts      Options
+	info      *irowiki.ArchiveInfo
+	templates map[string]*template.Template
+	mux       *http.ServeMux
+
+	// ctx is canceled by Close, ending the work the server does on behalf
+	// of several requests, such as extracting a sub-archive
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// downloadsMu guards the checksummed files served by /archive, keyed
+	// by sub-archive filter ("" for the archive itself)
+	downloadsMu sync.Mutex
+	downloads   map[string]*download
+	tempDir     string
+	extracts    int
+
+	jobs *jobRunner
+
+	// statsMu guards the quality overview cached by the refresh-stats job
+	statsMu sync.Mutex
+	quality *irowiki.QualityOverview
+}
+
+// New creates a Server for client. Archive metadata, if present, is loaded
+// once to provide the site title and source attribution.
+func New(client irowiki.Client, opts Options) (*Server, error) {
+	info, err := client.GetArchiveInfo(context.Background())
+	if err != nil && !errors.Is(err, irowiki.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load archive info: %w", err)
+	}
+	if opts.Title == "" && info != nil {
+		opts.Title = info.WikiName
+	}
+	opts.SetDefaults()
+
+	s := &Server{
+		client: client,
+		opts:   opts,
+		info:   info,
+		mux:    http.NewServeMux(),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	if s.templates, err = parseTemplates(); err != nil {
+		return nil, err
+	}
+
+	static, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.handleSearch)
+	s.mux.HandleFunc("GET /wiki/{title...}", s.handlePage)
+	s.mux.HandleFunc("GET /history/{title...}", s.handleHistory)
+	s.mux.HandleFunc("GET /revision/{id}", s.handleRevision)
+	s.mux.HandleFunc("GET /diff/{id}", s.handleDiff)
+	s.mux.HandleFunc("GET /diff", s.handleDiff)
+	s.mux.HandleFunc("POST /pages:batchGet", s.handlePagesBatchGet)
+	s.mux.HandleFunc("POST /revisions:batchGet", s.handleRevisionsBatchGet)
+	s.mux.HandleFunc("GET /changes/stream", s.handleChangesStream)
+	s.mux.HandleFunc("GET /archive", s.handleArchive)
+	s.mux.HandleFunc("GET /quality", s.handleQuality)
+	s.mux.HandleFunc("GET /quality.json", s.handleQualityJSON)
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+
+	if s.opts.AdminToken != "" {
+		jobs := map[string]JobFunc{refreshStatsJob: s.refreshStats}
+		for name, fn := range s.opts.Jobs {
+			jobs[name] = fn
+		}
+		s.jobs = newJobRunner(jobs)
+		s.mux.HandleFunc("GET /jobs", s.handleJobs)
+		s.mux.HandleFunc("POST /jobs", s.handleStartJob)
+		s.mux.HandleFunc("GET /jobs/{id}", s.handleJob)
+		s.mux.HandleFunc("DELETE /jobs/{id}", s.handleCancelJob)
+	}
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get("X-Request-ID")
+	if !validRequestID(id) {
+		id = rand.Text()
+	}
+	w.Header().Set("X-Request-ID", id)
+	s.mux.ServeHTTP(w, r.WithContext(irowiki.ContextWithRequestID(r.Context(), id)))
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to
+// log and echo: short and printable ASCII.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTemplates pairs each page template with the shared layout.
+func parseTemplates() (map[string]*template.Template, error) {
+	funcs := template.FuncMap{
+		"pageURL":   render.DefaultPageURL,
+		"highlight": highlight,
+		"date":      func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04") },
+		"title":     displayTitle,
+		"inc":       func(n int) int { return n + 1 },
+		"percent": func(n, total int) int {
+			if total == 0 {
+				return 0
+			}
+			return n * 100 / total
+		},
+	}
+
+	pages, err := fs.Glob(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]*template.Template)
+	for _, page := range pages {
+		name := strings.TrimSuffix(strings.TrimPrefix(page, "templates/"), ".html")
+		if name == "layout" {
+			continue
+		}
+		t, err := template.New("layout.html").Funcs(funcs).ParseFS(templateFS, "templates/layout.html", page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+		templates[name] = t
+	}
+	return templates, nil
+}
+
+// pageData is the data passed to every template.
+type pageData struct {
+	SiteTitle string
+	Title     string
+	Query     string
+	Info      *irowiki.ArchiveInfo
+	Data      interface{}
+}
+
+// render executes a page template. Output is buffered so a template error
+// becomes a clean 500 instead of a half-written page. modified is the time of
+// the newest revision shown, or zero when the page has no single source.
+func (s *Server) render(w http.ResponseWriter, r *http.Request, status int, modified time.Time, name, title string, data interface{}) {
+	var buf bytes.Buffer
+	err := s.templates[name].Execute(&buf, pageData{
+		SiteTitle: s.opts.Title,
+		Title:     title,
+		Query:     r.URL.Query().Get("q"),
+		Info:      s.info,
+		Data:      data,
+	})
+	if err != nil {
+		s.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if status == http.StatusOK {
+		// The archive may be updated while the server runs, so clients
+		// revalidate every time instead of caching for a fixed period
+		sum := sha256.Sum256(buf.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		if !modified.IsZero() {
+			w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+		}
+		if notModified(r, etag, modified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// notModified reports whether the client's cached copy is current. As in
+// RFC 9110, If-Modified-Since is only consulted without If-None-Match.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// notFound renders the not-found page.
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request, what string) {
+	s.render(w, r, http.StatusNotFound, time.Time{}, "notfound", "Not found", what)
+}
+
+// serverError logs err and responds with a generic 500.
+func (s *Server) serverError(w http.ResponseWriter, r *http.Request, err error) {
+	s.logError(r, err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// logError logs an error handling r, with the request's ID.
+func (s *Server) logError(r *http.Request, err error) {
+	s.opts.Logger.Printf("server: [%s] %s %s: %v", irowiki.RequestIDFromContext(r.Context()), r.Method, r.URL.Path, err)
+}
+
+// highlight escapes a search snippet while keeping its <mark> tags.
+func highlight(snippet string) template.HTML {
+	escaped := template.HTMLEscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	escaped = strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+	return template.HTML(escaped)
+}
+
+// displayTitle shows a stored title with spaces instead of underscores.
+func displayTitle(title string) string {
+	return strings.ReplaceAll(title, "_", " ")
+}
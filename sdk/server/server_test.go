@@ -0,0 +1,758 @@
+package server_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/internal/testutil"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/server"
+)
+
+// newTestServer serves the fixture archive.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	tdb := testutil.SetupTestDBFile(t)
+	t.Cleanup(func() { tdb.Close() })
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	srv, err := server.New(client, server.Options{})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// get fetches path and returns the status and body.
+func get(t *testing.T, ts *httptest.Server, path string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// TestServer_Pages tests each page of the web UI against the fixture archive
+func TestServer_Pages(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   []string
+	}{
+		{"home", "/", http.StatusOK, []string{`<input type="search" name="q"`}},
+		{"search", "/?q=slime", http.StatusOK, []string{`href="/wiki/Poring"`, "<mark>slime</mark>"}},
+		{"search suggestion", "/?q=porring", http.StatusOK, []string{"Did you mean", `href="/?q=poring"`}},
+		{"page", "/wiki/Poring", http.StatusOK, []string{"<h1>Poring</h1>", "pink slime monster", `href="/history/Poring"`}},
+		{"page with spaces", "/wiki/Main%20Page", http.StatusOK, []string{"<h1>Main Page</h1>", "Welcome to the iRO wiki!"}},
+		{"history", "/history/Prontera", http.StatusOK, []string{`href="/revision/103"`, `href="/diff/103"`, "Minor typo fix"}},
+		{"history data", "/history/Prontera?format=json", http.StatusOK, []string{"[\n  {\"id\":103,\"page_id\":2,\"parent_id\":102,", `"comment":"Created Prontera page"`}},
+		{"history arrow stream", "/history/Prontera?format=arrow", http.StatusOK, []string{"\xff\xff\xff\xff", "page_id", "Created Prontera page"}},
+		{"old revision", "/revision/102", http.StatusOK, []string{"Old revision", "Prontera is the capital city."}},
+		{"consecutive diff", "/diff/103", http.StatusOK, []string{`<span class="del">-Prontera is the capital city.</span>`}},
+		{"diff between revisions", "/diff?from=102&to=103", http.StatusOK, []string{"Changes to Prontera"}},
+		{"quality dashboard", "/quality", http.StatusOK, []string{"4 articles scored", `href="/wiki/Poring"`, `href="/quality.json"`}},
+		{"quality data", "/quality.json", http.StatusOK, []string{`"page_count":4`, `"worst":[`}},
+		{"stylesheet", "/static/style.css", http.StatusOK, []string{"pre.diff"}},
+		{"missing page", "/wiki/Nonexistent", http.StatusNotFound, []string{"page Nonexistent is not in this archive"}},
+		{"missing revision", "/revision/999", http.StatusNotFound, nil},
+		{"bad diff", "/diff?from=x", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, ts, tt.path)
+			if status != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, status, body)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected body to contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+// TestServer_EscapesContent tests that page content cannot inject markup
+func TestServer_EscapesContent(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, user_id, comment, content, size, sha1, minor)
+		VALUES (200, 3, 104, '2020-02-01 00:00:00', '<b>Mallory</b>', 9, 'x', 'Poring <script>alert(1)</script>', 30, 'x', 0)`)
+	if err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	srv, err := server.New(client, server.Options{})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	for _, path := range []string{"/wiki/Poring", "/?q=alert"} {
+		_, body := get(t, ts, path)
+		if strings.Contains(body, "<script>") || strings.Contains(body, "<b>Mallory</b>") {
+			t.Errorf("%s: unescaped content in page", path)
+		}
+	}
+}
+
+// TestServer_CapturedHTML tests the view of HTML rendered by the source wiki
+func TestServer_CapturedHTML(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	_, err := tdb.DB.Exec(`CREATE TABLE page_html (page_id INTEGER PRIMARY KEY, revision_id INTEGER NOT NULL, html TEXT NOT NULL, fetched_at TIMESTAMP)`)
+	if err != nil {
+		t.Fatalf("failed to create page_html: %v", err)
+	}
+	_, err = tdb.DB.Exec(`INSERT INTO page_html VALUES (3, 104, '<div class="infobox">Poring (wiki)</div>', '2024-01-15 10:30:00')`)
+	if err != nil {
+		t.Fatalf("failed to insert page_html: %v", err)
+	}
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	defer client.Close()
+
+	srv, err := server.New(client, server.Options{})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// Test: The archive rendering links to the captured one
+	_, body := get(t, ts, "/wiki/Poring")
+	if !strings.Contains(body, `href="/wiki/Poring?view=wiki"`) || !strings.Contains(body, "pink slime monster") {
+		t.Errorf("expected archive rendering with a link to the wiki rendering")
+	}
+
+	// Test: Pages without captured HTML have no link
+	_, body = get(t, ts, "/wiki/Prontera")
+	if strings.Contains(body, "?view=wiki") {
+		t.Errorf("expected no wiki rendering link for Prontera")
+	}
+
+	// Test: The captured HTML is served as-is, with scripts disabled
+	resp, err := http.Get(ts.URL + "/wiki/Poring?view=wiki")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(raw), `<div class="infobox">Poring (wiki)</div>`) || !strings.Contains(string(raw), "Rendered by the source wiki") {
+		t.Errorf("expected captured HTML in page: %s", raw)
+	}
+	if csp := resp.Header.Get("Content-Security-Policy"); csp != "script-src 'none'" {
+		t.Errorf("expected scripts to be disabled, got CSP %q", csp)
+	}
+}
+
+// TestServer_ConditionalRequests tests revalidation with ETag and Last-Modified
+func TestServer_ConditionalRequests(t *testing.T) {
+	ts := newTestServer(t)
+
+	conditional := func(path, header, value string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	for _, path := range []string{"/wiki/Poring", "/?q=slime"} {
+		// Test: Pages carry a validator and must be revalidated
+		resp := conditional(path, "", "")
+		etag := resp.Header.Get("ETag")
+		if etag == "" || resp.Header.Get("Cache-Control") != "no-cache" {
+			t.Fatalf("%s: expected ETag and no-cache, got %v", path, resp.Header)
+		}
+
+		// Test: A matching If-None-Match is answered without a body
+		if resp := conditional(path, "If-None-Match", etag); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("%s: expected 304, got %d", path, resp.StatusCode)
+		}
+		if resp := conditional(path, "If-None-Match", `"stale"`); resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected 200 for a stale ETag, got %d", path, resp.StatusCode)
+		}
+	}
+
+	// Test: Revision pages are dated by the revision
+	resp := conditional("/revision/102", "", "")
+	modified := resp.Header.Get("Last-Modified")
+	if modified == "" {
+		t.Fatal("expected Last-Modified on a revision")
+	}
+	if resp := conditional("/revision/102", "If-Modified-Since", modified); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 for If-Modified-Since, got %d", resp.StatusCode)
+	}
+	if resp := conditional("/revision/102", "If-Modified-Since", "Mon, 01 Jan 2001 00:00:00 GMT"); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for an older copy, got %d", resp.StatusCode)
+	}
+
+	// Test: Search results have no single modification time
+	if resp := conditional("/?q=slime", "", ""); resp.Header.Get("Last-Modified") != "" {
+		t.Errorf("expected no Last-Modified on search results")
+	}
+}
+
+// TestServer_BatchGet tests the JSON batch endpoints for pages and revisions
+func TestServer_BatchGet(t *testing.T) {
+	ts := newTestServer(t)
+
+	post := func(path, body string) (int, map[string][]map[string]any) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		var out map[string][]map[string]any
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return resp.StatusCode, out
+	}
+
+	// Test: Pages resolve in request order, normalized, with misses marked
+	status, out := post("/pages:batchGet", `{"titles": ["poring", "Nonexistent", "Prontera"]}`)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	pages := out["pages"]
+	if len(pages) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(pages))
+	}
+	if pages[0]["title"] != "Poring" || pages[0]["requested"] != "poring" || pages[0]["found"] != true {
+		t.Errorf("unexpected first page: %v", pages[0])
+	}
+	if pages[1]["found"] != false || pages[1]["title"] != nil {
+		t.Errorf("expected a miss, got %v", pages[1])
+	}
+	if pages[2]["revision_id"] != float64(103) {
+		t.Errorf("expected latest revision 103, got %v", pages[2]["revision_id"])
+	}
+
+	// Test: Revisions load by ID in request order
+	status, out = post("/revisions:batchGet", `{"ids": [102, 999, 104]}`)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	revisions := out["revisions"]
+	if len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revisions))
+	}
+	if revisions[0]["requested"] != float64(102) || revisions[0]["page_id"] != float64(2) {
+		t.Errorf("unexpected first revision: %v", revisions[0])
+	}
+	if revisions[1]["found"] != false {
+		t.Errorf("expected a miss, got %v", revisions[1])
+	}
+
+	// Test: Malformed and oversized requests are rejected
+	if status, _ := post("/revisions:batchGet", `{"ids": ["102"]}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for string IDs, got %d", status)
+	}
+	if status, _ := post("/pages:batchGet", `{"title": ["Poring"]}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown field, got %d", status)
+	}
+	many := strings.Repeat(`"Poring",`, 1000) + `"Poring"`
+	if status, _ := post("/pages:batchGet", `{"titles": [`+many+`]}`); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for too many titles, got %d", status)
+	}
+
+	// Test: Batch endpoints only accept POST
+	if status, _ := get(t, ts, "/pages:batchGet"); status != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", status)
+	}
+}
+
+// TestServer_ChangesStream tests following new edits as server-sent events
+func TestServer_ChangesStream(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	t.Cleanup(func() { tdb.Close() })
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	srv, err := server.New(client, server.Options{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	// Cleanups run in reverse, so open streams are cancelled before closing
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	// stream opens the feed and returns a function reading the next event's ID
+	stream := func(path, lastEventID string) func() string {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		t.Cleanup(cancel)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+path, nil)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("expected an event stream, got %q", ct)
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		return func() string {
+			t.Helper()
+			for scanner.Scan() {
+				if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+					return id
+				}
+			}
+			t.Fatalf("stream ended: %v", scanner.Err())
+			return ""
+		}
+	}
+
+	// Test: New streams replay the requested backlog
+	next := stream("/changes/stream?backlog=1", "")
+	if id := next(); id != "106" {
+		t.Errorf("expected backlog event 106, got %s", id)
+	}
+
+	// Test: Edits added to the archive are pushed
+	_, err = tdb.DB.Exec(`INSERT INTO revisions (revision_id, page_id, parent_id, timestamp, user, comment, content, size, sha1)
+		VALUES (107, 3, 104, '2020-01-08 00:00:00', 'Editor', 'Drops', 'Poring drops Jellopy.', 21, 'vwx234')`)
+	if err != nil {
+		t.Fatalf("failed to add revision: %v", err)
+	}
+	if id := next(); id != "107" {
+		t.Errorf("expected event 107, got %s", id)
+	}
+
+	// Test: Reconnecting clients resume after their last event
+	next = stream("/changes/stream", "105")
+	for _, want := range []string{"106", "107"} {
+		if id := next(); id != want {
+			t.Errorf("expected event %s, got %s", want, id)
+		}
+	}
+
+	// Test: Invalid event IDs are rejected
+	if status, _ := get(t, ts, "/changes/stream?after=abc"); status != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", status)
+	}
+}
+
+// TestServer_ArchiveDownload tests serving the archive and sub-archives to mirrors
+func TestServer_ArchiveDownload(t *testing.T) {
+	// Test: Downloads are disabled by default
+	if status, _ := get(t, newTestServer(t), "/archive"); status != http.StatusNotFound {
+		t.Errorf("expected 404 without ArchivePath, got %d", status)
+	}
+
+	tdb := testutil.SetupTestDBFile(t)
+	t.Cleanup(func() { tdb.Close() })
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	srv, err := server.New(client, server.Options{ArchivePath: tdb.Path})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	want, err := os.ReadFile(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	// Test: The archive is served with its checksum
+	status, body := get(t, ts, "/archive")
+	if status != http.StatusOK || body != string(want) {
+		t.Fatalf("expected the archive file, got %d (%d bytes)", status, len(body))
+	}
+	sum := sha256.Sum256(want)
+	resp, err := http.Head(ts.URL + "/archive")
+	if err != nil {
+		t.Fatalf("HEAD failed: %v", err)
+	}
+	resp.Body.Close()
+	if digest := resp.Header.Get("Repr-Digest"); digest != "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":" {
+		t.Errorf("unexpected Repr-Digest %q", digest)
+	}
+	etag := resp.Header.Get("ETag")
+
+	// Test: Range requests resume a transfer while the file is unchanged
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/archive", nil)
+	req.Header.Set("Range", "bytes=100-")
+	req.Header.Set("If-Range", etag)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	rest, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || !bytes.Equal(rest, want[100:]) {
+		t.Errorf("expected the rest of the file, got %d (%d bytes)", resp.StatusCode, len(rest))
+	}
+
+	// Test: Sub-archives are extracted and downloadable
+	status, body = get(t, ts, "/archive?title=Poring")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	subset := filepath.Join(t.TempDir(), "subset.db")
+	if err := os.WriteFile(subset, []byte(body), 0o600); err != nil {
+		t.Fatalf("failed to write sub-archive: %v", err)
+	}
+	sub, err := irowiki.OpenSQLite(subset)
+	if err != nil {
+		t.Fatalf("failed to open sub-archive: %v", err)
+	}
+	defer sub.Close()
+	pages, err := sub.ListPages(context.Background(), 0, 0, 0)
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Title != "Poring" {
+		t.Errorf("expected only Poring, got %v", pages)
+	}
+
+	// Test: Invalid filters are rejected
+	if status, _ := get(t, ts, "/archive?namespace=main"); status != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", status)
+	}
+}
+
+// TestServer_ArchiveDownloadCache tests that concurrent requests for a
+// sub-archive share one extract and that only MaxSubArchives are kept
+func TestServer_ArchiveDownloadCache(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	tdb := testutil.SetupTestDBFile(t)
+	t.Cleanup(func() { tdb.Close() })
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	srv, err := server.New(client, server.Options{ArchivePath: tdb.Path, MaxSubArchives: 2})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	extracts := func() []string {
+		files, _ := filepath.Glob(filepath.Join(tmp, "irowiki-server-*", "subset-*.db"))
+		return files
+	}
+
+	// Test: Concurrent requests for a sub-archive get the same extract
+	var wg sync.WaitGroup
+	bodies := make([]string, 4)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Get(ts.URL + "/archive?title=Poring")
+			if err != nil {
+				t.Errorf("GET failed: %v", err)
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("expected 200, got %d: %s", resp.StatusCode, body)
+			}
+			bodies[i] = string(body)
+		}(i)
+	}
+	wg.Wait()
+	for _, body := range bodies[1:] {
+		if body != bodies[0] {
+			t.Errorf("expected the same sub-archive for each request")
+		}
+	}
+	if files := extracts(); len(files) != 1 {
+		t.Errorf("expected 1 extract, got %v", files)
+	}
+
+	// Test: The least recently downloaded sub-archive makes room
+	for _, query := range []string{"title=Prontera", "title=Poring", "namespace=0"} {
+		if status, body := get(t, ts, "/archive?"+query); status != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d: %s", query, status, body)
+		}
+	}
+	files := extracts()
+	if len(files) != 2 {
+		t.Fatalf("expected 2 extracts kept, got %v", files)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "subset-2.db") {
+			t.Errorf("expected the Prontera extract to be removed, got %v", files)
+		}
+	}
+
+	// Test: Evicted sub-archives are extracted again
+	if status, _ := get(t, ts, "/archive?title=Prontera"); status != http.StatusOK {
+		t.Errorf("expected 200, got %d", status)
+	}
+	if files := extracts(); len(files) != 2 {
+		t.Errorf("expected 2 extracts kept, got %v", files)
+	}
+}
+
+// TestServer_RequestID tests that request IDs reach the client, the response and the error log
+func TestServer_RequestID(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	defer tdb.Close()
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+
+	var seen []string
+	traced := irowiki.WithInterceptor(client, func(ctx context.Context, call irowiki.Call, next irowiki.Invoker) (any, error) {
+		seen = append(seen, irowiki.RequestIDFromContext(ctx))
+		return next(ctx)
+	})
+
+	var logs bytes.Buffer
+	srv, err := server.New(traced, server.Options{Logger: log.New(&logs, "", 0)})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	fetch := func(id string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/wiki/Poring", nil)
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// Test: A client-supplied ID is used for the client calls and echoed
+	seen = nil
+	if got := fetch("abc-123").Header.Get("X-Request-ID"); got != "abc-123" {
+		t.Errorf("expected X-Request-ID abc-123, got %q", got)
+	}
+	if len(seen) == 0 || seen[0] != "abc-123" {
+		t.Errorf("expected client calls with request ID abc-123, got %v", seen)
+	}
+
+	// Test: Missing or unsafe IDs are replaced with generated ones
+	for _, id := range []string{"", "two words", strings.Repeat("x", 200)} {
+		got := fetch(id).Header.Get("X-Request-ID")
+		if got == "" || got == id {
+			t.Errorf("%q: expected a generated ID, got %q", id, got)
+		}
+	}
+
+	// Test: Logged errors carry the request ID
+	client.Close()
+	if resp := fetch("req-500"); resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(logs.String(), "[req-500] GET /wiki/Poring") {
+		t.Errorf("expected the request ID in the log, got %q", logs.String())
+	}
+}
+
+// TestServer_Jobs tests starting, polling and canceling maintenance jobs
+func TestServer_Jobs(t *testing.T) {
+	tdb := testutil.SetupTestDBFile(t)
+	t.Cleanup(func() { tdb.Close() })
+
+	client, err := irowiki.OpenSQLite(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	store, err := irowiki.OpenSQLiteStore(tdb.Path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	srv, err := server.New(client, server.Options{
+		AdminToken: "secret",
+		Jobs: map[string]server.JobFunc{
+			"reindex": server.ReindexJob(store, irowiki.FTSOptions{Tokenizer: irowiki.TokenizerTrigram}),
+			"broken":  server.CommandJob("false"),
+			"forever": func(ctx context.Context, progress irowiki.Progress) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("server.New failed: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	do := func(method, path, body string) (int, server.Job) {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+
+		var job server.Job
+		json.NewDecoder(resp.Body).Decode(&job)
+		return resp.StatusCode, job
+	}
+	wait := func(id string) server.Job {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			_, job := do("GET", "/jobs/"+id, "")
+			if !job.Finished.IsZero() || time.Now().After(deadline) {
+				return job
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Test: Job endpoints require the admin token
+	if status, _ := get(t, ts, "/jobs"); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", status)
+	}
+
+	// Test: A job runs in the background and reports its progress
+	status, job := do("POST", "/jobs", `{"name": "reindex"}`)
+	if status != http.StatusAccepted || job.ID == "" {
+		t.Fatalf("expected 202 with a job ID, got %d %+v", status, job)
+	}
+	job = wait(job.ID)
+	if job.State != server.JobSucceeded || job.Stage != "reindex" || job.Done != 5 || job.Total != 5 {
+		t.Errorf("expected a finished reindex of 5 pages, got %+v", job)
+	}
+	results, err := client.SearchFullText(context.Background(), "ronter", irowiki.SearchOptions{})
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected the trigram index to find Prontera, got %v, %v", results, err)
+	}
+
+	// Test: Failures are reported with their error
+	_, job = do("POST", "/jobs", `{"name": "broken"}`)
+	if job = wait(job.ID); job.State != server.JobFailed || job.Error == "" {
+		t.Errorf("expected a failed job with an error, got %+v", job)
+	}
+
+	// Test: Running jobs can be canceled
+	_, job = do("POST", "/jobs", `{"name": "forever"}`)
+	if status, _ := do("DELETE", "/jobs/"+job.ID, ""); status != http.StatusAccepted {
+		t.Errorf("expected 202 canceling, got %d", status)
+	}
+	if job = wait(job.ID); job.State != server.JobCanceled {
+		t.Errorf("expected a canceled job, got %+v", job)
+	}
+
+	// Test: The stats cache can be refreshed, and unknown jobs are rejected
+	_, job = do("POST", "/jobs", `{"name": "refresh-stats"}`)
+	if job = wait(job.ID); job.State != server.JobSucceeded {
+		t.Errorf("expected refresh-stats to succeed, got %+v", job)
+	}
+	if status, _ := do("POST", "/jobs", `{"name": "nope"}`); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown job, got %d", status)
+	}
+	if status, _ := do("GET", "/jobs/999", ""); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown job ID, got %d", status)
+	}
+
+	// Test: Servers without an admin token don't serve jobs
+	if status, _ := get(t, newTestServer(t), "/jobs"); status != http.StatusNotFound {
+		t.Errorf("expected 404 without an admin token, got %d", status)
+	}
+}
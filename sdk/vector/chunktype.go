@@ -0,0 +1,101 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownChunkType is returned for chunk types the indexer doesn't write
+var ErrUnknownChunkType = errors.New("unknown chunk type")
+
+// ChunkType is the kind of a chunk. The values match the indexer's
+// ChunkType enum (scripts/vectorize_wiki.py), so filters match what was
+// indexed.
+type ChunkType string
+
+const (
+	// ChunkPage is a whole page
+	ChunkPage ChunkType = "page"
+	// ChunkSection is a section under a heading
+	ChunkSection ChunkType = "section"
+	// ChunkParagraph is a paragraph
+	ChunkParagraph ChunkType = "paragraph"
+	// ChunkWindow is a fixed window of words
+	ChunkWindow ChunkType = "window"
+	// ChunkTable is rows of a wikitable
+	ChunkTable ChunkType = "table"
+	// ChunkSummary is a page's introduction, before its first heading
+	ChunkSummary ChunkType = "summary"
+	// ChunkInfobox is the parameters of an infobox template
+	ChunkInfobox ChunkType = "infobox"
+)
+
+// chunkTypes lists the valid chunk types
+var chunkTypes = []ChunkType{
+	ChunkPage, ChunkSection, ChunkParagraph, ChunkWindow, ChunkTable, ChunkSummary, ChunkInfobox,
+}
+
+// ChunkTypes returns the valid chunk types
+func ChunkTypes() []ChunkType {
+	return append([]ChunkType(nil), chunkTypes...)
+}
+
+// Valid reports whether t is a chunk type the indexer writes
+func (t ChunkType) Valid() bool {
+	for _, valid := range chunkTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseChunkType parses a chunk type, rejecting unknown ones with
+// ErrUnknownChunkType
+func ParseChunkType(s string) (ChunkType, error) {
+	t := ChunkType(s)
+	if !t.Valid() {
+		names := make([]string, len(chunkTypes))
+		for i, valid := range chunkTypes {
+			names[i] = string(valid)
+		}
+		return "", fmt.Errorf("%w %q (expected one of: %s)", ErrUnknownChunkType, s, strings.Join(names, ", "))
+	}
+	return t, nil
+}
+
+// Filter restricts searches to chunks matching all of its set fields
+type Filter struct {
+	// ChunkTypes matches chunks of any of these types
+	ChunkTypes []ChunkType
+
+	// Namespaces matches chunks of pages in any of these namespaces
+	Namespaces []int
+}
+
+// Validate rejects filters on unknown chunk types, which would silently
+// match nothing
+func (f Filter) Validate() error {
+	for _, t := range f.ChunkTypes {
+		if _, err := ParseChunkType(string(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// qdrantFilter returns the filter in Qdrant's syntax, or nil if it's empty
+func (f Filter) qdrantFilter() map[string]any {
+	var must []map[string]any
+	if len(f.ChunkTypes) > 0 {
+		must = append(must, map[string]any{"key": "chunk_type", "match": map[string]any{"any": f.ChunkTypes}})
+	}
+	if len(f.Namespaces) > 0 {
+		must = append(must, map[string]any{"key": "namespace", "match": map[string]any{"any": f.Namespaces}})
+	}
+	if must == nil {
+		return nil
+	}
+	return map[string]any{"must": must}
+}
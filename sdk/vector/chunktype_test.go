@@ -0,0 +1,62 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseChunkType(t *testing.T) {
+	for _, want := range ChunkTypes() {
+		got, err := ParseChunkType(string(want))
+		if err != nil || got != want {
+			t.Errorf("ParseChunkType(%q) = %q, %v", want, got, err)
+		}
+	}
+	if _, err := ParseChunkType("sections"); !errors.Is(err, ErrUnknownChunkType) {
+		t.Errorf("expected ErrUnknownChunkType, got %v", err)
+	}
+}
+
+func TestSearchFiltered(t *testing.T) {
+	var filter json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Searches []struct {
+				Filter json.RawMessage `json:"filter"`
+			} `json:"searches"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		filter = body.Searches[0].Filter
+		w.Write([]byte(`{"result": [[{"score": 0.7, "payload": {"page_title": "Poring", "chunk_type": "table"}}]], "status": "ok"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewVectorClient(Config{URL: server.URL, Embedder: &fakeEmbedder{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	results, err := client.SearchFiltered(ctx, []string{"poring drops"}, 5, Filter{
+		ChunkTypes: []ChunkType{ChunkTable, ChunkInfobox},
+		Namespaces: []int{0},
+	})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	want := `{"must":[{"key":"chunk_type","match":{"any":["table","infobox"]}},{"key":"namespace","match":{"any":[0]}}]}`
+	if string(filter) != want {
+		t.Errorf("expected filter %s, got %s", want, filter)
+	}
+	if results[0][0].ChunkType != ChunkTable {
+		t.Errorf("expected table chunk, got %q", results[0][0].ChunkType)
+	}
+
+	// Test: Unknown chunk types are rejected before searching
+	if _, err := client.SearchFiltered(ctx, []string{"poring"}, 5, Filter{ChunkTypes: []ChunkType{"tables"}}); !errors.Is(err, ErrUnknownChunkType) {
+		t.Errorf("expected ErrUnknownChunkType, got %v", err)
+	}
+}
@@ -10,13 +10,27 @@ import (
 
 // SearchResult represents a single search result from the vector database
 type SearchResult struct {
-	PageTitle    string  `json:"page_title"`
-	SectionTitle *string `json:"section_title,omitempty"`
-	Content      string  `json:"content"`
-	Score        float32 `json:"score"`
-	ChunkType    string  `json:"chunk_type"`
-	Namespace    int     `json:"namespace"`
-	PageID       int     `json:"page_id"`
+	PageTitle    string    `json:"page_title"`
+	SectionTitle *string   `json:"section_title,omitempty"`
+	Content      string    `json:"content"`
+	Score        float32   `json:"score"`
+	ChunkType    ChunkType `json:"chunk_type"`
+	Namespace    int       `json:"namespace"`
+	PageID       int       `json:"page_id"`
+
+	// RevisionID is the revision the chunk was indexed from
+	RevisionID int `json:"revision_id"`
+
+	// SectionAnchor is the anchor of the chunk's section, for deep links
+	SectionAnchor *string `json:"section_anchor,omitempty"`
+
+	// URL links to the chunk's section on the source wiki, or on the
+	// mirror given by Config.PageURL. Empty if the index has no base URL.
+	URL string `json:"url,omitempty"`
+
+	// Tokens is the chunk's length in the embedding model's tokens, for
+	// estimating embedding costs. Zero if the index predates token counts.
+	Tokens int `json:"tokens,omitempty"`
 }
 
 // Metadata contains vector database metadata
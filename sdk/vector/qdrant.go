@@ -0,0 +1,292 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultCollection is the collection name used by the vectorizer
+const DefaultCollection = "irowiki"
+
+// Config configures a VectorClient
+type Config struct {
+	// URL is the Qdrant REST endpoint, e.g. "http://localhost:6333"
+	URL string
+
+	// Collection is the collection to query (default: DefaultCollection)
+	Collection string
+
+	// DBPath is the vector database directory holding metadata.json.
+	// Optional; without it drift detection is skipped.
+	DBPath string
+
+	// Embedder embeds search queries. Optional; required by Search and
+	// SearchBatch.
+	Embedder Embedder
+
+	// Synonyms expands shorthand in queries before they are embedded.
+	// Optional.
+	Synonyms *Synonyms
+
+	// PageURL links results to a page, such as on an archive mirror.
+	// Default: the source URL written by the indexer.
+	PageURL func(title string) string
+
+	// APIKey is sent as the api-key header when set
+	APIKey string
+
+	// HTTPClient is used for requests (default: http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// VectorClient queries an iRO Wiki collection over Qdrant's REST API
+type VectorClient struct {
+	baseURL    string
+	collection string
+	apiKey     string
+	http       *http.Client
+	embedder   Embedder
+	synonyms   *Synonyms
+	pageURL    func(title string) string
+	metadata   *Metadata
+}
+
+// NewVectorClient creates a client for the collection described by cfg
+func NewVectorClient(cfg Config) (*VectorClient, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("qdrant URL is required")
+	}
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid qdrant URL: %w", err)
+	}
+
+	c := &VectorClient{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		collection: cfg.Collection,
+		apiKey:     cfg.APIKey,
+		http:       cfg.HTTPClient,
+		embedder:   cfg.Embedder,
+		synonyms:   cfg.Synonyms,
+		pageURL:    cfg.PageURL,
+	}
+	if c.collection == "" {
+		c.collection = DefaultCollection
+	}
+	if c.http == nil {
+		c.http = http.DefaultClient
+	}
+	if cfg.DBPath != "" {
+		metadata, err := LoadMetadata(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		c.metadata = metadata
+	}
+	return c, nil
+}
+
+// Metadata returns the metadata loaded from Config.DBPath, or nil
+func (c *VectorClient) Metadata() *Metadata {
+	return c.metadata
+}
+
+// CollectionStats describes the state of a collection as reported by Qdrant
+type CollectionStats struct {
+	Collection string `json:"collection"`
+
+	// Status is Qdrant's collection status: green, yellow, grey or red
+	Status string `json:"status"`
+
+	// OptimizerStatus is "ok" or the optimizer's error message
+	OptimizerStatus string `json:"optimizer_status"`
+
+	PointsCount         int64  `json:"points_count"`
+	IndexedVectorsCount int64  `json:"indexed_vectors_count"`
+	SegmentsCount       int    `json:"segments_count"`
+	VectorSize          int    `json:"vector_size"`
+	Distance            string `json:"distance"`
+
+	// PayloadSchema lists the indexed payload fields by name
+	PayloadSchema map[string]PayloadField `json:"payload_schema"`
+
+	// Drift lists where the collection disagrees with Metadata; empty
+	// when no metadata was loaded
+	Drift []Drift `json:"drift,omitempty"`
+}
+
+// PayloadField describes an indexed payload field
+type PayloadField struct {
+	DataType string `json:"data_type"`
+	Points   int64  `json:"points"`
+}
+
+// Drift is a mismatch between metadata.json and the live collection
+type Drift struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s: metadata has %s, collection has %s", d.Field, d.Expected, d.Actual)
+}
+
+// Healthy reports whether the collection is fully indexed, its optimizer
+// is not failing, and it matches its metadata
+func (s *CollectionStats) Healthy() bool {
+	return s.Status == "green" && s.OptimizerStatus == "ok" && len(s.Drift) == 0
+}
+
+// collectionInfo is the result of GET /collections/{name}
+type collectionInfo struct {
+	Status              string          `json:"status"`
+	OptimizerStatus     json.RawMessage `json:"optimizer_status"`
+	PointsCount         *int64          `json:"points_count"`
+	IndexedVectorsCount *int64          `json:"indexed_vectors_count"`
+	SegmentsCount       int             `json:"segments_count"`
+	Config              struct {
+		Params struct {
+			Vectors json.RawMessage `json:"vectors"`
+		} `json:"params"`
+	} `json:"config"`
+	PayloadSchema map[string]PayloadField `json:"payload_schema"`
+}
+
+// vectorParams is the configuration of a single vector
+type vectorParams struct {
+	Size     int    `json:"size"`
+	Distance string `json:"distance"`
+}
+
+// GetCollectionStats returns point counts, index status and the payload
+// schema of the collection, and compares them against Metadata to detect
+// drift such as an embedding dimension mismatch
+func (c *VectorClient) GetCollectionStats(ctx context.Context) (*CollectionStats, error) {
+	var info collectionInfo
+	if err := c.do(ctx, http.MethodGet, "/collections/"+url.PathEscape(c.collection), nil, &info); err != nil {
+		return nil, err
+	}
+
+	stats := &CollectionStats{
+		Collection:    c.collection,
+		Status:        info.Status,
+		SegmentsCount: info.SegmentsCount,
+		PayloadSchema: info.PayloadSchema,
+	}
+	if stats.PayloadSchema == nil {
+		stats.PayloadSchema = map[string]PayloadField{}
+	}
+	if info.PointsCount != nil {
+		stats.PointsCount = *info.PointsCount
+	}
+	if info.IndexedVectorsCount != nil {
+		stats.IndexedVectorsCount = *info.IndexedVectorsCount
+	}
+
+	// optimizer_status is either "ok" or {"error": "..."}
+	var optimizerError struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(info.OptimizerStatus, &stats.OptimizerStatus); err != nil {
+		if err := json.Unmarshal(info.OptimizerStatus, &optimizerError); err != nil {
+			return nil, fmt.Errorf("failed to parse optimizer status: %w", err)
+		}
+		stats.OptimizerStatus = optimizerError.Error
+	}
+
+	// vectors is a single unnamed vector or a map of named ones; the
+	// vectorizer writes a single one
+	var single vectorParams
+	if err := json.Unmarshal(info.Config.Params.Vectors, &single); err == nil && single.Size != 0 {
+		stats.VectorSize, stats.Distance = single.Size, single.Distance
+	} else {
+		var named map[string]vectorParams
+		if err := json.Unmarshal(info.Config.Params.Vectors, &named); err == nil && len(named) == 1 {
+			for _, params := range named {
+				stats.VectorSize, stats.Distance = params.Size, params.Distance
+			}
+		}
+	}
+
+	if c.metadata != nil {
+		stats.Drift = detectDrift(c.metadata, stats)
+	}
+	return stats, nil
+}
+
+// detectDrift compares metadata.json against the collection
+func detectDrift(metadata *Metadata, stats *CollectionStats) []Drift {
+	var drift []Drift
+	if metadata.EmbeddingDim != 0 && metadata.EmbeddingDim != stats.VectorSize {
+		drift = append(drift, Drift{
+			Field:    "embedding_dim",
+			Expected: fmt.Sprint(metadata.EmbeddingDim),
+			Actual:   fmt.Sprint(stats.VectorSize),
+		})
+	}
+	if metadata.TotalChunks != 0 && int64(metadata.TotalChunks) != stats.PointsCount {
+		drift = append(drift, Drift{
+			Field:    "total_chunks",
+			Expected: fmt.Sprint(metadata.TotalChunks),
+			Actual:   fmt.Sprint(stats.PointsCount),
+		})
+	}
+	return drift
+}
+
+// do sends a request to Qdrant and decodes the "result" of its response
+// into out
+func (c *VectorClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("api-key", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Status json.RawMessage `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed to parse qdrant response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var status struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(envelope.Status, &status)
+		if status.Error != "" {
+			return fmt.Errorf("qdrant request failed: %s: %s", resp.Status, status.Error)
+		}
+		return fmt.Errorf("qdrant request failed: %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("failed to parse qdrant result: %w", err)
+	}
+	return nil
+}
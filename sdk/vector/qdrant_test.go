@@ -0,0 +1,77 @@
+package vector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const collectionResponse = `{
+	"result": {
+		"status": "yellow",
+		"optimizer_status": {"error": "disk full"},
+		"points_count": 1200,
+		"indexed_vectors_count": 1000,
+		"segments_count": 4,
+		"config": {"params": {"vectors": {"size": 768, "distance": "Cosine"}}},
+		"payload_schema": {"namespace": {"data_type": "integer", "points": 1200}}
+	},
+	"status": "ok",
+	"time": 0.001
+}`
+
+func TestGetCollectionStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/collections/irowiki" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status": {"error": "Not found: Collection doesn't exist!"}}`))
+			return
+		}
+		w.Write([]byte(collectionResponse))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	metadata := `{"model": "all-MiniLM-L6-v2", "embedding_dim": 384, "total_chunks": 1200}`
+	if err := os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(metadata), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewVectorClient(Config{URL: server.URL, DBPath: dir})
+	if err != nil {
+		t.Fatalf("NewVectorClient failed: %v", err)
+	}
+	stats, err := client.GetCollectionStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetCollectionStats failed: %v", err)
+	}
+
+	if stats.Status != "yellow" || stats.OptimizerStatus != "disk full" {
+		t.Errorf("unexpected status %q, optimizer %q", stats.Status, stats.OptimizerStatus)
+	}
+	if stats.PointsCount != 1200 || stats.IndexedVectorsCount != 1000 || stats.SegmentsCount != 4 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if stats.VectorSize != 768 || stats.Distance != "Cosine" {
+		t.Errorf("unexpected vectors: %d %s", stats.VectorSize, stats.Distance)
+	}
+	if field := stats.PayloadSchema["namespace"]; field.DataType != "integer" {
+		t.Errorf("unexpected payload schema: %+v", stats.PayloadSchema)
+	}
+	want := Drift{Field: "embedding_dim", Expected: "384", Actual: "768"}
+	if len(stats.Drift) != 1 || stats.Drift[0] != want {
+		t.Errorf("expected drift %v, got %v", want, stats.Drift)
+	}
+	if stats.Healthy() {
+		t.Error("expected unhealthy collection")
+	}
+
+	// Test: Missing collections report Qdrant's error
+	client, _ = NewVectorClient(Config{URL: server.URL, Collection: "missing"})
+	if _, err := client.GetCollectionStats(context.Background()); err == nil {
+		t.Error("expected error for missing collection")
+	}
+}
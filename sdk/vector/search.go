@@ -0,0 +1,111 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Embedder turns texts into query vectors, using the model the collection
+// was built with (Metadata.Model)
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// scoredPoint is a search hit returned by Qdrant
+type scoredPoint struct {
+	Score   float32         `json:"score"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// sourceURL deep-links pageURL to a section
+func sourceURL(pageURL string, anchor *string) string {
+	if pageURL == "" || anchor == nil || *anchor == "" {
+		return pageURL
+	}
+	return pageURL + "#" + url.PathEscape(*anchor)
+}
+
+// searchRequest is one search of a batch search request
+type searchRequest struct {
+	Vector      []float32      `json:"vector"`
+	Filter      map[string]any `json:"filter,omitempty"`
+	Limit       int            `json:"limit"`
+	WithPayload bool           `json:"with_payload"`
+}
+
+// Search returns the limit chunks most similar to query
+func (c *VectorClient) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	results, err := c.SearchBatch(ctx, []string{query}, limit)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// SearchBatch runs several searches in one round trip, embedding all the
+// queries at once and sending them as one Qdrant batch search. Queries are
+// expanded with Config.Synonyms first. The results are in the order of
+// queries, limit per query.
+func (c *VectorClient) SearchBatch(ctx context.Context, queries []string, limit int) ([][]SearchResult, error) {
+	return c.SearchFiltered(ctx, queries, limit, Filter{})
+}
+
+// SearchFiltered is SearchBatch restricted to the chunks matching filter.
+// Filters on unknown chunk types fail with ErrUnknownChunkType.
+func (c *VectorClient) SearchFiltered(ctx context.Context, queries []string, limit int, filter Filter) ([][]SearchResult, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if c.embedder == nil {
+		return nil, fmt.Errorf("searching requires an Embedder")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+	if len(queries) == 0 {
+		return [][]SearchResult{}, nil
+	}
+
+	texts := make([]string, len(queries))
+	for i, query := range queries {
+		texts[i] = c.synonyms.Expand(query)
+	}
+	vectors, err := c.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed queries: %w", err)
+	}
+	if len(vectors) != len(queries) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d queries", len(vectors), len(queries))
+	}
+
+	searches := make([]searchRequest, len(vectors))
+	for i, vec := range vectors {
+		searches[i] = searchRequest{Vector: vec, Filter: filter.qdrantFilter(), Limit: limit, WithPayload: true}
+	}
+	var batch [][]scoredPoint
+	path := "/collections/" + url.PathEscape(c.collection) + "/points/search/batch"
+	if err := c.do(ctx, http.MethodPost, path, map[string]any{"searches": searches}, &batch); err != nil {
+		return nil, err
+	}
+	if len(batch) != len(queries) {
+		return nil, fmt.Errorf("qdrant returned %d results for %d queries", len(batch), len(queries))
+	}
+
+	results := make([][]SearchResult, len(batch))
+	for i, points := range batch {
+		results[i] = make([]SearchResult, len(points))
+		for j, point := range points {
+			if err := json.Unmarshal(point.Payload, &results[i][j]); err != nil {
+				return nil, fmt.Errorf("failed to parse payload: %w", err)
+			}
+			results[i][j].Score = point.Score
+			if c.pageURL != nil {
+				results[i][j].URL = sourceURL(c.pageURL(results[i][j].PageTitle), results[i][j].SectionAnchor)
+			}
+		}
+	}
+	return results, nil
+}
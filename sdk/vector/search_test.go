@@ -0,0 +1,95 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeEmbedder embeds each text as a vector of its length
+type fakeEmbedder struct {
+	calls int
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func TestSearchBatch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodPost || r.URL.Path != "/collections/irowiki/points/search/batch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Searches []searchRequest `json:"searches"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Searches) != 2 || body.Searches[1].Vector[0] != 4 || body.Searches[0].Limit != 3 {
+			t.Errorf("unexpected searches: %+v", body.Searches)
+		}
+		w.Write([]byte(`{"result": [
+			[{"id": 1, "score": 0.9, "payload": {"page_title": "Prontera", "content": "Capital", "chunk_type": "section", "namespace": 0, "page_id": 1}}],
+			[{"id": 2, "score": 0.8, "payload": {"page_title": "Geffen", "section_title": "Dungeon", "content": "Tower", "chunk_type": "section", "namespace": 0, "page_id": 2, "revision_id": 20, "section_anchor": "Dungeon", "url": "https://irowiki.org/wiki/Geffen#Dungeon", "tokens": 12}},
+			 {"id": 3, "score": 0.5, "payload": {"page_title": "Payon", "content": "Village", "chunk_type": "page", "namespace": 0, "page_id": 3}}]
+		], "status": "ok"}`))
+	}))
+	defer server.Close()
+
+	embedder := &fakeEmbedder{}
+	client, err := NewVectorClient(Config{URL: server.URL, Embedder: embedder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := client.SearchBatch(context.Background(), []string{"capital", "mage"}, 3)
+	if err != nil {
+		t.Fatalf("SearchBatch failed: %v", err)
+	}
+
+	if requests != 1 || embedder.calls != 1 {
+		t.Errorf("expected one round trip, got %d requests and %d embed calls", requests, embedder.calls)
+	}
+	if len(results) != 2 || len(results[0]) != 1 || len(results[1]) != 2 {
+		t.Fatalf("unexpected result shape: %+v", results)
+	}
+	if got := results[0][0]; got.PageTitle != "Prontera" || got.Score != 0.9 || got.PageID != 1 {
+		t.Errorf("unexpected first result: %+v", got)
+	}
+	if got := results[1][0]; got.SectionTitle == nil || *got.SectionTitle != "Dungeon" {
+		t.Errorf("expected section title, got %+v", got)
+	}
+	if got := results[1][0]; got.RevisionID != 20 || got.URL != "https://irowiki.org/wiki/Geffen#Dungeon" || got.Tokens != 12 {
+		t.Errorf("expected revision, source URL and tokens, got %+v", got)
+	}
+
+	// Test: PageURL links results to a mirror
+	client, _ = NewVectorClient(Config{URL: server.URL, Embedder: embedder, PageURL: func(title string) string {
+		return "https://mirror.example/wiki/" + title
+	}})
+	results, err = client.SearchBatch(context.Background(), []string{"capital", "mage"}, 3)
+	if err != nil {
+		t.Fatalf("SearchBatch failed: %v", err)
+	}
+	if got := results[1][0].URL; got != "https://mirror.example/wiki/Geffen#Dungeon" {
+		t.Errorf("expected mirror URL, got %q", got)
+	}
+	if got := results[0][0].URL; got != "https://mirror.example/wiki/Prontera" {
+		t.Errorf("expected mirror page URL, got %q", got)
+	}
+
+	// Test: Searching without an embedder fails
+	client, _ = NewVectorClient(Config{URL: server.URL})
+	if _, err := client.Search(context.Background(), "capital", 3); err == nil {
+		t.Error("expected error without an Embedder")
+	}
+}
@@ -0,0 +1,160 @@
+package vector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Synonyms expands community shorthand in search queries, such as "GX" for
+// "Guillotine Cross", so the embedding sees the terms the wiki uses.
+// Aliases match whole words, ignoring case.
+type Synonyms struct {
+	// expansions maps an alias's lowercase words, joined by spaces, to
+	// its expansions
+	expansions map[string][]string
+	maxWords   int
+}
+
+// NewSynonyms creates an empty set of synonyms
+func NewSynonyms() *Synonyms {
+	return &Synonyms{expansions: make(map[string][]string)}
+}
+
+// LoadSynonyms loads synonyms from a JSON file mapping each alias to its
+// expansions:
+//
+//	{"GX": ["Guillotine Cross"], "OCA": ["Old Card Album"]}
+func LoadSynonyms(path string) (*Synonyms, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read synonyms file: %w", err)
+	}
+
+	var aliases map[string][]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse synonyms: %w", err)
+	}
+
+	s := NewSynonyms()
+	for alias, expansions := range aliases {
+		s.Add(alias, expansions...)
+	}
+	return s, nil
+}
+
+// SynonymsFromRedirects derives synonyms from the wiki's redirects, given
+// as redirect title to target title. Redirects that only restate their
+// target, such as "Prontera (City)" to "Prontera", are skipped.
+func SynonymsFromRedirects(redirects map[string]string) *Synonyms {
+	s := NewSynonyms()
+	for alias, target := range redirects {
+		a, t := strings.ToLower(alias), strings.ToLower(target)
+		if strings.Contains(a, t) || strings.Contains(t, a) {
+			continue
+		}
+		s.Add(alias, target)
+	}
+	return s
+}
+
+// Add adds expansions for an alias. Aliases without words are ignored.
+func (s *Synonyms) Add(alias string, expansions ...string) {
+	words := splitWords(alias)
+	if len(words) == 0 {
+		return
+	}
+	key := joinWords(alias, words)
+	for _, expansion := range expansions {
+		if expansion = strings.TrimSpace(expansion); expansion != "" && !containsFold(s.expansions[key], expansion) {
+			s.expansions[key] = append(s.expansions[key], expansion)
+		}
+	}
+	s.maxWords = max(s.maxWords, len(words))
+}
+
+// Len returns the number of aliases
+func (s *Synonyms) Len() int {
+	return len(s.expansions)
+}
+
+// Expand returns query with the expansions of the aliases it contains
+// added in parentheses after them, e.g. "GX build" becomes
+// "GX (Guillotine Cross) build". Expansions already in the query aren't
+// repeated.
+func (s *Synonyms) Expand(query string) string {
+	if s == nil || len(s.expansions) == 0 {
+		return query
+	}
+	lower := strings.ToLower(query)
+	words := splitWords(query)
+
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(words); i++ {
+		// Prefer the longest alias starting at this word
+		for n := min(s.maxWords, len(words)-i); n > 0; n-- {
+			expansions, ok := s.expansions[joinWords(query, words[i:i+n])]
+			if !ok {
+				continue
+			}
+			var missing []string
+			for _, expansion := range expansions {
+				if !strings.Contains(lower, strings.ToLower(expansion)) {
+					missing = append(missing, expansion)
+				}
+			}
+			end := words[i+n-1][1]
+			if len(missing) > 0 {
+				b.WriteString(query[last:end])
+				b.WriteString(" (" + strings.Join(missing, ", ") + ")")
+				last = end
+			}
+			i += n - 1
+			break
+		}
+	}
+	b.WriteString(query[last:])
+	return b.String()
+}
+
+// splitWords returns the start and end offsets of the runs of letters and
+// digits in s
+func splitWords(s string) [][2]int {
+	var words [][2]int
+	start := -1
+	for i, r := range s {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, [2]int{start, len(s)})
+	}
+	return words
+}
+
+// joinWords joins the words of s at the given offsets, lowercased
+func joinWords(s string, words [][2]int) string {
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = strings.ToLower(s[w[0]:w[1]])
+	}
+	return strings.Join(parts, " ")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package vector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSynonyms_Expand(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "synonyms.json")
+	data := `{"GX": ["Guillotine Cross"], "OCA": ["Old Card Album"], "old blue box": ["Old Blue Box"], "Box": ["Container"]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	synonyms, err := LoadSynonyms(path)
+	if err != nil {
+		t.Fatalf("LoadSynonyms failed: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"GX build", "GX (Guillotine Cross) build"},
+		{"best gx, oca?", "best gx (Guillotine Cross), oca (Old Card Album)?"},
+		{"GXs", "GXs"},
+		{"Guillotine Cross GX", "Guillotine Cross GX"},
+		{"open an old blue box", "open an old blue box"},
+		{"box drops", "box (Container) drops"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := synonyms.Expand(tt.query); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	var none *Synonyms
+	if got := none.Expand("GX"); got != "GX" {
+		t.Errorf("nil Synonyms changed query to %q", got)
+	}
+}
+
+func TestSynonymsFromRedirects(t *testing.T) {
+	synonyms := SynonymsFromRedirects(map[string]string{
+		"GX":              "Guillotine Cross",
+		"Prontera (City)": "Prontera",
+		"Priest":          "Priest (Class)",
+	})
+	if synonyms.Len() != 1 {
+		t.Errorf("expected 1 alias, got %d", synonyms.Len())
+	}
+	if got := synonyms.Expand("gx skills"); got != "gx (Guillotine Cross) skills" {
+		t.Errorf("unexpected expansion %q", got)
+	}
+}
@@ -0,0 +1,176 @@
+// Package wayback checks external links and finds archived copies of dead
+// ones using the Internet Archive's Wayback Machine availability API.
+//
+// A Client implements irowiki.LinkChecker:
+//
+//	store, err := irowiki.OpenSQLiteStore("irowiki.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer store.Close()
+//
+//	result, err := store.CheckExternalLinks(ctx, wayback.NewClient(wayback.Options{}), irowiki.ExternalLinkOptions{})
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+)
+
+// DefaultAvailabilityURL is the Wayback Machine availability API endpoint.
+const DefaultAvailabilityURL = "https://archive.org/wayback/available"
+
+// timestampLayout is the Wayback Machine's 14-digit timestamp format.
+const timestampLayout = "20060102150405"
+
+// Options configures a Client.
+type Options struct {
+	// HTTPClient performs requests. Default: a client with Timeout.
+	HTTPClient *http.Client
+
+	// AvailabilityURL overrides the availability API endpoint (for testing or mirrors).
+	AvailabilityURL string
+
+	// UserAgent is sent with every request. Default: "iRO-Wiki-Scraper-SDK".
+	UserAgent string
+
+	// Timeout bounds each request when HTTPClient is not set. Default: 15 seconds.
+	Timeout time.Duration
+}
+
+// SetDefaults applies default values to unset options.
+func (o *Options) SetDefaults() {
+	if o.Timeout == 0 {
+		o.Timeout = 15 * time.Second
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{Timeout: o.Timeout}
+	}
+	if o.AvailabilityURL == "" {
+		o.AvailabilityURL = DefaultAvailabilityURL
+	}
+	if o.UserAgent == "" {
+		o.UserAgent = "iRO-Wiki-Scraper-SDK"
+	}
+}
+
+// Client checks link liveness and queries the availability API.
+// It is safe for concurrent use by multiple goroutines.
+type Client struct {
+	opts Options
+}
+
+var _ irowiki.LinkChecker = (*Client)(nil)
+
+// NewClient creates a Client with the given options.
+func NewClient(opts Options) *Client {
+	opts.SetDefaults()
+	return &Client{opts: opts}
+}
+
+// IsDead reports whether rawURL no longer serves its content.
+// Missing pages (404, 410), server errors, and unreachable hosts count as dead.
+// Responses that usually mean the site is blocking automated clients
+// (401, 403, 429) count as alive so they aren't replaced by stale copies.
+func (c *Client) IsDead(ctx context.Context, rawURL string) (bool, error) {
+	status, err := c.status(ctx, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		// Some servers reject HEAD; retry with GET before judging
+		status, err = c.status(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		// DNS failures, refused connections, TLS errors: the link is unusable
+		return true, nil
+	}
+
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return false, nil
+	case status >= 400:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// status performs a request and returns the response status code.
+func (c *Client) status(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// availabilityResponse is the JSON returned by the availability API.
+type availabilityResponse struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// NearestSnapshot returns the archived copy of rawURL captured closest to at.
+// Returns irowiki.ErrNotFound if the Wayback Machine has no usable copy.
+func (c *Client) NearestSnapshot(ctx context.Context, rawURL string, at time.Time) (*irowiki.Snapshot, error) {
+	params := url.Values{}
+	params.Set("url", rawURL)
+	if !at.IsZero() {
+		params.Set("timestamp", at.UTC().Format(timestampLayout))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.opts.AvailabilityURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build availability request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("availability request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("availability API returned status %d", resp.StatusCode)
+	}
+
+	var body availabilityResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode availability response: %w", err)
+	}
+
+	closest := body.ArchivedSnapshots.Closest
+	// Only successful captures are useful; an archived 404 is no better than the live one
+	if closest == nil || !closest.Available || closest.URL == "" || (closest.Status != "" && closest.Status != "200") {
+		return nil, irowiki.ErrNotFound
+	}
+
+	ts, err := time.Parse(timestampLayout, closest.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot timestamp %q: %w", closest.Timestamp, err)
+	}
+
+	return &irowiki.Snapshot{URL: closest.URL, Timestamp: ts}, nil
+}
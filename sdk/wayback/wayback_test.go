@@ -0,0 +1,99 @@
+package wayback_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/irowiki"
+	"github.com/mikekao/iRO-Wiki-Scraper/sdk/wayback"
+)
+
+// TestClient_IsDead tests liveness classification of HTTP responses
+func TestClient_IsDead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/blocked":
+			w.WriteHeader(http.StatusForbidden)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := wayback.NewClient(wayback.Options{HTTPClient: server.Client()})
+
+	tests := []struct {
+		path string
+		dead bool
+	}{
+		{"/ok", false},
+		{"/gone", true},
+		{"/missing", true},
+		{"/blocked", false},
+		{"/no-head", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			dead, err := client.IsDead(context.Background(), server.URL+tt.path)
+			if err != nil {
+				t.Fatalf("IsDead failed: %v", err)
+			}
+			if dead != tt.dead {
+				t.Errorf("expected dead=%v, got %v", tt.dead, dead)
+			}
+		})
+	}
+}
+
+// TestClient_NearestSnapshot tests parsing the availability API response
+func TestClient_NearestSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("timestamp") != "20200105000000" {
+			t.Errorf("unexpected timestamp parameter: %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("url") == "http://example.com/missing" {
+			w.Write([]byte(`{"url": "http://example.com/missing", "archived_snapshots": {}}`))
+			return
+		}
+		w.Write([]byte(`{"archived_snapshots": {"closest": {"status": "200", "available": true,
+			"url": "http://web.archive.org/web/20200104120000/http://example.com/", "timestamp": "20200104120000"}}}`))
+	}))
+	defer server.Close()
+
+	client := wayback.NewClient(wayback.Options{AvailabilityURL: server.URL})
+	at := time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	snapshot, err := client.NearestSnapshot(context.Background(), "http://example.com/", at)
+	if err != nil {
+		t.Fatalf("NearestSnapshot failed: %v", err)
+	}
+	if snapshot.URL != "http://web.archive.org/web/20200104120000/http://example.com/" {
+		t.Errorf("unexpected snapshot URL: %s", snapshot.URL)
+	}
+	if !snapshot.Timestamp.Equal(time.Date(2020, 1, 4, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected snapshot timestamp: %v", snapshot.Timestamp)
+	}
+
+	// Test: No snapshot
+	_, err = client.NearestSnapshot(context.Background(), "http://example.com/missing", at)
+	if !errors.Is(err, irowiki.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -539,6 +539,30 @@ class TestProcessDeletedPages:
         # For now just logs, returns count
         assert result == 3
 
+    def test_process_deleted_pages_records_tombstones(self, page_scraper, db):
+        """Test that archived pages get a deleted_pages tombstone."""
+        page_scraper.page_repo.insert_page(
+            Page(page_id=100, namespace=0, title="Gone", is_redirect=False)
+        )
+
+        result = page_scraper._process_deleted_pages({100, 200})
+
+        assert result == 2
+        rows = db.get_connection().execute("SELECT page_id FROM deleted_pages").fetchall()
+        assert [row[0] for row in rows] == [100]
+
+    def test_restored_pages_lose_tombstones(self, page_scraper, db):
+        """Test that new activity on a deleted page clears its tombstone."""
+        page_scraper.page_repo.insert_page(
+            Page(page_id=100, namespace=0, title="Gone", is_redirect=False)
+        )
+        page_scraper._process_deleted_pages({100})
+
+        page_scraper._clear_deleted_pages({100})
+
+        count = db.get_connection().execute("SELECT COUNT(*) FROM deleted_pages").fetchone()[0]
+        assert count == 0
+
 
 class TestProcessMovedPages:
     """Tests for _process_moved_pages method."""
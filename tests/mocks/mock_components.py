@@ -71,6 +71,7 @@ class MockPageRepository:
         self.db = db
         self.insert_pages_batch_calls = []
         self.inserted_pages = []
+        self.update_redirect_targets_calls = []
         self.should_fail = False
         self.failure_exception = None
 
@@ -88,6 +89,11 @@ class MockPageRepository:
 
         self.inserted_pages.extend(pages)
 
+    def update_redirect_targets(self, page_ids=None) -> int:
+        """Mock update_redirect_targets method."""
+        self.update_redirect_targets_calls.append(page_ids)
+        return 0
+
 
 class MockRevisionRepository:
     """Mock RevisionRepository for testing orchestration."""
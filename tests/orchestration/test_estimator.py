@@ -0,0 +1,153 @@
+"""Tests for scrape and export cost estimates."""
+
+from unittest.mock import Mock
+
+from scraper.orchestration.estimator import (
+    XML_PAGE_OVERHEAD_BYTES,
+    XML_REVISION_OVERHEAD_BYTES,
+    ScrapeEstimator,
+    ScrapePlan,
+    estimate_export,
+)
+from scraper.storage.page_repository import PageRepository
+from scraper.storage.revision_repository import RevisionRepository
+
+
+def _api_with_statistics(stats):
+    """Create an API client mock answering siteinfo with stats."""
+    api = Mock()
+    api.query.return_value = {"query": {"statistics": stats}}
+    return api
+
+
+class TestScrapePlan:
+    """Test ScrapePlan properties."""
+
+    def test_duration_from_rate_limit(self):
+        """Test duration is total requests over the rate limit."""
+        plan = ScrapePlan(
+            pages=10,
+            revisions=20,
+            requests={"discover": 2, "revisions": 10},
+            rate_limit=4.0,
+            output_bytes=0,
+            source="discovery",
+        )
+        assert plan.total_requests == 12
+        assert plan.duration_seconds == 3.0
+
+    def test_zero_rate_limit(self):
+        """Test an unlimited rate has no duration."""
+        plan = ScrapePlan(
+            pages=1,
+            revisions=1,
+            requests={"revisions": 1},
+            rate_limit=0,
+            output_bytes=0,
+            source="discovery",
+        )
+        assert plan.duration_seconds == 0.0
+
+    def test_to_dict(self):
+        """Test to_dict includes derived totals."""
+        plan = ScrapePlan(
+            pages=10,
+            revisions=20,
+            requests={"discover": 2, "revisions": 10},
+            rate_limit=4.0,
+            output_bytes=1234,
+            source="siteinfo",
+            namespaces=[0],
+        )
+        data = plan.to_dict()
+        assert data["total_requests"] == 12
+        assert data["duration_seconds"] == 3.0
+        assert data["output_bytes"] == 1234
+        assert data["namespaces"] == [0]
+
+
+class TestScrapeEstimator:
+    """Test ScrapeEstimator predictions."""
+
+    def test_estimate_from_siteinfo(self):
+        """Test estimates without a discovery use the wiki's statistics."""
+        api = _api_with_statistics({"pages": 1000, "edits": 5000, "articles": 600})
+        estimator = ScrapeEstimator(api, rate_limit=2.0)
+
+        plan = estimator.estimate([0, 4])
+
+        api.query.assert_called_once_with({"meta": "siteinfo", "siprop": "statistics"})
+        assert plan.source == "siteinfo"
+        assert plan.pages == 1000
+        assert plan.revisions == 5000
+        # One query per namespace plus continuations, one per page for history
+        assert plan.requests["discover"] == 4
+        assert plan.requests["revisions"] == 1000 + 8
+        assert plan.duration_seconds == plan.total_requests / 2.0
+        assert plan.output_bytes > 0
+
+    def test_estimate_from_discovery(self):
+        """Test discovered page counts replace the wiki's page count."""
+        api = _api_with_statistics({"pages": 1000, "edits": 3000})
+        estimator = ScrapeEstimator(api, rate_limit=1.0)
+
+        plan = estimator.estimate(page_counts={0: 1200, 4: 10})
+
+        assert plan.source == "discovery"
+        assert plan.pages == 1210
+        assert plan.revisions == 3630
+        assert plan.requests["discover"] == 3 + 1
+        assert plan.namespaces == [0, 4]
+
+    def test_estimate_optional_stages(self):
+        """Test HTML and protection capture add their requests."""
+        api = _api_with_statistics({"pages": 120, "edits": 120})
+        base = ScrapeEstimator(api, rate_limit=1.0).estimate([0])
+        full = ScrapeEstimator(
+            api, rate_limit=1.0, capture_html=True, capture_protection=True
+        ).estimate([0])
+
+        assert "html" not in base.requests
+        assert full.requests["html"] == 120
+        assert full.requests["protection"] == 3
+        assert full.output_bytes > base.output_bytes
+
+    def test_estimate_without_statistics(self):
+        """Test a wiki without statistics still estimates from discovery."""
+        api = Mock()
+        api.query.side_effect = Exception("API error")
+        estimator = ScrapeEstimator(api, rate_limit=2.0)
+
+        assert estimator.fetch_statistics() is None
+        plan = estimator.estimate([0], page_counts={0: 10})
+        assert plan.pages == 10
+        # One revision per page when the wiki doesn't say otherwise
+        assert plan.revisions == 10
+
+
+class TestEstimateExport:
+    """Test export estimates from archive counts."""
+
+    def test_estimate_export(self, db, sample_pages, sample_revisions):
+        """Test estimates count the archive's pages, revisions and content."""
+        PageRepository(db).insert_pages_batch(sample_pages)
+        RevisionRepository(db).insert_revisions_batch(sample_revisions)
+
+        plan = estimate_export(db)
+
+        assert plan.pages == 5
+        assert plan.revisions == 3
+        assert plan.content_bytes == 12 + 21 + 18
+        assert plan.output_bytes == (
+            51 + 3 * XML_REVISION_OVERHEAD_BYTES + 5 * XML_PAGE_OVERHEAD_BYTES
+        )
+
+    def test_estimate_empty_archive(self, db):
+        """Test an empty archive has nothing to export."""
+        plan = estimate_export(db)
+        assert plan.to_dict() == {
+            "pages": 0,
+            "revisions": 0,
+            "content_bytes": 0,
+            "output_bytes": 0,
+        }
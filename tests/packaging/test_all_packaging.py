@@ -1,5 +1,6 @@
 """Comprehensive tests for packaging modules."""
 
+import hashlib
 import json
 
 import pytest
@@ -13,6 +14,12 @@ from scraper.packaging.compression import compress_directory, split_archive
 from scraper.packaging.manifest import ManifestGenerator
 from scraper.packaging.release import ReleaseBuilder
 from scraper.packaging.release_notes import ReleaseNotesGenerator
+from scraper.packaging.torrent import (
+    bencode,
+    choose_piece_length,
+    create_torrent,
+    pin_to_ipfs,
+)
 from scraper.packaging.verify import verify_release
 from scraper.storage.database import Database
 
@@ -344,3 +351,117 @@ class TestReleaseNotes:
         assert output_path.exists()
         content = output_path.read_text()
         assert "2026.01" in content
+
+
+class TestTorrent:
+    """Test torrent and IPFS publishing."""
+
+    def test_bencode(self):
+        """Test bencoding with sorted dict keys."""
+        assert bencode(42) == b"i42e"
+        assert bencode("spam") == b"4:spam"
+        assert bencode([1, b"ab"]) == b"li1e2:abe"
+        assert bencode({"b": 1, "a": "x"}) == b"d1:a1:x1:bi1ee"
+
+        with pytest.raises(TypeError):
+            bencode(1.5)
+
+    def test_choose_piece_length(self):
+        """Test piece lengths are bounded powers of two."""
+        assert choose_piece_length(0) == 256 * 1024
+        assert choose_piece_length(10 * 1024) == 256 * 1024
+        assert choose_piece_length(4 * 1024**3) == 4 * 1024 * 1024
+        assert choose_piece_length(1024**4) == 16 * 1024 * 1024
+
+    def test_create_torrent_single_file(self, tmp_path):
+        """Test creating a torrent for an archive file."""
+        archive = tmp_path / "irowiki-archive-2026.01.tar.gz"
+        data = bytes(range(256)) * 5
+        archive.write_bytes(data)
+
+        result = create_torrent(
+            archive,
+            trackers=["udp://tracker.example:1337/announce"],
+            web_seeds=["https://example.org/irowiki-archive-2026.01.tar.gz"],
+            piece_length=512,
+        )
+
+        assert result["torrent_path"] == tmp_path / "irowiki-archive-2026.01.tar.gz.torrent"
+        assert result["piece_count"] == 3
+        assert result["total_size"] == len(data)
+
+        # Pieces are the SHA-1 of each 512-byte slice, the last one shorter
+        expected_pieces = b"".join(
+            hashlib.sha1(data[i : i + 512]).digest() for i in range(0, len(data), 512)
+        )
+        info = {
+            "name": archive.name,
+            "piece length": 512,
+            "pieces": expected_pieces,
+            "length": len(data),
+        }
+        assert result["info_hash"] == hashlib.sha1(bencode(info)).hexdigest()
+
+        content = result["torrent_path"].read_bytes()
+        assert bencode(info) in content
+        assert b"8:url-list" in content
+        assert b"udp://tracker.example:1337/announce" in content
+        assert result["magnet_uri"].startswith(f"magnet:?xt=urn:btih:{result['info_hash']}")
+        assert "ws=https%3A%2F%2Fexample.org" in result["magnet_uri"]
+
+    def test_create_torrent_directory(self, tmp_path):
+        """Test a release directory becomes a multi-file torrent."""
+        release_dir = tmp_path / "irowiki-archive-2026.01"
+        (release_dir / "files").mkdir(parents=True)
+        (release_dir / "irowiki.db").write_bytes(b"a" * 300)
+        (release_dir / "files" / "Poring.png").write_bytes(b"b" * 300)
+
+        result = create_torrent(release_dir, piece_length=256)
+
+        # Pieces span file boundaries: 600 bytes in 3 pieces
+        assert result["file_count"] == 2
+        assert result["piece_count"] == 3
+        content = result["torrent_path"].read_bytes()
+        assert b"5:filesl" in content
+        assert bencode(["files", "Poring.png"]) in content
+
+    def test_create_torrent_missing(self, tmp_path):
+        """Test missing paths and empty directories are rejected."""
+        with pytest.raises(FileNotFoundError):
+            create_torrent(tmp_path / "missing.tar.gz")
+
+        (tmp_path / "empty").mkdir()
+        with pytest.raises(ValueError):
+            create_torrent(tmp_path / "empty")
+
+    def test_pin_to_ipfs(self, tmp_path, monkeypatch):
+        """Test adding an archive through the IPFS RPC API."""
+        archive = tmp_path / "irowiki.db"
+        archive.write_bytes(b"archive")
+        calls = {}
+
+        class FakeResponse:
+            def raise_for_status(self):
+                pass
+
+            def json(self):
+                return {"Name": "irowiki.db", "Hash": "bafytest", "Size": "7"}
+
+        def fake_post(url, params, data, headers, timeout):
+            calls["url"] = url
+            calls["params"] = params
+            calls["body"] = b"".join(data)
+            calls["headers"] = headers
+            return FakeResponse()
+
+        monkeypatch.setattr("requests.post", fake_post)
+
+        result = pin_to_ipfs(archive, api_url="http://ipfs.local:5001/")
+
+        assert result["cid"] == "bafytest"
+        assert result["size"] == 7
+        assert result["gateway_url"].startswith("https://ipfs.io/ipfs/bafytest")
+        assert calls["url"] == "http://ipfs.local:5001/api/v0/add"
+        assert calls["params"]["pin"] == "true"
+        assert b'filename="irowiki.db"' in calls["body"]
+        assert b"\r\n\r\narchive\r\n" in calls["body"]
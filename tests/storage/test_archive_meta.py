@@ -0,0 +1,56 @@
+"""
+Test ArchiveMetaRepository key/value storage and source recording.
+"""
+
+import json
+from unittest.mock import MagicMock
+
+from scraper.storage.archive_meta import ArchiveMetaRepository
+
+
+class TestArchiveMetaRepository:
+    """Test archive_meta reads and writes."""
+
+    def test_set_and_get(self, db):
+        """Test values round-trip and overwrite."""
+        repo = ArchiveMetaRepository(db)
+
+        repo.set("base_url", "https://example.org")
+        repo.set("base_url", "https://irowiki.org")
+
+        assert repo.get("base_url") == "https://irowiki.org"
+        assert repo.get("missing") is None
+        assert repo.get_all() == {"base_url": "https://irowiki.org"}
+
+    def test_record_source_with_siteinfo(self, db):
+        """Test siteinfo and license are recorded."""
+        api_client = MagicMock()
+        api_client.query.return_value = {
+            "query": {
+                "general": {"sitename": "iRO Wiki", "lang": "en"},
+                "rightsinfo": {"text": "CC BY-SA 4.0", "url": "https://cc.org"},
+            }
+        }
+
+        repo = ArchiveMetaRepository(db)
+        repo.record_source(api_client, "https://irowiki.org/", "/w/api.php")
+
+        meta = repo.get_all()
+        assert meta["base_url"] == "https://irowiki.org"
+        assert meta["api_url"] == "https://irowiki.org/w/api.php"
+        assert meta["wiki_name"] == "iRO Wiki"
+        assert meta["license_name"] == "CC BY-SA 4.0"
+        assert json.loads(meta["siteinfo"])["lang"] == "en"
+        assert "scraper_version" in meta
+
+    def test_record_source_without_siteinfo(self, db):
+        """Test API failures still record the source URL."""
+        api_client = MagicMock()
+        api_client.query.side_effect = Exception("API down")
+
+        repo = ArchiveMetaRepository(db)
+        repo.record_source(api_client, "https://irowiki.org", "/w/api.php")
+
+        meta = repo.get_all()
+        assert meta["base_url"] == "https://irowiki.org"
+        assert "wiki_name" not in meta
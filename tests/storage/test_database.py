@@ -107,6 +107,90 @@ class TestDatabaseInitialization:
 
         db_readonly.close()
 
+    def test_migrate_pages_wiki_id(self, temp_db_path):
+        """Test archives from before sister wiki support gain pages.wiki_id."""
+        conn = sqlite3.connect(temp_db_path)
+        conn.executescript(
+            """
+            CREATE TABLE pages (
+                page_id INTEGER PRIMARY KEY,
+                namespace INTEGER NOT NULL DEFAULT 0,
+                title TEXT NOT NULL,
+                is_redirect BOOLEAN NOT NULL DEFAULT 0,
+                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                UNIQUE(namespace, title),
+                CHECK(namespace >= 0)
+            );
+            INSERT INTO pages (page_id, namespace, title) VALUES (1, 0, 'Prontera');
+        """
+        )
+        conn.close()
+
+        db = Database(temp_db_path)
+        db.initialize_schema()
+        conn = db.get_connection()
+
+        row = conn.execute("SELECT title, wiki_id FROM pages WHERE page_id = 1").fetchone()
+        assert tuple(row) == ("Prontera", "")
+
+        # The same title may now exist once per wiki
+        conn.execute(
+            "INSERT INTO pages (page_id, namespace, title, wiki_id) VALUES (2, 0, 'Prontera', 'classic')"
+        )
+        with pytest.raises(sqlite3.IntegrityError, match="UNIQUE"):
+            conn.execute("INSERT INTO pages (page_id, namespace, title) VALUES (3, 0, 'Prontera')")
+
+        db.close()
+
+    def test_migrate_pages_redirect_target(self, temp_db_path):
+        """Test archives from before redirect targets gain them from their revisions."""
+        conn = sqlite3.connect(temp_db_path)
+        conn.executescript(
+            """
+            CREATE TABLE pages (
+                page_id INTEGER PRIMARY KEY,
+                namespace INTEGER NOT NULL DEFAULT 0,
+                title TEXT NOT NULL,
+                is_redirect BOOLEAN NOT NULL DEFAULT 0,
+                wiki_id TEXT NOT NULL DEFAULT '',
+                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                UNIQUE(wiki_id, namespace, title),
+                CHECK(namespace >= 0)
+            );
+            CREATE TABLE revisions (
+                revision_id INTEGER PRIMARY KEY,
+                page_id INTEGER NOT NULL,
+                parent_id INTEGER,
+                timestamp TIMESTAMP NOT NULL,
+                user TEXT,
+                user_id INTEGER,
+                comment TEXT,
+                content TEXT NOT NULL,
+                size INTEGER NOT NULL,
+                sha1 TEXT NOT NULL,
+                minor BOOLEAN DEFAULT 0,
+                tags TEXT
+            );
+            INSERT INTO pages (page_id, namespace, title, is_redirect) VALUES
+                (1, 0, 'Prontera', 0), (2, 0, 'Capital', 1);
+            INSERT INTO revisions (revision_id, page_id, timestamp, content, size, sha1) VALUES
+                (1, 1, '2024-01-01', 'Capital of Rune-Midgarts', 24, 'a'),
+                (2, 2, '2024-01-01', '#REDIRECT [[prontera]]', 22, 'b');
+        """
+        )
+        conn.close()
+
+        db = Database(temp_db_path)
+        db.initialize_schema()
+        conn = db.get_connection()
+
+        rows = conn.execute("SELECT redirect_target FROM pages ORDER BY page_id").fetchall()
+        assert [row[0] for row in rows] == [None, "Prontera"]
+
+        db.close()
+
 
 class TestConnectionManagement:
     """Test Database connection management."""
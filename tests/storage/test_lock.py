@@ -0,0 +1,70 @@
+"""
+Test ArchiveLock writer coordination.
+"""
+
+import json
+import os
+import socket
+
+import pytest
+
+from scraper.storage.database import Database
+from scraper.storage.lock import ArchiveLock, ArchiveLockedError, lock_path
+
+
+class TestArchiveLock:
+    """Test taking, refusing and releasing archive locks."""
+
+    def test_lock_records_holder(self, tmp_path):
+        """Test the lock file describes its holder and is removed on release."""
+        db_path = tmp_path / "irowiki.db"
+
+        with ArchiveLock(db_path, "scraper full"):
+            holder = json.loads(lock_path(db_path).read_text())
+            assert holder["pid"] == os.getpid()
+            assert holder["host"] == socket.gethostname()
+            assert holder["tool"] == "scraper full"
+
+        assert not lock_path(db_path).exists()
+
+    def test_second_writer_is_refused(self, tmp_path):
+        """Test a held lock raises ArchiveLockedError with holder info."""
+        db_path = tmp_path / "irowiki.db"
+
+        with ArchiveLock(db_path, "scraper full"):
+            with pytest.raises(ArchiveLockedError) as exc_info:
+                ArchiveLock(db_path, "scraper merge-wiki").acquire()
+
+        assert exc_info.value.holder["tool"] == "scraper full"
+        assert "scraper full" in str(exc_info.value)
+
+    def test_stale_lock_is_taken_over(self, tmp_path):
+        """Test a lock left by an exited process on this host is replaced."""
+        db_path = tmp_path / "irowiki.db"
+        lock_path(db_path).write_text(
+            json.dumps({"pid": 2**22 + 12345, "host": socket.gethostname(), "tool": "crashed"})
+        )
+
+        with ArchiveLock(db_path, "scraper incremental"):
+            holder = json.loads(lock_path(db_path).read_text())
+            assert holder["tool"] == "scraper incremental"
+
+    def test_other_hosts_are_not_taken_over(self, tmp_path):
+        """Test locks of other hosts are respected even if the pid is unused here."""
+        db_path = tmp_path / "irowiki.db"
+        lock_path(db_path).write_text(
+            json.dumps({"pid": 2**22 + 12345, "host": "elsewhere", "tool": "scraper full"})
+        )
+
+        with pytest.raises(ArchiveLockedError):
+            ArchiveLock(db_path, "scraper incremental").acquire()
+
+    def test_database_releases_lock_on_close(self, tmp_path):
+        """Test Database.acquire_lock holds the lock until close."""
+        db_path = tmp_path / "irowiki.db"
+        database = Database(str(db_path))
+        database.acquire_lock("scraper full")
+
+        assert lock_path(db_path).exists()
+        database.close()
+        assert not lock_path(db_path).exists()
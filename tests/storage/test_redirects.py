@@ -0,0 +1,88 @@
+"""Tests for redirect target storage."""
+
+from datetime import datetime
+
+import pytest
+
+from scraper.storage.models import Page, Revision
+from scraper.storage.page_repository import PageRepository
+from scraper.storage.redirects import extract_redirect_target
+from scraper.storage.revision_repository import RevisionRepository
+
+
+class TestExtractRedirectTarget:
+    """Test parsing redirect targets from wikitext."""
+
+    @pytest.mark.parametrize(
+        "wikitext,expected",
+        [
+            ("#REDIRECT [[Main Page]]", "Main Page"),
+            ("#redirect:[[poring_card]]", "Poring card"),
+            ("#REDIRECT [[Poring#Drops]]", "Poring"),
+            ("  #Redirect [[:Category:Monsters|monsters]]", "Category:Monsters"),
+            ("#REDIRECT [[ Poring  Card ]]\n[[Category:Redirects]]", "Poring Card"),
+            ("Not a redirect", None),
+            ("See #REDIRECT [[Main Page]]", None),
+            ("#REDIRECT [[#Section]]", None),
+            ("", None),
+            (None, None),
+        ],
+    )
+    def test_extract(self, wikitext, expected):
+        """Test targets are normalized and non-redirects yield None."""
+        assert extract_redirect_target(wikitext) == expected
+
+
+class TestUpdateRedirectTargets:
+    """Test recording redirect targets in pages.redirect_target."""
+
+    def _revision(self, revision_id, page_id, day, content):
+        return Revision(
+            revision_id=revision_id,
+            page_id=page_id,
+            parent_id=None,
+            timestamp=datetime(2024, 1, day),
+            user="Alice",
+            user_id=101,
+            comment="",
+            content=content,
+            size=len(content),
+            sha1="a" * 40,
+        )
+
+    def test_update_uses_latest_revision(self, db, sample_pages):
+        """Test targets come from each redirect's latest revision."""
+        pages = PageRepository(db)
+        pages.insert_pages_batch(
+            sample_pages + [Page(page_id=6, namespace=0, title="Old Redirect", is_redirect=True)]
+        )
+        RevisionRepository(db).insert_revisions_batch(
+            [
+                self._revision(1, 1, 1, "#REDIRECT [[Nowhere]]"),
+                self._revision(2, 4, 1, "#REDIRECT [[Test Article]]"),
+                self._revision(3, 4, 2, "#REDIRECT [[main_Page#Intro]]"),
+                self._revision(4, 6, 1, "Just an article now"),
+            ]
+        )
+
+        assert pages.update_redirect_targets() == 1
+
+        rows = db.get_connection().execute(
+            "SELECT page_id, redirect_target FROM pages ORDER BY page_id"
+        ).fetchall()
+        targets = {row[0]: row[1] for row in rows}
+        # Page 1 isn't flagged as a redirect, so its content is ignored
+        assert targets[1] is None
+        assert targets[4] == "Main Page"
+        assert targets[6] is None
+
+    def test_update_selected_pages(self, db, sample_pages):
+        """Test only the given pages are updated."""
+        pages = PageRepository(db)
+        pages.insert_pages_batch(sample_pages)
+        RevisionRepository(db).insert_revisions_batch(
+            [self._revision(1, 4, 1, "#REDIRECT [[Main Page]]")]
+        )
+
+        assert pages.update_redirect_targets([1, 2]) == 0
+        assert pages.update_redirect_targets([4]) == 1
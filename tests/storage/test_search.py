@@ -677,6 +677,46 @@ class TestTriggers:
         page_ids = [r.page_id for r in results]
         assert 1 not in page_ids
 
+    def test_comment_index_follows_revisions(self, db: Database):
+        """Test edit summaries are indexed per revision and kept in sync."""
+        conn = db.get_connection()
+
+        conn.execute(
+            "INSERT INTO pages (page_id, namespace, title) VALUES (1, 0, 'Test Page')"
+        )
+        for revision_id, comment in [(1, "Added drop rates"), (2, ""), (3, None)]:
+            conn.execute(
+                """
+                INSERT INTO revisions
+                (revision_id, page_id, parent_id, timestamp, user, user_id,
+                 comment, content, size, sha1, minor, tags)
+                VALUES (?, 1, NULL, ?, 'User1', NULL, ?, 'Content', 7, ?, 0, NULL)
+            """,
+                (revision_id, datetime(2024, 1, revision_id), comment, f"abc{revision_id}"),
+            )
+        conn.commit()
+
+        def matches(query):
+            cursor = conn.execute(
+                "SELECT rowid FROM revision_comments_fts "
+                "WHERE revision_comments_fts MATCH ? ORDER BY rowid",
+                (query,),
+            )
+            return [row[0] for row in cursor.fetchall()]
+
+        # Porter stemming matches "drop" and "rate" against the summary
+        assert matches("drop rate") == [1]
+        cursor = conn.execute("SELECT COUNT(*) FROM revision_comments_fts")
+        assert cursor.fetchone()[0] == 1
+
+        conn.execute("UPDATE revisions SET comment = 'Fixed typo' WHERE revision_id = 3")
+        conn.commit()
+        assert matches("typo") == [3]
+
+        conn.execute("DELETE FROM revisions WHERE revision_id = 1")
+        conn.commit()
+        assert matches("drop") == []
+
 
 class TestEdgeCases:
     """Test edge cases and error handling."""
@@ -0,0 +1,105 @@
+"""Tests for sister wiki merging."""
+
+from dataclasses import replace
+
+import pytest
+
+from scraper.storage.archive_meta import ArchiveMetaRepository
+from scraper.storage.database import Database
+from scraper.storage.page_repository import PageRepository
+from scraper.storage.revision_repository import RevisionRepository
+from scraper.storage.wikis import (
+    ID_OFFSET_STEP,
+    WikiRepository,
+    map_interwiki_links,
+    merge_wiki,
+)
+
+
+@pytest.fixture
+def sister_archive(tmp_path, sample_pages, sample_revisions):
+    """Create a single-wiki archive of a sister wiki."""
+    path = tmp_path / "classic.db"
+    with Database(str(path)) as source:
+        source.initialize_schema()
+        PageRepository(source).insert_pages_batch(sample_pages)
+        RevisionRepository(source).insert_revisions_batch(sample_revisions)
+        ArchiveMetaRepository(source).set_many(
+            {
+                "base_url": "https://irowiki.org",
+                "wiki_name": "iRO Wiki Classic",
+                "article_path": "/classic/$1",
+            }
+        )
+    return path
+
+
+class TestMergeWiki:
+    """Test merging a sister wiki into an archive."""
+
+    def test_merge_offsets_ids(self, db, sample_pages, sister_archive):
+        """Test pages and revisions are copied with shifted IDs."""
+        PageRepository(db).insert_pages_batch(sample_pages)
+
+        result = merge_wiki(db, str(sister_archive), "classic")
+
+        assert result.wiki.name == "iRO Wiki Classic"
+        assert result.wiki.id_offset == ID_OFFSET_STEP
+        assert result.pages == len(sample_pages)
+
+        conn = db.get_connection()
+        rows = conn.execute(
+            "SELECT page_id, wiki_id FROM pages WHERE title = 'Main Page'"
+        ).fetchall()
+        assert sorted(tuple(row) for row in rows) == [
+            (1, ""),
+            (ID_OFFSET_STEP + 1, "classic"),
+        ]
+        revision = conn.execute(
+            "SELECT page_id FROM revisions WHERE revision_id = ?",
+            (ID_OFFSET_STEP + 1001,),
+        ).fetchone()
+        assert revision[0] == ID_OFFSET_STEP + 1
+
+    def test_remerge_replaces_pages(self, db, sister_archive):
+        """Test merging the same wiki again keeps its offset and row counts."""
+        first = merge_wiki(db, str(sister_archive), "classic")
+        second = merge_wiki(db, str(sister_archive), "classic")
+
+        assert second.wiki.id_offset == first.wiki.id_offset
+        count = db.get_connection().execute("SELECT COUNT(*) FROM pages").fetchone()
+        assert count[0] == first.pages
+        assert [w.wiki_id for w in WikiRepository(db).list()] == ["classic"]
+
+    def test_merge_maps_interwiki_links(
+        self, db, sample_pages, sample_revisions, sister_archive
+    ):
+        """Test links prefixed with a merged wiki's ID map pages across wikis."""
+        PageRepository(db).insert_pages_batch(sample_pages)
+        latest = replace(
+            sample_revisions[1],
+            content="Hello! [[classic:Test_Article#History|old]] [[de:Hauptseite]]",
+        )
+        RevisionRepository(db).insert_revisions_batch([sample_revisions[0], latest])
+
+        result = merge_wiki(db, str(sister_archive), "classic")
+
+        assert result.interwiki_links == 1
+        rows = db.get_connection().execute(
+            "SELECT source_page_id, target_wiki, target_title FROM interwiki_links"
+        ).fetchall()
+        assert [tuple(row) for row in rows] == [(1, "classic", "Test Article")]
+
+        # Rebuilding replaces the mapping
+        assert map_interwiki_links(db) == 1
+
+    def test_merge_rejects_invalid_input(self, db, tmp_path, sister_archive):
+        """Test invalid wiki IDs and archives without metadata are rejected."""
+        with pytest.raises(ValueError, match="Invalid wiki ID"):
+            merge_wiki(db, str(sister_archive), "Classic Wiki")
+
+        bare = tmp_path / "bare.db"
+        with Database(str(bare)) as source:
+            source.initialize_schema()
+        with pytest.raises(ValueError, match="base_url"):
+            merge_wiki(db, str(bare), "classic")
@@ -187,6 +187,25 @@ class TestFullScrapeArguments:
         args = parser.parse_args(["full"])
         assert args.dry_run is False
 
+    def test_estimate_flag(self):
+        """Test --estimate flag."""
+        parser = create_parser()
+        args = parser.parse_args(["full", "--estimate"])
+        assert args.estimate is True
+        assert parser.parse_args(["full"]).estimate is False
+
+    def test_capture_html_flag(self):
+        """Test --capture-html flag."""
+        parser = create_parser()
+        assert parser.parse_args(["full", "--capture-html"]).capture_html is True
+        assert parser.parse_args(["full"]).capture_html is False
+
+    def test_capture_protection_flag(self):
+        """Test --capture-protection flag."""
+        parser = create_parser()
+        assert parser.parse_args(["full", "--capture-protection"]).capture_protection is True
+        assert parser.parse_args(["full"]).capture_protection is False
+
     def test_full_with_all_arguments(self):
         """Test full command with all arguments."""
         parser = create_parser()
@@ -260,6 +279,19 @@ class TestIncrementalScrapeArguments:
         args = parser.parse_args(["incremental"])
         assert args.rate_limit == 2.0
 
+    def test_capture_html_flag(self):
+        """Test --capture-html flag for incremental."""
+        parser = create_parser()
+        assert parser.parse_args(["incremental", "--capture-html"]).capture_html is True
+        assert parser.parse_args(["incremental"]).capture_html is False
+
+    def test_capture_protection_flag(self):
+        """Test --capture-protection flag for incremental."""
+        parser = create_parser()
+        args = parser.parse_args(["incremental", "--capture-protection"])
+        assert args.capture_protection is True
+        assert parser.parse_args(["incremental"]).capture_protection is False
+
     def test_incremental_with_all_arguments(self):
         """Test incremental command with all arguments."""
         parser = create_parser()
@@ -294,6 +326,26 @@ class TestIncrementalScrapeArguments:
         assert args.rate_limit == 1.5
 
 
+class TestMergeWikiCommand:
+    """Test merge-wiki command arguments."""
+
+    def test_merge_wiki_arguments(self):
+        """Test merge-wiki takes a source archive and a wiki ID."""
+        parser = create_parser()
+        args = parser.parse_args(["merge-wiki", "classic.db", "--wiki-id", "classic"])
+
+        assert args.command == "merge-wiki"
+        assert args.source == Path("classic.db")
+        assert args.wiki_id == "classic"
+        assert args.format == "text"
+
+    def test_merge_wiki_requires_wiki_id(self):
+        """Test merge-wiki without --wiki-id is an error."""
+        parser = create_parser()
+        with pytest.raises(SystemExit):
+            parser.parse_args(["merge-wiki", "classic.db"])
+
+
 class TestHelpText:
     """Test help text generation."""
 
@@ -625,3 +625,75 @@ class TestConfigLogging:
                 Config.from_yaml(missing)
 
         assert len(caplog.records) > 0
+
+
+# ============================================================================
+# TEST CLASS: SHARED FILE AND ENVIRONMENT
+# ============================================================================
+
+
+class TestConfigSharedWithGoTools:
+    """Test the file and environment settings shared with the Go tools."""
+
+    def test_go_sections_ignored_and_db_used(self, temp_config_dir: Path):
+        """Test a Go tools config loads, with db as the database file."""
+        path = temp_config_dir / "shared.yaml"
+        path.write_text(
+            "db: archives/irowiki.db\n"
+            "format: json\n"
+            "server:\n  addr: localhost:9000\n"
+            "scraper:\n  rate_limit: 0.5\n"
+        )
+
+        config = Config.from_yaml(path)
+
+        assert config.storage.database_file == Path("archives/irowiki.db")
+        assert config.scraper.rate_limit == 0.5
+
+    def test_storage_database_file_wins_over_db(self, temp_config_dir: Path):
+        """Test storage.database_file takes precedence over db."""
+        path = temp_config_dir / "shared.yaml"
+        path.write_text(
+            "db: postgres://wiki@db/irowiki\nstorage:\n  database_file: data/x.db\n"
+        )
+
+        config = Config.from_yaml(path)
+
+        assert config.storage.database_file == Path("data/x.db")
+
+    def test_apply_env_overrides(self):
+        """Test IROWIKI_* variables override settings."""
+        config = Config()
+        config.apply_env(
+            {
+                "IROWIKI_WIKI_BASE_URL": "https://example.org",
+                "IROWIKI_SCRAPER_RATE_LIMIT": "3.5",
+                "IROWIKI_SCRAPER_MAX_RETRIES": "7",
+                "IROWIKI_DB": "env.db",
+                "IROWIKI_LOG_LEVEL": "debug",
+                "IROWIKI_SCRAPER_USER_AGENT": "",
+            }
+        )
+
+        assert config.wiki.base_url == "https://example.org"
+        assert config.scraper.rate_limit == 3.5
+        assert config.scraper.max_retries == 7
+        assert config.storage.database_file == Path("env.db")
+        assert config.logging.level == "DEBUG"
+        # Empty variables are ignored
+        assert config.scraper.user_agent == Config().scraper.user_agent
+        config.validate()
+
+    def test_apply_env_ignores_database_urls(self):
+        """Test IROWIKI_DB holding a PostgreSQL URL is left to the Go tools."""
+        config = Config()
+        config.apply_env({"IROWIKI_DB": "postgres://wiki@db/irowiki"})
+
+        assert config.storage.database_file == Path("data/irowiki.db")
+
+    def test_apply_env_invalid_value(self):
+        """Test unparseable variables raise ConfigError."""
+        config = Config()
+
+        with pytest.raises(ConfigError, match="IROWIKI_SCRAPER_TIMEOUT"):
+            config.apply_env({"IROWIKI_SCRAPER_TIMEOUT": "soon"})
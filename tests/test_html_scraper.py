@@ -0,0 +1,68 @@
+"""Tests for the PageHTMLScraper class."""
+
+from unittest.mock import MagicMock
+
+from scraper.scrapers.html_scraper import PageHTMLScraper
+from scraper.storage.page_repository import PageRepository
+from scraper.storage.revision_repository import RevisionRepository
+
+
+def _load_samples(db, sample_pages, sample_revisions):
+    """Insert the sample pages and revisions."""
+    PageRepository(db).insert_pages_batch(sample_pages)
+    RevisionRepository(db).insert_revisions_batch(sample_revisions)
+
+
+def _html_rows(db):
+    """Return page_html as {page_id: (revision_id, html)}."""
+    cursor = db.get_connection().execute(
+        "SELECT page_id, revision_id, html FROM page_html"
+    )
+    return {row[0]: (row[1], row[2]) for row in cursor.fetchall()}
+
+
+class TestPageHTMLScraper:
+    """Test rendered HTML capture."""
+
+    def test_capture_latest_revisions(self, db, sample_pages, sample_revisions):
+        """Test each page's latest revision is rendered and stored."""
+        _load_samples(db, sample_pages, sample_revisions)
+        api_client = MagicMock()
+        api_client.parse.side_effect = lambda params: {
+            "parse": {"text": {"*": f"<p>rev {params['oldid']}</p>"}}
+        }
+
+        result = PageHTMLScraper(api_client, db).capture()
+
+        assert result.captured == 2
+        assert result.failed == []
+        assert _html_rows(db) == {
+            1: (1002, "<p>rev 1002</p>"),
+            2: (1003, "<p>rev 1003</p>"),
+        }
+
+    def test_capture_skips_current_pages(self, db, sample_pages, sample_revisions):
+        """Test pages whose HTML matches the latest revision aren't refetched."""
+        _load_samples(db, sample_pages, sample_revisions)
+        scraper = PageHTMLScraper(MagicMock(), db)
+        scraper.store_html(1, 1002, "<p>current</p>")
+        scraper.store_html(2, 1000, "<p>stale</p>")
+
+        assert scraper.pending() == [(2, 1003)]
+        assert scraper.pending(namespaces=[1]) == []
+
+    def test_capture_records_failures(self, db, sample_pages, sample_revisions):
+        """Test a failed page is skipped and stays pending."""
+        _load_samples(db, sample_pages, sample_revisions)
+        api_client = MagicMock()
+        api_client.parse.side_effect = [
+            {"parse": {"text": {"*": "<p>ok</p>"}}},
+            {"error": {"code": "nosuchrevid"}},
+        ]
+
+        scraper = PageHTMLScraper(api_client, db)
+        result = scraper.capture()
+
+        assert result.captured == 1
+        assert result.failed == [2]
+        assert scraper.pending() == [(2, 1003)]
@@ -0,0 +1,94 @@
+"""Tests for the PageProtectionScraper class."""
+
+from unittest.mock import MagicMock
+
+from scraper.scrapers.protection_scraper import PageProtectionScraper
+from scraper.storage.page_repository import PageRepository
+
+
+def _protection_rows(db):
+    """Return page_protection as {page_id: [(action, level, expiry)]}."""
+    cursor = db.get_connection().execute(
+        "SELECT page_id, action, level, expiry FROM page_protection ORDER BY page_id, action"
+    )
+    rows = {}
+    for page_id, action, level, expiry in cursor.fetchall():
+        rows.setdefault(page_id, []).append((action, level, expiry))
+    return rows
+
+
+def _info_response(pages):
+    """Build a prop=info response for {page_id: protection entries}."""
+    return {
+        "query": {
+            "pages": {
+                str(page_id): {"pageid": page_id, "protection": entries}
+                for page_id, entries in pages.items()
+            }
+        }
+    }
+
+
+class TestPageProtectionScraper:
+    """Test protection level capture."""
+
+    def test_capture_protection(self, db, sample_pages):
+        """Test protection levels are stored per action."""
+        PageRepository(db).insert_pages_batch(sample_pages[:2])
+        api_client = MagicMock()
+        api_client.query.return_value = _info_response(
+            {
+                1: [
+                    {"type": "edit", "level": "sysop", "expiry": "infinity"},
+                    {"type": "move", "level": "sysop", "expiry": "2030-01-01T00:00:00Z"},
+                ],
+                2: [],
+            }
+        )
+
+        result = PageProtectionScraper(api_client, db).capture()
+
+        assert result.pages == 2
+        assert result.protected == 1
+        assert result.failed == []
+        assert _protection_rows(db) == {
+            1: [
+                ("edit", "sysop", "infinity"),
+                ("move", "sysop", "2030-01-01T00:00:00Z"),
+            ]
+        }
+        params = api_client.query.call_args[0][0]
+        assert params["inprop"] == "protection"
+        assert params["pageids"] == "1|2"
+
+    def test_capture_replaces_lifted_protection(self, db, sample_pages):
+        """Test recapturing removes protection that was lifted."""
+        PageRepository(db).insert_pages_batch(sample_pages[:2])
+        scraper = PageProtectionScraper(MagicMock(), db)
+        scraper.store_protection(1, [("edit", "autoconfirmed", "infinity")])
+
+        scraper.api.query.return_value = _info_response({1: [], 2: []})
+        scraper.capture()
+
+        assert _protection_rows(db) == {}
+
+    def test_capture_records_failures(self, db, sample_pages):
+        """Test a failed batch is skipped and keeps its previous rows."""
+        PageRepository(db).insert_pages_batch(sample_pages[:2])
+        scraper = PageProtectionScraper(MagicMock(), db)
+        scraper.store_protection(1, [("edit", "sysop", "infinity")])
+        scraper.api.query.side_effect = Exception("API error")
+
+        result = scraper.capture()
+
+        assert result.pages == 0
+        assert result.failed == [1, 2]
+        assert _protection_rows(db) == {1: [("edit", "sysop", "infinity")]}
+
+    def test_page_ids_by_namespace(self, db, sample_pages):
+        """Test capture can be limited to namespaces."""
+        PageRepository(db).insert_pages_batch(sample_pages)
+        scraper = PageProtectionScraper(MagicMock(), db)
+
+        assert scraper.page_ids() == [1, 2, 3, 4, 5]
+        assert scraper.page_ids(namespaces=[1, 6]) == [3, 5]
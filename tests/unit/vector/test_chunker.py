@@ -18,7 +18,7 @@ pytest.importorskip("numpy")
 # Add scripts directory to path
 sys.path.insert(0, str(Path(__file__).parent.parent.parent.parent / "scripts"))
 
-from vectorize_wiki import Chunk, WikiChunker  # noqa: E402
+from vectorize_wiki import Chunk, ChunkType, WikiChunker  # noqa: E402
 
 
 class TestWikiTextCleaning:
@@ -350,6 +350,30 @@ Section content here.
         # Should be sequential starting from 0
         assert indices == list(range(len(chunks)))
 
+    def test_chunk_section_anchor_and_url(self):
+        """Test that section chunks deep-link to their section"""
+        words = " ".join(["word"] * 60)
+        page_data = {
+            "page_id": 1,
+            "revision_id": 101,
+            "page_title": "Guillotine Cross",
+            "namespace": 0,
+            "content": f"{words}\n== Skills and Builds ==\n{words}\n",
+            "metadata": {},
+            "url": "https://irowiki.org/wiki/Guillotine_Cross",
+        }
+
+        chunks = list(self.chunker.chunk_section_level(page_data))
+
+        # The introduction links to the page itself
+        assert chunks[0].section_anchor is None
+        assert chunks[0].url == "https://irowiki.org/wiki/Guillotine_Cross"
+        assert chunks[1].section_anchor == "Skills_and_Builds"
+        assert (
+            chunks[1].url
+            == "https://irowiki.org/wiki/Guillotine_Cross#Skills_and_Builds"
+        )
+
 
 class TestParagraphLevelChunking:
     """Test paragraph-level chunking strategy"""
@@ -460,6 +484,128 @@ Paragraph under second section also with sufficient content.
         assert chunks[1].section_title == "Another Section"
 
 
+class TestWindowChunking:
+    """Test fixed-window chunking strategy"""
+
+    def setup_method(self):
+        self.chunker = WikiChunker(window_size=60, window_overlap=10)
+
+    def test_chunk_windows_overlap(self):
+        """Test that windows cover the page and share overlap words"""
+        words = [f"w{i}" for i in range(150)]
+        page_data = {
+            "page_id": 1,
+            "revision_id": 101,
+            "page_title": "Test",
+            "namespace": 0,
+            "content": " ".join(words),
+            "metadata": {},
+        }
+
+        chunks = list(self.chunker.chunk_window_level(page_data))
+
+        # Windows start every 50 words; the last reaches the end
+        assert [c.content.split()[0] for c in chunks] == ["w0", "w50", "w100"]
+        assert chunks[0].content.split()[-10:] == chunks[1].content.split()[:10]
+        assert chunks[-1].content.split()[-1] == "w149"
+        assert all(c.chunk_type == "window" for c in chunks)
+        assert chunks[1].get_id() == "page_1_window_1"
+
+    def test_chunk_window_filters_short(self):
+        """Test that very short pages produce no windows"""
+        page_data = {
+            "page_id": 1,
+            "revision_id": 101,
+            "page_title": "Test",
+            "namespace": 0,
+            "content": "Too short.",
+            "metadata": {},
+        }
+
+        assert list(self.chunker.chunk_window_level(page_data)) == []
+
+    def test_invalid_window_options(self):
+        """Test that the overlap must be smaller than the window"""
+        with pytest.raises(ValueError):
+            WikiChunker(window_size=10, window_overlap=10)
+
+
+class TestTableAwareChunking:
+    """Test table-aware chunking strategy"""
+
+    def setup_method(self):
+        self.chunker = WikiChunker(table_rows=2)
+
+    def test_chunk_tables_by_rows(self):
+        """Test that tables split between rows, keeping their headers"""
+        intro = " ".join(["word"] * 60)
+        content = f"""{intro}
+== Drops ==
+{{| class="wikitable"
+|+ Poring drops
+! Item !! Chance
+|-
+| [[Jellopy]] || 70%
+|-
+| style="color:red" | [[Apple|Apples]] || 10%
+|-
+| [[Poring Card]]
+| 0.01%
+|}}
+"""
+        page_data = {
+            "page_id": 1,
+            "revision_id": 101,
+            "page_title": "Poring",
+            "namespace": 0,
+            "content": content,
+            "metadata": {},
+        }
+
+        chunks = list(self.chunker.chunk_table_aware(page_data))
+        tables = [c for c in chunks if c.chunk_type == "table"]
+
+        # The introduction is the page's summary
+        assert chunks[0].chunk_type == ChunkType.SUMMARY
+        assert len(tables) == 2
+        assert tables[0].content == (
+            "Poring > Drops > Poring drops\n\n"
+            "Item: Jellopy; Chance: 70%\n"
+            "Item: Apples; Chance: 10%"
+        )
+        assert tables[1].content.endswith("Item: Poring Card; Chance: 0.01%")
+        assert tables[0].section_anchor == "Drops"
+
+    def test_chunk_infobox(self):
+        """Test that infoboxes become chunks of their parameters"""
+        page_data = {
+            "page_id": 1,
+            "revision_id": 101,
+            "page_title": "Poring",
+            "namespace": 0,
+            "content": "{{Infobox Monster\n|name=Poring\n|hp= 50\n|race=[[Plant]]}}\n{{Stub}}",
+            "metadata": {},
+        }
+
+        chunks = list(self.chunker.chunk_table_aware(page_data))
+
+        assert len(chunks) == 1
+        assert chunks[0].chunk_type == ChunkType.INFOBOX
+        assert chunks[0].content == (
+            "Poring > Infobox Monster\n\nname: Poring\nhp: 50\nrace: Plant"
+        )
+        assert chunks[0].get_id() == "page_1_infobox_0"
+
+    def test_extract_tables_keeps_prose(self):
+        """Test that text around tables is kept and unclosed tables stay text"""
+        prose, tables = WikiChunker.extract_tables(
+            "Before\n{|\n| a || b\n|}\nAfter\n{|\n| unclosed"
+        )
+
+        assert prose == "Before\nAfter\n{|\n| unclosed"
+        assert tables == [(None, [], [["a", "b"]])]
+
+
 class TestChunkGeneration:
     """Test Chunk object generation and properties"""
 
@@ -487,6 +633,51 @@ class TestChunkGeneration:
         assert data["chunk_type"] == "section"
         assert data["section_title"] == "Intro"
         assert data["metadata"]["key"] == "value"
+        assert data["url"] is None
+
+    def test_chunk_url(self):
+        """Test source URL construction with escaped anchors"""
+        chunk = Chunk(
+            page_id=1,
+            revision_id=101,
+            page_title="Test",
+            namespace=0,
+            content="Content",
+            chunk_type="section",
+            section_title="Drops & Stats",
+            section_anchor="Drops_&_Stats",
+            page_url="https://irowiki.org/wiki/Test",
+        )
+
+        data = chunk.to_dict()
+
+        assert data["revision_id"] == 101
+        assert data["section_anchor"] == "Drops_&_Stats"
+        assert data["url"] == "https://irowiki.org/wiki/Test#Drops_%26_Stats"
+
+    def test_chunk_type_validation(self):
+        """Test that unknown chunk types are rejected at index time"""
+        with pytest.raises(ValueError, match="unknown chunk type"):
+            Chunk(
+                page_id=1,
+                revision_id=101,
+                page_title="Test",
+                namespace=0,
+                content="Content",
+                chunk_type="sections",
+            )
+
+        # Strings are accepted and stored as plain values
+        chunk = Chunk(
+            page_id=1,
+            revision_id=101,
+            page_title="Test",
+            namespace=0,
+            content="Content",
+            chunk_type="table",
+        )
+        assert chunk.chunk_type is ChunkType.TABLE
+        assert chunk.to_dict()["chunk_type"] == "table"
 
     def test_chunk_get_id_page(self):
         """Test unique ID generation for page chunks"""
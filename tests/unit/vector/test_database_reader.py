@@ -124,6 +124,50 @@ class TestDatabaseReader:
             assert pages[0]["namespace"] == 10
             assert pages[0]["page_title"] == "Template:Infobox"
 
+    def test_iter_pages_url_from_archive_meta(self, tmp_path):
+        """Test that page URLs use the archive's base URL and article path"""
+        import sqlite3
+
+        db_path = tmp_path / "archive.db"
+        conn = sqlite3.connect(db_path)
+        conn.executescript("""
+            CREATE TABLE pages (
+                page_id INTEGER PRIMARY KEY,
+                title TEXT NOT NULL,
+                namespace INTEGER NOT NULL,
+                is_redirect INTEGER DEFAULT 0
+            );
+            CREATE TABLE revisions (
+                revision_id INTEGER PRIMARY KEY,
+                page_id INTEGER NOT NULL,
+                timestamp TEXT NOT NULL,
+                user TEXT,
+                content TEXT NOT NULL
+            );
+            INSERT INTO pages VALUES (1, 'Main_Page', 0, 0);
+            INSERT INTO revisions VALUES (101, 1, '2024-01-01T00:00:00Z', 'Editor1', 'Welcome');
+        """)
+        conn.commit()
+
+        # Without a base URL, pages have no URL
+        with DatabaseReader(str(db_path)) as db:
+            assert list(db.iter_pages())[0]["url"] is None
+
+        conn.execute("CREATE TABLE archive_meta (key TEXT PRIMARY KEY, value TEXT)")
+        conn.executemany(
+            "INSERT INTO archive_meta VALUES (?, ?)",
+            [("base_url", "https://irowiki.org/"), ("article_path", "/w/$1")],
+        )
+        conn.commit()
+        conn.close()
+
+        with DatabaseReader(str(db_path)) as db:
+            assert list(db.iter_pages())[0]["url"] == "https://irowiki.org/w/Main_Page"
+
+        # An explicit base URL, such as a mirror, takes precedence
+        with DatabaseReader(str(db_path), base_url="https://mirror.test") as db:
+            assert list(db.iter_pages())[0]["url"] == "https://mirror.test/wiki/Main_Page"
+
     def test_context_manager(self, test_database):
         """Test DatabaseReader as context manager"""
         db = DatabaseReader(str(test_database))